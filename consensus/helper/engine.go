@@ -26,9 +26,11 @@ import (
 	"github.com/hyperledger/fabric/consensus/controller"
 	"github.com/hyperledger/fabric/consensus/util"
 	"github.com/hyperledger/fabric/core/chaincode"
+	openchainUtil "github.com/hyperledger/fabric/core/util"
 	pb "github.com/hyperledger/fabric/protos"
 	"golang.org/x/net/context"
 	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
 )
 
 // EngineImpl implements a struct to hold consensus.Consenter, PeerEndpoint and MessageFan
@@ -49,6 +51,11 @@ func (eng *EngineImpl) ProcessTransactionMsg(msg *pb.Message, inBlockTx *pb.InBl
 	switch tx := inBlockTx.Transaction.(type) {
 	case *pb.InBlockTransaction_TransactionSet, *pb.InBlockTransaction_SetStQueryTransaction:
 
+		// Ordered tx set state queries go through the consensus like the other transactions
+		if stQuery, ok := tx.(*pb.InBlockTransaction_SetStQueryTransaction); ok && stQuery.SetStQueryTransaction.Ordered {
+			break
+		}
+
 		// Make sure that if this is a Transactions Set it is encapsulating a query.
 		if txSet, ok := tx.(*pb.InBlockTransaction_TransactionSet); ok {
 			if len(txSet.TransactionSet.Transactions) != 1 {
@@ -88,6 +95,13 @@ func (eng *EngineImpl) ProcessTransactionMsg(msg *pb.Message, inBlockTx *pb.InBl
 		return response
 	}
 
+	// The time of a block is derived from the timestamps of its transactions, a transaction stamped in the
+	// future would release the time locks of the sets early
+	if maxSkew := viper.GetDuration("peer.validator.consensus.maxTimestampSkew"); openchainUtil.IsTimestampTooFarAhead(inBlockTx.Timestamp, maxSkew) {
+		return &pb.Response{Status: pb.Response_FAILURE,
+			Msg: []byte(fmt.Sprintf("Error: the timestamp of transaction %s is more than %s ahead of the validator clock", inBlockTx.Txid, maxSkew))}
+	}
+
 	// Chaincode Transaction
 	response = &pb.Response{Status: pb.Response_SUCCESS, Msg: []byte(inBlockTx.Txid)}
 
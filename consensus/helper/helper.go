@@ -186,7 +186,8 @@ func (h *Helper) ExecTxs(id interface{}, txs []*pb.InBlockTransaction) ([]byte,
 		return nil, fmt.Errorf("Unable to persists nonces of given InBlockTransactions. Error: [%s]", err)
 	}
 
-	succeededTxs, res, ccevents, txerrs, err := chaincode.ExecuteTransactions(context.Background(), chaincode.DefaultChain, txs)
+	// The results also cover the scheduled and queued mutations, which are executed before txs
+	executedTxs, succeededTxs, res, ccevents, txerrs, err := chaincode.ExecuteTransactions(context.Background(), chaincode.DefaultChain, txs)
 
 	h.curBatch = append(h.curBatch, succeededTxs...) // TODO, remove after issue 579
 
@@ -198,9 +199,9 @@ func (h *Helper) ExecTxs(id interface{}, txs []*pb.InBlockTransaction) ([]byte,
 		//NOTE- it'll be nice if we can have error values. For now success == 0, error == 1
 		if txerrs[i] != nil {
 			// TODO: If the transaction was a set specialize the ID by the ID of the default transaction or the ID of the set (depending on which caused the error)
-			txresults[i] = &pb.TransactionResult{Txid: txs[i].Txid, Error: e.Error(), ErrorCode: 1, ChaincodeEvent: ccevents[i]}
+			txresults[i] = &pb.TransactionResult{Txid: executedTxs[i].Txid, Error: e.Error(), ErrorCode: 1, ChaincodeEvent: ccevents[i]}
 		} else {
-			txresults[i] = &pb.TransactionResult{Txid: txs[i].Txid, ChaincodeEvent: ccevents[i]}
+			txresults[i] = &pb.TransactionResult{Txid: executedTxs[i].Txid, ChaincodeEvent: ccevents[i]}
 		}
 	}
 	h.curBatchErrs = append(h.curBatchErrs, txresults...) // TODO, remove after issue 579
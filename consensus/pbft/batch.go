@@ -82,9 +82,11 @@ func newObcBatch(id uint64, config *viper.Viper, stack consensus.Stack) *obcBatc
 	op.manager.SetReceiver(op)
 	etf := events.NewTimerFactoryImpl(op.manager)
 	op.pbft = newPbftCore(id, config, op, etf)
+	op.obcGeneric.pbft = op.pbft
 	op.manager.Start()
 	op.externalEventReceiver.manager = op.manager
 	op.broadcaster = newBroadcaster(id, op.pbft.N, op.pbft.f, op.pbft.broadcastTimeout, stack)
+	op.broadcaster.reconfigure(op.pbft.membership)
 
 	op.batchSize = config.GetInt("general.batchsize")
 	op.batchStore = nil
@@ -176,7 +178,7 @@ func (op *obcBatch) sign(msg []byte) ([]byte, error) {
 
 // verify message signature
 func (op *obcBatch) verify(senderID uint64, signature []byte, message []byte) error {
-	senderHandle, err := getValidatorHandle(senderID)
+	senderHandle, err := op.pbft.replicaHandle(senderID)
 	if err != nil {
 		return err
 	}
@@ -187,6 +189,10 @@ func (op *obcBatch) verify(senderID uint64, signature []byte, message []byte) er
 func (op *obcBatch) execute(seqNo uint64, reqBatch *RequestBatch) {
 	var txs []*pb.InBlockTransaction
 	for _, req := range reqBatch.GetBatch() {
+		if reconf := req.GetReconfiguration(); reconf != nil {
+			op.executeReconfiguration(seqNo, req)
+			continue
+		}
 		//REVIEW put InBlockTransactions when constructing a request otherwise the unmarshal will fail
 		// Although that should be fine, just check that a message tx bytes instead are inBlockTransaction bytes
 		tx := &pb.InBlockTransaction{}
@@ -201,15 +207,40 @@ func (op *obcBatch) execute(seqNo uint64, reqBatch *RequestBatch) {
 		txs = append(txs, tx)
 		op.deduplicator.Execute(req)
 	}
-	meta, _ := proto.Marshal(&Metadata{seqNo})
+	meta, _ := proto.Marshal(&Metadata{
+		SeqNo:      seqNo,
+		Membership: op.pbft.membership,
+		Pending:    op.pbft.pendingMembership,
+		Votes:      op.pbft.reconfigurationVotes,
+	})
 	logger.Debugf("Batch replica %d received exec for seqNo %d containing %d transactions", op.pbft.id, seqNo, len(txs))
 	op.stack.Execute(meta, txs) // This executes in the background, we will receive an executedEvent once it completes
 }
 
+// update the destinations of the broadcaster when the replica set changes
+func (op *obcBatch) reconfigure(membership *Membership) {
+	if op.broadcaster == nil {
+		// Still restoring state during construction, the broadcaster picks up the membership once it is created
+		return
+	}
+	op.broadcaster.reconfigure(membership)
+}
+
 // =============================================================================
 // functions specific to batch mode
 // =============================================================================
 
+// executeReconfiguration records the vote carried by an ordered
+// reconfiguration request, the membership change is scheduled once enough
+// members signed the same one
+func (op *obcBatch) executeReconfiguration(seqNo uint64, req *Request) {
+	if err := op.pbft.voteReconfiguration(seqNo, req); err != nil {
+		logger.Warningf("Batch replica %d rejecting reconfiguration at seqNo=%d: %s", op.pbft.id, seqNo, err)
+	}
+	op.reqStore.remove(req)
+	op.deduplicator.Execute(req)
+}
+
 func (op *obcBatch) leaderProcReq(req *Request) events.Event {
 	// XXX check req sig
 	digest := hash(req)
@@ -255,9 +286,19 @@ func (op *obcBatch) txToReq(tx []byte) *Request {
 	return req
 }
 
+func (op *obcBatch) reconfigurationToReq(reconf *Reconfiguration) *Request {
+	req := op.txToReq(nil)
+	req.Reconfiguration = reconf
+	return req
+}
+
 func (op *obcBatch) processMessage(ocMsg *pb.Message, senderHandle *pb.PeerID) events.Event {
 	if ocMsg.Type == pb.Message_CHAIN_TRANSACTION {
 		req := op.txToReq(ocMsg.Payload)
+		if isAheadOfClock(req, op.pbft.maxTimestampSkew) {
+			logger.Warningf("Replica %d ignoring transaction stamped more than %v ahead of its clock", op.pbft.id, op.pbft.maxTimestampSkew)
+			return nil
+		}
 		return op.submitToLeader(req)
 	}
 
@@ -278,6 +319,11 @@ func (op *obcBatch) processMessage(ocMsg *pb.Message, senderHandle *pb.PeerID) e
 			logger.Warningf("Replica %d ignoring request as it is too old", op.pbft.id)
 			return nil
 		}
+		// Neither ordered by the primary nor awaited by the backups, a pre-prepare carrying it triggers a view change
+		if isAheadOfClock(req, op.pbft.maxTimestampSkew) {
+			logger.Warningf("Replica %d ignoring request carrying a transaction stamped more than %v ahead of its clock", op.pbft.id, op.pbft.maxTimestampSkew)
+			return nil
+		}
 
 		op.logAddTxFromRequest(req)
 		op.reqStore.storeOutstanding(req)
@@ -287,9 +333,10 @@ func (op *obcBatch) processMessage(ocMsg *pb.Message, senderHandle *pb.PeerID) e
 		op.startTimerIfOutstandingRequests()
 		return nil
 	} else if pbftMsg := batchMsg.GetPbftMessage(); pbftMsg != nil {
-		senderID, err := getValidatorID(senderHandle) // who sent this?
+		senderID, err := op.pbft.replicaID(senderHandle) // who sent this?
 		if err != nil {
-			panic("Cannot map sender's PeerID to a valid replica ID")
+			logger.Warningf("Replica %d ignoring consensus message: %s", op.pbft.id, err)
+			return nil
 		}
 		msg := &Message{}
 		err = proto.Unmarshal(pbftMsg, msg)
@@ -351,6 +398,14 @@ func (op *obcBatch) ProcessEvent(event events.Event) events.Event {
 	case batchMessageEvent:
 		ocMsg := et
 		return op.processMessage(ocMsg.msg, ocMsg.sender)
+	case reconfigurationEvent:
+		logger.Infof("Replica %d submitting reconfiguration adding %d and removing %v", op.pbft.id, len(et.reconf.Add), et.reconf.Remove)
+		req := op.reconfigurationToReq(et.reconf)
+		if err := op.pbft.sign(req); err != nil {
+			logger.Errorf("Replica %d could not sign reconfiguration request: %s", op.pbft.id, err)
+			return nil
+		}
+		return op.submitToLeader(req)
 	case executedEvent:
 		op.stack.Commit(nil, et.tag.([]byte))
 	case committedEvent:
@@ -33,9 +33,12 @@ type communicator interface {
 type broadcaster struct {
 	comm communicator
 
+	self             uint64
 	f                int
 	broadcastTimeout time.Duration
 	msgChans         map[uint64]chan *sendRequest
+	handles          map[uint64]string
+	stopChans        map[uint64]chan struct{}
 	closed           sync.WaitGroup
 	closedCh         chan struct{}
 }
@@ -46,32 +49,54 @@ type sendRequest struct {
 }
 
 func newBroadcaster(self uint64, N int, f int, broadcastTimeout time.Duration, c communicator) *broadcaster {
-	queueSize := 10 // XXX increase after testing
-
-	chans := make(map[uint64]chan *sendRequest)
 	b := &broadcaster{
 		comm:             c,
-		f:                f,
+		self:             self,
 		broadcastTimeout: broadcastTimeout,
-		msgChans:         chans,
+		msgChans:         make(map[uint64]chan *sendRequest),
+		handles:          make(map[uint64]string),
+		stopChans:        make(map[uint64]chan struct{}),
 		closedCh:         make(chan struct{}),
 	}
-	for i := 0; i < N; i++ {
-		if uint64(i) == self {
-			continue
+	b.reconfigure(defaultMembership(N, f))
+	return b
+}
+
+// reconfigure starts sending to the replicas which joined the membership,
+// and stops sending to those which left it. It must not be called
+// concurrently with send.
+func (b *broadcaster) reconfigure(m *Membership) {
+	queueSize := 10 // XXX increase after testing
+
+	b.f = int(m.F)
+
+	members := make(map[uint64]string)
+	for _, r := range m.Replicas {
+		if r.Id != b.self {
+			members[r.Id] = r.Handle
 		}
-		chans[uint64(i)] = make(chan *sendRequest, queueSize)
 	}
 
-	// We do not start the go routines in the above loop to avoid concurrent map read/writes
-	for i := 0; i < N; i++ {
-		if uint64(i) == self {
-			continue
+	for dest, handle := range b.handles {
+		if members[dest] != handle {
+			close(b.stopChans[dest])
+			delete(b.msgChans, dest)
+			delete(b.handles, dest)
+			delete(b.stopChans, dest)
 		}
-		go b.drainer(uint64(i))
 	}
 
-	return b
+	for dest, handle := range members {
+		if _, ok := b.handles[dest]; ok {
+			continue
+		}
+		destChan := make(chan *sendRequest, queueSize)
+		stopChan := make(chan struct{})
+		b.msgChans[dest] = destChan
+		b.handles[dest] = handle
+		b.stopChans[dest] = stopChan
+		go b.drainer(dest, &pb.PeerID{Name: handle}, destChan, stopChan)
+	}
 }
 
 func (b *broadcaster) Close() {
@@ -83,21 +108,13 @@ func (b *broadcaster) Wait() {
 	b.closed.Wait()
 }
 
-func (b *broadcaster) drainerSend(dest uint64, send *sendRequest, successLastTime bool) bool {
+func (b *broadcaster) drainerSend(dest uint64, h *pb.PeerID, send *sendRequest, successLastTime bool) bool {
 	// Note, successLastTime is purely used to avoid flooding the log with unnecessary warning messages when a network problem is encountered
 	defer func() {
 		b.closed.Done()
 	}()
-	h, err := getValidatorHandle(dest)
-	if err != nil {
-		if successLastTime {
-			logger.Warningf("could not get handle for replica %d", dest)
-		}
-		send.done <- false
-		return false
-	}
 
-	err = b.comm.Unicast(send.msg, h)
+	err := b.comm.Unicast(send.msg, h)
 	if err != nil {
 		if successLastTime {
 			logger.Warningf("could not send to replica %d: %v", dest, err)
@@ -111,29 +128,34 @@ func (b *broadcaster) drainerSend(dest uint64, send *sendRequest, successLastTim
 
 }
 
-func (b *broadcaster) drainer(dest uint64) {
+// drainer is passed its channels rather than looking them up, as the maps
+// may be modified by reconfigure while it runs
+func (b *broadcaster) drainer(dest uint64, h *pb.PeerID, destChan chan *sendRequest, stopChan chan struct{}) {
 	successLastTime := false
-	destChan, exsit := b.msgChans[dest] // Avoid doing the map lookup every send
-	if !exsit {
-		logger.Warningf("could not get message channel for replica %d", dest)
-		return
-	}
 
 	for {
 		select {
 		case send := <-destChan:
-			successLastTime = b.drainerSend(dest, send, successLastTime)
+			successLastTime = b.drainerSend(dest, h, send, successLastTime)
 		case <-b.closedCh:
-			for {
-				// Drain the message channel to free calling waiters before we shut down
-				select {
-				case send := <-destChan:
-					send.done <- false
-					b.closed.Done()
-				default:
-					return
-				}
-			}
+			b.drain(destChan)
+			return
+		case <-stopChan:
+			b.drain(destChan)
+			return
+		}
+	}
+}
+
+// drain frees the calling waiters of the messages still queued for a destination
+func (b *broadcaster) drain(destChan chan *sendRequest) {
+	for {
+		select {
+		case send := <-destChan:
+			send.done <- false
+			b.closed.Done()
+		default:
+			return
 		}
 	}
 }
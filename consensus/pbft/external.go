@@ -50,6 +50,11 @@ type committedEvent struct {
 // rolledBackEvent is sent when a requested rollback completes
 type rolledBackEvent struct{}
 
+// reconfigurationEvent is sent when a change of the replica set is requested
+type reconfigurationEvent struct {
+	reconf *Reconfiguration
+}
+
 type externalEventReceiver struct {
 	manager events.Manager
 }
@@ -85,3 +90,13 @@ func (eer *externalEventReceiver) StateUpdated(tag interface{}, target *pb.Block
 		target: target,
 	}
 }
+
+// Reconfigure requests that the given replicas be added to, and the replicas
+// with the given IDs be removed from, the replica set. The request is ordered
+// like any other, and once executed the new replica set takes effect at a
+// checkpoint boundary.
+func (eer *externalEventReceiver) Reconfigure(add []*Replica, remove []uint64) {
+	eer.manager.Queue() <- reconfigurationEvent{
+		reconf: &Reconfiguration{Add: add, Remove: remove},
+	}
+}
@@ -22,6 +22,9 @@ It has these top-level messages:
 	FetchRequestBatch
 	RequestBatch
 	BatchMessage
+	Replica
+	Membership
+	Reconfiguration
 	Metadata
 */
 package pbft
@@ -381,10 +384,11 @@ func _Message_OneofSizer(msg proto.Message) (n int) {
 }
 
 type Request struct {
-	Timestamp *google_protobuf.Timestamp `protobuf:"bytes,1,opt,name=timestamp" json:"timestamp,omitempty"`
-	Payload   []byte                     `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
-	ReplicaId uint64                     `protobuf:"varint,3,opt,name=replica_id,json=replicaId" json:"replica_id,omitempty"`
-	Signature []byte                     `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	Timestamp       *google_protobuf.Timestamp `protobuf:"bytes,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	Payload         []byte                     `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	ReplicaId       uint64                     `protobuf:"varint,3,opt,name=replica_id,json=replicaId" json:"replica_id,omitempty"`
+	Signature       []byte                     `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	Reconfiguration *Reconfiguration           `protobuf:"bytes,5,opt,name=reconfiguration" json:"reconfiguration,omitempty"`
 }
 
 func (m *Request) Reset()                    { *m = Request{} }
@@ -399,6 +403,13 @@ func (m *Request) GetTimestamp() *google_protobuf.Timestamp {
 	return nil
 }
 
+func (m *Request) GetReconfiguration() *Reconfiguration {
+	if m != nil {
+		return m.Reconfiguration
+	}
+	return nil
+}
+
 type PrePrepare struct {
 	View           uint64        `protobuf:"varint,1,opt,name=view" json:"view,omitempty"`
 	SequenceNumber uint64        `protobuf:"varint,2,opt,name=sequence_number,json=sequenceNumber" json:"sequence_number,omitempty"`
@@ -769,14 +780,83 @@ func _BatchMessage_OneofSizer(msg proto.Message) (n int) {
 	return n
 }
 
+type Replica struct {
+	Id     uint64 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Handle string `protobuf:"bytes,2,opt,name=handle" json:"handle,omitempty"`
+}
+
+func (m *Replica) Reset()                    { *m = Replica{} }
+func (m *Replica) String() string            { return proto.CompactTextString(m) }
+func (*Replica) ProtoMessage()               {}
+func (*Replica) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+type Membership struct {
+	Replicas []*Replica `protobuf:"bytes,1,rep,name=replicas" json:"replicas,omitempty"`
+	F        uint64     `protobuf:"varint,2,opt,name=f" json:"f,omitempty"`
+	SeqNo    uint64     `protobuf:"varint,3,opt,name=seqNo" json:"seqNo,omitempty"`
+}
+
+func (m *Membership) Reset()                    { *m = Membership{} }
+func (m *Membership) String() string            { return proto.CompactTextString(m) }
+func (*Membership) ProtoMessage()               {}
+func (*Membership) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *Membership) GetReplicas() []*Replica {
+	if m != nil {
+		return m.Replicas
+	}
+	return nil
+}
+
+type Reconfiguration struct {
+	Add    []*Replica `protobuf:"bytes,1,rep,name=add" json:"add,omitempty"`
+	Remove []uint64   `protobuf:"varint,2,rep,packed,name=remove" json:"remove,omitempty"`
+}
+
+func (m *Reconfiguration) Reset()                    { *m = Reconfiguration{} }
+func (m *Reconfiguration) String() string            { return proto.CompactTextString(m) }
+func (*Reconfiguration) ProtoMessage()               {}
+func (*Reconfiguration) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *Reconfiguration) GetAdd() []*Replica {
+	if m != nil {
+		return m.Add
+	}
+	return nil
+}
+
 type Metadata struct {
-	SeqNo uint64 `protobuf:"varint,1,opt,name=seqNo" json:"seqNo,omitempty"`
+	SeqNo      uint64      `protobuf:"varint,1,opt,name=seqNo" json:"seqNo,omitempty"`
+	Membership *Membership `protobuf:"bytes,2,opt,name=membership" json:"membership,omitempty"`
+	Pending    *Membership `protobuf:"bytes,3,opt,name=pending" json:"pending,omitempty"`
+	Votes      []*Request  `protobuf:"bytes,4,rep,name=votes" json:"votes,omitempty"`
 }
 
 func (m *Metadata) Reset()                    { *m = Metadata{} }
 func (m *Metadata) String() string            { return proto.CompactTextString(m) }
 func (*Metadata) ProtoMessage()               {}
-func (*Metadata) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+func (*Metadata) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *Metadata) GetMembership() *Membership {
+	if m != nil {
+		return m.Membership
+	}
+	return nil
+}
+
+func (m *Metadata) GetPending() *Membership {
+	if m != nil {
+		return m.Pending
+	}
+	return nil
+}
+
+func (m *Metadata) GetVotes() []*Request {
+	if m != nil {
+		return m.Votes
+	}
+	return nil
+}
 
 func init() {
 	proto.RegisterType((*Message)(nil), "pbft.message")
@@ -794,64 +874,77 @@ func init() {
 	proto.RegisterType((*FetchRequestBatch)(nil), "pbft.fetch_request_batch")
 	proto.RegisterType((*RequestBatch)(nil), "pbft.request_batch")
 	proto.RegisterType((*BatchMessage)(nil), "pbft.batch_message")
+	proto.RegisterType((*Replica)(nil), "pbft.replica")
+	proto.RegisterType((*Membership)(nil), "pbft.membership")
+	proto.RegisterType((*Reconfiguration)(nil), "pbft.reconfiguration")
 	proto.RegisterType((*Metadata)(nil), "pbft.metadata")
 }
 
 func init() { proto.RegisterFile("messages.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 848 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xc4, 0x56, 0xcd, 0x6e, 0xdb, 0x46,
-	0x10, 0xee, 0x52, 0x94, 0x64, 0x8e, 0x68, 0xd5, 0x5e, 0xfb, 0xc0, 0x0a, 0x35, 0xea, 0xd2, 0xa8,
-	0x6b, 0xa3, 0x2d, 0x0d, 0xb8, 0x06, 0x6a, 0x18, 0x3d, 0xd9, 0x2d, 0xea, 0xa2, 0xa8, 0x21, 0x13,
-	0x41, 0x92, 0x1b, 0x41, 0x51, 0x2b, 0x89, 0xb0, 0x44, 0xd2, 0x14, 0xe5, 0xc4, 0x2f, 0x90, 0xe4,
-	0x92, 0x17, 0xc8, 0x03, 0xe5, 0x92, 0x73, 0xde, 0x27, 0xbb, 0xc3, 0xa5, 0xf8, 0x23, 0x45, 0xf6,
-	0x25, 0xc8, 0x81, 0xc0, 0xee, 0xcc, 0xf7, 0x0d, 0x67, 0xbf, 0x9d, 0xdd, 0x59, 0x68, 0x4f, 0xd8,
-	0x74, 0xea, 0x0e, 0xd9, 0xd4, 0x8a, 0xe2, 0x30, 0x09, 0xa9, 0x1a, 0xf5, 0x06, 0x49, 0xe7, 0x87,
-	0x61, 0x18, 0x0e, 0xc7, 0xec, 0x08, 0x6d, 0xbd, 0xd9, 0xe0, 0x28, 0xf1, 0x39, 0x2e, 0x71, 0x27,
-	0x51, 0x0a, 0x33, 0x5f, 0xa9, 0xd0, 0x94, 0x4c, 0x7a, 0x06, 0xeb, 0x31, 0xbb, 0x9d, 0x71, 0xbf,
-	0xd3, 0x73, 0x13, 0x6f, 0x64, 0x90, 0x5d, 0x72, 0xd0, 0x3a, 0xde, 0xb2, 0x44, 0x28, 0xab, 0xe4,
-	0xba, 0xfc, 0xc6, 0xd6, 0xa5, 0xe1, 0x5c, 0xcc, 0xe9, 0x09, 0xb4, 0xa2, 0x98, 0x39, 0xfc, 0x8b,
-	0xdc, 0x98, 0x19, 0x0a, 0x32, 0x37, 0x53, 0x66, 0xc1, 0xc1, 0x79, 0xc0, 0x87, 0xdd, 0x74, 0x46,
-	0x0f, 0xa1, 0x99, 0x31, 0x6a, 0xc8, 0x58, 0x9f, 0x33, 0x24, 0x3a, 0xf3, 0xd3, 0x7d, 0x68, 0x78,
-	0xe1, 0x64, 0xe2, 0x27, 0x86, 0x8a, 0x48, 0x3d, 0x45, 0xa6, 0x36, 0x0e, 0x94, 0x5e, 0x7a, 0x0c,
-	0xe0, 0x8d, 0x98, 0x77, 0x13, 0x85, 0x7e, 0x90, 0x18, 0x75, 0xc4, 0x6e, 0x48, 0xec, 0xdc, 0x2e,
-	0xd2, 0xc8, 0x67, 0x22, 0xf9, 0x3b, 0x9f, 0xbd, 0x70, 0xbc, 0x91, 0x1b, 0x0c, 0x99, 0xd1, 0x28,
-	0x26, 0x5f, 0x70, 0x08, 0x96, 0x98, 0x5e, 0xe0, 0x8c, 0xfe, 0x02, 0x6b, 0x01, 0xf7, 0x09, 0x8b,
-	0xd1, 0x44, 0x4a, 0x3b, 0xa5, 0x64, 0x56, 0x91, 0x3e, 0x1f, 0x3f, 0xe5, 0x43, 0xfa, 0x1f, 0x6c,
-	0x0d, 0x18, 0x17, 0xca, 0x29, 0x2b, 0xbc, 0x86, 0xbc, 0xef, 0x52, 0xde, 0x12, 0x00, 0x0f, 0xb1,
-	0x89, 0x66, 0xbb, 0x28, 0xf6, 0x3f, 0xb0, 0x1d, 0xb3, 0x64, 0x16, 0x07, 0x95, 0x68, 0xda, 0xaa,
-	0xfd, 0xa2, 0x29, 0xa5, 0x18, 0xe8, 0x5c, 0xe3, 0xfa, 0xbb, 0xf7, 0xe3, 0xd0, 0xed, 0x9b, 0xef,
-	0x08, 0x34, 0x25, 0x85, 0x9e, 0x82, 0x36, 0xaf, 0x13, 0x59, 0x04, 0x1d, 0x2b, 0xad, 0x24, 0x2b,
-	0xab, 0x24, 0xeb, 0x49, 0x86, 0xb0, 0x73, 0x30, 0x35, 0xe6, 0x01, 0xb1, 0x04, 0x74, 0x3b, 0x9b,
-	0xd2, 0x1d, 0x00, 0xbe, 0x93, 0x63, 0xdf, 0x73, 0x1d, 0xbf, 0x8f, 0xbb, 0xad, 0xda, 0x9a, 0xb4,
-	0xfc, 0xdb, 0xa7, 0xdf, 0x83, 0x36, 0xf5, 0x87, 0x81, 0xcb, 0x53, 0x64, 0xb8, 0xc3, 0xba, 0x9d,
-	0x1b, 0xcc, 0xf7, 0xa4, 0x54, 0x5e, 0x94, 0x82, 0x8a, 0xb2, 0x13, 0x0c, 0x83, 0x63, 0xfa, 0x33,
-	0x7c, 0x3b, 0x15, 0xf9, 0x07, 0x1e, 0x73, 0x82, 0xd9, 0xa4, 0xc7, 0x62, 0x4c, 0x41, 0xb5, 0xdb,
-	0x99, 0xf9, 0x0a, 0xad, 0xf4, 0x47, 0xd0, 0x51, 0x13, 0xa7, 0xef, 0xf3, 0xe3, 0x92, 0x60, 0x2e,
-	0x9a, 0xdd, 0x42, 0xdb, 0x5f, 0x68, 0xe2, 0x02, 0x54, 0x4e, 0x82, 0xfa, 0x59, 0x65, 0x2b, 0xe7,
-	0xa0, 0xbc, 0xcc, 0x7a, 0x65, 0x99, 0xe6, 0x1b, 0x32, 0xaf, 0xf8, 0x2f, 0xbe, 0x88, 0x72, 0x2a,
-	0x6a, 0x35, 0x95, 0xd7, 0x24, 0x3b, 0x51, 0x5f, 0x3b, 0x93, 0x2b, 0x80, 0xde, 0x38, 0xf4, 0x6e,
-	0x1c, 0x3f, 0x18, 0x84, 0x18, 0x0f, 0x67, 0xf2, 0xaf, 0x69, 0x52, 0x2d, 0xb4, 0xc9, 0x5f, 0xee,
-	0x64, 0x84, 0x91, 0x3b, 0x1d, 0xc9, 0x42, 0xd3, 0xd0, 0x72, 0xc9, 0x0d, 0x66, 0xbf, 0x78, 0x05,
-	0x2c, 0x5b, 0x08, 0x59, 0xba, 0x90, 0x72, 0x96, 0x4a, 0xb5, 0x42, 0xdb, 0xa0, 0xc8, 0xc2, 0xd5,
-	0x6c, 0x3e, 0x32, 0xdf, 0xd6, 0x4a, 0xb7, 0xc6, 0x52, 0x11, 0x75, 0x20, 0x23, 0x19, 0x89, 0x8c,
-	0x78, 0x26, 0xaa, 0x37, 0x65, 0x42, 0xa1, 0x5a, 0x5e, 0x4c, 0x85, 0x10, 0xd6, 0x85, 0x8d, 0x00,
-	0x7a, 0x00, 0x6a, 0x24, 0x80, 0x2a, 0x02, 0xb7, 0x17, 0x81, 0xdd, 0x6b, 0x1b, 0x11, 0x02, 0x79,
-	0x2b, 0x90, 0xf5, 0x55, 0x48, 0x81, 0xa8, 0xac, 0xae, 0xb1, 0xf2, 0xfc, 0x35, 0x2b, 0xe7, 0xaf,
-	0xf3, 0x27, 0x90, 0x8b, 0xc7, 0x0b, 0x59, 0x51, 0xaa, 0xd3, 0x07, 0xa5, 0x7b, 0xfd, 0x78, 0x7a,
-	0xb5, 0xa0, 0x94, 0xc5, 0x82, 0xca, 0xb4, 0xae, 0xe5, 0x5a, 0x9b, 0x47, 0x50, 0xef, 0x5e, 0x8b,
-	0x95, 0xee, 0x43, 0x4d, 0x48, 0x42, 0x56, 0x48, 0x22, 0x00, 0xe6, 0x07, 0x92, 0x5f, 0xe0, 0x4b,
-	0x77, 0xef, 0x27, 0x6e, 0x13, 0x91, 0x14, 0x8c, 0xb4, 0xd8, 0x0f, 0x6c, 0x74, 0xd3, 0x5f, 0x41,
-	0x7d, 0x99, 0x6f, 0xab, 0x51, 0xee, 0x01, 0xd6, 0x73, 0xee, 0xfa, 0x3b, 0x48, 0xe2, 0x7b, 0x1b,
-	0x51, 0x0f, 0x9c, 0x85, 0xce, 0x1f, 0xa0, 0xcd, 0x19, 0x74, 0x03, 0x6a, 0x37, 0xec, 0x5e, 0xe6,
-	0x24, 0x86, 0x74, 0x1b, 0xea, 0x77, 0xee, 0x78, 0xc6, 0xa4, 0x28, 0xe9, 0xe4, 0x4c, 0x39, 0x25,
-	0xe6, 0xb3, 0xa5, 0x0d, 0x66, 0x41, 0x4c, 0xf2, 0xd0, 0xe9, 0xac, 0xd6, 0xbd, 0x79, 0x52, 0xb9,
-	0x0b, 0xe9, 0x1e, 0xd4, 0xb3, 0xe7, 0x41, 0x2d, 0x6f, 0xd9, 0x12, 0x63, 0xa7, 0x3e, 0xf3, 0x23,
-	0x81, 0xf5, 0xf4, 0xc7, 0xd9, 0xeb, 0xe2, 0x70, 0xde, 0x5f, 0x64, 0x4b, 0x29, 0x13, 0x45, 0xb3,
-	0xcc, 0xfa, 0xcf, 0xc2, 0x43, 0x44, 0x79, 0xfc, 0x43, 0x64, 0x0f, 0x74, 0x81, 0xca, 0x7e, 0x8b,
-	0x25, 0xa2, 0x73, 0x54, 0x4b, 0x58, 0xff, 0x97, 0xb9, 0xfc, 0x06, 0x1a, 0xbf, 0xfa, 0xa2, 0xb1,
-	0x2b, 0xde, 0x08, 0xea, 0xf2, 0x6c, 0x72, 0x44, 0xb1, 0x4d, 0xee, 0xc2, 0xda, 0x84, 0x25, 0x6e,
-	0xdf, 0x4d, 0x5c, 0xb1, 0x19, 0xbc, 0x74, 0xaf, 0x42, 0xb9, 0x41, 0xe9, 0xa4, 0xd7, 0xc0, 0x0e,
-	0xf9, 0xfb, 0xa7, 0x00, 0x00, 0x00, 0xff, 0xff, 0x18, 0x21, 0x64, 0x8c, 0x91, 0x09, 0x00, 0x00,
+	// 1001 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x56, 0xdd, 0x8e, 0xdb, 0x44,
+	0x14, 0xce, 0xd8, 0x93, 0x64, 0x7d, 0x92, 0xdd, 0xb6, 0xd3, 0x05, 0x99, 0x88, 0xaa, 0xc5, 0x15,
+	0x6d, 0x97, 0x9f, 0x2c, 0x94, 0x4a, 0xac, 0x2a, 0x24, 0xa4, 0x2e, 0x88, 0x00, 0x62, 0xb5, 0x3b,
+	0x42, 0x80, 0xc4, 0x45, 0x34, 0xb1, 0x27, 0xb1, 0xb5, 0xf1, 0xcf, 0xda, 0x93, 0x6c, 0xf7, 0x05,
+	0x80, 0x1b, 0x9e, 0x82, 0x77, 0xe1, 0x86, 0x6b, 0xae, 0x78, 0x19, 0x34, 0xe3, 0x71, 0xfc, 0x13,
+	0x77, 0xd9, 0x2b, 0x7a, 0xe7, 0x73, 0xce, 0x77, 0xce, 0x9c, 0x9f, 0x6f, 0x7c, 0x06, 0xf6, 0x42,
+	0x9e, 0x65, 0x6c, 0xc1, 0xb3, 0x71, 0x92, 0xc6, 0x22, 0x26, 0x38, 0x99, 0xcd, 0xc5, 0xe8, 0xfe,
+	0x22, 0x8e, 0x17, 0x4b, 0x7e, 0xa8, 0x74, 0xb3, 0xd5, 0xfc, 0x50, 0x04, 0x21, 0xcf, 0x04, 0x0b,
+	0x93, 0x1c, 0xe6, 0xfc, 0x82, 0xa1, 0xaf, 0x3d, 0xc9, 0x73, 0xd8, 0x4d, 0xf9, 0xc5, 0x8a, 0x67,
+	0x62, 0x3a, 0x63, 0xc2, 0xf5, 0x6d, 0xf4, 0x00, 0x3d, 0x19, 0x3c, 0xbd, 0x3b, 0x96, 0xa1, 0xc6,
+	0x35, 0xd3, 0xa4, 0x43, 0x87, 0x5a, 0xf1, 0x42, 0xca, 0xe4, 0x19, 0x0c, 0x92, 0x94, 0x4f, 0x93,
+	0x94, 0x27, 0x2c, 0xe5, 0xb6, 0xa1, 0x3c, 0xef, 0xe4, 0x9e, 0x15, 0xc3, 0xa4, 0x43, 0x21, 0x49,
+	0xf9, 0x69, 0x2e, 0x91, 0x03, 0xe8, 0x17, 0x1e, 0xa6, 0xf2, 0xd8, 0xdd, 0x78, 0x68, 0x74, 0x61,
+	0x27, 0x8f, 0xa0, 0xe7, 0xc6, 0x61, 0x18, 0x08, 0x1b, 0x2b, 0xe4, 0x30, 0x47, 0xe6, 0xba, 0x49,
+	0x87, 0x6a, 0x2b, 0x79, 0x0a, 0xe0, 0xfa, 0xdc, 0x3d, 0x4f, 0xe2, 0x20, 0x12, 0x76, 0x57, 0x61,
+	0x6f, 0x6b, 0xec, 0x46, 0x2f, 0xd3, 0x28, 0x25, 0x99, 0xfc, 0x3a, 0xe0, 0x97, 0x53, 0xd7, 0x67,
+	0xd1, 0x82, 0xdb, 0xbd, 0x6a, 0xf2, 0x15, 0x83, 0xf4, 0x92, 0xe2, 0xb1, 0x92, 0xc8, 0xfb, 0xb0,
+	0x13, 0xf1, 0xcb, 0xa9, 0xd4, 0xd8, 0x7d, 0xe5, 0xb2, 0x97, 0xbb, 0x14, 0x5a, 0x99, 0x7e, 0xc4,
+	0x2f, 0x7f, 0x08, 0xf8, 0x25, 0xf9, 0x16, 0xee, 0xce, 0xb9, 0x70, 0xfd, 0x69, 0xbd, 0xc3, 0x3b,
+	0xca, 0xef, 0xad, 0xdc, 0xaf, 0x05, 0x30, 0xe9, 0xd0, 0x3b, 0x4a, 0x4d, 0xab, 0xcd, 0xfe, 0x0a,
+	0xf6, 0x53, 0x2e, 0x56, 0x69, 0xd4, 0x88, 0x66, 0x5d, 0x37, 0x2f, 0x92, 0xbb, 0x54, 0x03, 0xbd,
+	0xb0, 0xa0, 0x9f, 0xb0, 0xab, 0x65, 0xcc, 0x3c, 0xe7, 0x1f, 0x04, 0x7d, 0xed, 0x42, 0x8e, 0xc0,
+	0xda, 0xf0, 0x44, 0x93, 0x60, 0x34, 0xce, 0x99, 0x34, 0x2e, 0x98, 0x34, 0xfe, 0xbe, 0x40, 0xd0,
+	0x12, 0x4c, 0xec, 0x4d, 0x40, 0x45, 0x81, 0x21, 0x2d, 0x44, 0x72, 0x0f, 0x20, 0xe5, 0xc9, 0x32,
+	0x70, 0xd9, 0x34, 0xf0, 0xd4, 0xb4, 0x31, 0xb5, 0xb4, 0xe6, 0x6b, 0x8f, 0xbc, 0x0d, 0x56, 0x16,
+	0x2c, 0x22, 0x26, 0x56, 0x29, 0x57, 0x13, 0x1e, 0xd2, 0x52, 0x41, 0x3e, 0x87, 0x5b, 0x29, 0x77,
+	0xe3, 0x68, 0x1e, 0x2c, 0x56, 0x29, 0x13, 0x41, 0x1c, 0xe9, 0xc9, 0xbe, 0x51, 0xd4, 0x5a, 0x33,
+	0xd2, 0x26, 0xda, 0xf9, 0x13, 0xd5, 0xf8, 0x49, 0x08, 0x60, 0x35, 0x37, 0xa4, 0xf2, 0x50, 0xdf,
+	0xe4, 0x31, 0xdc, 0xca, 0x64, 0x03, 0x22, 0x97, 0x4f, 0xa3, 0x55, 0x38, 0xe3, 0xa9, 0xaa, 0x01,
+	0xd3, 0xbd, 0x42, 0x7d, 0xa2, 0xb4, 0xe4, 0x1d, 0x18, 0xaa, 0xa6, 0x4e, 0xbd, 0x60, 0xc1, 0x33,
+	0xa1, 0x8a, 0xb1, 0xe8, 0x40, 0xe9, 0xbe, 0x50, 0x2a, 0x72, 0xd4, 0xbc, 0x4a, 0xf8, 0x95, 0xa3,
+	0x69, 0x5c, 0xa4, 0x7a, 0x9f, 0xba, 0x8d, 0x3e, 0x39, 0xbf, 0x21, 0xe8, 0xff, 0x5f, 0x45, 0xd4,
+	0x53, 0xc1, 0xcd, 0x54, 0x7e, 0x45, 0xc5, 0x95, 0x7c, 0xdd, 0x99, 0x9c, 0x00, 0xcc, 0x96, 0xb1,
+	0x7b, 0x3e, 0x0d, 0xa2, 0x79, 0xac, 0xe2, 0x29, 0x49, 0x9f, 0x9a, 0x27, 0x35, 0x50, 0x3a, 0x7d,
+	0xe4, 0xbd, 0xc2, 0xc1, 0x67, 0x99, 0xaf, 0x99, 0x6a, 0x29, 0xcd, 0x84, 0x65, 0xbe, 0xe3, 0x55,
+	0xff, 0x21, 0x6d, 0x85, 0xa0, 0xd6, 0x42, 0xea, 0x59, 0x1a, 0x4d, 0x8a, 0xef, 0x81, 0xa1, 0x99,
+	0x6f, 0x51, 0x23, 0xf0, 0x9c, 0xdf, 0xcd, 0xda, 0x6f, 0xa7, 0xb5, 0x89, 0x43, 0x40, 0xbe, 0x8e,
+	0x84, 0x7c, 0xf2, 0x18, 0xb0, 0x9b, 0x71, 0xd9, 0x21, 0xb3, 0x24, 0x53, 0x25, 0xc4, 0xf8, 0x98,
+	0x2a, 0x00, 0x79, 0x02, 0x38, 0x91, 0x40, 0xac, 0x80, 0xfb, 0xdb, 0xc0, 0xd3, 0x33, 0x8a, 0x13,
+	0x8d, 0xbc, 0x90, 0xc8, 0xee, 0x75, 0x48, 0x89, 0x68, 0x54, 0xd7, 0xbb, 0xf6, 0x02, 0xf7, 0x1b,
+	0x17, 0x78, 0xf4, 0x19, 0xa0, 0xe3, 0x9b, 0x37, 0xb2, 0xd1, 0xa9, 0x91, 0x07, 0xc6, 0xe9, 0xd9,
+	0xcd, 0xdd, 0x9b, 0x84, 0x32, 0xb6, 0x09, 0x55, 0xf4, 0xda, 0x2c, 0x7b, 0xed, 0x1c, 0x42, 0xf7,
+	0xf4, 0x4c, 0x56, 0xfa, 0x08, 0x4c, 0xd9, 0x12, 0x74, 0x4d, 0x4b, 0x24, 0xc0, 0xf9, 0x0b, 0x95,
+	0x1b, 0xa0, 0x75, 0x7a, 0xef, 0x02, 0x5e, 0xcb, 0x48, 0xc6, 0x03, 0xb3, 0x75, 0xa1, 0x50, 0x65,
+	0x26, 0x1f, 0x00, 0x7e, 0x59, 0x8e, 0xd5, 0xae, 0x2f, 0x91, 0xf1, 0x4f, 0x19, 0x17, 0x5f, 0x46,
+	0x22, 0xbd, 0xa2, 0xf8, 0xe5, 0xf6, 0x1c, 0x9a, 0x77, 0x61, 0xf4, 0x29, 0x58, 0x1b, 0x0f, 0x72,
+	0x1b, 0xcc, 0x73, 0x7e, 0xa5, 0x73, 0x92, 0x9f, 0x64, 0x1f, 0xba, 0x6b, 0xb6, 0x5c, 0x71, 0xdd,
+	0x94, 0x5c, 0x78, 0x6e, 0x1c, 0x21, 0xe7, 0xc7, 0xd6, 0x0d, 0xb5, 0xd5, 0x4c, 0xf4, 0x5f, 0xb7,
+	0xb3, 0xc9, 0x7b, 0xe7, 0x59, 0xe3, 0x5f, 0x48, 0x1e, 0x42, 0xb7, 0x78, 0x5f, 0x98, 0xe5, 0xce,
+	0xd7, 0x18, 0x9a, 0xdb, 0x9c, 0xbf, 0x11, 0xec, 0xe6, 0x07, 0x17, 0xcf, 0x93, 0x83, 0xcd, 0x82,
+	0xd2, 0x3b, 0xa9, 0xee, 0x28, 0xb7, 0xad, 0xfe, 0xdc, 0x7e, 0xc9, 0x18, 0x37, 0x7f, 0xc9, 0x3c,
+	0x84, 0xa1, 0x44, 0x15, 0xc7, 0x2a, 0x8a, 0x0c, 0x27, 0x1d, 0x3a, 0x90, 0xda, 0xef, 0x74, 0x2e,
+	0x1f, 0x82, 0xe5, 0xc6, 0x61, 0xb2, 0x64, 0xf2, 0x91, 0x81, 0xdb, 0xb3, 0x29, 0x11, 0xd5, 0x3d,
+	0xfb, 0x31, 0xf4, 0x75, 0x6b, 0x34, 0xcd, 0xf3, 0xe1, 0x18, 0x81, 0x47, 0xde, 0x84, 0x9e, 0xcf,
+	0x22, 0x6f, 0x59, 0x0c, 0x47, 0x4b, 0xce, 0xcf, 0x00, 0x21, 0x97, 0xcc, 0xce, 0xfc, 0x20, 0x21,
+	0x07, 0xb0, 0xa3, 0x03, 0x64, 0xcd, 0x06, 0x2a, 0x2d, 0xdd, 0x98, 0xe5, 0xdf, 0x63, 0x5e, 0xfc,
+	0x3d, 0xe6, 0x72, 0xf4, 0x19, 0xbf, 0x38, 0x89, 0x35, 0xe9, 0x73, 0xc1, 0xf9, 0x66, 0x6b, 0xb5,
+	0x92, 0xfb, 0x60, 0x32, 0xcf, 0x6b, 0x0f, 0x2e, 0x2d, 0x32, 0xd1, 0x94, 0x87, 0xf1, 0x9a, 0x2b,
+	0x66, 0x63, 0xaa, 0x25, 0xe7, 0x0f, 0x04, 0x3b, 0x21, 0x17, 0xcc, 0x63, 0x82, 0x95, 0xc7, 0xa1,
+	0xca, 0x71, 0xe4, 0xa3, 0x6a, 0x2d, 0xb6, 0x51, 0x7d, 0x9e, 0x95, 0x7a, 0x5a, 0xad, 0xf7, 0x3d,
+	0xe8, 0x27, 0x3c, 0xf2, 0x82, 0x68, 0x61, 0x9b, 0xaf, 0x80, 0x17, 0x00, 0xc9, 0xac, 0x75, 0x2c,
+	0x78, 0x66, 0xe3, 0x7a, 0xee, 0x9a, 0x59, 0xca, 0x36, 0xeb, 0xa9, 0x27, 0xcc, 0x27, 0xff, 0x0e,
+	0x00, 0x4c, 0x8f, 0x33, 0x21, 0x32, 0x0b, 0x00, 0x00,
 }
@@ -96,7 +96,7 @@ type MockLedger struct {
 	mutex *sync.Mutex
 
 	txID          interface{}
-	curBatch      []*protos.Transaction
+	curBatch      []*protos.InBlockTransaction
 	curResults    []byte
 	preBatchState uint64
 
@@ -124,7 +124,7 @@ func (mock *MockLedger) BeginTxBatch(id interface{}) error {
 	return nil
 }
 
-func (mock *MockLedger) Execute(tag interface{}, txs []*protos.Transaction) {
+func (mock *MockLedger) Execute(tag interface{}, txs []*protos.InBlockTransaction) {
 	go func() {
 		if mock.txID == nil {
 			mock.BeginTxBatch(mock)
@@ -155,7 +155,7 @@ func (mock *MockLedger) Rollback(tag interface{}) {
 	}()
 }
 
-func (mock *MockLedger) ExecTxs(id interface{}, txs []*protos.Transaction) ([]byte, error) {
+func (mock *MockLedger) ExecTxs(id interface{}, txs []*protos.InBlockTransaction) ([]byte, error) {
 	if !reflect.DeepEqual(mock.txID, id) {
 		return nil, fmt.Errorf("Invalid batch ID")
 	}
@@ -168,15 +168,15 @@ func (mock *MockLedger) ExecTxs(id interface{}, txs []*protos.Transaction) ([]by
 	} else {
 		// This is basically a default fake default transaction execution
 		if nil == txs {
-			txs = []*protos.Transaction{{Payload: []byte("DUMMY")}}
+			txs = []*protos.InBlockTransaction{{Txid: "DUMMY"}}
 		}
 
 		for _, transaction := range txs {
-			if transaction.Payload == nil {
-				transaction.Payload = []byte("DUMMY")
+			if transaction.Txid == "" {
+				transaction.Txid = "DUMMY"
 			}
 
-			txResult = append(txResult, transaction.Payload...)
+			txResult = append(txResult, transaction.Txid...)
 		}
 
 	}
@@ -103,16 +103,16 @@ func createRunningPbftWithManager(id uint64, config *viper.Viper, stack innerSta
 	return core, manager
 }
 
-func createTx(tag int64) (tx *pb.Transaction) {
+func createTx(tag int64) (tx *pb.InBlockTransaction) {
 	txTime := &timestamp.Timestamp{Seconds: tag, Nanos: 0}
-	tx = &pb.Transaction{Type: pb.ChaincodeAction_CHAINCODE_DEPLOY,
+	tx = &pb.InBlockTransaction{
 		Timestamp: txTime,
-		Payload:   []byte(fmt.Sprint(tag)),
+		Txid:      fmt.Sprint(tag),
 	}
 	return
 }
 
-func marshalTx(tx *pb.Transaction) (txPacked []byte) {
+func marshalTx(tx *pb.InBlockTransaction) (txPacked []byte) {
 	txPacked, _ = proto.Marshal(tx)
 	return
 }
@@ -178,12 +178,12 @@ type omniProto struct {
 	CommitStateDeltaImpl       func(id interface{}) error
 	RollbackStateDeltaImpl     func(id interface{}) error
 	EmptyStateImpl             func() error
-	ExecuteImpl                func(id interface{}, txs []*pb.Transaction)
+	ExecuteImpl                func(id interface{}, txs []*pb.InBlockTransaction)
 	CommitImpl                 func(id interface{}, meta []byte)
 	RollbackImpl               func(id interface{})
 	UpdateStateImpl            func(id interface{}, target *pb.BlockchainInfo, peers []*pb.PeerID)
 	BeginTxBatchImpl           func(id interface{}) error
-	ExecTxsImpl                func(id interface{}, txs []*pb.Transaction) ([]byte, error)
+	ExecTxsImpl                func(id interface{}, txs []*pb.InBlockTransaction) ([]byte, error)
 	CommitTxBatchImpl          func(id interface{}, metadata []byte) (*pb.Block, error)
 	RollbackTxBatchImpl        func(id interface{}) error
 	PreviewCommitTxBatchImpl   func(id interface{}, metadata []byte) ([]byte, error)
@@ -198,17 +198,20 @@ type omniProto struct {
 	InvalidateStateImpl        func()
 
 	// Inner Stack methods
-	broadcastImpl       func(msgPayload []byte)
-	unicastImpl         func(msgPayload []byte, receiverID uint64) (err error)
-	executeImpl         func(seqNo uint64, reqBatch *RequestBatch)
-	getStateImpl        func() []byte
-	skipToImpl          func(seqNo uint64, snapshotID []byte, peers []uint64)
-	viewChangeImpl      func(curView uint64)
-	signImpl            func(msg []byte) ([]byte, error)
-	verifyImpl          func(senderID uint64, signature []byte, message []byte) error
-	getLastSeqNoImpl    func() (uint64, error)
-	validateStateImpl   func()
-	invalidateStateImpl func()
+	broadcastImpl         func(msgPayload []byte)
+	unicastImpl           func(msgPayload []byte, receiverID uint64) (err error)
+	executeImpl           func(seqNo uint64, reqBatch *RequestBatch)
+	getStateImpl          func() []byte
+	skipToImpl            func(seqNo uint64, snapshotID []byte, peers []uint64)
+	viewChangeImpl        func(curView uint64)
+	signImpl              func(msg []byte) ([]byte, error)
+	verifyImpl            func(senderID uint64, signature []byte, message []byte) error
+	getLastSeqNoImpl      func() (uint64, error)
+	getLastMembershipImpl func() (*Membership, *Membership, error)
+	getLastVotesImpl      func() ([]*Request, error)
+	reconfigureImpl       func(membership *Membership)
+	validateStateImpl     func()
+	invalidateStateImpl   func()
 
 	// Closable Consenter methods
 	RecvMsgImpl func(ocMsg *pb.Message, senderHandle *pb.PeerID) error
@@ -360,7 +363,7 @@ func (op *omniProto) BeginTxBatch(id interface{}) error {
 
 	panic("Unimplemented")
 }
-func (op *omniProto) ExecTxs(id interface{}, txs []*pb.Transaction) ([]byte, error) {
+func (op *omniProto) ExecTxs(id interface{}, txs []*pb.InBlockTransaction) ([]byte, error) {
 	if nil != op.ExecTxsImpl {
 		return op.ExecTxsImpl(id, txs)
 	}
@@ -480,6 +483,28 @@ func (op *omniProto) getLastSeqNo() (uint64, error) {
 	return 0, fmt.Errorf("getLastSeqNo is not implemented")
 }
 
+func (op *omniProto) getLastMembership() (*Membership, *Membership, error) {
+	if op.getLastMembershipImpl != nil {
+		return op.getLastMembershipImpl()
+	}
+
+	return nil, nil, fmt.Errorf("getLastMembership is not implemented")
+}
+
+func (op *omniProto) getLastReconfigurationVotes() ([]*Request, error) {
+	if op.getLastVotesImpl != nil {
+		return op.getLastVotesImpl()
+	}
+
+	return nil, fmt.Errorf("getLastReconfigurationVotes is not implemented")
+}
+
+func (op *omniProto) reconfigure(membership *Membership) {
+	if op.reconfigureImpl != nil {
+		op.reconfigureImpl(membership)
+	}
+}
+
 func (op *omniProto) Close() {
 	if nil != op.CloseImpl {
 		op.CloseImpl()
@@ -602,7 +627,7 @@ func (op *omniProto) Rollback(tag interface{}) {
 	}
 	panic("unimplemented")
 }
-func (op *omniProto) Execute(tag interface{}, txs []*pb.Transaction) {
+func (op *omniProto) Execute(tag interface{}, txs []*pb.InBlockTransaction) {
 	if nil != op.ExecuteImpl {
 		op.ExecuteImpl(tag, txs)
 		return
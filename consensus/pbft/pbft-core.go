@@ -86,6 +86,8 @@ type innerStack interface {
 	execute(seqNo uint64, reqBatch *RequestBatch) // This is invoked on a separate thread
 	getState() []byte
 	getLastSeqNo() (uint64, error)
+	getLastMembership() (membership *Membership, pending *Membership, err error)
+	getLastReconfigurationVotes() ([]*Request, error)
 	skipTo(seqNo uint64, snapshotID []byte, peers []uint64)
 
 	sign(msg []byte) ([]byte, error)
@@ -94,6 +96,8 @@ type innerStack interface {
 	invalidateState()
 	validateState()
 
+	reconfigure(membership *Membership) // Invoked whenever the replica set changes
+
 	consensus.StatePersistor
 }
 
@@ -135,12 +139,18 @@ type pbftCore struct {
 	L             uint64            // log size
 	lastExec      uint64            // last request we executed
 	replicaCount  int               // number of replicas; PBFT `|R|`
+	replicas      map[uint64]string // replica ID to peer handle name, for the current membership
+	replicaIDs    []uint64          // sorted IDs of the current membership, used to pick the primary
 	seqNo         uint64            // PBFT "n", strictly monotonic increasing sequence number
 	view          uint64            // current view
 	chkpts        map[uint64]string // state checkpoints; map lastExec to global hash
 	pset          map[uint64]*ViewChange_PQ
 	qset          map[qidx]*ViewChange_PQ
 
+	membership           *Membership // current replica set
+	pendingMembership    *Membership // replica set which takes effect once its seqNo is executed
+	reconfigurationVotes []*Request  // signed reconfiguration requests of distinct members, awaiting a quorum
+
 	skipInProgress    bool               // Set when we have detected a fall behind scenario until we pick a new starting point
 	stateTransferring bool               // Set when state transfer is executing
 	highStateTarget   *stateUpdateTarget // Set to the highest weak checkpoint cert we have observed
@@ -156,6 +166,7 @@ type pbftCore struct {
 	newViewTimerReason    string                   // what triggered the timer
 	lastNewViewTimeout    time.Duration            // last timeout we used during this view change
 	broadcastTimeout      time.Duration            // progress timeout for broadcast
+	maxTimestampSkew      time.Duration            // how far ahead of the local clock a transaction may be stamped
 	outstandingReqBatches map[string]*RequestBatch // track whether we are waiting for request batches to execute
 
 	nullRequestTimer   events.Timer  // timeout triggering a null request
@@ -260,6 +271,10 @@ func newPbftCore(id uint64, config *viper.Viper, consumer innerStack, etf events
 	if err != nil {
 		panic(fmt.Errorf("Cannot parse new broadcast timeout: %s", err))
 	}
+	instance.maxTimestampSkew, err = time.ParseDuration(config.GetString("general.timeout.timestampskew"))
+	if err != nil {
+		instance.maxTimestampSkew = 0
+	}
 
 	instance.activeView = true
 	instance.replicaCount = instance.N
@@ -272,6 +287,11 @@ func newPbftCore(id uint64, config *viper.Viper, consumer innerStack, etf events
 	logger.Infof("PBFT view change timeout = %v", instance.newViewTimeout)
 	logger.Infof("PBFT Checkpoint period (K) = %v", instance.K)
 	logger.Infof("PBFT broadcast timeout = %v", instance.broadcastTimeout)
+	if instance.maxTimestampSkew > 0 {
+		logger.Infof("PBFT max timestamp skew = %v", instance.maxTimestampSkew)
+	} else {
+		logger.Infof("PBFT timestamp skew check disabled")
+	}
 	logger.Infof("PBFT Log multiplier = %v", instance.logMultiplier)
 	logger.Infof("PBFT log size (L) = %v", instance.L)
 	if instance.nullRequestTimeout > 0 {
@@ -379,6 +399,7 @@ func (instance *pbftCore) ProcessEvent(e events.Event) events.Event {
 		// XXX create checkpoint
 		instance.lastExec = update.seqNo
 		instance.moveWatermarks(instance.lastExec) // The watermark movement handles moving this to a checkpoint boundary
+		instance.refreshMembership()
 		instance.skipInProgress = false
 		instance.consumer.validateState()
 		instance.executeOutstanding()
@@ -426,7 +447,7 @@ func (instance *pbftCore) ProcessEvent(e events.Event) events.Event {
 
 // Given a certain view n, what is the expected primary?
 func (instance *pbftCore) primary(n uint64) uint64 {
-	return n % uint64(instance.replicaCount)
+	return instance.replicaIDs[n%uint64(len(instance.replicaIDs))]
 }
 
 // Is the sequence number between watermarks?
@@ -651,6 +672,11 @@ func (instance *pbftCore) sendPrePrepare(reqBatch *RequestBatch, digest string)
 		return
 	}
 
+	if instance.pendingMembership != nil && n > instance.pendingMembership.SeqNo {
+		logger.Infof("Primary %d waiting for the reconfiguration at seqNo %d, not sending pre-prepare with seqNo=%d", instance.id, instance.pendingMembership.SeqNo, n)
+		return
+	}
+
 	logger.Debugf("Primary %d broadcasting pre-prepare for view=%d/seqNo=%d and digest %s", instance.id, instance.view, n, digest)
 	instance.seqNo = n
 	preprep := &PrePrepare{
@@ -729,6 +755,23 @@ func (instance *pbftCore) recvPrePrepare(preprep *PrePrepare) error {
 		return nil
 	}
 
+	if instance.pendingMembership != nil && preprep.SequenceNumber > instance.pendingMembership.SeqNo {
+		logger.Warningf("Replica %d ignoring pre-prepare for seqNo=%d, beyond the reconfiguration at seqNo %d", instance.id, preprep.SequenceNumber, instance.pendingMembership.SeqNo)
+		return nil
+	}
+
+	reqBatch := preprep.GetRequestBatch()
+	if stored, ok := instance.reqBatchStore[preprep.BatchDigest]; ok {
+		reqBatch = stored
+	}
+	for _, req := range reqBatch.GetBatch() {
+		if isAheadOfClock(req, instance.maxTimestampSkew) {
+			logger.Warningf("Replica %d received pre-prepare for a batch carrying a transaction stamped more than %v ahead of its clock", instance.id, instance.maxTimestampSkew)
+			instance.sendViewChange()
+			return nil
+		}
+	}
+
 	cert := instance.getCert(preprep.View, preprep.SequenceNumber)
 	if cert.digest != "" && cert.digest != preprep.BatchDigest {
 		logger.Warningf("Pre-prepare found for same view/seqNo but different digest: received %s, stored %s", preprep.BatchDigest, cert.digest)
@@ -996,6 +1039,9 @@ func (instance *pbftCore) execDoneSync() {
 		if instance.lastExec%instance.K == 0 {
 			instance.Checkpoint(instance.lastExec, instance.consumer.getState())
 		}
+		if instance.maybeApplyPending() {
+			instance.resubmitRequestBatches()
+		}
 
 	} else {
 		// XXX This masks a bug, this should not be called when currentExec is nil
@@ -1285,12 +1331,12 @@ func (instance *pbftCore) innerBroadcast(msg *Message) error {
 	// testing byzantine fault.
 	if doByzantine {
 		rand2 := rand.New(rand.NewSource(time.Now().UnixNano()))
-		ignoreidx := rand2.Intn(instance.N)
-		for i := 0; i < instance.N; i++ {
-			if i != ignoreidx && uint64(i) != instance.id { //Pick a random replica and do not send message
-				instance.consumer.unicast(msgRaw, uint64(i))
+		ignoreidx := rand2.Intn(len(instance.replicaIDs))
+		for i, id := range instance.replicaIDs {
+			if i != ignoreidx && id != instance.id { //Pick a random replica and do not send message
+				instance.consumer.unicast(msgRaw, id)
 			} else {
-				logger.Debugf("PBFT byzantine: not broadcasting to replica %v", i)
+				logger.Debugf("PBFT byzantine: not broadcasting to replica %v", id)
 			}
 		}
 	} else {
@@ -1314,6 +1360,11 @@ func (instance *pbftCore) startTimerIfOutstandingRequests() {
 		return
 	}
 
+	if !instance.isMember() {
+		// A replica outside of the replica set has no primary to hold to account
+		return
+	}
+
 	if len(instance.outstandingReqBatches) > 0 {
 		getOutstandingDigests := func() []string {
 			var digests []string
@@ -1334,6 +1385,10 @@ func (instance *pbftCore) startTimerIfOutstandingRequests() {
 }
 
 func (instance *pbftCore) softStartTimer(timeout time.Duration, reason string) {
+	if !instance.isMember() {
+		logger.Debugf("Replica %d is not a member of the replica set, not starting new view timer for %s", instance.id, reason)
+		return
+	}
 	logger.Debugf("Replica %d soft starting new view timer for %s: %s", instance.id, timeout, reason)
 	instance.newViewTimerReason = reason
 	instance.timerActive = true
@@ -1341,6 +1396,10 @@ func (instance *pbftCore) softStartTimer(timeout time.Duration, reason string) {
 }
 
 func (instance *pbftCore) startTimer(timeout time.Duration, reason string) {
+	if !instance.isMember() {
+		logger.Debugf("Replica %d is not a member of the replica set, not starting new view timer for %s", instance.id, reason)
+		return
+	}
 	logger.Debugf("Replica %d starting new view timer for %s: %s", instance.id, timeout, reason)
 	instance.timerActive = true
 	instance.newViewTimer.Reset(timeout, viewChangeTimerEvent{})
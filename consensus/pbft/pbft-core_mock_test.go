@@ -23,17 +23,18 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/hyperledger/fabric/consensus/util/events"
+	"github.com/hyperledger/fabric/consensus/util/mocknet"
 	pb "github.com/hyperledger/fabric/protos"
 )
 
 type pbftEndpoint struct {
-	*testEndpoint
+	*mocknet.TestEndpoint
 	pbft    *pbftCore
 	sc      *simpleConsumer
 	manager events.Manager
 }
 
-func (pe *pbftEndpoint) deliver(msgPayload []byte, senderHandle *pb.PeerID) {
+func (pe *pbftEndpoint) Deliver(msgPayload []byte, senderHandle *pb.PeerID) {
 	senderID, _ := getValidatorID(senderHandle)
 	msg := &Message{}
 	err := proto.Unmarshal(msgPayload, msg)
@@ -44,13 +45,13 @@ func (pe *pbftEndpoint) deliver(msgPayload []byte, senderHandle *pb.PeerID) {
 	pe.manager.Queue() <- &pbftMessage{msg: msg, sender: senderID}
 }
 
-func (pe *pbftEndpoint) stop() {
+func (pe *pbftEndpoint) Stop() {
 	pe.pbft.close()
 }
 
-func (pe *pbftEndpoint) isBusy() bool {
+func (pe *pbftEndpoint) IsBusy() bool {
 	if pe.pbft.timerActive || pe.pbft.currentExec != nil {
-		pe.net.debugMsg("TEST: Returning as busy because timer active (%v) or current exec (%v)\n", pe.pbft.timerActive, pe.pbft.currentExec)
+		pe.Net.DebugMsg("TEST: Returning as busy because timer active (%v) or current exec (%v)\n", pe.pbft.timerActive, pe.pbft.currentExec)
 		return true
 	}
 
@@ -60,7 +61,7 @@ func (pe *pbftEndpoint) isBusy() bool {
 	select {
 	case pe.manager.Queue() <- nil:
 	default:
-		pe.net.debugMsg("TEST: Returning as busy no reply on idleChan\n")
+		pe.Net.DebugMsg("TEST: Returning as busy no reply on idleChan\n")
 		return true
 	}
 
@@ -68,7 +69,7 @@ func (pe *pbftEndpoint) isBusy() bool {
 }
 
 type pbftNetwork struct {
-	*testnet
+	*mocknet.Testnet
 	pbftEndpoints []*pbftEndpoint
 }
 
@@ -124,12 +125,12 @@ func (sc *simpleConsumer) skipTo(seqNo uint64, id []byte, replicas []uint64) {
 			target: &pb.BlockchainInfo{},
 		}
 	}()
-	sc.pbftNet.debugMsg("TEST: skipping to %d\n", seqNo)
+	sc.pbftNet.DebugMsg("TEST: skipping to %d\n", seqNo)
 }
 
 func (sc *simpleConsumer) execute(seqNo uint64, reqBatch *RequestBatch) {
 	for _, req := range reqBatch.GetBatch() {
-		sc.pbftNet.debugMsg("TEST: executing request\n")
+		sc.pbftNet.DebugMsg("TEST: executing request\n")
 		sc.lastExecution = hash(req)
 		sc.executions++
 		sc.lastSeqNo = seqNo
@@ -148,6 +149,16 @@ func (sc *simpleConsumer) getLastSeqNo() (uint64, error) {
 	return sc.lastSeqNo, nil
 }
 
+func (sc *simpleConsumer) getLastMembership() (*Membership, *Membership, error) {
+	return nil, nil, fmt.Errorf("no membership recorded")
+}
+
+func (sc *simpleConsumer) getLastReconfigurationVotes() ([]*Request, error) {
+	return nil, fmt.Errorf("no membership recorded")
+}
+
+func (sc *simpleConsumer) reconfigure(membership *Membership) {}
+
 func makePBFTNetwork(N int, config *viper.Viper) *pbftNetwork {
 	if config == nil {
 		config = loadConfig()
@@ -155,10 +166,10 @@ func makePBFTNetwork(N int, config *viper.Viper) *pbftNetwork {
 
 	config.Set("general.N", N)
 	config.Set("general.f", (N-1)/3)
-	endpointFunc := func(id uint64, net *testnet) endpoint {
-		tep := makeTestEndpoint(id, net)
+	endpointFunc := func(id uint64, net *mocknet.Testnet) mocknet.Endpoint {
+		tep := mocknet.MakeTestEndpoint(id, net)
 		pe := &pbftEndpoint{
-			testEndpoint: tep,
+			TestEndpoint: tep,
 			manager:      events.NewManagerImpl(),
 		}
 
@@ -175,9 +186,9 @@ func makePBFTNetwork(N int, config *viper.Viper) *pbftNetwork {
 
 	}
 
-	pn := &pbftNetwork{testnet: makeTestnet(N, endpointFunc)}
-	pn.pbftEndpoints = make([]*pbftEndpoint, len(pn.endpoints))
-	for i, ep := range pn.endpoints {
+	pn := &pbftNetwork{Testnet: mocknet.MakeTestnet(N, endpointFunc)}
+	pn.pbftEndpoints = make([]*pbftEndpoint, len(pn.Endpoints))
+	for i, ep := range pn.Endpoints {
 		pn.pbftEndpoints[i] = ep.(*pbftEndpoint)
 		pn.pbftEndpoints[i].sc.pbftNet = pn
 	}
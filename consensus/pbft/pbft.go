@@ -112,15 +112,6 @@ func getValidatorHandle(id uint64) (handle *pb.PeerID, err error) {
 	return &pb.PeerID{Name: name}, nil
 }
 
-// Returns the peer handles corresponding to a list of replica ids
-func getValidatorHandles(ids []uint64) (handles []*pb.PeerID) {
-	handles = make([]*pb.PeerID, len(ids))
-	for i, id := range ids {
-		handles[i], _ = getValidatorHandle(id)
-	}
-	return
-}
-
 type obcGeneric struct {
 	stack consensus.Stack
 	pbft  *pbftCore
@@ -133,7 +124,20 @@ func (op *obcGeneric) skipTo(seqNo uint64, id []byte, replicas []uint64) {
 		logger.Error(fmt.Sprintf("Error unmarshaling: %s", err))
 		return
 	}
-	op.stack.UpdateState(&checkpointMessage{seqNo, id}, info, getValidatorHandles(replicas))
+	op.stack.UpdateState(&checkpointMessage{seqNo, id}, info, op.getReplicaHandles(replicas))
+}
+
+// Returns the peer handles corresponding to a list of replica ids, replicas
+// which are not part of the current membership fall back to the vpX convention
+func (op *obcGeneric) getReplicaHandles(ids []uint64) (handles []*pb.PeerID) {
+	handles = make([]*pb.PeerID, len(ids))
+	for i, id := range ids {
+		var err error
+		if handles[i], err = op.pbft.replicaHandle(id); err != nil {
+			handles[i], _ = getValidatorHandle(id)
+		}
+	}
+	return
 }
 
 func (op *obcGeneric) invalidateState() {
@@ -149,11 +153,41 @@ func (op *obcGeneric) getState() []byte {
 }
 
 func (op *obcGeneric) getLastSeqNo() (uint64, error) {
-	raw, err := op.stack.GetBlockHeadMetadata()
+	meta, err := op.getLastMetadata()
 	if err != nil {
 		return 0, err
 	}
+	return meta.SeqNo, nil
+}
+
+func (op *obcGeneric) getLastMembership() (*Membership, *Membership, error) {
+	meta, err := op.getLastMetadata()
+	if err != nil {
+		return nil, nil, err
+	}
+	if meta.Membership == nil {
+		return nil, nil, fmt.Errorf("no membership recorded in the last block")
+	}
+	return meta.Membership, meta.Pending, nil
+}
+
+func (op *obcGeneric) getLastReconfigurationVotes() ([]*Request, error) {
+	meta, err := op.getLastMetadata()
+	if err != nil {
+		return nil, err
+	}
+	if meta.Membership == nil {
+		return nil, fmt.Errorf("no membership recorded in the last block")
+	}
+	return meta.Votes, nil
+}
+
+func (op *obcGeneric) getLastMetadata() (*Metadata, error) {
+	raw, err := op.stack.GetBlockHeadMetadata()
+	if err != nil {
+		return nil, err
+	}
 	meta := &Metadata{}
 	proto.Unmarshal(raw, meta)
-	return meta.SeqNo, nil
+	return meta, nil
 }
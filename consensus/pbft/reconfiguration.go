@@ -0,0 +1,360 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pbft
+
+import (
+	"fmt"
+	"sort"
+
+	pb "github.com/hyperledger/fabric/protos"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Membership changes are ordered like any other request. Reconfiguration
+// requests are signed by the member submitting them, and once f+1 members
+// requested the same change, so that at least one correct replica wants it,
+// every replica schedules the resulting membership for the first checkpoint
+// the current primary cannot have pre-prepared yet. No sequence number beyond that checkpoint is assigned
+// until it executes, so the old and the new replica set never share a
+// sequence number.
+
+const (
+	membershipKey        = "membership"
+	pendingMembershipKey = "membership.pending"
+	votesKey             = "membership.votes"
+)
+
+// defaultMembership returns the static membership derived from the
+// configuration, replicas 0 to N-1 using the vpX naming convention
+func defaultMembership(N int, f int) *Membership {
+	m := &Membership{F: uint64(f)}
+	for i := 0; i < N; i++ {
+		handle, _ := getValidatorHandle(uint64(i))
+		m.Replicas = append(m.Replicas, &Replica{Id: uint64(i), Handle: handle.Name})
+	}
+	return m
+}
+
+// applyMembership makes m the current membership, updating N, f and the
+// replica to handle mapping
+func (instance *pbftCore) applyMembership(m *Membership) {
+	instance.membership = m
+	instance.replicas = make(map[uint64]string)
+	instance.replicaIDs = nil
+	for _, r := range m.Replicas {
+		instance.replicas[r.Id] = r.Handle
+		instance.replicaIDs = append(instance.replicaIDs, r.Id)
+	}
+	sort.Sort(sortableUint64Slice(instance.replicaIDs))
+
+	instance.N = len(m.Replicas)
+	instance.replicaCount = instance.N
+	instance.f = int(m.F)
+
+	if !instance.isMember() {
+		// Nobody is listening to us anymore, do not keep asking for view changes
+		logger.Warningf("Replica %d is not a member of the replica set %v effective at seqNo %d", instance.id, instance.replicaIDs, m.SeqNo)
+		instance.stopTimer()
+		instance.nullRequestTimer.Stop()
+	}
+	logger.Infof("Replica %d using replica set %v effective at seqNo %d: N=%d, f=%d", instance.id, instance.replicaIDs, m.SeqNo, instance.N, instance.f)
+
+	instance.consumer.reconfigure(m)
+}
+
+// isMember reports whether this replica is part of the current membership
+func (instance *pbftCore) isMember() bool {
+	_, ok := instance.replicas[instance.id]
+	return ok
+}
+
+// replicaHandle returns the peer handle of a member of the current membership
+func (instance *pbftCore) replicaHandle(id uint64) (*pb.PeerID, error) {
+	name, ok := instance.replicas[id]
+	if !ok {
+		return nil, fmt.Errorf("replica %d is not a member of the replica set %v", id, instance.replicaIDs)
+	}
+	return &pb.PeerID{Name: name}, nil
+}
+
+// replicaID returns the replica ID of a member of the current membership
+func (instance *pbftCore) replicaID(handle *pb.PeerID) (uint64, error) {
+	for id, name := range instance.replicas {
+		if name == handle.Name {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("peer %s is not a member of the replica set %v", handle.Name, instance.replicaIDs)
+}
+
+// voteReconfiguration records the signed reconfiguration request ordered at
+// seqNo as the vote of its sender, replacing any previous vote of the same
+// replica, and schedules the reconfiguration once f+1 members voted for it
+func (instance *pbftCore) voteReconfiguration(seqNo uint64, req *Request) error {
+	if err := instance.verify(req); err != nil {
+		return fmt.Errorf("request from replica %d is not correctly signed by a member: %s", req.ReplicaId, err)
+	}
+	if instance.pendingMembership != nil {
+		return fmt.Errorf("a reconfiguration is already pending for seqNo %d", instance.pendingMembership.SeqNo)
+	}
+
+	var votes []*Request
+	matching := 1
+	for _, vote := range instance.reconfigurationVotes {
+		if vote.ReplicaId == req.ReplicaId {
+			continue
+		}
+		if proto.Equal(vote.Reconfiguration, req.Reconfiguration) {
+			matching++
+		}
+		votes = append(votes, vote)
+	}
+
+	if matching <= instance.f {
+		instance.reconfigurationVotes = append(votes, req)
+		instance.persistVotes()
+		logger.Infof("Replica %d recorded the reconfiguration vote of replica %d at seqNo %d, %d of %d needed",
+			instance.id, req.ReplicaId, seqNo, matching, instance.f+1)
+		return nil
+	}
+
+	instance.reconfigurationVotes = nil
+	instance.persistVotes()
+	return instance.scheduleReconfiguration(seqNo, req.Reconfiguration)
+}
+
+// scheduleReconfiguration validates a reconfiguration ordered at seqNo and,
+// if it is acceptable, records the resulting membership as pending. As this
+// is invoked on every replica for the same request at the same seqNo, the
+// outcome must only depend on the ordered history.
+func (instance *pbftCore) scheduleReconfiguration(seqNo uint64, reconf *Reconfiguration) error {
+	if instance.pendingMembership != nil {
+		return fmt.Errorf("a reconfiguration is already pending for seqNo %d", instance.pendingMembership.SeqNo)
+	}
+
+	removed := make(map[uint64]bool)
+	for _, id := range reconf.Remove {
+		if _, ok := instance.replicas[id]; !ok {
+			return fmt.Errorf("cannot remove replica %d, it is not a member", id)
+		}
+		removed[id] = true
+	}
+
+	var replicas []*Replica
+	handles := make(map[string]bool)
+	for _, id := range instance.replicaIDs {
+		if removed[id] {
+			continue
+		}
+		replicas = append(replicas, &Replica{Id: id, Handle: instance.replicas[id]})
+		handles[instance.replicas[id]] = true
+	}
+
+	for _, r := range reconf.Add {
+		if r.Handle == "" {
+			return fmt.Errorf("cannot add replica %d without a handle", r.Id)
+		}
+		if handles[r.Handle] {
+			return fmt.Errorf("cannot add replica %d, handle %s is already in use", r.Id, r.Handle)
+		}
+		for _, other := range replicas {
+			if other.Id == r.Id {
+				return fmt.Errorf("cannot add replica %d, it is already a member", r.Id)
+			}
+		}
+		replicas = append(replicas, &Replica{Id: r.Id, Handle: r.Handle})
+		handles[r.Handle] = true
+	}
+
+	if len(replicas) == 0 {
+		return fmt.Errorf("cannot remove every replica")
+	}
+	sort.Sort(replicasByID(replicas))
+
+	// The primary never pre-prepares past h+L/2, and h cannot exceed seqNo
+	// on a primary which has not yet executed this request
+	effective := (seqNo + instance.L/2 + instance.K - 1) / instance.K * instance.K
+
+	instance.pendingMembership = &Membership{
+		Replicas: replicas,
+		F:        uint64((len(replicas) - 1) / 3),
+		SeqNo:    effective,
+	}
+	instance.persistMembership(pendingMembershipKey, instance.pendingMembership)
+
+	logger.Infof("Replica %d scheduled a reconfiguration ordered at seqNo %d to take effect at seqNo %d: %d replicas tolerating %d faults",
+		instance.id, seqNo, effective, len(replicas), instance.pendingMembership.F)
+	return nil
+}
+
+// maybeApplyPending switches to the pending membership once the checkpoint
+// it is scheduled for has been executed, it returns whether it did
+func (instance *pbftCore) maybeApplyPending() bool {
+	pending := instance.pendingMembership
+	if pending == nil || instance.lastExec < pending.SeqNo {
+		return false
+	}
+
+	instance.pendingMembership = nil
+	instance.applyMembership(pending)
+	instance.persistMembership(membershipKey, pending)
+	instance.consumer.DelState(pendingMembershipKey)
+
+	// As a backup does not track the primary's sequence numbers, make sure
+	// whoever is primary under the new membership continues after the switch
+	if instance.seqNo < instance.lastExec {
+		instance.seqNo = instance.lastExec
+	}
+	return true
+}
+
+// refreshMembership adopts the membership recorded alongside the last
+// executed batch, this is how replicas which skipped the reconfiguration
+// through state transfer learn about it
+func (instance *pbftCore) refreshMembership() {
+	m, pending, err := instance.consumer.getLastMembership()
+	if err != nil {
+		logger.Debugf("Replica %d could not read the membership of the last block: %s", instance.id, err)
+		return
+	}
+
+	if m != nil && m.SeqNo > instance.membership.SeqNo {
+		instance.applyMembership(m)
+		instance.persistMembership(membershipKey, m)
+	}
+
+	if pending != nil && pending.SeqNo > instance.membership.SeqNo {
+		instance.pendingMembership = pending
+		instance.persistMembership(pendingMembershipKey, pending)
+	} else if instance.pendingMembership != nil && instance.pendingMembership.SeqNo <= instance.membership.SeqNo {
+		instance.pendingMembership = nil
+		instance.consumer.DelState(pendingMembershipKey)
+	}
+
+	if votes, err := instance.consumer.getLastReconfigurationVotes(); err == nil {
+		instance.reconfigurationVotes = votes
+		instance.persistVotes()
+	}
+
+	instance.maybeApplyPending()
+}
+
+func (instance *pbftCore) persistMembership(key string, m *Membership) {
+	raw, err := proto.Marshal(m)
+	if err != nil {
+		logger.Warningf("Replica %d could not persist %s: %s", instance.id, key, err)
+		return
+	}
+	err = instance.consumer.StoreState(key, raw)
+	if err != nil {
+		logger.Warningf("Replica %d could not persist %s: %s", instance.id, key, err)
+	}
+}
+
+func (instance *pbftCore) persistVotes() {
+	if len(instance.reconfigurationVotes) == 0 {
+		instance.consumer.DelState(votesKey)
+		return
+	}
+	raw, err := proto.Marshal(&RequestBatch{Batch: instance.reconfigurationVotes})
+	if err != nil {
+		logger.Warningf("Replica %d could not persist %s: %s", instance.id, votesKey, err)
+		return
+	}
+	err = instance.consumer.StoreState(votesKey, raw)
+	if err != nil {
+		logger.Warningf("Replica %d could not persist %s: %s", instance.id, votesKey, err)
+	}
+}
+
+func (instance *pbftCore) restorePersistedVotes() []*Request {
+	raw, err := instance.consumer.ReadState(votesKey)
+	if err != nil {
+		logger.Debugf("Replica %d could not restore state %s: %s", instance.id, votesKey, err)
+		return nil
+	}
+	votes := &RequestBatch{}
+	err = proto.Unmarshal(raw, votes)
+	if err != nil {
+		logger.Errorf("Replica %d could not unmarshal %s - local state is damaged: %s", instance.id, votesKey, err)
+		return nil
+	}
+	return votes.Batch
+}
+
+func (instance *pbftCore) restorePersistedMembership(key string) *Membership {
+	raw, err := instance.consumer.ReadState(key)
+	if err != nil {
+		logger.Debugf("Replica %d could not restore state %s: %s", instance.id, key, err)
+		return nil
+	}
+	m := &Membership{}
+	err = proto.Unmarshal(raw, m)
+	if err != nil {
+		logger.Errorf("Replica %d could not unmarshal %s - local state is damaged: %s", instance.id, key, err)
+		return nil
+	}
+	return m
+}
+
+// restoreMembership picks the most recent of the persisted membership,
+// the one recorded in the last block and the configured one, the votes
+// recorded in the last block are preferred over the persisted ones
+func (instance *pbftCore) restoreMembership() {
+	current := defaultMembership(instance.N, instance.f)
+	pending := instance.restorePersistedMembership(pendingMembershipKey)
+	votes := instance.restorePersistedVotes()
+
+	if m := instance.restorePersistedMembership(membershipKey); m != nil {
+		current = m
+	}
+
+	if m, p, err := instance.consumer.getLastMembership(); err == nil {
+		if m != nil && m.SeqNo > current.SeqNo {
+			current = m
+		}
+		if p != nil && (pending == nil || p.SeqNo > pending.SeqNo) {
+			pending = p
+		}
+	}
+
+	if v, err := instance.consumer.getLastReconfigurationVotes(); err == nil {
+		votes = v
+	}
+
+	if pending != nil && pending.SeqNo <= current.SeqNo {
+		pending = nil
+	}
+
+	instance.applyMembership(current)
+	instance.pendingMembership = pending
+	instance.reconfigurationVotes = votes
+	instance.maybeApplyPending()
+}
+
+type replicasByID []*Replica
+
+func (a replicasByID) Len() int {
+	return len(a)
+}
+func (a replicasByID) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
+func (a replicasByID) Less(i, j int) bool {
+	return a[i].Id < a[j].Id
+}
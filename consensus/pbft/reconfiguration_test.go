@@ -0,0 +1,279 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pbft
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hyperledger/fabric/consensus"
+	pb "github.com/hyperledger/fabric/protos"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
+)
+
+// obcBatchReconfigurationHelper creates replicas which start out with a
+// replica set of four, regardless of how many endpoints the network has
+func obcBatchReconfigurationHelper(id uint64, config *viper.Viper, stack consensus.Stack) pbftConsumer {
+	config.Set("general.N", 4)
+	config.Set("general.f", 1)
+	config.Set("general.K", 2)
+	config.Set("general.logmultiplier", 2)
+	config.Set("general.batchsize", 1)
+	return newObcBatch(id, config, stack)
+}
+
+func membershipIDs(m *Membership) []uint64 {
+	var ids []uint64
+	for _, r := range m.Replicas {
+		ids = append(ids, r.Id)
+	}
+	return ids
+}
+
+func TestReconfigurationRemoveReplica(t *testing.T) {
+	validatorCount := 4
+	net := makeConsumerNetwork(validatorCount, obcBatchReconfigurationHelper)
+	defer net.Stop()
+
+	broadcaster := net.Endpoints[generateBroadcaster(validatorCount)].GetHandle()
+	net.Endpoints[1].(*consumerEndpoint).consumer.RecvMsg(createTxMsg(1), broadcaster)
+	net.Process()
+
+	net.Endpoints[0].(*consumerEndpoint).consumer.(*obcBatch).Reconfigure(nil, []uint64{3})
+	net.Process()
+
+	// A single member cannot change the replica set on its own
+	for _, ep := range net.Endpoints {
+		pbft := ep.(*consumerEndpoint).consumer.getPBFTCore()
+		if pbft.pendingMembership != nil {
+			t.Fatalf("Replica %d scheduled a reconfiguration requested by a single member", pbft.id)
+		}
+		if len(pbft.reconfigurationVotes) != 1 {
+			t.Errorf("Replica %d expected one reconfiguration vote, got %d", pbft.id, len(pbft.reconfigurationVotes))
+		}
+	}
+
+	net.Endpoints[1].(*consumerEndpoint).consumer.(*obcBatch).Reconfigure(nil, []uint64{3})
+	net.Process()
+
+	// The second vote is ordered at seqNo 3, and the primary may have pre-prepared up to h+L/2 = 4
+	for _, ep := range net.Endpoints {
+		pbft := ep.(*consumerEndpoint).consumer.getPBFTCore()
+		if pbft.pendingMembership == nil || pbft.pendingMembership.SeqNo != 6 {
+			t.Fatalf("Replica %d expected a reconfiguration pending for seqNo 6, got %v", pbft.id, pbft.pendingMembership)
+		}
+		if len(pbft.reconfigurationVotes) != 0 {
+			t.Errorf("Replica %d expected the votes to be cleared once the reconfiguration is scheduled, got %d", pbft.id, len(pbft.reconfigurationVotes))
+		}
+		if pbft.N != 4 || pbft.f != 1 {
+			t.Errorf("Replica %d changed its replica set before the checkpoint: N=%d, f=%d", pbft.id, pbft.N, pbft.f)
+		}
+	}
+
+	for n := 2; n <= 7; n++ {
+		net.Endpoints[1].(*consumerEndpoint).consumer.RecvMsg(createTxMsg(int64(n)), broadcaster)
+	}
+	net.Process()
+
+	for _, ep := range net.Endpoints {
+		pbft := ep.(*consumerEndpoint).consumer.getPBFTCore()
+		if pbft.pendingMembership != nil {
+			t.Errorf("Replica %d still has a reconfiguration pending for seqNo %d", pbft.id, pbft.pendingMembership.SeqNo)
+		}
+		if pbft.N != 3 || pbft.f != 0 {
+			t.Errorf("Replica %d expected N=3 and f=0, got N=%d, f=%d", pbft.id, pbft.N, pbft.f)
+		}
+		if ids := membershipIDs(pbft.membership); !reflect.DeepEqual(ids, []uint64{0, 1, 2}) || pbft.membership.SeqNo != 6 {
+			t.Errorf("Replica %d expected replicas [0 1 2] from seqNo 6, got %v from seqNo %d", pbft.id, ids, pbft.membership.SeqNo)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		obc := net.Endpoints[i].(*consumerEndpoint).consumer.(*obcBatch)
+		if _, err := obc.stack.GetBlock(9); err != nil {
+			t.Errorf("Replica %d expected to keep executing after the reconfiguration, but could not retrieve block 9: %s", i, err)
+		}
+	}
+
+	// A restarted replica picks up the replica set it had persisted
+	stack := net.Endpoints[0].(*consumerEndpoint).consumer.(*obcBatch).stack
+	restarted := newObcBatch(0, loadConfig(), stack)
+	defer restarted.Close()
+	if restarted.pbft.N != 3 || restarted.pbft.f != 0 {
+		t.Errorf("Restarted replica expected N=3 and f=0, got N=%d, f=%d", restarted.pbft.N, restarted.pbft.f)
+	}
+	if _, ok := restarted.broadcaster.msgChans[3]; ok {
+		t.Errorf("Restarted replica should not send to the removed replica 3")
+	}
+}
+
+func TestReconfigurationAddReplica(t *testing.T) {
+	validatorCount := 5
+	net := makeConsumerNetwork(validatorCount, obcBatchReconfigurationHelper)
+	defer net.Stop()
+
+	for i := 0; i < 2; i++ {
+		net.Endpoints[i].(*consumerEndpoint).consumer.(*obcBatch).Reconfigure([]*Replica{{Id: 4, Handle: "vp4"}}, nil)
+		net.Process()
+	}
+
+	// Replica 4 joins by state transfer once the members checkpoint beyond its watermarks
+	broadcaster := net.Endpoints[generateBroadcaster(validatorCount)].GetHandle()
+	for n := 1; n <= 10; n++ {
+		net.Endpoints[1].(*consumerEndpoint).consumer.RecvMsg(createTxMsg(int64(n)), broadcaster)
+	}
+	net.Process()
+
+	for _, ep := range net.Endpoints {
+		obc := ep.(*consumerEndpoint).consumer.(*obcBatch)
+		if obc.pbft.N != 5 || obc.pbft.f != 1 {
+			t.Errorf("Replica %d expected N=5 and f=1, got N=%d, f=%d", obc.pbft.id, obc.pbft.N, obc.pbft.f)
+		}
+		if ids := membershipIDs(obc.pbft.membership); !reflect.DeepEqual(ids, []uint64{0, 1, 2, 3, 4}) {
+			t.Errorf("Replica %d expected replicas [0 1 2 3 4], got %v", obc.pbft.id, ids)
+		}
+		if _, err := obc.stack.GetBlock(12); err != nil {
+			t.Errorf("Replica %d expected to reach block 12, but could not retrieve it: %s", obc.pbft.id, err)
+		}
+	}
+}
+
+func TestReconfigurationValidation(t *testing.T) {
+	instance := newPbftCore(0, loadConfig(), &omniProto{}, &inertTimerFactory{})
+	instance.K = 2
+	instance.L = 4
+
+	invalid := []*Reconfiguration{
+		{Remove: []uint64{7}},
+		{Remove: []uint64{0, 1, 2, 3}},
+		{Add: []*Replica{{Id: 2, Handle: "vp9"}}},
+		{Add: []*Replica{{Id: 5, Handle: "vp1"}}},
+		{Add: []*Replica{{Id: 5}}},
+		{Add: []*Replica{{Id: 5, Handle: "vp5"}, {Id: 5, Handle: "vp6"}}},
+	}
+	for _, reconf := range invalid {
+		if err := instance.scheduleReconfiguration(1, reconf); err == nil {
+			t.Errorf("Expected reconfiguration %v to be rejected", reconf)
+		}
+	}
+
+	// Replacing a replica under the same ID with a new handle is fine
+	if err := instance.scheduleReconfiguration(5, &Reconfiguration{Add: []*Replica{{Id: 3, Handle: "vp3-new"}}, Remove: []uint64{3}}); err != nil {
+		t.Fatalf("Expected reconfiguration to be accepted: %s", err)
+	}
+	if instance.pendingMembership.SeqNo != 8 {
+		t.Errorf("Expected reconfiguration to take effect at seqNo 8, got %d", instance.pendingMembership.SeqNo)
+	}
+	if err := instance.scheduleReconfiguration(6, &Reconfiguration{Remove: []uint64{2}}); err == nil {
+		t.Errorf("Expected a second reconfiguration to be rejected while one is pending")
+	}
+
+	instance.lastExec = 8
+	instance.maybeApplyPending()
+	if handle, _ := instance.replicaHandle(3); handle == nil || handle.Name != "vp3-new" {
+		t.Errorf("Expected replica 3 to map to vp3-new, got %v", handle)
+	}
+	if _, err := instance.replicaID(&pb.PeerID{Name: "vp3"}); err == nil {
+		t.Errorf("Expected vp3 to no longer map to a replica")
+	}
+}
+
+func TestReconfigurationVotes(t *testing.T) {
+	forged := uint64(3)
+	instance := newPbftCore(0, loadConfig(), &omniProto{
+		verifyImpl: func(senderID uint64, signature []byte, message []byte) error {
+			if senderID == forged {
+				return fmt.Errorf("invalid signature")
+			}
+			return nil
+		},
+	}, &inertTimerFactory{})
+	instance.K = 2
+	instance.L = 4
+
+	vote := func(replica uint64, reconf *Reconfiguration) *Request {
+		return &Request{ReplicaId: replica, Reconfiguration: reconf}
+	}
+	remove3 := &Reconfiguration{Remove: []uint64{3}}
+	remove2 := &Reconfiguration{Remove: []uint64{2}}
+
+	if err := instance.voteReconfiguration(1, vote(forged, remove3)); err == nil {
+		t.Errorf("Expected a vote which fails signature verification to be rejected")
+	}
+	if err := instance.voteReconfiguration(2, vote(0, remove3)); err != nil {
+		t.Fatalf("Expected the vote of replica 0 to be recorded: %s", err)
+	}
+	if err := instance.voteReconfiguration(3, vote(0, remove3)); err != nil {
+		t.Fatalf("Expected the repeated vote of replica 0 to be recorded: %s", err)
+	}
+	if err := instance.voteReconfiguration(4, vote(1, remove2)); err != nil {
+		t.Fatalf("Expected the vote of replica 1 to be recorded: %s", err)
+	}
+	if instance.pendingMembership != nil {
+		t.Fatalf("Expected no reconfiguration without f+1 matching votes, got %v", instance.pendingMembership)
+	}
+	if len(instance.reconfigurationVotes) != 2 {
+		t.Errorf("Expected one vote per replica, got %d", len(instance.reconfigurationVotes))
+	}
+
+	if err := instance.voteReconfiguration(5, vote(2, remove3)); err != nil {
+		t.Fatalf("Expected the vote of replica 2 to schedule the reconfiguration: %s", err)
+	}
+	if instance.pendingMembership == nil || instance.pendingMembership.SeqNo != 8 {
+		t.Fatalf("Expected a reconfiguration pending for seqNo 8, got %v", instance.pendingMembership)
+	}
+	if ids := membershipIDs(instance.pendingMembership); !reflect.DeepEqual(ids, []uint64{0, 1, 2}) {
+		t.Errorf("Expected the pending replica set to be [0 1 2], got %v", ids)
+	}
+	if len(instance.reconfigurationVotes) != 0 {
+		t.Errorf("Expected the votes to be cleared, got %d", len(instance.reconfigurationVotes))
+	}
+	if err := instance.voteReconfiguration(6, vote(1, remove2)); err == nil {
+		t.Errorf("Expected votes to be rejected while a reconfiguration is pending")
+	}
+}
+
+func TestReconfigurationRestoredFromMetadata(t *testing.T) {
+	current := defaultMembership(4, 1)
+	pending := defaultMembership(7, 2)
+	pending.SeqNo = 10
+	raw, _ := proto.Marshal(&Metadata{SeqNo: 10, Membership: current, Pending: pending})
+
+	var reconfigured *Membership
+	instance := newPbftCore(0, loadConfig(), &omniProto{
+		getLastSeqNoImpl: func() (uint64, error) { return 10, nil },
+		getLastMembershipImpl: func() (*Membership, *Membership, error) {
+			meta := &Metadata{}
+			proto.Unmarshal(raw, meta)
+			return meta.Membership, meta.Pending, nil
+		},
+		reconfigureImpl: func(m *Membership) { reconfigured = m },
+	}, &inertTimerFactory{})
+
+	if instance.N != 7 || instance.f != 2 {
+		t.Errorf("Expected the pending replica set executed before the restart to apply, got N=%d, f=%d", instance.N, instance.f)
+	}
+	if reconfigured == nil || reconfigured.SeqNo != 10 {
+		t.Errorf("Expected the consumer to be reconfigured for the replica set effective at seqNo 10, got %v", reconfigured)
+	}
+	if instance.seqNo != 10 {
+		t.Errorf("Expected seqNo to resume from 10, got %d", instance.seqNo)
+	}
+}
@@ -71,3 +71,23 @@ func (vc *ViewChange) setID(id uint64) {
 func (vc *ViewChange) serialize() ([]byte, error) {
 	return pb.Marshal(vc)
 }
+
+func (msg *Request) getSignature() []byte {
+	return msg.Signature
+}
+
+func (msg *Request) setSignature(sig []byte) {
+	msg.Signature = sig
+}
+
+func (msg *Request) getID() uint64 {
+	return msg.ReplicaId
+}
+
+func (msg *Request) setID(id uint64) {
+	msg.ReplicaId = id
+}
+
+func (msg *Request) serialize() ([]byte, error) {
+	return pb.Marshal(msg)
+}
@@ -21,9 +21,11 @@ package pbft
 
 import (
 	"encoding/base64"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/util"
+	pb "github.com/hyperledger/fabric/protos"
 )
 
 func hash(msg interface{}) string {
@@ -40,3 +42,18 @@ func hash(msg interface{}) string {
 	return base64.StdEncoding.EncodeToString(util.ComputeCryptoHash(raw))
 
 }
+
+// isAheadOfClock returns true if the request carries a transaction stamped later than the local clock by more
+// than maxSkew. The time of a block is derived from the timestamps of its transactions, such a transaction
+// would release the time locks of the transactions sets early
+func isAheadOfClock(req *Request, maxSkew time.Duration) bool {
+	if maxSkew <= 0 || req.Payload == nil {
+		return false
+	}
+	tx := &pb.InBlockTransaction{}
+	if err := proto.Unmarshal(req.Payload, tx); err != nil {
+		// Not a transaction, execute ignores it
+		return false
+	}
+	return util.IsTimestampTooFarAhead(tx.Timestamp, maxSkew)
+}
@@ -123,6 +123,11 @@ func (instance *pbftCore) calcQSet() map[qidx]*ViewChange_PQ {
 }
 
 func (instance *pbftCore) sendViewChange() events.Event {
+	if !instance.isMember() {
+		logger.Warningf("Replica %d is not a member of the replica set, not sending view change", instance.id)
+		return nil
+	}
+
 	instance.stopTimer()
 
 	delete(instance.newViewStore, instance.view)
@@ -0,0 +1,161 @@
+// Code generated by protoc-gen-go.
+// source: messages.proto
+// DO NOT EDIT!
+
+/*
+Package raft is a generated protocol buffer package.
+
+It is generated from these files:
+	messages.proto
+
+It has these top-level messages:
+	Message
+	Entry
+	HardState
+	Metadata
+*/
+package raft
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type MessageType int32
+
+const (
+	Message_REQUEST         MessageType = 0
+	Message_VOTE            MessageType = 1
+	Message_VOTE_RESPONSE   MessageType = 2
+	Message_APPEND          MessageType = 3
+	Message_APPEND_RESPONSE MessageType = 4
+	Message_SNAPSHOT        MessageType = 5
+)
+
+var MessageType_name = map[int32]string{
+	0: "REQUEST",
+	1: "VOTE",
+	2: "VOTE_RESPONSE",
+	3: "APPEND",
+	4: "APPEND_RESPONSE",
+	5: "SNAPSHOT",
+}
+var MessageType_value = map[string]int32{
+	"REQUEST":         0,
+	"VOTE":            1,
+	"VOTE_RESPONSE":   2,
+	"APPEND":          3,
+	"APPEND_RESPONSE": 4,
+	"SNAPSHOT":        5,
+}
+
+func (x MessageType) String() string {
+	return proto.EnumName(MessageType_name, int32(x))
+}
+func (MessageType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0, 0} }
+
+type Message struct {
+	Type MessageType `protobuf:"varint,1,opt,name=type,enum=raft.MessageType" json:"type,omitempty"`
+	Term uint64      `protobuf:"varint,2,opt,name=term" json:"term,omitempty"`
+	// index of the entry preceding the appended entries, last entry of the
+	// candidate, last entry matching the leader or snapshot index
+	Index uint64 `protobuf:"varint,3,opt,name=index" json:"index,omitempty"`
+	// term of the entry at index
+	LogTerm uint64   `protobuf:"varint,4,opt,name=log_term,json=logTerm" json:"log_term,omitempty"`
+	Entries []*Entry `protobuf:"bytes,5,rep,name=entries" json:"entries,omitempty"`
+	Commit  uint64   `protobuf:"varint,6,opt,name=commit" json:"commit,omitempty"`
+	Reject  bool     `protobuf:"varint,7,opt,name=reject" json:"reject,omitempty"`
+	// forwarded transaction or blockchain info of the snapshot
+	Payload []byte `protobuf:"bytes,8,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (m *Message) Reset()                    { *m = Message{} }
+func (m *Message) String() string            { return proto.CompactTextString(m) }
+func (*Message) ProtoMessage()               {}
+func (*Message) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *Message) GetEntries() []*Entry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+// an entry of the replicated log, a batch of transactions executed as a block
+type Entry struct {
+	Term  uint64   `protobuf:"varint,1,opt,name=term" json:"term,omitempty"`
+	Index uint64   `protobuf:"varint,2,opt,name=index" json:"index,omitempty"`
+	Txs   [][]byte `protobuf:"bytes,3,rep,name=txs,proto3" json:"txs,omitempty"`
+}
+
+func (m *Entry) Reset()                    { *m = Entry{} }
+func (m *Entry) String() string            { return proto.CompactTextString(m) }
+func (*Entry) ProtoMessage()               {}
+func (*Entry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+type HardState struct {
+	Term uint64 `protobuf:"varint,1,opt,name=term" json:"term,omitempty"`
+	Vote string `protobuf:"bytes,2,opt,name=vote" json:"vote,omitempty"`
+}
+
+func (m *HardState) Reset()                    { *m = HardState{} }
+func (m *HardState) String() string            { return proto.CompactTextString(m) }
+func (*HardState) ProtoMessage()               {}
+func (*HardState) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+// stored as the consensus metadata of the blocks
+type Metadata struct {
+	Index uint64 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
+	Term  uint64 `protobuf:"varint,2,opt,name=term" json:"term,omitempty"`
+}
+
+func (m *Metadata) Reset()                    { *m = Metadata{} }
+func (m *Metadata) String() string            { return proto.CompactTextString(m) }
+func (*Metadata) ProtoMessage()               {}
+func (*Metadata) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func init() {
+	proto.RegisterType((*Message)(nil), "raft.message")
+	proto.RegisterType((*Entry)(nil), "raft.entry")
+	proto.RegisterType((*HardState)(nil), "raft.hard_state")
+	proto.RegisterType((*Metadata)(nil), "raft.metadata")
+	proto.RegisterEnum("raft.MessageType", MessageType_name, MessageType_value)
+}
+
+func init() { proto.RegisterFile("messages.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 342 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0xcb, 0x4e, 0xeb, 0x30,
+	0x10, 0x86, 0x8f, 0x13, 0xe7, 0x72, 0xa6, 0x3d, 0x3d, 0x61, 0x40, 0xc8, 0xec, 0xa2, 0x48, 0xa0,
+	0xac, 0xb2, 0x28, 0x7d, 0x81, 0x0a, 0x22, 0xb1, 0x6a, 0x83, 0x13, 0xd8, 0x46, 0xa6, 0x31, 0xa5,
+	0xa8, 0x69, 0xaa, 0xc4, 0x42, 0xed, 0x1b, 0xf2, 0x58, 0x28, 0x4e, 0x4a, 0xbb, 0xe8, 0xee, 0xff,
+	0xe7, 0x9b, 0x8b, 0x3d, 0x36, 0x8c, 0x4a, 0xd9, 0x34, 0x62, 0x29, 0x9b, 0x68, 0x5b, 0x57, 0xaa,
+	0x42, 0x5a, 0x8b, 0x77, 0x15, 0x7c, 0x1b, 0xe0, 0xf4, 0x00, 0xef, 0x80, 0xaa, 0xfd, 0x56, 0x32,
+	0xe2, 0x93, 0x70, 0x34, 0xc6, 0xa8, 0x4d, 0x88, 0x7a, 0x18, 0xb5, 0x84, 0x6b, 0x8e, 0x08, 0x54,
+	0xc9, 0xba, 0x64, 0x86, 0x4f, 0x42, 0xca, 0xb5, 0xc6, 0x2b, 0xb0, 0x56, 0x9b, 0x42, 0xee, 0x98,
+	0xa9, 0x83, 0x9d, 0xc1, 0x1b, 0x70, 0xd7, 0xd5, 0x32, 0xd7, 0xd9, 0x54, 0x03, 0x67, 0x5d, 0x2d,
+	0xb3, 0xb6, 0xe0, 0x16, 0x1c, 0xb9, 0x51, 0xf5, 0x4a, 0x36, 0xcc, 0xf2, 0xcd, 0x70, 0x30, 0x1e,
+	0x74, 0xf3, 0xda, 0xe0, 0x9e, 0x1f, 0x18, 0x5e, 0x83, 0xbd, 0xa8, 0xca, 0x72, 0xa5, 0x98, 0xad,
+	0xeb, 0x7b, 0xd7, 0xc6, 0x6b, 0xf9, 0x29, 0x17, 0x8a, 0x39, 0x3e, 0x09, 0x5d, 0xde, 0x3b, 0x64,
+	0xe0, 0x6c, 0xc5, 0x7e, 0x5d, 0x89, 0x82, 0xb9, 0x3e, 0x09, 0x87, 0xfc, 0x60, 0x83, 0xbc, 0xbb,
+	0x1d, 0x0e, 0xc0, 0xe1, 0xf1, 0xf3, 0x4b, 0x9c, 0x66, 0xde, 0x1f, 0x74, 0x81, 0xbe, 0xce, 0xb3,
+	0xd8, 0x23, 0x78, 0x01, 0xff, 0x5a, 0x95, 0xf3, 0x38, 0x4d, 0xe6, 0xb3, 0x34, 0xf6, 0x0c, 0x04,
+	0xb0, 0xa7, 0x49, 0x12, 0xcf, 0x1e, 0x3d, 0x13, 0x2f, 0xe1, 0x7f, 0xa7, 0x8f, 0x09, 0x14, 0x87,
+	0xe0, 0xa6, 0xb3, 0x69, 0x92, 0x3e, 0xcd, 0x33, 0xcf, 0x0a, 0x1e, 0xc0, 0xd2, 0x87, 0xff, 0xdd,
+	0x0f, 0x39, 0xb7, 0x1f, 0xe3, 0x74, 0x3f, 0x1e, 0x98, 0x6a, 0xd7, 0x30, 0xd3, 0x37, 0xc3, 0x21,
+	0x6f, 0x65, 0x30, 0x01, 0xf8, 0x10, 0x75, 0x91, 0x37, 0x4a, 0x28, 0x79, 0xb6, 0x13, 0x02, 0xfd,
+	0xaa, 0x94, 0xd4, 0x8d, 0xfe, 0x72, 0xad, 0x83, 0x09, 0xb8, 0xa5, 0x54, 0xa2, 0x10, 0x4a, 0x1c,
+	0x27, 0x91, 0xd3, 0x49, 0x67, 0xde, 0xec, 0xcd, 0xd6, 0x1f, 0xe1, 0xfe, 0x67, 0x00, 0x61, 0x6f,
+	0x3c, 0x46, 0x1a, 0x02, 0x00, 0x00,
+}
@@ -0,0 +1,431 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package raft
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/consensus"
+	"github.com/hyperledger/fabric/consensus/util/events"
+	"github.com/hyperledger/fabric/consensus/util/mocknet"
+	pb "github.com/hyperledger/fabric/protos"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
+)
+
+type noopSecurity struct{}
+
+func (ns *noopSecurity) Sign(msg []byte) ([]byte, error) {
+	return nil, nil
+}
+
+func (ns *noopSecurity) Verify(peerID *pb.PeerID, signature []byte, message []byte) error {
+	return nil
+}
+
+type mockPersist struct {
+	store map[string][]byte
+}
+
+func (p *mockPersist) initialize() {
+	if p.store == nil {
+		p.store = make(map[string][]byte)
+	}
+}
+
+func (p *mockPersist) ReadState(key string) ([]byte, error) {
+	p.initialize()
+	if val, ok := p.store[key]; ok {
+		return val, nil
+	}
+	return nil, fmt.Errorf("cannot find key %s", key)
+}
+
+func (p *mockPersist) ReadStateSet(prefix string) (map[string][]byte, error) {
+	p.initialize()
+	ret := make(map[string][]byte)
+	for k, v := range p.store {
+		if strings.HasPrefix(k, prefix) {
+			ret[k] = v
+		}
+	}
+	return ret, nil
+}
+
+func (p *mockPersist) StoreState(key string, value []byte) error {
+	p.initialize()
+	p.store[key] = value
+	return nil
+}
+
+func (p *mockPersist) DelState(key string) {
+	p.initialize()
+	delete(p.store, key)
+}
+
+// mockLedger appends a block for every committed batch, blocks carry the
+// transactions and the metadata handed to Commit
+type mockLedger struct {
+	mutex   sync.Mutex
+	blocks  []*pb.Block
+	pending []*pb.InBlockTransaction
+}
+
+func newMockLedger() *mockLedger {
+	return &mockLedger{blocks: []*pb.Block{{}}}
+}
+
+func (ml *mockLedger) GetBlock(id uint64) (*pb.Block, error) {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	if id >= uint64(len(ml.blocks)) {
+		return nil, fmt.Errorf("Block %d not found", id)
+	}
+	return ml.blocks[id], nil
+}
+
+func (ml *mockLedger) GetBlockchainSize() uint64 {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	return uint64(len(ml.blocks))
+}
+
+func (ml *mockLedger) GetBlockchainInfo() *pb.BlockchainInfo {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	return ml.info()
+}
+
+func (ml *mockLedger) info() *pb.BlockchainInfo {
+	hash, _ := ml.blocks[len(ml.blocks)-1].GetHash()
+	return &pb.BlockchainInfo{Height: uint64(len(ml.blocks)), CurrentBlockHash: hash}
+}
+
+func (ml *mockLedger) GetBlockchainInfoBlob() []byte {
+	raw, _ := proto.Marshal(ml.GetBlockchainInfo())
+	return raw
+}
+
+func (ml *mockLedger) GetBlockHeadMetadata() ([]byte, error) {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	return ml.blocks[len(ml.blocks)-1].ConsensusMetadata, nil
+}
+
+func (ml *mockLedger) txids() []string {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	var txids []string
+	for _, block := range ml.blocks {
+		for _, tx := range block.Transactions {
+			txids = append(txids, tx.Txid)
+		}
+	}
+	return txids
+}
+
+func (ml *mockLedger) BeginTxBatch(id interface{}) error {
+	return nil
+}
+
+func (ml *mockLedger) ExecTxs(id interface{}, txs []*pb.InBlockTransaction) ([]byte, error) {
+	return nil, fmt.Errorf("Not implemented by the mock ledger")
+}
+
+func (ml *mockLedger) CommitTxBatch(id interface{}, metadata []byte) (*pb.Block, error) {
+	return nil, fmt.Errorf("Not implemented by the mock ledger")
+}
+
+func (ml *mockLedger) RollbackTxBatch(id interface{}) error {
+	return nil
+}
+
+func (ml *mockLedger) PreviewCommitTxBatch(id interface{}, metadata []byte) ([]byte, error) {
+	return nil, fmt.Errorf("Not implemented by the mock ledger")
+}
+
+// raftEndpoint is a replica of the test network, the consenter may be crashed and
+// restarted on top of the same ledger and persisted state
+type raftEndpoint struct {
+	*mocknet.TestEndpoint
+	*noopSecurity
+	*mockLedger
+	*mockPersist
+
+	config    *viper.Viper
+	ledgers   func(*pb.PeerID) *mockLedger
+	mutex     sync.Mutex
+	consenter *obcRaft
+}
+
+func (re *raftEndpoint) start() {
+	re.mutex.Lock()
+	defer re.mutex.Unlock()
+	re.mockLedger.mutex.Lock()
+	re.pending = nil
+	re.mockLedger.mutex.Unlock()
+	re.consenter = newTestRaft(re.config, re)
+}
+
+// coreQueryEvent runs on the event thread, letting the tests inspect the raft core
+type coreQueryEvent func(*raftCore)
+
+type queryableRaft struct {
+	*obcRaft
+}
+
+func (qr queryableRaft) ProcessEvent(event events.Event) events.Event {
+	if query, ok := event.(coreQueryEvent); ok {
+		query(qr.raft)
+		return nil
+	}
+	return qr.obcRaft.ProcessEvent(event)
+}
+
+// newTestRaft mirrors newObcRaft, additionally serving coreQueryEvents
+func newTestRaft(config *viper.Viper, stack consensus.Stack) *obcRaft {
+	op := &obcRaft{}
+	op.manager = events.NewManagerImpl()
+	op.manager.SetReceiver(queryableRaft{op})
+	op.externalEventReceiver.manager = op.manager
+	op.raft = newRaftCore(config, stack, events.NewTimerFactoryImpl(op.manager))
+	op.manager.Start()
+	op.manager.Queue() <- startEvent{}
+	return op
+}
+
+func (re *raftEndpoint) Stop() {
+	re.mutex.Lock()
+	defer re.mutex.Unlock()
+	if re.consenter != nil {
+		re.consenter.Close()
+		re.consenter = nil
+	}
+}
+
+func (re *raftEndpoint) IsBusy() bool {
+	return false
+}
+
+func (re *raftEndpoint) Deliver(msg []byte, senderHandle *pb.PeerID) {
+	re.withConsenter(func(op *obcRaft) {
+		op.RecvMsg(&pb.Message{Type: pb.Message_CONSENSUS, Payload: msg}, senderHandle)
+	})
+}
+
+// withConsenter calls fn unless the replica is crashed
+func (re *raftEndpoint) withConsenter(fn func(*obcRaft)) {
+	re.mutex.Lock()
+	defer re.mutex.Unlock()
+	if re.consenter != nil {
+		fn(re.consenter)
+	}
+}
+
+// core returns a copy of the raft core taken on the event thread, the copy has
+// no stack if the replica is crashed
+func (re *raftEndpoint) core() raftCore {
+	result := make(chan raftCore, 1)
+	running := false
+	re.withConsenter(func(op *obcRaft) {
+		running = true
+		go func() {
+			op.manager.Queue() <- coreQueryEvent(func(core *raftCore) {
+				result <- *core
+			})
+		}()
+	})
+	if !running {
+		return raftCore{}
+	}
+	select {
+	case core := <-result:
+		return core
+	case <-time.After(time.Second):
+		return raftCore{}
+	}
+}
+
+func (re *raftEndpoint) request(txid string) {
+	raw, _ := proto.Marshal(&pb.InBlockTransaction{Txid: txid})
+	re.withConsenter(func(op *obcRaft) {
+		op.RecvMsg(&pb.Message{Type: pb.Message_CHAIN_TRANSACTION, Payload: raw}, re.GetHandle())
+	})
+}
+
+func (re *raftEndpoint) Start() {}
+func (re *raftEndpoint) Halt()  {}
+
+func (re *raftEndpoint) InvalidateState() {}
+func (re *raftEndpoint) ValidateState()   {}
+
+func (re *raftEndpoint) Execute(tag interface{}, txs []*pb.InBlockTransaction) {
+	go func() {
+		re.mockLedger.mutex.Lock()
+		re.pending = append(re.pending, txs...)
+		re.mockLedger.mutex.Unlock()
+		re.withConsenter(func(op *obcRaft) { op.Executed(tag) })
+	}()
+}
+
+func (re *raftEndpoint) Commit(tag interface{}, metadata []byte) {
+	go func() {
+		re.mockLedger.mutex.Lock()
+		block := &pb.Block{Transactions: re.pending, ConsensusMetadata: metadata}
+		block.PreviousBlockHash, _ = re.blocks[len(re.blocks)-1].GetHash()
+		re.blocks = append(re.blocks, block)
+		re.pending = nil
+		info := re.info()
+		re.mockLedger.mutex.Unlock()
+		re.withConsenter(func(op *obcRaft) { op.Committed(tag, info) })
+	}()
+}
+
+func (re *raftEndpoint) Rollback(tag interface{}) {
+	go func() {
+		re.mockLedger.mutex.Lock()
+		re.pending = nil
+		re.mockLedger.mutex.Unlock()
+		re.withConsenter(func(op *obcRaft) { op.RolledBack(tag) })
+	}()
+}
+
+// UpdateState copies the missing blocks from the first peer
+func (re *raftEndpoint) UpdateState(tag interface{}, target *pb.BlockchainInfo, peers []*pb.PeerID) {
+	go func() {
+		time.Sleep(50 * time.Millisecond) // State transfer takes time, not simulating this hides bugs
+		remote := re.ledgers(peers[0])
+		var info *pb.BlockchainInfo
+		re.mockLedger.mutex.Lock()
+		re.pending = nil
+		for n := uint64(len(re.blocks)); n < target.Height; n++ {
+			block, err := remote.GetBlock(n)
+			if err != nil {
+				break
+			}
+			re.blocks = append(re.blocks, block)
+		}
+		if uint64(len(re.blocks)) >= target.Height {
+			info = re.info()
+		}
+		re.mockLedger.mutex.Unlock()
+		re.withConsenter(func(op *obcRaft) { op.StateUpdated(tag, info) })
+	}()
+}
+
+type raftNetwork struct {
+	*mocknet.Testnet
+	replicas []*raftEndpoint
+
+	mutex     sync.Mutex
+	partition map[int]bool
+}
+
+// makeRaftNetwork starts N replicas, the optional function adjusts the configuration
+func makeRaftNetwork(N int, configFn ...func(*viper.Viper)) *raftNetwork {
+	rn := &raftNetwork{replicas: make([]*raftEndpoint, N), partition: make(map[int]bool)}
+
+	endpointFunc := func(id uint64, net *mocknet.Testnet) mocknet.Endpoint {
+		config := loadConfig()
+		config.Set("general.N", N)
+		config.Set("general.batchsize", 2)
+		config.Set("general.timeout.batch", "50ms")
+		config.Set("general.timeout.election", "300ms")
+		config.Set("general.timeout.heartbeat", "50ms")
+		for _, fn := range configFn {
+			fn(config)
+		}
+		re := &raftEndpoint{
+			TestEndpoint: mocknet.MakeTestEndpoint(id, net),
+			noopSecurity: &noopSecurity{},
+			mockLedger:   newMockLedger(),
+			mockPersist:  &mockPersist{},
+			config:       config,
+			ledgers: func(handle *pb.PeerID) *mockLedger {
+				id, _ := mocknet.GetValidatorID(handle)
+				return rn.replicas[id].mockLedger
+			},
+		}
+		rn.replicas[id] = re
+		return re
+	}
+
+	rn.Testnet = mocknet.MakeTestnet(N, endpointFunc)
+	rn.FilterFn = func(src int, dst int, payload []byte) []byte {
+		rn.mutex.Lock()
+		defer rn.mutex.Unlock()
+		if rn.partition[src] || (dst >= 0 && rn.partition[dst]) {
+			return nil
+		}
+		return payload
+	}
+	for _, re := range rn.replicas {
+		re.start()
+	}
+	go rn.ProcessContinually()
+	return rn
+}
+
+// isolate cuts the replica off the network, or connects it back
+func (rn *raftNetwork) isolate(id int, isolated bool) {
+	rn.mutex.Lock()
+	defer rn.mutex.Unlock()
+	rn.partition[id] = isolated
+}
+
+// leader waits for a single replica to be leader of the highest term, and returns it
+func (rn *raftNetwork) leader(timeout time.Duration) (int, error) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		best, term := -1, uint64(0)
+		for id, re := range rn.replicas {
+			rn.mutex.Lock()
+			isolated := rn.partition[id]
+			rn.mutex.Unlock()
+			core := re.core()
+			if isolated || core.stack == nil {
+				continue
+			}
+			if core.role == leader && core.term > term {
+				best, term = id, core.term
+			}
+		}
+		if best >= 0 {
+			return best, nil
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return -1, fmt.Errorf("No leader elected within %v", timeout)
+}
+
+// waitFor polls cond until it holds or the timeout expires
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return cond()
+}
+
+var _ consensus.Stack = &raftEndpoint{}
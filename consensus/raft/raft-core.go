@@ -0,0 +1,706 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package raft
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/hyperledger/fabric/consensus"
+	"github.com/hyperledger/fabric/consensus/util/events"
+	pb "github.com/hyperledger/fabric/protos"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
+)
+
+// =============================================================================
+// custom interfaces and structure definitions
+// =============================================================================
+
+// Event Types
+
+// startEvent is sent once the event manager runs, to arm the election timer
+type startEvent struct{}
+
+// electionTimerEvent is sent when the election timer expires
+type electionTimerEvent struct{}
+
+// heartbeatTimerEvent is sent when the leader should send its heartbeats
+type heartbeatTimerEvent struct{}
+
+// batchTimerEvent is sent when the batch timer expires
+type batchTimerEvent struct{}
+
+type role int
+
+const (
+	follower role = iota
+	candidate
+	leader
+)
+
+func (r role) String() string {
+	switch r {
+	case follower:
+		return "follower"
+	case candidate:
+		return "candidate"
+	}
+	return "leader"
+}
+
+// snapshotTag identifies the state transfer started to catch up with the leader
+type snapshotTag struct {
+	index uint64
+	term  uint64
+}
+
+type raftCore struct {
+	stack consensus.Stack
+	self  string // name of the handle of this replica
+
+	N          int // number of replicas
+	batchSize  int
+	maxAppend  int
+	retention  uint64
+	rnd        *rand.Rand
+	electionTO time.Duration
+	heartbeat  time.Duration
+	batchTO    time.Duration
+
+	electionTimer  events.Timer
+	heartbeatTimer events.Timer
+	batchTimer     events.Timer
+
+	// persistent state
+	term uint64
+	vote string
+
+	// replicated log, the entry at log[i] has index offset+i+1. The entries up to
+	// offset have been executed and discarded.
+	log        []*Entry
+	offset     uint64
+	offsetTerm uint64
+
+	role   role
+	leader string
+	votes  map[string]bool
+
+	commit         uint64             // highest entry known to be stored by a majority
+	applied        uint64             // highest entry executed and committed to the chain
+	lastBlock      uint64             // entry of the last block, the log is never discarded past it
+	lastInfo       *pb.BlockchainInfo // chain info after the last block
+	applying       *Entry             // entry being executed
+	skipInProgress bool               // a state transfer is ongoing
+
+	// leader state
+	nextIndex  map[string]uint64
+	matchIndex map[string]uint64
+	batch      [][]byte
+
+	pending [][]byte // transactions waiting for a leader to be known
+}
+
+func newRaftCore(config *viper.Viper, stack consensus.Stack, etf events.TimerFactory) *raftCore {
+	var err error
+	instance := &raftCore{stack: stack}
+
+	self, _, err := stack.GetNetworkHandles()
+	if err != nil {
+		panic(fmt.Errorf("Cannot retrieve the handle of the replica: %s", err))
+	}
+	instance.self = self.Name
+
+	instance.N = config.GetInt("general.N")
+	if instance.N < 1 {
+		panic(fmt.Errorf("The number of replicas must be positive, got %d", instance.N))
+	}
+	instance.batchSize = config.GetInt("general.batchsize")
+	instance.maxAppend = config.GetInt("general.maxappend")
+	if instance.maxAppend < 1 {
+		instance.maxAppend = 1
+	}
+	instance.retention = uint64(config.GetInt("general.logretention"))
+	if instance.batchTO, err = time.ParseDuration(config.GetString("general.timeout.batch")); err != nil {
+		panic(fmt.Errorf("Cannot parse batch timeout: %s", err))
+	}
+	if instance.electionTO, err = time.ParseDuration(config.GetString("general.timeout.election")); err != nil {
+		panic(fmt.Errorf("Cannot parse election timeout: %s", err))
+	}
+	if instance.heartbeat, err = time.ParseDuration(config.GetString("general.timeout.heartbeat")); err != nil {
+		panic(fmt.Errorf("Cannot parse heartbeat timeout: %s", err))
+	}
+	if instance.heartbeat >= instance.electionTO {
+		instance.heartbeat = instance.electionTO / 3
+		logger.Warningf("Configured heartbeat must be lower than the election timeout, setting to %v", instance.heartbeat)
+	}
+	instance.rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	logger.Infof("Raft replica %s, N = %d", instance.self, instance.N)
+	logger.Infof("Raft batch size = %d", instance.batchSize)
+	logger.Infof("Raft batch timeout = %v", instance.batchTO)
+	logger.Infof("Raft election timeout = %v", instance.electionTO)
+	logger.Infof("Raft heartbeat = %v", instance.heartbeat)
+
+	instance.electionTimer = etf.CreateTimer()
+	instance.heartbeatTimer = etf.CreateTimer()
+	instance.batchTimer = etf.CreateTimer()
+
+	instance.restoreState()
+
+	return instance
+}
+
+// close tears down resources opened by newRaftCore
+func (instance *raftCore) close() {
+	instance.electionTimer.Halt()
+	instance.heartbeatTimer.Halt()
+	instance.batchTimer.Halt()
+}
+
+// ProcessEvent is the main event handling loop of the raft core
+func (instance *raftCore) ProcessEvent(e events.Event) events.Event {
+	switch et := e.(type) {
+	case startEvent:
+		instance.resetElectionTimer()
+	case requestEvent:
+		instance.submit(et.tx)
+	case raftMessageEvent:
+		instance.recvMsg(et.msg, et.sender)
+	case electionTimerEvent:
+		if instance.role != leader {
+			logger.Infof("Replica %s election timer expired in term %d", instance.self, instance.term)
+			instance.becomeCandidate()
+		}
+	case heartbeatTimerEvent:
+		if instance.role == leader {
+			instance.sendHeartbeats()
+		}
+	case batchTimerEvent:
+		if instance.role == leader {
+			logger.Debugf("Replica %s batch timer expired", instance.self)
+			instance.propose()
+		}
+	case executedEvent:
+		instance.stack.Commit(nil, et.tag.([]byte))
+	case committedEvent:
+		instance.committed(et.target)
+	case rolledBackEvent:
+		logger.Warningf("Replica %s received an unexpected rollback", instance.self)
+	case stateUpdatedEvent:
+		instance.stateUpdated(et.snapshot, et.target)
+	case nil:
+		// Used by the tests to check the replica is idle
+	default:
+		logger.Warningf("Replica %s received an unknown event type %T", instance.self, et)
+	}
+	return nil
+}
+
+// =============================================================================
+// helper functions
+// =============================================================================
+
+func (instance *raftCore) quorum() int {
+	return instance.N/2 + 1
+}
+
+func (instance *raftCore) lastIndex() uint64 {
+	return instance.offset + uint64(len(instance.log))
+}
+
+func (instance *raftCore) lastTerm() uint64 {
+	if len(instance.log) == 0 {
+		return instance.offsetTerm
+	}
+	return instance.log[len(instance.log)-1].Term
+}
+
+// termAt returns the term of the entry at index, false if the entry is unknown
+func (instance *raftCore) termAt(index uint64) (uint64, bool) {
+	if index == instance.offset {
+		return instance.offsetTerm, true
+	}
+	if index < instance.offset || index > instance.lastIndex() {
+		return 0, false
+	}
+	return instance.log[index-instance.offset-1].Term, true
+}
+
+func (instance *raftCore) entryAt(index uint64) *Entry {
+	return instance.log[index-instance.offset-1]
+}
+
+// peers returns the handles of the other replicas
+func (instance *raftCore) peers() []string {
+	_, network, err := instance.stack.GetNetworkHandles()
+	if err != nil {
+		logger.Warningf("Replica %s could not retrieve the network: %s", instance.self, err)
+		return nil
+	}
+	var peers []string
+	for _, handle := range network {
+		if handle != nil && handle.Name != instance.self {
+			peers = append(peers, handle.Name)
+		}
+	}
+	return peers
+}
+
+func (instance *raftCore) send(msg *Message, receiver string) {
+	ocMsg, err := wrapMessage(msg)
+	if err != nil {
+		logger.Errorf("Replica %s could not marshal %s message: %s", instance.self, msg.Type, err)
+		return
+	}
+	if err = instance.stack.Unicast(ocMsg, &pb.PeerID{Name: receiver}); err != nil {
+		logger.Debugf("Replica %s could not send %s message to %s: %s", instance.self, msg.Type, receiver, err)
+	}
+}
+
+func (instance *raftCore) broadcast(msg *Message) {
+	ocMsg, err := wrapMessage(msg)
+	if err != nil {
+		logger.Errorf("Replica %s could not marshal %s message: %s", instance.self, msg.Type, err)
+		return
+	}
+	if err = instance.stack.Broadcast(ocMsg, pb.PeerEndpoint_VALIDATOR); err != nil {
+		logger.Debugf("Replica %s could not broadcast %s message: %s", instance.self, msg.Type, err)
+	}
+}
+
+func (instance *raftCore) resetElectionTimer() {
+	timeout := instance.electionTO + time.Duration(instance.rnd.Int63n(int64(instance.electionTO)))
+	instance.electionTimer.Reset(timeout, electionTimerEvent{})
+}
+
+// =============================================================================
+// roles
+// =============================================================================
+
+func (instance *raftCore) becomeFollower(term uint64, leaderName string) {
+	if term > instance.term {
+		instance.term = term
+		instance.vote = ""
+		instance.persistHardState()
+	}
+	if instance.role == leader {
+		instance.heartbeatTimer.Stop()
+		instance.batchTimer.Stop()
+		// Hand over the transactions not proposed yet to the new leader
+		instance.pending = append(instance.pending, instance.batch...)
+		instance.batch = nil
+	}
+	if instance.role != follower || instance.leader != leaderName {
+		logger.Infof("Replica %s is a follower in term %d, leader is %q", instance.self, instance.term, leaderName)
+	}
+	instance.role = follower
+	instance.leader = leaderName
+	instance.resetElectionTimer()
+	if leaderName != "" {
+		instance.forwardPending()
+	}
+}
+
+func (instance *raftCore) becomeCandidate() {
+	instance.role = candidate
+	instance.leader = ""
+	instance.term++
+	instance.vote = instance.self
+	instance.persistHardState()
+	instance.votes = map[string]bool{instance.self: true}
+	instance.resetElectionTimer()
+	logger.Infof("Replica %s is a candidate in term %d", instance.self, instance.term)
+
+	if len(instance.votes) >= instance.quorum() {
+		instance.becomeLeader()
+		return
+	}
+	instance.broadcast(&Message{
+		Type:    Message_VOTE,
+		Term:    instance.term,
+		Index:   instance.lastIndex(),
+		LogTerm: instance.lastTerm(),
+	})
+}
+
+func (instance *raftCore) becomeLeader() {
+	logger.Infof("Replica %s is the leader in term %d", instance.self, instance.term)
+	instance.role = leader
+	instance.leader = instance.self
+	instance.electionTimer.Stop()
+	instance.nextIndex = make(map[string]uint64)
+	instance.matchIndex = make(map[string]uint64)
+
+	// Entries of the previous terms are only committed along with an entry of the current term
+	instance.appendEntries([]*Entry{{Term: instance.term, Index: instance.lastIndex() + 1}})
+	instance.batch = append(instance.batch, instance.pending...)
+	instance.pending = nil
+	instance.propose()
+	instance.sendHeartbeats()
+	instance.maybeCommit()
+}
+
+// =============================================================================
+// requests
+// =============================================================================
+
+// submit orders a transaction, on the leader directly or by forwarding it to the leader
+func (instance *raftCore) submit(tx []byte) {
+	switch {
+	case instance.role == leader:
+		instance.batch = append(instance.batch, tx)
+		if len(instance.batch) >= instance.batchSize {
+			instance.propose()
+		} else if len(instance.batch) == 1 {
+			instance.batchTimer.Reset(instance.batchTO, batchTimerEvent{})
+		}
+	case instance.leader != "":
+		instance.send(&Message{Type: Message_REQUEST, Term: instance.term, Payload: tx}, instance.leader)
+	default:
+		logger.Debugf("Replica %s has no leader, keeping the request until one is elected", instance.self)
+		instance.pending = append(instance.pending, tx)
+	}
+}
+
+func (instance *raftCore) forwardPending() {
+	pending := instance.pending
+	instance.pending = nil
+	for _, tx := range pending {
+		instance.submit(tx)
+	}
+}
+
+// propose appends the batch to the log of the leader and replicates it
+func (instance *raftCore) propose() {
+	instance.batchTimer.Stop()
+	if len(instance.batch) == 0 {
+		return
+	}
+	entry := &Entry{Term: instance.term, Index: instance.lastIndex() + 1, Txs: instance.batch}
+	instance.batch = nil
+	logger.Infof("Replica %s proposing entry %d with %d transactions", instance.self, entry.Index, len(entry.Txs))
+	instance.appendEntries([]*Entry{entry})
+	instance.sendHeartbeats()
+	instance.maybeCommit()
+}
+
+// =============================================================================
+// receive methods
+// =============================================================================
+
+func (instance *raftCore) recvMsg(msg *Message, sender string) {
+	if msg.Type == Message_REQUEST {
+		instance.submit(msg.Payload)
+		return
+	}
+
+	if msg.Term > instance.term {
+		from := ""
+		if msg.Type == Message_APPEND || msg.Type == Message_SNAPSHOT {
+			from = sender
+		}
+		instance.becomeFollower(msg.Term, from)
+	} else if msg.Term < instance.term {
+		// Let a stale leader or candidate know about the current term
+		switch msg.Type {
+		case Message_APPEND, Message_SNAPSHOT:
+			instance.send(&Message{Type: Message_APPEND_RESPONSE, Term: instance.term, Reject: true}, sender)
+		case Message_VOTE:
+			instance.send(&Message{Type: Message_VOTE_RESPONSE, Term: instance.term, Reject: true}, sender)
+		}
+		return
+	}
+
+	switch msg.Type {
+	case Message_VOTE:
+		instance.recvVote(msg, sender)
+	case Message_VOTE_RESPONSE:
+		instance.recvVoteResponse(msg, sender)
+	case Message_APPEND:
+		instance.recvAppend(msg, sender)
+	case Message_APPEND_RESPONSE:
+		instance.recvAppendResponse(msg, sender)
+	case Message_SNAPSHOT:
+		instance.recvSnapshot(msg, sender)
+	default:
+		logger.Warningf("Replica %s received an unknown message type %s from %s", instance.self, msg.Type, sender)
+	}
+}
+
+func (instance *raftCore) recvVote(msg *Message, sender string) {
+	upToDate := msg.LogTerm > instance.lastTerm() ||
+		(msg.LogTerm == instance.lastTerm() && msg.Index >= instance.lastIndex())
+	grant := (instance.vote == "" || instance.vote == sender) && upToDate && instance.role != leader
+	if grant {
+		logger.Debugf("Replica %s votes for %s in term %d", instance.self, sender, instance.term)
+		instance.vote = sender
+		instance.persistHardState()
+		instance.resetElectionTimer()
+	}
+	instance.send(&Message{Type: Message_VOTE_RESPONSE, Term: instance.term, Reject: !grant}, sender)
+}
+
+func (instance *raftCore) recvVoteResponse(msg *Message, sender string) {
+	if instance.role != candidate || msg.Reject {
+		return
+	}
+	instance.votes[sender] = true
+	if len(instance.votes) >= instance.quorum() {
+		instance.becomeLeader()
+	}
+}
+
+func (instance *raftCore) recvAppend(msg *Message, sender string) {
+	if instance.role != follower || instance.leader != sender {
+		instance.becomeFollower(msg.Term, sender)
+	} else {
+		instance.resetElectionTimer()
+	}
+	if instance.skipInProgress {
+		return
+	}
+
+	prev, entries := msg.Index, msg.Entries
+	if prev < instance.offset {
+		// The entries up to the offset were committed, they match those of the leader
+		for len(entries) > 0 && entries[0].Index <= instance.offset {
+			entries = entries[1:]
+		}
+		prev = instance.offset
+	} else if term, ok := instance.termAt(prev); !ok || term != msg.LogTerm {
+		hint := prev - 1
+		if prev > instance.lastIndex() {
+			hint = instance.lastIndex()
+		}
+		logger.Debugf("Replica %s rejecting append after entry %d from %s", instance.self, prev, sender)
+		instance.send(&Message{Type: Message_APPEND_RESPONSE, Term: instance.term, Index: hint, Reject: true}, sender)
+		return
+	}
+
+	for i, entry := range entries {
+		if term, ok := instance.termAt(entry.Index); ok {
+			if term == entry.Term {
+				continue
+			}
+			logger.Infof("Replica %s discarding its conflicting entries from %d", instance.self, entry.Index)
+			instance.truncateLog(entry.Index)
+		}
+		instance.appendEntries(entries[i:])
+		break
+	}
+
+	last := prev + uint64(len(entries))
+	if msg.Commit > instance.commit {
+		instance.commit = msg.Commit
+		if instance.commit > last {
+			instance.commit = last
+		}
+	}
+	instance.send(&Message{Type: Message_APPEND_RESPONSE, Term: instance.term, Index: last}, sender)
+	instance.applyCommitted()
+}
+
+func (instance *raftCore) recvAppendResponse(msg *Message, sender string) {
+	if instance.role != leader {
+		return
+	}
+	if msg.Reject {
+		// The hint is the last entry the follower may share with us
+		instance.nextIndex[sender] = msg.Index + 1
+		instance.sendAppend(sender)
+		return
+	}
+	if msg.Index > instance.matchIndex[sender] {
+		instance.matchIndex[sender] = msg.Index
+	}
+	instance.nextIndex[sender] = instance.matchIndex[sender] + 1
+	if instance.maybeCommit() {
+		instance.sendHeartbeats()
+	} else if instance.nextIndex[sender] <= instance.lastIndex() {
+		instance.sendAppend(sender)
+	}
+}
+
+func (instance *raftCore) recvSnapshot(msg *Message, sender string) {
+	if instance.role != follower || instance.leader != sender {
+		instance.becomeFollower(msg.Term, sender)
+	} else {
+		instance.resetElectionTimer()
+	}
+	if instance.skipInProgress || instance.applying != nil {
+		// The leader sends it again with its next heartbeat
+		return
+	}
+	if msg.Index <= instance.applied {
+		instance.send(&Message{Type: Message_APPEND_RESPONSE, Term: instance.term, Index: instance.applied, Reject: true}, sender)
+		return
+	}
+	target := &pb.BlockchainInfo{}
+	if err := proto.Unmarshal(msg.Payload, target); err != nil {
+		logger.Errorf("Replica %s could not unmarshal the snapshot from %s: %s", instance.self, sender, err)
+		return
+	}
+	logger.Infof("Replica %s transferring the state of the chain up to entry %d from %s", instance.self, msg.Index, sender)
+	instance.skipInProgress = true
+	instance.stack.InvalidateState()
+	instance.stack.UpdateState(&snapshotTag{index: msg.Index, term: msg.LogTerm}, target, []*pb.PeerID{{Name: sender}})
+}
+
+// =============================================================================
+// replication
+// =============================================================================
+
+func (instance *raftCore) sendHeartbeats() {
+	for _, peer := range instance.peers() {
+		instance.sendAppend(peer)
+	}
+	instance.heartbeatTimer.Reset(instance.heartbeat, heartbeatTimerEvent{})
+}
+
+// sendAppend sends to the replica the entries it misses, or asks it to transfer the
+// state of the chain if the leader no longer has them
+func (instance *raftCore) sendAppend(peer string) {
+	next, ok := instance.nextIndex[peer]
+	if !ok {
+		next = instance.lastIndex() + 1
+	}
+	prev := next - 1
+	prevTerm, ok := instance.termAt(prev)
+	if !ok {
+		if instance.lastInfo == nil {
+			return
+		}
+		payload, err := proto.Marshal(instance.lastInfo)
+		if err != nil {
+			logger.Errorf("Replica %s could not marshal the blockchain info: %s", instance.self, err)
+			return
+		}
+		term, _ := instance.termAt(instance.lastBlock)
+		instance.send(&Message{Type: Message_SNAPSHOT, Term: instance.term, Index: instance.lastBlock, LogTerm: term, Payload: payload}, peer)
+		return
+	}
+	var entries []*Entry
+	for index := next; index <= instance.lastIndex() && len(entries) < instance.maxAppend; index++ {
+		entries = append(entries, instance.entryAt(index))
+	}
+	instance.send(&Message{
+		Type:    Message_APPEND,
+		Term:    instance.term,
+		Index:   prev,
+		LogTerm: prevTerm,
+		Entries: entries,
+		Commit:  instance.commit,
+	}, peer)
+}
+
+// maybeCommit advances the commit index to the last entry of the current term stored
+// by a majority, it returns whether the commit index changed
+func (instance *raftCore) maybeCommit() bool {
+	for index := instance.lastIndex(); index > instance.commit; index-- {
+		if term, _ := instance.termAt(index); term != instance.term {
+			break
+		}
+		count := 1
+		for _, match := range instance.matchIndex {
+			if match >= index {
+				count++
+			}
+		}
+		if count >= instance.quorum() {
+			logger.Debugf("Replica %s committing up to entry %d", instance.self, index)
+			instance.commit = index
+			instance.applyCommitted()
+			return true
+		}
+	}
+	return false
+}
+
+// =============================================================================
+// execution
+// =============================================================================
+
+// applyCommitted executes the next committed entry, unless one is already executing
+func (instance *raftCore) applyCommitted() {
+	for instance.applying == nil && !instance.skipInProgress && instance.applied < instance.commit {
+		entry := instance.entryAt(instance.applied + 1)
+		var txs []*pb.InBlockTransaction
+		for _, raw := range entry.Txs {
+			tx := &pb.InBlockTransaction{}
+			if err := proto.Unmarshal(raw, tx); err != nil {
+				logger.Warningf("Replica %s could not unmarshal transaction of entry %d: %s", instance.self, entry.Index, err)
+				continue
+			}
+			txs = append(txs, tx)
+		}
+		if len(txs) == 0 {
+			instance.applied = entry.Index
+			continue
+		}
+		meta, _ := proto.Marshal(&Metadata{Index: entry.Index, Term: entry.Term})
+		logger.Debugf("Replica %s executing entry %d containing %d transactions", instance.self, entry.Index, len(txs))
+		instance.applying = entry
+		instance.stack.Execute(meta, txs) // This executes in the background, we will receive an executedEvent once it completes
+	}
+}
+
+func (instance *raftCore) committed(target *pb.BlockchainInfo) {
+	if instance.applying == nil {
+		logger.Warningf("Replica %s received a commit without executing an entry", instance.self)
+		return
+	}
+	logger.Debugf("Replica %s committed entry %d as block %d", instance.self, instance.applying.Index, target.Height-1)
+	instance.applied = instance.applying.Index
+	instance.lastBlock = instance.applied
+	instance.lastInfo = target
+	instance.applying = nil
+	instance.compactLog()
+	instance.applyCommitted()
+}
+
+func (instance *raftCore) stateUpdated(snapshot *snapshotTag, target *pb.BlockchainInfo) {
+	instance.skipInProgress = false
+	if target == nil {
+		logger.Warningf("Replica %s could not transfer the state of the chain, waiting for the leader to retry", instance.self)
+		return
+	}
+	instance.stack.ValidateState()
+	logger.Infof("Replica %s transferred the state of the chain up to entry %d", instance.self, snapshot.index)
+	instance.discardLog(snapshot.index, snapshot.term)
+	instance.applied = snapshot.index
+	instance.lastBlock = snapshot.index
+	if instance.commit < snapshot.index {
+		instance.commit = snapshot.index
+	}
+	instance.lastInfo = target
+	if instance.leader != "" {
+		instance.send(&Message{Type: Message_APPEND_RESPONSE, Term: instance.term, Index: snapshot.index}, instance.leader)
+	}
+}
+
+// compactLog discards the executed entries beyond the retention
+func (instance *raftCore) compactLog() {
+	if instance.lastBlock < instance.offset+2*instance.retention {
+		return
+	}
+	index := instance.lastBlock - instance.retention
+	term, _ := instance.termAt(index)
+	instance.discardLog(index, term)
+}
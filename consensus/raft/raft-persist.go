@@ -0,0 +1,171 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package raft
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	hardStateKey   = "raft.hardstate"
+	entryKeyPrefix = "raft.entry."
+)
+
+func entryKey(index uint64) string {
+	return fmt.Sprintf("%s%020d", entryKeyPrefix, index)
+}
+
+func (instance *raftCore) persistHardState() {
+	raw, err := proto.Marshal(&HardState{Term: instance.term, Vote: instance.vote})
+	if err != nil {
+		logger.Warningf("Replica %s could not persist hard state: %s", instance.self, err)
+		return
+	}
+	if err = instance.stack.StoreState(hardStateKey, raw); err != nil {
+		logger.Warningf("Replica %s could not persist hard state: %s", instance.self, err)
+	}
+}
+
+func (instance *raftCore) persistEntry(entry *Entry) {
+	raw, err := proto.Marshal(entry)
+	if err != nil {
+		logger.Warningf("Replica %s could not persist entry %d: %s", instance.self, entry.Index, err)
+		return
+	}
+	if err = instance.stack.StoreState(entryKey(entry.Index), raw); err != nil {
+		logger.Warningf("Replica %s could not persist entry %d: %s", instance.self, entry.Index, err)
+	}
+}
+
+// appendEntries adds entries to the end of the log, persisting them first
+func (instance *raftCore) appendEntries(entries []*Entry) {
+	for _, entry := range entries {
+		instance.persistEntry(entry)
+		instance.log = append(instance.log, entry)
+	}
+}
+
+// truncateLog drops the entries from index onwards
+func (instance *raftCore) truncateLog(index uint64) {
+	for i := index; i <= instance.lastIndex(); i++ {
+		instance.stack.DelState(entryKey(i))
+	}
+	instance.log = instance.log[:index-instance.offset-1]
+}
+
+// discardLog drops the entries up to index, which becomes the new offset
+func (instance *raftCore) discardLog(index uint64, term uint64) {
+	for i := instance.offset + 1; i <= index && i <= instance.lastIndex(); i++ {
+		instance.stack.DelState(entryKey(i))
+	}
+	if t, ok := instance.termAt(index); ok && t == term && index <= instance.lastIndex() {
+		instance.log = append([]*Entry(nil), instance.log[index-instance.offset:]...)
+	} else {
+		// The log does not contain the entry, keep nothing that may conflict with it
+		for i := index + 1; i <= instance.lastIndex(); i++ {
+			instance.stack.DelState(entryKey(i))
+		}
+		instance.log = nil
+	}
+	instance.offset = index
+	instance.offsetTerm = term
+	logger.Debugf("Replica %s discarded its log up to entry %d", instance.self, index)
+}
+
+func (instance *raftCore) restoreLastApplied() {
+	raw, err := instance.stack.GetBlockHeadMetadata()
+	if err != nil || len(raw) == 0 {
+		logger.Debugf("Replica %s found no raft metadata in the head block", instance.self)
+		return
+	}
+	meta := &Metadata{}
+	if err = proto.Unmarshal(raw, meta); err != nil {
+		logger.Warningf("Replica %s could not unmarshal the head block metadata: %s", instance.self, err)
+		return
+	}
+	instance.offset = meta.Index
+	instance.offsetTerm = meta.Term
+}
+
+func (instance *raftCore) restoreState() {
+	instance.restoreLastApplied()
+	instance.applied = instance.offset
+	instance.commit = instance.offset
+	instance.lastBlock = instance.offset
+	instance.lastInfo = instance.stack.GetBlockchainInfo()
+
+	if raw, err := instance.stack.ReadState(hardStateKey); err == nil {
+		hs := &HardState{}
+		if err = proto.Unmarshal(raw, hs); err != nil {
+			logger.Errorf("Replica %s could not unmarshal hard state - local state is damaged: %s", instance.self, err)
+		} else {
+			instance.term = hs.Term
+			instance.vote = hs.Vote
+		}
+	} else {
+		logger.Debugf("Replica %s could not restore hard state: %s", instance.self, err)
+	}
+
+	packed, err := instance.stack.ReadStateSet(entryKeyPrefix)
+	if err != nil {
+		logger.Warningf("Replica %s could not restore the log: %s", instance.self, err)
+		packed = nil
+	}
+	entries := make(map[uint64]*Entry)
+	var indexes []uint64
+	for key, raw := range packed {
+		entry := &Entry{}
+		if err = proto.Unmarshal(raw, entry); err != nil || key != entryKey(entry.Index) {
+			logger.Warningf("Replica %s could not restore log entry %s", instance.self, key)
+			instance.stack.DelState(key)
+			continue
+		}
+		entries[entry.Index] = entry
+		indexes = append(indexes, entry.Index)
+	}
+	sort.Sort(sortableUint64Slice(indexes))
+	for _, index := range indexes {
+		if index == instance.lastIndex()+1 {
+			instance.log = append(instance.log, entries[index])
+		} else {
+			// Executed already, or not following on the log after a hole
+			instance.stack.DelState(entryKey(index))
+		}
+	}
+	if instance.term < instance.lastTerm() {
+		instance.term = instance.lastTerm()
+		instance.vote = ""
+	}
+
+	logger.Infof("Replica %s restored state: term: %d, vote: %q, applied: %d, log: %d-%d",
+		instance.self, instance.term, instance.vote, instance.applied, instance.offset+1, instance.lastIndex())
+}
+
+type sortableUint64Slice []uint64
+
+func (a sortableUint64Slice) Len() int {
+	return len(a)
+}
+func (a sortableUint64Slice) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
+func (a sortableUint64Slice) Less(i, j int) bool {
+	return a[i] < a[j]
+}
@@ -0,0 +1,122 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package raft
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric/consensus"
+	"github.com/hyperledger/fabric/consensus/util/events"
+	pb "github.com/hyperledger/fabric/protos"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/op/go-logging"
+	"github.com/spf13/viper"
+)
+
+const configPrefix = "CORE_RAFT"
+
+var logger *logging.Logger             // package-level logger
+var pluginInstance consensus.Consenter // singleton service
+var config *viper.Viper
+
+func init() {
+	logger = logging.MustGetLogger("consensus/raft")
+	config = loadConfig()
+}
+
+// GetPlugin returns the handle to the Consenter singleton
+func GetPlugin(c consensus.Stack) consensus.Consenter {
+	if pluginInstance == nil {
+		pluginInstance = New(c)
+	}
+	return pluginInstance
+}
+
+// New creates a new raft instance that provides the Consenter interface.
+func New(stack consensus.Stack) consensus.Consenter {
+	return newObcRaft(config, stack)
+}
+
+func loadConfig() (config *viper.Viper) {
+	config = viper.New()
+
+	// for environment variables
+	config.SetEnvPrefix(configPrefix)
+	config.AutomaticEnv()
+	replacer := strings.NewReplacer(".", "_")
+	config.SetEnvKeyReplacer(replacer)
+
+	config.SetConfigName("config")
+	config.AddConfigPath("./")
+	config.AddConfigPath("../consensus/raft/")
+	config.AddConfigPath("../../consensus/raft")
+	// Path to look for the config file in based on GOPATH
+	gopath := os.Getenv("GOPATH")
+	for _, p := range filepath.SplitList(gopath) {
+		raftpath := filepath.Join(p, "src/github.com/hyperledger/fabric/consensus/raft")
+		config.AddConfigPath(raftpath)
+	}
+
+	err := config.ReadInConfig()
+	if err != nil {
+		panic(fmt.Errorf("Error reading %s plugin config: %s", configPrefix, err))
+	}
+	return
+}
+
+// obcRaft glues the raft core to the stack: it turns the messages and the execution
+// callbacks of the stack into events processed serially by the core.
+type obcRaft struct {
+	externalEventReceiver
+	raft    *raftCore
+	manager events.Manager
+}
+
+func newObcRaft(config *viper.Viper, stack consensus.Stack) *obcRaft {
+	op := &obcRaft{}
+	op.manager = events.NewManagerImpl()
+	op.manager.SetReceiver(op)
+	op.externalEventReceiver.manager = op.manager
+	op.raft = newRaftCore(config, stack, events.NewTimerFactoryImpl(op.manager))
+	op.manager.Start()
+	op.manager.Queue() <- startEvent{}
+	return op
+}
+
+// Close tells us to release resources we are holding
+func (op *obcRaft) Close() {
+	op.raft.close()
+	op.manager.Halt()
+}
+
+// ProcessEvent hands the events over to the raft core
+func (op *obcRaft) ProcessEvent(event events.Event) events.Event {
+	return op.raft.ProcessEvent(event)
+}
+
+// wrapMessage packs a raft message into a Fabric message
+func wrapMessage(msg *Message) (*pb.Message, error) {
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Message{Type: pb.Message_CONSENSUS, Payload: payload}, nil
+}
@@ -0,0 +1,222 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package raft
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/op/go-logging"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	logging.SetLevel(logging.WARNING, "")
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+func requests(re *raftEndpoint, from int, to int) []string {
+	var txids []string
+	for i := from; i < to; i++ {
+		txid := fmt.Sprintf("tx%d", i)
+		re.request(txid)
+		txids = append(txids, txid)
+	}
+	return txids
+}
+
+// checkLedgers waits for the ledgers of the replicas to contain the expected transactions
+func checkLedgers(t *testing.T, rn *raftNetwork, replicas []int, expected []string) {
+	ok := waitFor(5*time.Second, func() bool {
+		for _, id := range replicas {
+			if !reflect.DeepEqual(rn.replicas[id].txids(), expected) {
+				return false
+			}
+		}
+		return true
+	})
+	if !ok {
+		for _, id := range replicas {
+			t.Logf("Replica %d has transactions %v", id, rn.replicas[id].txids())
+		}
+		t.Fatalf("Expected replicas %v to have transactions %v", replicas, expected)
+	}
+}
+
+func TestReplication(t *testing.T) {
+	rn := makeRaftNetwork(3)
+	defer rn.Stop()
+
+	leader, err := rn.leader(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	follower := (leader + 1) % 3
+
+	// Requests to a follower are forwarded to the leader
+	expected := requests(rn.replicas[follower], 0, 5)
+	checkLedgers(t, rn, []int{0, 1, 2}, expected)
+
+	for id, re := range rn.replicas {
+		raw, err := re.GetBlockHeadMetadata()
+		if err != nil {
+			t.Fatalf("Replica %d could not read the head metadata: %s", id, err)
+		}
+		meta := &Metadata{}
+		if err = proto.Unmarshal(raw, meta); err != nil || meta.Index == 0 || meta.Term == 0 {
+			t.Errorf("Replica %d has unexpected head metadata %v: %v", id, meta, err)
+		}
+	}
+}
+
+func TestSingleReplica(t *testing.T) {
+	rn := makeRaftNetwork(1)
+	defer rn.Stop()
+
+	if _, err := rn.leader(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	checkLedgers(t, rn, []int{0}, requests(rn.replicas[0], 0, 3))
+}
+
+func TestLeaderCrash(t *testing.T) {
+	rn := makeRaftNetwork(3)
+	defer rn.Stop()
+
+	leader, err := rn.leader(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := requests(rn.replicas[leader], 0, 4)
+	checkLedgers(t, rn, []int{0, 1, 2}, expected)
+
+	rn.isolate(leader, true)
+	rn.replicas[leader].Stop()
+
+	newLeader, err := rn.leader(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newLeader == leader {
+		t.Fatalf("Expected a new leader after replica %d crashed", leader)
+	}
+	var alive []int
+	for id := range rn.replicas {
+		if id != leader {
+			alive = append(alive, id)
+		}
+	}
+	expected = append(expected, requests(rn.replicas[newLeader], 4, 8)...)
+	checkLedgers(t, rn, alive, expected)
+
+	// The crashed replica catches up once restarted
+	rn.replicas[leader].start()
+	rn.isolate(leader, false)
+	checkLedgers(t, rn, []int{0, 1, 2}, expected)
+}
+
+func TestRestartRestoresState(t *testing.T) {
+	rn := makeRaftNetwork(3)
+	defer rn.Stop()
+
+	leader, err := rn.leader(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := requests(rn.replicas[leader], 0, 4)
+	checkLedgers(t, rn, []int{0, 1, 2}, expected)
+
+	terms := make([]uint64, 3)
+	for id, re := range rn.replicas {
+		terms[id] = re.core().term
+		re.Stop()
+	}
+
+	for id, re := range rn.replicas {
+		if _, err := re.ReadState(hardStateKey); err != nil {
+			t.Errorf("Replica %d did not persist its hard state: %s", id, err)
+		}
+		re.start()
+		core := re.core()
+		if core.term < terms[id] {
+			t.Errorf("Replica %d restarted in term %d, was in term %d", id, core.term, terms[id])
+		}
+		if core.applied == 0 || core.applied != core.offset {
+			t.Errorf("Replica %d restored applied entry %d with log offset %d", id, core.applied, core.offset)
+		}
+	}
+
+	leader, err = rn.leader(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The committed entries must not be executed again
+	expected = append(expected, requests(rn.replicas[leader], 4, 6)...)
+	checkLedgers(t, rn, []int{0, 1, 2}, expected)
+}
+
+func TestSnapshotCatchUp(t *testing.T) {
+	rn := makeRaftNetwork(3, func(config *viper.Viper) {
+		config.Set("general.logretention", 1)
+	})
+	defer rn.Stop()
+
+	leader, err := rn.leader(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lagging := (leader + 1) % 3
+	rn.isolate(lagging, true)
+
+	var alive []int
+	for id := range rn.replicas {
+		if id != lagging {
+			alive = append(alive, id)
+		}
+	}
+	leader, err = rn.leader(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := requests(rn.replicas[leader], 0, 10)
+	checkLedgers(t, rn, alive, expected)
+
+	if ok := waitFor(5*time.Second, func() bool { return rn.replicas[leader].core().offset > 1 }); !ok {
+		t.Fatalf("Expected the leader to discard the executed entries of its log")
+	}
+	if entries, _ := rn.replicas[leader].ReadStateSet(entryKeyPrefix); len(entries) > len(rn.replicas[leader].core().log) {
+		t.Errorf("Expected the discarded entries to be deleted, %d are persisted", len(entries))
+	}
+
+	rn.isolate(lagging, false)
+	checkLedgers(t, rn, []int{0, 1, 2}, expected)
+
+	// The replica keeps up with the new entries after the state transfer
+	leader, err = rn.leader(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = append(expected, requests(rn.replicas[leader], 10, 12)...)
+	checkLedgers(t, rn, []int{0, 1, 2}, expected)
+}
@@ -0,0 +1,331 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mocknet provides an in-memory network of validating peers, used to test the consensus plugins
+package mocknet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("consensus/mocknet")
+
+// Endpoint is a replica of the test network
+type Endpoint interface {
+	Stop()
+	Deliver([]byte, *pb.PeerID)
+	GetHandle() *pb.PeerID
+	GetID() uint64
+	IsBusy() bool
+}
+
+// TaggedMsg is a message in transit from the replica Src to the replica Dst, -1 for a broadcast
+type TaggedMsg struct {
+	Src int
+	Dst int
+	Msg []byte
+}
+
+// Testnet delivers the messages between its endpoints. FilterFn, if set, can alter or drop each message
+type Testnet struct {
+	Debug     bool
+	N         int
+	Closed    chan struct{}
+	Endpoints []Endpoint
+	Msgs      chan TaggedMsg
+	FilterFn  func(int, int, []byte) []byte
+}
+
+// TestEndpoint implements the network part of the consensus stack of an endpoint
+type TestEndpoint struct {
+	ID  uint64
+	Net *Testnet
+}
+
+// MakeTestEndpoint creates the network stack of the endpoint id of net
+func MakeTestEndpoint(id uint64, net *Testnet) *TestEndpoint {
+	ep := &TestEndpoint{}
+	ep.ID = id
+	ep.Net = net
+	return ep
+}
+
+// GetValidatorID returns the ID of the endpoint whose handle is given
+func GetValidatorID(handle *pb.PeerID) (uint64, error) {
+	if !strings.HasPrefix(handle.Name, "vp") {
+		return 0, fmt.Errorf("Unexpected handle %s", handle.Name)
+	}
+	return strconv.ParseUint(handle.Name[2:], 10, 64)
+}
+
+// GetID returns the ID of the endpoint
+func (ep *TestEndpoint) GetID() uint64 {
+	return ep.ID
+}
+
+// GetHandle returns the handle of the endpoint
+func (ep *TestEndpoint) GetHandle() *pb.PeerID {
+	return &pb.PeerID{Name: fmt.Sprintf("vp%d", ep.ID)}
+}
+
+// GetNetworkInfo returns the endpoints of the replica and of the whole network
+func (ep *TestEndpoint) GetNetworkInfo() (self *pb.PeerEndpoint, network []*pb.PeerEndpoint, err error) {
+	oSelf, oNetwork, _ := ep.GetNetworkHandles()
+	self = &pb.PeerEndpoint{
+		ID:   oSelf,
+		Type: pb.PeerEndpoint_VALIDATOR,
+	}
+
+	network = make([]*pb.PeerEndpoint, len(oNetwork))
+	for i, id := range oNetwork {
+		network[i] = &pb.PeerEndpoint{
+			ID:   id,
+			Type: pb.PeerEndpoint_VALIDATOR,
+		}
+	}
+	return
+}
+
+// GetNetworkHandles returns the handles of the replica and of the whole network
+func (ep *TestEndpoint) GetNetworkHandles() (self *pb.PeerID, network []*pb.PeerID, err error) {
+	if nil == ep.Net {
+		err = fmt.Errorf("Network not initialized")
+		return
+	}
+	self = ep.GetHandle()
+	network = make([]*pb.PeerID, len(ep.Net.Endpoints))
+	for i, oep := range ep.Net.Endpoints {
+		if nil != oep {
+			// In case this is invoked before all endpoints are initialized, this emulates a real network as well
+			network[i] = oep.GetHandle()
+		}
+	}
+	return
+}
+
+// Broadcast delivers to all endpoints.  In contrast to the stack
+// Broadcast, this will also deliver back to the replica.  We keep
+// this behavior, because it exposes subtle bugs in the
+// implementation.
+func (ep *TestEndpoint) Broadcast(msg *pb.Message, peerType pb.PeerEndpoint_Type) error {
+	ep.Net.broadcastFilter(ep, msg.Payload)
+	return nil
+}
+
+// Unicast delivers the message to the endpoint whose handle is given
+func (ep *TestEndpoint) Unicast(msg *pb.Message, receiverHandle *pb.PeerID) error {
+	receiverID, err := GetValidatorID(receiverHandle)
+	if err != nil {
+		return fmt.Errorf("Couldn't unicast message to %s: %v", receiverHandle.Name, err)
+	}
+	internalQueueMessage(ep.Net.Msgs, TaggedMsg{int(ep.ID), int(receiverID), msg.Payload})
+	return nil
+}
+
+func internalQueueMessage(queue chan<- TaggedMsg, tm TaggedMsg) {
+	select {
+	case queue <- tm:
+	default:
+		logger.Warning("Message cannot be queued without blocking, consider increasing the queue size")
+		queue <- tm
+	}
+}
+
+// DebugMsg logs the message if the network is in debug mode
+func (net *Testnet) DebugMsg(msg string, args ...interface{}) {
+	if net.Debug {
+		logger.Debugf(msg, args...)
+	}
+}
+
+func (net *Testnet) broadcastFilter(ep *TestEndpoint, payload []byte) {
+	select {
+	case <-net.Closed:
+		logger.Warning("Attempted to send a request to a closed network, ignoring")
+		return
+	default:
+	}
+	if net.FilterFn != nil {
+		payload = net.FilterFn(int(ep.ID), -1, payload)
+		net.DebugMsg("TEST: filtered message\n")
+	}
+	if payload != nil {
+		net.DebugMsg("TEST: attempting to queue message %p\n", payload)
+		internalQueueMessage(net.Msgs, TaggedMsg{int(ep.ID), -1, payload})
+		net.DebugMsg("TEST: message queued successfully %p\n", payload)
+	} else {
+		net.DebugMsg("TEST: suppressing message with payload %p\n", payload)
+	}
+}
+
+func (net *Testnet) deliverFilter(msg TaggedMsg) {
+	net.DebugMsg("TEST: deliver\n")
+	senderHandle := net.Endpoints[msg.Src].GetHandle()
+	if msg.Dst == -1 {
+		net.DebugMsg("TEST: Sending broadcast %v\n", net.Endpoints)
+		wg := &sync.WaitGroup{}
+		wg.Add(len(net.Endpoints))
+		for id, ep := range net.Endpoints {
+			net.DebugMsg("TEST: Looping broadcast %d\n", ep.GetID())
+			lid := id
+			lep := ep
+			go func() {
+				defer wg.Done()
+				if msg.Src == lid {
+					net.DebugMsg("TEST: Skipping local delivery %d %d\n", lid, msg.Src)
+					// do not deliver to local replica
+					return
+				}
+				payload := msg.Msg
+				net.DebugMsg("TEST: Filtering %d\n", lid)
+				if net.FilterFn != nil {
+					payload = net.FilterFn(msg.Src, lid, payload)
+				}
+				net.DebugMsg("TEST: Delivering %d\n", lid)
+				if payload != nil {
+					net.DebugMsg("TEST: Sending message %d\n", lid)
+					lep.Deliver(payload, senderHandle)
+					net.DebugMsg("TEST: Sent message %d\n", lid)
+				} else {
+					net.DebugMsg("TEST: Message to %d was skipped\n", lid)
+				}
+			}()
+		}
+		wg.Wait()
+	} else {
+		payload := msg.Msg
+		net.DebugMsg("TEST: Filtering %d\n", msg.Dst)
+		if net.FilterFn != nil {
+			payload = net.FilterFn(msg.Src, msg.Dst, payload)
+		}
+		if payload != nil {
+			net.DebugMsg("TEST: Sending unicast\n")
+			net.Endpoints[msg.Dst].Deliver(msg.Msg, senderHandle)
+		}
+	}
+}
+
+func (net *Testnet) processMessageFromChannel(msg TaggedMsg, ok bool) bool {
+	if !ok {
+		net.DebugMsg("TEST: message channel closed, exiting\n")
+		return false
+	}
+	net.DebugMsg("TEST: new message, delivering\n")
+	net.deliverFilter(msg)
+	return true
+}
+
+// Process delivers the messages until the network is idle: no message is in transit and no endpoint is busy
+func (net *Testnet) Process() error {
+	retry := true
+	countdown := time.After(60 * time.Second)
+	for {
+		net.DebugMsg("TEST: process looping\n")
+		select {
+		case msg, ok := <-net.Msgs:
+			retry = true
+			net.DebugMsg("TEST: processing message without testing for idle\n")
+			if !net.processMessageFromChannel(msg, ok) {
+				return nil
+			}
+		case <-net.Closed:
+			return nil
+		case <-countdown:
+			panic("Test network took more than 60 seconds to resolve requests, this usually indicates a hang")
+		default:
+			if !retry {
+				return nil
+			}
+
+			var busy []int
+			for i, ep := range net.Endpoints {
+				if ep.IsBusy() {
+					busy = append(busy, i)
+				}
+			}
+			if len(busy) == 0 {
+				retry = false
+				continue
+			}
+
+			net.DebugMsg("TEST: some replicas are busy, waiting: %v\n", busy)
+			select {
+			case msg, ok := <-net.Msgs:
+				retry = true
+				if !net.processMessageFromChannel(msg, ok) {
+					return nil
+				}
+				continue
+			case <-time.After(100 * time.Millisecond):
+				continue
+			}
+		}
+	}
+}
+
+// ProcessContinually delivers the messages until the network is stopped
+func (net *Testnet) ProcessContinually() {
+	for {
+		select {
+		case msg, ok := <-net.Msgs:
+			if !net.processMessageFromChannel(msg, ok) {
+				return
+			}
+		case <-net.Closed:
+			return
+		}
+	}
+}
+
+// MakeTestnet creates a network of N endpoints, built by initFn
+func MakeTestnet(N int, initFn func(id uint64, network *Testnet) Endpoint) *Testnet {
+	net := &Testnet{}
+	net.Msgs = make(chan TaggedMsg, 100)
+	net.Closed = make(chan struct{})
+	net.Endpoints = make([]Endpoint, N)
+
+	for i := range net.Endpoints {
+		net.Endpoints[i] = initFn(uint64(i), net)
+	}
+
+	return net
+}
+
+// ClearMessages drops the messages in transit
+func (net *Testnet) ClearMessages() {
+	for {
+		select {
+		case <-net.Msgs:
+		default:
+			return
+		}
+	}
+}
+
+// Stop closes the network and stops its endpoints
+func (net *Testnet) Stop() {
+	close(net.Closed)
+	for _, ep := range net.Endpoints {
+		ep.Stop()
+	}
+}
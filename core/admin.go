@@ -25,6 +25,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/core/ledger"
 	pb "github.com/hyperledger/fabric/protos"
 )
 
@@ -78,3 +79,12 @@ func (*ServerAdmin) StopServer(context.Context, *empty.Empty) (*pb.ServerStatus,
 	defer os.Exit(0)
 	return status, nil
 }
+
+// GetTxSetGCReport reports what the garbage collection of the tx sets data reclaimed
+func (*ServerAdmin) GetTxSetGCReport(context.Context, *empty.Empty) (*pb.TxSetGCReport, error) {
+	ledger, err := ledger.GetLedger()
+	if err != nil {
+		return nil, err
+	}
+	return ledger.GetTxSetGCReport()
+}
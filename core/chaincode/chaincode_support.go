@@ -255,9 +255,12 @@ func (chaincodeSupport *ChaincodeSupport) sendInitOrReady(context context.Contex
 	}
 	chaincodeSupport.runningChaincodes.Unlock()
 
+	lgr, err := ledgerFrom(context)
+	if err != nil {
+		return fmt.Errorf("Failed to get handle to ledger (%s)", err)
+	}
 	var notfy chan *pb.ChaincodeMessage
-	var err error
-	if notfy, err = chrte.handler.initOrReady(txid, initArgs, tx, depTx); err != nil {
+	if notfy, err = chrte.handler.initOrReady(txid, initArgs, tx, depTx, txSetContextFrom(context), lgr); err != nil {
 		return fmt.Errorf("Error sending %s: %s", pb.ChaincodeMessage_INIT, err)
 	}
 	if notfy != nil {
@@ -421,7 +424,7 @@ func (chaincodeSupport *ChaincodeSupport) Launch(context context.Context, t *pb.
 	var initargs [][]byte
 	var err error
 
-	ledger, ledgerErr := ledger.GetLedger()
+	ledger, ledgerErr := ledgerFrom(context)
 	if ledgerErr != nil {
 		return cID, cMsg, fmt.Errorf("Failed to get handle to ledger (%s)", ledgerErr)
 	}
@@ -641,6 +644,37 @@ func createQueryMessage(txid string, cMsg *pb.ChaincodeInput) (*pb.ChaincodeMess
 	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_QUERY, Payload: payload, Txid: txid}, nil
 }
 
+type txSetContextKey struct{}
+
+// withTxSetContext returns a copy of ctxt carrying the transactions set context that Launch sends
+// to the chaincode along with the INIT message.
+func withTxSetContext(ctxt context.Context, txSetCtx *pb.ChaincodeTxSetContext) context.Context {
+	return context.WithValue(ctxt, txSetContextKey{}, txSetCtx)
+}
+
+// txSetContextFrom returns the transactions set context carried by ctxt, nil if there is none
+func txSetContextFrom(ctxt context.Context) *pb.ChaincodeTxSetContext {
+	txSetCtx, _ := ctxt.Value(txSetContextKey{}).(*pb.ChaincodeTxSetContext)
+	return txSetCtx
+}
+
+type ledgerKey struct{}
+
+// withLedger returns a copy of ctxt carrying the ledger on which the transactions executed with it, and the
+// chaincodes they call, read and write their state, e.g. the ledger of a simulation. Without it the ledger of
+// the peer is used
+func withLedger(ctxt context.Context, lgr *ledger.Ledger) context.Context {
+	return context.WithValue(ctxt, ledgerKey{}, lgr)
+}
+
+// ledgerFrom returns the ledger carried by ctxt, the ledger of the peer if there is none
+func ledgerFrom(ctxt context.Context) (*ledger.Ledger, error) {
+	if lgr, _ := ctxt.Value(ledgerKey{}).(*ledger.Ledger); lgr != nil {
+		return lgr, nil
+	}
+	return ledger.GetLedger()
+}
+
 // Execute executes a transaction and waits for it to complete until a timeout value.
 func (chaincodeSupport *ChaincodeSupport) Execute(ctxt context.Context, chaincode string, msg *pb.ChaincodeMessage, timeout time.Duration, tx *pb.Transaction) (*pb.ChaincodeMessage, error) {
 	chaincodeSupport.runningChaincodes.Lock()
@@ -653,9 +687,12 @@ func (chaincodeSupport *ChaincodeSupport) Execute(ctxt context.Context, chaincod
 	}
 	chaincodeSupport.runningChaincodes.Unlock()
 
+	lgr, err := ledgerFrom(ctxt)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get handle to ledger (%s)", err)
+	}
 	var notfy chan *pb.ChaincodeMessage
-	var err error
-	if notfy, err = chrte.handler.sendExecuteMessage(msg, tx); err != nil {
+	if notfy, err = chrte.handler.sendExecuteMessage(msg, tx, lgr); err != nil {
 		return nil, fmt.Errorf("Error sending %s: %s", msg.Type.String(), err)
 	}
 	var ccresp *pb.ChaincodeMessage
@@ -17,17 +17,23 @@ limitations under the License.
 package chaincode
 
 import (
+	"bytes"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
 	"golang.org/x/net/context"
 
+	"github.com/hyperledger/fabric/core/crypto/txset"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/state/chaincodest/statemgmt"
+	"github.com/hyperledger/fabric/core/util"
 	"github.com/hyperledger/fabric/events/producer"
 	pb "github.com/hyperledger/fabric/protos"
-	"reflect"
 )
 
 //Execute - execute the default transaction of a transaction set (which might also be a query transaction) or a mutable transaction
@@ -35,7 +41,7 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, inBlockTx *pb.InBloc
 	var err error
 	//TODO: Check if the same transaction set was already part of the block
 	// get a handle to ledger to mark the begin/finish of a tx
-	ledger, err := ledger.GetLedger()
+	ledger, err := ledgerFrom(ctxt)
 	if err != nil {
 		return nil, nil, fmt.Errorf("Failed to get handle to ledger (%s)", err)
 	}
@@ -61,11 +67,30 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, inBlockTx *pb.InBloc
 			if !txSetExistedAlready && inBlockTx.GetTransactionSet().Extend {
 				return nil, nil, fmt.Errorf("Cannot extend a non existent transactions set.")
 			}
+			if txSetExistedAlready && tx.TransactionSet.MutationPolicy != nil {
+				return nil, nil, fmt.Errorf("The mutation policy of a transactions set can only be set at its creation.")
+			}
+			var mutationPolicy *pb.TxSetMutationPolicy
+			if !txSetExistedAlready {
+				// The previous versions are only kept to decrypt the sets already created
+				if !txset.IsCreationVersion(inBlockTx.ConfidentialityProtocolVersion) {
+					return nil, nil, fmt.Errorf("Unsupported confidentiality protocol version [%s] for a new transactions set.", inBlockTx.ConfidentialityProtocolVersion)
+				}
+				mutationPolicy, err = txset.PolicyForNewSet(chain.getSecHelper(), inBlockTx)
+				if err != nil {
+					return nil, nil, fmt.Errorf("Invalid mutation policy for the transactions set. (%s)", err)
+				}
+			}
 			ledger.SetTxBegin(inBlockTx.Txid)
 			if !txSetExistedAlready {
 				txSetStValue = &pb.TxSetStateValue{}
 				txSetStValue.IntroBlock = nextBlockNr
 				txSetStValue.Index = tx.TransactionSet.DefaultInx
+				txSetStValue.ConfidentialityProtocolVersion = inBlockTx.ConfidentialityProtocolVersion
+				txSetStValue.MutationPolicy = mutationPolicy
+			} else if txSetStValue.ConfidentialityProtocolVersion != inBlockTx.ConfidentialityProtocolVersion {
+				ledger.SetTxFinished(inBlockTx.Txid, false)
+				return nil, nil, fmt.Errorf("The extension was encrypted with confidentiality protocol version [%s], but the set uses version [%s].", inBlockTx.ConfidentialityProtocolVersion, txSetStValue.ConfidentialityProtocolVersion)
 			}
 			txSetStValue.Nonce++
 			txInSet := uint64(len(tx.TransactionSet.Transactions))
@@ -75,7 +100,7 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, inBlockTx *pb.InBloc
 			err = ledger.SetTxSetState(inBlockTx.Txid, txSetStValue)
 			if err != nil {
 				ledger.SetTxFinished(inBlockTx.Txid, false)
-				return nil, nil, fmt.Errorf("Unable to create the state for the new set. Error: %s", err)
+				return nil, nil, fmt.Errorf("Unable to set the state of the tx set with ID: %s. Error: %s", inBlockTx.Txid, err)
 			}
 			ledger.SetTxFinished(inBlockTx.Txid, true)
 
@@ -102,6 +127,12 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, inBlockTx *pb.InBloc
 			}
 		}
 
+		// Let the chaincode know which set it executes and whether the blocks are being replayed
+		txSetCtx := &pb.ChaincodeTxSetContext{TxSetID: inBlockTx.Txid, Replay: ledger.IsResetting()}
+		if txSetStValue != nil {
+			txSetCtx.TxSetIndex = txSetStValue.Index
+		}
+
 		if defTx.Type == pb.ChaincodeAction_CHAINCODE_DEPLOY {
 			_, err := chain.Deploy(ctxt, defTx)
 			if err != nil {
@@ -109,8 +140,8 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, inBlockTx *pb.InBloc
 			}
 
 			//launch and wait for ready
-			markTxBegin(ledger, defTx)
-			_, _, err = chain.Launch(ctxt, defTx)
+			markTxBegin(ledger, inBlockTx.Txid, defTx)
+			_, _, err = chain.Launch(withTxSetContext(ctxt, txSetCtx), defTx)
 			if err != nil {
 				markTxFinish(ledger, defTx, false)
 				return nil, nil, fmt.Errorf("%s", err)
@@ -151,8 +182,9 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, inBlockTx *pb.InBloc
 					return nil, nil, fmt.Errorf("Failed to query message(%s)", err)
 				}
 			}
+			ccMsg.TxSetContext = txSetCtx
 
-			markTxBegin(ledger, defTx)
+			markTxBegin(ledger, inBlockTx.Txid, defTx)
 			resp, err := chain.Execute(ctxt, chaincode, ccMsg, timeout, defTx)
 			if err != nil {
 				// Rollback transaction
@@ -190,31 +222,9 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, inBlockTx *pb.InBloc
 			// Do not execute mutant transactions in the past
 			return nil, nil, nil
 		}
-		// TODO: Trigger chaincode state re-computation here.
 		ledger.SetTxBegin(inBlockTx.Txid)
-		txSetStValue, err := ledger.GetTxSetState(tx.MutantTransaction.TxSetID, true)
-		if err != nil {
-			ledger.SetTxFinished(inBlockTx.Txid, false)
-			return nil, nil, fmt.Errorf("Failed to retrieve the txSet state, txID: %s, err: %s.", inBlockTx.Txid, err)
-		}
-		if txSetStValue == nil {
-			ledger.SetTxFinished(inBlockTx.Txid, false)
-			return nil, nil, fmt.Errorf("Issuing a mutant transaction for a non-existing tx set id.")
-		}
-		if reflect.DeepEqual(txSetStValue.Index, tx.MutantTransaction.TxSetIndex) {
-			ledger.SetTxFinished(inBlockTx.Txid, false)
-			return nil, nil, fmt.Errorf("Nothing to mutate, the default index of the transactions set did not change.")
-		}
-		txSetStValue.Nonce++
-		txSetStValue.Index = tx.MutantTransaction.TxSetIndex
-		txSetStValue.LastModifiedAtBlock = nextBlockNr
-		err = ledger.SetTxSetState(tx.MutantTransaction.TxSetID, txSetStValue)
-		if err != nil {
-			ledger.SetTxFinished(inBlockTx.Txid, false)
-			return nil, nil, fmt.Errorf("Unable to set the new state for the Tx Set with ID: %s, err = %s", tx.MutantTransaction.TxSetID, err)
-		}
-		ledger.SetTxFinished(inBlockTx.Txid, true)
-
+		err = mutateTxSets(chain, ledger, inBlockTx, nextBlockNr)
+		ledger.SetTxFinished(inBlockTx.Txid, err == nil)
 		return nil, nil, err
 	case *pb.InBlockTransaction_SetStQueryTransaction:
 		txSetState, err := ledger.GetTxSetState(tx.SetStQueryTransaction.TxSetID, true)
@@ -228,11 +238,170 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, inBlockTx *pb.InBloc
 		if err != nil {
 			return nil, nil, fmt.Errorf("Unable to marshal the retrieved txSetState for txID: %s. Retrieved state: %#v", tx.SetStQueryTransaction.TxSetID, txSetState)
 		}
+		stateBytes, err = txset.EncryptQueryResult(inBlockTx, stateBytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to encrypt the retrieved txSetState for txID: %s. Err: %s", tx.SetStQueryTransaction.TxSetID, err)
+		}
 		return stateBytes, nil, err
 	}
 	return nil, nil, err
 }
 
+// mutateTxSets changes the active transaction of all the sets mutated by the mutant transaction. The mutations are
+// all validated before being applied: if any of them is invalid the returned error names its set and none is applied
+func mutateTxSets(chain *ChaincodeSupport, ledger *ledger.Ledger, inBlockTx *pb.InBlockTransaction, nextBlockNr uint64) error {
+	mutant := inBlockTx.GetMutantTransaction()
+	if mutant.IsScheduling() || mutant.CancelScheduled {
+		return scheduleTxSetMutation(chain, ledger, inBlockTx, nextBlockNr)
+	}
+	if mutant.ScheduledBy != "" {
+		return applyScheduledMutation(ledger, mutant, nextBlockNr)
+	}
+	mutations := mutant.GetTxSetMutations()
+	txSetStValues := make([]*pb.TxSetStateValue, len(mutations))
+	mutated := make(map[string]bool)
+	for i, mutation := range mutations {
+		if mutated[mutation.TxSetID] {
+			return fmt.Errorf("The tx set with ID: %s is mutated more than once by the mutant transaction.", mutation.TxSetID)
+		}
+		mutated[mutation.TxSetID] = true
+		txSetStValue, err := ledger.GetTxSetState(mutation.TxSetID, true)
+		if err != nil {
+			return fmt.Errorf("Failed to retrieve the txSet state, txID: %s, err: %s.", mutation.TxSetID, err)
+		}
+		if txSetStValue == nil {
+			return fmt.Errorf("Issuing a mutant transaction for a non-existing tx set id: %s.", mutation.TxSetID)
+		}
+		if !ledger.IsTxSetMutable(txSetStValue, nextBlockNr) {
+			return fmt.Errorf("The tx set with ID: %s introduced at block %d can no longer be mutated.", mutation.TxSetID, txSetStValue.IntroBlock)
+		}
+		if txSetStValue.Index == mutation.TxSetIndex {
+			return fmt.Errorf("Nothing to mutate, the default index of the tx set with ID: %s did not change.", mutation.TxSetID)
+		}
+		txSetStValues[i] = txSetStValue
+	}
+	if err := txset.CheckMutationPolicies(chain.getSecHelper(), txSetStValues, inBlockTx); err != nil {
+		return err
+	}
+	for i, mutation := range mutations {
+		txSetStValue := txSetStValues[i]
+		txSetStValue.Nonce++
+		txSetStValue.Index = mutation.TxSetIndex
+		txSetStValue.LastModifiedAtBlock = nextBlockNr
+		if err := ledger.SetTxSetState(mutation.TxSetID, txSetStValue); err != nil {
+			return fmt.Errorf("Unable to set the new state for the Tx Set with ID: %s, err = %s", mutation.TxSetID, err)
+		}
+	}
+	return nil
+}
+
+// scheduleTxSetMutation records the mutation scheduled by the mutant transaction in the state of its set, or
+// cancels the one already scheduled. A single set is targeted and at most a mutation can be scheduled per set.
+// Both operations are subject to the mutation policy of the set, like an immediate mutation
+func scheduleTxSetMutation(chain *ChaincodeSupport, ledger *ledger.Ledger, inBlockTx *pb.InBlockTransaction, nextBlockNr uint64) error {
+	mutant := inBlockTx.GetMutantTransaction()
+	if len(mutant.AdditionalMutations) != 0 {
+		return errors.New("A mutation can only be scheduled or cancelled for a single tx set.")
+	}
+	if mutant.IsScheduling() && mutant.CancelScheduled {
+		return errors.New("A mutant transaction cannot both schedule and cancel a mutation.")
+	}
+	txSetStValue, err := ledger.GetTxSetState(mutant.TxSetID, true)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve the txSet state, txID: %s, err: %s.", mutant.TxSetID, err)
+	}
+	if txSetStValue == nil {
+		return fmt.Errorf("Issuing a mutant transaction for a non-existing tx set id: %s.", mutant.TxSetID)
+	}
+	if !ledger.IsTxSetMutable(txSetStValue, nextBlockNr) {
+		return fmt.Errorf("The tx set with ID: %s introduced at block %d can no longer be mutated.", mutant.TxSetID, txSetStValue.IntroBlock)
+	}
+	if mutant.CancelScheduled {
+		if txSetStValue.ScheduledMutation == nil {
+			return fmt.Errorf("No mutation is scheduled for the tx set with ID: %s.", mutant.TxSetID)
+		}
+	} else {
+		if txSetStValue.ScheduledMutation != nil {
+			return fmt.Errorf("The mutant transaction %s already scheduled a mutation of the tx set with ID: %s, it should be cancelled first.", txSetStValue.ScheduledMutation.MutantTxid, mutant.TxSetID)
+		}
+		if mutant.AtBlock != 0 && mutant.AtBlock <= nextBlockNr {
+			return fmt.Errorf("A mutation can only be scheduled at a block following block %d, got block %d.", nextBlockNr, mutant.AtBlock)
+		}
+	}
+	if err := txset.CheckMutationPolicies(chain.getSecHelper(), []*pb.TxSetStateValue{txSetStValue}, inBlockTx); err != nil {
+		return err
+	}
+	txSetStValue.Nonce++
+	txSetStValue.LastModifiedAtBlock = nextBlockNr
+	if mutant.CancelScheduled {
+		txSetStValue.ScheduledMutation = nil
+	} else {
+		txSetStValue.ScheduledMutation = mutant.NewScheduledMutation(inBlockTx.Txid)
+	}
+	if err := ledger.SetTxSetState(mutant.TxSetID, txSetStValue); err != nil {
+		return fmt.Errorf("Unable to set the new state for the Tx Set with ID: %s, err = %s", mutant.TxSetID, err)
+	}
+	return nil
+}
+
+// applyScheduledMutation applies the mutation scheduled on a set once it is due. The mutation policy was checked
+// when the mutation was scheduled. If the active transaction already is the scheduled one, the schedule is
+// simply cleared
+func applyScheduledMutation(ledger *ledger.Ledger, mutant *pb.MutantTransaction, nextBlockNr uint64) error {
+	txSetStValue, err := ledger.GetTxSetState(mutant.TxSetID, true)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve the txSet state, txID: %s, err: %s.", mutant.TxSetID, err)
+	}
+	if txSetStValue == nil {
+		return fmt.Errorf("Issuing a mutant transaction for a non-existing tx set id: %s.", mutant.TxSetID)
+	}
+	scheduled := txSetStValue.ScheduledMutation
+	if scheduled == nil || scheduled.MutantTxid != mutant.ScheduledBy || scheduled.TxSetIndex != mutant.TxSetIndex {
+		return fmt.Errorf("The mutation scheduled by the mutant transaction %s is not pending for the tx set with ID: %s.", mutant.ScheduledBy, mutant.TxSetID)
+	}
+	if !ledger.IsTxSetMutable(txSetStValue, nextBlockNr) {
+		return fmt.Errorf("The tx set with ID: %s introduced at block %d can no longer be mutated.", mutant.TxSetID, txSetStValue.IntroBlock)
+	}
+	txSetStValue.Nonce++
+	txSetStValue.Index = scheduled.TxSetIndex
+	txSetStValue.LastModifiedAtBlock = nextBlockNr
+	txSetStValue.ScheduledMutation = nil
+	if err := ledger.SetTxSetState(mutant.TxSetID, txSetStValue); err != nil {
+		return fmt.Errorf("Unable to set the new state for the Tx Set with ID: %s, err = %s", mutant.TxSetID, err)
+	}
+	return nil
+}
+
+// dueScheduledMutations returns the mutant transactions applying the scheduled mutations due at the start of the
+// next block. The time of the block is the timestamp of the previous block, which every validator agrees on. The
+// mutant transactions are derived from the state and the chain only, so that every validator creates the same ones
+func dueScheduledMutations(lgr *ledger.Ledger) ([]*pb.InBlockTransaction, error) {
+	blockTime := lgr.GetPreviousBlockTime()
+	txSetIDs, err := lgr.GetDueScheduledTxSetIDs(lgr.GetCurrentBlockEx(), blockTime)
+	if err != nil {
+		return nil, err
+	}
+	var mutantTxs []*pb.InBlockTransaction
+	for _, txSetID := range txSetIDs {
+		txSetStValue, err := lgr.GetTxSetState(txSetID, true)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to retrieve the txSet state, txID: %s, err: %s.", txSetID, err)
+		}
+		scheduled := txSetStValue.GetScheduledMutation()
+		mutantTxs = append(mutantTxs, &pb.InBlockTransaction{
+			Transaction: &pb.InBlockTransaction_MutantTransaction{MutantTransaction: &pb.MutantTransaction{
+				TxSetID:     txSetID,
+				TxSetIndex:  scheduled.TxSetIndex,
+				ScheduledBy: scheduled.MutantTxid,
+			}},
+			// The ID of the scheduling transaction identifies the mutation, it is scheduled only once
+			Txid:      hex.EncodeToString(util.ComputeCryptoHash([]byte("scheduled:" + scheduled.MutantTxid))),
+			Timestamp: blockTime,
+		})
+	}
+	return mutantTxs, nil
+}
+
 func ApplyMutations(ctxt context.Context, cname ChainName) error {
 	chaincodeLogger.Debug("Starting a state mutation.")
 	ledger, err := ledger.GetLedger()
@@ -246,72 +415,205 @@ func ApplyMutations(ctxt context.Context, cname ChainName) error {
 		return nil
 	}
 	err = ledger.ResetToBlock(restartBlockNum - 1)
-
+	if err == nil {
+		err = replayBlocks(ctxt, GetChain(cname), ledger, restartBlockNum, lastBlockToReExec)
+	}
 	if err != nil {
-		chaincodeLogger.Errorf("Unable to reset to base block. Err =  %s", err)
-		ledger.ConcludeReset()
-		// Try to recover to last computed state here
-		errRec := ledger.ResetToBlock(ledger.GetBlockchainSize())
+		chaincodeLogger.Errorf("Unable to apply the mutations to the state. Err =  %s", err)
+		// Go back to the state at the end of the chain
+		errRec := ledger.RollbackReset()
 		if errRec != nil {
-			return fmt.Errorf("Error while recovering the state from an error in the state mutation process. Last error: (%s)\nInitial error: (%s)", err, errRec)
-		}
-		errRec = ledger.ConcludeReset()
-		if errRec != nil {
-			return fmt.Errorf("Error while recovering the state from an error in the state mutation process. Last error: (%s)\nInitial error: (%s)", err, errRec)
+			return fmt.Errorf("Error while recovering the state from an error in the state mutation process. Last error: (%s)\nInitial error: (%s)", errRec, err)
 		}
 		return fmt.Errorf("Unable to apply the mutant transactions changes. (%s)", err)
 	}
-	defer ledger.ConcludeReset()
-	var chain = GetChain(cname)
+	return ledger.ConcludeReset()
+}
+
+// SimulateMutation executes mutantTx and replays the blocks following the oldest mutated set as ApplyMutations
+// would, on a simulation of the ledger: the changes are kept in memory, nothing is written to the DB nor
+// broadcast and the batches of the peer go on meanwhile. It returns the chaincode keys whose value at the end of
+// the chain would change and the transactions whose outcome would change.
+// Like the replay of an actual mutation, the simulation may stop and launch chaincodes
+func SimulateMutation(ctxt context.Context, cname ChainName, mutantTx *pb.InBlockTransaction) (*pb.MutationSimulation, error) {
+	if mutantTx.GetMutantTransaction() == nil {
+		return nil, errors.New("Only mutant transactions can be simulated")
+	}
+	lgr, err := ledger.GetLedger()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get handle to ledger (%s)", err)
+	}
+	simLedger, err := lgr.NewSimulation()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to start the simulation (%s)", err)
+	}
+	lastBlockToReExec := simLedger.GetBlockchainSize()
+	if lastBlockToReExec == 0 {
+		return nil, errors.New("The blockchain has no blocks")
+	}
+	stateBefore, err := simLedger.GetSimulatedState()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to retrieve the current state (%s)", err)
+	}
+	// The transactions executed in the simulation, and the chaincodes they call, use its ledger
+	ctxt = withLedger(ctxt, simLedger)
+	chain := GetChain(cname)
+	if _, _, err = Execute(ctxt, chain, mutantTx); err != nil {
+		return nil, fmt.Errorf("The mutant transaction would be rejected (%s)", err)
+	}
+	simulation := &pb.MutationSimulation{}
+	restartBlockNum, toReset := simLedger.GetOlderTBModBlock()
+	if !toReset {
+		chaincodeLogger.Debug("Nothing to reset.")
+		return simulation, nil
+	}
+	simulation.FromBlock = restartBlockNum
+	if err = simLedger.ResetToBlock(restartBlockNum - 1); err != nil {
+		return nil, err
+	}
+	if err = replayBlocks(ctxt, chain, simLedger, restartBlockNum, lastBlockToReExec); err != nil {
+		return nil, fmt.Errorf("Unable to replay the blocks (%s)", err)
+	}
+	stateAfter, err := simLedger.GetSimulatedState()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to retrieve the replayed state (%s)", err)
+	}
+	simulation.KeyChanges = diffStates(stateBefore, stateAfter)
+	simulation.ReplayReports = simLedger.GetReplayReports()
+	return simulation, nil
+}
+
+// diffStates returns the keys whose value differs between two full state images, sorted by chaincode and key
+func diffStates(before *statemgmt.StateDelta, after *statemgmt.StateDelta) []*pb.ChaincodeKeyChange {
+	chaincodeIDs := before.GetUpdatedChaincodeIds(false)
+	for _, chaincodeID := range after.GetUpdatedChaincodeIds(false) {
+		if len(before.GetUpdates(chaincodeID)) == 0 {
+			chaincodeIDs = append(chaincodeIDs, chaincodeID)
+		}
+	}
+	sort.Strings(chaincodeIDs)
+
+	var changes []*pb.ChaincodeKeyChange
+	for _, chaincodeID := range chaincodeIDs {
+		updatesBefore := before.GetUpdates(chaincodeID)
+		updatesAfter := after.GetUpdates(chaincodeID)
+		keys := make([]string, 0, len(updatesBefore)+len(updatesAfter))
+		for key := range updatesBefore {
+			keys = append(keys, key)
+		}
+		for key := range updatesAfter {
+			if _, ok := updatesBefore[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			var previousValue, value []byte
+			if updated := updatesBefore[key]; updated != nil {
+				previousValue = updated.GetValue()
+			}
+			if updated := updatesAfter[key]; updated != nil {
+				value = updated.GetValue()
+			}
+			if !bytes.Equal(previousValue, value) {
+				changes = append(changes, &pb.ChaincodeKeyChange{ChaincodeID: chaincodeID, Key: key, PreviousValue: previousValue, Value: value})
+			}
+		}
+	}
+	return changes
+}
+
+// replayBlocks brings the state, reset to the end of block restartBlockNum - 1, up to the end of the chain
+func replayBlocks(ctxt context.Context, chain *ChaincodeSupport, ledger *ledger.Ledger, restartBlockNum uint64, lastBlockToReExec uint64) error {
+	scheduler := newReplayScheduler(ctxt, chain, ledger, viper.GetInt("ledger.state.replayParallelism"))
+
 	chaincodeLogger.Debugf("Starting the re-execution of the transactions. From block: %d to block %d", restartBlockNum, lastBlockToReExec)
 	for i := restartBlockNum; i < lastBlockToReExec; i++ {
 		block, err := ledger.GetBlockByNumber(i)
 		if err != nil {
-			return fmt.Errorf("Unable to retrieve the block %d while applying the mutant changes (%s)", err)
+			return fmt.Errorf("Unable to retrieve the block %d while applying the mutant changes (%s)", i, err)
 		}
-		txs := block.GetTransactions()
-
-		for _, t := range txs {
-			if t.GetMutantTransaction() == nil {
-				// Check if the previous default was a deploy transaction and if so terminate it
-				prevDefault, err := prevDefault(t.Txid)
-				if err != nil {
-					return fmt.Errorf("Unable to verify the previous default transaction for the set with ID: %s. (%s)", t.Txid, err)
-				}
-				if prevDefault != nil && prevDefault.Type == pb.ChaincodeAction_CHAINCODE_DEPLOY {
-					depSpec := &pb.ChaincodeDeploymentSpec{}
-					errUnm := proto.Unmarshal(prevDefault.Payload, depSpec)
-					if errUnm != nil {
-						chaincodeLogger.Errorf("Unable to retrieve specification for previous deploy transaction. %s", errUnm)
-					} else {
-						errStop := chain.Stop(ctxt, depSpec)
-						if errStop != nil {
-							chaincodeLogger.Errorf("Unable to stop previous default transaction vm. (%s)", errStop)
-						}
-					}
-				}
-				_, _, txerr := Execute(ctxt, chain, t)
-				if txerr != nil {
-					// TODO process this better and don't ignore the errors!!
-					chaincodeLogger.Errorf("Error while re-executing transaction with id %s at block %d. Error: [%s]", t.Txid, i, txerr)
-				}
-			}
+		blockRWSets, err := ledger.GetTxReadWriteSets(i)
+		if err != nil {
+			return fmt.Errorf("Unable to retrieve the read/write sets of block %d while applying the mutant changes (%s)", i, err)
+		}
+		report, err := scheduler.replayBlock(i, block, blockRWSets)
+		if err != nil {
+			return err
 		}
 
 		if err := ledger.CommitResetTxBatch(); err != nil {
 			//TODO try to recover to the last state before returning
 			return fmt.Errorf("Failed to commit transaction to the ledger: %v", err)
 		}
-		chaincodeLogger.Infof("Block %d reexecuted.", i)
+		if len(report.Transactions) > 0 {
+			chaincodeLogger.Infof("Block %d reexecuted, %d transactions changed outcome.", i, len(report.Transactions))
+			ledger.AddReplayReport(report)
+		} else {
+			chaincodeLogger.Infof("Block %d reexecuted.", i)
+		}
 	}
+	chaincodeLogger.Infof("State mutation applied. Transactions re-executed: %d, transactions whose changes were replayed: %d", scheduler.numReExecuted, scheduler.numReplayed)
 	return nil
 }
 
-func prevDefault(txSetID string) (*pb.Transaction, error) {
-	ledger, err := ledger.GetLedger()
-	if err != nil {
-		return nil, err
+// dependsOnChanges returns true if the transaction read a key whose value might have changed, or used
+// a chaincode which might have been deployed with a different code
+func dependsOnChanges(rwSet *statemgmt.TxReadWriteSet, changedKeys *statemgmt.KeySet, redeployedChaincodes map[string]bool) bool {
+	if rwSet.DependsOn(changedKeys) {
+		return true
+	}
+	for chaincodeID := range redeployedChaincodes {
+		if rwSet.TouchesChaincode(chaincodeID) {
+			return true
+		}
+	}
+	return false
+}
+
+// replayResult compares the re-execution of the transaction at txIndex in the block with its original
+// execution. It returns nil if neither the outcome nor the chaincode event changed
+func replayResult(block *pb.Block, txIndex int, rwSet *statemgmt.TxReadWriteSet, ccEvent *pb.ChaincodeEvent, txerr error) *pb.TransactionReplayResult {
+	result := &pb.TransactionReplayResult{
+		Txid:            block.Transactions[txIndex].Txid,
+		PreviousOutcome: pb.TransactionReplayResult_UNKNOWN,
+		Outcome:         pb.TransactionReplayResult_SUCCEEDED,
+	}
+	if rwSet != nil {
+		if rwSet.Successful {
+			result.PreviousOutcome = pb.TransactionReplayResult_SUCCEEDED
+		} else {
+			result.PreviousOutcome = pb.TransactionReplayResult_FAILED
+		}
+	}
+	if txerr != nil {
+		result.Outcome = pb.TransactionReplayResult_FAILED
+		result.Error = txerr.Error()
+	}
+
+	// The events are stored at the position of their transaction in the block, empty for the transactions
+	// which did not emit any
+	var prevEvent *pb.ChaincodeEvent
+	if prevEvents := block.GetNonHashData().GetChaincodeEvents(); txIndex < len(prevEvents) && prevEvents[txIndex].ChaincodeID != "" {
+		prevEvent = prevEvents[txIndex]
+	}
+	if ccEvent != nil && ccEvent.ChaincodeID == "" {
+		ccEvent = nil
+	}
+	if (prevEvent == nil) != (ccEvent == nil) || (prevEvent != nil && !proto.Equal(prevEvent, ccEvent)) {
+		result.ChaincodeEventChanged = true
+		result.ChaincodeEvent = ccEvent
+	}
+
+	// Without a recorded outcome only the failures are reported
+	outcomeChanged := result.PreviousOutcome != pb.TransactionReplayResult_UNKNOWN && result.PreviousOutcome != result.Outcome
+	if !outcomeChanged && txerr == nil && !result.ChaincodeEventChanged {
+		return nil
 	}
+	return result
+}
+
+func prevDefault(ledger *ledger.Ledger, txSetID string) (*pb.Transaction, error) {
 	prevState, err := ledger.GetTxSetState(txSetID, true)
 	if err != nil {
 		return nil, err
@@ -331,26 +633,72 @@ func prevDefault(txSetID string) (*pb.Transaction, error) {
 //will return an array of errors one for each transaction. If the execution
 //succeeded, array element will be nil. returns []byte of state hash or
 //error
-func ExecuteTransactions(ctxt context.Context, cname ChainName, xacts []*pb.InBlockTransaction) (succeededTxs []*pb.InBlockTransaction, stateHash []byte, ccevents []*pb.ChaincodeEvent, txerrs []error, err error) {
+//The scheduled mutations due at this block and the mutations queued by the
+//chaincodes in the previous block are executed before xacts, executedTxs holds
+//them followed by xacts and the errors and events are aligned with it
+func ExecuteTransactions(ctxt context.Context, cname ChainName, xacts []*pb.InBlockTransaction) (executedTxs []*pb.InBlockTransaction, succeededTxs []*pb.InBlockTransaction, stateHash []byte, ccevents []*pb.ChaincodeEvent, txerrs []error, err error) {
 	var chain = GetChain(cname)
 	if chain == nil {
 		// TODO: We should never get here, but otherwise a good reminder to better handle
 		panic(fmt.Sprintf("[ExecuteTransactions]Chain %s not found\n", cname))
 	}
 
-	txerrs = make([]error, len(xacts))
-	ccevents = make([]*pb.ChaincodeEvent, len(xacts))
+	lgr, err := ledger.GetLedger()
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("Failed to get handle to ledger (%s)", err)
+	}
+	// The scheduled mutations due at this block are applied before any other transaction
+	due, err := dueScheduledMutations(lgr)
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("Unable to retrieve the scheduled mutations (%s)", err)
+	}
+
+	// The mutations requested by the chaincodes in the previous block are executed next
+	queued, err := lgr.DequeueMutations()
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("Unable to retrieve the queued mutations (%s)", err)
+	}
+	for _, t := range queued {
+		// Like the scheduled ones, they carry the time of the previous block
+		t.Timestamp = lgr.GetPreviousBlockTime()
+	}
+
+	issued := len(due) + len(queued)
+	executedTxs = make([]*pb.InBlockTransaction, 0, issued+len(xacts))
+	executedTxs = append(append(append(executedTxs, due...), queued...), xacts...)
+	txerrs = make([]error, len(executedTxs))
+	ccevents = make([]*pb.ChaincodeEvent, len(executedTxs))
 	succeededTxs = make([]*pb.InBlockTransaction, 0)
 	var setIndexes = make([]int, 0)
 
+	for i, t := range executedTxs[:issued] {
+		_, ccevents[i], txerrs[i] = Execute(ctxt, chain, t)
+		if txerrs[i] != nil {
+			if i < len(due) {
+				chaincodeLogger.Warningf("Mutation %s scheduled by %s rejected: %s", t.Txid, t.GetMutantTransaction().ScheduledBy, txerrs[i])
+			} else {
+				chaincodeLogger.Warningf("Mutation %s requested by chaincode %s rejected: %s", t.Txid, t.GetMutantTransaction().ChaincodeID, txerrs[i])
+			}
+			sendTxRejectedEvent(t, txerrs[i].Error())
+			continue
+		}
+		succeededTxs = append(succeededTxs, t)
+	}
+
 	// Execute all the mutant transactions first
-	for i, t := range xacts {
+	for i := issued; i < len(executedTxs); i++ {
+		t := executedTxs[i]
 		if t.GetMutantTransaction() != nil {
+			if t.GetMutantTransaction().IsIssuedByValidators() {
+				txerrs[i] = fmt.Errorf("The mutant transaction %s claims to be requested by a chaincode or to apply a scheduled mutation, only the validators can issue such transactions.", t.Txid)
+				sendTxRejectedEvent(t, txerrs[i].Error())
+				continue
+			}
 			_, ccevents[i], txerrs[i] = Execute(ctxt, chain, t)
 			if txerrs[i] == nil {
 				succeededTxs = append(succeededTxs, t)
 			} else {
-				sendTxRejectedEvent(xacts[i], txerrs[i].Error())
+				sendTxRejectedEvent(t, txerrs[i].Error())
 			}
 		} else {
 			setIndexes = append(setIndexes, i)
@@ -364,22 +712,18 @@ func ExecuteTransactions(ctxt context.Context, cname ChainName, xacts []*pb.InBl
 
 	// Now execute only the non mutant transactions
 	for _, i := range setIndexes {
-		actualTx := xacts[i]
+		actualTx := executedTxs[i]
 		_, ccevents[i], txerrs[i] = Execute(ctxt, chain, actualTx)
 		if txerrs[i] == nil {
 			succeededTxs = append(succeededTxs, actualTx)
 		} else {
-			sendTxRejectedEvent(xacts[i], txerrs[i].Error())
+			sendTxRejectedEvent(actualTx, txerrs[i].Error())
 		}
 	}
 
-	var lgr *ledger.Ledger
-	lgr, err = ledger.GetLedger()
-	if err == nil {
-		stateHash, err = lgr.GetTempStateHash()
-	}
+	stateHash, err = lgr.GetTempStateHash()
 
-	return succeededTxs, stateHash, ccevents, txerrs, err
+	return executedTxs, succeededTxs, stateHash, ccevents, txerrs, err
 }
 
 // GetSecureContext returns the security context from the context object or error
@@ -422,11 +766,11 @@ func getTimeout(cID *pb.ChaincodeID) (time.Duration, error) {
 	return -1, errFailedToGetChainCodeSpecForTransaction
 }
 
-func markTxBegin(ledger *ledger.Ledger, t *pb.Transaction) {
+func markTxBegin(ledger *ledger.Ledger, txSetID string, t *pb.Transaction) {
 	if t.Type == pb.ChaincodeAction_CHAINCODE_QUERY {
 		return
 	}
-	ledger.ChainTxBegin(t.Txid)
+	ledger.ChainTxBeginForSet(txSetID, t.Txid)
 }
 
 func markTxFinish(ledger *ledger.Ledger, t *pb.Transaction, successful bool) {
@@ -151,7 +151,7 @@ func getDeploymentSpec(context context.Context, spec *pb.ChaincodeSpec) (*pb.Cha
 	return chaincodeDeploymentSpec, nil
 }
 
-func createDeployTransaction(dspec *pb.ChaincodeDeploymentSpec, uuid string) (*pb.Transaction, error) {
+func createDeployTransaction(dspec *pb.ChaincodeDeploymentSpec, uuid string) (*pb.InBlockTransaction, error) {
 	var tx *pb.Transaction
 	var err error
 	var sec crypto.Client
@@ -173,10 +173,10 @@ func createDeployTransaction(dspec *pb.ChaincodeDeploymentSpec, uuid string) (*p
 			return nil, fmt.Errorf("Error deploying chaincode: %s ", err)
 		}
 	}
-	return tx, nil
+	return container.EncapsulateTransactionToInBlock(tx)
 }
 
-func createTransaction(invokeTx bool, spec *pb.ChaincodeInvocationSpec, uuid string) (*pb.Transaction, error) {
+func createTransaction(invokeTx bool, spec *pb.ChaincodeInvocationSpec, uuid string) (*pb.InBlockTransaction, error) {
 	var tx *pb.Transaction
 	var err error
 	var sec crypto.Client
@@ -195,18 +195,18 @@ func createTransaction(invokeTx bool, spec *pb.ChaincodeInvocationSpec, uuid str
 			return nil, err
 		}
 	} else {
-		var t pb.Transaction_Type
+		var t pb.ChaincodeAction
 		if invokeTx {
-			t = pb.Transaction_CHAINCODE_INVOKE
+			t = pb.ChaincodeAction_CHAINCODE_INVOKE
 		} else {
-			t = pb.Transaction_CHAINCODE_QUERY
+			t = pb.ChaincodeAction_CHAINCODE_QUERY
 		}
 		tx, err = pb.NewChaincodeExecute(spec, uuid, t)
 		if nil != err {
 			return nil, err
 		}
 	}
-	return tx, nil
+	return container.EncapsulateTransactionToInBlock(tx)
 }
 
 // Deploy a chaincode - i.e., build and initialize.
@@ -234,7 +234,7 @@ func deploy(ctx context.Context, spec *pb.ChaincodeSpec) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Error deploying chaincode: %s", err)
 	}
-	ledger.CommitTxBatch("1", []*pb.Transaction{transaction}, nil, nil)
+	ledger.CommitTxBatch("1", []*pb.InBlockTransaction{transaction}, nil, nil)
 
 	return b, err
 }
@@ -254,21 +254,21 @@ func deploy2(ctx context.Context, chaincodeDeploymentSpec *pb.ChaincodeDeploymen
 	if err != nil {
 		return nil, fmt.Errorf("Error deploying chaincode: %s", err)
 	}
-	ledger.CommitTxBatch("1", []*pb.Transaction{transaction}, nil, nil)
+	ledger.CommitTxBatch("1", []*pb.InBlockTransaction{transaction}, nil, nil)
 
 	return b, err
 }
 
 // Invoke or query a chaincode.
-func invoke(ctx context.Context, spec *pb.ChaincodeSpec, typ pb.Transaction_Type) (*pb.ChaincodeEvent, string, []byte, error) {
+func invoke(ctx context.Context, spec *pb.ChaincodeSpec, typ pb.ChaincodeAction) (*pb.ChaincodeEvent, string, []byte, error) {
 	chaincodeInvocationSpec := &pb.ChaincodeInvocationSpec{ChaincodeSpec: spec}
 
 	// Now create the Transactions message and send to Peer.
 	uuid := util.GenerateUUID()
 
-	var transaction *pb.Transaction
+	var transaction *pb.InBlockTransaction
 	var err error
-	if typ == pb.Transaction_CHAINCODE_QUERY {
+	if typ == pb.ChaincodeAction_CHAINCODE_QUERY {
 		transaction, err = createTransaction(false, chaincodeInvocationSpec, uuid)
 	} else {
 		transaction, err = createTransaction(true, chaincodeInvocationSpec, uuid)
@@ -280,7 +280,7 @@ func invoke(ctx context.Context, spec *pb.ChaincodeSpec, typ pb.Transaction_Type
 	var retval []byte
 	var execErr error
 	var ccevt *pb.ChaincodeEvent
-	if typ == pb.Transaction_CHAINCODE_QUERY {
+	if typ == pb.ChaincodeAction_CHAINCODE_QUERY {
 		retval, ccevt, execErr = Execute(ctx, GetChain(DefaultChain), transaction)
 	} else {
 		ledger, _ := ledger.GetLedger()
@@ -289,7 +289,7 @@ func invoke(ctx context.Context, spec *pb.ChaincodeSpec, typ pb.Transaction_Type
 		if execErr != nil {
 			return nil, uuid, nil, fmt.Errorf("Error invoking chaincode: %s ", execErr)
 		}
-		ledger.CommitTxBatch("1", []*pb.Transaction{transaction}, nil, nil)
+		ledger.CommitTxBatch("1", []*pb.InBlockTransaction{transaction}, nil, nil)
 	}
 
 	return ccevt, uuid, retval, execErr
@@ -447,7 +447,7 @@ func invokeExample02Transaction(ctxt context.Context, cID *pb.ChaincodeID, args
 	f = "invoke"
 	invokeArgs := append([]string{f}, args...)
 	spec = &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID, CtorMsg: &pb.ChaincodeInput{Args: util.ToChaincodeArgs(invokeArgs...)}}
-	_, uuid, _, err := invoke(ctxt, spec, pb.Transaction_CHAINCODE_INVOKE)
+	_, uuid, _, err := invoke(ctxt, spec, pb.ChaincodeAction_CHAINCODE_INVOKE)
 	if err != nil {
 		return fmt.Errorf("Error invoking <%s>: %s", chaincodeID, err)
 	}
@@ -461,7 +461,7 @@ func invokeExample02Transaction(ctxt context.Context, cID *pb.ChaincodeID, args
 	f = "delete"
 	delArgs := util.ToChaincodeArgs(f, "a")
 	spec = &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID, CtorMsg: &pb.ChaincodeInput{Args: delArgs}}
-	_, uuid, _, err = invoke(ctxt, spec, pb.Transaction_CHAINCODE_INVOKE)
+	_, uuid, _, err = invoke(ctxt, spec, pb.ChaincodeAction_CHAINCODE_INVOKE)
 	if err != nil {
 		return fmt.Errorf("Error deleting state in <%s>: %s", chaincodeID, err)
 	}
@@ -534,10 +534,10 @@ func exec(ctxt context.Context, chaincodeID string, numTrans int, numQueries int
 	var wg sync.WaitGroup
 	errs := make([]error, numTrans+numQueries)
 
-	e := func(qnum int, typ pb.Transaction_Type) {
+	e := func(qnum int, typ pb.ChaincodeAction) {
 		defer wg.Done()
 		var spec *pb.ChaincodeSpec
-		if typ == pb.Transaction_CHAINCODE_INVOKE {
+		if typ == pb.ChaincodeAction_CHAINCODE_INVOKE {
 			f := "invoke"
 			args := util.ToChaincodeArgs(f, "a", "b", "10")
 
@@ -561,13 +561,13 @@ func exec(ctxt context.Context, chaincodeID string, numTrans int, numQueries int
 	//execute transactions sequentially..
 	go func() {
 		for i := 0; i < numTrans; i++ {
-			e(i, pb.Transaction_CHAINCODE_INVOKE)
+			e(i, pb.ChaincodeAction_CHAINCODE_INVOKE)
 		}
 	}()
 
 	//...but queries in parallel
 	for i := numTrans; i < numTrans+numQueries; i++ {
-		go e(i, pb.Transaction_CHAINCODE_QUERY)
+		go e(i, pb.ChaincodeAction_CHAINCODE_QUERY)
 	}
 
 	wg.Wait()
@@ -781,7 +781,7 @@ func TestExecuteInvalidQuery(t *testing.T) {
 
 	spec = &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID, CtorMsg: &pb.ChaincodeInput{Args: args}}
 	// This query should fail as it attempts to put state
-	_, _, _, err = invoke(ctxt, spec, pb.Transaction_CHAINCODE_QUERY)
+	_, _, _, err = invoke(ctxt, spec, pb.ChaincodeAction_CHAINCODE_QUERY)
 
 	if err == nil {
 		t.Fail()
@@ -881,7 +881,7 @@ func TestChaincodeInvokeChaincode(t *testing.T) {
 	spec2 = &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID2, CtorMsg: &pb.ChaincodeInput{Args: args}}
 	// Invoke chaincode
 	var uuid string
-	_, uuid, _, err = invoke(ctxt, spec2, pb.Transaction_CHAINCODE_INVOKE)
+	_, uuid, _, err = invoke(ctxt, spec2, pb.ChaincodeAction_CHAINCODE_INVOKE)
 
 	if err != nil {
 		t.Fail()
@@ -994,7 +994,7 @@ func TestChaincodeInvokeChaincodeErrorCase(t *testing.T) {
 
 	spec2 = &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID2, CtorMsg: &pb.ChaincodeInput{Args: args}}
 	// Invoke chaincode
-	_, _, _, err = invoke(ctxt, spec2, pb.Transaction_CHAINCODE_INVOKE)
+	_, _, _, err = invoke(ctxt, spec2, pb.ChaincodeAction_CHAINCODE_INVOKE)
 
 	if err == nil {
 		t.Fail()
@@ -1066,7 +1066,7 @@ func chaincodeQueryChaincode(user string) error {
 	spec2 = &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID2, CtorMsg: &pb.ChaincodeInput{Args: args}, SecureContext: user}
 	// Invoke chaincode
 	var retVal []byte
-	_, _, retVal, err = invoke(ctxt, spec2, pb.Transaction_CHAINCODE_INVOKE)
+	_, _, retVal, err = invoke(ctxt, spec2, pb.ChaincodeAction_CHAINCODE_INVOKE)
 
 	if err != nil {
 		GetChain(DefaultChain).Stop(ctxt, &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec1})
@@ -1088,7 +1088,7 @@ func chaincodeQueryChaincode(user string) error {
 
 	spec2 = &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID2, CtorMsg: &pb.ChaincodeInput{Args: args}, SecureContext: user}
 	// Invoke chaincode
-	_, _, retVal, err = invoke(ctxt, spec2, pb.Transaction_CHAINCODE_QUERY)
+	_, _, retVal, err = invoke(ctxt, spec2, pb.ChaincodeAction_CHAINCODE_QUERY)
 
 	if err != nil {
 		GetChain(DefaultChain).Stop(ctxt, &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec1})
@@ -1217,7 +1217,7 @@ func TestChaincodeQueryChaincodeErrorCase(t *testing.T) {
 
 	spec2 = &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID2, CtorMsg: &pb.ChaincodeInput{Args: args}}
 	// Invoke chaincode
-	_, _, _, err = invoke(ctxt, spec2, pb.Transaction_CHAINCODE_QUERY)
+	_, _, _, err = invoke(ctxt, spec2, pb.ChaincodeAction_CHAINCODE_QUERY)
 
 	if err == nil {
 		t.Fail()
@@ -1356,7 +1356,7 @@ func TestRangeQuery(t *testing.T) {
 	args = util.ToChaincodeArgs(f)
 
 	spec = &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID, CtorMsg: &pb.ChaincodeInput{Args: args}}
-	_, _, _, err = invoke(ctxt, spec, pb.Transaction_CHAINCODE_QUERY)
+	_, _, _, err = invoke(ctxt, spec, pb.ChaincodeAction_CHAINCODE_QUERY)
 
 	if err != nil {
 		t.Fail()
@@ -1427,7 +1427,7 @@ func TestGetEvent(t *testing.T) {
 	spec = &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
 	var ccevt *pb.ChaincodeEvent
-	ccevt, _, _, err = invoke(ctxt, spec, pb.Transaction_CHAINCODE_INVOKE)
+	ccevt, _, _, err = invoke(ctxt, spec, pb.ChaincodeAction_CHAINCODE_INVOKE)
 
 	if err != nil {
 		t.Logf("Error invoking chaincode %s(%s)", chaincodeID, err)
@@ -17,6 +17,7 @@ limitations under the License.
 package chaincode
 
 import (
+	"encoding/hex"
 	"fmt"
 	"io"
 	"sync"
@@ -42,8 +43,8 @@ const (
 	initstate        = "init"        //in:ESTABLISHED, rcv:-, send: INIT
 	readystate       = "ready"       //in:ESTABLISHED,TRANSACTION, rcv:COMPLETED
 	transactionstate = "transaction" //in:READY, rcv: xact from consensus, send: TRANSACTION
-	busyinitstate    = "busyinit"    //in:INIT, rcv: PUT_STATE, DEL_STATE, INVOKE_CHAINCODE
-	busyxactstate    = "busyxact"    //in:TRANSACION, rcv: PUT_STATE, DEL_STATE, INVOKE_CHAINCODE
+	busyinitstate    = "busyinit"    //in:INIT, rcv: PUT_STATE, DEL_STATE, INVOKE_CHAINCODE, MUTATE_TX_SET
+	busyxactstate    = "busyxact"    //in:TRANSACION, rcv: PUT_STATE, DEL_STATE, INVOKE_CHAINCODE, MUTATE_TX_SET
 	endstate         = "end"         //in:INIT,ESTABLISHED, rcv: error, terminate container
 
 )
@@ -62,6 +63,12 @@ type transactionContext struct {
 
 	// tracks open iterators used for range queries
 	rangeQueryIteratorMap map[string]stcomm.RangeScanIterator
+
+	// transactions set the transaction belongs to, forwarded to the chaincodes it calls
+	txSetContext *pb.ChaincodeTxSetContext
+
+	// ledger the transaction reads and writes its state on, see withLedger
+	ledger *ledger.Ledger
 }
 
 type nextStateInfo struct {
@@ -135,6 +142,22 @@ func (handler *Handler) getTxContext(txid string) *transactionContext {
 	return handler.txCtxs[txid]
 }
 
+// getTxSetContext returns the transactions set context of the transaction, nil if it has none
+func (handler *Handler) getTxSetContext(txid string) *pb.ChaincodeTxSetContext {
+	if txctx := handler.getTxContext(txid); txctx != nil {
+		return txctx.txSetContext
+	}
+	return nil
+}
+
+// getLedger returns the ledger the transaction reads and writes its state on, see withLedger
+func (handler *Handler) getLedger(txid string) (*ledger.Ledger, error) {
+	if txctx := handler.getTxContext(txid); txctx != nil && txctx.ledger != nil {
+		return txctx.ledger, nil
+	}
+	return ledger.GetLedger()
+}
+
 func (handler *Handler) deleteTxContext(txid string) {
 	handler.Lock()
 	defer handler.Unlock()
@@ -393,9 +416,11 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			{Name: pb.ChaincodeMessage_PUT_STATE.String(), Src: []string{transactionstate}, Dst: busyxactstate},
 			{Name: pb.ChaincodeMessage_DEL_STATE.String(), Src: []string{transactionstate}, Dst: busyxactstate},
 			{Name: pb.ChaincodeMessage_INVOKE_CHAINCODE.String(), Src: []string{transactionstate}, Dst: busyxactstate},
+			{Name: pb.ChaincodeMessage_MUTATE_TX_SET.String(), Src: []string{transactionstate}, Dst: busyxactstate},
 			{Name: pb.ChaincodeMessage_PUT_STATE.String(), Src: []string{initstate}, Dst: busyinitstate},
 			{Name: pb.ChaincodeMessage_DEL_STATE.String(), Src: []string{initstate}, Dst: busyinitstate},
 			{Name: pb.ChaincodeMessage_INVOKE_CHAINCODE.String(), Src: []string{initstate}, Dst: busyinitstate},
+			{Name: pb.ChaincodeMessage_MUTATE_TX_SET.String(), Src: []string{initstate}, Dst: busyinitstate},
 			{Name: pb.ChaincodeMessage_COMPLETED.String(), Src: []string{initstate, readystate, transactionstate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_STATE.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_STATE.String(), Src: []string{initstate}, Dst: initstate},
@@ -435,6 +460,7 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			"after_" + pb.ChaincodeMessage_PUT_STATE.String():               func(e *fsm.Event) { v.afterPutState(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_DEL_STATE.String():               func(e *fsm.Event) { v.afterDelState(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_INVOKE_CHAINCODE.String():        func(e *fsm.Event) { v.afterInvokeChaincode(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_MUTATE_TX_SET.String():           func(e *fsm.Event) { v.afterMutateTxSet(e, v.FSM.Current()) },
 			"enter_" + establishedstate:                                     func(e *fsm.Event) { v.enterEstablishedState(e, v.FSM.Current()) },
 			"enter_" + initstate:                                            func(e *fsm.Event) { v.enterInitState(e, v.FSM.Current()) },
 			"enter_" + readystate:                                           func(e *fsm.Event) { v.enterReadyState(e, v.FSM.Current()) },
@@ -611,7 +637,7 @@ func (handler *Handler) handleGetState(msg *pb.ChaincodeMessage) {
 		}()
 
 		key := string(msg.Payload)
-		ledgerObj, ledgerErr := ledger.GetLedger()
+		ledgerObj, ledgerErr := handler.getLedger(msg.Txid)
 		if ledgerErr != nil {
 			// Send error msg back to chaincode. GetState will not trigger event
 			payload := []byte(ledgerErr.Error())
@@ -625,7 +651,7 @@ func (handler *Handler) handleGetState(msg *pb.ChaincodeMessage) {
 		chaincodeID := handler.ChaincodeID.Name
 
 		readCommittedState := !handler.getIsTransaction(msg.Txid)
-		res, err := ledgerObj.GetState(chaincodeID, key, readCommittedState)
+		res, err := ledgerObj.GetStateForTx(msg.Txid, chaincodeID, key, readCommittedState)
 		if err != nil {
 			// Send error msg back to chaincode. GetState will not trigger event
 			payload := []byte(err.Error())
@@ -702,7 +728,7 @@ func (handler *Handler) handleRangeQueryState(msg *pb.ChaincodeMessage) {
 
 		hasNext := true
 
-		ledger, ledgerErr := ledger.GetLedger()
+		ledger, ledgerErr := handler.getLedger(msg.Txid)
 		if ledgerErr != nil {
 			// Send error msg back to chaincode. GetState will not trigger event
 			payload := []byte(ledgerErr.Error())
@@ -714,7 +740,7 @@ func (handler *Handler) handleRangeQueryState(msg *pb.ChaincodeMessage) {
 		chaincodeID := handler.ChaincodeID.Name
 
 		readCommittedState := !handler.getIsTransaction(msg.Txid)
-		rangeIter, err := ledger.GetStateRangeScanIterator(chaincodeID, rangeQueryState.StartKey, rangeQueryState.EndKey, readCommittedState)
+		rangeIter, err := ledger.GetStateRangeScanIteratorForTx(msg.Txid, chaincodeID, rangeQueryState.StartKey, rangeQueryState.EndKey, readCommittedState)
 		if err != nil {
 			// Send error msg back to chaincode. GetState will not trigger event
 			payload := []byte(err.Error())
@@ -982,6 +1008,39 @@ func (handler *Handler) afterInvokeChaincode(e *fsm.Event, state string) {
 	// Invoke another chaincode handled within enterBusyState
 }
 
+// afterMutateTxSet handles a MUTATE_TX_SET request from the chaincode.
+func (handler *Handler) afterMutateTxSet(e *fsm.Event, state string) {
+	_, ok := e.Args[0].(*pb.ChaincodeMessage)
+	if !ok {
+		e.Cancel(fmt.Errorf("Received unexpected message type"))
+		return
+	}
+	chaincodeLogger.Debugf("Received %s in state %s, queueing a mutant transaction", pb.ChaincodeMessage_MUTATE_TX_SET, state)
+
+	// Queue the mutation handled within enterBusyState
+}
+
+// queueMutation queues for the next block the mutant transaction requested by the chaincode while executing
+// the transaction txid. The requests made while the blocks are replayed are ignored: the mutations were queued
+// when the transaction was first executed, and a replay must never trigger further mutations
+func (handler *Handler) queueMutation(ledgerObj *ledger.Ledger, txid string, mutation *pb.TxSetMutation) error {
+	if txSetCtx := handler.getTxSetContext(txid); ledgerObj.IsResetting() || (txSetCtx != nil && txSetCtx.Replay) {
+		chaincodeLogger.Infof("[%s]Ignoring the mutation of the tx set with ID: %s requested while replaying the blocks", shorttxid(txid), mutation.TxSetID)
+		return nil
+	}
+	mutantTx := &pb.MutantTransaction{TxSetID: mutation.TxSetID, TxSetIndex: mutation.TxSetIndex, ChaincodeID: handler.ChaincodeID.Name}
+	mutBytes, err := proto.Marshal(mutantTx)
+	if err != nil {
+		return fmt.Errorf("Unable to marshal the mutant transaction (%s)", err)
+	}
+	// The ID is derived from the requesting transaction only, so that every validator queues the same transaction
+	inBlockTx := &pb.InBlockTransaction{
+		Transaction: &pb.InBlockTransaction_MutantTransaction{MutantTransaction: mutantTx},
+		Txid:        hex.EncodeToString(util.ComputeCryptoHash(append([]byte(txid), mutBytes...))),
+	}
+	return ledgerObj.QueueMutation(txid, inBlockTx)
+}
+
 // Handles request to ledger to put state
 func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 	go func() {
@@ -1012,7 +1071,7 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 			handler.triggerNextState(triggerNextStateMsg, true)
 		}()
 
-		ledgerObj, ledgerErr := ledger.GetLedger()
+		ledgerObj, ledgerErr := handler.getLedger(msg.Txid)
 		if ledgerErr != nil {
 			// Send error msg back to chaincode and trigger event
 			payload := []byte(ledgerErr.Error())
@@ -1039,12 +1098,22 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 			// Encrypt the data if the confidential is enabled
 			if pVal, err = handler.encrypt(msg.Txid, putStateInfo.Value); err == nil {
 				// Invoke ledger to put state
-				err = ledgerObj.SetState(chaincodeID, putStateInfo.Key, pVal)
+				err = ledgerObj.SetStateForTx(msg.Txid, chaincodeID, putStateInfo.Key, pVal)
 			}
 		} else if msg.Type.String() == pb.ChaincodeMessage_DEL_STATE.String() {
 			// Invoke ledger to delete state
 			key := string(msg.Payload)
-			err = ledgerObj.DeleteState(chaincodeID, key)
+			err = ledgerObj.DeleteStateForTx(msg.Txid, chaincodeID, key)
+		} else if msg.Type.String() == pb.ChaincodeMessage_MUTATE_TX_SET.String() {
+			mutation := &pb.TxSetMutation{}
+			unmarshalErr := proto.Unmarshal(msg.Payload, mutation)
+			if unmarshalErr != nil {
+				payload := []byte(unmarshalErr.Error())
+				chaincodeLogger.Debugf("[%s]Unable to decipher payload. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_ERROR)
+				triggerNextStateMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+				return
+			}
+			err = handler.queueMutation(ledgerObj, msg.Txid, mutation)
 		} else if msg.Type.String() == pb.ChaincodeMessage_INVOKE_CHAINCODE.String() {
 			//check and prohibit C-call-C for CONFIDENTIAL txs
 			if triggerNextStateMsg = handler.canCallChaincode(msg.Txid); triggerNextStateMsg != nil {
@@ -1067,7 +1136,7 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 			transaction, _ := pb.NewChaincodeExecute(chaincodeInvocationSpec, msg.Txid, pb.ChaincodeAction_CHAINCODE_INVOKE)
 
 			// Launch the new chaincode if not already running
-			_, chaincodeInput, launchErr := handler.chaincodeSupport.Launch(context.Background(), transaction)
+			_, chaincodeInput, launchErr := handler.chaincodeSupport.Launch(withLedger(context.Background(), ledgerObj), transaction)
 			if launchErr != nil {
 				payload := []byte(launchErr.Error())
 				chaincodeLogger.Debugf("[%s]Failed to launch invoked chaincode. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_ERROR)
@@ -1079,10 +1148,11 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 			timeout := time.Duration(30000) * time.Millisecond
 
 			ccMsg, _ := createTransactionMessage(transaction.Txid, chaincodeInput)
+			ccMsg.TxSetContext = handler.getTxSetContext(msg.Txid)
 
 			// Execute the chaincode
 			//NOTE: when confidential C-call-C is understood, transaction should have the correct sec context for enc/dec
-			response, execErr := handler.chaincodeSupport.Execute(context.Background(), newChaincodeID, ccMsg, timeout, transaction)
+			response, execErr := handler.chaincodeSupport.Execute(withLedger(context.Background(), ledgerObj), newChaincodeID, ccMsg, timeout, transaction)
 
 			//payload is marshalled and send to the calling chaincode's shim which unmarshals and
 			//sends it to chaincode
@@ -1261,7 +1331,7 @@ func (handler *Handler) setChaincodeSecurityContext(tx *pb.Transaction, msg *pb.
 
 //if initArgs is set (should be for "deploy" only) move to Init
 //else move to ready
-func (handler *Handler) initOrReady(txid string, initArgs [][]byte, tx *pb.Transaction, depTx *pb.Transaction) (chan *pb.ChaincodeMessage, error) {
+func (handler *Handler) initOrReady(txid string, initArgs [][]byte, tx *pb.Transaction, depTx *pb.Transaction, txSetCtx *pb.ChaincodeTxSetContext, lgr *ledger.Ledger) (chan *pb.ChaincodeMessage, error) {
 	var ccMsg *pb.ChaincodeMessage
 	var send bool
 
@@ -1269,6 +1339,8 @@ func (handler *Handler) initOrReady(txid string, initArgs [][]byte, tx *pb.Trans
 	if funcErr != nil {
 		return nil, funcErr
 	}
+	txctx.txSetContext = txSetCtx
+	txctx.ledger = lgr
 
 	notfy := txctx.responseNotifier
 
@@ -1280,7 +1352,7 @@ func (handler *Handler) initOrReady(txid string, initArgs [][]byte, tx *pb.Trans
 			handler.deleteTxContext(txid)
 			return nil, fmt.Errorf("Failed to marshall %s : %s\n", ccMsg.Type.String(), funcErr)
 		}
-		ccMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_INIT, Payload: payload, Txid: txid}
+		ccMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_INIT, Payload: payload, Txid: txid, TxSetContext: txSetCtx}
 		send = false
 	} else {
 		chaincodeLogger.Debug("sending READY")
@@ -1342,8 +1414,18 @@ func (handler *Handler) handleQueryChaincode(msg *pb.ChaincodeMessage) {
 		chaincodeInvocationSpec := &pb.ChaincodeInvocationSpec{ChaincodeSpec: chaincodeSpec}
 		transaction, _ := pb.NewChaincodeExecute(chaincodeInvocationSpec, msg.Txid, pb.ChaincodeAction_CHAINCODE_QUERY)
 
+		// The called chaincode queries the ledger of the calling transaction
+		ledgerObj, ledgerErr := handler.getLedger(msg.Txid)
+		if ledgerErr != nil {
+			payload := []byte(ledgerErr.Error())
+			chaincodeLogger.Debugf("[%s]Failed to get ledger. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+			return
+		}
+		ctxt := withLedger(context.Background(), ledgerObj)
+
 		// Launch the new chaincode if not already running
-		_, chaincodeInput, launchErr := handler.chaincodeSupport.Launch(context.Background(), transaction)
+		_, chaincodeInput, launchErr := handler.chaincodeSupport.Launch(ctxt, transaction)
 		if launchErr != nil {
 			payload := []byte(launchErr.Error())
 			chaincodeLogger.Debugf("[%s]Failed to launch invoked chaincode. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_ERROR)
@@ -1355,10 +1437,11 @@ func (handler *Handler) handleQueryChaincode(msg *pb.ChaincodeMessage) {
 		timeout := time.Duration(30000) * time.Millisecond
 
 		ccMsg, _ := createQueryMessage(transaction.Txid, chaincodeInput)
+		ccMsg.TxSetContext = handler.getTxSetContext(msg.Txid)
 
 		// Query the chaincode
 		//NOTE: when confidential C-call-C is understood, transaction should have the correct sec context for enc/dec
-		response, execErr := handler.chaincodeSupport.Execute(context.Background(), newChaincodeID, ccMsg, timeout, transaction)
+		response, execErr := handler.chaincodeSupport.Execute(ctxt, newChaincodeID, ccMsg, timeout, transaction)
 
 		if execErr != nil {
 			// Send error msg back to chaincode and trigger event
@@ -1412,7 +1495,7 @@ func (handler *Handler) HandleMessage(msg *pb.ChaincodeMessage) error {
 	}
 	if handler.FSM.Cannot(msg.Type.String()) {
 		// Check if this is a request from validator in query context
-		if msg.Type.String() == pb.ChaincodeMessage_PUT_STATE.String() || msg.Type.String() == pb.ChaincodeMessage_DEL_STATE.String() || msg.Type.String() == pb.ChaincodeMessage_INVOKE_CHAINCODE.String() {
+		if msg.Type.String() == pb.ChaincodeMessage_PUT_STATE.String() || msg.Type.String() == pb.ChaincodeMessage_DEL_STATE.String() || msg.Type.String() == pb.ChaincodeMessage_INVOKE_CHAINCODE.String() || msg.Type.String() == pb.ChaincodeMessage_MUTATE_TX_SET.String() {
 			// Check if this TXID is a transaction
 			if !handler.getIsTransaction(msg.Txid) {
 				payload := []byte(fmt.Sprintf("[%s]Cannot handle %s in query context", msg.Txid, msg.Type.String()))
@@ -1456,11 +1539,13 @@ func filterError(errFromFSMEvent error) error {
 	return nil
 }
 
-func (handler *Handler) sendExecuteMessage(msg *pb.ChaincodeMessage, tx *pb.Transaction) (chan *pb.ChaincodeMessage, error) {
+func (handler *Handler) sendExecuteMessage(msg *pb.ChaincodeMessage, tx *pb.Transaction, lgr *ledger.Ledger) (chan *pb.ChaincodeMessage, error) {
 	txctx, err := handler.createTxContext(msg.Txid, tx)
 	if err != nil {
 		return nil, err
 	}
+	txctx.txSetContext = msg.TxSetContext
+	txctx.ledger = lgr
 
 	// Mark TXID as either transaction or query
 	chaincodeLogger.Debugf("[%s]Inside sendExecuteMessage. Message %s", shorttxid(msg.Txid), msg.Type.String())
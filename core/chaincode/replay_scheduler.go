@@ -0,0 +1,339 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/state/chaincodest/statemgmt"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// replayScheduler replays the transactions of the blocks following a mutation. Only the transactions reading
+// keys whose value might have changed because of the mutations are executed again, the recorded changes of the
+// others are simply applied.
+// Consecutive transactions of a block which touch different chaincodes cannot affect each other, so they are
+// grouped and up to parallelism of them are executed concurrently. The changes of a group are merged in the order
+// of the block, which gives the same result as executing the transactions one after the other. The chaincodes
+// touched by a transaction are known from its recorded read/write set: if a transaction executed concurrently
+// fails or touches other chaincodes, it might have disturbed (or been disturbed by) the rest of its group, so the
+// group is executed again one transaction after the other from that transaction onwards
+type replayScheduler struct {
+	ctxt                 context.Context
+	chain                *ChaincodeSupport
+	ledger               *ledger.Ledger
+	parallelism          int
+	mutatedSets          map[string]bool
+	changedKeys          *statemgmt.KeySet
+	redeployedChaincodes map[string]bool
+	replayAll            bool
+	numReExecuted        int
+	numReplayed          int
+	// execute executes a transaction again, it is Execute unless the chaincodes are simulated by a test
+	execute func(ctxt context.Context, chain *ChaincodeSupport, inBlockTx *pb.InBlockTransaction) ([]byte, *pb.ChaincodeEvent, error)
+
+	// The transactions of the block being replayed which are grouped but not executed yet
+	group           []*groupedTx
+	groupChaincodes map[string]bool
+}
+
+// groupedTx is a transaction of a group, with the chaincodes it touches
+type groupedTx struct {
+	txIndex    int
+	rwSet      *statemgmt.TxReadWriteSet
+	chaincodes map[string]bool
+	execute    bool
+	ccEvent    *pb.ChaincodeEvent
+	txerr      error
+}
+
+func newReplayScheduler(ctxt context.Context, chain *ChaincodeSupport, ledger *ledger.Ledger, parallelism int) *replayScheduler {
+	mutatedSets := make(map[string]bool)
+	for _, txSetID := range ledger.GetMutatedTxSetIDs() {
+		mutatedSets[txSetID] = true
+	}
+	return &replayScheduler{ctxt: ctxt, chain: chain, ledger: ledger, parallelism: parallelism, mutatedSets: mutatedSets,
+		changedKeys: statemgmt.NewKeySet(), redeployedChaincodes: make(map[string]bool), execute: Execute, groupChaincodes: make(map[string]bool)}
+}
+
+// replayBlock replays the transactions of the block blockNumber in the ongoing batch. blockRWSets are the read/write
+// sets recorded for the transactions of the block, nil if they were not recorded. The returned report lists the
+// transactions whose outcome changed
+func (sched *replayScheduler) replayBlock(blockNumber uint64, block *pb.Block, blockRWSets []*statemgmt.TxReadWriteSet) (*pb.BlockReplayReport, error) {
+	if blockRWSets == nil && !sched.replayAll {
+		// Without the read/write sets the dependencies are unknown, every following transaction is executed again
+		chaincodeLogger.Warningf("No read/write sets recorded for block %d, re-executing all the transactions from this block.", blockNumber)
+		sched.replayAll = true
+	}
+	rwSets := make(map[string]*statemgmt.TxReadWriteSet)
+	for _, rwSet := range blockRWSets {
+		rwSets[rwSet.TxSetID] = rwSet
+	}
+	report := &pb.BlockReplayReport{BlockNumber: blockNumber}
+
+	for txIndex, t := range block.GetTransactions() {
+		if t.GetMutantTransaction() != nil || t.GetSetStQueryTransaction() != nil {
+			// Ordered state queries do not change the state, there is nothing to replay
+			continue
+		}
+		rwSet := rwSets[t.Txid]
+		chaincodes := sched.touchedChaincodes(t, rwSet)
+		if chaincodes == nil {
+			if err := sched.flush(block, report); err != nil {
+				return nil, err
+			}
+			if err := sched.replayTx(block, txIndex, rwSet, report); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for chaincodeID := range chaincodes {
+			if sched.groupChaincodes[chaincodeID] {
+				if err := sched.flush(block, report); err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+		// The transactions of the group do not touch the chaincodes of this one, the keys they change do not matter
+		sched.group = append(sched.group, &groupedTx{txIndex: txIndex, rwSet: rwSet, chaincodes: chaincodes, execute: sched.mustExecute(t, rwSet)})
+		for chaincodeID := range chaincodes {
+			sched.groupChaincodes[chaincodeID] = true
+		}
+	}
+	if err := sched.flush(block, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// touchedChaincodes returns the chaincodes touched by the transaction if it can be grouped with other transactions,
+// nil otherwise. Only the invocations of the sets which were not mutated are grouped, since mutated sets and
+// deployments change the code run by a chaincode. The chaincodes touched by a transaction without a recorded
+// read/write set are unknown
+func (sched *replayScheduler) touchedChaincodes(t *pb.InBlockTransaction, rwSet *statemgmt.TxReadWriteSet) map[string]bool {
+	if sched.parallelism <= 1 || rwSet == nil || sched.mutatedSets[t.Txid] {
+		return nil
+	}
+	defTx, err := sched.ledger.GetCurrentDefault(t, false)
+	if err != nil {
+		return nil
+	}
+	if secHelper := sched.chain.getSecHelper(); nil != secHelper {
+		if defTx, err = secHelper.TransactionPreExecution(defTx); nil != err {
+			return nil
+		}
+	}
+	if defTx.Type != pb.ChaincodeAction_CHAINCODE_INVOKE {
+		return nil
+	}
+	ci := &pb.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(defTx.Payload, ci); err != nil || ci.GetChaincodeSpec().GetChaincodeID() == nil || ci.ChaincodeSpec.ChaincodeID.Name == "" {
+		return nil
+	}
+	chaincodes := map[string]bool{ci.ChaincodeSpec.ChaincodeID.Name: true}
+	for _, chaincodeID := range rwSet.GetChaincodeIds() {
+		chaincodes[chaincodeID] = true
+	}
+	return chaincodes
+}
+
+// mustExecute returns true if the transaction must be executed again, false if its recorded changes can be applied
+func (sched *replayScheduler) mustExecute(t *pb.InBlockTransaction, rwSet *statemgmt.TxReadWriteSet) bool {
+	return sched.replayAll || rwSet == nil || sched.mutatedSets[t.Txid] || dependsOnChanges(rwSet, sched.changedKeys, sched.redeployedChaincodes)
+}
+
+// flush replays the transactions of the group. The recorded changes are applied first, then the transactions
+// to execute again are executed concurrently
+func (sched *replayScheduler) flush(block *pb.Block, report *pb.BlockReplayReport) error {
+	group := sched.group
+	if len(group) == 0 {
+		return nil
+	}
+	sched.group = nil
+	sched.groupChaincodes = make(map[string]bool)
+	var numExecuted int
+	for _, gtx := range group {
+		if gtx.execute {
+			numExecuted++
+		}
+	}
+	if numExecuted < 2 {
+		for _, gtx := range group {
+			if err := sched.replayTx(block, gtx.txIndex, gtx.rwSet, report); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	sched.ledger.BeginConcurrentChainTxs()
+	for _, gtx := range group {
+		if !gtx.execute {
+			if err := sched.ledger.ReplayChainTx(gtx.rwSet); err != nil {
+				sched.ledger.EndConcurrentChainTxs(nil)
+				return fmt.Errorf("Unable to replay the changes of transaction with id %s at block %d. (%s)", block.Transactions[gtx.txIndex].Txid, report.BlockNumber, err)
+			}
+		}
+	}
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, sched.parallelism)
+	for _, gtx := range group {
+		if gtx.execute {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(gtx *groupedTx) {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+				_, gtx.ccEvent, gtx.txerr = sched.execute(sched.ctxt, sched.chain, block.Transactions[gtx.txIndex])
+			}(gtx)
+		}
+	}
+	wg.Wait()
+
+	numValid := len(group)
+	for i, gtx := range group {
+		if gtx.execute && !sched.isConcurrentResultValid(block.Transactions[gtx.txIndex], gtx) {
+			numValid = i
+			break
+		}
+	}
+	merged := make([]string, numValid)
+	for i, gtx := range group[:numValid] {
+		merged[i] = block.Transactions[gtx.txIndex].Txid
+	}
+	sched.ledger.EndConcurrentChainTxs(merged)
+
+	for _, gtx := range group[:numValid] {
+		if gtx.execute {
+			sched.recordExecution(block, gtx.txIndex, gtx.rwSet, gtx.ccEvent, gtx.txerr, report)
+		} else {
+			sched.recordReplay(gtx.rwSet)
+		}
+	}
+	if numValid < len(group) {
+		chaincodeLogger.Debugf("Transaction with id %s at block %d cannot be executed concurrently, executing the rest of its group serially",
+			block.Transactions[group[numValid].txIndex].Txid, report.BlockNumber)
+		for _, gtx := range group[numValid:] {
+			if err := sched.replayTx(block, gtx.txIndex, gtx.rwSet, report); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isConcurrentResultValid returns true if the concurrent execution of the transaction gave the same result as
+// a serial execution would: it succeeded and did not touch other chaincodes than the ones of its group entry
+func (sched *replayScheduler) isConcurrentResultValid(t *pb.InBlockTransaction, gtx *groupedTx) bool {
+	if gtx.txerr != nil {
+		return false
+	}
+	newRWSet := sched.ledger.GetTxReadWriteSet(t.Txid)
+	if newRWSet == nil {
+		return false
+	}
+	for _, chaincodeID := range newRWSet.GetChaincodeIds() {
+		if !gtx.chaincodes[chaincodeID] {
+			return false
+		}
+	}
+	return true
+}
+
+// replayTx replays the transaction at txIndex in the block on its own
+func (sched *replayScheduler) replayTx(block *pb.Block, txIndex int, rwSet *statemgmt.TxReadWriteSet, report *pb.BlockReplayReport) error {
+	t := block.Transactions[txIndex]
+	if !sched.mustExecute(t, rwSet) {
+		if err := sched.ledger.ReplayChainTx(rwSet); err != nil {
+			return fmt.Errorf("Unable to replay the changes of transaction with id %s at block %d. (%s)", t.Txid, report.BlockNumber, err)
+		}
+		sched.recordReplay(rwSet)
+		return nil
+	}
+
+	// Check if the previous default was a deploy transaction and if so terminate it
+	prevDefault, err := prevDefault(sched.ledger, t.Txid)
+	if err != nil {
+		return fmt.Errorf("Unable to verify the previous default transaction for the set with ID: %s. (%s)", t.Txid, err)
+	}
+	if prevDefault != nil && prevDefault.Type == pb.ChaincodeAction_CHAINCODE_DEPLOY {
+		depSpec := &pb.ChaincodeDeploymentSpec{}
+		errUnm := proto.Unmarshal(prevDefault.Payload, depSpec)
+		if errUnm != nil {
+			chaincodeLogger.Errorf("Unable to retrieve specification for previous deploy transaction. %s", errUnm)
+		} else {
+			errStop := sched.chain.Stop(sched.ctxt, depSpec)
+			if errStop != nil {
+				chaincodeLogger.Errorf("Unable to stop previous default transaction vm. (%s)", errStop)
+			}
+			if sched.mutatedSets[t.Txid] && depSpec.GetChaincodeSpec().GetChaincodeID() != nil {
+				// A different code might now be deployed under the same name
+				sched.redeployedChaincodes[depSpec.ChaincodeSpec.ChaincodeID.Name] = true
+			}
+		}
+	}
+	_, ccEvent, txerr := sched.execute(sched.ctxt, sched.chain, t)
+
+	if sched.mutatedSets[t.Txid] {
+		newDefault, err := sched.ledger.GetCurrentDefault(t, false)
+		if err == nil && newDefault.Type == pb.ChaincodeAction_CHAINCODE_DEPLOY {
+			depSpec := &pb.ChaincodeDeploymentSpec{}
+			if errUnm := proto.Unmarshal(newDefault.Payload, depSpec); errUnm == nil && depSpec.GetChaincodeSpec().GetChaincodeID() != nil {
+				sched.redeployedChaincodes[depSpec.ChaincodeSpec.ChaincodeID.Name] = true
+			}
+		}
+	}
+	sched.recordExecution(block, txIndex, rwSet, ccEvent, txerr, report)
+	return nil
+}
+
+// recordReplay updates the changed keys after the recorded changes of a transaction were applied
+func (sched *replayScheduler) recordReplay(rwSet *statemgmt.TxReadWriteSet) {
+	sched.changedKeys.RemoveWritten(rwSet.Writes)
+	sched.numReplayed++
+}
+
+// recordExecution updates the changed keys and the report after the transaction at txIndex in the block was
+// executed again. rwSet is the read/write set recorded by its previous execution
+func (sched *replayScheduler) recordExecution(block *pb.Block, txIndex int, rwSet *statemgmt.TxReadWriteSet,
+	ccEvent *pb.ChaincodeEvent, txerr error, report *pb.BlockReplayReport) {
+	t := block.Transactions[txIndex]
+	if txerr != nil {
+		chaincodeLogger.Warningf("Error while re-executing transaction with id %s at block %d. Error: [%s]", t.Txid, report.BlockNumber, txerr)
+	}
+	if result := replayResult(block, txIndex, rwSet, ccEvent, txerr); result != nil {
+		report.Transactions = append(report.Transactions, result)
+	}
+	sched.numReExecuted++
+
+	var prevWrites, newWrites *statemgmt.StateDelta
+	if rwSet != nil {
+		prevWrites = rwSet.Writes
+	}
+	if newRWSet := sched.ledger.GetTxReadWriteSet(t.Txid); newRWSet != nil {
+		newWrites = newRWSet.Writes
+	}
+	sched.changedKeys.AddChanged(prevWrites, newWrites)
+}
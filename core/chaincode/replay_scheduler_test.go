@@ -0,0 +1,287 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/state/chaincodest/statemgmt"
+	"github.com/hyperledger/fabric/core/util"
+	pb "github.com/hyperledger/fabric/protos"
+	"golang.org/x/net/context"
+)
+
+// replayTestChaincodes simulates the chaincodes invoked by the transactions replayed in the tests. A transaction
+// adds the key "step" of its chaincode (1 if not set) to the key "counter" and emits the new counter. If the key
+// "redirect" names another chaincode, the counter of that chaincode is incremented as well, and if the key "fail"
+// is set the transaction fails
+type replayTestChaincodes struct {
+	sync.Mutex
+	ledger        *ledger.Ledger
+	executionTime time.Duration
+	running       int
+	maxRunning    int
+}
+
+func (chaincodes *replayTestChaincodes) execute(ctxt context.Context, chain *ChaincodeSupport, inBlockTx *pb.InBlockTransaction) ([]byte, *pb.ChaincodeEvent, error) {
+	lgr := chaincodes.ledger
+	defTx, err := lgr.GetCurrentDefault(inBlockTx, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	ci := &pb.ChaincodeInvocationSpec{}
+	if err = proto.Unmarshal(defTx.Payload, ci); err != nil {
+		return nil, nil, err
+	}
+	chaincodeID := ci.ChaincodeSpec.ChaincodeID.Name
+
+	chaincodes.Lock()
+	chaincodes.running++
+	if chaincodes.running > chaincodes.maxRunning {
+		chaincodes.maxRunning = chaincodes.running
+	}
+	chaincodes.Unlock()
+	defer func() {
+		chaincodes.Lock()
+		chaincodes.running--
+		chaincodes.Unlock()
+	}()
+	time.Sleep(chaincodes.executionTime)
+
+	lgr.ChainTxBeginForSet(inBlockTx.Txid, defTx.Txid)
+	counter, err := chaincodes.increment(defTx.Txid, chaincodeID)
+	if err == nil {
+		var redirect []byte
+		if redirect, err = lgr.GetStateForTx(defTx.Txid, chaincodeID, "redirect", false); err == nil && len(redirect) > 0 {
+			_, err = chaincodes.increment(defTx.Txid, string(redirect))
+		}
+	}
+	if err == nil {
+		var fail []byte
+		if fail, err = lgr.GetStateForTx(defTx.Txid, chaincodeID, "fail", false); err == nil && len(fail) > 0 {
+			err = fmt.Errorf("Transaction %s of chaincode %s failed", defTx.Txid, chaincodeID)
+		}
+	}
+	if err != nil {
+		lgr.ChainTxFinished(defTx.Txid, false)
+		return nil, nil, err
+	}
+	lgr.ChainTxFinished(defTx.Txid, true)
+	return nil, &pb.ChaincodeEvent{ChaincodeID: chaincodeID, TxID: defTx.Txid, EventName: "counter", Payload: counter}, nil
+}
+
+func (chaincodes *replayTestChaincodes) increment(txID string, chaincodeID string) ([]byte, error) {
+	step := 1
+	stepValue, err := chaincodes.ledger.GetStateForTx(txID, chaincodeID, "step", false)
+	if err != nil {
+		return nil, err
+	}
+	if len(stepValue) > 0 {
+		if step, err = strconv.Atoi(string(stepValue)); err != nil {
+			return nil, err
+		}
+	}
+	counter := 0
+	counterValue, err := chaincodes.ledger.GetStateForTx(txID, chaincodeID, "counter", false)
+	if err != nil {
+		return nil, err
+	}
+	if len(counterValue) > 0 {
+		if counter, err = strconv.Atoi(string(counterValue)); err != nil {
+			return nil, err
+		}
+	}
+	newValue := []byte(strconv.Itoa(counter + step))
+	return newValue, chaincodes.ledger.SetStateForTx(txID, chaincodeID, "counter", newValue)
+}
+
+func newReplayTestLedger(tb testing.TB) *ledger.Ledger {
+	testDBWrapper.CleanDB(tb)
+	lgr, err := ledger.GetNewLedger()
+	if err != nil {
+		tb.Fatalf("Error while constructing ledger: %s", err)
+	}
+	return lgr
+}
+
+// buildReplayTestTx builds a transactions set whose default transaction invokes the chaincode
+func buildReplayTestTx(tb testing.TB, txSetID string, chaincodeID string) *pb.InBlockTransaction {
+	spec := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{Type: pb.ChaincodeSpec_GOLANG,
+		ChaincodeID: &pb.ChaincodeID{Name: chaincodeID}, CtorMsg: &pb.ChaincodeInput{Args: util.ToChaincodeArgs("increment")}}}
+	tx, err := pb.NewChaincodeExecute(spec, txSetID+"_default", pb.ChaincodeAction_CHAINCODE_INVOKE)
+	if err != nil {
+		tb.Fatalf("Error while building the transaction: %s", err)
+	}
+	txBytes, err := proto.Marshal(tx)
+	if err != nil {
+		tb.Fatalf("Error while marshalling the transaction: %s", err)
+	}
+	return &pb.InBlockTransaction{Txid: txSetID, Transaction: &pb.InBlockTransaction_TransactionSet{
+		TransactionSet: &pb.TransactionSet{Transactions: [][]byte{txBytes}}}}
+}
+
+// executeReplayTestBlock executes a block invoking the chaincodes, one transaction per chaincode ID, and returns
+// it with its chaincode events and the read/write sets of its transactions
+func executeReplayTestBlock(tb testing.TB, chaincodeIDs []string) (*pb.Block, []*statemgmt.TxReadWriteSet) {
+	lgr := newReplayTestLedger(tb)
+	chaincodes := &replayTestChaincodes{ledger: lgr}
+	block := &pb.Block{NonHashData: &pb.NonHashData{}}
+	var rwSets []*statemgmt.TxReadWriteSet
+	lgr.BeginTxBatch(1)
+	for i, chaincodeID := range chaincodeIDs {
+		tx := buildReplayTestTx(tb, fmt.Sprintf("txSet_%d", i), chaincodeID)
+		_, ccEvent, err := chaincodes.execute(context.Background(), nil, tx)
+		if err != nil {
+			tb.Fatalf("Error while executing transaction %s: %s", tx.Txid, err)
+		}
+		block.Transactions = append(block.Transactions, tx)
+		block.NonHashData.ChaincodeEvents = append(block.NonHashData.ChaincodeEvents, ccEvent)
+		rwSets = append(rwSets, lgr.GetTxReadWriteSet(tx.Txid))
+	}
+	lgr.RollbackTxBatch(1)
+	return block, rwSets
+}
+
+type replayTestResult struct {
+	report     *pb.BlockReplayReport
+	stateHash  []byte
+	rwSets     []*statemgmt.TxReadWriteSet
+	counters   map[string]string
+	maxRunning int
+}
+
+// replayTestBlock replays the block after the mutation changed the state, with the given parallelism
+func replayTestBlock(t *testing.T, block *pb.Block, blockRWSets []*statemgmt.TxReadWriteSet, mutation map[string]map[string]string, parallelism int) *replayTestResult {
+	lgr := newReplayTestLedger(t)
+	lgr.BeginTxBatch(1)
+	defer lgr.RollbackTxBatch(1)
+	chaincodes := &replayTestChaincodes{ledger: lgr, executionTime: 10 * time.Millisecond}
+	sched := newReplayScheduler(context.Background(), &ChaincodeSupport{}, lgr, parallelism)
+	sched.execute = chaincodes.execute
+
+	lgr.ChainTxBeginForSet("mutation", "mutation")
+	for chaincodeID, kvs := range mutation {
+		for key, value := range kvs {
+			if err := lgr.SetState(chaincodeID, key, []byte(value)); err != nil {
+				t.Fatalf("Error while setting state: %s", err)
+			}
+			sched.changedKeys.Add(chaincodeID, key)
+		}
+	}
+	lgr.ChainTxFinished("mutation", true)
+
+	report, err := sched.replayBlock(1, block, blockRWSets)
+	if err != nil {
+		t.Fatalf("Error while replaying the block with parallelism %d: %s", parallelism, err)
+	}
+	result := &replayTestResult{report: report, counters: make(map[string]string), maxRunning: chaincodes.maxRunning}
+	if result.stateHash, err = lgr.GetTempStateHash(); err != nil {
+		t.Fatalf("Error while computing the state hash: %s", err)
+	}
+	for _, tx := range block.Transactions {
+		rwSet := lgr.GetTxReadWriteSet(tx.Txid)
+		if rwSet == nil {
+			t.Fatalf("No read/write set recorded for transaction %s", tx.Txid)
+		}
+		result.rwSets = append(result.rwSets, rwSet)
+		for _, chaincodeID := range rwSet.GetChaincodeIds() {
+			counter, err := lgr.GetState(chaincodeID, "counter", false)
+			if err != nil {
+				t.Fatalf("Error while getting state: %s", err)
+			}
+			result.counters[chaincodeID] = string(counter)
+		}
+	}
+	return result
+}
+
+func TestReplaySchedulerParallelMatchesSerial(t *testing.T) {
+	// The first six transactions touch different chaincodes and form a group, the last two start a new one
+	block, blockRWSets := executeReplayTestBlock(t, []string{"cc0", "cc1", "cc2", "cc3", "cc4", "cc5", "cc0", "cc1"})
+
+	testCases := []struct {
+		name     string
+		mutation map[string]map[string]string
+		counters map[string]string
+	}{
+		// txSet_2 now touches cc4 too, txSet_4 must see its change: the group is executed serially from txSet_2
+		{"touches another chaincode", map[string]map[string]string{"cc0": {"step": "1"}, "cc2": {"redirect": "cc4"}, "cc3": {"step": "5"}},
+			map[string]string{"cc0": "2", "cc1": "2", "cc2": "1", "cc3": "5", "cc4": "2", "cc5": "1"}},
+		// txSet_1 now fails: the group is executed serially from txSet_1, and txSet_7 fails as well
+		{"fails", map[string]map[string]string{"cc0": {"step": "1"}, "cc1": {"fail": "1"}, "cc3": {"step": "1"}},
+			map[string]string{"cc0": "2", "cc1": "", "cc2": "1", "cc3": "1", "cc4": "1", "cc5": "1"}},
+	}
+	for _, testCase := range testCases {
+		serial := replayTestBlock(t, block, blockRWSets, testCase.mutation, 1)
+		parallel := replayTestBlock(t, block, blockRWSets, testCase.mutation, 4)
+		if parallel.maxRunning < 2 {
+			t.Errorf("%s: expected transactions to be executed concurrently", testCase.name)
+		}
+		for chaincodeID, counter := range testCase.counters {
+			if serial.counters[chaincodeID] != counter || parallel.counters[chaincodeID] != counter {
+				t.Errorf("%s: expected counter %q for chaincode %s, got %q serially and %q in parallel", testCase.name, counter,
+					chaincodeID, serial.counters[chaincodeID], parallel.counters[chaincodeID])
+			}
+		}
+		if !bytes.Equal(serial.stateHash, parallel.stateHash) {
+			t.Errorf("%s: the state differs between the serial and the parallel replay", testCase.name)
+		}
+		if !reflect.DeepEqual(serial.rwSets, parallel.rwSets) {
+			t.Errorf("%s: the read/write sets differ between the serial and the parallel replay", testCase.name)
+		}
+		if len(serial.report.Transactions) == 0 || !proto.Equal(serial.report, parallel.report) {
+			t.Errorf("%s: expected the same non empty report, got %v serially and %v in parallel", testCase.name, serial.report, parallel.report)
+		}
+	}
+}
+
+func BenchmarkReplaySchedulerIndependentTransactions(b *testing.B) {
+	// Consecutive transactions touch different chaincodes, every transaction is executed again
+	const numChaincodes = 8
+	chaincodeIDs := make([]string, 64)
+	for i := range chaincodeIDs {
+		chaincodeIDs[i] = "cc" + strconv.Itoa(i%numChaincodes)
+	}
+	block, blockRWSets := executeReplayTestBlock(b, chaincodeIDs)
+
+	// The ratio of the two shows the speedup of the parallel replay
+	for _, parallelism := range []int{1, 4} {
+		b.Run(fmt.Sprintf("Parallelism=%d", parallelism), func(b *testing.B) {
+			lgr := newReplayTestLedger(b)
+			chaincodes := &replayTestChaincodes{ledger: lgr, executionTime: time.Millisecond}
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				lgr.BeginTxBatch(n)
+				sched := newReplayScheduler(context.Background(), &ChaincodeSupport{}, lgr, parallelism)
+				sched.execute = chaincodes.execute
+				sched.replayAll = true
+				if _, err := sched.replayBlock(1, block, blockRWSets); err != nil {
+					b.Fatalf("Error while replaying the block: %s", err)
+				}
+				lgr.RollbackTxBatch(n)
+			}
+		})
+	}
+}
@@ -52,6 +52,7 @@ type ChaincodeStub struct {
 	TxID            string
 	securityContext *pb.ChaincodeSecurityContext
 	chaincodeEvent  *pb.ChaincodeEvent
+	txSetContext    *pb.ChaincodeTxSetContext
 	args            [][]byte
 }
 
@@ -233,9 +234,10 @@ func chatWithPeer(chaincodename string, stream PeerChaincodeStream, cc Chaincode
 // -- init stub ---
 // ChaincodeInvocation functionality
 
-func (stub *ChaincodeStub) init(txid string, secContext *pb.ChaincodeSecurityContext) {
+func (stub *ChaincodeStub) init(txid string, secContext *pb.ChaincodeSecurityContext, txSetContext *pb.ChaincodeTxSetContext) {
 	stub.TxID = txid
 	stub.securityContext = secContext
+	stub.txSetContext = txSetContext
 	stub.args = [][]byte{}
 	newCI := pb.ChaincodeInput{}
 	err := proto.Unmarshal(secContext.Payload, &newCI)
@@ -250,6 +252,32 @@ func (stub *ChaincodeStub) GetTxID() string {
 	return stub.TxID
 }
 
+// GetTxSetID returns the ID of the transactions set whose default transaction is being executed
+func (stub *ChaincodeStub) GetTxSetID() string {
+	if stub.txSetContext == nil {
+		return ""
+	}
+	return stub.txSetContext.TxSetID
+}
+
+// GetTxSetIndex returns the index in its set of the transaction being executed
+func (stub *ChaincodeStub) GetTxSetIndex() uint64 {
+	if stub.txSetContext == nil {
+		return 0
+	}
+	return stub.txSetContext.TxSetIndex
+}
+
+// IsReplay returns whether the transaction is being re-executed because a mutation changed
+// the default transaction of a set of a previous block. Chaincodes should not trigger side
+// effects outside of the ledger, such as notifications, on a replay.
+func (stub *ChaincodeStub) IsReplay() bool {
+	if stub.txSetContext == nil {
+		return false
+	}
+	return stub.txSetContext.Replay
+}
+
 // --------- Security functions ----------
 //CHAINCODE SEC INTERFACE FUNCS TOBE IMPLEMENTED BY ANGELO
 
@@ -286,6 +314,13 @@ func (stub *ChaincodeStub) DelState(key string) error {
 	return handler.handleDelState(key, stub.TxID)
 }
 
+// MutateTxSet requests that the transaction at index becomes the default of the
+// transactions set txSetID. The mutation is queued for the next block if the
+// transaction succeeds, the requests made during a replay are ignored.
+func (stub *ChaincodeStub) MutateTxSet(txSetID string, index uint64) error {
+	return handler.handleMutateTxSet(txSetID, index, stub.TxID)
+}
+
 //ReadCertAttribute is used to read an specific attribute from the transaction certificate, *attributeName* is passed as input parameter to this function.
 // Example:
 //  attrValue,error:=stub.ReadCertAttribute("position")
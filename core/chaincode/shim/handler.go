@@ -222,7 +222,7 @@ func (handler *Handler) handleInit(msg *pb.ChaincodeMessage) {
 		// Call chaincode's Run
 		// Create the ChaincodeStub which the chaincode can use to callback
 		stub := new(ChaincodeStub)
-		stub.init(msg.Txid, msg.SecurityContext)
+		stub.init(msg.Txid, msg.SecurityContext, msg.TxSetContext)
 		function, params := getFunctionAndParams(stub)
 		res, err := handler.cc.Init(stub, function, params)
 
@@ -290,7 +290,7 @@ func (handler *Handler) handleTransaction(msg *pb.ChaincodeMessage) {
 		// Call chaincode's Run
 		// Create the ChaincodeStub which the chaincode can use to callback
 		stub := new(ChaincodeStub)
-		stub.init(msg.Txid, msg.SecurityContext)
+		stub.init(msg.Txid, msg.SecurityContext, msg.TxSetContext)
 		function, params := getFunctionAndParams(stub)
 		res, err := handler.cc.Invoke(stub, function, params)
 
@@ -338,7 +338,7 @@ func (handler *Handler) handleQuery(msg *pb.ChaincodeMessage) {
 		// Call chaincode's Query
 		// Create the ChaincodeStub which the chaincode can use to callback
 		stub := new(ChaincodeStub)
-		stub.init(msg.Txid, msg.SecurityContext)
+		stub.init(msg.Txid, msg.SecurityContext, msg.TxSetContext)
 		function, params := getFunctionAndParams(stub)
 		res, err := handler.cc.Query(stub, function, params)
 
@@ -580,6 +580,56 @@ func (handler *Handler) handleDelState(key string, txid string) error {
 	return errors.New("Incorrect chaincode message received")
 }
 
+func (handler *Handler) handleMutateTxSet(txSetID string, index uint64, txid string) error {
+	// Check if this is a transaction
+	if !handler.isTransaction[txid] {
+		return errors.New("Cannot mutate a transactions set in query context")
+	}
+
+	// Create the channel on which to communicate the response from validating peer
+	respChan, uniqueReqErr := handler.createChannel(txid)
+	if uniqueReqErr != nil {
+		chaincodeLogger.Errorf("[%s]Another state request pending for this Txid. Cannot process create createChannel.", shorttxid(txid))
+		return uniqueReqErr
+	}
+
+	defer handler.deleteChannel(txid)
+
+	// Send MUTATE_TX_SET message to validator chaincode support
+	payload, err := proto.Marshal(&pb.TxSetMutation{TxSetID: txSetID, TxSetIndex: index})
+	if err != nil {
+		return errors.New("Failed to process mutate tx set request")
+	}
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_MUTATE_TX_SET, Payload: payload, Txid: txid}
+	chaincodeLogger.Debugf("[%s]Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_MUTATE_TX_SET)
+	if err := handler.serialSend(msg); err != nil {
+		chaincodeLogger.Errorf("[%s]error sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_MUTATE_TX_SET)
+		return errors.New("could not send msg")
+	}
+
+	// Wait on responseChannel for response
+	responseMsg, ok := handler.receiveChannel(respChan)
+	if !ok {
+		chaincodeLogger.Errorf("[%s]Received unexpected message type", shorttxid(msg.Txid))
+		return errors.New("Received unexpected message type")
+	}
+
+	if responseMsg.Type.String() == pb.ChaincodeMessage_RESPONSE.String() {
+		// Success response
+		chaincodeLogger.Debugf("[%s]Received %s. Successfully requested the mutation", msg.Txid, pb.ChaincodeMessage_RESPONSE)
+		return nil
+	}
+	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
+		// Error response
+		chaincodeLogger.Errorf("[%s]Received %s. Payload: %s", msg.Txid, pb.ChaincodeMessage_ERROR, responseMsg.Payload)
+		return errors.New(string(responseMsg.Payload[:]))
+	}
+
+	// Incorrect chaincode message received
+	chaincodeLogger.Errorf("[%s]Incorrect chaincode message %s received. Expecting %s or %s", shorttxid(responseMsg.Txid), responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
+	return errors.New("Incorrect chaincode message received")
+}
+
 func (handler *Handler) handleRangeQueryState(startKey, endKey string, txid string) (*pb.RangeQueryStateResponse, error) {
 	// Create the channel on which to communicate the response from validating peer
 	respChan, uniqueReqErr := handler.createChannel(txid)
@@ -49,6 +49,17 @@ type ChaincodeStubInterface interface {
 	// Get the transaction ID
 	GetTxID() string
 
+	// GetTxSetID returns the ID of the transactions set whose default transaction
+	// is being executed
+	GetTxSetID() string
+
+	// GetTxSetIndex returns the index in its set of the transaction being executed
+	GetTxSetIndex() uint64
+
+	// IsReplay returns whether the transaction is being re-executed because a
+	// mutation changed the default transaction of a set of a previous block
+	IsReplay() bool
+
 	// InvokeChaincode locally calls the specified chaincode `Invoke` using the
 	// same transaction context; that is, chaincode calling chaincode doesn't
 	// create a new transaction message.
@@ -68,6 +79,13 @@ type ChaincodeStubInterface interface {
 	// DelState removes the specified `key` and its value from the ledger.
 	DelState(key string) error
 
+	// MutateTxSet requests that the transaction at `index` becomes the default
+	// of the transactions set `txSetID`. If the calling transaction succeeds, the
+	// mutation is queued under the identity of the chaincode and executed at the
+	// start of the next block, where it is subject to the mutation policy of the
+	// set. The requests made while the transaction is replayed are ignored.
+	MutateTxSet(txSetID string, index uint64) error
+
 	// RangeQueryState function can be invoked by a chaincode to query of a range
 	// of keys in the state. Assuming the startKey and endKey are in lexical
 	// an iterator will be returned that can be used to iterate over all keys
@@ -0,0 +1,311 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shim
+
+import (
+	"container/list"
+	"fmt"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// MockTx is a transaction of a mocked transactions set: the invocation of the
+// chaincode of the MockStub with Function and Args.
+type MockTx struct {
+	Function string
+	Args     []string
+}
+
+type mockTxSetOpType int
+
+const (
+	mockTxSetIssue mockTxSetOpType = iota
+	mockTxSetExtend
+	mockTxSetMutate
+)
+
+// MockTxSetOp is an operation on a transactions set made part of a block by
+// MockTxSetLedger.MockBlock. Use IssueTxSet, ExtendTxSet and MutateTxSet to
+// create them.
+type MockTxSetOp struct {
+	opType  mockTxSetOpType
+	txSetID string
+	index   uint64
+	txs     []MockTx
+}
+
+// IssueTxSet introduces a new set made of txs whose default is the transaction
+// at defaultInx. The default is executed in the block that introduces the set.
+func IssueTxSet(txSetID string, defaultInx uint64, txs ...MockTx) MockTxSetOp {
+	return MockTxSetOp{opType: mockTxSetIssue, txSetID: txSetID, index: defaultInx, txs: txs}
+}
+
+// ExtendTxSet adds txs to an existing set. Extensions are not executed.
+func ExtendTxSet(txSetID string, txs ...MockTx) MockTxSetOp {
+	return MockTxSetOp{opType: mockTxSetExtend, txSetID: txSetID, txs: txs}
+}
+
+// MutateTxSet makes the transaction at index the default of an existing set.
+func MutateTxSet(txSetID string, index uint64) MockTxSetOp {
+	return MockTxSetOp{opType: mockTxSetMutate, txSetID: txSetID, index: index}
+}
+
+type mockTxSet struct {
+	txs        []MockTx
+	index      uint64
+	introBlock int
+}
+
+type mockStubState struct {
+	state map[string][]byte
+	keys  []string
+}
+
+// MockTxSetLedger drives a MockStub through ordered blocks of transactions sets
+// the way the peer does. Mutations are applied before the other operations of
+// their block: the state of the chaincodes is reset to the beginning of the
+// oldest block that introduced a mutated set, then the blocks from there are
+// replayed executing the current default of each set, with IsReplay returning
+// true. A transaction that fails has its changes discarded.
+// The state of the chaincodes peered with the MockStub is reset and replayed
+// as well. The mutations requested by the chaincodes through MutateTxSet are
+// queued if their transaction succeeds and applied first in the next block,
+// the invalid ones are dropped.
+type MockTxSetLedger struct {
+	stub *MockStub
+
+	// IDs of the sets introduced by each block, in block order
+	blocks [][]string
+
+	// state of the chaincodes at the beginning of each block
+	snapshots []map[*MockStub]*mockStubState
+
+	txSets map[string]*mockTxSet
+
+	// result of the last execution of the default of each set
+	results map[string][]byte
+	errors  map[string]error
+
+	// mutations requested by the chaincodes in the last block
+	queued []*pb.TxSetMutation
+}
+
+// NewMockTxSetLedger creates a ledger driving stub, which should already be
+// initialised with MockInit.
+func NewMockTxSetLedger(stub *MockStub) *MockTxSetLedger {
+	return &MockTxSetLedger{
+		stub:    stub,
+		txSets:  make(map[string]*mockTxSet),
+		results: make(map[string][]byte),
+		errors:  make(map[string]error)}
+}
+
+// GetBlockchainSize returns the number of blocks made part of the ledger
+func (ledger *MockTxSetLedger) GetBlockchainSize() int {
+	return len(ledger.blocks)
+}
+
+// GetTxSetIndex returns the index of the current default of the set
+func (ledger *MockTxSetLedger) GetTxSetIndex(txSetID string) (uint64, error) {
+	txSet, ok := ledger.txSets[txSetID]
+	if !ok {
+		return 0, fmt.Errorf("The tx set with ID: %s does not exist.", txSetID)
+	}
+	return txSet.index, nil
+}
+
+// GetTxSetResult returns what the last execution of the default of the set returned
+func (ledger *MockTxSetLedger) GetTxSetResult(txSetID string) ([]byte, error) {
+	return ledger.results[txSetID], ledger.errors[txSetID]
+}
+
+// MockBlock makes ops part of a new block. The mutations are checked against
+// the sets of the previous blocks and applied first, then the sets are issued
+// and extended in order. No block is added if an operation is invalid.
+func (ledger *MockTxSetLedger) MockBlock(ops ...MockTxSetOp) error {
+	mutations := make(map[string]uint64)
+	for _, mutation := range ledger.queued {
+		if err := ledger.checkMutation(mutation.TxSetID, mutation.TxSetIndex, mutations); err != nil {
+			mockLogger.Debug("MockTxSetLedger", ledger.stub.Name, "Dropping a queued mutation:", err)
+			continue
+		}
+		mutations[mutation.TxSetID] = mutation.TxSetIndex
+	}
+	introduced := make(map[string]bool)
+	for _, op := range ops {
+		_, exists := ledger.txSets[op.txSetID]
+		switch op.opType {
+		case mockTxSetIssue:
+			if exists || introduced[op.txSetID] {
+				return fmt.Errorf("The tx set with ID: %s was already issued.", op.txSetID)
+			}
+			if op.index >= uint64(len(op.txs)) {
+				return fmt.Errorf("The default index %d is out of the bounds of the tx set with ID: %s.", op.index, op.txSetID)
+			}
+			introduced[op.txSetID] = true
+		case mockTxSetExtend:
+			if !exists && !introduced[op.txSetID] {
+				return fmt.Errorf("The tx set with ID: %s does not exist.", op.txSetID)
+			}
+		case mockTxSetMutate:
+			if err := ledger.checkMutation(op.txSetID, op.index, mutations); err != nil {
+				return err
+			}
+			mutations[op.txSetID] = op.index
+		}
+	}
+
+	replayFrom := len(ledger.blocks)
+	for txSetID, index := range mutations {
+		txSet := ledger.txSets[txSetID]
+		txSet.index = index
+		if txSet.introBlock < replayFrom {
+			replayFrom = txSet.introBlock
+		}
+	}
+	if replayFrom < len(ledger.blocks) {
+		mockLogger.Debug("MockTxSetLedger", ledger.stub.Name, "Replaying from block", replayFrom)
+		ledger.restore(ledger.snapshots[replayFrom])
+		for blockNr := replayFrom; blockNr < len(ledger.blocks); blockNr++ {
+			ledger.snapshots[blockNr] = ledger.snapshot()
+			for _, txSetID := range ledger.blocks[blockNr] {
+				ledger.execute(txSetID, true)
+			}
+		}
+	}
+
+	ledger.snapshots = append(ledger.snapshots, ledger.snapshot())
+	ledger.queued = nil
+	var block []string
+	for _, op := range ops {
+		switch op.opType {
+		case mockTxSetIssue:
+			ledger.txSets[op.txSetID] = &mockTxSet{txs: op.txs, index: op.index, introBlock: len(ledger.blocks)}
+			block = append(block, op.txSetID)
+			ledger.execute(op.txSetID, false)
+		case mockTxSetExtend:
+			txSet := ledger.txSets[op.txSetID]
+			txSet.txs = append(txSet.txs, op.txs...)
+		}
+	}
+	ledger.blocks = append(ledger.blocks, block)
+	return nil
+}
+
+// checkMutation verifies that the set can be mutated to index, given the mutations already in the block
+func (ledger *MockTxSetLedger) checkMutation(txSetID string, index uint64, mutations map[string]uint64) error {
+	txSet, exists := ledger.txSets[txSetID]
+	if !exists {
+		return fmt.Errorf("The tx set with ID: %s does not exist.", txSetID)
+	}
+	if _, ok := mutations[txSetID]; ok {
+		return fmt.Errorf("The tx set with ID: %s is mutated more than once in the block.", txSetID)
+	}
+	if index >= uint64(len(txSet.txs)) {
+		return fmt.Errorf("The index %d is out of the bounds of the tx set with ID: %s.", index, txSetID)
+	}
+	if index == txSet.index {
+		return fmt.Errorf("The tx set with ID: %s already has the index %d.", txSetID, index)
+	}
+	return nil
+}
+
+// ForEachTxSetIndex makes each transaction of the set its default in turn, mutating
+// the set in a new block when needed, and calls check with the resulting state of
+// the MockStub. It stops at the first error returned by check.
+func (ledger *MockTxSetLedger) ForEachTxSetIndex(txSetID string, check func(index uint64, stub *MockStub) error) error {
+	txSet, ok := ledger.txSets[txSetID]
+	if !ok {
+		return fmt.Errorf("The tx set with ID: %s does not exist.", txSetID)
+	}
+	for index := uint64(0); index < uint64(len(txSet.txs)); index++ {
+		if index != txSet.index {
+			if err := ledger.MockBlock(MutateTxSet(txSetID, index)); err != nil {
+				return err
+			}
+		}
+		if err := check(index, ledger.stub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execute invokes the current default of the set, discarding its changes if it fails
+func (ledger *MockTxSetLedger) execute(txSetID string, replay bool) {
+	txSet := ledger.txSets[txSetID]
+	tx := txSet.txs[txSet.index]
+	before := ledger.snapshot()
+	ledger.stub.MockTxSetContext(txSetID, txSet.index, replay)
+	result, err := ledger.stub.MockInvoke(txSetID, tx.Function, tx.Args)
+	ledger.stub.MockTxSetContext("", 0, false)
+	for _, stub := range ledger.stubs() {
+		if err == nil {
+			ledger.queued = append(ledger.queued, stub.Mutations...)
+		}
+		stub.Mutations = nil
+	}
+	if err != nil {
+		mockLogger.Debug("MockTxSetLedger", ledger.stub.Name, "Tx set", txSetID, "failed at index", txSet.index, err)
+		ledger.restore(before)
+	}
+	ledger.results[txSetID] = result
+	ledger.errors[txSetID] = err
+}
+
+// stubs returns the MockStub and the ones it can invoke, directly or not
+func (ledger *MockTxSetLedger) stubs() []*MockStub {
+	visited := map[*MockStub]bool{ledger.stub: true}
+	stubs := []*MockStub{ledger.stub}
+	for i := 0; i < len(stubs); i++ {
+		for _, peer := range stubs[i].Invokables {
+			if peer != nil && !visited[peer] {
+				visited[peer] = true
+				stubs = append(stubs, peer)
+			}
+		}
+	}
+	return stubs
+}
+
+func (ledger *MockTxSetLedger) snapshot() map[*MockStub]*mockStubState {
+	snapshot := make(map[*MockStub]*mockStubState)
+	for _, stub := range ledger.stubs() {
+		stubState := &mockStubState{state: make(map[string][]byte, len(stub.State))}
+		for key, value := range stub.State {
+			stubState.state[key] = value
+		}
+		for elem := stub.Keys.Front(); elem != nil; elem = elem.Next() {
+			stubState.keys = append(stubState.keys, elem.Value.(string))
+		}
+		snapshot[stub] = stubState
+	}
+	return snapshot
+}
+
+func (ledger *MockTxSetLedger) restore(snapshot map[*MockStub]*mockStubState) {
+	for stub, stubState := range snapshot {
+		stub.State = make(map[string][]byte, len(stubState.state))
+		for key, value := range stubState.state {
+			stub.State[key] = value
+		}
+		stub.Keys = list.New()
+		for _, key := range stubState.keys {
+			stub.Keys.PushBack(key)
+		}
+	}
+}
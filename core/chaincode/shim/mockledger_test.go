@@ -0,0 +1,181 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shim
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+// counterChaincode keeps integer counters: "set key value" and "add key value"
+// update the counter key, "replays" counts the executions seen as replays.
+// "mutate txSetID index" requests the mutation of a set.
+type counterChaincode struct {
+}
+
+func (t *counterChaincode) Init(stub ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	return nil, nil
+}
+
+func (t *counterChaincode) Invoke(stub ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	if stub.IsReplay() {
+		if err := t.add(stub, "replays", 1); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) != 2 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 2")
+	}
+	value, err := strconv.Atoi(args[1])
+	if err != nil {
+		return nil, err
+	}
+	switch function {
+	case "set":
+		return nil, stub.PutState(args[0], []byte(strconv.Itoa(value)))
+	case "add":
+		return nil, t.add(stub, args[0], value)
+	case "mutate":
+		return nil, stub.MutateTxSet(args[0], uint64(value))
+	}
+	return nil, fmt.Errorf("Unknown function %s", function)
+}
+
+func (t *counterChaincode) Query(stub ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	return nil, nil
+}
+
+func (t *counterChaincode) add(stub ChaincodeStubInterface, key string, value int) error {
+	current, err := stub.GetState(key)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return stub.PutState(key, []byte(strconv.Itoa(value)))
+	}
+	counter, err := strconv.Atoi(string(current))
+	if err != nil {
+		return err
+	}
+	return stub.PutState(key, []byte(strconv.Itoa(counter+value)))
+}
+
+func checkCounter(t *testing.T, stub *MockStub, key string, expected string) {
+	if value := string(stub.State[key]); value != expected {
+		t.Fatalf("Expected [%s] for counter %s, got [%s]", expected, key, value)
+	}
+}
+
+func TestMockTxSetLedgerMutation(t *testing.T) {
+	stub := NewMockStub("txSetTest", new(counterChaincode))
+	ledger := NewMockTxSetLedger(stub)
+
+	if err := ledger.MockBlock(IssueTxSet("set1", 0, MockTx{"set", []string{"a", "1"}}, MockTx{"set", []string{"a", "10"}})); err != nil {
+		t.Fatalf("Unable to issue set1: %s", err)
+	}
+	if err := ledger.MockBlock(IssueTxSet("set2", 0, MockTx{"add", []string{"a", "5"}})); err != nil {
+		t.Fatalf("Unable to issue set2: %s", err)
+	}
+	checkCounter(t, stub, "a", "6")
+
+	if err := ledger.MockBlock(MutateTxSet("set1", 1)); err != nil {
+		t.Fatalf("Unable to mutate set1: %s", err)
+	}
+	// set1 and set2 are replayed with the new default of set1
+	checkCounter(t, stub, "a", "15")
+	checkCounter(t, stub, "replays", "2")
+	if index, _ := ledger.GetTxSetIndex("set1"); index != 1 {
+		t.Fatalf("Expected index 1 for set1, got %d", index)
+	}
+
+	if err := ledger.MockBlock(MutateTxSet("set1", 1)); err == nil {
+		t.Fatalf("Mutating a set to its current index should fail")
+	}
+	if err := ledger.MockBlock(MutateTxSet("set3", 0)); err == nil {
+		t.Fatalf("Mutating an unknown set should fail")
+	}
+	if ledger.GetBlockchainSize() != 3 {
+		t.Fatalf("Expected 3 blocks, got %d", ledger.GetBlockchainSize())
+	}
+}
+
+func TestMockTxSetLedgerFailedTx(t *testing.T) {
+	stub := NewMockStub("txSetTest", new(counterChaincode))
+	ledger := NewMockTxSetLedger(stub)
+
+	if err := ledger.MockBlock(IssueTxSet("set1", 0, MockTx{"set", []string{"a", "x"}}, MockTx{"set", []string{"a", "2"}}),
+		IssueTxSet("set2", 0, MockTx{"add", []string{"a", "3"}})); err != nil {
+		t.Fatalf("Unable to issue the sets: %s", err)
+	}
+	if _, err := ledger.GetTxSetResult("set1"); err == nil {
+		t.Fatalf("The default of set1 should have failed")
+	}
+	checkCounter(t, stub, "a", "3")
+
+	if err := ledger.MockBlock(ExtendTxSet("set2", MockTx{"add", []string{"a", "4"}})); err != nil {
+		t.Fatalf("Unable to extend set2: %s", err)
+	}
+	// extensions are not executed
+	checkCounter(t, stub, "a", "3")
+
+	expected := map[uint64]string{0: "5", 1: "6"}
+	if err := ledger.MockBlock(MutateTxSet("set1", 1)); err != nil {
+		t.Fatalf("Unable to mutate set1: %s", err)
+	}
+	err := ledger.ForEachTxSetIndex("set2", func(index uint64, stub *MockStub) error {
+		if value := string(stub.State["a"]); value != expected[index] {
+			return fmt.Errorf("Expected [%s] at index %d, got [%s]", expected[index], index, value)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMockTxSetLedgerChaincodeMutation(t *testing.T) {
+	stub := NewMockStub("txSetTest", new(counterChaincode))
+	ledger := NewMockTxSetLedger(stub)
+
+	if err := ledger.MockBlock(IssueTxSet("set1", 0, MockTx{"set", []string{"a", "1"}}, MockTx{"set", []string{"a", "10"}})); err != nil {
+		t.Fatalf("Unable to issue set1: %s", err)
+	}
+	if err := ledger.MockBlock(IssueTxSet("set2", 0, MockTx{"mutate", []string{"set1", "1"}})); err != nil {
+		t.Fatalf("Unable to issue set2: %s", err)
+	}
+	// The mutation is only queued by the block of set2
+	if index, _ := ledger.GetTxSetIndex("set1"); index != 0 {
+		t.Fatalf("Expected index 0 for set1, got %d", index)
+	}
+	checkCounter(t, stub, "a", "1")
+
+	if err := ledger.MockBlock(); err != nil {
+		t.Fatalf("Unable to add an empty block: %s", err)
+	}
+	if index, _ := ledger.GetTxSetIndex("set1"); index != 1 {
+		t.Fatalf("Expected index 1 for set1, got %d", index)
+	}
+	checkCounter(t, stub, "a", "10")
+	checkCounter(t, stub, "replays", "2")
+
+	// The replay of set2 did not request the mutation again
+	if len(ledger.queued) != 0 {
+		t.Fatalf("No mutation should be queued after a replay, found %d", len(ledger.queued))
+	}
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/core/chaincode/shim/crypto/attr"
+	pb "github.com/hyperledger/fabric/protos"
 	"github.com/op/go-logging"
 )
 
@@ -56,12 +57,40 @@ type MockStub struct {
 	// stores a transaction uuid while being Invoked / Deployed
 	// TODO if a chaincode uses recursion this may need to be a stack of TxIDs or possibly a reference counting map
 	TxID string
+
+	// transactions set context of the mocked transaction, see MockTxSetContext
+	TxSetID    string
+	TxSetIndex uint64
+	Replay     bool
+
+	// mutations requested through MutateTxSet, applied by MockTxSetLedger
+	Mutations []*pb.TxSetMutation
 }
 
 func (stub *MockStub) GetTxID() string {
 	return stub.TxID
 }
 
+func (stub *MockStub) GetTxSetID() string {
+	return stub.TxSetID
+}
+
+func (stub *MockStub) GetTxSetIndex() uint64 {
+	return stub.TxSetIndex
+}
+
+func (stub *MockStub) IsReplay() bool {
+	return stub.Replay
+}
+
+// Set the transactions set context seen by the chaincode in the next calls to
+// MockInit, MockInvoke or MockQuery.
+func (stub *MockStub) MockTxSetContext(txSetID string, txSetIndex uint64, replay bool) {
+	stub.TxSetID = txSetID
+	stub.TxSetIndex = txSetIndex
+	stub.Replay = replay
+}
+
 func (stub *MockStub) GetArgs() [][]byte {
 	return stub.args
 }
@@ -170,6 +199,17 @@ func (stub *MockStub) PutState(key string, value []byte) error {
 	return nil
 }
 
+// MutateTxSet records the requested mutation in Mutations, unless the
+// transaction is replayed. MockTxSetLedger queues them for the next block.
+func (stub *MockStub) MutateTxSet(txSetID string, index uint64) error {
+	if stub.Replay {
+		mockLogger.Debug("MockStub", stub.Name, "Ignoring the mutation of", txSetID, "during a replay")
+		return nil
+	}
+	stub.Mutations = append(stub.Mutations, &pb.TxSetMutation{TxSetID: txSetID, TxSetIndex: index})
+	return nil
+}
+
 // DelState removes the specified `key` and its value from the ledger.
 func (stub *MockStub) DelState(key string) error {
 	mockLogger.Debug("MockStub", stub.Name, "Deleting", key, stub.State[key])
@@ -239,7 +279,11 @@ func (stub *MockStub) InvokeChaincode(chaincodeName string, args [][]byte) ([]by
 	otherStub := stub.Invokables[chaincodeName]
 	mockLogger.Debug("MockStub", stub.Name, "Invoking peer chaincode", otherStub.Name, args)
 	//	function, strings := getFuncArgs(args)
+	// the invoked chaincode runs in the transactions set context of the caller
+	txSetID, txSetIndex, replay := otherStub.TxSetID, otherStub.TxSetIndex, otherStub.Replay
+	otherStub.MockTxSetContext(stub.TxSetID, stub.TxSetIndex, stub.Replay)
 	bytes, err := otherStub.MockInvoke(stub.TxID, function, params)
+	otherStub.MockTxSetContext(txSetID, txSetIndex, replay)
 	mockLogger.Debug("MockStub", stub.Name, "Invoked peer chaincode", otherStub.Name, "got", bytes, err)
 	return bytes, err
 }
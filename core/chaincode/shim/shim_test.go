@@ -20,6 +20,7 @@ import (
 	"os"
 	"testing"
 
+	pb "github.com/hyperledger/fabric/protos"
 	"github.com/op/go-logging"
 )
 
@@ -134,3 +135,26 @@ func TestChaincodeLogging(t *testing.T) {
 		t.Errorf("'bar' should be enabled for LogCritical")
 	}
 }
+
+// TestChaincodeStubTxSetContext tests that the stub exposes the transactions
+// set context sent by the peer, and the defaults when there is none.
+func TestChaincodeStubTxSetContext(t *testing.T) {
+	stub := new(ChaincodeStub)
+	stub.init("tx1", &pb.ChaincodeSecurityContext{}, nil)
+	if stub.GetTxSetID() != "" || stub.GetTxSetIndex() != 0 || stub.IsReplay() {
+		t.Errorf("Expected an empty tx set context, got ID [%s], index [%d], replay [%t]",
+			stub.GetTxSetID(), stub.GetTxSetIndex(), stub.IsReplay())
+	}
+
+	stub = new(ChaincodeStub)
+	stub.init("tx2", &pb.ChaincodeSecurityContext{}, &pb.ChaincodeTxSetContext{TxSetID: "set1", TxSetIndex: 2, Replay: true})
+	if stub.GetTxSetID() != "set1" {
+		t.Errorf("Expected tx set ID [set1], got [%s]", stub.GetTxSetID())
+	}
+	if stub.GetTxSetIndex() != 2 {
+		t.Errorf("Expected tx set index [2], got [%d]", stub.GetTxSetIndex())
+	}
+	if !stub.IsReplay() {
+		t.Errorf("Expected the execution to be a replay")
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/crypto/utils"
+	membersrvc "github.com/hyperledger/fabric/membersrvc/protos"
+	"golang.org/x/net/context"
+)
+
+// RevokeEnrollmentCertificate asks the ECA to revoke the enrollment certificate pair cert belongs to.
+// If cert is nil or is the enrollment certificate of this client, the client revokes its own pair,
+// otherwise the client must be a registrar for the role of the owner of cert.
+func (client *clientImpl) RevokeEnrollmentCertificate(cert []byte) error {
+	// Verify that the client is initialized
+	if !client.IsInitialized() {
+		return utils.ErrNotInitialized
+	}
+
+	own := cert == nil || bytes.Equal(cert, client.enrollCert.Raw)
+	if cert == nil {
+		cert = client.enrollCert.Raw
+	}
+
+	req := &membersrvc.ECertRevokeReq{
+		Id:   &membersrvc.Identity{Id: client.enrollID},
+		Cert: &membersrvc.Cert{Cert: cert},
+	}
+	sig, err := client.signRequest(req)
+	if err != nil {
+		return err
+	}
+	req.Sig = sig
+
+	// Get an ECA Client
+	sock, ecaP, err := client.getECAClient()
+	if err != nil {
+		return err
+	}
+	defer sock.Close()
+
+	var status *membersrvc.CAStatus
+	if own {
+		status, err = ecaP.RevokeCertificatePair(context.Background(), req)
+	} else {
+		status, err = membersrvc.NewECAAClient(sock).RevokeCertificate(context.Background(), req)
+	}
+	if err != nil {
+		client.Errorf("Failed requesting eca revoke certificate [%s].", err.Error())
+
+		return err
+	}
+	if status.Status != membersrvc.CAStatus_OK {
+		return errors.New("Failed revoking enrollment certificate.")
+	}
+
+	return nil
+}
+
+// RevokeTCertificate asks the TCA to revoke the transaction certificate cert. Unless cert belongs to
+// this client, the client must be a registrar for the role of its owner.
+func (client *clientImpl) RevokeTCertificate(cert []byte) error {
+	// Verify that the client is initialized
+	if !client.IsInitialized() {
+		return utils.ErrNotInitialized
+	}
+
+	// Validate the transaction certificate, its signing key can only be derived by its owner
+	tCert, err := client.getTCertFromExternalDER(cert)
+	if err != nil {
+		client.Warningf("Failed validating transaction certificate [%s].", err)
+
+		return err
+	}
+	own := tCert.(*tCertImpl).sk != nil
+
+	req := &membersrvc.TCertRevokeReq{
+		Id:   &membersrvc.Identity{Id: client.enrollID},
+		Cert: &membersrvc.Cert{Cert: cert},
+	}
+	sig, err := client.signRequest(req)
+	if err != nil {
+		return err
+	}
+	req.Sig = sig
+
+	// Get a TCA Client
+	sock, tcaP, err := client.getTCAClient()
+	if err != nil {
+		return err
+	}
+	defer sock.Close()
+
+	var status *membersrvc.CAStatus
+	if own {
+		status, err = tcaP.RevokeCertificate(context.Background(), req)
+	} else {
+		status, err = membersrvc.NewTCAAClient(sock).RevokeCertificate(context.Background(), req)
+	}
+	if err != nil {
+		client.Errorf("Failed requesting tca revoke certificate [%s].", err.Error())
+
+		return err
+	}
+	if status.Status != membersrvc.CAStatus_OK {
+		return errors.New("Failed revoking transaction certificate.")
+	}
+
+	return nil
+}
+
+// signRequest signs a request to the membership services, whose signature field must be unset,
+// with the enrollment key
+func (client *clientImpl) signRequest(req proto.Message) (*membersrvc.Signature, error) {
+	rawReq, err := proto.Marshal(req)
+	if err != nil {
+		client.Errorf("Failed marshaling request [%s].", err.Error())
+		return nil, err
+	}
+
+	r, s, err := client.ecdsaSignWithEnrollmentKey(rawReq)
+	if err != nil {
+		client.Errorf("Failed creating signature for [% x]: [%s].", rawReq, err.Error())
+		return nil, err
+	}
+
+	R, _ := r.MarshalText()
+	S, _ := s.MarshalText()
+
+	return &membersrvc.Signature{Type: membersrvc.CryptoType_ECDSA, R: R, S: S}, nil
+}
@@ -74,6 +74,12 @@ type Client interface {
 
 	// GetChainPublicKey returns the public key of the chain at which this user is logged in
 	GetChainPublicKey(attributes ...string) ([]byte, error)
+
+	// RevokeEnrollmentCertificate revokes the enrollment certificate pair cert belongs to, the client's own if cert is nil
+	RevokeEnrollmentCertificate(cert []byte) error
+
+	// RevokeTCertificate revokes the transaction certificate passed
+	RevokeTCertificate(tCertDER []byte) error
 }
 
 // Peer is an entity able to verify transactions
@@ -110,6 +116,15 @@ type Peer interface {
 	// If vkID is nil, then the signature is verified against this validator's verification key.
 	Verify(vkID, signature, message []byte) error
 
+	// VerifyCertificateSignature checks that cert has been issued by a trusted authority and that
+	// signature is a valid signature of message under cert's verification key.
+	VerifyCertificateSignature(cert, signature, message []byte) error
+
+	// CheckCertificateRevocation returns an error if cert has been revoked by
+	// the authority which issued it. Like TransactionPreValidation, it must
+	// only be used before transactions are ordered.
+	CheckCertificateRevocation(cert []byte) error
+
 	// GetStateEncryptor returns a StateEncryptor linked to pair defined by
 	// the deploy transaction and the execute transaction. Notice that,
 	// executeTx can also correspond to a deploy transaction.
@@ -290,6 +290,22 @@ func (node *nodeImpl) callECAReadCACertificate(ctx context.Context, opts ...grpc
 	return cert, nil
 }
 
+func (node *nodeImpl) callECAReadCRL(ctx context.Context, opts ...grpc.CallOption) (*membersrvc.CRL, error) {
+	// Get an ECA Client
+	sock, ecaP, err := node.getECAClient()
+	defer sock.Close()
+
+	// Issue the request
+	crl, err := ecaP.ReadCRL(ctx, &membersrvc.Empty{}, opts...)
+	if err != nil {
+		node.Errorf("Failed requesting eca read crl [%s].", err.Error())
+
+		return nil, err
+	}
+
+	return crl, nil
+}
+
 func (node *nodeImpl) callECAReadCertificate(ctx context.Context, in *membersrvc.ECertReadReq, opts ...grpc.CallOption) (*membersrvc.CertPair, error) {
 	// Get an ECA Client
 	sock, ecaP, err := node.getECAClient()
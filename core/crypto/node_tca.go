@@ -112,6 +112,22 @@ func (node *nodeImpl) callTCAReadCACertificate(ctx context.Context, opts ...grpc
 	return cert, nil
 }
 
+func (node *nodeImpl) callTCAReadCRL(ctx context.Context, opts ...grpc.CallOption) (*membersrvc.CRL, error) {
+	// Get a TCA Client
+	sock, tcaP, err := node.getTCAClient()
+	defer sock.Close()
+
+	// Issue the request
+	crl, err := tcaP.ReadCRL(ctx, &membersrvc.Empty{}, opts...)
+	if err != nil {
+		node.Errorf("Failed requesting tca read crl [%s].", err.Error())
+
+		return nil, err
+	}
+
+	return crl, nil
+}
+
 func (node *nodeImpl) getTCACertificate() ([]byte, error) {
 	response, err := node.callTCAReadCACertificate(context.Background())
 	if err != nil {
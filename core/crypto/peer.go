@@ -129,7 +129,7 @@ func CloseAllPeers() (bool, []error) {
 // Private Methods
 
 func newPeer() *peerImpl {
-	return &peerImpl{&nodeImpl{}, sync.RWMutex{}, nil}
+	return &peerImpl{&nodeImpl{}, sync.RWMutex{}, nil, sync.Mutex{}, nil, nil}
 }
 
 func closePeerInternal(peer Peer, force bool) error {
@@ -0,0 +1,230 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	"github.com/hyperledger/fabric/core/crypto/utils"
+	membersrvc "github.com/hyperledger/fabric/membersrvc/protos"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+)
+
+// Peers refuse transactions signed by certificates revoked by the ECA or the
+// TCA. This only happens when transactions are pre-validated: the CRL a peer
+// holds depends on when it last fetched it, so revocation must not influence
+// the execution of transactions which have already been ordered.
+
+const (
+	crlIssuerECA = "eca"
+	crlIssuerTCA = "tca"
+)
+
+// crlEntry is the cached view of the last CRL fetched from a CA
+type crlEntry struct {
+	revoked map[string]bool
+	fetched time.Time
+	expires time.Time
+}
+
+// crlFetch is a fetch of the CRL of a CA in progress, concurrent readers of
+// the same CRL wait for it instead of fetching the CRL again
+type crlFetch struct {
+	done  chan struct{}
+	entry *crlEntry
+}
+
+func crlVerificationEnabled() bool {
+	if viper.IsSet("security.crl.enabled") {
+		return viper.GetBool("security.crl.enabled")
+	}
+
+	// CRL verification is enabled by default if no configuration was specified.
+	return true
+}
+
+func crlRefreshInterval() time.Duration {
+	if refresh := viper.GetDuration("security.crl.refresh"); refresh > 0 {
+		return refresh
+	}
+	return time.Minute
+}
+
+// CheckCertificateRevocation returns an error if cert, issued by the ECA or
+// the TCA, has been revoked by its issuer
+func (peer *peerImpl) CheckCertificateRevocation(cert []byte) error {
+	if !peer.IsInitialized() {
+		return utils.ErrNotInitialized
+	}
+
+	x509Cert, err := primitives.DERToX509Certificate(cert)
+	if err != nil {
+		peer.Debugf("Failed parsing certificate [% x]: [%s].", cert, err)
+
+		return err
+	}
+
+	// Get rid of the extensions that cannot be checked now
+	x509Cert.UnhandledCriticalExtensions = nil
+	issuer, err := peer.certificateIssuer(x509Cert)
+	if err != nil {
+		return err
+	}
+
+	return peer.checkRevocation(x509Cert, issuer)
+}
+
+// certificateIssuer returns the CA, the TCA or the ECA, which issued cert
+func (peer *peerImpl) certificateIssuer(cert *x509.Certificate) (string, error) {
+	if _, err := primitives.CheckCertAgainRoot(cert, peer.tcaCertPool); err != nil {
+		peer.Warningf("Failed verifing certificate against TCA cert pool [%s].", err.Error())
+		if _, err = primitives.CheckCertAgainRoot(cert, peer.ecaCertPool); err != nil {
+			peer.Warningf("Failed verifing certificate against ECA cert pool [%s].", err.Error())
+
+			return "", fmt.Errorf("Certificate has not been signed by a trusted authority. [%s]", err)
+		}
+		return crlIssuerECA, nil
+	}
+	return crlIssuerTCA, nil
+}
+
+// checkRevocation returns an error if cert, issued by the given CA, has been
+// revoked
+func (peer *peerImpl) checkRevocation(cert *x509.Certificate, issuer string) error {
+	if !crlVerificationEnabled() {
+		return nil
+	}
+
+	crl := peer.getCRL(issuer)
+	if crl.revoked[cert.SerialNumber.String()] {
+		peer.Warningf("Certificate with serial number [%s] has been revoked by the %s.", cert.SerialNumber, issuer)
+
+		return errors.New("Certificate has been revoked.")
+	}
+
+	return nil
+}
+
+// getCRL returns the cached CRL of the given CA, refreshing it when it is due.
+// If the CRL cannot be fetched, the last known one keeps being used, or none
+// at all if there is no such CRL, until the next attempt. The CRL is fetched
+// without holding crlsMutex, and only once at a time for each CA.
+func (peer *peerImpl) getCRL(issuer string) *crlEntry {
+	peer.crlsMutex.Lock()
+	crl := peer.crls[issuer]
+	if crl != nil && time.Now().Before(crl.expires) {
+		peer.crlsMutex.Unlock()
+		return crl
+	}
+	if fetch, ok := peer.crlFetches[issuer]; ok {
+		peer.crlsMutex.Unlock()
+		<-fetch.done
+		return fetch.entry
+	}
+	fetch := &crlFetch{done: make(chan struct{})}
+	if peer.crlFetches == nil {
+		peer.crlFetches = make(map[string]*crlFetch)
+	}
+	peer.crlFetches[issuer] = fetch
+	peer.crlsMutex.Unlock()
+
+	fresh, err := peer.fetchCRL(issuer)
+	if err != nil {
+		if crl == nil {
+			crl = &crlEntry{revoked: make(map[string]bool)}
+		}
+		peer.Warningf("Failed refreshing the %s CRL, using the one fetched at [%s] [%s].", issuer, crl.fetched, err)
+
+		fresh = &crlEntry{revoked: crl.revoked, fetched: crl.fetched, expires: time.Now().Add(crlRefreshInterval())}
+	}
+
+	peer.crlsMutex.Lock()
+	if peer.crls == nil {
+		peer.crls = make(map[string]*crlEntry)
+	}
+	peer.crls[issuer] = fresh
+	delete(peer.crlFetches, issuer)
+	peer.crlsMutex.Unlock()
+
+	fetch.entry = fresh
+	close(fetch.done)
+
+	return fresh
+}
+
+// fetchCRL reads the current CRL of the given CA and verifies it has been
+// signed by that CA
+func (peer *peerImpl) fetchCRL(issuer string) (*crlEntry, error) {
+	peer.Debugf("Fetching the %s CRL...", issuer)
+
+	var resp *membersrvc.CRL
+	var caCertAlias string
+	var err error
+
+	switch issuer {
+	case crlIssuerECA:
+		resp, err = peer.callECAReadCRL(context.Background())
+		caCertAlias = peer.conf.getECACertsChainFilename()
+	case crlIssuerTCA:
+		resp, err = peer.callTCAReadCRL(context.Background())
+		caCertAlias = peer.conf.getTCACertsChainFilename()
+	default:
+		return nil, errors.New("Unknown certificate authority " + issuer + ".")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	caCert, _, err := peer.ks.loadCertX509AndDer(caCertAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	crl, err := x509.ParseCRL(resp.Crl)
+	if err != nil {
+		peer.Errorf("Failed parsing the %s CRL [%s].", issuer, err)
+
+		return nil, err
+	}
+	if err = caCert.CheckCRLSignature(crl); err != nil {
+		peer.Errorf("Failed verifying the %s CRL signature [%s].", issuer, err)
+
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := &crlEntry{
+		revoked: make(map[string]bool),
+		fetched: now,
+		expires: now.Add(crlRefreshInterval()),
+	}
+	if nextUpdate := crl.TBSCertList.NextUpdate; nextUpdate.After(now) && nextUpdate.Before(entry.expires) {
+		entry.expires = nextUpdate
+	}
+	for _, revoked := range crl.TBSCertList.RevokedCertificates {
+		entry.revoked[revoked.SerialNumber.String()] = true
+	}
+
+	peer.Debugf("Fetching the %s CRL...done, %d certificate(s) revoked.", issuer, len(entry.revoked))
+
+	return entry, nil
+}
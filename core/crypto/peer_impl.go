@@ -33,6 +33,10 @@ type peerImpl struct {
 
 	nodeEnrollmentCertificatesMutex sync.RWMutex
 	nodeEnrollmentCertificates      map[string]*x509.Certificate
+
+	crlsMutex  sync.Mutex
+	crls       map[string]*crlEntry
+	crlFetches map[string]*crlFetch
 }
 
 // Public methods
@@ -79,14 +83,15 @@ func (peer *peerImpl) TransactionPreValidation(tx *obc.Transaction) (*obc.Transa
 		// 1. Get rid of the extensions that cannot be checked now
 		x509Cert.UnhandledCriticalExtensions = nil
 		// 2. Check against TCA certPool
-		if _, err = primitives.CheckCertAgainRoot(x509Cert, peer.tcaCertPool); err != nil {
-			peer.Warningf("Failed verifing certificate against TCA cert pool [%s].", err.Error())
-			// 3. Check against ECA certPool, if this check also fails then return an error
-			if _, err = primitives.CheckCertAgainRoot(x509Cert, peer.ecaCertPool); err != nil {
-				peer.Warningf("Failed verifing certificate against ECA cert pool [%s].", err.Error())
+		// 3. Check against ECA certPool, if this check also fails then return an error
+		issuer, err := peer.certificateIssuer(x509Cert)
+		if err != nil {
+			return tx, err
+		}
 
-				return tx, fmt.Errorf("Certificate has not been signed by a trusted authority. [%s]", err)
-			}
+		// 4. Check the certificate has not been revoked by its issuer
+		if err = peer.checkRevocation(x509Cert, issuer); err != nil {
+			return tx, err
 		}
 
 		// 3. Marshall tx without signature
@@ -181,6 +186,44 @@ func (peer *peerImpl) Verify(vkID, signature, message []byte) error {
 	return nil
 }
 
+// VerifyCertificateSignature checks that cert has been issued by a trusted authority and that
+// signature is a valid signature of message under cert's verification key.
+func (peer *peerImpl) VerifyCertificateSignature(cert, signature, message []byte) error {
+	if !peer.IsInitialized() {
+		return utils.ErrNotInitialized
+	}
+	if len(signature) == 0 {
+		return utils.ErrInvalidSignature
+	}
+
+	x509Cert, err := primitives.DERToX509Certificate(cert)
+	if err != nil {
+		peer.Debugf("Failed parsing certificate [% x]: [%s].", cert, err)
+
+		return err
+	}
+
+	// Get rid of the extensions that cannot be checked now
+	x509Cert.UnhandledCriticalExtensions = nil
+	if _, err = primitives.CheckCertAgainRoot(x509Cert, peer.tcaCertPool); err != nil {
+		if _, err = primitives.CheckCertAgainRoot(x509Cert, peer.ecaCertPool); err != nil {
+			peer.Warningf("Failed verifing certificate against TCA and ECA cert pools [%s].", err.Error())
+
+			return fmt.Errorf("Certificate has not been signed by a trusted authority. [%s]", err)
+		}
+	}
+
+	ok, err := peer.verify(x509Cert.PublicKey, message, signature)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return utils.ErrInvalidSignature
+	}
+
+	return nil
+}
+
 func (peer *peerImpl) GetStateEncryptor(deployTx *obc.Transaction, invokeTx *obc.Transaction) (StateEncryptor, error) {
 	return nil, utils.ErrNotImplemented
 }
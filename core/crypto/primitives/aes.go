@@ -152,3 +152,45 @@ func CBCPKCS7Decrypt(key, src []byte) ([]byte, error) {
 
 	return original, nil
 }
+
+// GCMEncrypt encrypts and authenticates src using AES in GCM mode.
+// The additional data is authenticated but not encrypted.
+// The random nonce is prepended to the returned ciphertext.
+func GCMEncrypt(key, src, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, src, additionalData), nil
+}
+
+// GCMDecrypt authenticates and decrypts a ciphertext produced by GCMEncrypt.
+// The same additional data used for encryption must be provided.
+func GCMDecrypt(key, src, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(src) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	return aead.Open(nil, src[:aead.NonceSize()], src[aead.NonceSize():], additionalData)
+}
@@ -413,6 +413,58 @@ func TestCBCEncryptCBCDecrypt(t *testing.T) {
 	}
 }
 
+// TestGCMEncryptGCMDecrypt encrypts with GCMEncrypt and decrypts with GCMDecrypt.
+func TestGCMEncryptGCMDecrypt(t *testing.T) {
+
+	key := make([]byte, primitives.AESKeyLength)
+	rand.Reader.Read(key)
+
+	var ptext = []byte("a message with arbitrary length (42 bytes)")
+	var adata = []byte("additional data")
+
+	encrypted, encErr := primitives.GCMEncrypt(key, ptext, adata)
+	if encErr != nil {
+		t.Fatalf("Error encrypting '%s': %v", ptext, encErr)
+	}
+
+	decrypted, decErr := primitives.GCMDecrypt(key, encrypted, adata)
+	if decErr != nil {
+		t.Fatalf("Error decrypting '%s': %v", ptext, decErr)
+	}
+
+	if string(ptext[:]) != string(decrypted[:]) {
+		t.Fatal("Encryption->Decryption with same key should result in the original plaintext.")
+	}
+}
+
+// TestGCMDecrypt_ExpectingAuthenticationFailure tampers with the ciphertext and the additional data.
+func TestGCMDecrypt_ExpectingAuthenticationFailure(t *testing.T) {
+
+	key := make([]byte, primitives.AESKeyLength)
+	rand.Reader.Read(key)
+
+	var ptext = []byte("1234567890ABCDEF")
+	var adata = []byte("additional data")
+
+	encrypted, encErr := primitives.GCMEncrypt(key, ptext, adata)
+	if encErr != nil {
+		t.Fatalf("Error encrypting '%s': %v", ptext, encErr)
+	}
+
+	if _, err := primitives.GCMDecrypt(key, encrypted, []byte("other data")); err == nil {
+		t.Fatal("Decrypting with different additional data should fail.")
+	}
+
+	encrypted[len(encrypted)-1] ^= 1
+	if _, err := primitives.GCMDecrypt(key, encrypted, adata); err == nil {
+		t.Fatal("Decrypting a tampered ciphertext should fail.")
+	}
+
+	if _, err := primitives.GCMDecrypt(key, encrypted[:4], adata); err == nil {
+		t.Fatal("Decrypting a too short ciphertext should fail.")
+	}
+}
+
 // TestAESRelatedUtilFunctions tests various functions commonly used in fabric wrt AES
 func TestAESRelatedUtilFunctions(t *testing.T) {
 
@@ -1,11 +1,17 @@
 package txset
 
 import (
-	"github.com/hyperledger/fabric/core/crypto/primitives"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
+	"io"
 	"math/rand"
-	"encoding/binary"
+
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	"github.com/hyperledger/fabric/core/crypto/primitives/ecies"
 	"github.com/op/go-logging"
+	"golang.org/x/crypto/hkdf"
 )
 
 const (
@@ -14,17 +20,30 @@ const (
 	KEY_BYTES  = 32
 )
 
+const (
+	// ConfidentialityProtocolVersion1_0 derives the transaction keys from a math/rand PRG seeded with the nonce
+	// and encrypts with AES-CBC-PKCS7. It is only kept to decrypt sets issued before version 2.0.
+	ConfidentialityProtocolVersion1_0 = "1.0"
+	// ConfidentialityProtocolVersion2_0 derives the transaction keys with HKDF-SHA256 over the nonce and the
+	// transaction index and encrypts with AES-GCM.
+	ConfidentialityProtocolVersion2_0 = "2.0"
+	// ConfidentialityProtocolVersion is the version used to encrypt new transactions sets
+	ConfidentialityProtocolVersion = ConfidentialityProtocolVersion2_0
+)
+
+var hkdfInfo = []byte("muchain txset transaction key")
+
 var logger = logging.MustGetLogger("txsetcrypto")
 
 // EncryptTxSetSpecification encrypts the txSetSpecification and returns the nonce necessary to generate the decryption keys.
+// The specification is encrypted using the current ConfidentialityProtocolVersion.
 func EncryptTxSetSpecification(specs [][]byte) ([]byte, [][]byte, error) {
-	return EncryptTxSetSpecificationStartingFrom(specs, nil, 0)
+	return EncryptTxSetSpecificationStartingFrom(ConfidentialityProtocolVersion, specs, nil, 0)
 }
 
 // EncryptTxSetSpecificationStartingFrom encrypts the txSetSpecification assuming prev transactions are already part of the set.
-// Returns the nonce necessary to generate the decryption keys.
-func EncryptTxSetSpecificationStartingFrom(specs [][]byte, nonce []byte, startInx uint64) ([]byte, [][]byte, error) {
-
+// The version must be the one the set was created with. Returns the nonce necessary to generate the decryption keys.
+func EncryptTxSetSpecificationStartingFrom(version string, specs [][]byte, nonce []byte, startInx uint64) ([]byte, [][]byte, error) {
 	var err error
 	var randNonces []byte
 	randNonces = nonce
@@ -35,6 +54,105 @@ func EncryptTxSetSpecificationStartingFrom(specs [][]byte, nonce []byte, startIn
 			return nil, nil, fmt.Errorf("Unable to generate initial randomness for the transaction encryption. (%s)", err)
 		}
 	}
+
+	var encSpecs [][]byte
+	switch normalizeVersion(version) {
+	case ConfidentialityProtocolVersion1_0:
+		encSpecs, err = encryptV1_0(specs, randNonces, startInx)
+	case ConfidentialityProtocolVersion2_0:
+		encSpecs, err = encryptV2_0(specs, randNonces, startInx)
+	default:
+		err = fmt.Errorf("Unsupported confidentiality protocol version [%s]", version)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return randNonces, encSpecs, nil
+}
+
+// DecryptTxSetSpecification decrypts the transaction at the given index of a set encrypted
+// under the given confidentiality protocol version.
+func DecryptTxSetSpecification(version string, nonce, spec []byte, index uint64) ([]byte, error) {
+	key, err := GenerateKeyForTransaction(version, nonce, index)
+	if err != nil {
+		return nil, err
+	}
+	switch normalizeVersion(version) {
+	case ConfidentialityProtocolVersion1_0:
+		return primitives.CBCPKCS7Decrypt(key, spec)
+	default:
+		return primitives.GCMDecrypt(key, spec, indexBytes(index))
+	}
+}
+
+// GenerateKeyForTransaction returns the key of the transaction at the given index of a set
+// encrypted under the given confidentiality protocol version.
+func GenerateKeyForTransaction(version string, nonce []byte, index uint64) ([]byte, error) {
+	if len(nonce) < NUM_SEEDS*SEED_BYTES {
+		return nil, fmt.Errorf("Invalid nonce length. Expected at least [%d], was [%d]", NUM_SEEDS*SEED_BYTES, len(nonce))
+	}
+	switch normalizeVersion(version) {
+	case ConfidentialityProtocolVersion1_0:
+		return generateKeyV1_0(nonce, index)
+	case ConfidentialityProtocolVersion2_0:
+		return generateKeyV2_0(nonce, index)
+	default:
+		return nil, fmt.Errorf("Unsupported confidentiality protocol version [%s]", version)
+	}
+}
+
+// EncryptNonce encrypts the nonce of a set under the PEM encoded public key of the chain,
+// so that only the validators are able to derive the keys of its transactions.
+func EncryptNonce(chainPubKeyPEM []byte, nonce []byte) ([]byte, error) {
+	pubTemp, err := primitives.PEMtoPublicKey(chainPubKeyPEM, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error when reading the chain public key: %s", err)
+	}
+	chainPubKey, ok := pubTemp.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("The chain public key is not an ECDSA key")
+	}
+	eciesSPI := ecies.NewSPI()
+	pubKey, err := eciesSPI.NewPublicKey(nil, chainPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("Error when converting the chain public key: %s", err)
+	}
+	cipher, err := eciesSPI.NewAsymmetricCipherFromPublicKey(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating new encryption scheme: %s", err)
+	}
+	encNonce, err := cipher.Process(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("Failed encrypting the nonce: %s", err)
+	}
+	return encNonce, nil
+}
+
+// IsSupportedVersion returns true if the given confidentiality protocol version can be used to decrypt a set.
+func IsSupportedVersion(version string) bool {
+	switch normalizeVersion(version) {
+	case ConfidentialityProtocolVersion1_0, ConfidentialityProtocolVersion2_0:
+		return true
+	}
+	return false
+}
+
+// IsCreationVersion returns true if a new set can be created with the given confidentiality protocol version.
+// Only the current version is accepted, the previous ones are kept to decrypt the sets already issued.
+func IsCreationVersion(version string) bool {
+	return version == ConfidentialityProtocolVersion
+}
+
+// Sets issued before the introduction of the versioning do not carry a version, they can only be decrypted
+func normalizeVersion(version string) string {
+	if version == "" {
+		return ConfidentialityProtocolVersion1_0
+	}
+	return version
+}
+
+func encryptV1_0(specs [][]byte, randNonces []byte, startInx uint64) ([][]byte, error) {
+	var err error
 	// Read and combine the randomness from the seeded PRG
 	txKeys := make([][]byte, len(specs))
 	for i := range specs {
@@ -42,14 +160,14 @@ func EncryptTxSetSpecificationStartingFrom(specs [][]byte, nonce []byte, startIn
 	}
 	tempKey := make([]byte, KEY_BYTES)
 	for i := 0; i < NUM_SEEDS; i++ {
-		rand.Seed(int64(binary.BigEndian.Uint64(randNonces[i * SEED_BYTES : (i + 1) * SEED_BYTES])))
-		for j := uint64(0); j < uint64(len(specs)) + startInx; j++ {
+		rand.Seed(int64(binary.BigEndian.Uint64(randNonces[i*SEED_BYTES : (i+1)*SEED_BYTES])))
+		for j := uint64(0); j < uint64(len(specs))+startInx; j++ {
 			_, err = rand.Read(tempKey)
 			if err != nil {
-				return nil, nil, fmt.Errorf("Unable to generate random key for the transaction encryption. (%s)", err)
+				return nil, fmt.Errorf("Unable to generate random key for the transaction encryption. (%s)", err)
 			}
 			if j >= startInx {
-				xorBytes(txKeys[j - startInx], tempKey)
+				xorBytes(txKeys[j-startInx], tempKey)
 			}
 		}
 	}
@@ -58,25 +176,34 @@ func EncryptTxSetSpecificationStartingFrom(specs [][]byte, nonce []byte, startIn
 	for i := range specs {
 		encSpecs[i], err = primitives.CBCPKCS7Encrypt(txKeys[i], specs[i])
 		if err != nil {
-			return nil, nil, fmt.Errorf("Unable to encrypt transaction. Err: [%s]", err)
+			return nil, fmt.Errorf("Unable to encrypt transaction. Err: [%s]", err)
 		}
 	}
-	return randNonces, encSpecs, nil
+	return encSpecs, nil
 }
 
-func DecryptTxSetSpecification(nonce, spec []byte, index uint64) ([]byte, error) {
-	key, err := GenerateKeyForTransaction(nonce, index)
-	if err != nil {
-		return nil, err
+func encryptV2_0(specs [][]byte, nonce []byte, startInx uint64) ([][]byte, error) {
+	encSpecs := make([][]byte, len(specs))
+	for i := range specs {
+		index := startInx + uint64(i)
+		key, err := generateKeyV2_0(nonce, index)
+		if err != nil {
+			return nil, err
+		}
+		// Binding the index prevents moving a ciphertext to a different position of the set
+		encSpecs[i], err = primitives.GCMEncrypt(key, specs[i], indexBytes(index))
+		if err != nil {
+			return nil, fmt.Errorf("Unable to encrypt transaction. Err: [%s]", err)
+		}
 	}
-	return primitives.CBCPKCS7Decrypt(key, spec)
+	return encSpecs, nil
 }
 
-func GenerateKeyForTransaction(nonce []byte, index uint64) ([]byte, error) {
+func generateKeyV1_0(nonce []byte, index uint64) ([]byte, error) {
 	key := make([]byte, KEY_BYTES)
 	tempKey := make([]byte, KEY_BYTES)
 	for i := 0; i < NUM_SEEDS; i++ {
-		rand.Seed(int64(binary.BigEndian.Uint64(nonce[i * SEED_BYTES : (i + 1) * SEED_BYTES])))
+		rand.Seed(int64(binary.BigEndian.Uint64(nonce[i*SEED_BYTES : (i+1)*SEED_BYTES])))
 		for j := 0; uint64(j) <= index; j++ {
 			_, err := rand.Read(tempKey)
 			if err != nil {
@@ -88,6 +215,21 @@ func GenerateKeyForTransaction(nonce []byte, index uint64) ([]byte, error) {
 	return key, nil
 }
 
+func generateKeyV2_0(nonce []byte, index uint64) ([]byte, error) {
+	info := append(append([]byte{}, hkdfInfo...), indexBytes(index)...)
+	key := make([]byte, KEY_BYTES)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, nonce, nil, info), key); err != nil {
+		return nil, fmt.Errorf("Unable to derive the transaction key. (%s)", err)
+	}
+	return key, nil
+}
+
+func indexBytes(index uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, index)
+	return b
+}
+
 // performs a xor of the content of the second array into the first
 func xorBytes(first, second []byte) {
 	for i := 0; i < len(first); i++ {
@@ -96,4 +238,4 @@ func xorBytes(first, second []byte) {
 		}
 		first[i] = first[i] ^ second[i]
 	}
-}
\ No newline at end of file
+}
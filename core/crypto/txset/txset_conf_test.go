@@ -0,0 +1,75 @@
+package txset
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func createSpecs(n int) [][]byte {
+	specs := make([][]byte, n)
+	for i := range specs {
+		specs[i] = []byte(fmt.Sprintf("transaction specification %d", i))
+	}
+	return specs
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	for _, version := range []string{"", ConfidentialityProtocolVersion1_0, ConfidentialityProtocolVersion2_0} {
+		specs := createSpecs(5)
+		nonce, encSpecs, err := EncryptTxSetSpecificationStartingFrom(version, specs[:3], nil, 0)
+		if err != nil {
+			t.Fatalf("Version [%s]: unable to encrypt specs: %s", version, err)
+		}
+		_, extSpecs, err := EncryptTxSetSpecificationStartingFrom(version, specs[3:], nonce, 3)
+		if err != nil {
+			t.Fatalf("Version [%s]: unable to encrypt extension: %s", version, err)
+		}
+		encSpecs = append(encSpecs, extSpecs...)
+		for i, encSpec := range encSpecs {
+			spec, err := DecryptTxSetSpecification(version, nonce, encSpec, uint64(i))
+			if err != nil {
+				t.Fatalf("Version [%s]: unable to decrypt spec %d: %s", version, i, err)
+			}
+			if !bytes.Equal(spec, specs[i]) {
+				t.Fatalf("Version [%s]: decrypted spec %d does not match. Expected [%s], was [%s]", version, i, specs[i], spec)
+			}
+		}
+	}
+}
+
+func TestDecryptWrongIndex(t *testing.T) {
+	nonce, encSpecs, err := EncryptTxSetSpecification(createSpecs(2))
+	if err != nil {
+		t.Fatalf("Unable to encrypt specs: %s", err)
+	}
+	if _, err := DecryptTxSetSpecification(ConfidentialityProtocolVersion, nonce, encSpecs[0], 1); err == nil {
+		t.Fatal("Decrypting a transaction with the key of a different index should fail.")
+	}
+}
+
+func TestCreationVersion(t *testing.T) {
+	if !IsCreationVersion(ConfidentialityProtocolVersion2_0) {
+		t.Fatal("New sets should be created with version 2.0.")
+	}
+	for _, version := range []string{"", ConfidentialityProtocolVersion1_0} {
+		if IsCreationVersion(version) {
+			t.Fatalf("New sets should not be created with version [%s].", version)
+		}
+		if !IsSupportedVersion(version) {
+			t.Fatalf("The sets created with version [%s] should still be decrypted.", version)
+		}
+	}
+}
+
+func TestUnsupportedVersion(t *testing.T) {
+	if IsSupportedVersion("0.1") {
+		t.Fatal("Version 0.1 should not be supported.")
+	}
+	if _, _, err := EncryptTxSetSpecificationStartingFrom("0.1", createSpecs(1), nil, 0); err == nil {
+		t.Fatal("Encrypting with an unsupported version should fail.")
+	}
+	if _, err := GenerateKeyForTransaction("0.1", make([]byte, NUM_SEEDS*SEED_BYTES), 0); err == nil {
+		t.Fatal("Generating a key with an unsupported version should fail.")
+	}
+}
@@ -50,11 +50,41 @@ func RetrieveNonce(tx *pb.InBlockTransaction) ([]byte, error) {
 			return nil, fmt.Errorf("Unable to get the security helper. Error: [%s]", err)
 		}
 		clone, err := secHelper.InBlockTransactionPreExecution(tx)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to decrypt the nonce. Error: [%s]", err)
+		}
 		return clone.Nonce, nil
 	}
 	return db.GetDBHandle().GetFromNoncesCF(encodeTxID(tx.Txid))
 }
 
+// DeleteNonces adds to writeBatch the deletion of the persisted nonces of the sets for which collect returns true.
+// It returns the number of nonces deleted and the bytes they took.
+func DeleteNonces(writeBatch *gorocksdb.WriteBatch, collect func(txSetID string) (bool, error)) (uint64, uint64, error) {
+	dbHandle := db.GetDBHandle()
+	itr := dbHandle.GetIterator(dbHandle.NoncesCF)
+	defer itr.Close()
+	var deleted, size uint64
+	for itr.SeekToFirst(); itr.Valid(); itr.Next() {
+		keyBytes := append([]byte{}, itr.Key().Data()...)
+		valueSize := uint64(len(itr.Value().Data()))
+		ok, err := collect(decodeTxID(keyBytes))
+		if err != nil {
+			return 0, 0, err
+		}
+		if ok {
+			writeBatch.DeleteCF(dbHandle.NoncesCF, keyBytes)
+			deleted++
+			size += uint64(len(keyBytes)) + valueSize
+		}
+	}
+	return deleted, size, itr.Err()
+}
+
 func encodeTxID(ID string) ([]byte) {
 	return []byte(ID)
 }
+
+func decodeTxID(key []byte) string {
+	return string(key)
+}
@@ -0,0 +1,173 @@
+package txset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/chaincode/shim/crypto/attr"
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// SignatureVerifier verifies that a signature was produced by the holder of a
+// certificate issued by a trusted authority.
+type SignatureVerifier interface {
+	VerifyCertificateSignature(cert, signature, message []byte) error
+}
+
+// PolicyForNewSet returns the mutation policy to be stored in the state of the set created by txSetTx.
+// For a creator only policy the certificate of the creator is verified and recorded in the returned policy.
+// A nil policy is returned if anyone is allowed to mutate the set.
+func PolicyForNewSet(verifier SignatureVerifier, txSetTx *pb.InBlockTransaction) (*pb.TxSetMutationPolicy, error) {
+	txSet := txSetTx.GetTransactionSet()
+	policy := txSet.GetMutationPolicy()
+	if policy.IsUnrestricted() {
+		return nil, nil
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if verifier == nil && policy.RequiresSecurity() {
+		return nil, errors.New("A mutation policy can be enforced only when security is enabled.")
+	}
+	policy = proto.Clone(policy).(*pb.TxSetMutationPolicy)
+	if policy.Type == pb.TxSetMutationPolicy_CREATOR {
+		if len(txSetTx.Cert) == 0 || len(txSetTx.Signature) == 0 {
+			return nil, errors.New("A creator only mutation policy requires the transactions set to be signed by its creator.")
+		}
+		msg, err := pb.TransactionSetSigningBytes(txSet)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifier.VerifyCertificateSignature(txSetTx.Cert, txSetTx.Signature, msg); err != nil {
+			return nil, fmt.Errorf("Invalid signature of the creator of the transactions set. (%s)", err)
+		}
+		policy.CreatorCert = txSetTx.Cert
+	}
+	return policy, nil
+}
+
+// CheckMutationPolicies verifies that the mutant transaction satisfies the mutation policies of all the sets it
+// mutates. txSetStValues holds the current state of each mutated set, in the order of GetTxSetMutations. The
+// signatures must cover all the mutations, which are checked against the current state of their sets, and the
+// scheduling or cancellation requested by the mutant transaction, see MutantSigningBytes.
+func CheckMutationPolicies(verifier SignatureVerifier, txSetStValues []*pb.TxSetStateValue, mutantTx *pb.InBlockTransaction) error {
+	mutation := mutantTx.GetMutantTransaction()
+	if mutation == nil {
+		return errors.New("The given transaction is not a mutant transaction.")
+	}
+	mutations := mutation.GetTxSetMutations()
+	if len(mutations) != len(txSetStValues) {
+		return fmt.Errorf("Expected the state of %d tx sets, got %d.", len(mutations), len(txSetStValues))
+	}
+	if mutation.ChaincodeID != "" {
+		return checkChaincodePolicies(mutation.ChaincodeID, mutations, txSetStValues)
+	}
+
+	restricted := false
+	for i, txSetStValue := range txSetStValues {
+		policy := txSetStValue.GetMutationPolicy()
+		if !policy.IsUnrestricted() && policy.Type == pb.TxSetMutationPolicy_CHAINCODE {
+			return fmt.Errorf("Mutation rejected by the mutation policy of the tx set with ID: %s. (Only the chaincodes named by the policy are allowed to mutate it.)", mutations[i].TxSetID)
+		}
+		restricted = restricted || !policy.IsUnrestricted()
+	}
+	if !restricted {
+		return nil
+	}
+	if verifier == nil {
+		return errors.New("Unable to verify the mutation policy, security is not enabled.")
+	}
+
+	nonces := make([]uint64, len(txSetStValues))
+	for i, txSetStValue := range txSetStValues {
+		nonces[i] = txSetStValue.Nonce
+	}
+	msg := pb.MutantSigningBytes(mutation, nonces)
+	signatures := mutation.Signatures
+	if len(mutantTx.Cert) != 0 {
+		signatures = append([]*pb.MutationSignature{{Cert: mutantTx.Cert, Signature: mutantTx.Signature}}, signatures...)
+	}
+	if len(signatures) == 0 {
+		return errors.New("The mutant transaction is not signed.")
+	}
+	for i, sig := range signatures {
+		if err := verifier.VerifyCertificateSignature(sig.Cert, sig.Signature, msg); err != nil {
+			return fmt.Errorf("Invalid signature [%d] of the mutant transaction. (%s)", i, err)
+		}
+	}
+
+	for i, txSetStValue := range txSetStValues {
+		if err := checkPolicy(txSetStValue.GetMutationPolicy(), signatures); err != nil {
+			return fmt.Errorf("Mutation rejected by the mutation policy of the tx set with ID: %s. (%s)", mutations[i].TxSetID, err)
+		}
+	}
+	return nil
+}
+
+// checkChaincodePolicies verifies that the chaincode chaincodeID, which requested the mutations through its shim,
+// is allowed to mutate all the sets. No signature is involved: the validators vouch for the identity of the chaincode
+func checkChaincodePolicies(chaincodeID string, mutations []*pb.TxSetMutation, txSetStValues []*pb.TxSetStateValue) error {
+	for i, txSetStValue := range txSetStValues {
+		if !txSetStValue.GetMutationPolicy().AllowsChaincode(chaincodeID) {
+			return fmt.Errorf("Mutation rejected by the mutation policy of the tx set with ID: %s. (The chaincode [%s] is not allowed to mutate it.)", mutations[i].TxSetID, chaincodeID)
+		}
+	}
+	return nil
+}
+
+// checkPolicy verifies that the signers of a mutation satisfy policy. The signatures are already verified.
+func checkPolicy(policy *pb.TxSetMutationPolicy, signatures []*pb.MutationSignature) error {
+	if policy.IsUnrestricted() {
+		return nil
+	}
+	switch policy.Type {
+	case pb.TxSetMutationPolicy_CREATOR:
+		for _, sig := range signatures {
+			if bytes.Equal(sig.Cert, policy.CreatorCert) {
+				return nil
+			}
+		}
+		return errors.New("Only the creator of the transactions set is allowed to mutate it.")
+	case pb.TxSetMutationPolicy_ENROLLMENT_IDS:
+		for _, sig := range signatures {
+			cert, err := primitives.DERToX509Certificate(sig.Cert)
+			if err != nil {
+				return err
+			}
+			for _, id := range policy.EnrollmentIDs {
+				if cert.Subject.CommonName == id {
+					return nil
+				}
+			}
+		}
+		return errors.New("None of the signers of the mutant transaction is among the enrollment IDs allowed to mutate the set.")
+	case pb.TxSetMutationPolicy_ATTRIBUTE:
+		// Only transaction certificates hold attributes. The TCA does not encrypt them, so they are read in clear
+		for _, sig := range signatures {
+			value, err := attr.GetValueFrom(policy.AttributeName, sig.Cert)
+			if err == nil && bytes.Equal(value, policy.AttributeValue) {
+				return nil
+			}
+		}
+		return fmt.Errorf("None of the signers of the mutant transaction holds the attribute [%s] with the required value.", policy.AttributeName)
+	case pb.TxSetMutationPolicy_THRESHOLD:
+		signed := make([]bool, len(policy.SignerCerts))
+		count := uint32(0)
+		for _, sig := range signatures {
+			for j, signer := range policy.SignerCerts {
+				if !signed[j] && bytes.Equal(sig.Cert, signer) {
+					signed[j] = true
+					count++
+				}
+			}
+		}
+		if count < policy.Threshold {
+			return fmt.Errorf("Not enough signatures for the mutation. Required: [%d], provided: [%d]", policy.Threshold, count)
+		}
+		return nil
+	}
+	return fmt.Errorf("Unknown mutation policy type [%d]", policy.Type)
+}
@@ -0,0 +1,269 @@
+package txset
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/crypto/attributes"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// mockVerifier accepts a signature if it is equal to the certificate followed by the message
+type mockVerifier struct{}
+
+func (mockVerifier) VerifyCertificateSignature(cert, signature, message []byte) error {
+	if !bytes.Equal(signature, append(append([]byte{}, cert...), message...)) {
+		return errors.New("Invalid signature")
+	}
+	return nil
+}
+
+func mockSign(cert, message []byte) []byte {
+	return append(append([]byte{}, cert...), message...)
+}
+
+func createCert(t *testing.T, commonName string) []byte {
+	return createCertWithExtensions(t, commonName, nil)
+}
+
+// createTCert creates a certificate holding attrs the way the TCA stores them in a TCert
+func createTCert(t *testing.T, attrs map[string]string) []byte {
+	var extensions []pkix.Extension
+	header := make(map[string]int)
+	for name, value := range attrs {
+		header[name] = len(header) + 1
+		extensions = append(extensions, pkix.Extension{Id: asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 9 + header[name]}, Value: []byte(value)})
+	}
+	headerValue, err := attributes.BuildAttributesHeader(header)
+	if err != nil {
+		t.Fatalf("Unable to build the attributes header: %s", err)
+	}
+	extensions = append(extensions, pkix.Extension{Id: attributes.TCertAttributesHeaders, Value: headerValue})
+	return createCertWithExtensions(t, "Transaction Certificate", extensions)
+}
+
+func createCertWithExtensions(t *testing.T, commonName string, extensions []pkix.Extension) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unable to generate key: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		Subject:         pkix.Name{CommonName: commonName},
+		NotBefore:       time.Now(),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: extensions,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Unable to create certificate: %s", err)
+	}
+	return der
+}
+
+func createMutantTx(stValue *pb.TxSetStateValue, signers ...[]byte) *pb.InBlockTransaction {
+	mutation := &pb.MutantTransaction{TxSetID: "txSetID", TxSetIndex: 1}
+	msg := pb.MutationSigningBytes(mutation.TxSetID, mutation.TxSetIndex, stValue.Nonce)
+	for _, signer := range signers {
+		mutation.Signatures = append(mutation.Signatures, &pb.MutationSignature{Cert: signer, Signature: mockSign(signer, msg)})
+	}
+	return &pb.InBlockTransaction{Transaction: &pb.InBlockTransaction_MutantTransaction{MutantTransaction: mutation}}
+}
+
+func TestPolicyForNewSet(t *testing.T) {
+	creator := createCert(t, "creator")
+	txSet := &pb.TransactionSet{
+		Transactions:   [][]byte{[]byte("tx0"), []byte("tx1")},
+		MutationPolicy: &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_CREATOR},
+	}
+	txSetTx := &pb.InBlockTransaction{Transaction: &pb.InBlockTransaction_TransactionSet{TransactionSet: txSet}}
+
+	if _, err := PolicyForNewSet(mockVerifier{}, txSetTx); err == nil {
+		t.Fatal("A creator only policy for a set which is not signed should be rejected.")
+	}
+	if _, err := PolicyForNewSet(nil, txSetTx); err == nil {
+		t.Fatal("A mutation policy should be rejected when security is not enabled.")
+	}
+
+	msg, err := pb.TransactionSetSigningBytes(txSet)
+	if err != nil {
+		t.Fatalf("Unable to marshal the set: %s", err)
+	}
+	txSetTx.Cert = creator
+	txSetTx.Signature = mockSign(creator, msg)
+	policy, err := PolicyForNewSet(mockVerifier{}, txSetTx)
+	if err != nil {
+		t.Fatalf("Unable to create the policy: %s", err)
+	}
+	if !bytes.Equal(policy.CreatorCert, creator) {
+		t.Fatal("The certificate of the creator should be recorded in the policy.")
+	}
+	if len(txSet.MutationPolicy.CreatorCert) != 0 {
+		t.Fatal("The policy of the transactions set should not be modified.")
+	}
+
+	txSet.MutationPolicy = nil
+	policy, err = PolicyForNewSet(nil, txSetTx)
+	if err != nil || policy != nil {
+		t.Fatalf("An unrestricted set should not have a policy. Policy: %v, err: %v", policy, err)
+	}
+}
+
+func TestCheckMutationPolicy(t *testing.T) {
+	alice := createCert(t, "alice")
+	bob := createCert(t, "bob")
+	carol := createCert(t, "carol")
+	admin := createTCert(t, map[string]string{"company": "ACompany", "role": "admin"})
+	user := createTCert(t, map[string]string{"role": "user"})
+
+	testCases := []struct {
+		name    string
+		policy  *pb.TxSetMutationPolicy
+		signers [][]byte
+		valid   bool
+	}{
+		{"unrestricted", nil, nil, true},
+		{"creator", &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_CREATOR, CreatorCert: alice}, [][]byte{alice}, true},
+		{"not creator", &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_CREATOR, CreatorCert: alice}, [][]byte{bob}, false},
+		{"unsigned", &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_CREATOR, CreatorCert: alice}, nil, false},
+		{"enrollment id", &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_ENROLLMENT_IDS, EnrollmentIDs: []string{"bob", "carol"}}, [][]byte{bob}, true},
+		{"unknown enrollment id", &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_ENROLLMENT_IDS, EnrollmentIDs: []string{"bob", "carol"}}, [][]byte{alice}, false},
+		{"attribute", &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_ATTRIBUTE, AttributeName: "role", AttributeValue: []byte("admin")}, [][]byte{admin}, true},
+		{"attribute among signers", &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_ATTRIBUTE, AttributeName: "role", AttributeValue: []byte("admin")}, [][]byte{alice, admin}, true},
+		{"wrong attribute value", &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_ATTRIBUTE, AttributeName: "role", AttributeValue: []byte("admin")}, [][]byte{user}, false},
+		{"attribute without tcert", &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_ATTRIBUTE, AttributeName: "role", AttributeValue: []byte("admin")}, [][]byte{alice}, false},
+		{"threshold", &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_THRESHOLD, SignerCerts: [][]byte{alice, bob, carol}, Threshold: 2}, [][]byte{alice, carol}, true},
+		{"threshold duplicates", &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_THRESHOLD, SignerCerts: [][]byte{alice, bob, carol}, Threshold: 2}, [][]byte{alice, alice}, false},
+	}
+
+	for _, testCase := range testCases {
+		stValue := &pb.TxSetStateValue{Nonce: 3, TxNumber: 2, MutationPolicy: testCase.policy}
+		err := CheckMutationPolicies(mockVerifier{}, []*pb.TxSetStateValue{stValue}, createMutantTx(stValue, testCase.signers...))
+		if testCase.valid && err != nil {
+			t.Fatalf("Test case [%s]: unexpected error: %s", testCase.name, err)
+		}
+		if !testCase.valid && err == nil {
+			t.Fatalf("Test case [%s]: the mutation should have been rejected.", testCase.name)
+		}
+	}
+}
+
+func TestCheckMutationPolicyStaleSignature(t *testing.T) {
+	alice := createCert(t, "alice")
+	stValue := &pb.TxSetStateValue{Nonce: 3, TxNumber: 2, MutationPolicy: &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_CREATOR, CreatorCert: alice}}
+	mutantTx := createMutantTx(stValue, alice)
+	stValue.Nonce++
+	if err := CheckMutationPolicies(mockVerifier{}, []*pb.TxSetStateValue{stValue}, mutantTx); err == nil {
+		t.Fatal("A signature for a previous state of the set should be rejected.")
+	}
+}
+
+func TestCheckMutationPoliciesMultipleSets(t *testing.T) {
+	alice := createCert(t, "alice")
+	bob := createCert(t, "bob")
+	stValues := []*pb.TxSetStateValue{
+		{Nonce: 3, TxNumber: 2, MutationPolicy: &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_CREATOR, CreatorCert: alice}},
+		{Nonce: 5, TxNumber: 2, MutationPolicy: &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_CREATOR, CreatorCert: bob}},
+	}
+	mutation := &pb.MutantTransaction{TxSetID: "txSet1", TxSetIndex: 1,
+		AdditionalMutations: []*pb.TxSetMutation{{TxSetID: "txSet2", TxSetIndex: 0}}}
+	mutantTx := &pb.InBlockTransaction{Transaction: &pb.InBlockTransaction_MutantTransaction{MutantTransaction: mutation}}
+	msg := pb.MutationsSigningBytes(mutation.GetTxSetMutations(), []uint64{3, 5})
+
+	mutation.Signatures = []*pb.MutationSignature{{Cert: alice, Signature: mockSign(alice, msg)}}
+	err := CheckMutationPolicies(mockVerifier{}, stValues, mutantTx)
+	if err == nil || !strings.Contains(err.Error(), "txSet2") {
+		t.Fatalf("The mutation should have been rejected by the policy of txSet2, got: %v", err)
+	}
+
+	mutation.Signatures = append(mutation.Signatures, &pb.MutationSignature{Cert: bob, Signature: mockSign(bob, msg)})
+	if err = CheckMutationPolicies(mockVerifier{}, stValues, mutantTx); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// A signature over one of the mutations only does not authorize the others
+	single := pb.MutationSigningBytes("txSet1", 1, 3)
+	mutation.Signatures = []*pb.MutationSignature{{Cert: alice, Signature: mockSign(alice, single)}, {Cert: bob, Signature: mockSign(bob, msg)}}
+	if err = CheckMutationPolicies(mockVerifier{}, stValues, mutantTx); err == nil {
+		t.Fatal("A signature which does not cover all the mutations should be rejected.")
+	}
+}
+
+func TestCheckMutationPoliciesScheduled(t *testing.T) {
+	alice := createCert(t, "alice")
+	stValues := []*pb.TxSetStateValue{{Nonce: 3, TxNumber: 2, MutationPolicy: &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_CREATOR, CreatorCert: alice}}}
+	mutation := &pb.MutantTransaction{TxSetID: "txSet1", TxSetIndex: 1, AtBlock: 10}
+	mutantTx := &pb.InBlockTransaction{Transaction: &pb.InBlockTransaction_MutantTransaction{MutantTransaction: mutation}}
+
+	// A signature of the immediate mutation does not authorize scheduling it
+	immediate := pb.MutationSigningBytes("txSet1", 1, 3)
+	mutation.Signatures = []*pb.MutationSignature{{Cert: alice, Signature: mockSign(alice, immediate)}}
+	if err := CheckMutationPolicies(mockVerifier{}, stValues, mutantTx); err == nil {
+		t.Fatal("A signature of the immediate mutation should not authorize its scheduling.")
+	}
+
+	msg := pb.MutantSigningBytes(mutation, []uint64{3})
+	mutation.Signatures = []*pb.MutationSignature{{Cert: alice, Signature: mockSign(alice, msg)}}
+	if err := CheckMutationPolicies(mockVerifier{}, stValues, mutantTx); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// The signature covers the block at which the mutation takes effect
+	mutation.AtBlock = 11
+	if err := CheckMutationPolicies(mockVerifier{}, stValues, mutantTx); err == nil {
+		t.Fatal("The signature should not authorize the mutation at a different block.")
+	}
+
+	// Nor does it authorize the cancellation
+	mutation.AtBlock = 0
+	mutation.CancelScheduled = true
+	if err := CheckMutationPolicies(mockVerifier{}, stValues, mutantTx); err == nil {
+		t.Fatal("The signature of the scheduling should not authorize the cancellation.")
+	}
+}
+
+func TestCheckMutationPoliciesChaincode(t *testing.T) {
+	alice := createCert(t, "alice")
+	stValues := []*pb.TxSetStateValue{
+		{Nonce: 3, TxNumber: 2, MutationPolicy: &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_CHAINCODE, ChaincodeIDs: []string{"escrow"}}},
+		{Nonce: 5, TxNumber: 2},
+	}
+	mutation := &pb.MutantTransaction{TxSetID: "txSet1", TxSetIndex: 1, ChaincodeID: "escrow",
+		AdditionalMutations: []*pb.TxSetMutation{{TxSetID: "txSet2", TxSetIndex: 0}}}
+	mutantTx := &pb.InBlockTransaction{Transaction: &pb.InBlockTransaction_MutantTransaction{MutantTransaction: mutation}}
+
+	// No verifier is needed, the identity of the chaincode is vouched for by the validators
+	if err := CheckMutationPolicies(nil, stValues, mutantTx); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	mutation.ChaincodeID = "other"
+	if err := CheckMutationPolicies(nil, stValues, mutantTx); err == nil || !strings.Contains(err.Error(), "txSet1") {
+		t.Fatalf("The mutation should have been rejected by the policy of txSet1, got: %v", err)
+	}
+
+	stValues[1].MutationPolicy = &pb.TxSetMutationPolicy{Type: pb.TxSetMutationPolicy_CREATOR, CreatorCert: alice}
+	mutation.ChaincodeID = "escrow"
+	if err := CheckMutationPolicies(nil, stValues, mutantTx); err == nil || !strings.Contains(err.Error(), "txSet2") {
+		t.Fatalf("A chaincode should not satisfy a creator only policy, got: %v", err)
+	}
+
+	// Clients cannot mutate a set reserved to chaincodes, whatever they sign
+	mutation.ChaincodeID = ""
+	stValues[1].MutationPolicy = nil
+	msg := pb.MutationsSigningBytes(mutation.GetTxSetMutations(), []uint64{3, 5})
+	mutation.Signatures = []*pb.MutationSignature{{Cert: alice, Signature: mockSign(alice, msg)}}
+	if err := CheckMutationPolicies(mockVerifier{}, stValues, mutantTx); err == nil {
+		t.Fatal("A client should not be allowed to mutate a set reserved to chaincodes.")
+	}
+}
@@ -0,0 +1,43 @@
+package txset
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// NewQueryKey generates a fresh key for the result of a tx set state query. It returns the key and its
+// encryption under the PEM encoded public key of the chain, to be sent as the nonce of the query.
+func NewQueryKey(chainPubKeyPEM []byte) ([]byte, []byte, error) {
+	key, err := primitives.GetRandomBytes(KEY_BYTES)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to generate the query key. (%s)", err)
+	}
+	encKey, err := EncryptNonce(chainPubKeyPEM, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, encKey, nil
+}
+
+// EncryptQueryResult encrypts the result of a tx set state query under the key carried by the query.
+// The result of a query that is not confidential is returned in the clear.
+func EncryptQueryResult(queryTx *pb.InBlockTransaction, result []byte) ([]byte, error) {
+	if queryTx.ConfidentialityLevel != pb.ConfidentialityLevel_CONFIDENTIAL {
+		return result, nil
+	}
+	key, err := RetrieveNonce(queryTx)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != KEY_BYTES {
+		return nil, fmt.Errorf("Invalid query key length. Expected [%d], was [%d]", KEY_BYTES, len(key))
+	}
+	return primitives.GCMEncrypt(key, result, []byte(queryTx.Txid))
+}
+
+// DecryptQueryResult decrypts the result of the tx set state query with the given ID
+func DecryptQueryResult(key []byte, txID string, result []byte) ([]byte, error) {
+	return primitives.GCMDecrypt(key, result, []byte(txID))
+}
@@ -0,0 +1,41 @@
+package txset
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+func TestEncryptQueryResultPublic(t *testing.T) {
+	queryTx := &pb.InBlockTransaction{Txid: "query", ConfidentialityLevel: pb.ConfidentialityLevel_PUBLIC}
+	result, err := EncryptQueryResult(queryTx, []byte("state"))
+	if err != nil {
+		t.Fatalf("Unable to answer a public query: %s", err)
+	}
+	if !bytes.Equal(result, []byte("state")) {
+		t.Fatalf("The result of a public query should not be encrypted. Was [% x]", result)
+	}
+}
+
+func TestDecryptQueryResult(t *testing.T) {
+	key, err := primitives.GetRandomBytes(KEY_BYTES)
+	if err != nil {
+		t.Fatalf("Unable to generate a key: %s", err)
+	}
+	encResult, err := primitives.GCMEncrypt(key, []byte("state"), []byte("query"))
+	if err != nil {
+		t.Fatalf("Unable to encrypt the result: %s", err)
+	}
+	result, err := DecryptQueryResult(key, "query", encResult)
+	if err != nil {
+		t.Fatalf("Unable to decrypt the result: %s", err)
+	}
+	if !bytes.Equal(result, []byte("state")) {
+		t.Fatalf("Decrypted result does not match. Expected [state], was [%s]", result)
+	}
+	if _, err := DecryptQueryResult(key, "another query", encResult); err == nil {
+		t.Fatal("Decrypting the result of a different query should fail.")
+	}
+}
@@ -39,6 +39,8 @@ const txSetStateDeltaCF = "txSetStateDeltaCF"
 const noncesCF = "noncesCF"
 const indexesCF = "indexesCF"
 const persistCF = "persistCF"
+const txRWSetCF = "txRWSetCF"
+const resetJournalCF = "resetJournalCF"
 
 var columnfamilies = []string{
 	blockchainCF,      // blocks of the block chain
@@ -50,6 +52,8 @@ var columnfamilies = []string{
 	noncesCF,		   // save every nonce apart from the blockchain
 	indexesCF,         // tx uuid -> blockno
 	persistCF,         // persistent per-peer state (consensus)
+	txRWSetCF,         // keys read and changes written by the transactions of each block
+	resetJournalCF,    // progress of an ongoing state reset and the data it overwrote
 }
 
 // OpenchainDB encapsulates rocksdb's structures
@@ -64,6 +68,8 @@ type OpenchainDB struct {
 	NoncesCF		  *gorocksdb.ColumnFamilyHandle
 	IndexesCF         *gorocksdb.ColumnFamilyHandle
 	PersistCF         *gorocksdb.ColumnFamilyHandle
+	TxRWSetCF         *gorocksdb.ColumnFamilyHandle
+	ResetJournalCF    *gorocksdb.ColumnFamilyHandle
 }
 
 var openchainDB = create()
@@ -133,6 +139,16 @@ func (openchainDB *OpenchainDB) GetFromIndexesCF(key []byte) ([]byte, error) {
 	return openchainDB.Get(openchainDB.IndexesCF, key)
 }
 
+// GetFromTxRWSetCF get value for given key from column family - txRWSetCF
+func (openchainDB *OpenchainDB) GetFromTxRWSetCF(key []byte) ([]byte, error) {
+	return openchainDB.Get(openchainDB.TxRWSetCF, key)
+}
+
+// GetFromResetJournalCF get value for given key from column family - resetJournalCF
+func (openchainDB *OpenchainDB) GetFromResetJournalCF(key []byte) ([]byte, error) {
+	return openchainDB.Get(openchainDB.ResetJournalCF, key)
+}
+
 // GetBlockchainCFIterator get iterator for column family - blockchainCF
 func (openchainDB *OpenchainDB) GetBlockchainCFIterator() *gorocksdb.Iterator {
 	return openchainDB.GetIterator(openchainDB.BlockchainCF)
@@ -153,6 +169,11 @@ func (openchainDB *OpenchainDB) GetTxSetStateCFIterator() *gorocksdb.Iterator {
 	return openchainDB.GetIterator(openchainDB.TxSetStateCF)
 }
 
+// GetResetJournalCFIterator get iterator for column family - resetJournalCF
+func (openchainDB *OpenchainDB) GetResetJournalCFIterator() *gorocksdb.Iterator {
+	return openchainDB.GetIterator(openchainDB.ResetJournalCF)
+}
+
 // GetStateCFSnapshotIterator get iterator for column family - stateCF. This iterator
 // is based on a snapshot and should be used for long running scans, such as
 // reading the entire state. Remember to call iterator.Close() when you are done.
@@ -244,6 +265,8 @@ func (openchainDB *OpenchainDB) open() {
 	openchainDB.NoncesCF = cfHandlers[7]
 	openchainDB.IndexesCF = cfHandlers[8]
 	openchainDB.PersistCF = cfHandlers[9]
+	openchainDB.TxRWSetCF = cfHandlers[10]
+	openchainDB.ResetJournalCF = cfHandlers[11]
 }
 
 // Close releases all column family handles and closes rocksdb
@@ -257,6 +280,8 @@ func (openchainDB *OpenchainDB) close() {
 	openchainDB.NoncesCF.Destroy()
 	openchainDB.IndexesCF.Destroy()
 	openchainDB.PersistCF.Destroy()
+	openchainDB.TxRWSetCF.Destroy()
+	openchainDB.ResetJournalCF.Destroy()
 	openchainDB.DB.Close()
 }
 
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/op/go-logging"
 	"github.com/spf13/viper"
@@ -34,6 +35,8 @@ import (
 	"github.com/hyperledger/fabric/core/chaincode/platforms"
 	"github.com/hyperledger/fabric/core/container"
 	"github.com/hyperledger/fabric/core/crypto"
+	"github.com/hyperledger/fabric/core/crypto/txset"
+	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/core/util"
 	pb "github.com/hyperledger/fabric/protos"
@@ -42,6 +45,12 @@ import (
 
 var devopsLogger = logging.MustGetLogger("devops")
 
+const (
+	// defaultOrderedQueryTimeout is used when ledger.txSetState.orderedQueryTimeout is not configured
+	defaultOrderedQueryTimeout = 30 * time.Second
+	orderedQueryPollInterval   = 100 * time.Millisecond
+)
+
 // NewDevopsServer creates and returns a new Devops server instance.
 func NewDevopsServer(coord peer.MessageHandlerCoordinator) *Devops {
 	d := new(Devops)
@@ -400,7 +409,19 @@ func (d *Devops) issueOrExtend(ctx context.Context, txSetSpec *pb.TxSetSpec) (*p
 		}
 		return d.Query(ctx, trans.GetInvocationSpec())
 	}
+	if txSetSpec.Type == pb.TxSetSpec_CREATION && !txset.IsCreationVersion(txSetSpec.ConfidentialityProtocolVersion) {
+		return nil, fmt.Errorf("Unsupported confidentiality protocol version [%s]", txSetSpec.ConfidentialityProtocolVersion)
+	}
 	transSet := &pb.TransactionSet{Transactions: txSetSpec.TxSpecs, DefaultInx: txSetSpec.DefaultInx}
+	if policy := txSetSpec.GetMutationPolicy(); !policy.IsUnrestricted() {
+		if txSetSpec.Type != pb.TxSetSpec_CREATION {
+			return nil, errors.New("The mutation policy of a transactions set can only be set at its creation.")
+		}
+		if err := policy.Validate(); err != nil {
+			return nil, err
+		}
+		transSet.MutationPolicy = policy
+	}
 
 	var txID string
 	switch txSetSpec.Type {
@@ -427,6 +448,20 @@ func (d *Devops) issueOrExtend(ctx context.Context, txSetSpec *pb.TxSetSpec) (*p
 		Timestamp:   		  util.CreateUtcTimestamp(),
 		Nonce:		 		  txSetSpec.Metadata,
 		ConfidentialityLevel: pb.ConfidentialityLevel_CONFIDENTIAL,
+		ConfidentialityProtocolVersion: txSetSpec.ConfidentialityProtocolVersion,
+	}
+	if d.isSecurityEnabled && txSetSpec.SecureContext != "" {
+		// Sign the set so that the validators can record its creator
+		msg, err := pb.TransactionSetSigningBytes(transSet)
+		if err != nil {
+			return nil, err
+		}
+		inBlockTx.Cert, inBlockTx.Signature, err = d.signWithEnrollmentCert(txSetSpec.SecureContext, msg)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to sign the transactions set. Err: %s", err)
+		}
+	} else if !transSet.MutationPolicy.IsUnrestricted() {
+		return nil, errors.New("A mutation policy requires security to be enabled and a secure context.")
 	}
 	resp := d.coord.ExecuteTransaction(inBlockTx)
 	if resp.Status == pb.Response_FAILURE {
@@ -454,9 +489,79 @@ func (d *Devops) IssueSetExtension(ctx context.Context, txSetSpec *pb.TxSetSpec)
 
 // Mutate - Modifies the active transaction of a transactions set
 func (d *Devops) Mutate(ctx context.Context, mutantSpec *pb.MutantSpec) (*pb.Response, error) {
+	inBlockTx, err := d.createMutantTx(ctx, mutantSpec)
+	if err != nil {
+		return nil, err
+	}
+	resp := d.coord.ExecuteTransaction(inBlockTx)
+	if resp.Status == pb.Response_FAILURE {
+		err = fmt.Errorf(string(resp.Msg))
+	}
+	return resp, err
+}
+
+// SimulateMutation - Replays the blocks a mutation would change without submitting it. On success the message
+// of the response is a marshaled MutationSimulation. Only validating peers can simulate mutations, since the
+// replay executes the chaincodes
+func (d *Devops) SimulateMutation(ctx context.Context, mutantSpec *pb.MutantSpec) (*pb.Response, error) {
+	if !peer.ValidatorEnabled() {
+		return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte("Mutations can only be simulated by validating peers")}, nil
+	}
+	inBlockTx, err := d.createMutantTx(ctx, mutantSpec)
+	if err != nil {
+		return nil, err
+	}
+	simulation, err := chaincode.SimulateMutation(context.Background(), chaincode.DefaultChain, inBlockTx)
+	if err != nil {
+		return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(err.Error())}, nil
+	}
+	simulationBytes, err := proto.Marshal(simulation)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to marshal the mutation simulation (%s)", err)
+	}
+	return &pb.Response{Status: pb.Response_SUCCESS, Msg: simulationBytes}, nil
+}
+
+// createMutantTx creates the mutant transaction described by mutantSpec. If a secure context is given, the
+// transaction is signed with its enrollment certificate, or with a transaction certificate holding the
+// attributes of mutantSpec if any
+func (d *Devops) createMutantTx(ctx context.Context, mutantSpec *pb.MutantSpec) (*pb.InBlockTransaction, error) {
 	mutantTx := &pb.MutantTransaction{
-		TxSetID:    mutantSpec.TxSetID,
-		TxSetIndex: mutantSpec.Index,
+		TxSetID:             mutantSpec.TxSetID,
+		TxSetIndex:          mutantSpec.Index,
+		Signatures:          mutantSpec.Signatures,
+		AdditionalMutations: mutantSpec.AdditionalMutations,
+		AtBlock:             mutantSpec.AtBlock,
+		NotBefore:           mutantSpec.NotBefore,
+		CancelScheduled:     mutantSpec.CancelScheduled,
+	}
+
+	var cert, signature []byte
+	if d.isSecurityEnabled && mutantSpec.SecureContext != "" {
+		// The signature is bound to the current state of the sets
+		mutations := mutantTx.GetTxSetMutations()
+		nonces := make([]uint64, len(mutations))
+		for i, mutation := range mutations {
+			resp, err := d.QueryTxSetState(ctx, &pb.MutantSpec{TxSetID: mutation.TxSetID})
+			if err != nil {
+				return nil, fmt.Errorf("Unable to retrieve the state of the tx set %s to mutate (%s)", mutation.TxSetID, err)
+			}
+			txSetState, err := pb.UnmarshalTxSetStateValue(resp.Msg)
+			if err != nil {
+				return nil, err
+			}
+			nonces[i] = txSetState.Nonce
+		}
+		msg := pb.MutantSigningBytes(mutantTx, nonces)
+		var err error
+		if len(mutantSpec.Attributes) != 0 {
+			cert, signature, err = d.signWithTCert(mutantSpec.SecureContext, mutantSpec.Attributes, msg)
+		} else {
+			cert, signature, err = d.signWithEnrollmentCert(mutantSpec.SecureContext, msg)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Unable to sign the mutant transaction (%s)", err)
+		}
 	}
 
 	mutBytes, err := proto.Marshal(mutantTx)
@@ -474,24 +579,65 @@ func (d *Devops) Mutate(ctx context.Context, mutantSpec *pb.MutantSpec) (*pb.Res
 		Transaction: &pb.InBlockTransaction_MutantTransaction{MutantTransaction: mutantTx},
 		Txid:        hex.EncodeToString(util.ComputeCryptoHash(mutBytes)),
 		Timestamp:   util.CreateUtcTimestamp(),
+		Cert:        cert,
+		Signature:   signature,
 	}
-	resp := d.coord.ExecuteTransaction(inBlockTx)
-	if resp.Status == pb.Response_FAILURE {
-		err = fmt.Errorf(string(resp.Msg))
+	return inBlockTx, nil
+}
+
+// signWithEnrollmentCert signs msg with the enrollment certificate of the client logged in with secureContext.
+// It returns the certificate and the signature.
+func (d *Devops) signWithEnrollmentCert(secureContext string, msg []byte) ([]byte, []byte, error) {
+	sec, err := crypto.InitClient(secureContext, nil)
+	if err != nil {
+		return nil, nil, err
 	}
-	return resp, err
+	defer crypto.CloseClient(sec)
+
+	handler, err := sec.GetEnrollmentCertificateHandler()
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := handler.Sign(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return handler.GetCertificate(), signature, nil
+}
+
+// signWithTCert signs msg with the next transaction certificate of the client logged in with secureContext,
+// issued with the given attributes. It returns the certificate and the signature.
+func (d *Devops) signWithTCert(secureContext string, attributes []string, msg []byte) ([]byte, []byte, error) {
+	sec, err := crypto.InitClient(secureContext, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer crypto.CloseClient(sec)
+
+	handler, err := sec.GetTCertificateHandlerNext(attributes...)
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := handler.Sign(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return handler.GetCertificate(), signature, nil
 }
 
-func (d *Devops) createTxSetQueryTx(txSetID string) (*pb.InBlockTransaction, error) {
+// createTxSetQueryTx creates a tx set state query. If a secure context is given and privacy is enabled the
+// query is confidential: the returned key, which the validators receive encrypted, decrypts its result.
+func (d *Devops) createTxSetQueryTx(txSetID string, secureContext string, ordered bool) (*pb.InBlockTransaction, []byte, error) {
 
 	queryTx := &pb.TxSetStateQuery{
 		TxSetID: txSetID,
 		Timestamp: util.CreateUtcTimestamp(),
+		Ordered: ordered,
 	}
 
 	queryBytes, err := proto.Marshal(queryTx)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to marshal created query tx (%s).", err)
+		return nil, nil, fmt.Errorf("Unable to marshal created query tx (%s).", err)
 	}
 
 	inBlockTx := &pb.InBlockTransaction{
@@ -499,9 +645,53 @@ func (d *Devops) createTxSetQueryTx(txSetID string) (*pb.InBlockTransaction, err
 		Txid: hex.EncodeToString(util.ComputeCryptoHash(queryBytes)),
 		Timestamp: util.CreateUtcTimestamp(),
 	}
-	return inBlockTx, nil
+
+	var queryKey []byte
+	if d.isSecurityEnabled && secureContext != "" && viper.GetBool("security.privacy") {
+		sec, err := crypto.InitClient(secureContext, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer crypto.CloseClient(sec)
+		chainPubKey, err := sec.GetChainPublicKey()
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to get the chain public key (%s).", err)
+		}
+		queryKey, inBlockTx.Nonce, err = txset.NewQueryKey(chainPubKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		inBlockTx.ConfidentialityLevel = pb.ConfidentialityLevel_CONFIDENTIAL
+	}
+	return inBlockTx, queryKey, nil
+}
+
+// readTxSetState reads the state of a tx set from the committed state of the local ledger
+func readTxSetState(txSetID string, blockNr uint64, atBlock bool) (*pb.TxSetStateValue, error) {
+	lgr, err := ledger.GetLedger()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to retrieve the ledger: %s", err)
+	}
+	if atBlock {
+		history, err := lgr.GetTxSetStateHistory(txSetID, blockNr)
+		if err != nil {
+			return nil, err
+		}
+		return history.State, nil
+	}
+	txSetState, err := lgr.GetTxSetState(txSetID, true)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to retrieve the state for the tx set from the db. Tx Set Id: %s. Err: %s", txSetID, err)
+	}
+	if txSetState == nil {
+		return nil, fmt.Errorf("The state queried does not exists. Tx set id: %s", txSetID)
+	}
+	return txSetState, nil
 }
 
+// QueryTxSetState returns the state of a tx set. Validating peers read it from their committed ledger, the
+// other peers send the query to a validator. If the query is ordered it goes through the consensus, and the
+// state returned is the one of the set once the block containing the query is committed.
 func (d *Devops) QueryTxSetState(ctx context.Context, querySpec *pb.MutantSpec) (*pb.Response, error) {
 	var err error
 
@@ -509,8 +699,16 @@ func (d *Devops) QueryTxSetState(ctx context.Context, querySpec *pb.MutantSpec)
 		return nil, errors.New("tx set id not given for query tx set state tx")
 	}
 
+	if querySpec.Ordered {
+		return d.orderedTxSetStateQuery(querySpec)
+	}
+
+	if peer.ValidatorEnabled() {
+		return txSetStateResponse(readTxSetState(querySpec.TxSetID, 0, false))
+	}
+
 	// Now create the Transactions message and send to Peer.
-	transaction, err := d.createTxSetQueryTx(querySpec.TxSetID)
+	transaction, queryKey, err := d.createTxSetQueryTx(querySpec.TxSetID, querySpec.SecureContext, false)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to create tx set state query transaction for tx id: %s, err: %s", querySpec.TxSetID, err)
 	}
@@ -521,13 +719,131 @@ func (d *Devops) QueryTxSetState(ctx context.Context, querySpec *pb.MutantSpec)
 	resp := d.coord.ExecuteTransaction(transaction)
 	if resp.Status == pb.Response_FAILURE {
 		err = fmt.Errorf(string(resp.Msg))
+	} else if queryKey != nil {
+		if resp.Msg, err = txset.DecryptQueryResult(queryKey, transaction.Txid, resp.Msg); err != nil {
+			devopsLogger.Errorf("Failed decrypting tx set state query result: %s", err)
+			err = fmt.Errorf("Unable to decrypt the tx set state (%s)", err)
+		}
 	}
 
-	// TODO provide encryption for txSetState transactions
-
 	return resp, err
 }
 
+// orderedTxSetStateQuery sends a tx set state query through the consensus and waits for the block
+// containing it to be committed.
+func (d *Devops) orderedTxSetStateQuery(querySpec *pb.MutantSpec) (*pb.Response, error) {
+	if !peer.ValidatorEnabled() {
+		return nil, errors.New("Ordered tx set state queries are only served by validating peers")
+	}
+	// A query for a set that does not exist would be rejected and never be committed
+	if _, err := readTxSetState(querySpec.TxSetID, 0, false); err != nil {
+		return txSetStateResponse(nil, err)
+	}
+
+	transaction, _, err := d.createTxSetQueryTx(querySpec.TxSetID, "", true)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create tx set state query transaction for tx id: %s, err: %s", querySpec.TxSetID, err)
+	}
+	if devopsLogger.IsEnabledFor(logging.DEBUG) {
+		devopsLogger.Debugf("Sending ordered tx set state query transaction (%s) to the consensus", transaction.Txid)
+	}
+	resp := d.coord.ExecuteTransaction(transaction)
+	if resp.Status == pb.Response_FAILURE {
+		return resp, fmt.Errorf(string(resp.Msg))
+	}
+
+	lgr, err := ledger.GetLedger()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to retrieve the ledger: %s", err)
+	}
+	timeout := viper.GetDuration("ledger.txSetState.orderedQueryTimeout")
+	if timeout <= 0 {
+		timeout = defaultOrderedQueryTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		blockNr, err := lgr.GetTransactionBlockNumber(transaction.Txid)
+		if err == nil {
+			return txSetStateResponse(readTxSetState(querySpec.TxSetID, blockNr, true))
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("The ordered tx set state query %s was not committed within %s", transaction.Txid, timeout)
+		}
+		time.Sleep(orderedQueryPollInterval)
+	}
+}
+
+// txSetStateResponse builds the response to a tx set state query
+func txSetStateResponse(txSetState *pb.TxSetStateValue, err error) (*pb.Response, error) {
+	if err != nil {
+		return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(err.Error())}, err
+	}
+	stateBytes, err := proto.Marshal(txSetState)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to marshal the retrieved txSetState. Retrieved state: %#v", txSetState)
+	}
+	return &pb.Response{Status: pb.Response_SUCCESS, Msg: stateBytes}, nil
+}
+
+// QueryTxSetStateHistory rebuilds the state of a tx set at a block height from the local ledger
+func (d *Devops) QueryTxSetStateHistory(ctx context.Context, historySpec *pb.TxSetHistorySpec) (*pb.Response, error) {
+	if historySpec.TxSetID == "" {
+		return nil, errors.New("tx set id not given for query tx set state history")
+	}
+
+	ledger, err := ledger.GetLedger()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to retrieve the ledger: %s", err)
+	}
+	blockNr := historySpec.BlockNr
+	if blockNr == 0 {
+		blockNr = ledger.GetBlockchainSize() - 1
+	}
+	history, err := ledger.GetTxSetStateHistory(historySpec.TxSetID, blockNr)
+	if err != nil {
+		return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(err.Error())}, nil
+	}
+	historyBytes, err := proto.Marshal(history)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to marshal the tx set state history: %s", err)
+	}
+	return &pb.Response{Status: pb.Response_SUCCESS, Msg: historyBytes}, nil
+}
+
+// Revoke asks the member services to revoke a certificate on behalf of the user logged in with the supplied secure context
+func (d *Devops) Revoke(ctx context.Context, revocationSpec *pb.RevocationSpec) (*pb.Response, error) {
+	if !d.isSecurityEnabled {
+		devopsLogger.Warning("Security NOT enabled")
+		return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte("Security NOT enabled")}, nil
+	}
+	if revocationSpec.SecureContext == "" {
+		return nil, errors.New("secure context not given for revocation")
+	}
+	if revocationSpec.Type == pb.RevocationSpec_TRANSACTION && revocationSpec.Cert == nil {
+		return nil, errors.New("transaction certificate not given for revocation")
+	}
+
+	sec, err := crypto.InitClient(revocationSpec.SecureContext, nil)
+	if err != nil {
+		return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(err.Error())}, nil
+	}
+	defer crypto.CloseClient(sec)
+
+	switch revocationSpec.Type {
+	case pb.RevocationSpec_ENROLLMENT:
+		err = sec.RevokeEnrollmentCertificate(revocationSpec.Cert)
+	case pb.RevocationSpec_TRANSACTION:
+		err = sec.RevokeTCertificate(revocationSpec.Cert)
+	default:
+		err = fmt.Errorf("unknown certificate type %s", revocationSpec.Type)
+	}
+	if err != nil {
+		devopsLogger.Errorf("Failed revoking certificate for %s: %s", revocationSpec.SecureContext, err)
+		return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(err.Error())}, nil
+	}
+	return &pb.Response{Status: pb.Response_SUCCESS}, nil
+}
+
 // CheckSpec to see if chaincode resides within current package capture for language.
 func CheckSpec(spec *pb.ChaincodeSpec) error {
 	// Don't allow nil value
@@ -92,3 +92,18 @@ func TestDevops_Spec_NoVersion(t *testing.T) {
 	t.Logf("Deploy result = %s, err = %s", buildResult, err)
 	//performHandshake(t, peerClientConn)
 }
+
+func TestDevops_IssueTxSet_UnsupportedVersion(t *testing.T) {
+	devopsServer := NewDevopsServer(nil)
+
+	txSetSpec := &pb.TxSetSpec{
+		Type:                           pb.TxSetSpec_CREATION,
+		TxSpecs:                        [][]byte{[]byte("tx1"), []byte("tx2")},
+		ConfidentialityProtocolVersion: "0.1",
+	}
+	_, err := devopsServer.IssueTxSet(context.Background(), txSetSpec)
+	if err == nil {
+		t.Fatal("Expected an error when issuing a set with an unsupported confidentiality protocol version")
+	}
+	t.Logf("Got expected err: %s", err)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/spf13/viper"
+	"github.com/tecbot/gorocksdb"
+)
+
+// The state at every block is kept in blockStateCF, since a mutation may replay the blocks from any block
+// that introduced a set which can still be mutated. The tx set garbage collection only discards it before
+// the oldest mutable set, which never happens when the sets are mutable forever. The compaction bounds the
+// size of the older states independently: it replaces the checkpoints preceding the last sparse checkpoint
+// by forward deltas, keeping a checkpoint every sparseCheckpointInterval blocks only. The progress of the
+// compaction is recorded in persistCF, so each run only reads the blocks committed since the previous one.
+
+const defaultBlockStateCompactionInterval = 10 * time.Minute
+
+// defaultSparseCheckpointsRatio is the default number of regular checkpoint intervals between two sparse checkpoints
+const defaultSparseCheckpointsRatio = 100
+
+var blockStateCompactionKey = []byte("blockStateCompaction")
+
+type blockStateCompactionConfig struct {
+	once           sync.Once
+	sparseInterval uint64
+	interval       time.Duration
+}
+
+var compactionConfig blockStateCompactionConfig
+
+func getBlockStateCompactionConfig(checkpointInterval uint64) *blockStateCompactionConfig {
+	compactionConfig.once.Do(func() {
+		compactionConfig.sparseInterval = checkpointInterval * defaultSparseCheckpointsRatio
+		if viper.IsSet("ledger.state.sparseCheckpointInterval") {
+			sparseInterval := viper.GetInt("ledger.state.sparseCheckpointInterval")
+			if sparseInterval < 0 || uint64(sparseInterval)%checkpointInterval != 0 {
+				panic(fmt.Errorf("The number of blocks between two sparse state checkpoints must be a multiple of blockStateCheckpointInterval (%d), or 0. Current value is %d.", checkpointInterval, sparseInterval))
+			}
+			compactionConfig.sparseInterval = uint64(sparseInterval)
+		}
+		compactionConfig.interval = viper.GetDuration("ledger.state.blockStateCompactionInterval")
+		if compactionConfig.interval <= 0 {
+			compactionConfig.interval = defaultBlockStateCompactionInterval
+		}
+		ledgerLogger.Infof("Block state compaction configuration loaded. sparseCheckpointInterval=[%d], blockStateCompactionInterval=[%s]",
+			compactionConfig.sparseInterval, compactionConfig.interval)
+	})
+	return &compactionConfig
+}
+
+// StartBlockStateCompaction starts compacting in the background the states of the older blocks.
+// Nothing is started if sparseCheckpointInterval is 0.
+func (ledger *Ledger) StartBlockStateCompaction() {
+	conf := getBlockStateCompactionConfig(ledger.chaincodeState.GetBlockStateCheckpointInterval())
+	if conf.sparseInterval == 0 {
+		ledgerLogger.Info("A checkpoint of the state is kept at every blockStateCheckpointInterval blocks, the block state compaction is disabled.")
+		return
+	}
+	go func() {
+		for {
+			time.Sleep(conf.interval)
+			if _, err := ledger.CompactBlockStates(); err != nil {
+				ledgerLogger.Errorf("Error while compacting the states of the older blocks: %s", err)
+			}
+		}
+	}()
+}
+
+// CompactBlockStates replaces by forward deltas the checkpoints of the state committed since the previous run
+// and preceding the last sparse checkpoint, except the sparse ones. It returns the number of checkpoints replaced
+func (ledger *Ledger) CompactBlockStates() (uint64, error) {
+	sparseInterval := getBlockStateCompactionConfig(ledger.chaincodeState.GetBlockStateCheckpointInterval()).sparseInterval
+	if sparseInterval == 0 {
+		return 0, nil
+	}
+	ledger.blockStateLock.Lock()
+	defer ledger.blockStateLock.Unlock()
+	journal, err := fetchResetJournal()
+	if err != nil {
+		return 0, err
+	}
+	if journal != nil {
+		// The blocks are being replayed, try again once the reset is over
+		ledgerLogger.Debugf("Skipping the block state compaction, a state reset is ongoing: %s", journal)
+		return 0, nil
+	}
+	from, err := fetchBlockStateCompactionProgress()
+	if err != nil {
+		return 0, err
+	}
+	to := ledger.GetBlockchainSize() / sparseInterval * sparseInterval
+	if to <= from {
+		return 0, nil
+	}
+	ledgerLogger.Debugf("Compacting the block states from block [%d] to block [%d]", from, to)
+
+	writeBatch := gorocksdb.NewWriteBatch()
+	defer writeBatch.Destroy()
+	entries, reclaimed, err := ledger.chaincodeState.CompactBlockStateCheckpoints(writeBatch, from, to, sparseInterval)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to compact the block states from block %d to block %d. (%s)", from, to, err)
+	}
+	writeBatch.PutCF(db.GetDBHandle().PersistCF, blockStateCompactionKey, encodeUint64(to))
+	if err := writeBatchToDB(writeBatch); err != nil {
+		return 0, fmt.Errorf("Unable to write the compacted block states. (%s)", err)
+	}
+	ledgerLogger.Infof("Replaced %d state checkpoints preceding block [%d] by forward deltas, %d bytes reclaimed", entries, to, reclaimed)
+	return entries, nil
+}
+
+// rewindBlockStateCompaction makes the next compaction start again from the sparse checkpoint preceding
+// blockNumber, whose following blocks are replayed and store their checkpoints at the regular interval again
+func (ledger *Ledger) rewindBlockStateCompaction(blockNumber uint64) error {
+	sparseInterval := getBlockStateCompactionConfig(ledger.chaincodeState.GetBlockStateCheckpointInterval()).sparseInterval
+	if sparseInterval == 0 {
+		return nil
+	}
+	progress, err := fetchBlockStateCompactionProgress()
+	if err != nil {
+		return err
+	}
+	if rewound := blockNumber / sparseInterval * sparseInterval; rewound < progress {
+		return db.GetDBHandle().Put(db.GetDBHandle().PersistCF, blockStateCompactionKey, encodeUint64(rewound))
+	}
+	return nil
+}
+
+// fetchBlockStateCompactionProgress returns the block up to which the block states are compacted
+func fetchBlockStateCompactionProgress() (uint64, error) {
+	progressBytes, err := db.GetDBHandle().Get(db.GetDBHandle().PersistCF, blockStateCompactionKey)
+	if err != nil {
+		return 0, err
+	}
+	if progressBytes == nil {
+		return 0, nil
+	}
+	return decodeToUint64(progressBytes), nil
+}
@@ -22,6 +22,7 @@ import (
 	"strconv"
 	"errors"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/util"
 	"github.com/hyperledger/fabric/protos"
@@ -36,6 +37,7 @@ type blockchain struct {
 	sizeReset		   uint64
 	isResetting		   bool
 	previousBlockHash  []byte
+	previousBlockTime  *timestamp.Timestamp
 	indexer            blockchainIndexer
 	lastProcessedBlock *lastProcessedBlock
 }
@@ -53,7 +55,7 @@ func newBlockchain() (*blockchain, error) {
 	if err != nil {
 		return nil, err
 	}
-	blockchain := &blockchain{0, 0, false, nil, nil, nil}
+	blockchain := &blockchain{0, 0, false, nil, nil, nil, nil}
 	blockchain.size = size
 	if size > 0 {
 		previousBlock, err := fetchBlockFromDB(size - 1)
@@ -65,6 +67,7 @@ func newBlockchain() (*blockchain, error) {
 			return nil, err
 		}
 		blockchain.previousBlockHash = previousBlockHash
+		blockchain.previousBlockTime = previousBlock.Timestamp
 	}
 
 	err = blockchain.startIndexer()
@@ -186,6 +189,11 @@ func (blockchain *blockchain) endReset() error {
 	return nil
 }
 
+func (blockchain *blockchain) abortReset() {
+	blockchain.isResetting = false
+	blockchain.sizeReset = 0
+}
+
 // getTransactionByBlockHash get a transaction identified by block hash and index within the block
 func (blockchain *blockchain) getTransactionByBlockHash(blockHash []byte, txIndex uint64) (*protos.InBlockTransaction, error) {
 	block, err := blockchain.getBlockByHash(blockHash)
@@ -220,13 +228,34 @@ func (blockchain *blockchain) getBlockchainInfoForBlock(height uint64, block *pr
 	return info
 }
 
+// buildBlock links the block to the chain. The timestamp of the block is the latest timestamp of its transactions
+// and never earlier than the one of the previous block: it is derived from the chain only, so that every validator
+// stamps the block with the same time. The consensus rejects the transactions stamped too far ahead of the validators
+// clocks, see peer.validator.consensus.maxTimestampSkew
 func (blockchain *blockchain) buildBlock(block *protos.Block, chaincodeStHash, txSetStHash []byte) *protos.Block {
 	block.SetPreviousBlockHash(blockchain.previousBlockHash)
 	block.StateHash = chaincodeStHash
 	block.TxSetStateHash = txSetStHash
+	block.Timestamp = blockchain.previousBlockTime
+	for _, tx := range block.Transactions {
+		if isLaterTimestamp(tx.GetTimestamp(), block.Timestamp) {
+			block.Timestamp = tx.GetTimestamp()
+		}
+	}
 	return block
 }
 
+// isLaterTimestamp returns true if ts is later than other, a nil timestamp is earlier than any other
+func isLaterTimestamp(ts, other *timestamp.Timestamp) bool {
+	if ts == nil {
+		return false
+	}
+	if other == nil {
+		return true
+	}
+	return ts.Seconds > other.Seconds || (ts.Seconds == other.Seconds && ts.Nanos > other.Nanos)
+}
+
 func (blockchain *blockchain) addPersistenceChangesForNewBlock(ctx context.Context,
 	block *protos.Block, chaincodeStHash, txSetStHash []byte, writeBatch *gorocksdb.WriteBatch) (uint64, error) {
 	block = blockchain.buildBlock(block, chaincodeStHash, txSetStHash)
@@ -257,6 +286,7 @@ func (blockchain *blockchain) blockPersistenceStatus(success bool) {
 	if success {
 		blockchain.size++
 		blockchain.previousBlockHash = blockchain.lastProcessedBlock.blockHash
+		blockchain.previousBlockTime = blockchain.lastProcessedBlock.block.Timestamp
 		if !blockchain.indexer.isSynchronous() {
 			writeBatch := gorocksdb.NewWriteBatch()
 			defer writeBatch.Destroy()
@@ -288,6 +318,7 @@ func (blockchain *blockchain) persistRawBlock(block *protos.Block, blockNumber u
 		writeBatch.PutCF(db.GetDBHandle().BlockchainCF, blockCountKey, sizeBytes)
 		blockchain.size = blockNumber + 1
 		blockchain.previousBlockHash = blockHash
+		blockchain.previousBlockTime = block.Timestamp
 	}
 
 	if blockchain.indexer.isSynchronous() {
@@ -164,6 +164,9 @@ func (noop *NoopIndexer) fetchBlockNumberByBlockHash(blockHash []byte) (uint64,
 func (noop *NoopIndexer) fetchTransactionIndexByID(txID string) (uint64, uint64, error) {
 	return 0, 0, nil
 }
+func (noop *NoopIndexer) fetchTransactionIndexMap(txID string) (map[uint64]uint64, error) {
+	return nil, nil
+}
 func (noop *NoopIndexer) stop() {
 }
 
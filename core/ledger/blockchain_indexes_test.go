@@ -146,12 +146,12 @@ func testIndexesGetTransactionByID(t *testing.T) {
 	defer func() { testBlockchainWrapper.blockchain.indexer.stop() }()
 	tx1, uuid1 := buildTestTx(t)
 	tx2, uuid2 := buildTestTx(t)
-	block1 := protos.NewBlock([]*protos.Transaction{tx1, tx2}, nil)
+	block1 := protos.NewBlock([]*protos.InBlockTransaction{tx1, tx2}, nil)
 	testBlockchainWrapper.addNewBlock(block1, []byte("stateHash1"))
 
 	tx3, uuid3 := buildTestTx(t)
 	tx4, uuid4 := buildTestTx(t)
-	block2 := protos.NewBlock([]*protos.Transaction{tx3, tx4}, nil)
+	block2 := protos.NewBlock([]*protos.InBlockTransaction{tx3, tx4}, nil)
 	testBlockchainWrapper.addNewBlock(block2, []byte("stateHash2"))
 
 	testutil.AssertEquals(t, testBlockchainWrapper.getTransactionByID(uuid1), tx1)
@@ -20,9 +20,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/core/ledger/testutil"
 	"github.com/hyperledger/fabric/core/util"
 	"github.com/hyperledger/fabric/protos"
+	"github.com/tecbot/gorocksdb"
+	"golang.org/x/net/context"
 )
 
 func TestBlockchain_InfoNoBlock(t *testing.T) {
@@ -65,7 +68,7 @@ func TestBlockChain_SingleBlock(t *testing.T) {
 	testutil.AssertNoError(t, err, "Failed to create new chaincode Deployment Transaction")
 	t.Logf("New chaincode tx: %v", newChaincodeTx)
 
-	block1 := protos.NewBlock([]*protos.Transaction{newChaincodeTx}, nil)
+	block1 := protos.NewBlock([]*protos.InBlockTransaction{newTestInBlockTx(t, newChaincodeTx)}, nil)
 	blockNumber := blockchainTestWrapper.addNewBlock(block1, []byte("stateHash1"))
 	t.Logf("New chain: %v", blockchain)
 	testutil.AssertEquals(t, blockNumber, uint64(0))
@@ -137,3 +140,41 @@ func TestBlockchainBlockLedgerCommitTimestamp(t *testing.T) {
 		t.Fatal("Expected block time to be after start time")
 	}
 }
+
+func TestBlockchainBlockTimestamp(t *testing.T) {
+	testDBWrapper.CleanDB(t)
+	blockchainTestWrapper := newTestBlockchainWrapper(t)
+	blockchain := blockchainTestWrapper.blockchain
+	addBlock := func(timestamps ...*timestamp.Timestamp) *protos.Block {
+		var txs []*protos.InBlockTransaction
+		for _, ts := range timestamps {
+			txs = append(txs, &protos.InBlockTransaction{Txid: testutil.GenerateID(t), Timestamp: ts})
+		}
+		writeBatch := gorocksdb.NewWriteBatch()
+		defer writeBatch.Destroy()
+		_, err := blockchain.addPersistenceChangesForNewBlock(context.TODO(), protos.NewBlock(txs, nil), []byte("stateHash"), []byte("txSetStateHash"), writeBatch)
+		testutil.AssertNoError(t, err, "Error while adding a new block")
+		testDBWrapper.WriteToDB(t, writeBatch)
+		blockchain.blockPersistenceStatus(true)
+		return blockchainTestWrapper.getLastBlock()
+	}
+
+	// A block is stamped with the latest timestamp of its transactions
+	testutil.AssertNil(t, addBlock().Timestamp)
+	block := addBlock(&timestamp.Timestamp{Seconds: 10}, &timestamp.Timestamp{Seconds: 20, Nanos: 1}, nil)
+	testutil.AssertEquals(t, block.Timestamp, &timestamp.Timestamp{Seconds: 20, Nanos: 1})
+	testutil.AssertEquals(t, blockchain.previousBlockTime, block.Timestamp)
+
+	// but never with a time earlier than the one of the previous block
+	block = addBlock(&timestamp.Timestamp{Seconds: 15})
+	testutil.AssertEquals(t, block.Timestamp, &timestamp.Timestamp{Seconds: 20, Nanos: 1})
+	block = addBlock()
+	testutil.AssertEquals(t, block.Timestamp, &timestamp.Timestamp{Seconds: 20, Nanos: 1})
+	block = addBlock(&timestamp.Timestamp{Seconds: 30})
+	testutil.AssertEquals(t, block.Timestamp, &timestamp.Timestamp{Seconds: 30})
+
+	// The time of the last block is restored on restart
+	blockchain, err := newBlockchain()
+	testutil.AssertNoError(t, err, "Error while getting handle to chain")
+	testutil.AssertEquals(t, blockchain.previousBlockTime, &timestamp.Timestamp{Seconds: 30})
+}
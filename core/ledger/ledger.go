@@ -23,6 +23,7 @@ import (
 	"sync"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/ledger/state"
 	"github.com/hyperledger/fabric/core/ledger/state/chaincodest"
@@ -54,6 +55,8 @@ const (
 	ErrorTypeResourceNotFound = ErrorType("ResourceNotFound")
 	//ErrorTypeBlockNotFound used to indicate if a block is not found when looked up by it's hash
 	ErrorTypeBlockNotFound = ErrorType("ErrorTypeBlockNotFound")
+	//ErrorTypeTxSetConflict used to indicate that the state of a transactions set was already changed in the current block
+	ErrorTypeTxSetConflict = ErrorType("TxSetConflict")
 )
 
 //Error can be used for throwing an error from ledger code.
@@ -89,6 +92,19 @@ type Ledger struct {
 	chaincodeState *chaincodest.State
 	txSetState     *txsetst.TxSetState
 	currentID      interface{}
+	resetJournal   *resetJournal
+	replayReports  []*protos.BlockReplayReport
+	// mutations requested by the chaincodes, see QueueMutation
+	pendingMutations  map[string][]*protos.InBlockTransaction
+	queuedMutations   []*protos.InBlockTransaction
+	mutationsDequeued bool
+	// IDs of the sets with a scheduled mutation, nil until read, see GetDueScheduledTxSetIDs
+	scheduledTxSets map[string]bool
+	// the ledger of a simulation keeps all its changes in memory, see NewSimulation
+	simulation bool
+	// serializes the rewrites of the states of the older blocks by the garbage collection, the
+	// compaction and the start of a reset
+	blockStateLock sync.Mutex
 }
 
 var ledger *Ledger
@@ -112,7 +128,11 @@ func GetNewLedger() (*Ledger, error) {
 
 	chaincodeState := chaincodest.NewState()
 	txSetState := txsetst.NewTxSetState()
-	return &Ledger{blockchain, chaincodeState, txSetState, nil}, nil
+	ledger := &Ledger{blockchain: blockchain, chaincodeState: chaincodeState, txSetState: txSetState}
+	if err := ledger.recoverInterruptedReset(); err != nil {
+		return nil, err
+	}
+	return ledger, nil
 }
 
 /////////////////// Transaction-batch related methods ///////////////////////////////
@@ -156,6 +176,9 @@ func (ledger *Ledger) GetTXBatchPreviewBlockInfo(id interface{},
 // This function returns successfully iff the transactions details and state changes (that
 // may have happened during execution of this transaction-batch) have been committed to permanent storage
 func (ledger *Ledger) CommitTxBatch(id interface{}, transactions []*protos.InBlockTransaction, transactionResults []*protos.TransactionResult, metadata []byte) error {
+	if ledger.simulation {
+		return errors.New("The batches of a simulation cannot be committed")
+	}
 	err := ledger.checkValidIDCommitORRollback(id)
 	if err != nil {
 		return err
@@ -182,31 +205,17 @@ func (ledger *Ledger) CommitTxBatch(id interface{}, transactions []*protos.InBlo
 
 	var numErroneusTxs = 0
 	if transactionResults != nil {
-		ccEvents = make([]*protos.ChaincodeEvent, len(transactionResults))
 		for i := 0; i < len(transactionResults); i++ {
-			if transactionResults[i].ChaincodeEvent != nil {
-				ccEvents[i] = transactionResults[i].ChaincodeEvent
-			} else {
-				//We need the index so we can map the chaincode
-				//event to the transaction that generated it.
-				//Hence need an entry for cc event even if one
-				//wasn't generated for the transaction. We cannot
-				//use a nil cc event as protobuf does not like
-				//elements of a repeated array to be nil.
-				//
-				//We should discard empty events without chaincode
-				//ID when sending out events.
-				ccEvents[i] = &protos.ChaincodeEvent{}
-			}
 			if transactionResults[i].ErrorCode != 0 {
 				ledgerLogger.Warningf("Transaction with id %s contained errors: %s", transactionResults[i].Txid, transactionResults[i].Error)
 				numErroneusTxs++
 			}
 		}
+		ccEvents = blockChaincodeEvents(transactions, transactionResults)
 	}
 
 	//store chaincode events directly in NonHashData. This will likely change in New Consensus where we can move them to Transaction
-	block.NonHashData = &protos.NonHashData{ChaincodeEvents: ccEvents}
+	block.NonHashData = &protos.NonHashData{ChaincodeEvents: ccEvents, ReplayReports: ledger.replayReports}
 	newBlockNumber, err := ledger.blockchain.addPersistenceChangesForNewBlock(context.TODO(), block, chaincodeStHash, txSetStHash, writeBatch)
 	if err != nil {
 		ledger.resetForNextTxGroup(false)
@@ -215,6 +224,16 @@ func (ledger *Ledger) CommitTxBatch(id interface{}, transactions []*protos.InBlo
 	}
 	ledger.chaincodeState.AddChangesForPersistence(newBlockNumber, writeBatch)
 	ledger.txSetState.AddChangesForPersistence(newBlockNumber, writeBatch)
+	txSetEvents := buildTxSetEvents(ledger.txSetState.GetTxSetStateDelta(), block, newBlockNumber)
+	if ledger.resetJournal != nil {
+		// The batch carrying the mutations is committed together with the replayed state
+		err = addResetJournalDeletionToWriteBatch(writeBatch)
+		if err != nil {
+			ledger.resetForNextTxGroup(false)
+			ledger.blockchain.blockPersistenceStatus(false)
+			return err
+		}
+	}
 	opt := gorocksdb.NewDefaultWriteOptions()
 	defer opt.Destroy()
 	dbErr := db.GetDBHandle().DB.Write(opt, writeBatch)
@@ -224,6 +243,8 @@ func (ledger *Ledger) CommitTxBatch(id interface{}, transactions []*protos.InBlo
 		return dbErr
 	}
 
+	ledger.resetJournal = nil
+	ledger.updateScheduledTxSets(ledger.txSetState.GetTxSetStateDelta())
 	ledger.resetForNextTxGroup(true)
 	ledger.blockchain.blockPersistenceStatus(true)
 
@@ -232,12 +253,46 @@ func (ledger *Ledger) CommitTxBatch(id interface{}, transactions []*protos.InBlo
 	//send chaincode events from transaction results
 	sendChaincodeEvents(transactionResults)
 
+	sendReplayEvents(block.NonHashData.ReplayReports)
+
+	sendTxSetEvents(txSetEvents)
+
 	if numErroneusTxs != 0 {
 		ledgerLogger.Debug("There were some erroneous transactions. We need to send a 'TX rejected' message here.")
 	}
 	return nil
 }
 
+// blockChaincodeEvents returns the chaincode events to store in a block, at the position of the transaction
+// which emitted them in transactions. The results are matched by transaction ID: they also cover the failed
+// transactions, which are not part of the block, and the block can contain transactions without a result
+func blockChaincodeEvents(transactions []*protos.InBlockTransaction, transactionResults []*protos.TransactionResult) []*protos.ChaincodeEvent {
+	txEvents := make(map[string]*protos.ChaincodeEvent)
+	for _, result := range transactionResults {
+		if result.ErrorCode == 0 && result.ChaincodeEvent != nil {
+			txEvents[result.Txid] = result.ChaincodeEvent
+		}
+	}
+	ccEvents := make([]*protos.ChaincodeEvent, len(transactions))
+	for i, tx := range transactions {
+		if event, ok := txEvents[tx.Txid]; ok {
+			ccEvents[i] = event
+		} else {
+			//We need the index so we can map the chaincode
+			//event to the transaction that generated it.
+			//Hence need an entry for cc event even if one
+			//wasn't generated for the transaction. We cannot
+			//use a nil cc event as protobuf does not like
+			//elements of a repeated array to be nil.
+			//
+			//We should discard empty events without chaincode
+			//ID when sending out events.
+			ccEvents[i] = &protos.ChaincodeEvent{}
+		}
+	}
+	return ccEvents
+}
+
 // CommitResetTxBatch - gets invoked when the current transaction-batch needs to be committed
 // This function returns successfully iff the transactions details and state changes (that
 // may have happened during execution of this transaction-batch) have been committed to permanent storage
@@ -246,9 +301,24 @@ func (ledger *Ledger) CommitResetTxBatch() error {
 		return fmt.Errorf("Cannot commit a reset tx batch bacause the blockchain is not in a reset status.")
 	}
 
+	if ledger.simulation {
+		if err := ledger.chaincodeState.CommitInMemory(); err != nil {
+			return err
+		}
+		return ledger.blockchain.advanceResetBlock()
+	}
+
+	blockNumber := ledger.GetCurrentBlockEx()
 	writeBatch := gorocksdb.NewWriteBatch()
 	defer writeBatch.Destroy()
-	ledger.chaincodeState.AddChangesForPersistence(ledger.GetCurrentBlockEx(), writeBatch)
+	// The original data of the block is saved in the journal and the progress recorded together with the new one
+	err := addResetBackupsToWriteBatch(blockNumber, writeBatch)
+	if err != nil {
+		return err
+	}
+	ledger.resetJournal.nextBlock = blockNumber + 1
+	ledger.resetJournal.addToWriteBatch(writeBatch)
+	ledger.chaincodeState.AddChangesForPersistence(blockNumber, writeBatch)
 	opt := gorocksdb.NewDefaultWriteOptions()
 	defer opt.Destroy()
 	dbErr := db.GetDBHandle().DB.Write(opt, writeBatch)
@@ -257,6 +327,8 @@ func (ledger *Ledger) CommitResetTxBatch() error {
 		ledger.blockchain.blockPersistenceStatus(false)
 		return dbErr
 	}
+	// Only the chaincode state is cleared, the tx set state holds the mutations of the ongoing batch
+	ledger.chaincodeState.ClearInMemoryChanges(true)
 
 	return ledger.blockchain.advanceResetBlock()
 }
@@ -271,7 +343,8 @@ func (ledger *Ledger) RollbackTxBatch(id interface{}) error {
 		return err
 	}
 	ledger.resetForNextTxGroup(false)
-	return nil
+	// The mutations are discarded, so must be the replayed state
+	return ledger.RollbackReset()
 }
 
 // ChainTxBegin - Marks the begin of a new transaction in the ongoing batch
@@ -279,15 +352,54 @@ func (ledger *Ledger) ChainTxBegin(txID string) {
 	ledger.chaincodeState.TxBegin(txID)
 }
 
+// ChainTxBeginForSet - Marks the begin of a new transaction executed as the default transaction of
+// the transactions set txSetID in the ongoing batch
+func (ledger *Ledger) ChainTxBeginForSet(txSetID string, txID string) {
+	ledger.chaincodeState.TxBeginForSet(txSetID, txID)
+}
+
+// ReplayChainTx - Applies the changes recorded in the read/write set of a transaction as a new
+// transaction in the ongoing batch, without executing it again
+func (ledger *Ledger) ReplayChainTx(rwSet *chstatemgmt.TxReadWriteSet) error {
+	return ledger.chaincodeState.ReplayTx(rwSet)
+}
+
+// GetTxReadWriteSet - returns the read/write set recorded in the ongoing batch for the default
+// transaction of the transactions set txSetID
+func (ledger *Ledger) GetTxReadWriteSet(txSetID string) *chstatemgmt.TxReadWriteSet {
+	return ledger.chaincodeState.GetTxReadWriteSet(txSetID)
+}
+
+// GetTxReadWriteSets - returns the read/write sets of the transactions of the given block.
+// nil is returned if they were not recorded
+func (ledger *Ledger) GetTxReadWriteSets(blockNumber uint64) ([]*chstatemgmt.TxReadWriteSet, error) {
+	return ledger.chaincodeState.FetchTxReadWriteSetsFromDB(blockNumber)
+}
+
 // SetTxBegin - Marks the begin of a new tx set transaction in the ongoing batch
 func (ledger *Ledger) SetTxBegin(txID string) {
 	ledger.txSetState.TxBegin(txID)
 }
 
 // ChainTxFinished - Marks the finish of the on-going transaction.
-// If txSuccessful is false, the state changes made by the transaction are discarded
+// If txSuccessful is false, the state changes and the mutations requested by the transaction are discarded
 func (ledger *Ledger) ChainTxFinished(txID string, txSuccessful bool) {
 	ledger.chaincodeState.TxFinish(txID, txSuccessful)
+	ledger.finishQueuedMutations(txID, txSuccessful)
+}
+
+// BeginConcurrentChainTxs - Lets the chaincode transactions begun until EndConcurrentChainTxs be executed
+// concurrently. They must be independent and access the state through their transaction ID (GetStateForTx,
+// SetStateForTx...)
+func (ledger *Ledger) BeginConcurrentChainTxs() {
+	ledger.chaincodeState.BeginConcurrentTxs()
+}
+
+// EndConcurrentChainTxs - Ends the concurrent chaincode transactions. The changes of the transactions executed
+// as the default transactions of the sets txSetIDs are merged in the ongoing batch in the order of txSetIDs,
+// the changes of the other transactions are discarded
+func (ledger *Ledger) EndConcurrentChainTxs(txSetIDs []string) {
+	ledger.chaincodeState.EndConcurrentTxs(txSetIDs)
 }
 
 // SetTxFinished - Marks the finish of the on-going tx set transaction.
@@ -333,6 +445,11 @@ func (ledger *Ledger) GetState(chaincodeID string, key string, committed bool) (
 	return ledger.chaincodeState.Get(chaincodeID, key, committed)
 }
 
+// GetStateForTx is GetState for the chaincode transaction txID, which might be executed concurrently
+func (ledger *Ledger) GetStateForTx(txID string, chaincodeID string, key string, committed bool) ([]byte, error) {
+	return ledger.chaincodeState.GetForTx(txID, chaincodeID, key, committed)
+}
+
 // GetTxSetState get state for txSetID. If committed is false, this first looks in memory
 // and if missing, pulls from db.  If committed is true, this pulls from the db only.
 func (ledger *Ledger) GetTxSetState(txSetID string, committed bool) (*protos.TxSetStateValue, error) {
@@ -345,6 +462,11 @@ func (ledger *Ledger) GetOlderTBModBlock() (uint64, bool) {
 	return ledger.txSetState.GetOlderBlockMod()
 }
 
+// GetMutatedTxSetIDs - returns the IDs of the sets mutated by a mutant transaction at the next commit
+func (ledger *Ledger) GetMutatedTxSetIDs() []string {
+	return ledger.txSetState.GetMutatedTxSetIDs()
+}
+
 // GetStateRangeScanIterator returns an iterator to get all the keys (and values) between startKey and endKey
 // (assuming lexical order of the keys) for a chaincodeID.
 // If committed is true, the key-values are retrieved only from the db. If committed is false, the results from db
@@ -354,6 +476,12 @@ func (ledger *Ledger) GetStateRangeScanIterator(chaincodeID string, startKey str
 	return ledger.chaincodeState.GetRangeScanIterator(chaincodeID, startKey, endKey, committed)
 }
 
+// GetStateRangeScanIteratorForTx is GetStateRangeScanIterator for the chaincode transaction txID, which might be
+// executed concurrently
+func (ledger *Ledger) GetStateRangeScanIteratorForTx(txID string, chaincodeID string, startKey string, endKey string, committed bool) (stcomm.RangeScanIterator, error) {
+	return ledger.chaincodeState.GetRangeScanIteratorForTx(txID, chaincodeID, startKey, endKey, committed)
+}
+
 // SetState sets state to given value for chaincodeID and key. Does not immediately write to DB
 func (ledger *Ledger) SetState(chaincodeID string, key string, value []byte) error {
 	if key == "" || value == nil {
@@ -363,12 +491,27 @@ func (ledger *Ledger) SetState(chaincodeID string, key string, value []byte) err
 	return ledger.chaincodeState.Set(chaincodeID, key, value)
 }
 
-// SetTxSetState sets state to given value for txSetID. Does not immediately write to DB
+// SetStateForTx is SetState for the chaincode transaction txID, which might be executed concurrently
+func (ledger *Ledger) SetStateForTx(txID string, chaincodeID string, key string, value []byte) error {
+	if key == "" || value == nil {
+		return newLedgerError(ErrorTypeInvalidArgument,
+			fmt.Sprintf("An empty string key or a nil value is not supported. Method invoked with key='%s', value='%#v'", key, value))
+	}
+	return ledger.chaincodeState.SetForTx(txID, chaincodeID, key, value)
+}
+
+// SetTxSetState sets state to given value for txSetID. Does not immediately write to DB.
+// The state of a set can be changed only once per batch: a later change returns an error
+// of type ErrorTypeTxSetConflict, so that the first transaction of the block wins
 func (ledger *Ledger) SetTxSetState(txSetID string, txSetStateValue *protos.TxSetStateValue) error {
 	if txSetStateValue == nil {
 		return newLedgerError(ErrorTypeInvalidArgument,
 			fmt.Sprintf("An empty transaction set state value is not supported. Method invoked with stateValue='%#v'", txSetStateValue))
 	}
+	if ledger.txSetState.IsModified(txSetID) {
+		return newLedgerError(ErrorTypeTxSetConflict,
+			fmt.Sprintf("Conflicting change of the tx set with ID: %s, its state was already changed by a previous transaction of the block.", txSetID))
+	}
 	previousValue, err := ledger.GetTxSetState(txSetID, true)
 	if err != nil {
 		return newLedgerError(ErrorTypeResourceNotFound,
@@ -390,6 +533,11 @@ func (ledger *Ledger) SetTxSetState(txSetID string, txSetStateValue *protos.TxSe
 		if err != nil {
 			return newLedgerError(ErrorTypeInvalidArgument, err.Error())
 		}
+	} else if previousValue.IntroBlock != 0 && !proto.Equal(previousValue.ScheduledMutation, txSetStateValue.ScheduledMutation) {
+		err = previousValue.IsValidScheduleChange(txSetStateValue)
+		if err != nil {
+			return newLedgerError(ErrorTypeInvalidArgument, err.Error())
+		}
 	} else {
 		err = previousValue.IsValidBlockExtension(txSetStateValue)
 		if err != nil {
@@ -402,10 +550,38 @@ func (ledger *Ledger) SetTxSetState(txSetID string, txSetStateValue *protos.TxSe
 // ResetToBlock resets the chaincode state to the state at the end of the given block (i.e. beginning of the next),
 // keeping the rest of the data intact
 func (ledger *Ledger) ResetToBlock(blockNum uint64) error {
+	if ledger.simulation {
+		stateAtBlock, err := ledger.chaincodeState.FetchBlockStateDeltaFromDB(blockNum)
+		if err != nil {
+			return fmt.Errorf("Unable to reset the state to block %d, the state at that block could not be retrieved. (%s)", blockNum, err)
+		}
+		if err := keepQueuedMutations(ledger.chaincodeState, stateAtBlock); err != nil {
+			return fmt.Errorf("Unable to reset the state to block %d, the queued mutations could not be retrieved. (%s)", blockNum, err)
+		}
+		ledger.chaincodeState = chaincodest.NewMemoryState(stateAtBlock)
+		return ledger.blockchain.startResetFromBlock(blockNum + 1)
+	}
+	if ledger.resetJournal != nil {
+		return fmt.Errorf("Unable to reset the state to block %d, the previous reset was neither committed nor rolled back.", blockNum)
+	}
+	ledger.blockStateLock.Lock()
+	defer ledger.blockStateLock.Unlock()
+	journal := &resetJournal{resetJournalReplaying, blockNum, ledger.GetBlockchainSize(), blockNum + 1}
+	if err := journal.persist(); err != nil {
+		return fmt.Errorf("Unable to reset the state to block %d, the reset journal could not be written. (%s)", blockNum, err)
+	}
+	ledger.resetJournal = journal
+	// The replayed blocks store their checkpoints at the regular interval again
+	if err := ledger.rewindBlockStateCompaction(blockNum + 1); err != nil {
+		return fmt.Errorf("Unable to reset the state to block %d, the block state compaction could not be rewound. (%s)", blockNum, err)
+	}
 	stateAtBlock, err := ledger.chaincodeState.FetchBlockStateDeltaFromDB(blockNum)
 	if err != nil {
 		return fmt.Errorf("Unable to reset the state to block %d, the state at that block could not be retrieved.", blockNum, err)
 	}
+	if err := keepQueuedMutations(ledger.chaincodeState, stateAtBlock); err != nil {
+		return fmt.Errorf("Unable to reset the state to block %d, the queued mutations could not be retrieved. (%s)", blockNum, err)
+	}
 	err = ledger.chaincodeState.DeleteState()
 	if err != nil {
 		return fmt.Errorf("Unable to reset the state to block %d, the state could not be erased.", blockNum, err)
@@ -418,15 +594,77 @@ func (ledger *Ledger) ResetToBlock(blockNum uint64) error {
 	return ledger.blockchain.startResetFromBlock(blockNum + 1)
 }
 
+// ConcludeReset ends the replay of the blocks started by ResetToBlock. The replayed state
+// is kept until the ongoing batch is either committed or rolled back
 func (ledger *Ledger) ConcludeReset() error {
 	return ledger.blockchain.endReset()
 }
 
+// RollbackReset discards the state replayed since the last ResetToBlock, restoring the state at the end of the chain
+func (ledger *Ledger) RollbackReset() error {
+	journal := ledger.resetJournal
+	if journal == nil {
+		return nil
+	}
+	err := ledger.rollbackReset(journal)
+	if err != nil {
+		return err
+	}
+	ledger.resetJournal = nil
+	ledger.replayReports = nil
+	return nil
+}
+
+// AddReplayReport records the transactions of a replayed block whose outcome changed. The reports are
+// stored in the NonHashData of the block committed by the ongoing batch, the one carrying the mutations
+func (ledger *Ledger) AddReplayReport(report *protos.BlockReplayReport) {
+	ledger.replayReports = append(ledger.replayReports, report)
+}
+
+// GetReplayReports returns the reports added by the ongoing batch
+func (ledger *Ledger) GetReplayReports() []*protos.BlockReplayReport {
+	return ledger.replayReports
+}
+
+// NewSimulation returns a ledger on which the changes of a mutation can be simulated while this ledger goes on
+// committing batches. It starts from the chaincode state at the end of the chain and reads the blocks and the
+// committed tx set state of the DB, but all its changes, including the ones of a reset and of the replayed
+// blocks, are kept in memory: it never writes to the DB and is simply dropped once the simulation is done
+func (ledger *Ledger) NewSimulation() (*Ledger, error) {
+	size, err := fetchBlockchainSizeFromDB()
+	if err != nil {
+		return nil, err
+	}
+	stateAtEnd := chstatemgmt.NewStateDelta()
+	if size > 0 {
+		stateAtEnd, err = ledger.chaincodeState.FetchBlockStateDeltaFromDB(size - 1)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to retrieve the state at the end of the chain (%s)", err)
+		}
+	}
+	simBlockchain := &blockchain{size: size, indexer: ledger.blockchain.indexer}
+	return &Ledger{blockchain: simBlockchain, chaincodeState: chaincodest.NewMemoryState(stateAtEnd),
+		txSetState: txsetst.NewTxSetStateOverlay(), simulation: true}, nil
+}
+
+// GetSimulatedState returns the full image of the chaincode state of a ledger created by NewSimulation
+func (ledger *Ledger) GetSimulatedState() (*chstatemgmt.StateDelta, error) {
+	if !ledger.simulation {
+		return nil, errors.New("The ledger is not the ledger of a simulation")
+	}
+	return ledger.chaincodeState.GetMemoryImage()
+}
+
 // DeleteState tracks the deletion of state for chaincodeID and key. Does not immediately writes to DB
 func (ledger *Ledger) DeleteState(chaincodeID string, key string) error {
 	return ledger.chaincodeState.Delete(chaincodeID, key)
 }
 
+// DeleteStateForTx is DeleteState for the chaincode transaction txID, which might be executed concurrently
+func (ledger *Ledger) DeleteStateForTx(txID string, chaincodeID string, key string) error {
+	return ledger.chaincodeState.DeleteForTx(txID, chaincodeID, key)
+}
+
 // CopyState copies all the key-values from sourceChaincodeID to destChaincodeID
 func (ledger *Ledger) CopyState(sourceChaincodeID string, destChaincodeID string) error {
 	return ledger.chaincodeState.CopyState(sourceChaincodeID, destChaincodeID)
@@ -453,17 +691,25 @@ func (ledger *Ledger) GetStateSnapshot() (*stcomm.StateSnapshot, *stcomm.StateSn
 	blockHeight, err := fetchBlockchainSizeFromSnapshot(dbSnapshot)
 	if err != nil {
 		dbSnapshot.Release()
+		dbSnapshotForTxSet.Release()
 		return nil, nil, err
 	}
 	if 0 == blockHeight {
 		dbSnapshot.Release()
+		dbSnapshotForTxSet.Release()
 		return nil, nil, errors.New("Blockchain has no blocks, cannot determine block number")
 	}
 	chainSnap, err := ledger.chaincodeState.GetSnapshot(blockHeight-1, dbSnapshot)
 	if err != nil {
+		dbSnapshotForTxSet.Release()
 		return nil, nil, err
 	}
 	txSetSnap, err := ledger.txSetState.GetTxSetSnapshot(blockHeight-1, dbSnapshotForTxSet)
+	if err != nil {
+		chainSnap.Release()
+		dbSnapshotForTxSet.Release()
+		return nil, nil, err
+	}
 	return chainSnap, txSetSnap, nil
 }
 
@@ -529,6 +775,8 @@ func (ledger *Ledger) CommitStateDelta(id interface{}) error {
 	if err != nil {
 		return err
 	}
+	// The state is transferred, the scheduled mutations are read again when needed
+	ledger.scheduledTxSets = nil
 	return ledger.txSetState.CommitStateDelta()
 }
 
@@ -551,6 +799,7 @@ func (ledger *Ledger) DeleteALLStateKeysAndValues() error {
 	if err != nil {
 		return err
 	}
+	ledger.scheduledTxSets = nil
 	return ledger.txSetState.DeleteState()
 }
 
@@ -586,6 +835,13 @@ func (ledger *Ledger) GetCurrentBlockEx() uint64 {
 	}
 }
 
+// GetPreviousBlockTime returns the timestamp of the last block committed, nil if there is no such block or if
+// none of the blocks committed so far is timestamped. Unlike the timestamps of the transactions, it is agreed by
+// all the validators
+func (ledger *Ledger) GetPreviousBlockTime() *timestamp.Timestamp {
+	return ledger.blockchain.previousBlockTime
+}
+
 // IsResetting returns true if a reset is currently occurring
 func (ledger *Ledger) IsResetting() bool {
 	return ledger.blockchain.isResetting
@@ -658,7 +914,7 @@ func (ledger *Ledger) GetCurrentDefault(inBlockTx *protos.InBlockTransaction, co
 		if err != nil {
 			return nil, fmt.Errorf("Unable to retrieve nonce for given in block transaction. Error: [%s]", err)
 		}
-		defTxBytes, err = txset.DecryptTxSetSpecification(nonce, copiedDefTx, txSetStValue.Index)
+		defTxBytes, err = txset.DecryptTxSetSpecification(inBlockTx.ConfidentialityProtocolVersion, nonce, copiedDefTx, txSetStValue.Index)
 		if err != nil {
 			return nil, fmt.Errorf("Unable to decrypt transaction specification. Error: [%s]", err)
 		}
@@ -677,6 +933,12 @@ func (ledger *Ledger) GetTransactionByID(txID string) (*protos.InBlockTransactio
 	return ledger.blockchain.getTransactionByID(txID)
 }
 
+// GetTransactionBlockNumber returns the number of the block containing the transaction with the given ID
+func (ledger *Ledger) GetTransactionBlockNumber(txID string) (uint64, error) {
+	blockNumber, _, err := ledger.blockchain.indexer.fetchTransactionIndexByID(txID)
+	return blockNumber, err
+}
+
 // PutRawBlock puts a raw block on the chain. This function should only be
 // used for synchronization between peers.
 func (ledger *Ledger) PutRawBlock(block *protos.Block, blockNumber uint64) error {
@@ -756,6 +1018,10 @@ func (ledger *Ledger) checkValidIDCommitORRollback(id interface{}) error {
 func (ledger *Ledger) resetForNextTxGroup(txCommited bool) {
 	ledgerLogger.Debug("resetting ledger state for next transaction batch")
 	ledger.currentID = nil
+	ledger.replayReports = nil
+	ledger.pendingMutations = nil
+	ledger.queuedMutations = nil
+	ledger.mutationsDequeued = false
 	ledger.chaincodeState.ClearInMemoryChanges(txCommited)
 	ledger.txSetState.ClearInMemoryChanges(txCommited)
 }
@@ -790,7 +1056,7 @@ func sendProducerBlockEvent(block *protos.Block) {
 				transaction.Payload = deploymentSpecBytes
 			}
 		case *protos.InBlockTransaction_MutantTransaction:
-			//TODO: generate events for mutable transactions here!
+			//the changes of the index of the sets are sent as tx set events
 		}
 	}
 
@@ -809,3 +1075,41 @@ func sendChaincodeEvents(trs []*protos.TransactionResult) {
 		}
 	}
 }
+
+func sendReplayEvents(reports []*protos.BlockReplayReport) {
+	for _, report := range reports {
+		producer.Send(producer.CreateReplayEvent(report))
+	}
+}
+
+// buildTxSetEvents returns the events reporting the changes to the transactions sets committed with block
+func buildTxSetEvents(delta *txsetstmgmt.TxSetStateDelta, block *protos.Block, blockNumber uint64) []*protos.TxSetEvent {
+	var events []*protos.TxSetEvent
+	for _, txSetID := range delta.GetUpdatedTxSetIDs(true) {
+		updates := delta.GetUpdates(txSetID)
+		if updates.IsDeleted() {
+			continue
+		}
+		event := &protos.TxSetEvent{TxSetID: txSetID, OldState: updates.PreviousValue, NewState: updates.Value,
+			BlockNumber: blockNumber, Txid: txSetID}
+		switch {
+		case updates.PreviousValue == nil:
+			event.Kind = protos.TxSetEvent_CREATED
+		case updates.Value.Index != updates.PreviousValue.Index:
+			event.Kind = protos.TxSetEvent_INDEX_CHANGED
+			event.Txid = mutantTxIDForSet(txSetID, block)
+		case updates.Value.TxNumber != updates.PreviousValue.TxNumber:
+			event.Kind = protos.TxSetEvent_EXTENDED
+		default:
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func sendTxSetEvents(events []*protos.TxSetEvent) {
+	for _, event := range events {
+		producer.Send(producer.CreateTxSetEvent(event))
+	}
+}
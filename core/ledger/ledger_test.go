@@ -21,9 +21,10 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/hyperledger/fabric/core/ledger/state"
+	chstatemgmt "github.com/hyperledger/fabric/core/ledger/state/chaincodest/statemgmt"
 	"github.com/hyperledger/fabric/core/ledger/testutil"
 	"github.com/hyperledger/fabric/protos"
-	"github.com/ethereum/go-ethereum/core/state"
 )
 
 func TestLedgerCommit(t *testing.T) {
@@ -36,7 +37,7 @@ func TestLedgerCommit(t *testing.T) {
 	ledger.SetState("chaincode3", "key3", []byte("value3"))
 	ledger.ChainTxFinished("txUuid", true)
 	transaction, _ := buildTestTx(t)
-	ledger.CommitTxBatch(1, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(1, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", false), []byte("value1"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value1"))
 }
@@ -84,6 +85,128 @@ func TestLedgerRollbackWithHash(t *testing.T) {
 	testutil.AssertNil(t, ledgerTestWrapper.GetState("chaincode1", "key1", false))
 }
 
+func TestLedgerResetRollback(t *testing.T) {
+	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
+	ledger := ledgerTestWrapper.ledger
+	for i := 0; i < 3; i++ {
+		ledger.BeginTxBatch(i)
+		ledger.ChainTxBegin("txUuid")
+		ledger.SetState("chaincode1", "key1", []byte("value"+strconv.Itoa(i)))
+		ledger.ChainTxFinished("txUuid", true)
+		transaction, _ := buildTestTx(t)
+		ledger.CommitTxBatch(i, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
+	}
+
+	// Replay block 2 with a different state and roll back
+	testutil.AssertNoError(t, ledger.ResetToBlock(0), "Error while resetting the state")
+	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value0"))
+	ledger.ChainTxBegin("txUuid")
+	ledger.SetState("chaincode1", "key1", []byte("replayed"))
+	ledger.ChainTxFinished("txUuid", true)
+	testutil.AssertNoError(t, ledger.CommitResetTxBatch(), "Error while committing the replayed block")
+	testutil.AssertNoError(t, ledger.RollbackReset(), "Error while rolling back the reset")
+	testutil.AssertEquals(t, ledger.IsResetting(), false)
+	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value2"))
+	delta, err := ledger.GetDeltaFromGenesis(1)
+	testutil.AssertNoError(t, err, "Error while retrieving the state at block 1")
+	testutil.AssertEquals(t, delta.Get("chaincode1", "key1").GetValue(), []byte("value1"))
+
+	// A reset interrupted by a crash is rolled back when the ledger is opened again
+	testutil.AssertNoError(t, ledger.ResetToBlock(0), "Error while resetting the state")
+	ledger.ChainTxBegin("txUuid")
+	ledger.SetState("chaincode1", "key1", []byte("replayed"))
+	ledger.ChainTxFinished("txUuid", true)
+	testutil.AssertNoError(t, ledger.CommitResetTxBatch(), "Error while committing the replayed block")
+	reopened, err := GetNewLedger()
+	testutil.AssertNoError(t, err, "Error while recovering the interrupted reset")
+	value, err := reopened.GetState("chaincode1", "key1", true)
+	testutil.AssertNoError(t, err, "Error while getting the state")
+	testutil.AssertEquals(t, value, []byte("value2"))
+}
+
+func TestLedgerSimulation(t *testing.T) {
+	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
+	ledger := ledgerTestWrapper.ledger
+	for i := 0; i < 3; i++ {
+		ledger.BeginTxBatch(i)
+		ledger.ChainTxBegin("txUuid")
+		ledger.SetState("chaincode1", "key1", []byte("value"+strconv.Itoa(i)))
+		ledger.ChainTxFinished("txUuid", true)
+		transaction, _ := buildTestTx(t)
+		ledger.CommitTxBatch(i, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
+	}
+
+	simulation, err := ledger.NewSimulation()
+	testutil.AssertNoError(t, err, "Error while creating the simulation")
+	value, err := simulation.GetState("chaincode1", "key1", true)
+	testutil.AssertNoError(t, err, "Error while getting the simulated state")
+	testutil.AssertEquals(t, value, []byte("value2"))
+
+	// The batches of the ledger go on during the simulation
+	ledger.BeginTxBatch(3)
+
+	// Replay blocks 1 and 2 with a different state
+	testutil.AssertNoError(t, simulation.ResetToBlock(0), "Error while resetting the simulated state")
+	value, err = simulation.GetState("chaincode1", "key1", true)
+	testutil.AssertNoError(t, err, "Error while getting the simulated state")
+	testutil.AssertEquals(t, value, []byte("value0"))
+	for i := 1; i < 3; i++ {
+		simulation.ChainTxBegin("txUuid")
+		simulation.SetState("chaincode1", "key"+strconv.Itoa(i), []byte("replayed"))
+		simulation.ChainTxFinished("txUuid", true)
+		testutil.AssertNoError(t, simulation.CommitResetTxBatch(), "Error while committing the replayed block")
+	}
+	testutil.AssertNoError(t, simulation.ConcludeReset(), "Error while concluding the simulated reset")
+	simulated, err := simulation.GetSimulatedState()
+	testutil.AssertNoError(t, err, "Error while getting the simulated state")
+	testutil.AssertEquals(t, simulated.Get("chaincode1", "key1").GetValue(), []byte("replayed"))
+	testutil.AssertEquals(t, simulated.Get("chaincode1", "key2").GetValue(), []byte("replayed"))
+	testutil.AssertError(t, simulation.CommitTxBatch(3, nil, nil, nil), "A simulation should not be committed")
+
+	// Nothing reached the DB
+	ledger.ChainTxBegin("txUuid")
+	ledger.SetState("chaincode1", "key1", []byte("value3"))
+	ledger.ChainTxFinished("txUuid", true)
+	testutil.AssertNoError(t, ledger.CommitTxBatch(3, nil, nil, []byte("proof")), "Error while committing a block")
+	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value3"))
+	testutil.AssertNil(t, ledgerTestWrapper.GetState("chaincode1", "key2", true))
+	delta, err := ledger.GetDeltaFromGenesis(1)
+	testutil.AssertNoError(t, err, "Error while retrieving the state at block 1")
+	testutil.AssertEquals(t, delta.Get("chaincode1", "key1").GetValue(), []byte("value1"))
+}
+
+func TestLedgerBlockStateCheckpoints(t *testing.T) {
+	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
+	ledger := ledgerTestWrapper.ledger
+	// test.yaml stores a checkpoint every 5 blocks
+	for i := 0; i < 12; i++ {
+		ledger.BeginTxBatch(i)
+		ledger.ChainTxBegin("txUuid")
+		ledger.SetState("chaincode1", "key"+strconv.Itoa(i), []byte("value"+strconv.Itoa(i)))
+		if i > 0 {
+			ledger.DeleteState("chaincode1", "key"+strconv.Itoa(i-1))
+		}
+		ledger.ChainTxFinished("txUuid", true)
+		testutil.AssertNoError(t, ledger.CommitTxBatch(i, nil, nil, []byte("proof")), "Error while committing a block")
+	}
+
+	for i := 0; i < 12; i++ {
+		delta, err := ledger.GetDeltaFromGenesis(uint64(i))
+		testutil.AssertNoError(t, err, "Error while retrieving the state at a block")
+		testutil.AssertEquals(t, delta.Get("chaincode1", "key"+strconv.Itoa(i)).GetValue(), []byte("value"+strconv.Itoa(i)))
+		if deleted := delta.Get("chaincode1", "key"+strconv.Itoa(i-1)); i > 0 && deleted != nil {
+			testutil.AssertNil(t, deleted.GetValue())
+		}
+	}
+
+	checkpoint, err := ledger.chaincodeState.FindBlockStateCheckpoint(9)
+	testutil.AssertNoError(t, err, "Error while finding the checkpoint")
+	testutil.AssertEquals(t, checkpoint, uint64(5))
+	checkpoint, err = ledger.chaincodeState.FindBlockStateCheckpoint(10)
+	testutil.AssertNoError(t, err, "Error while finding the checkpoint")
+	testutil.AssertEquals(t, checkpoint, uint64(10))
+}
+
 func TestLedgerDifferentID(t *testing.T) {
 	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
 	ledger := ledgerTestWrapper.ledger
@@ -94,7 +217,7 @@ func TestLedgerDifferentID(t *testing.T) {
 	ledger.SetState("chaincode3", "key3", []byte("value3"))
 	ledger.ChainTxFinished("txUuid", true)
 	transaction, _ := buildTestTx(t)
-	err := ledger.CommitTxBatch(2, []*protos.Transaction{transaction}, nil, []byte("prrof"))
+	err := ledger.CommitTxBatch(2, []*protos.InBlockTransaction{transaction}, nil, []byte("prrof"))
 	testutil.AssertError(t, err, "ledger should throw error for wrong batch ID")
 }
 
@@ -125,7 +248,7 @@ func TestLedgerGetTempStateHashWithTxDeltaStateHashes(t *testing.T) {
 	if ok {
 		t.Fatalf("Entry for a failed Tx should not be present in txDeltaHashes map")
 	}
-	ledger.CommitTxBatch(1, []*protos.Transaction{}, nil, []byte("proof"))
+	ledger.CommitTxBatch(1, []*protos.InBlockTransaction{}, nil, []byte("proof"))
 
 	ledger.BeginTxBatch(2)
 	ledger.ChainTxBegin("txUuid1")
@@ -147,14 +270,15 @@ func TestLedgerStateSnapshot(t *testing.T) {
 	ledger.SetState("chaincode3", "key3", []byte("value3"))
 	ledger.ChainTxFinished("txUuid", true)
 	transaction, _ := buildTestTx(t)
-	ledger.CommitTxBatch(1, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(1, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 
-	snapshot, err := ledger.GetStateSnapshot()
+	snapshot, txSetSnapshot, err := ledger.GetStateSnapshot()
 
 	if err != nil {
 		t.Fatalf("Error fetching snapshot %s", err)
 	}
 	defer snapshot.Release()
+	defer txSetSnapshot.Release()
 
 	// Modify keys to ensure they do not impact the snapshot
 	ledger.BeginTxBatch(2)
@@ -165,7 +289,7 @@ func TestLedgerStateSnapshot(t *testing.T) {
 	ledger.SetState("chaincode6", "key6", []byte("value6"))
 	ledger.ChainTxFinished("txUuid", true)
 	transaction, _ = buildTestTx(t)
-	ledger.CommitTxBatch(2, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(2, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 
 	var count = 0
 	for snapshot.Next() {
@@ -197,7 +321,7 @@ func TestLedgerPutRawBlock(t *testing.T) {
 	ledger.SetState("chaincode1", "key1", []byte("value1"))
 	ledger.ChainTxFinished("txUuid", true)
 	transaction, _ := buildTestTx(t)
-	ledger.CommitTxBatch(1, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(1, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 
 	previousHash, _ := block.GetHash()
 	newBlock := ledgerTestWrapper.GetBlockByNumber(5)
@@ -220,7 +344,7 @@ func TestLedgerSetRawState(t *testing.T) {
 	ledger.SetState("chaincode3", "key3", []byte("value3"))
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, _ := buildTestTx(t)
-	ledger.CommitTxBatch(1, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(1, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 
 	// Ensure values are in the DB
 	val := ledgerTestWrapper.GetState("chaincode1", "key1", true)
@@ -241,11 +365,12 @@ func TestLedgerSetRawState(t *testing.T) {
 		t.Fatalf("Error getting hash1 %s", hash1Err)
 	}
 
-	snapshot, snapshotError := ledger.GetStateSnapshot()
+	snapshot, txSetSnapshot, snapshotError := ledger.GetStateSnapshot()
 	if snapshotError != nil {
 		t.Fatalf("Error fetching snapshot %s", snapshotError)
 	}
 	defer snapshot.Release()
+	defer txSetSnapshot.Release()
 
 	// Delete keys
 	ledger.BeginTxBatch(2)
@@ -255,7 +380,7 @@ func TestLedgerSetRawState(t *testing.T) {
 	ledger.DeleteState("chaincode3", "key3")
 	ledger.ChainTxFinished("txUuid2", true)
 	transaction, _ = buildTestTx(t)
-	ledger.CommitTxBatch(2, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(2, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 
 	// ensure keys are deleted
 	val = ledgerTestWrapper.GetState("chaincode1", "key1", true)
@@ -282,10 +407,10 @@ func TestLedgerSetRawState(t *testing.T) {
 
 	// put key/values from the snapshot back in the DB
 	//var keys, values [][]byte
-	delta := state.NewStateDelta()
+	delta := chstatemgmt.NewStateDelta()
 	for i := 0; snapshot.Next(); i++ {
 		k, v := snapshot.GetRawKeyValue()
-		cID, keyID := state.DecodeCompositeKey(k)
+		cID, keyID := stcomm.DecodeCompositeKey(k)
 		delta.Set(cID, keyID, v, nil)
 	}
 
@@ -325,7 +450,7 @@ func TestDeleteAllStateKeysAndValues(t *testing.T) {
 	ledger.SetState("chaincode3", "key3", []byte("value3"))
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, _ := buildTestTx(t)
-	ledger.CommitTxBatch(1, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(1, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 
 	// Confirm values are present in state
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value1"))
@@ -351,7 +476,7 @@ func TestDeleteAllStateKeysAndValues(t *testing.T) {
 	ledger.SetState("chaincode3", "key3", []byte("value3"))
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, _ = buildTestTx(t)
-	ledger.CommitTxBatch(2, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(2, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 
 	// Confirm values are present in state
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value1"))
@@ -370,7 +495,7 @@ func TestVerifyChain(t *testing.T) {
 		ledger.SetState("chaincode"+strconv.Itoa(i), "key"+strconv.Itoa(i), []byte("value"+strconv.Itoa(i)))
 		ledger.ChainTxFinished("txUuid"+strconv.Itoa(i), true)
 		transaction, _ := buildTestTx(t)
-		ledger.CommitTxBatch(i, []*protos.Transaction{transaction}, nil, []byte("proof"))
+		ledger.CommitTxBatch(i, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 	}
 
 	// Verify the chain
@@ -426,7 +551,7 @@ func TestBlockNumberOutOfBoundsError(t *testing.T) {
 		ledger.SetState("chaincode"+strconv.Itoa(i), "key"+strconv.Itoa(i), []byte("value"+strconv.Itoa(i)))
 		ledger.ChainTxFinished("txUuid"+strconv.Itoa(i), true)
 		transaction, _ := buildTestTx(t)
-		ledger.CommitTxBatch(i, []*protos.Transaction{transaction}, nil, []byte("proof"))
+		ledger.CommitTxBatch(i, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 	}
 
 	ledgerTestWrapper.GetBlockByNumber(9)
@@ -434,7 +559,7 @@ func TestBlockNumberOutOfBoundsError(t *testing.T) {
 	testutil.AssertEquals(t, err, ErrOutOfBounds)
 
 	ledgerTestWrapper.GetStateDelta(9)
-	_, err = ledger.GetStateDelta(10)
+	_, _, err = ledger.GetStateDelta(10)
 	testutil.AssertEquals(t, err, ErrOutOfBounds)
 
 }
@@ -452,7 +577,7 @@ func TestRollBackwardsAndForwards(t *testing.T) {
 	ledger.SetState("chaincode3", "key3", []byte("value3A"))
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, _ := buildTestTx(t)
-	ledger.CommitTxBatch(0, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(0, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value1A"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode2", "key2", true), []byte("value2A"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode3", "key3", true), []byte("value3A"))
@@ -465,7 +590,7 @@ func TestRollBackwardsAndForwards(t *testing.T) {
 	ledger.SetState("chaincode3", "key3", []byte("value3B"))
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, _ = buildTestTx(t)
-	ledger.CommitTxBatch(1, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(1, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value1B"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode2", "key2", true), []byte("value2B"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode3", "key3", true), []byte("value3B"))
@@ -479,7 +604,7 @@ func TestRollBackwardsAndForwards(t *testing.T) {
 	ledger.SetState("chaincode4", "key4", []byte("value4C"))
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, _ = buildTestTx(t)
-	ledger.CommitTxBatch(2, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(2, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value1C"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode2", "key2", true), []byte("value2C"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode3", "key3", true), []byte("value3C"))
@@ -542,7 +667,7 @@ func TestInvalidOrderDelta(t *testing.T) {
 	ledger.SetState("chaincode3", "key3", []byte("value3A"))
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, _ := buildTestTx(t)
-	ledger.CommitTxBatch(0, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(0, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value1A"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode2", "key2", true), []byte("value2A"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode3", "key3", true), []byte("value3A"))
@@ -555,7 +680,7 @@ func TestInvalidOrderDelta(t *testing.T) {
 	ledger.SetState("chaincode3", "key3", []byte("value3B"))
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, _ = buildTestTx(t)
-	ledger.CommitTxBatch(1, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(1, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value1B"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode2", "key2", true), []byte("value2B"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode3", "key3", true), []byte("value3B"))
@@ -570,7 +695,7 @@ func TestInvalidOrderDelta(t *testing.T) {
 
 	ledgerTestWrapper.ApplyStateDelta(2, delta)
 
-	err = ledger.ApplyStateDelta(3, delta)
+	err = ledger.ApplyStateDelta(3, delta, nil)
 	testutil.AssertError(t, err, "Expected error applying delta")
 
 	err = ledger.CommitStateDelta(3)
@@ -594,7 +719,7 @@ func TestApplyDeltaHash(t *testing.T) {
 	ledger.SetState("chaincode3", "key3", []byte("value3A"))
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, _ := buildTestTx(t)
-	ledger.CommitTxBatch(0, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(0, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value1A"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode2", "key2", true), []byte("value2A"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode3", "key3", true), []byte("value3A"))
@@ -607,7 +732,7 @@ func TestApplyDeltaHash(t *testing.T) {
 	ledger.SetState("chaincode3", "key3", []byte("value3B"))
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, _ = buildTestTx(t)
-	ledger.CommitTxBatch(1, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(1, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value1B"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode2", "key2", true), []byte("value2B"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode3", "key3", true), []byte("value3B"))
@@ -621,7 +746,7 @@ func TestApplyDeltaHash(t *testing.T) {
 	ledger.SetState("chaincode4", "key4", []byte("value4C"))
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, _ = buildTestTx(t)
-	ledger.CommitTxBatch(2, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(2, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value1C"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode2", "key2", true), []byte("value2C"))
 	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode3", "key3", true), []byte("value3C"))
@@ -673,10 +798,10 @@ func TestPreviewTXBatchBlock(t *testing.T) {
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, _ := buildTestTx(t)
 
-	previewBlockInfo, err := ledger.GetTXBatchPreviewBlockInfo(0, []*protos.Transaction{transaction}, []byte("proof"))
+	previewBlockInfo, err := ledger.GetTXBatchPreviewBlockInfo(0, []*protos.InBlockTransaction{transaction}, []byte("proof"))
 	testutil.AssertNoError(t, err, "Error fetching preview block info.")
 
-	ledger.CommitTxBatch(0, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(0, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 	committedBlockInfo, err := ledger.GetBlockchainInfo()
 	testutil.AssertNoError(t, err, "Error fetching committed block hash.")
 
@@ -695,7 +820,7 @@ func TestGetTransactionByID(t *testing.T) {
 	ledger.SetState("chaincode3", "key3", []byte("value3A"))
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, uuid := buildTestTx(t)
-	ledger.CommitTxBatch(0, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(0, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 
 	ledgerTransaction, err := ledger.GetTransactionByID(uuid)
 	testutil.AssertNoError(t, err, "Error fetching transaction by ID.")
@@ -713,11 +838,11 @@ func TestRangeScanIterator(t *testing.T) {
 	///////// Test with an empty Ledger //////////
 	//////////////////////////////////////////////
 	itr, _ := ledger.GetStateRangeScanIterator("chaincodeID2", "key2", "key5", false)
-	state.AssertIteratorContains(t, itr, map[string][]byte{})
+	chstatemgmt.AssertIteratorContains(t, itr, map[string][]byte{})
 	itr.Close()
 
 	itr, _ = ledger.GetStateRangeScanIterator("chaincodeID2", "key2", "key5", true)
-	state.AssertIteratorContains(t, itr, map[string][]byte{})
+	chstatemgmt.AssertIteratorContains(t, itr, map[string][]byte{})
 	itr.Close()
 
 	// Commit initial data to ledger
@@ -744,7 +869,7 @@ func TestRangeScanIterator(t *testing.T) {
 
 	ledger.ChainTxFinished("txUuid1", true)
 	transaction, _ := buildTestTx(t)
-	ledger.CommitTxBatch(0, []*protos.Transaction{transaction}, nil, []byte("proof"))
+	ledger.CommitTxBatch(0, []*protos.InBlockTransaction{transaction}, nil, []byte("proof"))
 
 	// Add new keys and modify existing keys in on-going tx-batch
 	ledger.BeginTxBatch(1)
@@ -757,7 +882,7 @@ func TestRangeScanIterator(t *testing.T) {
 	//////////////////////////////////////////////////////////
 	// test range scan for chaincodeID4
 	itr, _ = ledger.GetStateRangeScanIterator("chaincodeID4", "key2", "key5", true)
-	state.AssertIteratorContains(t, itr,
+	chstatemgmt.AssertIteratorContains(t, itr,
 		map[string][]byte{
 			"key2": []byte("value2"),
 			"key3": []byte("value3"),
@@ -768,7 +893,7 @@ func TestRangeScanIterator(t *testing.T) {
 
 	// test with empty start-key
 	itr, _ = ledger.GetStateRangeScanIterator("chaincodeID4", "", "key5", true)
-	state.AssertIteratorContains(t, itr,
+	chstatemgmt.AssertIteratorContains(t, itr,
 		map[string][]byte{
 			"key1": []byte("value1"),
 			"key2": []byte("value2"),
@@ -780,7 +905,7 @@ func TestRangeScanIterator(t *testing.T) {
 
 	// test with empty end-key
 	itr, _ = ledger.GetStateRangeScanIterator("chaincodeID4", "", "", true)
-	state.AssertIteratorContains(t, itr,
+	chstatemgmt.AssertIteratorContains(t, itr,
 		map[string][]byte{
 			"key1": []byte("value1"),
 			"key2": []byte("value2"),
@@ -796,7 +921,7 @@ func TestRangeScanIterator(t *testing.T) {
 	//////////////////////////////////////////////////////////
 	// test range scan for chaincodeID4
 	itr, _ = ledger.GetStateRangeScanIterator("chaincodeID4", "key2", "key5", false)
-	state.AssertIteratorContains(t, itr,
+	chstatemgmt.AssertIteratorContains(t, itr,
 		map[string][]byte{
 			"key2": []byte("value2_new"),
 			"key4": []byte("value4"),
@@ -806,7 +931,7 @@ func TestRangeScanIterator(t *testing.T) {
 
 	// test with empty start-key
 	itr, _ = ledger.GetStateRangeScanIterator("chaincodeID4", "", "key5", false)
-	state.AssertIteratorContains(t, itr,
+	chstatemgmt.AssertIteratorContains(t, itr,
 		map[string][]byte{
 			"key1": []byte("value1"),
 			"key2": []byte("value2_new"),
@@ -817,7 +942,7 @@ func TestRangeScanIterator(t *testing.T) {
 
 	// test with empty end-key
 	itr, _ = ledger.GetStateRangeScanIterator("chaincodeID4", "", "", false)
-	state.AssertIteratorContains(t, itr,
+	chstatemgmt.AssertIteratorContains(t, itr,
 		map[string][]byte{
 			"key1": []byte("value1"),
 			"key2": []byte("value2_new"),
@@ -838,7 +963,7 @@ func TestGetSetMultipleKeys(t *testing.T) {
 	l.SetStateMultipleKeys("chaincodeID", map[string][]byte{"key1": []byte("value1"), "key2": []byte("value2")})
 	l.ChainTxFinished("txID", true)
 	tx, _ := buildTestTx(t)
-	l.CommitTxBatch(1, []*protos.Transaction{tx}, nil, nil)
+	l.CommitTxBatch(1, []*protos.InBlockTransaction{tx}, nil, nil)
 
 	values, _ := l.GetStateMultipleKeys("chaincodeID", []string{"key1", "key2"}, true)
 	testutil.AssertEquals(t, values, [][]byte{[]byte("value1"), []byte("value2")})
@@ -853,14 +978,14 @@ func TestCopyState(t *testing.T) {
 	l.SetState("chaincodeID1", "key3", []byte("value3"))
 	l.ChainTxFinished("txID", true)
 	tx, _ := buildTestTx(t)
-	l.CommitTxBatch(1, []*protos.Transaction{tx}, nil, nil)
+	l.CommitTxBatch(1, []*protos.InBlockTransaction{tx}, nil, nil)
 
 	l.BeginTxBatch(2)
 	l.ChainTxBegin("txID")
 	l.CopyState("chaincodeID1", "chaincodeID2")
 	l.ChainTxFinished("txID", true)
 	tx, _ = buildTestTx(t)
-	l.CommitTxBatch(2, []*protos.Transaction{tx}, nil, nil)
+	l.CommitTxBatch(2, []*protos.InBlockTransaction{tx}, nil, nil)
 
 	values, _ := l.GetStateMultipleKeys("chaincodeID2", []string{"key1", "key2", "key3"}, true)
 	testutil.AssertEquals(t, values, [][]byte{[]byte("value1"), []byte("value2"), []byte("value3")})
@@ -874,7 +999,7 @@ func TestLedgerEmptyArrayValue(t *testing.T) {
 	l.SetState("chaincodeID1", "key1", []byte{})
 	l.ChainTxFinished("txID", true)
 	tx, _ := buildTestTx(t)
-	l.CommitTxBatch(1, []*protos.Transaction{tx}, nil, nil)
+	l.CommitTxBatch(1, []*protos.InBlockTransaction{tx}, nil, nil)
 
 	value, _ := l.GetState("chaincodeID1", "key1", true)
 	if value == nil || len(value) != 0 {
@@ -912,7 +1037,116 @@ func TestLedgerInvalidInput(t *testing.T) {
 	l.SetState("chaincodeID1", "key1", []byte("value1"))
 	l.ChainTxFinished("txID", true)
 	tx, _ := buildTestTx(t)
-	l.CommitTxBatch(1, []*protos.Transaction{tx}, nil, nil)
+	l.CommitTxBatch(1, []*protos.InBlockTransaction{tx}, nil, nil)
 	value, _ := l.GetState("chaincodeID1", "key1", true)
 	testutil.AssertEquals(t, value, []byte("value1"))
 }
+
+func TestTxSetStateConflict(t *testing.T) {
+	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
+	l := ledgerTestWrapper.ledger
+	indexAtBlock := []*protos.TxSetIndex{{BlockNr: 0, InBlockIndex: 1}}
+	l.BeginTxBatch(1)
+	l.SetTxBegin("txSet1")
+	testutil.AssertNoError(t, l.SetTxSetState("txSet1", &protos.TxSetStateValue{IntroBlock: 1, Nonce: 1, TxNumber: 2, IndexAtBlock: indexAtBlock}), "Error while creating the set")
+	l.SetTxFinished("txSet1", true)
+	testutil.AssertNoError(t, l.CommitTxBatch(1, nil, nil, nil), "Error while committing the batch")
+
+	// The first change of the set in the batch wins
+	l.BeginTxBatch(2)
+	l.SetTxBegin("mutant1")
+	testutil.AssertNoError(t, l.SetTxSetState("txSet1", &protos.TxSetStateValue{IntroBlock: 1, Nonce: 2, Index: 1, TxNumber: 2, IndexAtBlock: indexAtBlock, LastModifiedAtBlock: 1}), "Error while mutating the set")
+	l.SetTxFinished("mutant1", true)
+	l.SetTxBegin("mutant2")
+	err := l.SetTxSetState("txSet1", &protos.TxSetStateValue{IntroBlock: 1, Nonce: 2, Index: 0, TxNumber: 2, IndexAtBlock: indexAtBlock, LastModifiedAtBlock: 1})
+	ledgerErr, ok := err.(*Error)
+	if !(ok && ledgerErr.Type() == ErrorTypeTxSetConflict) {
+		t.Fatalf("A 'LedgerError' of type 'ErrorTypeTxSetConflict' should have been returned, got: %v", err)
+	}
+	l.SetTxFinished("mutant2", false)
+	testutil.AssertNoError(t, l.CommitTxBatch(2, nil, nil, nil), "Error while committing the batch")
+
+	stateValue, err := l.GetTxSetState("txSet1", true)
+	testutil.AssertNoError(t, err, "Error while reading the state of the set")
+	testutil.AssertEquals(t, stateValue.Index, uint64(1))
+}
+
+func TestQueuedMutations(t *testing.T) {
+	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
+	l := ledgerTestWrapper.ledger
+	mutantTx := func(txID string) *protos.InBlockTransaction {
+		return &protos.InBlockTransaction{Txid: txID, Transaction: &protos.InBlockTransaction_MutantTransaction{
+			MutantTransaction: &protos.MutantTransaction{TxSetID: "txSet1", TxSetIndex: 1, ChaincodeID: "escrow"}}}
+	}
+
+	l.BeginTxBatch(1)
+	l.ChainTxBegin("tx1")
+	testutil.AssertNoError(t, l.QueueMutation("tx1", mutantTx("mutant1")), "Error while queueing a mutation")
+	testutil.AssertError(t, l.QueueMutation("tx1", mutantTx("mutant1")), "The same mutation should not be queued twice")
+	l.ChainTxFinished("tx1", true)
+	// The mutations of a failed transaction are discarded
+	l.ChainTxBegin("tx2")
+	testutil.AssertNoError(t, l.QueueMutation("tx2", mutantTx("mutant2")), "Error while queueing a mutation")
+	l.ChainTxFinished("tx2", false)
+	testutil.AssertNoError(t, l.CommitTxBatch(1, nil, nil, nil), "Error while committing the batch")
+	// The queue is part of the state, hence of its hash
+	queueBytes, err := l.GetState(queuedMutationsChaincodeID, queuedMutationsKey, true)
+	testutil.AssertNoError(t, err, "Error while reading the queue from the state")
+	testutil.AssertNotNil(t, queueBytes)
+	stateHash, err := l.GetTempStateHash()
+	testutil.AssertNoError(t, err, "Error while computing the state hash")
+
+	// A rolled back batch leaves the queue untouched
+	l.BeginTxBatch(2)
+	queue, err := l.DequeueMutations()
+	testutil.AssertNoError(t, err, "Error while dequeueing the mutations")
+	testutil.AssertEquals(t, len(queue), 1)
+	dequeuedHash, err := l.GetTempStateHash()
+	testutil.AssertNoError(t, err, "Error while computing the state hash")
+	testutil.AssertNotEquals(t, dequeuedHash, stateHash)
+	testutil.AssertNoError(t, l.RollbackTxBatch(2), "Error while rolling back the batch")
+
+	l.BeginTxBatch(3)
+	queue, err = l.DequeueMutations()
+	testutil.AssertNoError(t, err, "Error while dequeueing the mutations")
+	testutil.AssertEquals(t, len(queue), 1)
+	testutil.AssertEquals(t, queue[0].Txid, "mutant1")
+	queue, err = l.DequeueMutations()
+	testutil.AssertNoError(t, err, "Error while dequeueing the mutations")
+	testutil.AssertEquals(t, len(queue), 0)
+	testutil.AssertNoError(t, l.CommitTxBatch(3, nil, nil, nil), "Error while committing the batch")
+	queueBytes, err = l.GetState(queuedMutationsChaincodeID, queuedMutationsKey, true)
+	testutil.AssertNoError(t, err, "Error while reading the queue from the state")
+	testutil.AssertNil(t, queueBytes)
+
+	l.BeginTxBatch(4)
+	queue, err = l.DequeueMutations()
+	testutil.AssertNoError(t, err, "Error while dequeueing the mutations")
+	testutil.AssertEquals(t, len(queue), 0)
+	testutil.AssertNoError(t, l.CommitTxBatch(4, nil, nil, nil), "Error while committing the batch")
+}
+
+func TestCommitTxBatchChaincodeEvents(t *testing.T) {
+	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
+	l := ledgerTestWrapper.ledger
+	mutantTx := &protos.InBlockTransaction{Txid: "mutant1", Transaction: &protos.InBlockTransaction_MutantTransaction{
+		MutantTransaction: &protos.MutantTransaction{TxSetID: "txSet0", TxSetIndex: 1}}}
+	txSet := &protos.InBlockTransaction{Txid: "txSet1", Transaction: &protos.InBlockTransaction_TransactionSet{
+		TransactionSet: &protos.TransactionSet{Transactions: [][]byte{[]byte("tx0")}}}}
+	event := &protos.ChaincodeEvent{ChaincodeID: "chaincode1", TxID: "tx0", EventName: "event1"}
+
+	// The failed transaction is not part of the block, the mutant transaction comes first and has no result
+	l.BeginTxBatch(1)
+	results := []*protos.TransactionResult{
+		{Txid: "failedTx", ErrorCode: 1, Error: "failed", ChaincodeEvent: &protos.ChaincodeEvent{ChaincodeID: "chaincode1", TxID: "failedTx"}},
+		{Txid: "txSet1", ChaincodeEvent: event},
+	}
+	testutil.AssertNoError(t, l.CommitTxBatch(1, []*protos.InBlockTransaction{mutantTx, txSet}, results, nil), "Error while committing the batch")
+
+	block, err := l.GetBlockByNumber(0)
+	testutil.AssertNoError(t, err, "Error while retrieving the block")
+	ccEvents := block.GetNonHashData().GetChaincodeEvents()
+	testutil.AssertEquals(t, len(ccEvents), len(block.Transactions))
+	testutil.AssertEquals(t, ccEvents[0], &protos.ChaincodeEvent{})
+	testutil.AssertEquals(t, ccEvents[1], event)
+}
@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/ledger/perfstat"
 	"github.com/hyperledger/fabric/core/ledger/testutil"
@@ -74,16 +75,16 @@ func BenchmarkLedgerSingleKeyTransaction(b *testing.B) {
 	chaincode := "chaincodeId"
 	value := testutil.ConstructRandomBytes(b, *kvSize-(len(chaincode)+len(*key)))
 	tx := constructDummyTx(b)
-	serializedBytes, _ := tx.Bytes()
+	serializedBytes, _ := proto.Marshal(tx)
 	b.Logf("Size of serialized bytes for tx = %d", len(serializedBytes))
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
 		for i := 0; i < *numBatches; i++ {
 			ledger.BeginTxBatch(1)
 			// execute one batch
-			var transactions []*protos.Transaction
+			var transactions []*protos.InBlockTransaction
 			for j := 0; j < *batchSize; j++ {
-				ledger.TxBegin("txUuid")
+				ledger.ChainTxBegin("txUuid")
 				_, err := ledger.GetState(chaincode, *key, true)
 				if err != nil {
 					b.Fatalf("Error in getting state: %s", err)
@@ -91,7 +92,7 @@ func BenchmarkLedgerSingleKeyTransaction(b *testing.B) {
 				for l := 0; l < *numWritesToLedger; l++ {
 					ledger.SetState(chaincode, *key, value)
 				}
-				ledger.TxFinished("txUuid", true)
+				ledger.ChainTxFinished("txUuid", true)
 				transactions = append(transactions, tx)
 			}
 			ledger.CommitTxBatch(1, transactions, nil, []byte("proof"))
@@ -131,13 +132,13 @@ func BenchmarkLedgerPopulate(b *testing.B) {
 		for batchID := 0; batchID < numBatches; batchID++ {
 			ledger.BeginTxBatch(1)
 			// execute one batch
-			var transactions []*protos.Transaction
+			var transactions []*protos.InBlockTransaction
 			for j := 0; j < *batchSize; j++ {
-				ledger.TxBegin("txUuid")
+				ledger.ChainTxBegin("txUuid")
 				keyNumber := batchID*(*batchSize) + j
 				key := *keyPrefix + strconv.Itoa(keyNumber)
 				ledger.SetState(chaincode, key, value)
-				ledger.TxFinished("txUuid", true)
+				ledger.ChainTxFinished("txUuid", true)
 				transactions = append(transactions, tx)
 			}
 			ledger.CommitTxBatch(1, transactions, nil, []byte("proof"))
@@ -176,10 +177,10 @@ func BenchmarkLedgerRandomTransactions(b *testing.B) {
 		for batchID := 0; batchID < *numBatches; batchID++ {
 			ledger.BeginTxBatch(1)
 			// execute one batch
-			var transactions []*protos.Transaction
+			var transactions []*protos.InBlockTransaction
 			for j := 0; j < *batchSize; j++ {
 				randomKeySuffixGen := testutil.NewTestRandomNumberGenerator(*maxKeySuffix)
-				ledger.TxBegin("txUuid")
+				ledger.ChainTxBegin("txUuid")
 				for k := 0; k < *numReadsFromLedger; k++ {
 					randomKey := *keyPrefix + strconv.Itoa(randomKeySuffixGen.Next())
 					ledger.GetState(chaincode, randomKey, true)
@@ -188,7 +189,7 @@ func BenchmarkLedgerRandomTransactions(b *testing.B) {
 					randomKey := *keyPrefix + strconv.Itoa(randomKeySuffixGen.Next())
 					ledger.SetState(chaincode, randomKey, value)
 				}
-				ledger.TxFinished("txUuid", true)
+				ledger.ChainTxFinished("txUuid", true)
 				transactions = append(transactions, tx)
 			}
 			ledger.CommitTxBatch(1, transactions, nil, []byte("proof"))
@@ -216,11 +217,11 @@ func populateDB(tb testing.TB, kvSize int, totalKeys int, keyPrefix string) {
 	dbWrapper.CloseDB(tb)
 }
 
-func constructDummyTx(tb testing.TB) *protos.Transaction {
+func constructDummyTx(tb testing.TB) *protos.InBlockTransaction {
 	uuid := util.GenerateUUID()
 	tx, err := protos.NewTransaction(protos.ChaincodeID{Path: "dummyChaincodeId"}, uuid, "dummyFunction", []string{"dummyParamValue1, dummyParamValue2"})
 	testutil.AssertNil(tb, err)
-	return tx
+	return newTestInBlockTx(tb, tx)
 }
 
 func disableLogging() {
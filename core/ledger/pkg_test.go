@@ -20,6 +20,9 @@ import (
 	"os"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	chstatemgmt "github.com/hyperledger/fabric/core/ledger/state/chaincodest/statemgmt"
+	txsetstmgmt "github.com/hyperledger/fabric/core/ledger/state/txsetst/statemgmt"
 	"github.com/hyperledger/fabric/core/ledger/testutil"
 	"github.com/hyperledger/fabric/core/util"
 	"github.com/hyperledger/fabric/protos"
@@ -49,7 +52,7 @@ func newTestBlockchainWrapper(t *testing.T) *blockchainTestWrapper {
 func (testWrapper *blockchainTestWrapper) addNewBlock(block *protos.Block, stateHash []byte) uint64 {
 	writeBatch := gorocksdb.NewWriteBatch()
 	defer writeBatch.Destroy()
-	newBlockNumber, err := testWrapper.blockchain.addPersistenceChangesForNewBlock(context.TODO(), block, stateHash, writeBatch)
+	newBlockNumber, err := testWrapper.blockchain.addPersistenceChangesForNewBlock(context.TODO(), block, stateHash, nil, writeBatch)
 	testutil.AssertNoError(testWrapper.t, err, "Error while adding a new block")
 	testDBWrapper.WriteToDB(testWrapper.t, writeBatch)
 	testWrapper.blockchain.blockPersistenceStatus(true)
@@ -80,19 +83,19 @@ func (testWrapper *blockchainTestWrapper) getBlockByHash(blockHash []byte) *prot
 	return block
 }
 
-func (testWrapper *blockchainTestWrapper) getTransaction(blockNumber uint64, txIndex uint64) *protos.Transaction {
+func (testWrapper *blockchainTestWrapper) getTransaction(blockNumber uint64, txIndex uint64) *protos.InBlockTransaction {
 	tx, err := testWrapper.blockchain.getTransaction(blockNumber, txIndex)
 	testutil.AssertNoError(testWrapper.t, err, "Error while getting tx from blockchain")
 	return tx
 }
 
-func (testWrapper *blockchainTestWrapper) getTransactionByBlockHash(blockHash []byte, txIndex uint64) *protos.Transaction {
+func (testWrapper *blockchainTestWrapper) getTransactionByBlockHash(blockHash []byte, txIndex uint64) *protos.InBlockTransaction {
 	tx, err := testWrapper.blockchain.getTransactionByBlockHash(blockHash, txIndex)
 	testutil.AssertNoError(testWrapper.t, err, "Error while getting tx from blockchain")
 	return tx
 }
 
-func (testWrapper *blockchainTestWrapper) getTransactionByID(txID string) *protos.Transaction {
+func (testWrapper *blockchainTestWrapper) getTransactionByID(txID string) *protos.InBlockTransaction {
 	tx, err := testWrapper.blockchain.getTransactionByID(txID)
 	testutil.AssertNoError(testWrapper.t, err, "Error while getting tx from blockchain")
 	return tx
@@ -117,7 +120,7 @@ func (testWrapper *blockchainTestWrapper) populateBlockChainWithSampleData() (bl
 		return nil, nil, err
 	}
 	// Now we add the transaction to the block 2 and add the block to the chain
-	transactions2a := []*protos.Transaction{transaction2a}
+	transactions2a := []*protos.InBlockTransaction{newTestInBlockTx(testWrapper.t, transaction2a)}
 	block2 := protos.NewBlock(transactions2a, nil)
 
 	allBlocks = append(allBlocks, block2)
@@ -132,7 +135,7 @@ func (testWrapper *blockchainTestWrapper) populateBlockChainWithSampleData() (bl
 		return nil, nil, err
 	}
 	// Create the third block and add it to the chain
-	transactions3a := []*protos.Transaction{transaction3a}
+	transactions3a := []*protos.InBlockTransaction{newTestInBlockTx(testWrapper.t, transaction3a)}
 	block3 := protos.NewBlock(transactions3a, nil)
 	allBlocks = append(allBlocks, block3)
 	allHashes = append(allHashes, []byte("stateHash3"))
@@ -142,15 +145,26 @@ func (testWrapper *blockchainTestWrapper) populateBlockChainWithSampleData() (bl
 	return allBlocks, allHashes, nil
 }
 
-func buildTestTx(tb testing.TB) (*protos.Transaction, string) {
+// newTestInBlockTx wraps tx in a transactions set of its own, the way a single chaincode transaction is recorded in a block
+func newTestInBlockTx(tb testing.TB, tx *protos.Transaction) *protos.InBlockTransaction {
+	txBytes, err := proto.Marshal(tx)
+	testutil.AssertNoError(tb, err, "Error while marshalling the transaction")
+	return &protos.InBlockTransaction{
+		Transaction: &protos.InBlockTransaction_TransactionSet{TransactionSet: &protos.TransactionSet{Transactions: [][]byte{txBytes}}},
+		Txid:        tx.Txid,
+		Timestamp:   tx.Timestamp,
+	}
+}
+
+func buildTestTx(tb testing.TB) (*protos.InBlockTransaction, string) {
 	uuid := util.GenerateUUID()
 	tx, err := protos.NewTransaction(protos.ChaincodeID{Path: "testUrl"}, uuid, "anyfunction", []string{"param1, param2"})
 	testutil.AssertNil(tb, err)
-	return tx, uuid
+	return newTestInBlockTx(tb, tx), uuid
 }
 
 func buildTestBlock(t *testing.T) (*protos.Block, error) {
-	transactions := []*protos.Transaction{}
+	transactions := []*protos.InBlockTransaction{}
 	tx, _ := buildTestTx(t)
 	transactions = append(transactions, tx)
 	block := protos.NewBlock(transactions, nil)
@@ -192,8 +206,9 @@ func (ledgerTestWrapper *ledgerTestWrapper) PutRawBlock(block *protos.Block, blo
 	testutil.AssertNoError(ledgerTestWrapper.tb, err, "error while verifying chain")
 }
 
-func (ledgerTestWrapper *ledgerTestWrapper) GetStateDelta(blockNumber uint64) *state.StateDelta {
-	delta, err := ledgerTestWrapper.ledger.GetStateDelta(blockNumber)
+// GetStateDelta returns the chaincode state delta of the block, the transactions sets are not changed by these tests
+func (ledgerTestWrapper *ledgerTestWrapper) GetStateDelta(blockNumber uint64) *chstatemgmt.StateDelta {
+	delta, _, err := ledgerTestWrapper.ledger.GetStateDelta(blockNumber)
 	testutil.AssertNoError(ledgerTestWrapper.tb, err, "error while getting state delta from ledger")
 	return delta
 }
@@ -204,8 +219,8 @@ func (ledgerTestWrapper *ledgerTestWrapper) GetTempStateHash() []byte {
 	return hash
 }
 
-func (ledgerTestWrapper *ledgerTestWrapper) ApplyStateDelta(id interface{}, delta *state.StateDelta) {
-	err := ledgerTestWrapper.ledger.ApplyStateDelta(id, delta)
+func (ledgerTestWrapper *ledgerTestWrapper) ApplyStateDelta(id interface{}, delta *chstatemgmt.StateDelta) {
+	err := ledgerTestWrapper.ledger.ApplyStateDelta(id, delta, txsetstmgmt.NewTxSetStateDelta())
 	testutil.AssertNoError(ledgerTestWrapper.tb, err, "error applying state delta")
 }
 
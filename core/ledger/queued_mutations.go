@@ -0,0 +1,162 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger/state/chaincodest"
+	"github.com/hyperledger/fabric/core/ledger/state/chaincodest/statemgmt"
+	"github.com/hyperledger/fabric/protos"
+)
+
+// A chaincode can request the mutation of transactions sets through its shim. The mutant transactions
+// it requests are kept per chaincode transaction and queued once that transaction succeeds. The queue
+// is executed at the start of the next block, which also records the mutant transactions. Since every
+// validator executes the same transactions, every validator queues the same mutations.
+// The queue is kept in the chaincode state under a reserved chaincode ID, so that it is part of the
+// state hash and carried by a state transfer. It is changed by transactions of the ledger itself: their
+// read/write sets match no transaction of the block, so a replay of the block does not change the queue.
+
+const (
+	queuedMutationsChaincodeID = "#queuedMutations"
+	queuedMutationsKey         = "queue"
+	// the ID of the transactions changing the queue
+	queuedMutationsTxID = "#queuedMutations"
+)
+
+// QueueMutation records the mutant transaction requested by a chaincode while executing the transaction
+// txID. The mutation is queued for the next block only if the transaction succeeds
+func (ledger *Ledger) QueueMutation(txID string, mutantTx *protos.InBlockTransaction) error {
+	if ledger.currentID == nil {
+		return fmt.Errorf("No transaction batch in progress, unable to queue the mutation requested by transaction %s", txID)
+	}
+	queue, err := ledger.getQueuedMutations()
+	if err != nil {
+		return err
+	}
+	for _, queued := range queue {
+		if queued.Txid == mutantTx.Txid {
+			return fmt.Errorf("The mutant transaction %s is already queued.", mutantTx.Txid)
+		}
+	}
+	for _, pending := range ledger.pendingMutations[txID] {
+		if pending.Txid == mutantTx.Txid {
+			return fmt.Errorf("The mutant transaction %s was already requested by transaction %s.", mutantTx.Txid, txID)
+		}
+	}
+	if ledger.pendingMutations == nil {
+		ledger.pendingMutations = make(map[string][]*protos.InBlockTransaction)
+	}
+	ledger.pendingMutations[txID] = append(ledger.pendingMutations[txID], mutantTx)
+	return nil
+}
+
+// DequeueMutations returns the mutant transactions queued by the previous block. They are returned
+// only once per transaction batch, and removed from the state of the batch
+func (ledger *Ledger) DequeueMutations() ([]*protos.InBlockTransaction, error) {
+	if ledger.mutationsDequeued {
+		return nil, nil
+	}
+	queue, err := ledger.getQueuedMutations()
+	if err != nil {
+		return nil, err
+	}
+	if len(queue) > 0 {
+		ledger.chaincodeState.TxBegin(queuedMutationsTxID)
+		err = ledger.chaincodeState.Delete(queuedMutationsChaincodeID, queuedMutationsKey)
+		ledger.chaincodeState.TxFinish(queuedMutationsTxID, err == nil)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to remove the queued mutations from the state. (%s)", err)
+		}
+	}
+	ledger.queuedMutations = nil
+	ledger.mutationsDequeued = true
+	return queue, nil
+}
+
+// finishQueuedMutations queues the mutations requested by the chaincode transaction txID if it succeeded
+func (ledger *Ledger) finishQueuedMutations(txID string, txSuccessful bool) {
+	pending, ok := ledger.pendingMutations[txID]
+	if !ok {
+		return
+	}
+	delete(ledger.pendingMutations, txID)
+	if !txSuccessful {
+		return
+	}
+	queue, err := ledger.getQueuedMutations()
+	var queueBytes []byte
+	if err == nil {
+		queue = append(queue, pending...)
+		queueBytes, err = proto.Marshal(&protos.TransactionBlock{Transactions: queue})
+	}
+	if err == nil {
+		ledger.chaincodeState.TxBegin(queuedMutationsTxID)
+		err = ledger.chaincodeState.Set(queuedMutationsChaincodeID, queuedMutationsKey, queueBytes)
+		ledger.chaincodeState.TxFinish(queuedMutationsTxID, err == nil)
+	}
+	if err != nil {
+		// The state of the batch would no longer match the one of the other validators
+		panic(fmt.Errorf("Unable to queue the mutations requested by transaction %s. (%s)", txID, err))
+	}
+	ledger.queuedMutations = queue
+}
+
+// getQueuedMutations returns the queue in the ongoing batch, read from the committed state the first time
+func (ledger *Ledger) getQueuedMutations() ([]*protos.InBlockTransaction, error) {
+	if ledger.queuedMutations != nil || ledger.mutationsDequeued {
+		return ledger.queuedMutations, nil
+	}
+	queueBytes, err := ledger.chaincodeState.Get(queuedMutationsChaincodeID, queuedMutationsKey, true)
+	if err != nil {
+		return nil, err
+	}
+	queue, err := unmarshalQueuedMutations(queueBytes)
+	if err != nil {
+		return nil, err
+	}
+	ledger.queuedMutations = queue
+	return queue, nil
+}
+
+// keepQueuedMutations replaces the queue in stateAtBlock, the state a reset starts from, by the current
+// one. The requests made by the replayed transactions are ignored, so the queue is the one of the last block
+func keepQueuedMutations(chaincodeState *chaincodest.State, stateAtBlock *statemgmt.StateDelta) error {
+	queueBytes, err := chaincodeState.Get(queuedMutationsChaincodeID, queuedMutationsKey, true)
+	if err != nil {
+		return err
+	}
+	if queueBytes == nil {
+		stateAtBlock.Delete(queuedMutationsChaincodeID, queuedMutationsKey, nil)
+	} else {
+		stateAtBlock.Set(queuedMutationsChaincodeID, queuedMutationsKey, queueBytes, nil)
+	}
+	return nil
+}
+
+func unmarshalQueuedMutations(queueBytes []byte) ([]*protos.InBlockTransaction, error) {
+	if queueBytes == nil {
+		return nil, nil
+	}
+	queue := &protos.TransactionBlock{}
+	if err := proto.Unmarshal(queueBytes, queue); err != nil {
+		return nil, fmt.Errorf("Unable to unmarshal the queued mutations. (%s)", err)
+	}
+	return queue.Transactions, nil
+}
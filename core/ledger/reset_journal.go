@@ -0,0 +1,260 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/state"
+	"github.com/tecbot/gorocksdb"
+)
+
+// A reset replays the blocks following a mutation, overwriting the chaincode state and the data
+// stored per block for it. The mutations are part of the ongoing batch, so the replayed state is only
+// valid once that batch is committed. The reset journal records the progress of the reset and a copy
+// of the per block data it overwrote, so that a reset whose batch is not committed (because it is
+// rolled back or because the peer crashed) can always be rolled back to the state of the chain.
+
+const (
+	resetJournalReplaying   = uint64(0)
+	resetJournalRollingBack = uint64(1)
+)
+
+var resetJournalKey = []byte("resetJournal")
+var resetBackupPrefix = []byte("backup_")
+
+type resetJournal struct {
+	phase uint64
+	// the state was reset to the end of baseBlock
+	baseBlock uint64
+	// the size of the blockchain when the reset started
+	targetBlock uint64
+	// the next block to be replayed
+	nextBlock uint64
+}
+
+func (journal *resetJournal) String() string {
+	return fmt.Sprintf("phase=[%d], baseBlock=[%d], targetBlock=[%d], nextBlock=[%d]",
+		journal.phase, journal.baseBlock, journal.targetBlock, journal.nextBlock)
+}
+
+func (journal *resetJournal) marshal() []byte {
+	var buffer bytes.Buffer
+	buffer.Write(encodeUint64(journal.phase))
+	buffer.Write(encodeUint64(journal.baseBlock))
+	buffer.Write(encodeUint64(journal.targetBlock))
+	buffer.Write(encodeUint64(journal.nextBlock))
+	return buffer.Bytes()
+}
+
+func unmarshalResetJournal(journalBytes []byte) (*resetJournal, error) {
+	if len(journalBytes) != 32 {
+		return nil, fmt.Errorf("Invalid reset journal length [%d]", len(journalBytes))
+	}
+	return &resetJournal{
+		phase:       decodeToUint64(journalBytes[0:8]),
+		baseBlock:   decodeToUint64(journalBytes[8:16]),
+		targetBlock: decodeToUint64(journalBytes[16:24]),
+		nextBlock:   decodeToUint64(journalBytes[24:32]),
+	}, nil
+}
+
+// fetchResetJournal returns the journal of the ongoing reset, or nil if there is none
+func fetchResetJournal() (*resetJournal, error) {
+	journalBytes, err := db.GetDBHandle().GetFromResetJournalCF(resetJournalKey)
+	if err != nil {
+		return nil, err
+	}
+	if journalBytes == nil {
+		return nil, nil
+	}
+	return unmarshalResetJournal(journalBytes)
+}
+
+func (journal *resetJournal) addToWriteBatch(writeBatch *gorocksdb.WriteBatch) {
+	writeBatch.PutCF(db.GetDBHandle().ResetJournalCF, resetJournalKey, journal.marshal())
+}
+
+func (journal *resetJournal) persist() error {
+	writeBatch := gorocksdb.NewWriteBatch()
+	defer writeBatch.Destroy()
+	journal.addToWriteBatch(writeBatch)
+	return writeBatchToDB(writeBatch)
+}
+
+// resetOverwrittenCFs returns the column families holding per block data of the chaincode state
+// which are overwritten when a block is replayed
+func resetOverwrittenCFs() map[string]*gorocksdb.ColumnFamilyHandle {
+	openchainDB := db.GetDBHandle()
+	return map[string]*gorocksdb.ColumnFamilyHandle{
+		"stateDelta": openchainDB.StateDeltaCF,
+		"blockState": openchainDB.BlockStateCF,
+		"txRWSet":    openchainDB.TxRWSetCF,
+	}
+}
+
+func encodeResetBackupKey(cfName string, blockNumber uint64) []byte {
+	key := append([]byte{}, resetBackupPrefix...)
+	key = append(key, cfName...)
+	key = append(key, 0)
+	return append(key, encodeUint64(blockNumber)...)
+}
+
+func decodeResetBackupKey(key []byte) (string, uint64, error) {
+	key = key[len(resetBackupPrefix):]
+	sep := bytes.IndexByte(key, 0)
+	if sep < 0 || len(key)-sep-1 != 8 {
+		return "", 0, fmt.Errorf("Invalid reset backup key [%x]", key)
+	}
+	return string(key[:sep]), decodeToUint64(key[sep+1:]), nil
+}
+
+// addResetBackupsToWriteBatch saves in the journal the original per block data of blockNumber before it
+// is overwritten. Data already saved by an earlier attempt is kept, since that is the original one.
+func addResetBackupsToWriteBatch(blockNumber uint64, writeBatch *gorocksdb.WriteBatch) error {
+	openchainDB := db.GetDBHandle()
+	for cfName, cf := range resetOverwrittenCFs() {
+		backupKey := encodeResetBackupKey(cfName, blockNumber)
+		backup, err := openchainDB.GetFromResetJournalCF(backupKey)
+		if err != nil {
+			return err
+		}
+		if backup != nil {
+			continue
+		}
+		original, err := openchainDB.Get(cf, stcomm.EncodeStateDeltaKey(blockNumber))
+		if err != nil {
+			return err
+		}
+		// The first byte tells whether the data existed
+		if original == nil {
+			backup = []byte{0}
+		} else {
+			backup = append([]byte{1}, original...)
+		}
+		writeBatch.PutCF(openchainDB.ResetJournalCF, backupKey, backup)
+	}
+	return nil
+}
+
+// addResetRestoreToWriteBatch restores the per block data saved in the journal
+func addResetRestoreToWriteBatch(writeBatch *gorocksdb.WriteBatch) error {
+	openchainDB := db.GetDBHandle()
+	cfs := resetOverwrittenCFs()
+	itr := openchainDB.GetResetJournalCFIterator()
+	defer itr.Close()
+	for itr.Seek(resetBackupPrefix); itr.ValidForPrefix(resetBackupPrefix); itr.Next() {
+		cfName, blockNumber, err := decodeResetBackupKey(stcomm.Copy(itr.Key().Data()))
+		if err != nil {
+			return err
+		}
+		cf, ok := cfs[cfName]
+		if !ok {
+			return fmt.Errorf("Unknown column family [%s] in the reset journal", cfName)
+		}
+		backup := stcomm.Copy(itr.Value().Data())
+		if len(backup) == 0 || backup[0] == 0 {
+			writeBatch.DeleteCF(cf, stcomm.EncodeStateDeltaKey(blockNumber))
+		} else {
+			writeBatch.PutCF(cf, stcomm.EncodeStateDeltaKey(blockNumber), backup[1:])
+		}
+	}
+	return itr.Err()
+}
+
+// addResetJournalDeletionToWriteBatch deletes the journal and all the data saved in it
+func addResetJournalDeletionToWriteBatch(writeBatch *gorocksdb.WriteBatch) error {
+	openchainDB := db.GetDBHandle()
+	itr := openchainDB.GetResetJournalCFIterator()
+	defer itr.Close()
+	for itr.SeekToFirst(); itr.Valid(); itr.Next() {
+		writeBatch.DeleteCF(openchainDB.ResetJournalCF, stcomm.Copy(itr.Key().Data()))
+	}
+	return itr.Err()
+}
+
+func writeBatchToDB(writeBatch *gorocksdb.WriteBatch) error {
+	opt := gorocksdb.NewDefaultWriteOptions()
+	defer opt.Destroy()
+	return db.GetDBHandle().DB.Write(opt, writeBatch)
+}
+
+// rollbackReset brings the chaincode state and its per block data back to what they were before the reset
+// recorded in the journal started. It can be executed again if interrupted.
+func (ledger *Ledger) rollbackReset(journal *resetJournal) error {
+	ledgerLogger.Infof("Rolling back the state reset: %s", journal)
+	if journal.phase != resetJournalRollingBack {
+		journal.phase = resetJournalRollingBack
+		if err := journal.persist(); err != nil {
+			return fmt.Errorf("Unable to record the roll back of the state reset. (%s)", err)
+		}
+	}
+
+	restoreBatch := gorocksdb.NewWriteBatch()
+	defer restoreBatch.Destroy()
+	if err := addResetRestoreToWriteBatch(restoreBatch); err != nil {
+		return fmt.Errorf("Unable to read the data overwritten by the state reset. (%s)", err)
+	}
+	if err := writeBatchToDB(restoreBatch); err != nil {
+		return fmt.Errorf("Unable to restore the data overwritten by the state reset. (%s)", err)
+	}
+
+	ledger.chaincodeState.ClearInMemoryChanges(false)
+	if journal.targetBlock > 0 {
+		stateAtHead, err := ledger.chaincodeState.FetchBlockStateDeltaFromDB(journal.targetBlock - 1)
+		if err != nil {
+			return fmt.Errorf("Unable to retrieve the state at block %d. (%s)", journal.targetBlock-1, err)
+		}
+		if err = ledger.chaincodeState.DeleteState(); err != nil {
+			return fmt.Errorf("Unable to erase the state. (%s)", err)
+		}
+		ledger.chaincodeState.ApplyStateDelta(stateAtHead)
+		if err = ledger.chaincodeState.CommitStateDelta(); err != nil {
+			return fmt.Errorf("Unable to restore the state at block %d. (%s)", journal.targetBlock-1, err)
+		}
+		ledger.chaincodeState.ClearInMemoryChanges(true)
+	}
+
+	// The journal is deleted only once the state is restored
+	deletionBatch := gorocksdb.NewWriteBatch()
+	defer deletionBatch.Destroy()
+	if err := addResetJournalDeletionToWriteBatch(deletionBatch); err != nil {
+		return fmt.Errorf("Unable to read the reset journal. (%s)", err)
+	}
+	if err := writeBatchToDB(deletionBatch); err != nil {
+		return fmt.Errorf("Unable to delete the reset journal. (%s)", err)
+	}
+	ledger.blockchain.abortReset()
+	ledgerLogger.Info("State reset rolled back.")
+	return nil
+}
+
+// recoverInterruptedReset rolls back a reset left behind by a crash. The batch carrying the mutations was not
+// committed, so the state must match the chain without them.
+func (ledger *Ledger) recoverInterruptedReset() error {
+	journal, err := fetchResetJournal()
+	if err != nil {
+		return fmt.Errorf("Unable to read the reset journal. (%s)", err)
+	}
+	if journal == nil {
+		return nil
+	}
+	ledgerLogger.Warningf("Found an interrupted state reset: %s", journal)
+	return ledger.rollbackReset(journal)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/state"
+	txsetstmgmt "github.com/hyperledger/fabric/core/ledger/state/txsetst/statemgmt"
+	"github.com/hyperledger/fabric/protos"
+)
+
+// A mutant transaction can schedule the mutation of a transactions set instead of applying it. The scheduled
+// mutation is kept in the state of the set until it is due or cancelled. To avoid scanning the state of all the
+// sets at every block, the IDs of the sets with a scheduled mutation are kept in memory: they are read from the
+// committed state the first time they are needed, then updated by the committed batches. Since they are derived
+// from the state only, every validator finds the same due mutations.
+
+// GetDueScheduledTxSetIDs returns the IDs of the transactions sets whose scheduled mutation is due at the start of
+// block blockNumber. blockTime is the timestamp of the previous block, see GetPreviousBlockTime, nil if there is
+// none. The sets that can no longer be mutated are skipped. The IDs are sorted so that every validator applies the
+// mutations in the same order
+func (ledger *Ledger) GetDueScheduledTxSetIDs(blockNumber uint64, blockTime *timestamp.Timestamp) ([]string, error) {
+	if ledger.scheduledTxSets == nil {
+		if err := ledger.loadScheduledTxSets(); err != nil {
+			return nil, err
+		}
+	}
+	var due []string
+	for txSetID := range ledger.scheduledTxSets {
+		txSetStValue, err := ledger.GetTxSetState(txSetID, true)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to retrieve the txSet state, txID: %s, err: %s.", txSetID, err)
+		}
+		if txSetStValue == nil || !txSetStValue.ScheduledMutation.IsDue(blockNumber, blockTime) {
+			continue
+		}
+		if !ledger.IsTxSetMutable(txSetStValue, blockNumber) {
+			ledgerLogger.Debugf("The mutation scheduled on the tx set with ID: %s is due, but the set can no longer be mutated", txSetID)
+			continue
+		}
+		due = append(due, txSetID)
+	}
+	sort.Strings(due)
+	return due, nil
+}
+
+// loadScheduledTxSets reads the IDs of the sets with a scheduled mutation from the committed state
+func (ledger *Ledger) loadScheduledTxSets() error {
+	dbSnapshot := db.GetDBHandle().GetSnapshot()
+	snapshot, err := ledger.txSetState.GetTxSetSnapshot(0, dbSnapshot)
+	if err != nil {
+		dbSnapshot.Release()
+		return err
+	}
+	defer snapshot.Release()
+	scheduled := make(map[string]bool)
+	for snapshot.Next() {
+		k, v := snapshot.GetRawKeyValue()
+		txSetStValue, err := protos.UnmarshalTxSetStateValue(v)
+		if err != nil {
+			return fmt.Errorf("Unable to read the scheduled mutations. (%s)", err)
+		}
+		if txSetStValue.ScheduledMutation != nil {
+			scheduled[stcomm.DecomposeTxSetKey(k)] = true
+		}
+	}
+	ledgerLogger.Debugf("Found %d tx sets with a scheduled mutation", len(scheduled))
+	ledger.scheduledTxSets = scheduled
+	return nil
+}
+
+// updateScheduledTxSets records the scheduled mutations changed by a committed batch
+func (ledger *Ledger) updateScheduledTxSets(delta *txsetstmgmt.TxSetStateDelta) {
+	if ledger.scheduledTxSets == nil {
+		return
+	}
+	for _, txSetID := range delta.GetUpdatedTxSetIDs(false) {
+		if delta.Get(txSetID).GetValue().GetScheduledMutation() != nil {
+			ledger.scheduledTxSets[txSetID] = true
+		} else {
+			delete(ledger.scheduledTxSets, txSetID)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincodest
+
+import (
+	"errors"
+
+	"github.com/hyperledger/fabric/core/ledger/state"
+	"github.com/hyperledger/fabric/core/ledger/state/chaincodest/statemgmt"
+	"github.com/tecbot/gorocksdb"
+)
+
+// NewMemoryState constructs a State held in memory, whose committed state is the given full image of the
+// state (a delta from genesis). Its changes are committed to the image by CommitInMemory, the DB is never
+// written. It is meant for simulations, which must not affect the state of the peer
+func NewMemoryState(image *statemgmt.StateDelta) *State {
+	committed := statemgmt.NewStateDelta()
+	committed.ApplyChanges(image)
+	return &State{stateImpl: &memoryStateImpl{image: committed}, stateDelta: statemgmt.NewStateDelta(),
+		currentTxStateDelta: statemgmt.NewStateDelta(), txStateDeltaHash: make(map[string][]byte),
+		historyStateDeltaSize: 0, checkpointInterval: defaultBlockStateCheckpointInterval}
+}
+
+// CommitInMemory commits the changes of the ongoing batch to the image of a State constructed by NewMemoryState
+func (state *State) CommitInMemory() error {
+	if _, ok := state.stateImpl.(*memoryStateImpl); !ok {
+		return errors.New("Only a state held in memory can be committed in memory")
+	}
+	if state.updateStateImpl {
+		state.stateImpl.PrepareWorkingSet(state.stateDelta)
+		state.updateStateImpl = false
+	}
+	state.ClearInMemoryChanges(true)
+	return nil
+}
+
+// GetMemoryImage returns a copy of the committed image of a State constructed by NewMemoryState
+func (state *State) GetMemoryImage() (*statemgmt.StateDelta, error) {
+	impl, ok := state.stateImpl.(*memoryStateImpl)
+	if !ok {
+		return nil, errors.New("The state is not held in memory")
+	}
+	image := statemgmt.NewStateDelta()
+	image.ApplyChanges(impl.image)
+	return image, nil
+}
+
+// memoryStateImpl implements statemgmt.HashableState on a full image of the state held in memory. The working
+// set is applied to the image when the changes are cleared as persisted
+type memoryStateImpl struct {
+	image      *statemgmt.StateDelta
+	workingSet *statemgmt.StateDelta
+}
+
+func (impl *memoryStateImpl) Initialize(configs map[string]interface{}) error {
+	return nil
+}
+
+func (impl *memoryStateImpl) Get(chaincodeID string, key string) ([]byte, error) {
+	if value := impl.image.Get(chaincodeID, key); value != nil {
+		return value.GetValue(), nil
+	}
+	return nil, nil
+}
+
+func (impl *memoryStateImpl) PrepareWorkingSet(stateDelta *statemgmt.StateDelta) error {
+	impl.workingSet = stateDelta
+	return nil
+}
+
+func (impl *memoryStateImpl) ComputeCryptoHash() ([]byte, error) {
+	state := statemgmt.NewStateDelta()
+	state.ApplyChanges(impl.image)
+	if impl.workingSet != nil {
+		state.ApplyChanges(impl.workingSet)
+	}
+	return state.ComputeCryptoHash(), nil
+}
+
+func (impl *memoryStateImpl) AddChangesForPersistence(writeBatch *gorocksdb.WriteBatch) error {
+	return errors.New("A state held in memory cannot be persisted")
+}
+
+func (impl *memoryStateImpl) ClearWorkingSet(changesPersisted bool) {
+	if changesPersisted && impl.workingSet != nil {
+		impl.image.ApplyChanges(impl.workingSet)
+	}
+	impl.workingSet = nil
+}
+
+func (impl *memoryStateImpl) GetStateSnapshotIterator(snapshot *gorocksdb.Snapshot) (stcomm.StateSnapshotIterator, error) {
+	return nil, errors.New("A state held in memory has no snapshot")
+}
+
+func (impl *memoryStateImpl) GetRangeScanIterator(chaincodeID string, startKey string, endKey string) (stcomm.RangeScanIterator, error) {
+	return statemgmt.NewStateDeltaRangeScanIterator(impl.image, chaincodeID, startKey, endKey), nil
+}
+
+func (impl *memoryStateImpl) PerfHintKeyChanged(chaincodeID string, key string) {
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package chaincodest
 
 import (
+	"bytes"
 	"fmt"
+	"sync"
 
 	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/ledger/state"
@@ -26,6 +28,7 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/state/chaincodest/statemgmt"
 	"github.com/hyperledger/fabric/core/ledger/state/chaincodest/trie"
 	"github.com/op/go-logging"
+	"github.com/spf13/viper"
 	"github.com/tecbot/gorocksdb"
 )
 
@@ -49,9 +52,26 @@ var (
 
 var defaultStateImpl = buckettreeType
 
+// The state at each block is kept in blockStateCF to be able to reset the state to any block. A full image
+// of the state (a checkpoint) is only stored every checkpointInterval blocks, the other blocks store the
+// changes they made (a forward delta). The state at a block is rebuilt from the nearest checkpoint preceding it.
+const defaultBlockStateCheckpointInterval = 100
+
+const (
+	blockStateCheckpoint   = byte(0)
+	blockStateForwardDelta = byte(1)
+)
+
+// Each entry of blockStateCF starts with its kind. Earlier versions stored a checkpoint at every block, without
+// the kind, so the format of the entries is recorded in persistCF and the entries of older DBs are migrated.
+var blockStateFormatKey = []byte("blockStateFormat")
+
+const blockStateFormatWithKind = byte(1)
+
 // State structure for maintaining world state.
 // This encapsulates a particular implementation for managing the state persistence
-// This is not thread safe
+// This is not thread safe, except between BeginConcurrentTxs and EndConcurrentTxs where the txs
+// accessing the state through their txID can be executed concurrently
 type State struct {
 	stateImpl             statemgmt.HashableState
 	stateDelta            *statemgmt.StateDelta
@@ -60,6 +80,21 @@ type State struct {
 	txStateDeltaHash      map[string][]byte
 	updateStateImpl       bool
 	historyStateDeltaSize uint64
+	currentTxRWSet        *statemgmt.TxReadWriteSet
+	txRWSets              []*statemgmt.TxReadWriteSet
+	checkpointInterval    uint64
+	concurrentTxsLock     sync.RWMutex
+	concurrentTxs         map[string]*concurrentTx
+}
+
+// concurrentTx holds the changes and the read/write set of a tx executed concurrently with other txs.
+// They are kept apart from the changes of the batch until the concurrent txs end
+type concurrentTx struct {
+	sync.Mutex
+	stateDelta *statemgmt.StateDelta
+	rwSet      *statemgmt.TxReadWriteSet
+	finished   bool
+	successful bool
 }
 
 // NewState constructs a new State. This Initializes encapsulated state implementation
@@ -80,37 +115,185 @@ func NewState() *State {
 	if err != nil {
 		panic(fmt.Errorf("Error during initialization of state implementation: %s", err))
 	}
-	return &State{stateImpl, statemgmt.NewStateDelta(), statemgmt.NewStateDelta(), "", make(map[string][]byte),
-		false, uint64(confData.DeltaHistorySize)}
+	if err = migrateBlockStateFormat(); err != nil {
+		panic(fmt.Errorf("Error during the migration of the block states: %s", err))
+	}
+	checkpointInterval := viper.GetInt("ledger.state.blockStateCheckpointInterval")
+	if checkpointInterval <= 0 {
+		checkpointInterval = defaultBlockStateCheckpointInterval
+	}
+	return &State{stateImpl: stateImpl, stateDelta: statemgmt.NewStateDelta(), currentTxStateDelta: statemgmt.NewStateDelta(),
+		txStateDeltaHash: make(map[string][]byte), historyStateDeltaSize: uint64(confData.DeltaHistorySize),
+		checkpointInterval: uint64(checkpointInterval)}
 }
 
 // TxBegin marks begin of a new tx. If a tx is already in progress, this call panics
 func (state *State) TxBegin(txID string) {
+	state.txBegin("", txID)
+}
+
+// TxBeginForSet marks begin of a new tx executed as the default transaction of the transactions set txSetID.
+// The read/write set of the tx is recorded under txSetID
+func (state *State) TxBeginForSet(txSetID string, txID string) {
+	state.txBegin(txSetID, txID)
+}
+
+func (state *State) txBegin(txSetID string, txID string) {
 	logger.Debugf("txBegin() for txId [%s]", txID)
+	state.concurrentTxsLock.Lock()
+	defer state.concurrentTxsLock.Unlock()
+	if state.concurrentTxs != nil {
+		if state.concurrentTxs[txID] != nil {
+			panic(fmt.Errorf("The tx [%s] is already in progress", txID))
+		}
+		rwSet := statemgmt.NewTxReadWriteSet(txID)
+		rwSet.TxSetID = txSetID
+		state.concurrentTxs[txID] = &concurrentTx{stateDelta: statemgmt.NewStateDelta(), rwSet: rwSet}
+		return
+	}
 	if state.txInProgress() {
 		panic(fmt.Errorf("A tx [%s] is already in progress. Received call for begin of another tx [%s]", state.currentTxID, txID))
 	}
 	state.currentTxID = txID
+	state.currentTxRWSet = statemgmt.NewTxReadWriteSet(txID)
+	state.currentTxRWSet.TxSetID = txSetID
 }
 
 // TxFinish marks the completion of on-going tx. If txID is not same as of the on-going tx, this call panics
 func (state *State) TxFinish(txID string, txSuccessful bool) {
 	logger.Debugf("txFinish() for txId [%s], txSuccessful=[%t]", txID, txSuccessful)
+	state.concurrentTxsLock.Lock()
+	defer state.concurrentTxsLock.Unlock()
+	if state.concurrentTxs != nil {
+		tx := state.concurrentTxs[txID]
+		if tx == nil {
+			panic(fmt.Errorf("Received call for finish of the tx [%s], which is not in progress", txID))
+		}
+		tx.Lock()
+		tx.finished = true
+		tx.successful = txSuccessful
+		tx.Unlock()
+		return
+	}
 	if state.currentTxID != txID {
 		panic(fmt.Errorf("Different txId in tx-begin [%s] and tx-finish [%s]", state.currentTxID, txID))
 	}
+	state.mergeTx(state.currentTxStateDelta, state.currentTxRWSet, txSuccessful)
+	state.currentTxStateDelta = statemgmt.NewStateDelta()
+	state.currentTxRWSet = nil
+	state.currentTxID = ""
+}
+
+// mergeTx adds the changes of a finished tx to the changes of the batch
+func (state *State) mergeTx(txStateDelta *statemgmt.StateDelta, rwSet *statemgmt.TxReadWriteSet, txSuccessful bool) {
 	if txSuccessful {
-		if !state.currentTxStateDelta.IsEmpty() {
-			logger.Debugf("txFinish() for txId [%s] merging state changes", txID)
-			state.stateDelta.ApplyChanges(state.currentTxStateDelta)
-			state.txStateDeltaHash[txID] = state.currentTxStateDelta.ComputeCryptoHash()
+		if !txStateDelta.IsEmpty() {
+			logger.Debugf("txFinish() for txId [%s] merging state changes", rwSet.TxID)
+			state.stateDelta.ApplyChanges(txStateDelta)
+			state.txStateDeltaHash[rwSet.TxID] = txStateDelta.ComputeCryptoHash()
 			state.updateStateImpl = true
 		} else {
-			state.txStateDeltaHash[txID] = nil
+			state.txStateDeltaHash[rwSet.TxID] = nil
 		}
+		rwSet.Writes = txStateDelta
 	}
-	state.currentTxStateDelta = statemgmt.NewStateDelta()
-	state.currentTxID = ""
+	// The reads of a failed tx are recorded as well, since a different state might make it succeed
+	rwSet.Successful = txSuccessful
+	state.txRWSets = append(state.txRWSets, rwSet)
+}
+
+// BeginConcurrentTxs lets the txs begun until EndConcurrentTxs be executed concurrently. Each of them
+// works on its own changes, which must be accessed through its txID (GetForTx, SetForTx...). The
+// changes of the batch are not modified until EndConcurrentTxs, so the txs must be independent:
+// a tx must not read a key written by another one
+func (state *State) BeginConcurrentTxs() {
+	state.concurrentTxsLock.Lock()
+	defer state.concurrentTxsLock.Unlock()
+	if state.txInProgress() {
+		panic(fmt.Errorf("A tx [%s] is in progress, unable to begin concurrent txs", state.currentTxID))
+	}
+	state.concurrentTxs = make(map[string]*concurrentTx)
+}
+
+// EndConcurrentTxs ends the concurrent txs begun after BeginConcurrentTxs. The finished txs of the sets
+// in txSetIDs are merged in the changes of the batch in the order of txSetIDs, as if they had been executed
+// one after the other. The changes of the other txs are discarded
+func (state *State) EndConcurrentTxs(txSetIDs []string) {
+	state.concurrentTxsLock.Lock()
+	defer state.concurrentTxsLock.Unlock()
+	bySet := make(map[string]*concurrentTx, len(state.concurrentTxs))
+	for _, tx := range state.concurrentTxs {
+		bySet[tx.rwSet.TxSetID] = tx
+	}
+	for _, txSetID := range txSetIDs {
+		if tx := bySet[txSetID]; tx != nil && tx.finished {
+			state.mergeTx(tx.stateDelta, tx.rwSet, tx.successful)
+		}
+	}
+	state.concurrentTxs = nil
+}
+
+// getConcurrentTx returns the concurrent tx txID, or nil if txID is not executed concurrently
+func (state *State) getConcurrentTx(txID string) *concurrentTx {
+	state.concurrentTxsLock.RLock()
+	defer state.concurrentTxsLock.RUnlock()
+	return state.concurrentTxs[txID]
+}
+
+// ReplayTx applies the changes recorded in rwSet as a new tx, without executing it again.
+// The reads of rwSet are kept as the reads of the replayed tx
+func (state *State) ReplayTx(rwSet *statemgmt.TxReadWriteSet) error {
+	state.TxBeginForSet(rwSet.TxSetID, rwSet.TxID)
+	if tx := state.getConcurrentTx(rwSet.TxID); tx != nil {
+		tx.rwSet.Reads = rwSet.Reads
+		tx.rwSet.RangeReads = rwSet.RangeReads
+	} else {
+		state.currentTxRWSet.Reads = rwSet.Reads
+		state.currentTxRWSet.RangeReads = rwSet.RangeReads
+	}
+	if rwSet.Writes != nil {
+		for _, chaincodeID := range rwSet.Writes.GetUpdatedChaincodeIds(true) {
+			for key, updatedValue := range rwSet.Writes.GetUpdates(chaincodeID) {
+				var err error
+				if updatedValue.IsDeleted() {
+					err = state.DeleteForTx(rwSet.TxID, chaincodeID, key)
+				} else {
+					err = state.SetForTx(rwSet.TxID, chaincodeID, key, updatedValue.GetValue())
+				}
+				if err != nil {
+					state.TxFinish(rwSet.TxID, false)
+					return err
+				}
+			}
+		}
+	}
+	state.TxFinish(rwSet.TxID, rwSet.Successful)
+	return nil
+}
+
+// GetTxReadWriteSet returns the read/write set recorded in the ongoing batch for the default
+// transaction of the transactions set txSetID, or nil if none was recorded
+func (state *State) GetTxReadWriteSet(txSetID string) *statemgmt.TxReadWriteSet {
+	state.concurrentTxsLock.RLock()
+	defer state.concurrentTxsLock.RUnlock()
+	for _, tx := range state.concurrentTxs {
+		if tx.rwSet.TxSetID == txSetID && tx.finished {
+			if tx.successful {
+				// The writes of the tx are recorded when it is merged
+				rwSet := *tx.rwSet
+				rwSet.Writes = tx.stateDelta
+				rwSet.Successful = true
+				return &rwSet
+			}
+			return tx.rwSet
+		}
+	}
+	for i := len(state.txRWSets) - 1; i >= 0; i-- {
+		if state.txRWSets[i].TxSetID == txSetID {
+			return state.txRWSets[i]
+		}
+	}
+	return nil
 }
 
 func (state *State) txInProgress() bool {
@@ -120,8 +303,30 @@ func (state *State) txInProgress() bool {
 // Get returns state for chaincodeID and key. If committed is false, this first looks in memory and if missing,
 // pulls from db. If committed is true, this pulls from the db only.
 func (state *State) Get(chaincodeID string, key string, committed bool) ([]byte, error) {
+	var rwSet *statemgmt.TxReadWriteSet
+	if state.txInProgress() {
+		rwSet = state.currentTxRWSet
+	}
+	return state.get(state.currentTxStateDelta, rwSet, chaincodeID, key, committed)
+}
+
+// GetForTx is Get in the context of the tx txID, which might be executed concurrently with other txs
+func (state *State) GetForTx(txID string, chaincodeID string, key string, committed bool) ([]byte, error) {
+	tx := state.getConcurrentTx(txID)
+	if tx == nil {
+		return state.Get(chaincodeID, key, committed)
+	}
+	tx.Lock()
+	defer tx.Unlock()
+	return state.get(tx.stateDelta, tx.rwSet, chaincodeID, key, committed)
+}
+
+func (state *State) get(txStateDelta *statemgmt.StateDelta, rwSet *statemgmt.TxReadWriteSet, chaincodeID string, key string, committed bool) ([]byte, error) {
 	if !committed {
-		valueHolder := state.currentTxStateDelta.Get(chaincodeID, key)
+		if rwSet != nil {
+			rwSet.AddRead(chaincodeID, key)
+		}
+		valueHolder := txStateDelta.Get(chaincodeID, key)
 		if valueHolder != nil {
 			return valueHolder.GetValue(), nil
 		}
@@ -136,6 +341,27 @@ func (state *State) Get(chaincodeID string, key string, committed bool) ([]byte,
 // GetRangeScanIterator returns an iterator to get all the keys (and values) between startKey and endKey
 // (assuming lexical order of the keys) for a chaincodeID.
 func (state *State) GetRangeScanIterator(chaincodeID string, startKey string, endKey string, committed bool) (stcomm.RangeScanIterator, error) {
+	var rwSet *statemgmt.TxReadWriteSet
+	if state.txInProgress() {
+		rwSet = state.currentTxRWSet
+	}
+	return state.getRangeScanIterator(state.currentTxStateDelta, rwSet, chaincodeID, startKey, endKey, committed)
+}
+
+// GetRangeScanIteratorForTx is GetRangeScanIterator in the context of the tx txID, which might be executed
+// concurrently with other txs
+func (state *State) GetRangeScanIteratorForTx(txID string, chaincodeID string, startKey string, endKey string, committed bool) (stcomm.RangeScanIterator, error) {
+	tx := state.getConcurrentTx(txID)
+	if tx == nil {
+		return state.GetRangeScanIterator(chaincodeID, startKey, endKey, committed)
+	}
+	tx.Lock()
+	defer tx.Unlock()
+	return state.getRangeScanIterator(tx.stateDelta, tx.rwSet, chaincodeID, startKey, endKey, committed)
+}
+
+func (state *State) getRangeScanIterator(txStateDelta *statemgmt.StateDelta, rwSet *statemgmt.TxReadWriteSet,
+	chaincodeID string, startKey string, endKey string, committed bool) (stcomm.RangeScanIterator, error) {
 	stateImplItr, err := state.stateImpl.GetRangeScanIterator(chaincodeID, startKey, endKey)
 	if err != nil {
 		return nil, err
@@ -144,8 +370,11 @@ func (state *State) GetRangeScanIterator(chaincodeID string, startKey string, en
 	if committed {
 		return stateImplItr, nil
 	}
+	if rwSet != nil {
+		rwSet.AddRangeRead(chaincodeID, startKey, endKey)
+	}
 	return newCompositeRangeScanIterator(
-		statemgmt.NewStateDeltaRangeScanIterator(state.currentTxStateDelta, chaincodeID, startKey, endKey),
+		statemgmt.NewStateDeltaRangeScanIterator(txStateDelta, chaincodeID, startKey, endKey),
 		statemgmt.NewStateDeltaRangeScanIterator(state.stateDelta, chaincodeID, startKey, endKey),
 		stateImplItr), nil
 }
@@ -156,19 +385,34 @@ func (state *State) Set(chaincodeID string, key string, value []byte) error {
 	if !state.txInProgress() {
 		panic("State can be changed only in context of a tx.")
 	}
+	return state.set(state.currentTxStateDelta, chaincodeID, key, value)
+}
+
+// SetForTx is Set in the context of the tx txID, which might be executed concurrently with other txs
+func (state *State) SetForTx(txID string, chaincodeID string, key string, value []byte) error {
+	tx := state.getConcurrentTx(txID)
+	if tx == nil {
+		return state.Set(chaincodeID, key, value)
+	}
+	logger.Debugf("set() txId=[%s], chaincodeID=[%s], key=[%s], value=[%#v]", txID, chaincodeID, key, value)
+	tx.Lock()
+	defer tx.Unlock()
+	return state.set(tx.stateDelta, chaincodeID, key, value)
+}
 
+func (state *State) set(txStateDelta *statemgmt.StateDelta, chaincodeID string, key string, value []byte) error {
 	// Check if a previous value is already set in the state delta
-	if state.currentTxStateDelta.IsUpdatedValueSet(chaincodeID, key) {
+	if txStateDelta.IsUpdatedValueSet(chaincodeID, key) {
 		// No need to bother looking up the previous value as we will not
 		// set it again. Just pass nil
-		state.currentTxStateDelta.Set(chaincodeID, key, value, nil)
+		txStateDelta.Set(chaincodeID, key, value, nil)
 	} else {
 		// Need to lookup the previous value
-		previousValue, err := state.Get(chaincodeID, key, true)
+		previousValue, err := state.stateImpl.Get(chaincodeID, key)
 		if err != nil {
 			return err
 		}
-		state.currentTxStateDelta.Set(chaincodeID, key, value, previousValue)
+		txStateDelta.Set(chaincodeID, key, value, previousValue)
 	}
 
 	return nil
@@ -180,19 +424,34 @@ func (state *State) Delete(chaincodeID string, key string) error {
 	if !state.txInProgress() {
 		panic("State can be changed only in context of a tx.")
 	}
+	return state.delete(state.currentTxStateDelta, chaincodeID, key)
+}
+
+// DeleteForTx is Delete in the context of the tx txID, which might be executed concurrently with other txs
+func (state *State) DeleteForTx(txID string, chaincodeID string, key string) error {
+	tx := state.getConcurrentTx(txID)
+	if tx == nil {
+		return state.Delete(chaincodeID, key)
+	}
+	logger.Debugf("delete() txId=[%s], chaincodeID=[%s], key=[%s]", txID, chaincodeID, key)
+	tx.Lock()
+	defer tx.Unlock()
+	return state.delete(tx.stateDelta, chaincodeID, key)
+}
 
+func (state *State) delete(txStateDelta *statemgmt.StateDelta, chaincodeID string, key string) error {
 	// Check if a previous value is already set in the state delta
-	if state.currentTxStateDelta.IsUpdatedValueSet(chaincodeID, key) {
+	if txStateDelta.IsUpdatedValueSet(chaincodeID, key) {
 		// No need to bother looking up the previous value as we will not
 		// set it again. Just pass nil
-		state.currentTxStateDelta.Delete(chaincodeID, key, nil)
+		txStateDelta.Delete(chaincodeID, key, nil)
 	} else {
 		// Need to lookup the previous value
-		previousValue, err := state.Get(chaincodeID, key, true)
+		previousValue, err := state.stateImpl.Get(chaincodeID, key)
 		if err != nil {
 			return err
 		}
-		state.currentTxStateDelta.Delete(chaincodeID, key, previousValue)
+		txStateDelta.Delete(chaincodeID, key, previousValue)
 	}
 
 	return nil
@@ -265,6 +524,7 @@ func (state *State) GetTxStateDeltaHash() map[string][]byte {
 func (state *State) ClearInMemoryChanges(changesPersisted bool) {
 	state.stateDelta = statemgmt.NewStateDelta()
 	state.txStateDeltaHash = make(map[string][]byte)
+	state.txRWSets = nil
 	state.stateImpl.ClearWorkingSet(changesPersisted)
 }
 
@@ -297,6 +557,19 @@ func (state *State) FetchStateDeltaFromDB(blockNumber uint64) (*statemgmt.StateD
 	return stateDelta, nil
 }
 
+// FetchTxReadWriteSetsFromDB fetches the read/write sets of the transactions of the given block.
+// It returns nil if they were not recorded
+func (state *State) FetchTxReadWriteSetsFromDB(blockNumber uint64) ([]*statemgmt.TxReadWriteSet, error) {
+	rwSetsBytes, err := db.GetDBHandle().GetFromTxRWSetCF(stcomm.EncodeStateDeltaKey(blockNumber))
+	if err != nil {
+		return nil, err
+	}
+	if rwSetsBytes == nil {
+		return nil, nil
+	}
+	return statemgmt.UnmarshalTxReadWriteSets(rwSetsBytes)
+}
+
 // CreateDeltaFromGenesis creates a state delta that if applied to the genesis block
 // produces the current state. This state delta is created only from the last committed state.
 func (state *State) CreateDeltaFromGenesis(blockNumber uint64) (*statemgmt.StateDelta, error) {
@@ -317,19 +590,148 @@ func (state *State) CreateDeltaFromGenesis(blockNumber uint64) (*statemgmt.State
 	return delta, nil
 }
 
-// FetchBlockStateDeltaFromDB returns a delta from the genesis block to the given block
+// FetchBlockStateDeltaFromDB returns a delta from the genesis block to the given block. It is rebuilt
+// from the nearest checkpoint preceding the block and the forward deltas of the blocks following it
 func (state *State) FetchBlockStateDeltaFromDB(blockNumber uint64) (*statemgmt.StateDelta, error) {
-	deltaBytes, err := db.GetDBHandle().GetFromBlockStateCF(stcomm.EncodeStateDeltaKey(blockNumber))
+	var forwardDeltas []*statemgmt.StateDelta
+	for checkpoint := blockNumber; ; checkpoint-- {
+		kind, delta, err := fetchBlockStateEntry(checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		if kind == blockStateCheckpoint {
+			logger.Debugf("Rebuilding the state at block [%d] from the checkpoint at block [%d]", blockNumber, checkpoint)
+			for i := len(forwardDeltas) - 1; i >= 0; i-- {
+				delta.ApplyChanges(forwardDeltas[i])
+			}
+			return delta, nil
+		}
+		if checkpoint == 0 {
+			return nil, fmt.Errorf("No state checkpoint found preceding block %d", blockNumber)
+		}
+		forwardDeltas = append(forwardDeltas, delta)
+	}
+}
+
+// FindBlockStateCheckpoint returns the block of the nearest checkpoint preceding (or at) the given block,
+// the one the state at that block is rebuilt from
+func (state *State) FindBlockStateCheckpoint(blockNumber uint64) (uint64, error) {
+	for checkpoint := blockNumber; ; checkpoint-- {
+		entry, err := db.GetDBHandle().GetFromBlockStateCF(stcomm.EncodeStateDeltaKey(checkpoint))
+		if err != nil {
+			return 0, err
+		}
+		if len(entry) == 0 {
+			return 0, fmt.Errorf("The state at block %d is missing", checkpoint)
+		}
+		if entry[0] == blockStateCheckpoint {
+			return checkpoint, nil
+		}
+		if checkpoint == 0 {
+			return 0, fmt.Errorf("No state checkpoint found preceding block %d", blockNumber)
+		}
+	}
+}
+
+func fetchBlockStateEntry(blockNumber uint64) (byte, *statemgmt.StateDelta, error) {
+	entry, err := db.GetDBHandle().GetFromBlockStateCF(stcomm.EncodeStateDeltaKey(blockNumber))
 	if err != nil {
-		return nil, err
+		return 0, nil, err
 	}
+	return decodeBlockStateEntry(blockNumber, entry)
+}
 
+func decodeBlockStateEntry(blockNumber uint64, entry []byte) (byte, *statemgmt.StateDelta, error) {
+	if len(entry) == 0 {
+		return 0, nil, fmt.Errorf("The state at block %d is missing", blockNumber)
+	}
+	if entry[0] != blockStateCheckpoint && entry[0] != blockStateForwardDelta {
+		return 0, nil, fmt.Errorf("The state at block %d is of unknown kind [%d]", blockNumber, entry[0])
+	}
 	delta := statemgmt.NewStateDelta()
-	err = delta.Unmarshal(deltaBytes)
-	if err != nil {
-		return nil, err
+	if err := delta.Unmarshal(entry[1:]); err != nil {
+		return 0, nil, err
 	}
-	return delta, nil
+	return entry[0], delta, nil
+}
+
+// CompactBlockStateCheckpoints adds to writeBatch the replacement of the checkpoints of the blocks in [from, to)
+// by forward deltas, except for the ones at a multiple of sparseInterval. The state at a block is then rebuilt
+// from at most sparseInterval entries, and the full image of the state is no longer stored every
+// checkpointInterval blocks. The checkpoint preceding from is the base of the first forward deltas, it is kept.
+// It returns the number of checkpoints replaced and the bytes reclaimed
+func (state *State) CompactBlockStateCheckpoints(writeBatch *gorocksdb.WriteBatch, from, to, sparseInterval uint64) (uint64, uint64, error) {
+	openchainDB := db.GetDBHandle()
+	itr := openchainDB.GetBlockStateCFIterator()
+	defer itr.Close()
+	// The state at the end of the previous block
+	var image *statemgmt.StateDelta
+	var entries, reclaimed uint64
+	for itr.Seek(stcomm.EncodeStateDeltaKey(from)); itr.Valid(); itr.Next() {
+		key := stcomm.Copy(itr.Key().Data())
+		blockNumber := stcomm.DecodeStateDeltaKey(key)
+		if blockNumber >= to {
+			break
+		}
+		value := itr.Value().Data()
+		kind, delta, err := decodeBlockStateEntry(blockNumber, value)
+		if err != nil {
+			return 0, 0, err
+		}
+		switch {
+		case kind == blockStateForwardDelta && image == nil:
+			if image, err = state.FetchBlockStateDeltaFromDB(blockNumber); err != nil {
+				return 0, 0, err
+			}
+		case kind == blockStateForwardDelta:
+			image.ApplyChanges(delta)
+		case image == nil || blockNumber%sparseInterval == 0:
+			image = delta
+		default:
+			entry := append([]byte{blockStateForwardDelta}, blockStateChanges(image, delta).Marshal()...)
+			writeBatch.PutCF(openchainDB.BlockStateCF, key, entry)
+			entries++
+			if len(entry) < len(value) {
+				reclaimed += uint64(len(value) - len(entry))
+			}
+			image = delta
+		}
+	}
+	return entries, reclaimed, itr.Err()
+}
+
+// blockStateChanges returns the forward delta turning the state image prev into the state image cur
+func blockStateChanges(prev, cur *statemgmt.StateDelta) *statemgmt.StateDelta {
+	changes := statemgmt.NewStateDelta()
+	for _, chaincodeID := range cur.GetUpdatedChaincodeIds(false) {
+		for key, value := range cur.GetUpdates(chaincodeID) {
+			previous := prev.Get(chaincodeID, key)
+			if previous == nil {
+				previous = &statemgmt.UpdatedValue{}
+			}
+			if !value.IsDeleted() && (previous.IsDeleted() || !bytes.Equal(previous.GetValue(), value.GetValue())) {
+				changes.Set(chaincodeID, key, value.GetValue(), previous.GetValue())
+			}
+		}
+	}
+	for _, chaincodeID := range prev.GetUpdatedChaincodeIds(false) {
+		for key, previous := range prev.GetUpdates(chaincodeID) {
+			if value := cur.Get(chaincodeID, key); !previous.IsDeleted() && (value == nil || value.IsDeleted()) {
+				changes.Delete(chaincodeID, key, previous.GetValue())
+			}
+		}
+	}
+	return changes
+}
+
+// GetBlockStateCheckpointInterval returns the number of blocks between two checkpoints of the state
+func (state *State) GetBlockStateCheckpointInterval() uint64 {
+	return state.checkpointInterval
+}
+
+// GetHistoryStateDeltaSize returns the number of state deltas kept in the db
+func (state *State) GetHistoryStateDeltaSize() uint64 {
+	return state.historyStateDeltaSize
 }
 
 // AddChangesForPersistence adds key-value pairs to writeBatch
@@ -354,15 +756,63 @@ func (state *State) AddChangesForPersistence(blockNumber uint64, writeBatch *gor
 			blockNumber, state.historyStateDeltaSize)
 	}
 
-	fromGenesisStateDelta, err := state.CreateDeltaFromGenesis(blockNumber)
-	if err != nil {
-		panic("Unable to create delta from genesis")
+	logger.Debugf("Adding read/write sets of the transactions of block number[%d]", blockNumber)
+	writeBatch.PutCF(db.GetDBHandle().TxRWSetCF, stcomm.EncodeStateDeltaKey(blockNumber), statemgmt.MarshalTxReadWriteSets(state.txRWSets))
+
+	if blockNumber%state.checkpointInterval == 0 {
+		fromGenesisStateDelta, err := state.CreateDeltaFromGenesis(blockNumber)
+		if err != nil {
+			panic("Unable to create delta from genesis")
+		}
+		logger.Debugf("Adding state checkpoint at block number[%d]", blockNumber)
+		writeBatch.PutCF(db.GetDBHandle().BlockStateCF, stcomm.EncodeStateDeltaKey(blockNumber),
+			append([]byte{blockStateCheckpoint}, fromGenesisStateDelta.Marshal()...))
+	} else {
+		logger.Debugf("Adding forward state-delta of block number[%d]", blockNumber)
+		writeBatch.PutCF(db.GetDBHandle().BlockStateCF, stcomm.EncodeStateDeltaKey(blockNumber),
+			append([]byte{blockStateForwardDelta}, serializedStateDelta...))
 	}
-	logger.Debugf("Adding state-delta from genesis to block number[%d]", blockNumber)
-	writeBatch.PutCF(db.GetDBHandle().BlockStateCF, stcomm.EncodeStateDeltaKey(blockNumber), fromGenesisStateDelta.Marshal())
+	writeBatch.PutCF(db.GetDBHandle().PersistCF, blockStateFormatKey, []byte{blockStateFormatWithKind})
 	logger.Debug("state.addChangesForPersistence()...finished")
 }
 
+// migrateBlockStateFormat prefixes the entries of blockStateCF written by earlier versions, which are all
+// checkpoints, with their kind. It fails if the entries are in an unknown format
+func migrateBlockStateFormat() error {
+	openchainDB := db.GetDBHandle()
+	format, err := openchainDB.Get(openchainDB.PersistCF, blockStateFormatKey)
+	if err != nil {
+		return err
+	}
+	if len(format) != 0 {
+		if format[0] != blockStateFormatWithKind {
+			return fmt.Errorf("Unknown format [%d] of the block states", format[0])
+		}
+		return nil
+	}
+
+	writeBatch := gorocksdb.NewWriteBatch()
+	defer writeBatch.Destroy()
+	itr := openchainDB.GetBlockStateCFIterator()
+	defer itr.Close()
+	var entries int
+	for itr.SeekToFirst(); itr.Valid(); itr.Next() {
+		key := stcomm.Copy(itr.Key().Data())
+		writeBatch.PutCF(openchainDB.BlockStateCF, key, append([]byte{blockStateCheckpoint}, itr.Value().Data()...))
+		entries++
+	}
+	if err = itr.Err(); err != nil {
+		return err
+	}
+	if entries > 0 {
+		logger.Infof("Migrating the states of %d blocks to checkpoints prefixed with their kind", entries)
+	}
+	writeBatch.PutCF(openchainDB.PersistCF, blockStateFormatKey, []byte{blockStateFormatWithKind})
+	opt := gorocksdb.NewDefaultWriteOptions()
+	defer opt.Destroy()
+	return openchainDB.DB.Write(opt, writeBatch)
+}
+
 // ApplyStateDelta applies already prepared stateDelta to the existing state.
 // This is an in memory change only. state.CommitStateDelta must be used to
 // commit the state to the DB. This method is to be used in state transfer.
@@ -17,9 +17,14 @@ limitations under the License.
 package chaincodest
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/state"
+	"github.com/hyperledger/fabric/core/ledger/state/chaincodest/statemgmt"
 	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/tecbot/gorocksdb"
 )
 
 func TestStateChanges(t *testing.T) {
@@ -102,6 +107,47 @@ func TestStateTxBehavior(t *testing.T) {
 	testutil.AssertEquals(t, stateTestWrapper.get("chaincode2", "key2", false), []byte("value2"))
 }
 
+func TestStateConcurrentTxs(t *testing.T) {
+	stateTestWrapper, state := createFreshDBAndConstructState(t)
+	state.BeginConcurrentTxs()
+	state.TxBeginForSet("txSet1", "txUuid1")
+	state.TxBeginForSet("txSet2", "txUuid2")
+	state.TxBeginForSet("txSet3", "txUuid3")
+	state.SetForTx("txUuid2", "chaincode2", "key2", []byte("value2"))
+	state.SetForTx("txUuid1", "chaincode1", "key1", []byte("value1"))
+	state.SetForTx("txUuid3", "chaincode3", "key3", []byte("value3"))
+
+	// the changes of a tx are only visible to the tx until the concurrent txs end
+	value, _ := state.GetForTx("txUuid1", "chaincode1", "key1", false)
+	testutil.AssertEquals(t, value, []byte("value1"))
+	value, _ = state.GetForTx("txUuid2", "chaincode1", "key1", false)
+	testutil.AssertNil(t, value)
+	testutil.AssertNil(t, stateTestWrapper.get("chaincode1", "key1", false))
+
+	state.TxFinish("txUuid2", true)
+	state.TxFinish("txUuid1", true)
+	state.TxFinish("txUuid3", true)
+	testutil.AssertEquals(t, state.GetTxReadWriteSet("txSet1").Writes.Get("chaincode1", "key1").GetValue(), []byte("value1"))
+	testutil.AssertEquals(t, state.GetTxReadWriteSet("txSet2").Reads["chaincode1"]["key1"], true)
+
+	// only the txs of the listed sets are merged, in the given order
+	state.EndConcurrentTxs([]string{"txSet1", "txSet2"})
+	testutil.AssertEquals(t, stateTestWrapper.get("chaincode1", "key1", false), []byte("value1"))
+	testutil.AssertEquals(t, stateTestWrapper.get("chaincode2", "key2", false), []byte("value2"))
+	testutil.AssertNil(t, stateTestWrapper.get("chaincode3", "key3", false))
+	testutil.AssertEquals(t, len(state.txRWSets), 2)
+	testutil.AssertEquals(t, state.txRWSets[0].TxSetID, "txSet1")
+	testutil.AssertEquals(t, state.txRWSets[1].TxSetID, "txSet2")
+	_, ok := state.GetTxStateDeltaHash()["txUuid3"]
+	testutil.AssertEquals(t, ok, false)
+
+	// the txs are executed serially again
+	state.TxBegin("txUuid4")
+	state.Set("chaincode3", "key3", []byte("value3"))
+	state.TxFinish("txUuid4", true)
+	testutil.AssertEquals(t, stateTestWrapper.get("chaincode3", "key3", false), []byte("value3"))
+}
+
 func TestStateTxWrongCallCausePanic_1(t *testing.T) {
 	_, state := createFreshDBAndConstructState(t)
 	defer testutil.AssertPanic(t, "A panic should occur when a set state is invoked with out calling a tx-begin")
@@ -169,3 +215,86 @@ func TestStateDeltaSizeSetting(t *testing.T) {
 		t.Fatalf("Error reading historyStateDeltaSize. Expected 500, but got %d", state.historyStateDeltaSize)
 	}
 }
+
+func TestBlockStateLegacyFormatMigration(t *testing.T) {
+	_, state := createFreshDBAndConstructState(t)
+	openchainDB := db.GetDBHandle()
+
+	// An earlier version stored the full state at every block, without its kind
+	legacyDelta := statemgmt.NewStateDelta()
+	legacyDelta.Set("chaincode1", "key1", []byte("value1"), nil)
+	writeBatch := gorocksdb.NewWriteBatch()
+	defer writeBatch.Destroy()
+	writeBatch.PutCF(openchainDB.BlockStateCF, stcomm.EncodeStateDeltaKey(0), legacyDelta.Marshal())
+	writeBatch.DeleteCF(openchainDB.PersistCF, blockStateFormatKey)
+	testDBWrapper.WriteToDB(t, writeBatch)
+
+	err := migrateBlockStateFormat()
+	testutil.AssertNoError(t, err, "Error while migrating the block states")
+	delta, err := state.FetchBlockStateDeltaFromDB(0)
+	testutil.AssertNoError(t, err, "Error while fetching the block state")
+	testutil.AssertEquals(t, delta.Get("chaincode1", "key1").GetValue(), []byte("value1"))
+
+	// The migration is recorded and not applied again
+	err = migrateBlockStateFormat()
+	testutil.AssertNoError(t, err, "Error while migrating the block states")
+	delta, err = state.FetchBlockStateDeltaFromDB(0)
+	testutil.AssertNoError(t, err, "Error while fetching the block state")
+	testutil.AssertEquals(t, delta.Get("chaincode1", "key1").GetValue(), []byte("value1"))
+}
+
+func TestBlockStateUnknownKind(t *testing.T) {
+	_, state := createFreshDBAndConstructState(t)
+	writeBatch := gorocksdb.NewWriteBatch()
+	defer writeBatch.Destroy()
+	writeBatch.PutCF(db.GetDBHandle().BlockStateCF, stcomm.EncodeStateDeltaKey(0), []byte{7, 0})
+	testDBWrapper.WriteToDB(t, writeBatch)
+
+	_, err := state.FetchBlockStateDeltaFromDB(0)
+	testutil.AssertError(t, err, "A block state of unknown kind should not be read")
+}
+
+func TestCompactBlockStateCheckpoints(t *testing.T) {
+	_, state := createFreshDBAndConstructState(t)
+	openchainDB := db.GetDBHandle()
+
+	// A checkpoint at every block, key2 is deleted by block 4
+	images := make([]*statemgmt.StateDelta, 6)
+	writeBatch := gorocksdb.NewWriteBatch()
+	defer writeBatch.Destroy()
+	for blockNumber := range images {
+		images[blockNumber] = statemgmt.NewStateDelta()
+		images[blockNumber].Set("chaincode1", "key1", []byte(fmt.Sprintf("value%d", blockNumber)), nil)
+		if blockNumber < 4 {
+			images[blockNumber].Set("chaincode1", "key2", []byte("value"), nil)
+		}
+		entry := append([]byte{blockStateCheckpoint}, images[blockNumber].Marshal()...)
+		writeBatch.PutCF(openchainDB.BlockStateCF, stcomm.EncodeStateDeltaKey(uint64(blockNumber)), entry)
+	}
+	testDBWrapper.WriteToDB(t, writeBatch)
+
+	compactionBatch := gorocksdb.NewWriteBatch()
+	defer compactionBatch.Destroy()
+	entries, _, err := state.CompactBlockStateCheckpoints(compactionBatch, 0, 6, 3)
+	testutil.AssertNoError(t, err, "Error while compacting the block states")
+	testutil.AssertEquals(t, entries, uint64(4))
+	testDBWrapper.WriteToDB(t, compactionBatch)
+
+	for blockNumber, image := range images {
+		entry, err := openchainDB.GetFromBlockStateCF(stcomm.EncodeStateDeltaKey(uint64(blockNumber)))
+		testutil.AssertNoError(t, err, "Error while reading the block state")
+		if blockNumber%3 == 0 {
+			testutil.AssertEquals(t, entry[0], blockStateCheckpoint)
+		} else {
+			testutil.AssertEquals(t, entry[0], blockStateForwardDelta)
+		}
+		delta, err := state.FetchBlockStateDeltaFromDB(uint64(blockNumber))
+		testutil.AssertNoError(t, err, "Error while fetching the block state")
+		testutil.AssertEquals(t, delta.Get("chaincode1", "key1").GetValue(), image.Get("chaincode1", "key1").GetValue())
+		if image.Get("chaincode1", "key2") != nil {
+			testutil.AssertEquals(t, delta.Get("chaincode1", "key2").GetValue(), []byte("value"))
+		} else if value := delta.Get("chaincode1", "key2"); value != nil {
+			testutil.AssertEquals(t, value.IsDeleted(), true)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statemgmt
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// KeyRange is a range of keys of a chaincode read through a range scan.
+// An empty EndKey denotes a range without an upper bound
+type KeyRange struct {
+	ChaincodeID string
+	StartKey    string
+	EndKey      string
+}
+
+func (keyRange *KeyRange) contains(key string) bool {
+	return key >= keyRange.StartKey && (keyRange.EndKey == "" || key <= keyRange.EndKey)
+}
+
+// TxReadWriteSet holds the keys read and the changes written by a transaction. It is used to
+// decide which transactions are affected when the state is recomputed after a mutation
+type TxReadWriteSet struct {
+	TxSetID    string
+	TxID       string
+	Successful bool
+	Reads      map[string]map[string]bool
+	RangeReads []*KeyRange
+	// Writes is nil if the transaction was not successful
+	Writes *StateDelta
+}
+
+// NewTxReadWriteSet constructs an empty TxReadWriteSet for the transaction txID
+func NewTxReadWriteSet(txID string) *TxReadWriteSet {
+	return &TxReadWriteSet{TxID: txID, Reads: make(map[string]map[string]bool)}
+}
+
+// AddRead records that the key of the chaincode was read
+func (rwSet *TxReadWriteSet) AddRead(chaincodeID string, key string) {
+	keys, ok := rwSet.Reads[chaincodeID]
+	if !ok {
+		keys = make(map[string]bool)
+		rwSet.Reads[chaincodeID] = keys
+	}
+	keys[key] = true
+}
+
+// AddRangeRead records that the keys of the chaincode between startKey and endKey were read
+func (rwSet *TxReadWriteSet) AddRangeRead(chaincodeID string, startKey string, endKey string) {
+	rwSet.RangeReads = append(rwSet.RangeReads, &KeyRange{chaincodeID, startKey, endKey})
+}
+
+// DependsOn returns true if the transaction read any of the given keys
+func (rwSet *TxReadWriteSet) DependsOn(keySet *KeySet) bool {
+	if keySet.IsEmpty() {
+		return false
+	}
+	for chaincodeID, keys := range rwSet.Reads {
+		for key := range keys {
+			if keySet.Contains(chaincodeID, key) {
+				return true
+			}
+		}
+	}
+	for _, keyRange := range rwSet.RangeReads {
+		for key := range keySet.keys[keyRange.ChaincodeID] {
+			if keyRange.contains(key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TouchesChaincode returns true if the transaction read or wrote any key of the chaincode
+func (rwSet *TxReadWriteSet) TouchesChaincode(chaincodeID string) bool {
+	if _, ok := rwSet.Reads[chaincodeID]; ok {
+		return true
+	}
+	for _, keyRange := range rwSet.RangeReads {
+		if keyRange.ChaincodeID == chaincodeID {
+			return true
+		}
+	}
+	return rwSet.Writes != nil && rwSet.Writes.GetUpdates(chaincodeID) != nil
+}
+
+// GetChaincodeIds returns the sorted IDs of the chaincodes whose keys were read or written by the transaction
+func (rwSet *TxReadWriteSet) GetChaincodeIds() []string {
+	chaincodeIDs := make(map[string]bool)
+	for chaincodeID := range rwSet.Reads {
+		chaincodeIDs[chaincodeID] = true
+	}
+	for _, keyRange := range rwSet.RangeReads {
+		chaincodeIDs[keyRange.ChaincodeID] = true
+	}
+	if rwSet.Writes != nil {
+		for _, chaincodeID := range rwSet.Writes.GetUpdatedChaincodeIds(false) {
+			chaincodeIDs[chaincodeID] = true
+		}
+	}
+	ids := make([]string, 0, len(chaincodeIDs))
+	for chaincodeID := range chaincodeIDs {
+		ids = append(ids, chaincodeID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// KeySet is a set of keys grouped by chaincode
+type KeySet struct {
+	keys map[string]map[string]bool
+}
+
+// NewKeySet constructs an empty KeySet
+func NewKeySet() *KeySet {
+	return &KeySet{make(map[string]map[string]bool)}
+}
+
+// Add adds the key of the chaincode to the set
+func (keySet *KeySet) Add(chaincodeID string, key string) {
+	keys, ok := keySet.keys[chaincodeID]
+	if !ok {
+		keys = make(map[string]bool)
+		keySet.keys[chaincodeID] = keys
+	}
+	keys[key] = true
+}
+
+// Remove removes the key of the chaincode from the set
+func (keySet *KeySet) Remove(chaincodeID string, key string) {
+	keys, ok := keySet.keys[chaincodeID]
+	if !ok {
+		return
+	}
+	delete(keys, key)
+	if len(keys) == 0 {
+		delete(keySet.keys, chaincodeID)
+	}
+}
+
+// Contains returns true if the key of the chaincode is in the set
+func (keySet *KeySet) Contains(chaincodeID string, key string) bool {
+	return keySet.keys[chaincodeID][key]
+}
+
+// IsEmpty returns true if the set does not contain any key
+func (keySet *KeySet) IsEmpty() bool {
+	return len(keySet.keys) == 0
+}
+
+// RemoveWritten removes from the set all the keys written in the delta
+func (keySet *KeySet) RemoveWritten(delta *StateDelta) {
+	if delta == nil {
+		return
+	}
+	for chaincodeID, chaincodeStateDelta := range delta.ChaincodeStateDeltas {
+		for key := range chaincodeStateDelta.UpdatedKVs {
+			keySet.Remove(chaincodeID, key)
+		}
+	}
+}
+
+// AddChanged updates the set after a transaction that originally wrote previous was executed
+// again and wrote current. Keys written with the same value are removed from the set, while
+// keys whose value changed or that are no longer written are added.
+func (keySet *KeySet) AddChanged(previous *StateDelta, current *StateDelta) {
+	if current != nil {
+		for chaincodeID, chaincodeStateDelta := range current.ChaincodeStateDeltas {
+			for key, updatedValue := range chaincodeStateDelta.UpdatedKVs {
+				var previousValue *UpdatedValue
+				if previous != nil {
+					previousValue = previous.Get(chaincodeID, key)
+				}
+				if previousValue != nil && previousValue.IsDeleted() == updatedValue.IsDeleted() &&
+					bytes.Equal(previousValue.GetValue(), updatedValue.GetValue()) {
+					keySet.Remove(chaincodeID, key)
+				} else {
+					keySet.Add(chaincodeID, key)
+				}
+			}
+		}
+	}
+	if previous != nil {
+		for chaincodeID, chaincodeStateDelta := range previous.ChaincodeStateDeltas {
+			for key := range chaincodeStateDelta.UpdatedKVs {
+				if current == nil || current.Get(chaincodeID, key) == nil {
+					keySet.Add(chaincodeID, key)
+				}
+			}
+		}
+	}
+}
+
+// MarshalTxReadWriteSets serializes the read/write sets of the transactions of a block
+func MarshalTxReadWriteSets(rwSets []*TxReadWriteSet) []byte {
+	buffer := proto.NewBuffer([]byte{})
+	encodeVarint(buffer, uint64(len(rwSets)))
+	for _, rwSet := range rwSets {
+		rwSet.marshal(buffer)
+	}
+	return buffer.Bytes()
+}
+
+// UnmarshalTxReadWriteSets deserializes the read/write sets of the transactions of a block
+func UnmarshalTxReadWriteSets(b []byte) ([]*TxReadWriteSet, error) {
+	buffer := proto.NewBuffer(b)
+	size, err := buffer.DecodeVarint()
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling size: %s", err)
+	}
+	rwSets := make([]*TxReadWriteSet, size)
+	for i := range rwSets {
+		rwSets[i] = &TxReadWriteSet{}
+		if err := rwSets[i].unmarshal(buffer); err != nil {
+			return nil, fmt.Errorf("Error unmarshaling read/write set: %s", err)
+		}
+	}
+	return rwSets, nil
+}
+
+func (rwSet *TxReadWriteSet) marshal(buffer *proto.Buffer) {
+	encodeString(buffer, rwSet.TxSetID)
+	encodeString(buffer, rwSet.TxID)
+	if rwSet.Successful {
+		encodeVarint(buffer, 1)
+	} else {
+		encodeVarint(buffer, 0)
+	}
+	encodeVarint(buffer, uint64(len(rwSet.Reads)))
+	for chaincodeID, keys := range rwSet.Reads {
+		encodeString(buffer, chaincodeID)
+		encodeVarint(buffer, uint64(len(keys)))
+		for key := range keys {
+			encodeString(buffer, key)
+		}
+	}
+	encodeVarint(buffer, uint64(len(rwSet.RangeReads)))
+	for _, keyRange := range rwSet.RangeReads {
+		encodeString(buffer, keyRange.ChaincodeID)
+		encodeString(buffer, keyRange.StartKey)
+		encodeString(buffer, keyRange.EndKey)
+	}
+	if rwSet.Writes == nil {
+		encodeVarint(buffer, 0)
+		return
+	}
+	encodeVarint(buffer, 1)
+	if err := buffer.EncodeRawBytes(rwSet.Writes.Marshal()); err != nil {
+		panic(fmt.Errorf("This error should not occur: %s", err))
+	}
+}
+
+func (rwSet *TxReadWriteSet) unmarshal(buffer *proto.Buffer) error {
+	var err error
+	if rwSet.TxSetID, err = buffer.DecodeStringBytes(); err != nil {
+		return err
+	}
+	if rwSet.TxID, err = buffer.DecodeStringBytes(); err != nil {
+		return err
+	}
+	successful, err := buffer.DecodeVarint()
+	if err != nil {
+		return err
+	}
+	rwSet.Successful = successful == 1
+	size, err := buffer.DecodeVarint()
+	if err != nil {
+		return err
+	}
+	rwSet.Reads = make(map[string]map[string]bool, size)
+	for i := uint64(0); i < size; i++ {
+		chaincodeID, err := buffer.DecodeStringBytes()
+		if err != nil {
+			return err
+		}
+		numKeys, err := buffer.DecodeVarint()
+		if err != nil {
+			return err
+		}
+		keys := make(map[string]bool, numKeys)
+		for j := uint64(0); j < numKeys; j++ {
+			key, err := buffer.DecodeStringBytes()
+			if err != nil {
+				return err
+			}
+			keys[key] = true
+		}
+		rwSet.Reads[chaincodeID] = keys
+	}
+	size, err = buffer.DecodeVarint()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < size; i++ {
+		keyRange := &KeyRange{}
+		if keyRange.ChaincodeID, err = buffer.DecodeStringBytes(); err != nil {
+			return err
+		}
+		if keyRange.StartKey, err = buffer.DecodeStringBytes(); err != nil {
+			return err
+		}
+		if keyRange.EndKey, err = buffer.DecodeStringBytes(); err != nil {
+			return err
+		}
+		rwSet.RangeReads = append(rwSet.RangeReads, keyRange)
+	}
+	hasWrites, err := buffer.DecodeVarint()
+	if err != nil {
+		return err
+	}
+	if hasWrites == 0 {
+		return nil
+	}
+	writes, err := buffer.DecodeRawBytes(false)
+	if err != nil {
+		return err
+	}
+	rwSet.Writes = NewStateDelta()
+	return rwSet.Writes.Unmarshal(writes)
+}
+
+func encodeVarint(buffer *proto.Buffer, x uint64) {
+	if err := buffer.EncodeVarint(x); err != nil {
+		// in protobuf code the error return is always nil
+		panic(fmt.Errorf("This error should not occur: %s", err))
+	}
+}
+
+func encodeString(buffer *proto.Buffer, s string) {
+	if err := buffer.EncodeStringBytes(s); err != nil {
+		panic(fmt.Errorf("This error should not occur: %s", err))
+	}
+}
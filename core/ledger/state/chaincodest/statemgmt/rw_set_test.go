@@ -0,0 +1,100 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statemgmt
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+)
+
+func TestTxReadWriteSetsMarshalling(t *testing.T) {
+	rwSet1 := NewTxReadWriteSet("txID1")
+	rwSet1.TxSetID = "txSetID1"
+	rwSet1.Successful = true
+	rwSet1.AddRead("chaincode1", "key1")
+	rwSet1.AddRead("chaincode2", "key2")
+	rwSet1.AddRangeRead("chaincode1", "a", "")
+	rwSet1.Writes = NewStateDelta()
+	rwSet1.Writes.Set("chaincode1", "key1", []byte("value1"), nil)
+	rwSet1.Writes.Delete("chaincode1", "key3", nil)
+
+	rwSet2 := NewTxReadWriteSet("txID2")
+	rwSet2.AddRead("chaincode1", "key1")
+
+	rwSets, err := UnmarshalTxReadWriteSets(MarshalTxReadWriteSets([]*TxReadWriteSet{rwSet1, rwSet2}))
+	testutil.AssertNoError(t, err, "Error while unmarshaling the read/write sets")
+	testutil.AssertEquals(t, len(rwSets), 2)
+	testutil.AssertEquals(t, rwSets[0], rwSet1)
+	testutil.AssertEquals(t, rwSets[1], rwSet2)
+}
+
+func TestTxReadWriteSetDependsOn(t *testing.T) {
+	rwSet := NewTxReadWriteSet("txID")
+	rwSet.AddRead("chaincode1", "key1")
+	rwSet.AddRangeRead("chaincode2", "b", "d")
+
+	keySet := NewKeySet()
+	testutil.AssertEquals(t, rwSet.DependsOn(keySet), false)
+	keySet.Add("chaincode2", "a")
+	keySet.Add("chaincode1", "key2")
+	testutil.AssertEquals(t, rwSet.DependsOn(keySet), false)
+	keySet.Add("chaincode2", "c")
+	testutil.AssertEquals(t, rwSet.DependsOn(keySet), true)
+	keySet.Remove("chaincode2", "c")
+	keySet.Add("chaincode1", "key1")
+	testutil.AssertEquals(t, rwSet.DependsOn(keySet), true)
+
+	testutil.AssertEquals(t, rwSet.TouchesChaincode("chaincode2"), true)
+	testutil.AssertEquals(t, rwSet.TouchesChaincode("chaincode3"), false)
+}
+
+func TestTxReadWriteSetGetChaincodeIds(t *testing.T) {
+	rwSet := NewTxReadWriteSet("txID")
+	testutil.AssertEquals(t, len(rwSet.GetChaincodeIds()), 0)
+	rwSet.AddRead("chaincode3", "key1")
+	rwSet.AddRangeRead("chaincode1", "b", "d")
+	rwSet.Writes = NewStateDelta()
+	rwSet.Writes.Set("chaincode2", "key1", []byte("value1"), nil)
+	rwSet.Writes.Set("chaincode3", "key2", []byte("value2"), nil)
+	testutil.AssertEquals(t, rwSet.GetChaincodeIds(), []string{"chaincode1", "chaincode2", "chaincode3"})
+}
+
+func TestKeySetAddChanged(t *testing.T) {
+	previous := NewStateDelta()
+	previous.Set("chaincode1", "key1", []byte("value1"), nil)
+	previous.Set("chaincode1", "key2", []byte("value2"), nil)
+	previous.Set("chaincode1", "key3", []byte("value3"), nil)
+	current := NewStateDelta()
+	current.Set("chaincode1", "key1", []byte("value1"), nil)
+	current.Set("chaincode1", "key2", []byte("value2_new"), nil)
+	current.Delete("chaincode1", "key4", nil)
+
+	keySet := NewKeySet()
+	keySet.Add("chaincode1", "key1")
+	keySet.AddChanged(previous, current)
+	testutil.AssertEquals(t, keySet.Contains("chaincode1", "key1"), false)
+	testutil.AssertEquals(t, keySet.Contains("chaincode1", "key2"), true)
+	testutil.AssertEquals(t, keySet.Contains("chaincode1", "key3"), true)
+	testutil.AssertEquals(t, keySet.Contains("chaincode1", "key4"), true)
+
+	keySet.RemoveWritten(previous)
+	testutil.AssertEquals(t, keySet.Contains("chaincode1", "key2"), false)
+	testutil.AssertEquals(t, keySet.Contains("chaincode1", "key4"), true)
+	keySet.AddChanged(current, nil)
+	testutil.AssertEquals(t, keySet.Contains("chaincode1", "key1"), true)
+}
@@ -64,14 +64,11 @@ func (rawTxSetStTestWrapper *rawTxSetStTestWrapper) CreateRandTxSetStateVal() *p
 		Nonce: uint64(rand.Uint32()),
 		IntroBlock: uint64(rand.Uint32()),
 		LastModifiedAtBlock: uint64(rand.Uint32()),
-		Index: &pb.TxSetIndex{
-			BlockNr: uint64(rand.Uint32()),
-			InBlockIndex: uint64(rand.Uint32()),
-		},
+		Index: uint64(rand.Uint32()),
 		TxNumber: uint64(rand.Uint32()),
-		TxsInBlock: map[uint64]uint64{
-			uint64(rand.Uint32()): uint64(rand.Uint32()),
-			uint64(rand.Uint32()): uint64(rand.Uint32()),
+		IndexAtBlock: []*pb.TxSetIndex{
+			{BlockNr: uint64(rand.Uint32()), InBlockIndex: uint64(rand.Uint32())},
+			{BlockNr: uint64(rand.Uint32()), InBlockIndex: uint64(rand.Uint32())},
 		},
 	}
 	return txSetStVal
@@ -9,6 +9,7 @@ import (
 	"github.com/op/go-logging"
 	"bytes"
 	"github.com/hyperledger/fabric/core/util"
+	"sort"
 )
 
 var loggerRaw = logging.MustGetLogger("txsetst_raw")
@@ -19,11 +20,14 @@ type TxSetStateImpl struct {
 	txSetStateDelta *statemgmt.TxSetStateDelta
 	prevHash []byte
 	recomputeHash bool
+	// hash of the persisted state with the working set applied, until the working set changes
+	workingSetHash []byte
 }
 
 // NewTxSetStateImpl constructs new instance of raw state
 func NewTxSetStateImpl() *TxSetStateImpl {
-	return &TxSetStateImpl{}
+	// The hash of the persisted state is computed on first use
+	return &TxSetStateImpl{recomputeHash: true}
 }
 
 // Initialize - method implementation for interface 'statemgmt.HashableTxSetState'
@@ -48,32 +52,83 @@ func (impl *TxSetStateImpl) Get(txSetID string) (*pb.TxSetStateValue, error) {
 // PrepareWorkingSet - method implementation for interface 'statemgmt.HashableTxSetState'
 func (impl *TxSetStateImpl) PrepareWorkingSet(stateDelta *statemgmt.TxSetStateDelta) error {
 	impl.txSetStateDelta = stateDelta
+	impl.workingSetHash = nil
 	return nil
 }
 
 // ClearWorkingSet - method implementation for interface 'statemgmt.HashableTxSetState'
 func (impl *TxSetStateImpl) ClearWorkingSet(changesPersisted bool) {
 	impl.txSetStateDelta = nil
+	if !changesPersisted {
+		// The state may have been deleted from the db, e.g. before being rebuilt from a snapshot
+		impl.recomputeHash = true
+	} else if impl.workingSetHash != nil {
+		// The persisted state is now the one the working set hash was computed for
+		impl.prevHash = impl.workingSetHash
+		impl.recomputeHash = false
+	}
+	impl.workingSetHash = nil
 }
 
 // ComputeCryptoHash - method implementation for interface 'statemgmt.HashableTxSetState'
+// The hash covers the persisted state with the working set applied, so that the hash stored in a block
+// is the one of the state after that block. It is the hash of the concatenation of each key, in byte
+// order, followed by its marshalled value.
+// NOTE: earlier versions hashed the persisted state only, i.e. the state before the block, and
+// returned a nil hash until the state was first changed after a restart. The TxSetStateHash of the
+// blocks committed by peers running these versions differ, so all the validators of a network using
+// the 'raw' data structure must be upgraded together.
 func (impl *TxSetStateImpl) ComputeCryptoHash() ([]byte, error) {
-	if !impl.recomputeHash {
+	delta := impl.txSetStateDelta
+	pendingChanges := delta != nil && !delta.IsEmpty()
+	if !impl.recomputeHash && !pendingChanges {
 		return impl.prevHash, nil
 	}
+	if pendingChanges && impl.workingSetHash != nil {
+		return impl.workingSetHash, nil
+	}
 	hyperDB := db.GetDBHandle()
-	var resBuffer bytes.Buffer
+	kvs := make(map[string][]byte)
 	stateIterator := hyperDB.GetTxSetStateCFIterator()
 	defer stateIterator.Close()
 	for stateIterator.SeekToFirst(); stateIterator.Valid(); stateIterator.Next() {
 		k := stcomm.Copy(stateIterator.Key().Data())
-		resBuffer.Write(k)
-		v := stcomm.Copy(stateIterator.Value().Data())
-		resBuffer.Write(v)
+		kvs[string(k)] = stcomm.Copy(stateIterator.Value().Data())
+	}
+	if pendingChanges {
+		for _, updatedTxSetID := range delta.GetUpdatedTxSetIDs(false) {
+			key := string(stcomm.ConstructTxSetKey(updatedTxSetID))
+			updatedTxSetStateValue := delta.GetUpdates(updatedTxSetID)
+			if updatedTxSetStateValue.IsDeleted() {
+				delete(kvs, key)
+				continue
+			}
+			marshalledTxSetValue, err := updatedTxSetStateValue.GetValue().Bytes()
+			if err != nil {
+				return nil, err
+			}
+			kvs[key] = marshalledTxSetValue
+		}
+	}
+	// Keys are hashed in the byte order rocksdb keeps them in
+	keys := make([]string, 0, len(kvs))
+	for k := range kvs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var resBuffer bytes.Buffer
+	for _, k := range keys {
+		resBuffer.WriteString(k)
+		resBuffer.Write(kvs[k])
+	}
+	hash := util.ComputeCryptoHash(resBuffer.Bytes())
+	if pendingChanges {
+		impl.workingSetHash = hash
+	} else {
+		impl.prevHash = hash
+		impl.recomputeHash = false
 	}
-	impl.prevHash = util.ComputeCryptoHash(resBuffer.Bytes())
-	impl.recomputeHash = false
-	return impl.prevHash, nil
+	return hash, nil
 }
 
 // AddChangesForPersistence - method implementation for interface 'statemgmt.HashableTxSetState'
@@ -96,8 +151,8 @@ func (impl *TxSetStateImpl) AddChangesForPersistence(writeBatch *gorocksdb.Write
 				return err
 			}
 			writeBatch.PutCF(openchainDB.TxSetStateCF, key, marshalledTxSetValue)
-			impl.recomputeHash = true
 		}
+		impl.recomputeHash = true
 	}
 	return nil
 }
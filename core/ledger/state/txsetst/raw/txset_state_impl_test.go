@@ -0,0 +1,56 @@
+package raw
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/state/txsetst/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+func createTxSetStateVal(n uint64) *pb.TxSetStateValue {
+	return &pb.TxSetStateValue{
+		Nonce:               n,
+		IntroBlock:          n,
+		LastModifiedAtBlock: n,
+		Index:               n,
+		TxNumber:            2,
+		IndexAtBlock:        []*pb.TxSetIndex{{BlockNr: n}},
+	}
+}
+
+// The hashes are pinned as they end up in the blocks, any change to them breaks the consensus with older peers
+func TestComputeCryptoHash_Pinned(t *testing.T) {
+	testDBWrapper.CleanDB(t)
+	txSetStRawTestWrapper := newTxSetStRawTestWrapper(t)
+
+	stateDelta := statemgmt.NewTxSetStateDelta()
+	stateDelta.Set("txset2", createTxSetStateVal(2), nil)
+	stateDelta.Set("txset1", createTxSetStateVal(1), nil)
+	hash := txSetStRawTestWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta)
+	expectedHash := "2e802d8a1cc773e1335f58eb84a96fba35c192e54a98eb3ce8a35139aba2c7d8c0d6327c5981c93547a04db071bc365402930d6080a242189a75a7af38fbba8f"
+	testutil.AssertEquals(t, hex.EncodeToString(hash), expectedHash)
+	txSetStRawTestWrapper.PersistChangesAndResetInMemoryChanges()
+
+	// The hash of the persisted state is the same, also after a restart
+	hash, err := txSetStRawTestWrapper.rawState.ComputeCryptoHash()
+	testutil.AssertNoError(t, err, "Error while computing crypto hash")
+	testutil.AssertEquals(t, hex.EncodeToString(hash), expectedHash)
+	hash, err = NewTxSetStateImpl().ComputeCryptoHash()
+	testutil.AssertNoError(t, err, "Error while computing crypto hash")
+	testutil.AssertEquals(t, hex.EncodeToString(hash), expectedHash)
+
+	stateDelta = statemgmt.NewTxSetStateDelta()
+	stateDelta.Delete("txset1", nil)
+	stateDelta.Set("txset3", createTxSetStateVal(3), nil)
+	hash = txSetStRawTestWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta)
+	expectedHash = "1778d5ed586177dbb9ea5150482f88df0f3f3331aa15cc11753e5c2e8cca4d5c27534300c9317070127bcdd63b9a01cc8d47e8d000ced493b4112de17214ff77"
+	testutil.AssertEquals(t, hex.EncodeToString(hash), expectedHash)
+
+	// Rolling back the working set restores the hash of the persisted state
+	txSetStRawTestWrapper.rawState.ClearWorkingSet(false)
+	hash, err = txSetStRawTestWrapper.rawState.ComputeCryptoHash()
+	testutil.AssertNoError(t, err, "Error while computing crypto hash")
+	testutil.AssertEquals(t, hex.EncodeToString(hash), "2e802d8a1cc773e1335f58eb84a96fba35c192e54a98eb3ce8a35139aba2c7d8c0d6327c5981c93547a04db071bc365402930d6080a242189a75a7af38fbba8f")
+}
@@ -0,0 +1,91 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trie
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/state/txsetst/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/tecbot/gorocksdb"
+)
+
+var testDBWrapper = db.NewTestDBWrapper()
+
+type txSetStTrieTestWrapper struct {
+	stateTrie *TxSetStateTrie
+	t         *testing.T
+}
+
+func newTxSetStTrieTestWrapper(t *testing.T) *txSetStTrieTestWrapper {
+	stateTrie := NewTxSetStateImpl()
+	err := stateTrie.Initialize(nil)
+	testutil.AssertNoError(t, err, "Error while constructing stateTrie")
+	return &txSetStTrieTestWrapper{stateTrie, t}
+}
+
+func (testWrapper *txSetStTrieTestWrapper) Get(txSetID string) *pb.TxSetStateValue {
+	value, err := testWrapper.stateTrie.Get(txSetID)
+	testutil.AssertNoError(testWrapper.t, err, "Error while getting value")
+	testWrapper.t.Logf("state value for txSetID=[%s] = [%#v], ", txSetID, value)
+	return value
+}
+
+func (testWrapper *txSetStTrieTestWrapper) PrepareWorkingSetAndComputeCryptoHash(stateDelta *statemgmt.TxSetStateDelta) []byte {
+	testWrapper.stateTrie.PrepareWorkingSet(stateDelta)
+	cryptoHash, err := testWrapper.stateTrie.ComputeCryptoHash()
+	testutil.AssertNoError(testWrapper.t, err, "Error while computing crypto hash")
+	testWrapper.t.Logf("Cryptohash = [%x]", cryptoHash)
+	return cryptoHash
+}
+
+func (testWrapper *txSetStTrieTestWrapper) AddChangesForPersistence(writeBatch *gorocksdb.WriteBatch) {
+	err := testWrapper.stateTrie.AddChangesForPersistence(writeBatch)
+	testutil.AssertNoError(testWrapper.t, err, "Error while adding changes to db write-batch")
+}
+
+func (testWrapper *txSetStTrieTestWrapper) CreateRandTxSetStateVal() *pb.TxSetStateValue {
+	txSetStVal := &pb.TxSetStateValue{
+		Nonce:               uint64(rand.Uint32()),
+		IntroBlock:          uint64(rand.Uint32()),
+		LastModifiedAtBlock: uint64(rand.Uint32()),
+		Index:               uint64(rand.Uint32()),
+		TxNumber:            uint64(rand.Uint32()),
+		IndexAtBlock: []*pb.TxSetIndex{
+			{BlockNr: uint64(rand.Uint32()), InBlockIndex: uint64(rand.Uint32())},
+			{BlockNr: uint64(rand.Uint32()), InBlockIndex: uint64(rand.Uint32())},
+		},
+	}
+	return txSetStVal
+}
+
+func (testWrapper *txSetStTrieTestWrapper) PersistChangesAndResetInMemoryChanges() {
+	writeBatch := gorocksdb.NewWriteBatch()
+	defer writeBatch.Destroy()
+	testWrapper.AddChangesForPersistence(writeBatch)
+	testDBWrapper.WriteToDB(testWrapper.t, writeBatch)
+	testWrapper.stateTrie.ClearWorkingSet(true)
+}
+
+func TestMain(m *testing.M) {
+	testutil.SetupTestConfig()
+	os.Exit(m.Run())
+}
@@ -0,0 +1,141 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trie
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/state/txsetst/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+func createRandTxSetStateVals(testWrapper *txSetStTrieTestWrapper, num int) []*pb.TxSetStateValue {
+	values := make([]*pb.TxSetStateValue, num)
+	for i := range values {
+		values[i] = testWrapper.CreateRandTxSetStateVal()
+	}
+	return values
+}
+
+func TestTxSetStateTrie_ComputeHash_Deterministic(t *testing.T) {
+	testDBWrapper.CleanDB(t)
+	testWrapper := newTxSetStTrieTestWrapper(t)
+	values := createRandTxSetStateVals(testWrapper, 4)
+
+	stateDelta := statemgmt.NewTxSetStateDelta()
+	stateDelta.Set("txSet1", values[0], nil)
+	stateDelta.Set("txSet2", values[1], nil)
+	stateDelta.Set("txSet3", values[2], nil)
+	expectedHash := testWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta)
+	testutil.AssertNotNil(t, expectedHash)
+
+	// the same content, set in a different order and over two blocks, results in the same hash
+	testDBWrapper.CleanDB(t)
+	testWrapper = newTxSetStTrieTestWrapper(t)
+	stateDelta = statemgmt.NewTxSetStateDelta()
+	stateDelta.Set("txSet3", values[2], nil)
+	stateDelta.Set("txSet1", values[0], nil)
+	testWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta)
+	testWrapper.PersistChangesAndResetInMemoryChanges()
+	stateDelta = statemgmt.NewTxSetStateDelta()
+	stateDelta.Set("txSet2", values[1], nil)
+	testutil.AssertEquals(t, testWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta), expectedHash)
+	testWrapper.PersistChangesAndResetInMemoryChanges()
+
+	// a fresh instance on the persisted state reports the same hash
+	testWrapper = newTxSetStTrieTestWrapper(t)
+	hash, err := testWrapper.stateTrie.ComputeCryptoHash()
+	testutil.AssertNoError(t, err, "Error while computing crypto hash")
+	testutil.AssertEquals(t, hash, expectedHash)
+
+	// a different value results in a different hash
+	stateDelta = statemgmt.NewTxSetStateDelta()
+	stateDelta.Set("txSet2", values[3], values[1])
+	testutil.AssertNotEquals(t, testWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta), expectedHash)
+}
+
+func TestTxSetStateTrie_ClearWorkingSet_Rollback(t *testing.T) {
+	testDBWrapper.CleanDB(t)
+	testWrapper := newTxSetStTrieTestWrapper(t)
+	values := createRandTxSetStateVals(testWrapper, 4)
+
+	stateDelta := statemgmt.NewTxSetStateDelta()
+	stateDelta.Set("txSet1", values[0], nil)
+	stateDelta.Set("txSet2", values[1], nil)
+	persistedHash := testWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta)
+	testWrapper.PersistChangesAndResetInMemoryChanges()
+
+	stateDelta = statemgmt.NewTxSetStateDelta()
+	stateDelta.Set("txSet1", values[2], values[0])
+	stateDelta.Set("txSet3", values[3], nil)
+	stateDelta.Delete("txSet2", values[1])
+	workingSetHash := testWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta)
+	testutil.AssertNotEquals(t, workingSetHash, persistedHash)
+
+	// discarding the working set reverts to the persisted hash and values
+	testWrapper.stateTrie.ClearWorkingSet(false)
+	hash, err := testWrapper.stateTrie.ComputeCryptoHash()
+	testutil.AssertNoError(t, err, "Error while computing crypto hash")
+	testutil.AssertEquals(t, hash, persistedHash)
+	testutil.AssertEquals(t, testWrapper.Get("txSet1"), values[0])
+	testutil.AssertEquals(t, testWrapper.Get("txSet2"), values[1])
+	testutil.AssertNil(t, testWrapper.Get("txSet3"))
+
+	// the discarded computation does not leak into the persisted trie
+	testutil.AssertEquals(t, testWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta), workingSetHash)
+	testWrapper.PersistChangesAndResetInMemoryChanges()
+	hash, err = testWrapper.stateTrie.ComputeCryptoHash()
+	testutil.AssertNoError(t, err, "Error while computing crypto hash")
+	testutil.AssertEquals(t, hash, workingSetHash)
+	testutil.AssertEquals(t, testWrapper.Get("txSet1"), values[2])
+	testutil.AssertNil(t, testWrapper.Get("txSet2"))
+	testutil.AssertEquals(t, testWrapper.Get("txSet3"), values[3])
+}
+
+func TestTxSetStateTrie_Initialize_AfterDeleteState(t *testing.T) {
+	testDBWrapper.CleanDB(t)
+	testWrapper := newTxSetStTrieTestWrapper(t)
+	values := createRandTxSetStateVals(testWrapper, 3)
+
+	stateDelta := statemgmt.NewTxSetStateDelta()
+	stateDelta.Set("txSet1", values[0], nil)
+	stateDelta.Set("txSet2", values[1], nil)
+	testWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta)
+	testWrapper.PersistChangesAndResetInMemoryChanges()
+
+	// this is what TxSetState.DeleteState does
+	err := db.GetDBHandle().DeleteTxSetState()
+	testutil.AssertNoError(t, err, "Error while deleting tx set state")
+	err = testWrapper.stateTrie.Initialize(nil)
+	testutil.AssertNoError(t, err, "Error while initializing stateTrie")
+	hash, err := testWrapper.stateTrie.ComputeCryptoHash()
+	testutil.AssertNoError(t, err, "Error while computing crypto hash")
+	testutil.AssertNil(t, hash)
+	testutil.AssertNil(t, testWrapper.Get("txSet1"))
+
+	stateDelta = statemgmt.NewTxSetStateDelta()
+	stateDelta.Set("txSet3", values[2], nil)
+	hashAfterDelete := testWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta)
+	testWrapper.PersistChangesAndResetInMemoryChanges()
+
+	// no trie node of the deleted state is referenced any longer
+	testDBWrapper.CleanDB(t)
+	freshTestWrapper := newTxSetStTrieTestWrapper(t)
+	testutil.AssertEquals(t, freshTestWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta), hashAfterDelete)
+}
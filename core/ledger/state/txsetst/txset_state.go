@@ -1,14 +1,15 @@
 package txsetst
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/hyperledger/fabric/core/db"
-	"github.com/hyperledger/fabric/core/ledger/state/txsetst/statemgmt"
-	//	"github.com/hyperledger/fabric/core/ledger/state/txset_state/buckettree"
-	//	"github.com/hyperledger/fabric/core/ledger/state/txset_state/trie"
 	stcomm "github.com/hyperledger/fabric/core/ledger/state"
+	"github.com/hyperledger/fabric/core/ledger/state/txsetst/buckettree"
 	"github.com/hyperledger/fabric/core/ledger/state/txsetst/raw"
+	"github.com/hyperledger/fabric/core/ledger/state/txsetst/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/state/txsetst/trie"
 	pb "github.com/hyperledger/fabric/protos"
 	"github.com/op/go-logging"
 	"github.com/tecbot/gorocksdb"
@@ -27,9 +28,9 @@ func (implInt *txSetStateImplType) Name() string {
 }
 
 var (
-	//	buckettreeType txSetStateImplType = "buckettree"
-	//	trieType 	   txSetStateImplType = "trie"
-	rawType = &txSetStateImplType{"raw"}
+	buckettreeType = &txSetStateImplType{"buckettree"}
+	trieType       = &txSetStateImplType{"trie"}
+	rawType        = &txSetStateImplType{"raw"}
 )
 
 var defaultTxSetStateImpl = rawType
@@ -45,17 +46,20 @@ type TxSetState struct {
 	txStateDeltaHash       map[string][]byte
 	updateStateImpl        bool
 	historyStateDeltaSize  uint64
+	stateImplConfigs       map[string]interface{}
+	// an overlay shares the implementation of the state of the peer, see NewTxSetStateOverlay
+	overlay bool
 }
 
 // NewTxSetState constructs a new TxSetState. This Initializes encapsulated state implementation
 func NewTxSetState() *TxSetState {
-	confData := stcomm.GetConfig("txSetState", defaultTxSetStateImpl, rawType)
+	confData := stcomm.GetConfig("txSetState", defaultTxSetStateImpl, buckettreeType, trieType, rawType)
 	txSetStateLogger.Infof("Initializing tx set state implementation [%s]", confData.StateImplName)
 	switch confData.StateImplName {
-	/*	case buckettreeType:
-			txSetStateImpl = buckettree.NewTxSetStateImpl()
-		case trieType:
-			txSetStateImpl = trie.NewTxSetStateImpl()*/
+	case buckettreeType.Name():
+		txSetStateImpl = buckettree.NewTxSetStateImpl()
+	case trieType.Name():
+		txSetStateImpl = trie.NewTxSetStateImpl()
 	case rawType.Name():
 		txSetStateImpl = raw.NewTxSetStateImpl()
 	default:
@@ -66,7 +70,15 @@ func NewTxSetState() *TxSetState {
 		panic(fmt.Errorf("Error during initialization of tx set state implementation: %s", err))
 	}
 	return &TxSetState{txSetStateImpl, statemgmt.NewTxSetStateDelta(), statemgmt.NewTxSetStateDelta(), "", make(map[string][]byte),
-		false, uint64(confData.DeltaHistorySize)}
+		false, uint64(confData.DeltaHistorySize), confData.StateImplConfigs, false}
+}
+
+// NewTxSetStateOverlay constructs a TxSetState keeping its changes in memory on top of the committed state of the
+// implementation initialized by NewTxSetState, which it only reads. Its changes can never be persisted nor hashed.
+// It is meant for simulations, which must not affect the state of the peer
+func NewTxSetStateOverlay() *TxSetState {
+	return &TxSetState{txSetStateImpl: txSetStateImpl, txSetStateDelta: statemgmt.NewTxSetStateDelta(),
+		currentTxSetStateDelta: statemgmt.NewTxSetStateDelta(), txStateDeltaHash: make(map[string][]byte), overlay: true}
 }
 
 // TxBegin marks begin of a new tx. If a tx is already in progress, this call panics.
@@ -128,14 +140,10 @@ func (state *TxSetState) Set(txSetID string, stateValue *pb.TxSetStateValue) err
 		panic("State can be changed only in context of a tx.")
 	}
 
-	// Check if a previous value is already set in the state delta,
-	// if so raise a warning and not change the value. A transactionSet
-	// index can be changed only one time per block.
-	if state.currentTxSetStateDelta.IsUpdatedValueSet(txSetID) || state.txSetStateDelta.IsUpdatedValueSet(txSetID) {
-		txSetStateLogger.Warning("Potential dependency cycle avoided by not changing an already modified tx set value")
-		// No need to bother looking up the previous value as we will not
-		// set it again. Just pass nil
-		return nil
+	// The state of a transactions set can be changed only once per block,
+	// the first change wins and the following ones are rejected
+	if state.IsModified(txSetID) {
+		return fmt.Errorf("The state of the tx set with ID: %s was already changed in this block.", txSetID)
 	}
 
 	// Lookup the previous value
@@ -148,6 +156,11 @@ func (state *TxSetState) Set(txSetID string, stateValue *pb.TxSetStateValue) err
 	return nil
 }
 
+// IsModified returns true if the state of txSetID was already changed by the ongoing batch
+func (state *TxSetState) IsModified(txSetID string) bool {
+	return state.currentTxSetStateDelta.IsUpdatedValueSet(txSetID) || state.txSetStateDelta.IsUpdatedValueSet(txSetID)
+}
+
 // Delete tracks the deletion of state for txSetID. Does not immediately write to DB
 // REVIEW: Should delete be allowed??
 func (state *TxSetState) Delete(txSetID string) error {
@@ -196,10 +209,25 @@ func (state *TxSetState) GetOlderBlockMod() (uint64, bool) {
 	return older, isSet
 }
 
+// GetMutatedTxSetIDs returns the IDs of the transactions sets whose default transaction was changed
+// by a mutant transaction in the ongoing batch
+func (state *TxSetState) GetMutatedTxSetIDs() []string {
+	var mutated []string
+	for _, stID := range state.txSetStateDelta.GetUpdatedTxSetIDs(true) {
+		if state.txSetStateDelta.GetUpdates(stID).IsMutant {
+			mutated = append(mutated, stID)
+		}
+	}
+	return mutated
+}
+
 // GetHash computes new state hash if the stateDelta is to be applied.
 // Recomputes only if stateDelta has changed after most recent call to this function
 func (state *TxSetState) GetHash() ([]byte, error) {
 	txSetStateLogger.Debug("Enter - GetHash()")
+	if state.overlay {
+		return nil, errors.New("The hash of an overlay of the tx set state cannot be computed")
+	}
 	if state.updateStateImpl {
 		txSetStateLogger.Debug("updating stateImpl with working-set")
 		state.txSetStateImpl.PrepareWorkingSet(state.txSetStateDelta)
@@ -222,7 +250,14 @@ func (state *TxSetState) GetTxStateDeltaHash() map[string][]byte {
 func (state *TxSetState) ClearInMemoryChanges(changesPersisted bool) {
 	state.txSetStateDelta = statemgmt.NewTxSetStateDelta()
 	state.txStateDeltaHash = make(map[string][]byte)
-	state.txSetStateImpl.ClearWorkingSet(changesPersisted)
+	if !state.overlay {
+		state.txSetStateImpl.ClearWorkingSet(changesPersisted)
+	}
+}
+
+// GetTxSetStateDelta returns the changes to the state of the transactions sets made by the ongoing batch
+func (state *TxSetState) GetTxSetStateDelta() *statemgmt.TxSetStateDelta {
+	return state.txSetStateDelta
 }
 
 // getStateDelta get changes in state after most recent call to method clearInMemoryChanges
@@ -254,9 +289,17 @@ func (state *TxSetState) FetchStateDeltaFromDB(blockNumber uint64) (*statemgmt.T
 	return stateDelta, nil
 }
 
+// GetHistoryStateDeltaSize returns the number of tx set state deltas kept in the db
+func (state *TxSetState) GetHistoryStateDeltaSize() uint64 {
+	return state.historyStateDeltaSize
+}
+
 // AddChangesForPersistence adds key-value pairs to writeBatch
 func (state *TxSetState) AddChangesForPersistence(blockNumber uint64, writeBatch *gorocksdb.WriteBatch) {
 	txSetStateLogger.Debug("txsetstate.addChangesForPersistence()...start")
+	if state.overlay {
+		panic("An overlay of the tx set state cannot be persisted")
+	}
 	if state.updateStateImpl {
 		state.txSetStateImpl.PrepareWorkingSet(state.txSetStateDelta)
 		state.updateStateImpl = false
@@ -289,6 +332,9 @@ func (state *TxSetState) ApplyStateDelta(delta *statemgmt.TxSetStateDelta) {
 // CommitStateDelta commits the changes from state.ApplyStateDelta to the
 // DB.
 func (state *TxSetState) CommitStateDelta() error {
+	if state.overlay {
+		return errors.New("An overlay of the tx set state cannot be committed")
+	}
 	if state.updateStateImpl {
 		state.txSetStateImpl.PrepareWorkingSet(state.txSetStateDelta)
 		state.updateStateImpl = false
@@ -310,6 +356,8 @@ func (state *TxSetState) DeleteState() error {
 	err := db.GetDBHandle().DeleteTxSetState()
 	if err != nil {
 		txSetStateLogger.Errorf("Error deleting state: %s", err)
+		return err
 	}
-	return err
+	// the implementation caches the persisted hash (and, for the bucket-tree, the bucket nodes)
+	return state.txSetStateImpl.Initialize(state.stateImplConfigs)
 }
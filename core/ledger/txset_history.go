@@ -0,0 +1,163 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos"
+)
+
+// GetTxSetStateHistory rebuilds the state that the transactions set with the given ID had once the block
+// blockNumber was committed, together with the index transitions caused by mutant transactions up to that
+// block. The state changes are read from the tx set state deltas; for the blocks whose delta was already
+// discarded they are computed again from the transactions contained in the block.
+func (ledger *Ledger) GetTxSetStateHistory(txSetID string, blockNumber uint64) (*protos.TxSetStateHistory, error) {
+	if blockNumber >= ledger.GetBlockchainSize() {
+		return nil, ErrOutOfBounds
+	}
+	current, err := ledger.GetTxSetState(txSetID, true)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the txSet state, txID: %s, err: %s.", txSetID, err)
+	}
+	if current == nil {
+		return nil, ErrResourceNotFound
+	}
+	if blockNumber < current.IntroBlock {
+		return nil, fmt.Errorf("The transactions set with ID %s was introduced at block %d, after the requested block %d.", txSetID, current.IntroBlock, blockNumber)
+	}
+
+	history := &protos.TxSetStateHistory{TxSetID: txSetID, BlockNr: blockNumber}
+	var txSetStValue *protos.TxSetStateValue
+	for blockNr := current.IntroBlock; blockNr <= blockNumber; blockNr++ {
+		var block *protos.Block
+		var nextValue *protos.TxSetStateValue
+		delta, err := ledger.txSetState.FetchStateDeltaFromDB(blockNr)
+		if err != nil {
+			return nil, err
+		}
+		if delta != nil {
+			updatedValue := delta.Get(txSetID)
+			if updatedValue == nil {
+				continue
+			}
+			nextValue = updatedValue.GetValue()
+		} else {
+			// The delta was discarded, recompute the change from the block
+			block, err = ledger.GetBlockByNumber(blockNr)
+			if err != nil {
+				return nil, err
+			}
+			nextValue = txSetStateAfterBlock(txSetID, txSetStValue, current.MutationPolicy, block, blockNr)
+		}
+		if nextValue == nil {
+			return nil, fmt.Errorf("Unable to rebuild the state of the transactions set with ID %s at block %d.", txSetID, blockNr)
+		}
+		if txSetStValue != nil && nextValue.Index != txSetStValue.Index {
+			if block == nil {
+				block, err = ledger.GetBlockByNumber(blockNr)
+				if err != nil {
+					return nil, err
+				}
+			}
+			history.Transitions = append(history.Transitions, &protos.TxSetIndexTransition{
+				BlockNr:    blockNr,
+				MutantTxid: mutantTxIDForSet(txSetID, block),
+				OldIndex:   txSetStValue.Index,
+				NewIndex:   nextValue.Index,
+			})
+		}
+		txSetStValue = nextValue
+	}
+	history.State = txSetStValue
+	return history, nil
+}
+
+// txSetStateAfterBlock applies to the given state the changes that the transactions of the block make to the
+// state of the transactions set, the same way the chaincode execution does. Only the first change to the set
+// in a block is taken, and the mutant transactions are stored in a block before the other transactions. A mutant
+// transaction scheduling or cancelling a mutation only changes the scheduled mutation of the set.
+func txSetStateAfterBlock(txSetID string, txSetStValue *protos.TxSetStateValue, mutationPolicy *protos.TxSetMutationPolicy, block *protos.Block, blockNr uint64) *protos.TxSetStateValue {
+	for _, inBlockTx := range block.GetTransactions() {
+		if mutant := inBlockTx.GetMutantTransaction(); mutant != nil {
+			mutation := mutationOfSet(txSetID, mutant)
+			if mutation == nil || txSetStValue == nil {
+				continue
+			}
+			nextValue := proto.Clone(txSetStValue).(*protos.TxSetStateValue)
+			nextValue.Nonce++
+			switch {
+			case mutant.CancelScheduled:
+				nextValue.ScheduledMutation = nil
+			case mutant.IsScheduling():
+				nextValue.ScheduledMutation = mutant.NewScheduledMutation(inBlockTx.Txid)
+			default:
+				if mutant.ScheduledBy != "" {
+					nextValue.ScheduledMutation = nil
+				}
+				nextValue.Index = mutation.TxSetIndex
+			}
+			nextValue.LastModifiedAtBlock = blockNr
+			return nextValue
+		}
+		txSet := inBlockTx.GetTransactionSet()
+		if inBlockTx.Txid != txSetID || txSet == nil {
+			continue
+		}
+		var nextValue *protos.TxSetStateValue
+		if txSetStValue == nil {
+			if txSet.Extend || len(txSet.Transactions) < 2 {
+				continue
+			}
+			nextValue = &protos.TxSetStateValue{
+				IntroBlock:                     blockNr,
+				Index:                          txSet.DefaultInx,
+				ConfidentialityProtocolVersion: inBlockTx.ConfidentialityProtocolVersion,
+				MutationPolicy:                 mutationPolicy,
+			}
+		} else {
+			nextValue = proto.Clone(txSetStValue).(*protos.TxSetStateValue)
+		}
+		nextValue.Nonce++
+		nextValue.TxNumber += uint64(len(txSet.Transactions))
+		nextValue.IndexAtBlock = append(nextValue.IndexAtBlock, &protos.TxSetIndex{InBlockIndex: nextValue.TxNumber - 1, BlockNr: blockNr})
+		nextValue.LastModifiedAtBlock = blockNr
+		return nextValue
+	}
+	return txSetStValue
+}
+
+// mutantTxIDForSet returns the ID of the mutant transaction that changed the index of the given set in the block
+func mutantTxIDForSet(txSetID string, block *protos.Block) string {
+	for _, inBlockTx := range block.GetTransactions() {
+		if mutant := inBlockTx.GetMutantTransaction(); mutant != nil && mutationOfSet(txSetID, mutant) != nil {
+			return inBlockTx.Txid
+		}
+	}
+	return ""
+}
+
+// mutationOfSet returns the mutation of the given set made by the mutant transaction, nil if it does not mutate it
+func mutationOfSet(txSetID string, mutant *protos.MutantTransaction) *protos.TxSetMutation {
+	for _, mutation := range mutant.GetTxSetMutations() {
+		if mutation.TxSetID == txSetID {
+			return mutation
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"testing"
+
+	txsetstmgmt "github.com/hyperledger/fabric/core/ledger/state/txsetst/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/protos"
+)
+
+func buildTestTxSetTx(txSetID string, txNumber int, extend bool) *protos.InBlockTransaction {
+	txSet := &protos.TransactionSet{Transactions: make([][]byte, txNumber), DefaultInx: 1, Extend: extend}
+	return &protos.InBlockTransaction{Txid: txSetID, Transaction: &protos.InBlockTransaction_TransactionSet{TransactionSet: txSet}}
+}
+
+func buildTestMutantTx(txid string, txSetID string, index uint64) *protos.InBlockTransaction {
+	mutant := &protos.MutantTransaction{TxSetID: txSetID, TxSetIndex: index}
+	return &protos.InBlockTransaction{Txid: txid, Transaction: &protos.InBlockTransaction_MutantTransaction{MutantTransaction: mutant}}
+}
+
+func TestTxSetStateAfterBlock(t *testing.T) {
+	// Creation
+	block := &protos.Block{Transactions: []*protos.InBlockTransaction{buildTestTxSetTx("other", 3, false), buildTestTxSetTx("set", 3, false)}}
+	value := txSetStateAfterBlock("set", nil, nil, block, 2)
+	testutil.AssertNotNil(t, value)
+	testutil.AssertEquals(t, value.IntroBlock, uint64(2))
+	testutil.AssertEquals(t, value.Index, uint64(1))
+	testutil.AssertEquals(t, value.TxNumber, uint64(3))
+
+	// Extension
+	block = &protos.Block{Transactions: []*protos.InBlockTransaction{buildTestTxSetTx("set", 2, true)}}
+	extended := txSetStateAfterBlock("set", value, nil, block, 4)
+	testutil.AssertEquals(t, extended.TxNumber, uint64(5))
+	testutil.AssertEquals(t, len(extended.IndexAtBlock), 2)
+	testutil.AssertEquals(t, extended.IndexAtBlock[1], &protos.TxSetIndex{BlockNr: 4, InBlockIndex: 4})
+	testutil.AssertEquals(t, value.TxNumber, uint64(3))
+
+	// Only the first change of a block is taken into account
+	block = &protos.Block{Transactions: []*protos.InBlockTransaction{buildTestMutantTx("mutant", "set", 4), buildTestTxSetTx("set", 2, true)}}
+	mutated := txSetStateAfterBlock("set", extended, nil, block, 5)
+	testutil.AssertEquals(t, mutated.Index, uint64(4))
+	testutil.AssertEquals(t, mutated.TxNumber, uint64(5))
+	testutil.AssertEquals(t, mutantTxIDForSet("set", block), "mutant")
+	testutil.AssertEquals(t, mutantTxIDForSet("other", block), "")
+
+	// A mutant transaction changing several sets
+	block = &protos.Block{Transactions: []*protos.InBlockTransaction{buildTestMutantTx("multi", "other", 1)}}
+	block.Transactions[0].GetMutantTransaction().AdditionalMutations = []*protos.TxSetMutation{{TxSetID: "set", TxSetIndex: 2}}
+	testutil.AssertEquals(t, txSetStateAfterBlock("set", mutated, nil, block, 6).Index, uint64(2))
+	testutil.AssertEquals(t, mutantTxIDForSet("set", block), "multi")
+
+	// Scheduling, cancelling and applying a mutation
+	block = &protos.Block{Transactions: []*protos.InBlockTransaction{buildTestMutantTx("schedule", "set", 3)}}
+	block.Transactions[0].GetMutantTransaction().AtBlock = 20
+	scheduled := txSetStateAfterBlock("set", mutated, nil, block, 7)
+	testutil.AssertEquals(t, scheduled.Index, mutated.Index)
+	testutil.AssertEquals(t, scheduled.ScheduledMutation, &protos.ScheduledMutation{MutantTxid: "schedule", TxSetIndex: 3, AtBlock: 20})
+	block = &protos.Block{Transactions: []*protos.InBlockTransaction{buildTestMutantTx("cancel", "set", 0)}}
+	block.Transactions[0].GetMutantTransaction().CancelScheduled = true
+	cancelled := txSetStateAfterBlock("set", scheduled, nil, block, 8)
+	testutil.AssertNil(t, cancelled.ScheduledMutation)
+	testutil.AssertEquals(t, cancelled.Index, mutated.Index)
+	block = &protos.Block{Transactions: []*protos.InBlockTransaction{buildTestMutantTx("apply", "set", 3)}}
+	block.Transactions[0].GetMutantTransaction().ScheduledBy = "schedule"
+	applied := txSetStateAfterBlock("set", scheduled, nil, block, 20)
+	testutil.AssertNil(t, applied.ScheduledMutation)
+	testutil.AssertEquals(t, applied.Index, uint64(3))
+	testutil.AssertEquals(t, applied.LastModifiedAtBlock, uint64(20))
+
+	// Blocks not touching the set leave the state unchanged
+	block = &protos.Block{Transactions: []*protos.InBlockTransaction{buildTestMutantTx("mutant", "other", 0)}}
+	testutil.AssertSame(t, txSetStateAfterBlock("set", mutated, nil, block, 6), mutated)
+}
+
+func TestBuildTxSetEvents(t *testing.T) {
+	delta := txsetstmgmt.NewTxSetStateDelta()
+	delta.Set("created", &protos.TxSetStateValue{IntroBlock: 3, TxNumber: 2}, nil)
+	delta.Set("extended", &protos.TxSetStateValue{TxNumber: 4}, &protos.TxSetStateValue{TxNumber: 2})
+	delta.Set("mutated", &protos.TxSetStateValue{Index: 1, TxNumber: 2}, &protos.TxSetStateValue{TxNumber: 2})
+	delta.Delete("deleted", &protos.TxSetStateValue{})
+	block := &protos.Block{Transactions: []*protos.InBlockTransaction{buildTestMutantTx("mutant", "mutated", 1)}}
+
+	events := buildTxSetEvents(delta, block, 3)
+	testutil.AssertEquals(t, len(events), 3)
+	testutil.AssertEquals(t, events[0].TxSetID, "created")
+	testutil.AssertEquals(t, events[0].Kind, protos.TxSetEvent_CREATED)
+	testutil.AssertNil(t, events[0].OldState)
+	testutil.AssertEquals(t, events[1].TxSetID, "extended")
+	testutil.AssertEquals(t, events[1].Kind, protos.TxSetEvent_EXTENDED)
+	testutil.AssertEquals(t, events[1].Txid, "extended")
+	testutil.AssertEquals(t, events[2].TxSetID, "mutated")
+	testutil.AssertEquals(t, events[2].Kind, protos.TxSetEvent_INDEX_CHANGED)
+	testutil.AssertEquals(t, events[2].Txid, "mutant")
+	testutil.AssertEquals(t, events[2].OldState.Index, uint64(0))
+	testutil.AssertEquals(t, events[2].NewState.Index, uint64(1))
+	testutil.AssertEquals(t, events[2].BlockNumber, uint64(3))
+}
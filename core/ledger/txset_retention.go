@@ -0,0 +1,291 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/crypto/txset"
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/state"
+	"github.com/hyperledger/fabric/core/util"
+	"github.com/hyperledger/fabric/protos"
+	"github.com/spf13/viper"
+	"github.com/tecbot/gorocksdb"
+)
+
+// A transactions set can only be mutated during the mutableBlocks blocks following the block that
+// introduced it. A mutation replays the blocks starting from the block that introduced the mutated set,
+// so the blocks preceding the oldest set that can still be mutated (the horizon) are never replayed
+// again. The garbage collection discards the data that is only needed for those replays: the nonces of
+// the immutable sets, the state checkpoints and forward deltas no longer needed to rebuild the state at
+// the block before the horizon, and the read/write sets of the blocks before the horizon.
+
+const defaultTxSetGCInterval = 10 * time.Minute
+
+var txSetGCReportKey = []byte("txSetGCReport")
+
+type txSetRetentionConfig struct {
+	once          sync.Once
+	mutableBlocks uint64
+	gcInterval    time.Duration
+}
+
+var retentionConfig txSetRetentionConfig
+
+func getTxSetRetentionConfig() *txSetRetentionConfig {
+	retentionConfig.once.Do(func() {
+		mutableBlocks := viper.GetInt("ledger.txSetState.mutableBlocks")
+		if mutableBlocks < 0 {
+			panic(fmt.Errorf("The number of blocks during which a tx set is mutable must be greater than or equal to 0. Current value is %d.", mutableBlocks))
+		}
+		retentionConfig.mutableBlocks = uint64(mutableBlocks)
+		retentionConfig.gcInterval = viper.GetDuration("ledger.txSetState.gcInterval")
+		if retentionConfig.gcInterval <= 0 {
+			retentionConfig.gcInterval = defaultTxSetGCInterval
+		}
+		ledgerLogger.Infof("Tx set retention configuration loaded. mutableBlocks=[%d], gcInterval=[%s]",
+			retentionConfig.mutableBlocks, retentionConfig.gcInterval)
+	})
+	return &retentionConfig
+}
+
+// IsTxSetMutable returns whether the set whose state is txSetStValue can be mutated by a transaction
+// of the block blockNumber. Sets are always mutable if no retention period is configured.
+func (ledger *Ledger) IsTxSetMutable(txSetStValue *protos.TxSetStateValue, blockNumber uint64) bool {
+	mutableBlocks := getTxSetRetentionConfig().mutableBlocks
+	return mutableBlocks == 0 || blockNumber <= txSetStValue.IntroBlock+mutableBlocks
+}
+
+// StartTxSetGC starts collecting in the background the data of the sets that can no longer be mutated.
+// Nothing is started if the sets are mutable forever.
+func (ledger *Ledger) StartTxSetGC() {
+	conf := getTxSetRetentionConfig()
+	if conf.mutableBlocks == 0 {
+		ledgerLogger.Info("Tx sets are mutable forever, the tx set garbage collection is disabled.")
+		return
+	}
+	go func() {
+		for {
+			time.Sleep(conf.gcInterval)
+			report, err := ledger.CollectTxSetGarbage()
+			if err != nil {
+				ledgerLogger.Errorf("Error while collecting the data of the immutable tx sets: %s", err)
+				continue
+			}
+			ledgerLogger.Debugf("Tx set garbage collection done: %s", report)
+		}
+	}()
+}
+
+// GetTxSetGCReport returns what the garbage collection reclaimed since the creation of the database
+func (ledger *Ledger) GetTxSetGCReport() (*protos.TxSetGCReport, error) {
+	return fetchTxSetGCReport()
+}
+
+// CollectTxSetGarbage deletes the nonces of the sets that can no longer be mutated and the per block data
+// that is only needed to replay the blocks preceding the oldest mutable set. The state deltas already
+// out of the delta history are deleted as well. It returns the updated report of the reclaimed data.
+func (ledger *Ledger) CollectTxSetGarbage() (*protos.TxSetGCReport, error) {
+	report, err := fetchTxSetGCReport()
+	if err != nil {
+		return nil, err
+	}
+	mutableBlocks := getTxSetRetentionConfig().mutableBlocks
+	if mutableBlocks == 0 {
+		return report, nil
+	}
+	ledger.blockStateLock.Lock()
+	defer ledger.blockStateLock.Unlock()
+	journal, err := fetchResetJournal()
+	if err != nil {
+		return nil, err
+	}
+	if journal != nil {
+		// The blocks are being replayed, try again once the reset is over
+		ledgerLogger.Debugf("Skipping the tx set garbage collection, a state reset is ongoing: %s", journal)
+		return report, nil
+	}
+	size := ledger.GetBlockchainSize()
+	horizon, err := ledger.txSetGCHorizon(size, mutableBlocks)
+	if err != nil {
+		return nil, err
+	}
+	ledgerLogger.Debugf("Collecting the tx set data preceding block [%d]", horizon)
+
+	openchainDB := db.GetDBHandle()
+	writeBatch := gorocksdb.NewWriteBatch()
+	defer writeBatch.Destroy()
+	nonces, reclaimed, err := txset.DeleteNonces(writeBatch, func(txSetID string) (bool, error) {
+		return ledger.isTxSetCollectable(txSetID, horizon)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to collect the nonces of the immutable tx sets. (%s)", err)
+	}
+	report.Nonces += nonces
+
+	// A replay from the horizon starts from the state at the end of the previous block, which is rebuilt
+	// from the nearest checkpoint preceding it
+	if horizon > 0 {
+		checkpoint, err := ledger.chaincodeState.FindBlockStateCheckpoint(horizon - 1)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to find the state checkpoint preceding block %d. (%s)", horizon-1, err)
+		}
+		entries, entriesSize, err := deleteBlockEntriesBefore(writeBatch, openchainDB.BlockStateCF, checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		report.BlockStates += entries
+		reclaimed += entriesSize
+	}
+	entries, entriesSize, err := deleteBlockEntriesBefore(writeBatch, openchainDB.TxRWSetCF, horizon)
+	if err != nil {
+		return nil, err
+	}
+	report.ReadWriteSets += entries
+	reclaimed += entriesSize
+
+	entries, entriesSize, err = deleteBlockEntriesBefore(writeBatch, openchainDB.StateDeltaCF,
+		deltaHistoryStart(size, ledger.chaincodeState.GetHistoryStateDeltaSize(), horizon))
+	if err != nil {
+		return nil, err
+	}
+	report.StateDeltas += entries
+	reclaimed += entriesSize
+	entries, entriesSize, err = deleteBlockEntriesBefore(writeBatch, openchainDB.TxSetStateDeltaCF,
+		deltaHistoryStart(size, ledger.txSetState.GetHistoryStateDeltaSize(), horizon))
+	if err != nil {
+		return nil, err
+	}
+	report.TxSetStateDeltas += entries
+	reclaimed += entriesSize
+
+	report.Horizon = horizon
+	report.Runs++
+	report.LastRun = util.CreateUtcTimestamp()
+	report.ReclaimedBytes += reclaimed
+	reportBytes, err := proto.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	writeBatch.PutCF(openchainDB.PersistCF, txSetGCReportKey, reportBytes)
+	if err := writeBatchToDB(writeBatch); err != nil {
+		return nil, fmt.Errorf("Unable to delete the data of the immutable tx sets. (%s)", err)
+	}
+	return report, nil
+}
+
+// txSetGCHorizon returns the block that introduced the oldest set that can still be mutated by the
+// next block, or the size of the chain if there is none
+func (ledger *Ledger) txSetGCHorizon(size uint64, mutableBlocks uint64) (uint64, error) {
+	blockNr := uint64(0)
+	if size > mutableBlocks {
+		blockNr = size - mutableBlocks
+	}
+	for ; blockNr < size; blockNr++ {
+		block, err := ledger.GetBlockByNumber(blockNr)
+		if err != nil {
+			return 0, fmt.Errorf("Unable to retrieve the block %d. (%s)", blockNr, err)
+		}
+		for _, inBlockTx := range block.GetTransactions() {
+			txSet := inBlockTx.GetTransactionSet()
+			if txSet == nil || txSet.Extend {
+				continue
+			}
+			txSetStValue, err := ledger.GetTxSetState(inBlockTx.Txid, true)
+			if err != nil {
+				return 0, err
+			}
+			if txSetStValue != nil && txSetStValue.IntroBlock == blockNr {
+				return blockNr, nil
+			}
+		}
+	}
+	return size, nil
+}
+
+// isTxSetCollectable returns whether the set was committed and last changed before the horizon: a replay
+// from the horizon still decrypts the extensions of older sets committed after it. Sets without a state
+// (made of a single transaction) are never mutated, their nonce is collected once they are committed.
+func (ledger *Ledger) isTxSetCollectable(txSetID string, horizon uint64) (bool, error) {
+	txSetStValue, err := ledger.GetTxSetState(txSetID, true)
+	if err != nil {
+		return false, err
+	}
+	if txSetStValue != nil {
+		return isTxSetStateCollectable(txSetStValue, horizon), nil
+	}
+	blockNr, err := ledger.GetTransactionBlockNumber(txSetID)
+	if err != nil {
+		// Not committed (yet), the nonce is kept
+		return false, nil
+	}
+	return blockNr < horizon, nil
+}
+
+// isTxSetStateCollectable returns whether neither the creation of the set whose state is txSetStValue nor
+// its last extension or mutation happened at or after the horizon
+func isTxSetStateCollectable(txSetStValue *protos.TxSetStateValue, horizon uint64) bool {
+	return txSetStValue.IntroBlock < horizon && txSetStValue.LastModifiedAtBlock < horizon
+}
+
+// deltaHistoryStart returns the first block whose state delta is kept: the ones in the delta history
+// and the ones following the horizon
+func deltaHistoryStart(size uint64, historySize uint64, horizon uint64) uint64 {
+	if size <= historySize {
+		return 0
+	}
+	if size-historySize < horizon {
+		return size - historySize
+	}
+	return horizon
+}
+
+// deleteBlockEntriesBefore adds to writeBatch the deletion of the entries of the column family, keyed
+// by block number, of the blocks preceding bound. It returns the number of entries and the bytes deleted.
+func deleteBlockEntriesBefore(writeBatch *gorocksdb.WriteBatch, cf *gorocksdb.ColumnFamilyHandle, bound uint64) (uint64, uint64, error) {
+	itr := db.GetDBHandle().GetIterator(cf)
+	defer itr.Close()
+	var entries, size uint64
+	for itr.SeekToFirst(); itr.Valid(); itr.Next() {
+		key := stcomm.Copy(itr.Key().Data())
+		if stcomm.DecodeStateDeltaKey(key) >= bound {
+			break
+		}
+		writeBatch.DeleteCF(cf, key)
+		entries++
+		size += uint64(len(key) + len(itr.Value().Data()))
+	}
+	return entries, size, itr.Err()
+}
+
+func fetchTxSetGCReport() (*protos.TxSetGCReport, error) {
+	report := &protos.TxSetGCReport{}
+	reportBytes, err := db.GetDBHandle().Get(db.GetDBHandle().PersistCF, txSetGCReportKey)
+	if err != nil {
+		return nil, err
+	}
+	if reportBytes != nil {
+		if err := proto.Unmarshal(reportBytes, report); err != nil {
+			return nil, fmt.Errorf("Unable to unmarshal the tx set garbage collection report. (%s)", err)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/state"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/protos"
+	"github.com/tecbot/gorocksdb"
+)
+
+func TestDeltaHistoryStart(t *testing.T) {
+	// The chain is shorter than the delta history
+	testutil.AssertEquals(t, deltaHistoryStart(10, 20, 5), uint64(0))
+	// The horizon precedes the delta history
+	testutil.AssertEquals(t, deltaHistoryStart(100, 20, 50), uint64(50))
+	// The delta history precedes the horizon
+	testutil.AssertEquals(t, deltaHistoryStart(100, 20, 90), uint64(80))
+}
+
+func TestIsTxSetStateCollectable(t *testing.T) {
+	testutil.AssertEquals(t, isTxSetStateCollectable(&protos.TxSetStateValue{IntroBlock: 2, LastModifiedAtBlock: 2}, 5), true)
+	testutil.AssertEquals(t, isTxSetStateCollectable(&protos.TxSetStateValue{IntroBlock: 5, LastModifiedAtBlock: 5}, 5), false)
+	// The set was extended after the horizon, a replay from the horizon decrypts the extension
+	testutil.AssertEquals(t, isTxSetStateCollectable(&protos.TxSetStateValue{IntroBlock: 2, LastModifiedAtBlock: 7}, 5), false)
+}
+
+func TestDeleteBlockEntriesBefore(t *testing.T) {
+	testDBWrapper.CleanDB(t)
+	openchainDB := db.GetDBHandle()
+	writeBatch := gorocksdb.NewWriteBatch()
+	defer writeBatch.Destroy()
+	for i := uint64(0); i < 10; i++ {
+		writeBatch.PutCF(openchainDB.TxRWSetCF, stcomm.EncodeStateDeltaKey(i), []byte("value"))
+	}
+	testDBWrapper.WriteToDB(t, writeBatch)
+
+	deletionBatch := gorocksdb.NewWriteBatch()
+	defer deletionBatch.Destroy()
+	entries, size, err := deleteBlockEntriesBefore(deletionBatch, openchainDB.TxRWSetCF, 4)
+	testutil.AssertNoError(t, err, "Error while deleting the entries")
+	testutil.AssertEquals(t, entries, uint64(4))
+	testutil.AssertEquals(t, size, uint64(4*(8+5)))
+	testDBWrapper.WriteToDB(t, deletionBatch)
+
+	for i := uint64(0); i < 10; i++ {
+		value, err := openchainDB.GetFromTxRWSetCF(stcomm.EncodeStateDeltaKey(i))
+		testutil.AssertNoError(t, err, "Error while reading the entries")
+		if i < 4 {
+			testutil.AssertNil(t, value)
+		} else {
+			testutil.AssertNotNil(t, value)
+		}
+	}
+}
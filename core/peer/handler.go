@@ -530,16 +530,22 @@ func (d *Handler) sendStateSnapshot(syncStateSnapshotRequest *pb.SyncStateSnapsh
 	// Iterate over the state deltas and send to requestor
 	currBlockNumber := chainSnapshot.GetBlockNumber()
 	var sequence uint64
-	// Loop through and send the Deltas
-	for i := 0; chainSnapshot.Next() || txSetSnapshot.Next(); i++ {
+	// Loop through and send the Deltas, each one carrying the next key of both states until they are exhausted
+	hasChainKV, hasTxSetKV := true, true
+	for i := 0; ; i++ {
+		hasChainKV = hasChainKV && chainSnapshot.Next()
+		hasTxSetKV = hasTxSetKV && txSetSnapshot.Next()
+		if !hasChainKV && !hasTxSetKV {
+			break
+		}
 		delta := chainstmgmt.NewStateDelta()
-		if chainSnapshot.Valid() {
+		if hasChainKV {
 			k, v := chainSnapshot.GetRawKeyValue()
 			cID, keyID := stcomm.DecodeCompositeKey(k)
 			delta.Set(cID, keyID, v, nil)
 		}
 		txSetStateDelta := txsetstmgmt.NewTxSetStateDelta()
-		if txSetSnapshot.Valid() {
+		if hasTxSetKV {
 			k, v := txSetSnapshot.GetRawKeyValue()
 			txID := stcomm.DecomposeTxSetKey(k)
 			txSetStateValue, err := pb.UnmarshalTxSetStateValue(v)
@@ -655,7 +661,7 @@ func (d *Handler) sendStateDeltas(syncStateDeltasRequest *pb.SyncStateDeltasRequ
 			peerLogger.Errorf("Error sending stateDelta for blockNum %d: %s", currBlockNum, err)
 			break
 		}
-		if stateDelta == nil {
+		if stateDelta == nil || txSetStDelta == nil {
 			peerLogger.Warningf("Requested to send a stateDelta for blockNum %d which has been discarded", currBlockNum)
 			break
 		}
@@ -302,6 +302,13 @@ func (p *Impl) ProcessTransaction(ctx context.Context, tx *pb.InBlockTransaction
 				peerLogger.Errorf("ProcessTransaction failed to verify transaction %v", err)
 				return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(err.Error())}, nil
 			}
+			// The co-signers of a mutation are held to the same revocation check as the submitter
+			for _, sig := range tx.GetMutantTransaction().GetSignatures() {
+				if err = secHelper.CheckCertificateRevocation(sig.Cert); err != nil {
+					peerLogger.Errorf("ProcessTransaction failed to verify mutation signature %v", err)
+					return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(err.Error())}, nil
+				}
+			}
 		}
 
 	}
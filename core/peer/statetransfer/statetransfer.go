@@ -92,6 +92,9 @@ type coordinatorImpl struct {
 	maxStateDeltaRange uint64 // The maximum number of state deltas to attempt to retrieve at once, to prevent from overflowing the peer's buffer
 
 	currentStateBlockNumber uint64 // When state transfer does not complete successfully, the current state does not always correspond to the block height
+
+	snapshotPeer           *pb.PeerID          // The peer the current, not yet verified, state snapshot was retrieved from
+	divergentSnapshotPeers map[string]struct{} // Peers whose state snapshot did not match the blockchain, not asked again for one until every peer was
 }
 
 // SyncToTarget consumes the calling thread and attempts to perform state transfer until success or an error occurs
@@ -144,6 +147,7 @@ func NewCoordinatorImpl(stack PartialStack) Coordinator {
 	sts.stateValid = true // Assume our starting state is correct unless told otherwise
 
 	sts.validBlockRanges = make([]*blockRange, 0)
+	sts.divergentSnapshotPeers = make(map[string]struct{})
 	sts.blockVerifyChunkSize = uint64(viper.GetInt("statetransfer.blocksperrequest"))
 	if sts.blockVerifyChunkSize == 0 {
 		panic(fmt.Errorf("Must set statetransfer.blocksperrequest to be nonzero"))
@@ -631,22 +635,35 @@ func (sts *coordinatorImpl) attemptStateTransfer(blockNumber uint64, peerIDs []*
 
 	}
 
+	txSetStateHash, err := sts.stack.GetCurrentTxSetStateHash()
+	if nil != err {
+		sts.stateValid = false
+		return fmt.Errorf("Could not compute its current tx set state hash: %s", err), true
+	}
+
 	block, err := sts.stack.GetBlockByNumber(sts.currentStateBlockNumber)
 	if err != nil {
 		return fmt.Errorf("Could not get block %d though we just retrieved it: %s", sts.currentStateBlockNumber, err), true
 	}
 
-	if !bytes.Equal(stateHash, block.StateHash) {
+	if !bytes.Equal(stateHash, block.StateHash) || !bytes.Equal(txSetStateHash, block.TxSetStateHash) {
 		if sts.stateValid {
 			sts.stateValid = false
-			return fmt.Errorf("Believed its state for block %d to be valid, but its hash (%x) did not match the recovered blockchain's (%x)", sts.currentStateBlockNumber, stateHash, block.StateHash), true
+			return fmt.Errorf("Believed its state for block %d to be valid, but its hashes (%x, %x) did not match the recovered blockchain's (%x, %x)", sts.currentStateBlockNumber, stateHash, txSetStateHash, block.StateHash, block.TxSetStateHash), true
+		}
+		if nil != sts.snapshotPeer {
+			// Ask the other peers for the snapshot first on the next attempt
+			sts.divergentSnapshotPeers[sts.snapshotPeer.Name] = struct{}{}
+			sts.snapshotPeer = nil
 		}
-		return fmt.Errorf("Recovered to an incorrect state at block number %d, (%x, %x)", sts.currentStateBlockNumber, stateHash, block.StateHash), true
+		return fmt.Errorf("Recovered to an incorrect state at block number %d, (%x, %x), tx set state (%x, %x)", sts.currentStateBlockNumber, stateHash, block.StateHash, txSetStateHash, block.TxSetStateHash), true
 	}
 
-	logger.Debugf("State is now valid at block %d and hash %x", sts.currentStateBlockNumber, stateHash)
+	logger.Debugf("State is now valid at block %d and hash %x, tx set state hash %x", sts.currentStateBlockNumber, stateHash, txSetStateHash)
 
 	sts.stateValid = true
+	sts.snapshotPeer = nil
+	sts.divergentSnapshotPeers = make(map[string]struct{})
 
 	if sts.currentStateBlockNumber < blockNumber {
 		err = sts.playStateUpToBlockNumber(blockNumber, peerIDs)
@@ -692,6 +709,10 @@ func (sts *coordinatorImpl) playStateUpToBlockNumber(toBlockNumber uint64, peerI
 						return fmt.Errorf("Received a state delta from %v either in the wrong order (backwards) or not next in sequence, aborting, start=%d, end=%d", peerID, deltaMessage.Range.Start, deltaMessage.Range.End)
 					}
 
+					if len(deltaMessage.Deltas) != len(deltaMessage.TxSetDeltas) {
+						return fmt.Errorf("Received %d state deltas but %d tx set state deltas from %v", len(deltaMessage.Deltas), len(deltaMessage.TxSetDeltas), peerID)
+					}
+
 					for i := range deltaMessage.Deltas {
 						umDelta := &chainstmgmt.StateDelta{}
 						if err := umDelta.Unmarshal(deltaMessage.Deltas[i]); nil != err {
@@ -701,7 +722,9 @@ func (sts *coordinatorImpl) playStateUpToBlockNumber(toBlockNumber uint64, peerI
 						if err := umTxSetStateDelta.Unmarshal(deltaMessage.TxSetDeltas[i]); nil != err {
 							return fmt.Errorf("Received a corrupt tx set state delta from %v : %s", peerID, err)
 						}
-						sts.stack.ApplyStateDelta(deltaMessage, umDelta, umTxSetStateDelta)
+						if err := sts.stack.ApplyStateDelta(deltaMessage, umDelta, umTxSetStateDelta); nil != err {
+							return fmt.Errorf("Could not apply the state delta from %v : %s", peerID, err)
+						}
 
 						success := false
 
@@ -721,7 +744,11 @@ func (sts *coordinatorImpl) playStateUpToBlockNumber(toBlockNumber uint64, peerI
 								logger.Warningf("Could not compute tx set state hash for some reason: %s", err)
 							}
 							logger.Debugf("Played state forward from %v to block %d with TxSetStateHash (%x), block has TxSetStateHash (%x)", peerID, deltaMessage.Range.End, txSetStateHash, testBlock.TxSetStateHash)
-							if bytes.Equal(testBlock.StateHash, stateHash) && bytes.Equal(testBlock.TxSetStateHash, txSetStateHash) {
+							if !bytes.Equal(testBlock.StateHash, stateHash) {
+								logger.Warningf("Played state forward from %v to block %d, but its StateHash (%x) did not match the block's (%x)", peerID, deltaMessage.Range.End, stateHash, testBlock.StateHash)
+							} else if !bytes.Equal(testBlock.TxSetStateHash, txSetStateHash) {
+								logger.Warningf("Played state forward from %v to block %d, but its TxSetStateHash (%x) did not match the block's (%x)", peerID, deltaMessage.Range.End, txSetStateHash, testBlock.TxSetStateHash)
+							} else {
 								success = true
 							}
 						}
@@ -769,8 +796,14 @@ func (sts *coordinatorImpl) syncStateSnapshot(minBlockNumber uint64, peerIDs []*
 	logger.Debugf("Attempting to retrieve state snapshot from %v", peerIDs)
 
 	currentStateBlock := uint64(0)
+	skipped := 0
 
 	ok := sts.tryOverPeers(peerIDs, func(peerID *pb.PeerID) error {
+		if _, divergent := sts.divergentSnapshotPeers[peerID.Name]; divergent {
+			skipped++
+			return fmt.Errorf("%v previously sent a state snapshot which did not match the blockchain", peerID)
+		}
+
 		logger.Debugf("Initiating state recovery from %v", peerID)
 
 		if err := sts.stack.EmptyState(); nil != err {
@@ -806,6 +839,7 @@ func (sts *coordinatorImpl) syncStateSnapshot(minBlockNumber uint64, peerIDs []*
 					}
 
 					logger.Debugf("Received final piece of state snapshot from %v after %d deltas. Chaincode state now has hash %x, Tx Set State now has hash: %x", peerID, counter, stateHash, txSetStateHash)
+					sts.snapshotPeer = peerID
 					return nil
 				}
 				//TODO REVIEW: Check if unmarshalling an empty state produces any problem.
@@ -819,7 +853,9 @@ func (sts *coordinatorImpl) syncStateSnapshot(minBlockNumber uint64, peerIDs []*
 				if err := umTxSetState.Unmarshal(piece.TxSetDelta); nil != err {
 					return fmt.Errorf("received a corrupt tx set state delta from %v after %d deltas : %s", peerID, counter, err)
 				}
-				sts.stack.ApplyStateDelta(piece, umDelta, umTxSetState)
+				if err := sts.stack.ApplyStateDelta(piece, umDelta, umTxSetState); nil != err {
+					return fmt.Errorf("could not apply state delta from %v after %d deltas: %s", peerID, counter, err)
+				}
 				currentStateBlock = piece.BlockNumber
 				if err := sts.stack.CommitStateDelta(piece); nil != err {
 					return fmt.Errorf("could not commit state delta from %v after %d deltas: %s", peerID, counter, err)
@@ -832,6 +868,11 @@ func (sts *coordinatorImpl) syncStateSnapshot(minBlockNumber uint64, peerIDs []*
 
 	})
 
+	if nil != ok && skipped > 0 {
+		// Every other peer failed as well, give the skipped ones another chance
+		sts.divergentSnapshotPeers = make(map[string]struct{})
+	}
+
 	return currentStateBlock, ok
 }
 
@@ -173,6 +173,31 @@ func (s *ServerOpenchain) GetTransactionByID(ctx context.Context, txID string) (
 	return transaction, nil
 }
 
+// GetTxSetDefaultTransaction returns the transaction currently active in the
+// transactions set with the given ID. As for blocks, the code package of a
+// deploy transaction is removed from the payload.
+func (s *ServerOpenchain) GetTxSetDefaultTransaction(ctx context.Context, txSetID string) (*pb.Transaction, error) {
+	transaction, err := s.ledger.GetCurrentDefaultByID(txSetID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.Type == pb.ChaincodeAction_CHAINCODE_DEPLOY {
+		deploymentSpec := &pb.ChaincodeDeploymentSpec{}
+		if err := proto.Unmarshal(transaction.Payload, deploymentSpec); err != nil {
+			if !viper.GetBool("security.privacy") {
+				return nil, err
+			}
+			deploymentSpec = &pb.ChaincodeDeploymentSpec{}
+		}
+		deploymentSpec.CodePackage = nil
+		transaction.Payload, err = proto.Marshal(deploymentSpec)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return transaction, nil
+}
+
 // GetPeers returns a list of all peer nodes currently connected to the target peer.
 func (s *ServerOpenchain) GetPeers(ctx context.Context, e *empty.Empty) (*pb.PeersMessage, error) {
 	return s.peerInfo.GetPeers()
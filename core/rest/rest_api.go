@@ -43,6 +43,7 @@ import (
 	"github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/core/crypto"
 	"github.com/hyperledger/fabric/core/crypto/primitives"
+	"github.com/hyperledger/fabric/core/crypto/txset"
 	pb "github.com/hyperledger/fabric/protos"
 )
 
@@ -75,6 +76,29 @@ type tcertsResult struct {
 	OK []string
 }
 
+// txSetRequest defines the request payload for the /txsets and
+// /txsets/:id/extensions endpoints. It accepts the same JSON as the TxSetInput
+// files of the CLI, plus the fields the CLI keeps locally.
+type txSetRequest struct {
+	pb.TxSetInput
+	// SecureContext is the enrollment ID of the user issuing the set, required
+	// when security is enabled.
+	SecureContext string `json:"secureContext,omitempty"`
+	// Seed is the seed returned at the creation of the set. It is required to
+	// encrypt the transactions of an extension.
+	Seed []byte `json:"seed,omitempty"`
+}
+
+// txSetResult defines the response payload for the /txsets endpoints.
+type txSetResult struct {
+	TxSetID string `json:"txSetID,omitempty"`
+	// Seed used to encrypt the transactions of the set. Only returned at the
+	// creation of the set, clients must keep it to extend the set.
+	Seed               []byte              `json:"seed,omitempty"`
+	State              *pb.TxSetStateValue `json:"state,omitempty"`
+	DefaultTransaction *pb.Transaction     `json:"defaultTransaction,omitempty"`
+}
+
 // rpcRequest defines the JSON RPC 2.0 request payload for the /chaincode endpoint.
 type rpcRequest struct {
 	Jsonrpc *string           `json:"jsonrpc,omitempty"`
@@ -307,6 +331,16 @@ func (s *ServerOpenchainREST) Register(rw web.ResponseWriter, req *web.Request)
 			panic(fmt.Errorf("Fatal error when storing client login token: %s\n", err))
 		}
 
+		// Store the chain public key, used to encrypt the nonces of the transactions sets
+		if len(loginResult.Msg) != 0 {
+			err = ioutil.WriteFile(localStore+"chainpub_"+loginSpec.EnrollId, loginResult.Msg, 0755)
+			if err != nil {
+				rw.WriteHeader(http.StatusInternalServerError)
+				encoder.Encode(restResult{Error: fmt.Sprintf("Fatal error -- %s", err)})
+				panic(fmt.Errorf("Fatal error when storing the chain public key: %s\n", err))
+			}
+		}
+
 		rw.WriteHeader(http.StatusOK)
 		encoder.Encode(restResult{OK: fmt.Sprintf("Login successful for user '%s'.", loginSpec.EnrollId)})
 		restLogger.Infof("Login successful for user '%s'.\n", loginSpec.EnrollId)
@@ -1698,6 +1732,431 @@ func (s *ServerOpenchainREST) processChaincodeInvokeOrQuery(method string, spec
 	return result
 }
 
+// checkTxSetUser checks that the user acting on a transactions set is logged in
+// when security is enabled. It returns the HTTP status to reply with on error.
+func checkTxSetUser(user string) (int, error) {
+	if !core.SecurityEnabled() {
+		return http.StatusOK, nil
+	}
+	if user == "" {
+		return http.StatusBadRequest, errors.New("Must supply secureContext when security is enabled.")
+	}
+	if _, err := os.Stat(getRESTFilePath() + "loginToken_" + user); err != nil {
+		if os.IsNotExist(err) {
+			return http.StatusUnauthorized, errors.New(MissingRegistrationError.Data)
+		}
+		return http.StatusInternalServerError, fmt.Errorf("Unexpected fatal error when checking for client login token: %s", err)
+	}
+	return http.StatusOK, nil
+}
+
+// decodeTxSetRequest decodes the payload of the /txsets endpoints and encrypts
+// the transactions it carries. If seed is nil a new one is generated.
+func decodeTxSetRequest(req *web.Request, version string, startInx uint64, seed []byte) (*txSetRequest, []byte, [][]byte, int, error) {
+	var txSetReq txSetRequest
+	err := json.NewDecoder(req.Body).Decode(&txSetReq)
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil, nil, http.StatusBadRequest, errors.New("Payload must contain a TxSetInput.")
+		}
+		return nil, nil, nil, http.StatusBadRequest, err
+	}
+	if len(txSetReq.TxSpecs) == 0 {
+		return nil, nil, nil, http.StatusBadRequest, errors.New("A transactions set must contain at least one transaction.")
+	}
+	if status, err := checkTxSetUser(txSetReq.SecureContext); err != nil {
+		return nil, nil, nil, status, err
+	}
+
+	txSpecs := make([][]byte, len(txSetReq.TxSpecs))
+	for i, simpSpec := range txSetReq.TxSpecs {
+		spec := simpSpec.ChaincodeSpec()
+		if core.SecurityEnabled() {
+			spec.SecureContext = txSetReq.SecureContext
+			if viper.GetBool("security.privacy") {
+				spec.ConfidentialityLevel = pb.ConfidentialityLevel_CONFIDENTIAL
+			}
+		}
+		txSpecs[i], err = proto.Marshal(simpSpec.TxSpec(spec))
+		if err != nil {
+			return nil, nil, nil, http.StatusInternalServerError, fmt.Errorf("Unable to marshal the transaction at index %d: %s", i, err)
+		}
+	}
+
+	if seed == nil {
+		seed = txSetReq.Seed
+	}
+	seed, encryptedSpecs, err := txset.EncryptTxSetSpecificationStartingFrom(version, txSpecs, seed, startInx)
+	if err != nil {
+		return nil, nil, nil, http.StatusInternalServerError, err
+	}
+	return &txSetReq, seed, encryptedSpecs, http.StatusOK, nil
+}
+
+// txSetMetadata returns the nonce to send along the transactions of a set.
+// When security is enabled it is encrypted with the chain public key stored at
+// the login of the user.
+func txSetMetadata(user string, seed []byte) ([]byte, error) {
+	if !core.SecurityEnabled() {
+		return seed, nil
+	}
+	pem, err := ioutil.ReadFile(getRESTFilePath() + "chainpub_" + user)
+	if err != nil {
+		return nil, fmt.Errorf("Error when reading the chain public key: %s", err)
+	}
+	return txset.EncryptNonce(pem, seed)
+}
+
+// queryTxSetState retrieves the state of the given transactions set together
+// with its current default transaction. If ordered is set the query goes
+// through the consensus.
+func (s *ServerOpenchainREST) queryTxSetState(txSetID string, ordered bool) (*txSetResult, error) {
+	resp, err := s.devops.QueryTxSetState(context.Background(), &pb.MutantSpec{TxSetID: txSetID, Ordered: ordered})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != pb.Response_SUCCESS {
+		return nil, fmt.Errorf("Error querying the state of transactions set %s: %s", txSetID, string(resp.Msg))
+	}
+	state, err := pb.UnmarshalTxSetStateValue(resp.Msg)
+	if err != nil {
+		return nil, err
+	}
+	result := &txSetResult{TxSetID: txSetID, State: state}
+	result.DefaultTransaction, err = s.server.GetTxSetDefaultTransaction(context.Background(), txSetID)
+	if err != nil {
+		restLogger.Warningf("Unable to retrieve the default transaction of transactions set %s: %s", txSetID, err)
+	}
+	return result, nil
+}
+
+// replyTxSetState writes the state of the given transactions set as the
+// response of a successful request.
+func (s *ServerOpenchainREST) replyTxSetState(rw web.ResponseWriter, txSetID string, seed []byte) {
+	encoder := json.NewEncoder(rw)
+
+	result, err := s.queryTxSetState(txSetID, false)
+	if err != nil {
+		// The request went through, only the state is not available yet
+		restLogger.Warningf("Unable to retrieve the state of transactions set %s: %s", txSetID, err)
+		result = &txSetResult{TxSetID: txSetID}
+	}
+	result.Seed = seed
+
+	rw.WriteHeader(http.StatusOK)
+	encoder.Encode(result)
+}
+
+// CreateTxSet issues a new transactions set. The transactions are encrypted
+// with a fresh seed, which is returned to the client together with the ID
+// assigned to the set and its state.
+func (s *ServerOpenchainREST) CreateTxSet(rw web.ResponseWriter, req *web.Request) {
+	restLogger.Info("REST issuing transactions set...")
+	encoder := json.NewEncoder(rw)
+
+	txSetReq, seed, encryptedSpecs, status, err := decodeTxSetRequest(req, txset.ConfidentialityProtocolVersion, 0, nil)
+	if err != nil {
+		rw.WriteHeader(status)
+		encoder.Encode(restResult{Error: err.Error()})
+		restLogger.Errorf("Error: %s", err)
+
+		return
+	}
+
+	// Check that default index is in range
+	if txSetReq.DefaultIndex >= uint64(len(txSetReq.TxSpecs)) {
+		rw.WriteHeader(http.StatusBadRequest)
+		encoder.Encode(restResult{Error: fmt.Sprintf("Default index out of range. Index: %d; Set size: %d", txSetReq.DefaultIndex, len(txSetReq.TxSpecs))})
+		restLogger.Errorf("Error: Default index out of range. Index: %d; Set size: %d", txSetReq.DefaultIndex, len(txSetReq.TxSpecs))
+
+		return
+	}
+
+	metadata, err := txSetMetadata(txSetReq.SecureContext, seed)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		encoder.Encode(restResult{Error: err.Error()})
+		restLogger.Errorf("Error: %s", err)
+
+		return
+	}
+
+	txSetSpec := &pb.TxSetSpec{
+		Type:                           pb.TxSetSpec_CREATION,
+		TxSpecs:                        encryptedSpecs,
+		DefaultInx:                     txSetReq.DefaultIndex,
+		ConfidentialityLevel:           pb.ConfidentialityLevel_CONFIDENTIAL,
+		ConfidentialityProtocolVersion: txset.ConfidentialityProtocolVersion,
+		MutationPolicy:                 txSetReq.MutationPolicy,
+		Metadata:                       metadata,
+	}
+	if core.SecurityEnabled() {
+		txSetSpec.SecureContext = txSetReq.SecureContext
+	}
+
+	resp, err := s.devops.IssueTxSet(context.Background(), txSetSpec)
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		encoder.Encode(restResult{Error: fmt.Sprintf("Error issuing transactions set: %s", err)})
+		restLogger.Errorf("Error issuing transactions set: %s", err)
+
+		return
+	}
+
+	txSetID := string(resp.Msg)
+	restLogger.Infof("Successfully issued transactions set: %s", txSetID)
+	s.replyTxSetState(rw, txSetID, seed)
+}
+
+// ExtendTxSet adds the transactions in the payload to an existing transactions
+// set. The payload must carry the seed returned at the creation of the set.
+func (s *ServerOpenchainREST) ExtendTxSet(rw web.ResponseWriter, req *web.Request) {
+	restLogger.Info("REST extending transactions set...")
+	encoder := json.NewEncoder(rw)
+
+	txSetID := req.PathParams["id"]
+
+	current, err := s.queryTxSetState(txSetID, false)
+	if err != nil {
+		rw.WriteHeader(http.StatusNotFound)
+		encoder.Encode(restResult{Error: fmt.Sprintf("Unable to retrieve the transactions set to extend: %s", err)})
+		restLogger.Errorf("Unable to retrieve the transactions set to extend: %s", err)
+
+		return
+	}
+
+	// The seed is decoded from the payload, it must be given before encrypting
+	txSetReq, seed, encryptedSpecs, status, err := decodeTxSetRequest(req, current.State.ConfidentialityProtocolVersion, current.State.TxNumber, nil)
+	if err == nil && len(txSetReq.Seed) == 0 {
+		status, err = http.StatusBadRequest, errors.New("The seed of the transactions set must be provided to extend it.")
+	}
+	if err != nil {
+		rw.WriteHeader(status)
+		encoder.Encode(restResult{Error: err.Error()})
+		restLogger.Errorf("Error: %s", err)
+
+		return
+	}
+
+	metadata, err := txSetMetadata(txSetReq.SecureContext, seed)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		encoder.Encode(restResult{Error: err.Error()})
+		restLogger.Errorf("Error: %s", err)
+
+		return
+	}
+
+	txSetSpec := &pb.TxSetSpec{
+		Type:                           pb.TxSetSpec_EXTENSION,
+		TxSpecs:                        encryptedSpecs,
+		ExtSetID:                       txSetID,
+		ConfidentialityLevel:           pb.ConfidentialityLevel_CONFIDENTIAL,
+		ConfidentialityProtocolVersion: current.State.ConfidentialityProtocolVersion,
+		Metadata:                       metadata,
+	}
+
+	_, err = s.devops.IssueSetExtension(context.Background(), txSetSpec)
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		encoder.Encode(restResult{Error: fmt.Sprintf("Error extending transactions set: %s", err)})
+		restLogger.Errorf("Error extending transactions set %s: %s", txSetID, err)
+
+		return
+	}
+
+	restLogger.Infof("Successfully extended transactions set: %s", txSetID)
+	s.replyTxSetState(rw, txSetID, nil)
+}
+
+// MutateTxSet changes the active transaction of a transactions set. The
+// payload is a MutantSpec, its txSetID is taken from the path.
+func (s *ServerOpenchainREST) MutateTxSet(rw web.ResponseWriter, req *web.Request) {
+	restLogger.Info("REST mutating transactions set...")
+	encoder := json.NewEncoder(rw)
+
+	var mutantSpec pb.MutantSpec
+	err := json.NewDecoder(req.Body).Decode(&mutantSpec)
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		if err == io.EOF {
+			encoder.Encode(restResult{Error: "Payload must contain a MutantSpec."})
+			restLogger.Error("Error: Payload must contain a MutantSpec.")
+		} else {
+			encoder.Encode(restResult{Error: err.Error()})
+			restLogger.Errorf("Error: %s", err)
+		}
+
+		return
+	}
+	mutantSpec.TxSetID = req.PathParams["id"]
+
+	if status, err := checkTxSetUser(mutantSpec.SecureContext); err != nil {
+		rw.WriteHeader(status)
+		encoder.Encode(restResult{Error: err.Error()})
+		restLogger.Errorf("Error: %s", err)
+
+		return
+	}
+	if !core.SecurityEnabled() {
+		mutantSpec.SecureContext = ""
+	}
+
+	resp, err := s.devops.Mutate(context.Background(), &mutantSpec)
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		encoder.Encode(restResult{Error: fmt.Sprintf("Error mutating transactions set: %s", err)})
+		restLogger.Errorf("Error mutating transactions set %s: %s", mutantSpec.TxSetID, err)
+
+		return
+	}
+
+	restLogger.Infof("Successfully mutated transactions set %s with mutant transaction %s", mutantSpec.TxSetID, string(resp.Msg))
+	s.replyTxSetState(rw, mutantSpec.TxSetID, nil)
+}
+
+// SimulateTxSetMutation replays the blocks a mutation of a transactions set
+// would change without submitting it, and returns the resulting changes of
+// the chaincode state and of the outcome of the transactions. The payload is
+// a MutantSpec, its txSetID is taken from the path.
+func (s *ServerOpenchainREST) SimulateTxSetMutation(rw web.ResponseWriter, req *web.Request) {
+	restLogger.Info("REST simulating transactions set mutation...")
+	encoder := json.NewEncoder(rw)
+
+	var mutantSpec pb.MutantSpec
+	err := json.NewDecoder(req.Body).Decode(&mutantSpec)
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		if err == io.EOF {
+			encoder.Encode(restResult{Error: "Payload must contain a MutantSpec."})
+			restLogger.Error("Error: Payload must contain a MutantSpec.")
+		} else {
+			encoder.Encode(restResult{Error: err.Error()})
+			restLogger.Errorf("Error: %s", err)
+		}
+
+		return
+	}
+	mutantSpec.TxSetID = req.PathParams["id"]
+
+	if status, err := checkTxSetUser(mutantSpec.SecureContext); err != nil {
+		rw.WriteHeader(status)
+		encoder.Encode(restResult{Error: err.Error()})
+		restLogger.Errorf("Error: %s", err)
+
+		return
+	}
+	if !core.SecurityEnabled() {
+		mutantSpec.SecureContext = ""
+	}
+
+	resp, err := s.devops.SimulateMutation(context.Background(), &mutantSpec)
+	if err == nil && resp.Status != pb.Response_SUCCESS {
+		err = errors.New(string(resp.Msg))
+	}
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		encoder.Encode(restResult{Error: fmt.Sprintf("Error simulating the mutation: %s", err)})
+		restLogger.Errorf("Error simulating the mutation of transactions set %s: %s", mutantSpec.TxSetID, err)
+
+		return
+	}
+
+	simulation := &pb.MutationSimulation{}
+	if err = proto.Unmarshal(resp.Msg, simulation); err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		encoder.Encode(restResult{Error: fmt.Sprintf("Unable to unmarshal the mutation simulation: %s", err)})
+		restLogger.Errorf("Error unmarshalling the mutation simulation of transactions set %s: %s", mutantSpec.TxSetID, err)
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	encoder.Encode(simulation)
+}
+
+// GetTxSetState returns the state of a transactions set together with its
+// current default transaction. The ordered query parameter asks for a read
+// ordered by the consensus.
+func (s *ServerOpenchainREST) GetTxSetState(rw web.ResponseWriter, req *web.Request) {
+	encoder := json.NewEncoder(rw)
+
+	txSetID := req.PathParams["id"]
+
+	req.ParseForm()
+	ordered := false
+	if orderedParam := req.Form.Get("ordered"); orderedParam != "" {
+		var err error
+		ordered, err = strconv.ParseBool(orderedParam)
+		if err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			encoder.Encode(restResult{Error: "Ordered query parameter must be a boolean."})
+			restLogger.Errorf("Error: Ordered query parameter must be a boolean.")
+
+			return
+		}
+	}
+
+	result, err := s.queryTxSetState(txSetID, ordered)
+	if err != nil {
+		rw.WriteHeader(http.StatusNotFound)
+		encoder.Encode(restResult{Error: err.Error()})
+		restLogger.Errorf("Error retrieving the state of transactions set %s: %s", txSetID, err)
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	encoder.Encode(result)
+}
+
+// GetTxSetStateHistory returns the state of a transactions set at the block
+// given by the block query parameter, or at the latest block if it is not
+// given, together with the index changes caused by mutations up to that block.
+func (s *ServerOpenchainREST) GetTxSetStateHistory(rw web.ResponseWriter, req *web.Request) {
+	encoder := json.NewEncoder(rw)
+
+	historySpec := &pb.TxSetHistorySpec{TxSetID: req.PathParams["id"]}
+
+	req.ParseForm()
+	queryParams := req.Form
+	if queryParams["block"] != nil {
+		blockNumber, err := strconv.ParseUint(queryParams["block"][0], 10, 64)
+		if err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			encoder.Encode(restResult{Error: "Block query parameter must be a non-negative integer."})
+			restLogger.Errorf("Error: Block query parameter must be a non-negative integer.")
+
+			return
+		}
+		historySpec.BlockNr = blockNumber
+	}
+
+	resp, err := s.devops.QueryTxSetStateHistory(context.Background(), historySpec)
+	if err == nil && resp.Status != pb.Response_SUCCESS {
+		err = errors.New(string(resp.Msg))
+	}
+	if err != nil {
+		rw.WriteHeader(http.StatusNotFound)
+		encoder.Encode(restResult{Error: err.Error()})
+		restLogger.Errorf("Error retrieving the state history of transactions set %s: %s", historySpec.TxSetID, err)
+
+		return
+	}
+
+	history := &pb.TxSetStateHistory{}
+	if err = proto.Unmarshal(resp.Msg, history); err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		encoder.Encode(restResult{Error: fmt.Sprintf("Unable to unmarshal the state history: %s", err)})
+		restLogger.Errorf("Error unmarshalling the state history of transactions set %s: %s", historySpec.TxSetID, err)
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	encoder.Encode(history)
+}
+
 // GetPeers returns a list of all peer nodes currently connected to the target peer, including itself
 func (s *ServerOpenchainREST) GetPeers(rw web.ResponseWriter, req *web.Request) {
 	peers, err := s.server.GetPeers(context.Background(), &empty.Empty{})
@@ -1765,6 +2224,13 @@ func buildOpenchainRESTRouter() *web.Router {
 
 	router.Get("/transactions/:id", (*ServerOpenchainREST).GetTransactionByID)
 
+	router.Post("/txsets", (*ServerOpenchainREST).CreateTxSet)
+	router.Get("/txsets/:id", (*ServerOpenchainREST).GetTxSetState)
+	router.Get("/txsets/:id/history", (*ServerOpenchainREST).GetTxSetStateHistory)
+	router.Post("/txsets/:id/extensions", (*ServerOpenchainREST).ExtendTxSet)
+	router.Post("/txsets/:id/mutations", (*ServerOpenchainREST).MutateTxSet)
+	router.Post("/txsets/:id/simulations", (*ServerOpenchainREST).SimulateTxSetMutation)
+
 	router.Get("/network/peers", (*ServerOpenchainREST).GetPeers)
 
 	// Add not found page
@@ -18,6 +18,7 @@ package rest
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -27,6 +28,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
 
 	"github.com/hyperledger/fabric/core/ledger"
@@ -147,6 +149,76 @@ func (d *mockDevops) Query(c context.Context, cis *protos.ChaincodeInvocationSpe
 	return nil, fmt.Errorf("Unknown query function")
 }
 
+func (d *mockDevops) IssueTxSet(c context.Context, spec *protos.TxSetSpec) (*protos.Response, error) {
+	if len(spec.TxSpecs) == 0 {
+		return nil, fmt.Errorf("A transactions set must contain at least one transaction.")
+	}
+	return &protos.Response{Status: protos.Response_SUCCESS, Msg: []byte("new_txset_id")}, nil
+}
+
+func (d *mockDevops) IssueSetExtension(c context.Context, spec *protos.TxSetSpec) (*protos.Response, error) {
+	return &protos.Response{Status: protos.Response_SUCCESS, Msg: []byte(spec.ExtSetID)}, nil
+}
+
+func (d *mockDevops) Mutate(c context.Context, spec *protos.MutantSpec) (*protos.Response, error) {
+	if spec.Index == 0 {
+		return nil, fmt.Errorf("Mutating, but the active index did not change.")
+	}
+	return &protos.Response{Status: protos.Response_SUCCESS, Msg: []byte("mutant_tx_id")}, nil
+}
+
+func (d *mockDevops) SimulateMutation(c context.Context, spec *protos.MutantSpec) (*protos.Response, error) {
+	if spec.Index == 0 {
+		return &protos.Response{Status: protos.Response_FAILURE, Msg: []byte("The mutant transaction would be rejected")}, nil
+	}
+	simulation := &protos.MutationSimulation{
+		FromBlock:  1,
+		KeyChanges: []*protos.ChaincodeKeyChange{{ChaincodeID: "mycc", Key: "a", PreviousValue: []byte("10"), Value: []byte("20")}},
+	}
+	simulationBytes, err := proto.Marshal(simulation)
+	if err != nil {
+		return nil, err
+	}
+	return &protos.Response{Status: protos.Response_SUCCESS, Msg: simulationBytes}, nil
+}
+
+func (d *mockDevops) QueryTxSetState(c context.Context, spec *protos.MutantSpec) (*protos.Response, error) {
+	if spec.TxSetID == "non-existing" {
+		return nil, fmt.Errorf("The state queried does not exists. Tx set id: %s", spec.TxSetID)
+	}
+	state := &protos.TxSetStateValue{
+		Index:        0,
+		TxNumber:     2,
+		IndexAtBlock: []*protos.TxSetIndex{{BlockNr: 1, InBlockIndex: 1}},
+	}
+	stateBytes, err := state.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return &protos.Response{Status: protos.Response_SUCCESS, Msg: stateBytes}, nil
+}
+
+func (d *mockDevops) QueryTxSetStateHistory(c context.Context, spec *protos.TxSetHistorySpec) (*protos.Response, error) {
+	if spec.TxSetID == "non-existing" {
+		return &protos.Response{Status: protos.Response_FAILURE, Msg: []byte("ledger: resource not found")}, nil
+	}
+	history := &protos.TxSetStateHistory{
+		TxSetID:     spec.TxSetID,
+		BlockNr:     spec.BlockNr,
+		State:       &protos.TxSetStateValue{Index: 1, TxNumber: 2},
+		Transitions: []*protos.TxSetIndexTransition{{BlockNr: spec.BlockNr, MutantTxid: "mutant_txid", OldIndex: 0, NewIndex: 1}},
+	}
+	historyBytes, err := proto.Marshal(history)
+	if err != nil {
+		return nil, err
+	}
+	return &protos.Response{Status: protos.Response_SUCCESS, Msg: historyBytes}, nil
+}
+
+func (d *mockDevops) Revoke(ctx context.Context, spec *protos.RevocationSpec) (*protos.Response, error) {
+	return nil, nil
+}
+
 func (d *mockDevops) EXP_GetApplicationTCert(ctx context.Context, secret *protos.Secret) (*protos.Response, error) {
 	return nil, nil
 }
@@ -780,6 +852,123 @@ func TestServerOpenchainREST_API_Chaincode_Query(t *testing.T) {
 	}
 }
 
+func parseTxSetResult(t *testing.T, body []byte) txSetResult {
+	var res txSetResult
+	err := json.Unmarshal(body, &res)
+	if err != nil {
+		t.Fatalf("Invalid JSON response: %v", err)
+	}
+	return res
+}
+
+func TestServerOpenchainREST_API_TxSets(t *testing.T) {
+	ledger.InitTestLedger(t)
+	initGlobalServerOpenchain(t)
+
+	// Start the HTTP REST test server
+	httpServer := httptest.NewServer(buildOpenchainRESTRouter())
+	defer httpServer.Close()
+
+	// Create a set
+	txSetInput := `{"defaultIndex":0,"txSpecs":[` +
+		`{"action":2,"lang":1,"chaincodeID":{"name":"mycc"},"inputArgs":{"args":["invoke","a","b","10"]}},` +
+		`{"action":2,"lang":1,"chaincodeID":{"name":"mycc"},"inputArgs":{"args":["invoke","b","a","10"]}}]}`
+	_, body := performHTTPPost(t, httpServer.URL+"/txsets", []byte(txSetInput))
+	res := parseTxSetResult(t, body)
+	if res.TxSetID != "new_txset_id" {
+		t.Errorf("Expected the ID of the new set but got %#v", res.TxSetID)
+	}
+	if len(res.Seed) == 0 {
+		t.Errorf("Expected the seed of the new set to be returned")
+	}
+	if res.State == nil || res.State.TxNumber != 2 {
+		t.Errorf("Expected the state of the new set but got %#v", res.State)
+	}
+
+	// Default index out of range
+	_, body = performHTTPPost(t, httpServer.URL+"/txsets", []byte(`{"defaultIndex":1,"txSpecs":[{"action":2}]}`))
+	if parseRESTResult(t, body).Error == "" {
+		t.Errorf("Expected an error when the default index is out of range")
+	}
+
+	// Empty set
+	_, body = performHTTPPost(t, httpServer.URL+"/txsets", []byte(`{"txSpecs":[]}`))
+	if parseRESTResult(t, body).Error == "" {
+		t.Errorf("Expected an error when issuing an empty set")
+	}
+
+	// Extensions require the seed of the set
+	_, body = performHTTPPost(t, httpServer.URL+"/txsets/new_txset_id/extensions", []byte(`{"txSpecs":[{"action":2}]}`))
+	if parseRESTResult(t, body).Error == "" {
+		t.Errorf("Expected an error when extending a set without its seed")
+	}
+	extension := fmt.Sprintf(`{"seed":"%s","txSpecs":[{"action":2}]}`, base64.StdEncoding.EncodeToString(res.Seed))
+	_, body = performHTTPPost(t, httpServer.URL+"/txsets/new_txset_id/extensions", []byte(extension))
+	if res := parseTxSetResult(t, body); res.TxSetID != "new_txset_id" {
+		t.Errorf("Expected the ID of the extended set but got %#v", res.TxSetID)
+	}
+
+	// Mutations
+	_, body = performHTTPPost(t, httpServer.URL+"/txsets/new_txset_id/mutations", []byte(`{"index":0}`))
+	if parseRESTResult(t, body).Error == "" {
+		t.Errorf("Expected an error when the mutation does not change the index")
+	}
+	_, body = performHTTPPost(t, httpServer.URL+"/txsets/new_txset_id/mutations", []byte(`{"index":1}`))
+	if res := parseTxSetResult(t, body); res.TxSetID != "new_txset_id" {
+		t.Errorf("Expected the ID of the mutated set but got %#v", res.TxSetID)
+	}
+
+	// Simulations
+	_, body = performHTTPPost(t, httpServer.URL+"/txsets/new_txset_id/simulations", []byte(`{"index":0}`))
+	if parseRESTResult(t, body).Error == "" {
+		t.Errorf("Expected an error when the simulated mutation would be rejected")
+	}
+	_, body = performHTTPPost(t, httpServer.URL+"/txsets/new_txset_id/simulations", []byte(`{"index":1}`))
+	var simulation protos.MutationSimulation
+	if err := json.Unmarshal(body, &simulation); err != nil {
+		t.Fatalf("Invalid JSON response: %v", err)
+	}
+	if simulation.FromBlock != 1 || len(simulation.KeyChanges) != 1 || simulation.KeyChanges[0].Key != "a" {
+		t.Errorf("Expected the changes of the simulated mutation but got %#v", simulation)
+	}
+
+	// State queries
+	body = performHTTPGet(t, httpServer.URL+"/txsets/new_txset_id")
+	if res := parseTxSetResult(t, body); res.State == nil || res.State.TxNumber != 2 {
+		t.Errorf("Expected the state of the set but got %#v", res.State)
+	}
+	body = performHTTPGet(t, httpServer.URL+"/txsets/non-existing")
+	if parseRESTResult(t, body).Error == "" {
+		t.Errorf("Expected an error when querying a non existing set")
+	}
+	body = performHTTPGet(t, httpServer.URL+"/txsets/new_txset_id?ordered=true")
+	if res := parseTxSetResult(t, body); res.State == nil {
+		t.Errorf("Expected the state of the set from an ordered query but got %#v", res)
+	}
+	body = performHTTPGet(t, httpServer.URL+"/txsets/new_txset_id?ordered=maybe")
+	if parseRESTResult(t, body).Error == "" {
+		t.Errorf("Expected an error when the ordered parameter is not a boolean")
+	}
+
+	// History queries
+	body = performHTTPGet(t, httpServer.URL+"/txsets/new_txset_id/history?block=3")
+	var history protos.TxSetStateHistory
+	if err := json.Unmarshal(body, &history); err != nil {
+		t.Fatalf("Invalid JSON response: %v", err)
+	}
+	if history.BlockNr != 3 || len(history.Transitions) != 1 || history.Transitions[0].NewIndex != 1 {
+		t.Errorf("Expected the state history of the set at block 3 but got %#v", history)
+	}
+	body = performHTTPGet(t, httpServer.URL+"/txsets/new_txset_id/history?block=abc")
+	if parseRESTResult(t, body).Error == "" {
+		t.Errorf("Expected an error when the block is not a number")
+	}
+	body = performHTTPGet(t, httpServer.URL+"/txsets/non-existing/history")
+	if parseRESTResult(t, body).Error == "" {
+		t.Errorf("Expected an error when querying the history of a non existing set")
+	}
+}
+
 func TestServerOpenchainREST_API_NotFound(t *testing.T) {
 	httpServer := httptest.NewServer(buildOpenchainRESTRouter())
 	defer httpServer.Close()
@@ -84,6 +84,15 @@ func CreateUtcTimestamp() *timestamp.Timestamp {
 	return &(timestamp.Timestamp{Seconds: secs, Nanos: nanos})
 }
 
+// IsTimestampTooFarAhead returns true if ts is later than the local clock by more than maxSkew.
+// A nil timestamp is never too far ahead, and a maxSkew of 0 disables the check
+func IsTimestampTooFarAhead(ts *timestamp.Timestamp, maxSkew time.Duration) bool {
+	if ts == nil || maxSkew <= 0 {
+		return false
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).After(time.Now().Add(maxSkew))
+}
+
 //GenerateHashFromSignature returns a hash of the combined parameters
 func GenerateHashFromSignature(path string, args []byte) []byte {
 	return ComputeCryptoHash(args)
@@ -21,6 +21,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
 )
 
 func TestComputeCryptoHash(t *testing.T) {
@@ -98,3 +100,23 @@ func TestFindMissingElements(t *testing.T) {
 		}
 	}
 }
+
+func TestTimestampTooFarAhead(t *testing.T) {
+	now := CreateUtcTimestamp()
+	if IsTimestampTooFarAhead(now, time.Minute) {
+		t.Fatalf("The current time should not be too far ahead")
+	}
+	future := &timestamp.Timestamp{Seconds: now.Seconds + 3600}
+	if !IsTimestampTooFarAhead(future, time.Minute) {
+		t.Fatalf("A timestamp one hour ahead should be too far ahead with a skew of one minute")
+	}
+	if IsTimestampTooFarAhead(future, 2*time.Hour) {
+		t.Fatalf("A timestamp one hour ahead should be tolerated with a skew of two hours")
+	}
+	if IsTimestampTooFarAhead(future, 0) {
+		t.Fatalf("A skew of 0 should disable the check")
+	}
+	if IsTimestampTooFarAhead(nil, time.Minute) {
+		t.Fatalf("A nil timestamp should never be too far ahead")
+	}
+}
@@ -63,7 +63,7 @@ func (a *Adapter) updateCountNotify() {
 
 func (a *Adapter) Recv(msg *ehpb.Event) (bool, error) {
 	switch x := msg.Event.(type) {
-	case *ehpb.Event_Block, *ehpb.Event_ChaincodeEvent, *ehpb.Event_Register, *ehpb.Event_Unregister:
+	case *ehpb.Event_Block, *ehpb.Event_ChaincodeEvent, *ehpb.Event_TxSetEvent, *ehpb.Event_Register, *ehpb.Event_Unregister:
 		a.updateCountNotify()
 	case nil:
 		// The field is not set.
@@ -90,6 +90,12 @@ func createTestChaincodeEvent(tid string, typ string) *ehpb.Event {
 	return emsg
 }
 
+func createTestTxSetEvent(txSetID string) *ehpb.Event {
+	emsg := producer.CreateTxSetEvent(&ehpb.TxSetEvent{Kind: ehpb.TxSetEvent_INDEX_CHANGED, TxSetID: txSetID,
+		OldState: &ehpb.TxSetStateValue{Index: 0}, NewState: &ehpb.TxSetStateValue{Index: 1}, BlockNumber: 1})
+	return emsg
+}
+
 func closeListenerAndSleep(l net.Listener) {
 	l.Close()
 	time.Sleep(2 * time.Second)
@@ -177,6 +183,60 @@ func TestReceiveCCWildcard(t *testing.T) {
 	}
 }
 
+func TestReceiveTxSetEvent(t *testing.T) {
+	var err error
+	txSetInterest := &ehpb.Interest{EventType: ehpb.EventType_TXSET, RegInfo: &ehpb.Interest_TxSetRegInfo{TxSetRegInfo: &ehpb.TxSetReg{TxSetID: "txset1"}}}
+
+	adapter.count = 1
+	obcEHClient.RegisterAsync([]*ehpb.Interest{txSetInterest})
+
+	select {
+	case <-adapter.notfy:
+	case <-time.After(2 * time.Second):
+		t.Fail()
+		t.Logf("timed out on messge")
+	}
+
+	adapter.count = 1
+	emsg := createTestTxSetEvent("txset1")
+	if err = producer.Send(emsg); err != nil {
+		t.Fail()
+		t.Logf("Error sending message %s", err)
+	}
+
+	select {
+	case <-adapter.notfy:
+	case <-time.After(2 * time.Second):
+		t.Fail()
+		t.Logf("timed out on messge")
+	}
+
+	//events of other sets are filtered out
+	adapter.count = 1
+	emsg = createTestTxSetEvent("txset2")
+	if err = producer.Send(emsg); err != nil {
+		t.Fail()
+		t.Logf("Error sending message %s", err)
+	}
+
+	select {
+	case <-adapter.notfy:
+		t.Fail()
+		t.Logf("should NOT have received the event of txset2")
+	case <-time.After(2 * time.Second):
+	}
+
+	adapter.count = 1
+	obcEHClient.UnregisterAsync([]*ehpb.Interest{txSetInterest})
+
+	select {
+	case <-adapter.notfy:
+	case <-time.After(2 * time.Second):
+		t.Fail()
+		t.Logf("should have received unreg")
+	}
+}
+
 func TestFailReceive(t *testing.T) {
 	var err error
 
@@ -34,3 +34,13 @@ func CreateChaincodeEvent(te *ehpb.ChaincodeEvent) *ehpb.Event {
 func CreateRejectionEvent(tx *ehpb.InBlockTransaction, errorMsg string) *ehpb.Event {
 	return &ehpb.Event{Event: &ehpb.Event_Rejection{Rejection: &ehpb.Rejection{Tx: tx, ErrorMsg: errorMsg}}}
 }
+
+//CreateReplayEvent creates an Event from the report of a block replayed after a mutation
+func CreateReplayEvent(report *ehpb.BlockReplayReport) *ehpb.Event {
+	return &ehpb.Event{Event: &ehpb.Event_ReplayReport{ReplayReport: report}}
+}
+
+//CreateTxSetEvent creates an Event reporting a change of the state of a transactions set
+func CreateTxSetEvent(te *ehpb.TxSetEvent) *ehpb.Event {
+	return &ehpb.Event{Event: &ehpb.Event_TxSetEvent{TxSetEvent: te}}
+}
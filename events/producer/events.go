@@ -156,6 +156,73 @@ func (hl *chaincodeHandlerList) foreach(e *pb.Event, action func(h *handler)) {
 	}
 }
 
+type txSetHandlerList struct {
+	sync.RWMutex
+	handlers map[string]map[*handler]bool
+}
+
+//txSetRegID returns the set ID of a tx set interest, "" registers for all the sets
+func txSetRegID(ie *pb.Interest) string {
+	if ie.GetTxSetRegInfo() == nil {
+		return ""
+	}
+	return ie.GetTxSetRegInfo().TxSetID
+}
+
+func (hl *txSetHandlerList) add(ie *pb.Interest, h *handler) (bool, error) {
+	hl.Lock()
+	defer hl.Unlock()
+
+	txSetID := txSetRegID(ie)
+	handlerMap, ok := hl.handlers[txSetID]
+	if !ok {
+		handlerMap = make(map[*handler]bool)
+		hl.handlers[txSetID] = handlerMap
+	} else if _, ok = handlerMap[h]; ok {
+		return false, fmt.Errorf("handler exists for tx set ID %s", txSetID)
+	}
+	handlerMap[h] = true
+
+	return true, nil
+}
+
+func (hl *txSetHandlerList) del(ie *pb.Interest, h *handler) (bool, error) {
+	hl.Lock()
+	defer hl.Unlock()
+
+	txSetID := txSetRegID(ie)
+	handlerMap, ok := hl.handlers[txSetID]
+	if !ok {
+		return false, fmt.Errorf("tx set ID %s not registered", txSetID)
+	}
+	if _, ok = handlerMap[h]; !ok {
+		return false, fmt.Errorf("handler not registered for tx set ID %s", txSetID)
+	}
+	delete(handlerMap, h)
+	if len(handlerMap) == 0 {
+		delete(hl.handlers, txSetID)
+	}
+
+	return true, nil
+}
+
+func (hl *txSetHandlerList) foreach(e *pb.Event, action func(h *handler)) {
+	hl.Lock()
+	defer hl.Unlock()
+
+	if e.GetTxSetEvent() == nil || e.GetTxSetEvent().TxSetID == "" {
+		return
+	}
+
+	//handlers registered for the set, then handlers registered for all the sets
+	for h := range hl.handlers[e.GetTxSetEvent().TxSetID] {
+		action(h)
+	}
+	for h := range hl.handlers[""] {
+		action(h)
+	}
+}
+
 func (hl *genericHandlerList) add(ie *pb.Interest, h *handler) (bool, error) {
 	hl.Lock()
 	if _, ok := hl.handlers[h]; ok {
@@ -265,6 +332,10 @@ func AddEventType(eventType pb.EventType) error {
 		gEventProcessor.eventConsumers[eventType] = &chaincodeHandlerList{handlers: make(map[string]map[string]map[*handler]bool)}
 	case pb.EventType_REJECTION:
 		gEventProcessor.eventConsumers[eventType] = &genericHandlerList{handlers: make(map[*handler]bool)}
+	case pb.EventType_REPLAY:
+		gEventProcessor.eventConsumers[eventType] = &genericHandlerList{handlers: make(map[*handler]bool)}
+	case pb.EventType_TXSET:
+		gEventProcessor.eventConsumers[eventType] = &txSetHandlerList{handlers: make(map[string]map[*handler]bool)}
 	}
 	gEventProcessor.Unlock()
 
@@ -50,6 +50,10 @@ func getInterestKey(interest pb.Interest) string {
 		key = "/" + strconv.Itoa(int(pb.EventType_BLOCK))
 	case pb.EventType_REJECTION:
 		key = "/" + strconv.Itoa(int(pb.EventType_REJECTION))
+	case pb.EventType_REPLAY:
+		key = "/" + strconv.Itoa(int(pb.EventType_REPLAY))
+	case pb.EventType_TXSET:
+		key = "/" + strconv.Itoa(int(pb.EventType_TXSET)) + "/" + txSetRegID(&interest)
 	case pb.EventType_CHAINCODE:
 		key = "/" + strconv.Itoa(int(pb.EventType_CHAINCODE)) + "/" + interest.GetChaincodeRegInfo().ChaincodeID + "/" + interest.GetChaincodeRegInfo().EventName
 	default:
@@ -42,6 +42,10 @@ func getMessageType(e *pb.Event) pb.EventType {
 		return pb.EventType_CHAINCODE
 	case *pb.Event_Rejection:
 		return pb.EventType_REJECTION
+	case *pb.Event_ReplayReport:
+		return pb.EventType_REPLAY
+	case *pb.Event_TxSetEvent:
+		return pb.EventType_TXSET
 	default:
 		return -1
 	}
@@ -52,5 +56,7 @@ func addInternalEventTypes() {
 	AddEventType(pb.EventType_BLOCK)
 	AddEventType(pb.EventType_CHAINCODE)
 	AddEventType(pb.EventType_REJECTION)
+	AddEventType(pb.EventType_REPLAY)
+	AddEventType(pb.EventType_TXSET)
 	AddEventType(pb.EventType_REGISTER)
 }
@@ -32,23 +32,31 @@ type adapter struct {
 	notfy              chan *pb.Event_Block
 	rejected           chan *pb.Event_Rejection
 	cEvent             chan *pb.Event_ChaincodeEvent
+	replayed           chan *pb.Event_ReplayReport
+	txSetEvent         chan *pb.Event_TxSetEvent
 	listenToRejections bool
 	chaincodeID        string
+	txSetID            string
 }
 
 //GetInterestedEvents implements consumer.EventAdapter interface for registering interested events
 func (a *adapter) GetInterestedEvents() ([]*pb.Interest, error) {
+	txSetInterest := &pb.Interest{EventType: pb.EventType_TXSET,
+		RegInfo: &pb.Interest_TxSetRegInfo{
+			TxSetRegInfo: &pb.TxSetReg{TxSetID: a.txSetID}}}
 	if a.chaincodeID != "" {
 		return []*pb.Interest{
 			{EventType: pb.EventType_BLOCK},
 			{EventType: pb.EventType_REJECTION},
+			{EventType: pb.EventType_REPLAY},
+			txSetInterest,
 			{EventType: pb.EventType_CHAINCODE,
 				RegInfo: &pb.Interest_ChaincodeRegInfo{
 					ChaincodeRegInfo: &pb.ChaincodeReg{
 						ChaincodeID: a.chaincodeID,
 						EventName:   ""}}}}, nil
 	}
-	return []*pb.Interest{{EventType: pb.EventType_BLOCK}, {EventType: pb.EventType_REJECTION}}, nil
+	return []*pb.Interest{{EventType: pb.EventType_BLOCK}, {EventType: pb.EventType_REJECTION}, {EventType: pb.EventType_REPLAY}, txSetInterest}, nil
 }
 
 //Recv implements consumer.EventAdapter interface for receiving events
@@ -65,6 +73,14 @@ func (a *adapter) Recv(msg *pb.Event) (bool, error) {
 		a.cEvent <- o
 		return true, nil
 	}
+	if o, e := msg.Event.(*pb.Event_ReplayReport); e {
+		a.replayed <- o
+		return true, nil
+	}
+	if o, e := msg.Event.(*pb.Event_TxSetEvent); e {
+		a.txSetEvent <- o
+		return true, nil
+	}
 	a.notfy <- nil
 	return false, nil
 }
@@ -75,12 +91,12 @@ func (a *adapter) Disconnected(err error) {
 	os.Exit(1)
 }
 
-func createEventClient(eventAddress string, listenToRejections bool, cid string) *adapter {
+func createEventClient(eventAddress string, listenToRejections bool, cid string, txSetID string) *adapter {
 	var obcEHClient *consumer.EventsClient
 
 	done := make(chan *pb.Event_Block)
 	reject := make(chan *pb.Event_Rejection)
-	adapter := &adapter{notfy: done, rejected: reject, listenToRejections: listenToRejections, chaincodeID: cid, cEvent: make(chan *pb.Event_ChaincodeEvent)}
+	adapter := &adapter{notfy: done, rejected: reject, listenToRejections: listenToRejections, chaincodeID: cid, cEvent: make(chan *pb.Event_ChaincodeEvent), replayed: make(chan *pb.Event_ReplayReport), txSetEvent: make(chan *pb.Event_TxSetEvent), txSetID: txSetID}
 	obcEHClient, _ = consumer.NewEventsClient(eventAddress, 5, adapter)
 	if err := obcEHClient.Start(); err != nil {
 		fmt.Printf("could not start chat %s\n", err)
@@ -95,14 +111,16 @@ func main() {
 	var eventAddress string
 	var listenToRejections bool
 	var chaincodeID string
+	var txSetID string
 	flag.StringVar(&eventAddress, "events-address", "0.0.0.0:7053", "address of events server")
 	flag.BoolVar(&listenToRejections, "listen-to-rejections", false, "whether to listen to rejection events")
 	flag.StringVar(&chaincodeID, "events-from-chaincode", "", "listen to events from given chaincode")
+	flag.StringVar(&txSetID, "events-from-txset", "", "listen to the changes of the given transactions set, all the sets if not set")
 	flag.Parse()
 
 	fmt.Printf("Event Address: %s\n", eventAddress)
 
-	a := createEventClient(eventAddress, listenToRejections, chaincodeID)
+	a := createEventClient(eventAddress, listenToRejections, chaincodeID, txSetID)
 	if a == nil {
 		fmt.Printf("Error creating event client\n")
 		return
@@ -130,6 +148,21 @@ func main() {
 			fmt.Printf("Received chaincode event\n")
 			fmt.Printf("------------------------\n")
 			fmt.Printf("Chaincode Event:%v\n", ce)
+		case rr := <-a.replayed:
+			fmt.Printf("\n")
+			fmt.Printf("\n")
+			fmt.Printf("Received replay report for block %d\n", rr.ReplayReport.BlockNumber)
+			fmt.Printf("------------------------\n")
+			for _, r := range rr.ReplayReport.Transactions {
+				fmt.Printf("Transaction %s: %s -> %s\t%s\n", r.Txid, r.PreviousOutcome, r.Outcome, r.Error)
+			}
+		case te := <-a.txSetEvent:
+			fmt.Printf("\n")
+			fmt.Printf("\n")
+			fmt.Printf("Received tx set event %s for set %s at block %d\n", te.TxSetEvent.Kind, te.TxSetEvent.TxSetID, te.TxSetEvent.BlockNumber)
+			fmt.Printf("------------------------\n")
+			fmt.Printf("Old state: %v\n", te.TxSetEvent.OldState)
+			fmt.Printf("New state: %v\n", te.TxSetEvent.NewState)
 		}
 	}
 }
@@ -36,6 +36,7 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric/core/crypto/primitives"
+	"github.com/hyperledger/fabric/core/util"
 	"github.com/hyperledger/fabric/flogging"
 	pb "github.com/hyperledger/fabric/membersrvc/protos"
 	_ "github.com/mattn/go-sqlite3" // This blank import is required to load sqlite3 driver
@@ -49,11 +50,13 @@ var caLogger = logging.MustGetLogger("ca")
 type CA struct {
 	db *sql.DB
 
+	name string
 	path string
 
 	priv *ecdsa.PrivateKey
 	cert *x509.Certificate
 	raw  []byte
+	crl  []byte
 }
 
 // CertificateSpec defines the parameter used to create a new certificate.
@@ -82,6 +85,7 @@ var (
 	caCountry      string
 	rootPath       string
 	caDir          string
+	crlValidity    time.Duration
 )
 
 // NewCertificateSpec creates a new certificate spec
@@ -132,6 +136,10 @@ func CacheConfiguration() {
 	caCountry = viper.GetString("pki.ca.subject.country")
 	rootPath = viper.GetString("server.rootpath")
 	caDir = viper.GetString("server.cadir")
+	crlValidity = viper.GetDuration("pki.crl.validity")
+	if crlValidity <= 0 {
+		crlValidity = 24 * time.Hour
+	}
 }
 
 // GetID returns the spec's ID field/value
@@ -219,6 +227,9 @@ func initializeCommonTables(db *sql.DB) error {
 	if _, err := db.Exec("CREATE TABLE IF NOT EXISTS AffiliationGroups (row INTEGER PRIMARY KEY, name VARCHAR(64), parent INTEGER, FOREIGN KEY(parent) REFERENCES AffiliationGroups(row))"); err != nil {
 		return err
 	}
+	if _, err := db.Exec("CREATE TABLE IF NOT EXISTS Revocations (row INTEGER PRIMARY KEY, serial VARCHAR(64) UNIQUE, id VARCHAR(64), timestamp INTEGER)"); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -226,6 +237,7 @@ func initializeCommonTables(db *sql.DB) error {
 func NewCA(name string, initTables TableInitializer) *CA {
 	ca := new(CA)
 	flogging.LoggingInit("ca")
+	ca.name = name
 	ca.path = filepath.Join(rootPath, caDir)
 
 	if _, err := os.Stat(ca.path); err != nil {
@@ -271,6 +283,11 @@ func NewCA(name string, initTables TableInitializer) *CA {
 	ca.raw = raw
 	ca.cert = cert
 
+	// read the last published CRL, if any
+	if raw, err = ca.readPublishedCRL(); err == nil {
+		ca.crl = raw
+	}
+
 	return ca
 }
 
@@ -367,7 +384,7 @@ func (ca *CA) readCACertificate(name string) ([]byte, error) {
 }
 
 func (ca *CA) createCertificate(id string, pub interface{}, usage x509.KeyUsage, timestamp int64, kdfKey []byte, opt ...pkix.Extension) ([]byte, error) {
-	spec := NewDefaultCertificateSpec(id, pub, usage, opt...)
+	spec := NewDefaultPeriodCertificateSpec(id, util.GenerateIntUUID(), pub, usage, opt...)
 	return ca.createCertificateFromSpec(spec, timestamp, kdfKey, true)
 }
 
@@ -24,10 +24,13 @@ import (
 	"encoding/asn1"
 	"encoding/base64"
 	"encoding/pem"
+	"errors"
 	"io/ioutil"
+	"math/big"
 	"strconv"
 	"strings"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/crypto/primitives"
 	"github.com/hyperledger/fabric/flogging"
 	pb "github.com/hyperledger/fabric/membersrvc/protos"
@@ -220,3 +223,82 @@ func (eca *ECA) startECAA(srv *grpc.Server) {
 	pb.RegisterECAAServer(srv, &ECAA{eca})
 	ecaLogger.Info("ECA ADMIN gRPC API server started")
 }
+
+// verifySignature checks that sig is a signature of msg, whose own signature
+// field must have been cleared, under the enrollment certificate of member
+// 'id'. Revoked enrollment certificates are refused.
+//
+func (eca *ECA) verifySignature(id string, msg proto.Message, sig *pb.Signature) error {
+	if sig == nil {
+		return errors.New("Signature missing.")
+	}
+
+	raw, err := eca.readCertificateByKeyUsage(id, x509.KeyUsageDigitalSignature)
+	if err != nil {
+		return err
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := eca.isRevoked(cert.SerialNumber)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		ecaLogger.Debugf("ECA.verifySignature: enrollment certificate of %s is revoked", id)
+		return errors.New("Enrollment certificate revoked.")
+	}
+
+	r, s := big.NewInt(0), big.NewInt(0)
+	r.UnmarshalText(sig.R)
+	s.UnmarshalText(sig.S)
+
+	hash := primitives.NewHash()
+	raw, _ = proto.Marshal(msg)
+	hash.Write(raw)
+	if ecdsa.Verify(cert.PublicKey.(*ecdsa.PublicKey), hash.Sum(nil), r, s) == false {
+		return errors.New("Signature verification failed.")
+	}
+
+	return nil
+}
+
+// revokeCertificatePair revokes the enrollment certificate pair of member
+// 'id' issued at 'timestamp'.
+//
+func (eca *ECA) revokeCertificatePair(id string, timestamp int64) error {
+	serials, err := eca.readCertificatePairSerials(id, timestamp)
+	if err != nil {
+		return err
+	}
+	if len(serials) == 0 {
+		return errors.New("No certificates for the given identity were found.")
+	}
+
+	return eca.revokeCertificates(id, serials...)
+}
+
+func (eca *ECA) readCertificatePairSerials(id string, timestamp int64) ([]*big.Int, error) {
+	rows, err := eca.readCertificates(id, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var serials []*big.Int
+	for rows.Next() {
+		var raw, kdfKey []byte
+		if err = rows.Scan(&raw, &kdfKey); err != nil {
+			return nil, err
+		}
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return nil, err
+		}
+		serials = append(serials, cert.SerialNumber)
+	}
+
+	return serials, rows.Err()
+}
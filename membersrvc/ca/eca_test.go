@@ -57,6 +57,8 @@ var (
 		registrarRoles: []string{"client"}}
 	testPeer = User{enrollID: "testPeer", role: 2, affiliation: "institution_a",
 		registrarRoles: []string{"peer"}}
+	testRevoked1 = User{enrollID: "testRevoked1", role: 1, affiliation: "institution_a"}
+	testRevoked2 = User{enrollID: "testRevoked2", role: 1, affiliation: "institution_a"}
 )
 
 //helper function for multiple tests
@@ -541,13 +543,89 @@ func TestCreateCertificatePairBadToken(t *testing.T) {
 	}
 }
 
+//helper function signing ECA requests on behalf of user
+func signRequest(user User, req proto.Message) (*pb.Signature, error) {
+	hash := primitives.NewHash()
+	raw, _ := proto.Marshal(req)
+	hash.Write(raw)
+
+	r, s, err := ecdsa.Sign(rand.Reader, user.enrollPrivKey, hash.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	R, _ := r.MarshalText()
+	S, _ := s.MarshalText()
+
+	return &pb.Signature{Type: pb.CryptoType_ECDSA, R: R, S: S}, nil
+}
+
+//helper function registering and enrolling user, returning its signing certificate
+func enrollRevocationUser(user *User) (*x509.Certificate, error) {
+	if err := registerUser(testAdmin, user); err != nil {
+		return nil, err
+	}
+	if err := enrollUser(user); err != nil {
+		return nil, err
+	}
+
+	raw, err := eca.readCertificateByKeyUsage(user.enrollID, x509.KeyUsageDigitalSignature)
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(raw)
+}
+
 func TestRevokeCertificatePair(t *testing.T) {
 
 	ecap := &ECAP{eca}
 
-	_, err := ecap.RevokeCertificatePair(context.Background(), &pb.ECertRevokeReq{})
-	if err.Error() != "ECAP:RevokeCertificate method not (yet) implemented" {
-		t.Fatalf("Expected error was not returned: [%s]", err.Error())
+	cert, err := enrollRevocationUser(&testRevoked1)
+	if err != nil {
+		t.Fatalf("Failed to enroll testRevoked1: [%s]", err.Error())
+	}
+
+	req := &pb.ECertRevokeReq{Id: &pb.Identity{Id: testRevoked1.enrollID}, Cert: &pb.Cert{Cert: cert.Raw}}
+	_, err = ecap.RevokeCertificatePair(context.Background(), req)
+	if err == nil {
+		t.Fatal("Unsigned revocation requests should be refused")
+	}
+
+	//testUser can't revoke testRevoked1's certificates
+	req = &pb.ECertRevokeReq{Id: &pb.Identity{Id: testUser.enrollID}, Cert: &pb.Cert{Cert: cert.Raw}}
+	if req.Sig, err = signRequest(testUser, req); err != nil {
+		t.Fatalf("Failed (ECDSA) signing [%s]", err.Error())
+	}
+	_, err = ecap.RevokeCertificatePair(context.Background(), req)
+	if err == nil {
+		t.Fatal("Users should only be able to revoke their own certificates")
+	}
+
+	req = &pb.ECertRevokeReq{Id: &pb.Identity{Id: testRevoked1.enrollID}, Cert: &pb.Cert{Cert: cert.Raw}}
+	if req.Sig, err = signRequest(testRevoked1, req); err != nil {
+		t.Fatalf("Failed (ECDSA) signing [%s]", err.Error())
+	}
+	status, err := ecap.RevokeCertificatePair(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to revoke certificate pair: [%s]", err.Error())
+	}
+	if status.Status != pb.CAStatus_OK {
+		t.Fatalf("Expected status OK, got %s", status.Status)
+	}
+
+	revoked, err := eca.isRevoked(cert.SerialNumber)
+	if err != nil || !revoked {
+		t.Fatalf("Expected the certificate to be revoked, got %t [%v]", revoked, err)
+	}
+
+	//the revoked enrollment certificate can no longer be used to sign requests
+	req.Sig = nil
+	if req.Sig, err = signRequest(testRevoked1, req); err != nil {
+		t.Fatalf("Failed (ECDSA) signing [%s]", err.Error())
+	}
+	_, err = ecap.RevokeCertificatePair(context.Background(), req)
+	if err == nil || err.Error() != "Enrollment certificate revoked." {
+		t.Fatalf("Expected error was not returned: [%v]", err)
 	}
 }
 
@@ -555,17 +633,105 @@ func TestRevokeCertificate(t *testing.T) {
 
 	ecaa := &ECAA{eca}
 
-	_, err := ecaa.RevokeCertificate(context.Background(), &pb.ECertRevokeReq{})
-	if err.Error() != "ECAA:RevokeCertificate method not (yet) implemented" {
-		t.Fatalf("Expected error was not returned: [%s]", err.Error())
+	cert, err := enrollRevocationUser(&testRevoked2)
+	if err != nil {
+		t.Fatalf("Failed to enroll testRevoked2: [%s]", err.Error())
+	}
+
+	//testUser is not a registrar
+	req := &pb.ECertRevokeReq{Id: &pb.Identity{Id: testUser.enrollID}, Cert: &pb.Cert{Cert: cert.Raw}}
+	if req.Sig, err = signRequest(testUser, req); err != nil {
+		t.Fatalf("Failed (ECDSA) signing [%s]", err.Error())
+	}
+	_, err = ecaa.RevokeCertificate(context.Background(), req)
+	if err == nil {
+		t.Fatal("Only registrars should be able to revoke certificates of other members")
+	}
+
+	//testClient1 is a registrar for clients
+	req = &pb.ECertRevokeReq{Id: &pb.Identity{Id: testClient1.enrollID}, Cert: &pb.Cert{Cert: cert.Raw}}
+	if req.Sig, err = signRequest(testClient1, req); err != nil {
+		t.Fatalf("Failed (ECDSA) signing [%s]", err.Error())
+	}
+	_, err = ecaa.RevokeCertificate(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to revoke certificate: [%s]", err.Error())
+	}
+
+	revoked, err := eca.isRevoked(cert.SerialNumber)
+	if err != nil || !revoked {
+		t.Fatalf("Expected the certificate to be revoked, got %t [%v]", revoked, err)
 	}
 }
 
 func TestPublishCRL(t *testing.T) {
 	ecaa := &ECAA{eca}
+	ecap := &ECAP{eca}
+
+	req := &pb.ECertCRLReq{Id: &pb.Identity{Id: testUser.enrollID}}
+	var err error
+	if req.Sig, err = signRequest(testUser, req); err != nil {
+		t.Fatalf("Failed (ECDSA) signing [%s]", err.Error())
+	}
+	_, err = ecaa.PublishCRL(context.Background(), req)
+	if err == nil {
+		t.Fatal("Only registrars should be able to publish CRLs")
+	}
+
+	req = &pb.ECertCRLReq{Id: &pb.Identity{Id: testAdmin.enrollID}}
+	if req.Sig, err = signRequest(testAdmin, req); err != nil {
+		t.Fatalf("Failed (ECDSA) signing [%s]", err.Error())
+	}
+	_, err = ecaa.PublishCRL(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to publish CRL: [%s]", err.Error())
+	}
 
-	_, err := ecaa.PublishCRL(context.Background(), &pb.ECertCRLReq{})
-	if err.Error() != "ECAA:PublishCRL method not (yet) implemented" {
-		t.Fatalf("Expected error was not returned: [%s]", err.Error())
+	resp, err := ecap.ReadCRL(context.Background(), &pb.Empty{})
+	if err != nil {
+		t.Fatalf("Failed to read CRL: [%s]", err.Error())
+	}
+	crl, err := x509.ParseCRL(resp.Crl)
+	if err != nil {
+		t.Fatalf("Failed to parse CRL: [%s]", err.Error())
+	}
+	if err = eca.cert.CheckCRLSignature(crl); err != nil {
+		t.Fatalf("Failed to verify CRL signature: [%s]", err.Error())
+	}
+
+	//both certificate pairs revoked in the previous tests are listed
+	if n := len(crl.TBSCertList.RevokedCertificates); n != 4 {
+		t.Fatalf("Expected 4 revoked certificates, got %d", n)
+	}
+}
+
+func TestReadCRLPastNextUpdate(t *testing.T) {
+	past := time.Now().Add(-2 * time.Hour)
+	expired, err := eca.cert.CreateCRL(rand.Reader, eca.priv, nil, past, past.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mutex.Lock()
+	eca.crl = expired
+	mutex.Unlock()
+
+	raw, err := eca.readCRL()
+	if err != nil {
+		t.Fatalf("Failed to read CRL: [%s]", err.Error())
+	}
+	crl, err := x509.ParseCRL(raw)
+	if err != nil {
+		t.Fatalf("Failed to parse CRL: [%s]", err.Error())
+	}
+	if crl.HasExpired(time.Now()) {
+		t.Fatalf("Expected a new CRL to be published, got one which expired at %s", crl.TBSCertList.NextUpdate)
+	}
+
+	again, err := eca.readCRL()
+	if err != nil {
+		t.Fatalf("Failed to read CRL: [%s]", err.Error())
+	}
+	if string(again) != string(raw) {
+		t.Fatal("Expected the CRL to be published only once")
 	}
 }
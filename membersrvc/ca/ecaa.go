@@ -168,18 +168,63 @@ func (ecaa *ECAA) ReadUserSet(ctx context.Context, in *pb.ReadUserSetReq) (*pb.U
 	return &pb.UserSet{Users: users}, err
 }
 
-// RevokeCertificate revokes a certificate from the ECA.  Not yet implemented.
+// RevokeCertificate revokes the enrollment certificate pair the given certificate belongs to.
+// The requester must be a registrar for the role of the certificate owner.
 //
-func (ecaa *ECAA) RevokeCertificate(context.Context, *pb.ECertRevokeReq) (*pb.CAStatus, error) {
+func (ecaa *ECAA) RevokeCertificate(ctx context.Context, in *pb.ECertRevokeReq) (*pb.CAStatus, error) {
 	ecaaLogger.Debug("gRPC ECAA:RevokeCertificate")
 
-	return nil, errors.New("ECAA:RevokeCertificate method not (yet) implemented")
+	if in.Id == nil || in.Cert == nil {
+		return nil, errors.New("Identity and certificate to revoke are required.")
+	}
+
+	admin := in.Id.Id
+	sig := in.Sig
+	in.Sig = nil
+	if err := ecaa.eca.verifySignature(admin, in, sig); err != nil {
+		return nil, err
+	}
+
+	owner, timestamp, err := ecaa.eca.readCertificateOwner(in.Cert.Cert)
+	if err != nil {
+		return nil, errors.New("Certificate was not issued by this ECA.")
+	}
+	if owner != admin {
+		if err = ecaa.eca.canRevoke(admin, ecaa.eca.readRole(owner)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = ecaa.eca.revokeCertificatePair(owner, timestamp); err != nil {
+		return nil, err
+	}
+
+	return &pb.CAStatus{Status: pb.CAStatus_OK}, nil
 }
 
-// PublishCRL requests the creation of a certificate revocation list from the ECA.  Not yet implemented.
+// PublishCRL requests the creation of a certificate revocation list from the ECA.
+// The requester must be a registrar.
 //
-func (ecaa *ECAA) PublishCRL(context.Context, *pb.ECertCRLReq) (*pb.CAStatus, error) {
+func (ecaa *ECAA) PublishCRL(ctx context.Context, in *pb.ECertCRLReq) (*pb.CAStatus, error) {
 	ecaaLogger.Debug("gRPC ECAA:CreateCRL")
 
-	return nil, errors.New("ECAA:PublishCRL method not (yet) implemented")
+	if in.Id == nil {
+		return nil, errors.New("Identity is required.")
+	}
+
+	admin := in.Id.Id
+	sig := in.Sig
+	in.Sig = nil
+	if err := ecaa.eca.verifySignature(admin, in, sig); err != nil {
+		return nil, err
+	}
+	if err := ecaa.eca.canRevoke(admin, 0); err != nil {
+		return nil, err
+	}
+
+	if _, err := ecaa.eca.publishCRL(); err != nil {
+		return nil, err
+	}
+
+	return &pb.CAStatus{Status: pb.CAStatus_OK}, nil
 }
@@ -33,6 +33,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/core/crypto/primitives"
+	"github.com/hyperledger/fabric/core/util"
 	pb "github.com/hyperledger/fabric/membersrvc/protos"
 	"github.com/op/go-logging"
 	"github.com/spf13/viper"
@@ -188,7 +189,7 @@ func (ecap *ECAP) CreateCertificatePair(ctx context.Context, in *pb.ECertCreateR
 		// create new certificate pair
 		ts := time.Now().Add(-1 * time.Minute).UnixNano()
 
-		spec := NewDefaultCertificateSpecWithCommonName(id, enrollID, skey.(*ecdsa.PublicKey), x509.KeyUsageDigitalSignature, pkix.Extension{Id: ECertSubjectRole, Critical: true, Value: []byte(strconv.Itoa(ecap.eca.readRole(id)))})
+		spec := NewDefaultPeriodCertificateSpecWithCommonName(id, enrollID, util.GenerateIntUUID(), skey.(*ecdsa.PublicKey), x509.KeyUsageDigitalSignature, pkix.Extension{Id: ECertSubjectRole, Critical: true, Value: []byte(strconv.Itoa(ecap.eca.readRole(id)))})
 		sraw, err := ecap.eca.createCertificateFromSpec(spec, ts, nil, true)
 		if err != nil {
 			ecapLogger.Error(err)
@@ -197,7 +198,7 @@ func (ecap *ECAP) CreateCertificatePair(ctx context.Context, in *pb.ECertCreateR
 
 		_ = ioutil.WriteFile("/tmp/ecert_"+id, sraw, 0644)
 
-		spec = NewDefaultCertificateSpecWithCommonName(id, enrollID, ekey.(*ecdsa.PublicKey), x509.KeyUsageDataEncipherment, pkix.Extension{Id: ECertSubjectRole, Critical: true, Value: []byte(strconv.Itoa(ecap.eca.readRole(id)))})
+		spec = NewDefaultPeriodCertificateSpecWithCommonName(id, enrollID, util.GenerateIntUUID(), ekey.(*ecdsa.PublicKey), x509.KeyUsageDataEncipherment, pkix.Extension{Id: ECertSubjectRole, Critical: true, Value: []byte(strconv.Itoa(ecap.eca.readRole(id)))})
 		eraw, err := ecap.eca.createCertificateFromSpec(spec, ts, nil, true)
 		if err != nil {
 			mutex.Lock()
@@ -276,10 +277,48 @@ func (ecap *ECAP) ReadCertificateByHash(ctx context.Context, hash *pb.Hash) (*pb
 	return &pb.Cert{Cert: raw}, err
 }
 
-// RevokeCertificatePair revokes a certificate pair from the ECA.  Not yet implemented.
+// RevokeCertificatePair revokes the enrollment certificate pair the given certificate belongs to.
+// Users can only revoke their own certificates.
 //
-func (ecap *ECAP) RevokeCertificatePair(context.Context, *pb.ECertRevokeReq) (*pb.CAStatus, error) {
+func (ecap *ECAP) RevokeCertificatePair(ctx context.Context, in *pb.ECertRevokeReq) (*pb.CAStatus, error) {
 	ecapLogger.Debug("gRPC ECAP:RevokeCertificate")
 
-	return nil, errors.New("ECAP:RevokeCertificate method not (yet) implemented")
+	if in.Id == nil || in.Cert == nil {
+		return nil, errors.New("Identity and certificate to revoke are required.")
+	}
+
+	id := in.Id.Id
+	sig := in.Sig
+	in.Sig = nil
+	if err := ecap.eca.verifySignature(id, in, sig); err != nil {
+		return nil, err
+	}
+
+	owner, timestamp, err := ecap.eca.readCertificateOwner(in.Cert.Cert)
+	if err != nil {
+		return nil, errors.New("Certificate was not issued by this ECA.")
+	}
+	if owner != id {
+		ecapLogger.Debugf("ECAP:RevokeCertificate: %s is not the owner of the certificate", id)
+		return nil, errors.New("Access denied.")
+	}
+
+	if err = ecap.eca.revokeCertificatePair(owner, timestamp); err != nil {
+		return nil, err
+	}
+
+	return &pb.CAStatus{Status: pb.CAStatus_OK}, nil
+}
+
+// ReadCRL reads the last certificate revocation list published by the ECA.
+//
+func (ecap *ECAP) ReadCRL(ctx context.Context, in *pb.Empty) (*pb.CRL, error) {
+	ecapLogger.Debug("gRPC ECAP:ReadCRL")
+
+	raw, err := ecap.eca.readCRL()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CRL{Crl: raw}, nil
 }
@@ -0,0 +1,219 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ca
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"time"
+
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+)
+
+// readCertificateOwner returns the owner and the issuance timestamp of a
+// certificate issued and persisted by this CA.
+//
+func (ca *CA) readCertificateOwner(certRaw []byte) (string, int64, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	hash := primitives.NewHash()
+	hash.Write(certRaw)
+
+	var id string
+	var timestamp int64
+	err := ca.db.QueryRow("SELECT id, timestamp FROM Certificates WHERE hash=?", hash.Sum(nil)).Scan(&id, &timestamp)
+
+	return id, timestamp, err
+}
+
+// revokeCertificates records the revocation of the certificates of member
+// 'id' with the given serial numbers and publishes a new CRL.
+//
+func (ca *CA) revokeCertificates(id string, serials ...*big.Int) error {
+	caLogger.Debugf("Revoking %d certificate(s) of %s.", len(serials), id)
+
+	for _, serial := range serials {
+		// Certificates issued before serial numbers were randomized all
+		// carry serial number 1, revoking one would revoke all of them
+		if serial.Cmp(big.NewInt(1)) == 0 {
+			return errors.New("Certificates with the default serial number cannot be revoked individually.")
+		}
+	}
+
+	mutex.Lock()
+	now := time.Now().UnixNano()
+	for _, serial := range serials {
+		if _, err := ca.db.Exec("INSERT OR IGNORE INTO Revocations (serial, id, timestamp) VALUES (?, ?, ?)", serial.String(), id, now); err != nil {
+			mutex.Unlock()
+			caLogger.Error(err)
+			return err
+		}
+	}
+	mutex.Unlock()
+
+	_, err := ca.publishCRL()
+	return err
+}
+
+// isRevoked returns whether the certificate with the given serial number has been revoked.
+//
+func (ca *CA) isRevoked(serial *big.Int) (bool, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	var count int
+	err := ca.db.QueryRow("SELECT count(row) FROM Revocations WHERE serial=?", serial.String()).Scan(&count)
+
+	return count > 0, err
+}
+
+func (ca *CA) readRevokedCertificates() ([]pkix.RevokedCertificate, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	rows, err := ca.db.Query("SELECT serial, timestamp FROM Revocations ORDER BY row")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revoked []pkix.RevokedCertificate
+	for rows.Next() {
+		var serial string
+		var timestamp int64
+		if err = rows.Scan(&serial, &timestamp); err != nil {
+			return nil, err
+		}
+
+		serialNumber, ok := new(big.Int).SetString(serial, 10)
+		if !ok {
+			caLogger.Warningf("Skipping malformed serial number %s in the revocations table.", serial)
+			continue
+		}
+		revoked = append(revoked, pkix.RevokedCertificate{SerialNumber: serialNumber, RevocationTime: time.Unix(0, timestamp).UTC()})
+	}
+
+	return revoked, rows.Err()
+}
+
+// publishCRL creates a CRL signed by the CA listing every certificate revoked
+// so far, valid for the configured pki.crl.validity. The CRL is stored next to
+// the CA certificate and returned by readCRL from then on.
+//
+func (ca *CA) publishCRL() ([]byte, error) {
+	caLogger.Debug("Publishing CRL.")
+
+	revoked, err := ca.readRevokedCertificates()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	raw, err := ca.cert.CreateCRL(rand.Reader, ca.priv, revoked, now, now.Add(crlValidity))
+	if err != nil {
+		caLogger.Error(err)
+		return nil, err
+	}
+
+	cooked := pem.EncodeToMemory(
+		&pem.Block{
+			Type:  "X509 CRL",
+			Bytes: raw,
+		})
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if err = ioutil.WriteFile(ca.path+"/"+ca.name+".crl", cooked, 0644); err != nil {
+		caLogger.Error(err)
+		return nil, err
+	}
+	ca.crl = raw
+
+	return raw, nil
+}
+
+func (ca *CA) readPublishedCRL() ([]byte, error) {
+	cooked, err := ioutil.ReadFile(ca.path + "/" + ca.name + ".crl")
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(cooked)
+	if block == nil {
+		return nil, errors.New("Invalid CRL file.")
+	}
+	return block.Bytes, nil
+}
+
+// readCRL returns the last published CRL, publishing a new one if the CA
+// never did or if the last one is past its next update.
+//
+func (ca *CA) readCRL() ([]byte, error) {
+	mutex.RLock()
+	crl := ca.crl
+	mutex.RUnlock()
+
+	if crl != nil {
+		parsed, err := x509.ParseDERCRL(crl)
+		if err == nil && !parsed.HasExpired(time.Now()) {
+			return crl, nil
+		}
+		caLogger.Debug("The published CRL is past its next update, publishing a new one.")
+	}
+	return ca.publishCRL()
+}
+
+// canRevoke checks whether member 'admin' may revoke the certificates of a
+// member with role 'ownerRole', which holds if 'admin' is a registrar for that
+// role. If ownerRole is 0, being a registrar at all is enough.
+//
+func (ca *CA) canRevoke(admin string, ownerRole int) error {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	var adminMetadataStr string
+	err := ca.db.QueryRow("SELECT metadata FROM Users WHERE id=?", admin).Scan(&adminMetadataStr)
+	if err != nil {
+		caLogger.Debugf("CA.canRevoke: db error: %s\n", err.Error())
+		return err
+	}
+	if adminMetadataStr == "" {
+		caLogger.Debug("canRevoke: member " + admin + " is not a registrar")
+		return errors.New("member " + admin + " is not a registrar")
+	}
+	adminMetadata, err := newMemberMetadata(adminMetadataStr)
+	if err != nil {
+		return err
+	}
+	if len(adminMetadata.Registrar.Roles) == 0 {
+		caLogger.Debug("canRevoke: member " + admin + " may not register any role")
+		return errors.New("member " + admin + " is not a registrar")
+	}
+	if ownerRole != 0 && !strContained(role2String(ownerRole), adminMetadata.Registrar.Roles) {
+		caLogger.Debugf("CA.canRevoke: role %s can't be revoked by %s\n", role2String(ownerRole), admin)
+		return errors.New("member " + admin + " may not revoke certificates of member of type " + role2String(ownerRole))
+	}
+
+	return nil
+}
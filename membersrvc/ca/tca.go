@@ -25,7 +25,9 @@ import (
 	"encoding/base64"
 	"errors"
 	"io/ioutil"
+	"math/big"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/core/crypto/primitives"
 	"github.com/hyperledger/fabric/flogging"
 	pb "github.com/hyperledger/fabric/membersrvc/protos"
@@ -81,6 +83,9 @@ func initializeTCATables(db *sql.DB) error {
 	if _, err = db.Exec("CREATE TABLE IF NOT EXISTS TCertificateSets (row INTEGER PRIMARY KEY, enrollmentID VARCHAR(64), timestamp INTEGER, nonce BLOB, kdfkey BLOB)"); err != nil {
 		return err
 	}
+	if _, err = db.Exec("CREATE TABLE IF NOT EXISTS TCertificates (row INTEGER PRIMARY KEY, serial VARCHAR(64), enrollmentID VARCHAR(64), timestamp INTEGER)"); err != nil {
+		return err
+	}
 
 	return err
 }
@@ -276,7 +281,7 @@ func (tca *TCA) getCertificateSets(enrollmentID string) ([]*TCertSet, error) {
 	return sets, nil
 }
 
-func (tca *TCA) persistCertificateSet(enrollmentID string, timestamp int64, nonce []byte, kdfKey []byte) error {
+func (tca *TCA) persistCertificateSet(enrollmentID string, timestamp int64, nonce []byte, kdfKey []byte, serials []*big.Int) error {
 	mutex.Lock()
 	defer mutex.Unlock()
 
@@ -284,8 +289,101 @@ func (tca *TCA) persistCertificateSet(enrollmentID string, timestamp int64, nonc
 
 	if _, err = tca.db.Exec("INSERT INTO TCertificateSets (enrollmentID, timestamp, nonce, kdfkey) VALUES (?, ?, ?, ?)", enrollmentID, timestamp, nonce, kdfKey); err != nil {
 		tcaLogger.Error(err)
+		return err
 	}
-	return err
+
+	// TCerts themselves are not persisted, only their serial numbers so
+	// that they can be revoked later on
+	for _, serial := range serials {
+		if _, err = tca.db.Exec("INSERT INTO TCertificates (serial, enrollmentID, timestamp) VALUES (?, ?, ?)", serial.String(), enrollmentID, timestamp); err != nil {
+			tcaLogger.Error(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// readTCertificateOwner returns the member the TCert with the given serial number was issued to.
+func (tca *TCA) readTCertificateOwner(serial *big.Int) (string, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	var enrollmentID string
+	err := tca.db.QueryRow("SELECT enrollmentID FROM TCertificates WHERE serial=?", serial.String()).Scan(&enrollmentID)
+
+	return enrollmentID, err
+}
+
+// readTCertOwner returns the member a TCert issued by this TCA belongs to, along with its serial number.
+func (tca *TCA) readTCertOwner(certRaw []byte) (string, *big.Int, error) {
+	cert, err := x509.ParseCertificate(certRaw)
+	if err != nil {
+		return "", nil, err
+	}
+	if err = cert.CheckSignatureFrom(tca.cert); err != nil {
+		return "", nil, errors.New("certificate was not issued by this TCA")
+	}
+
+	owner, err := tca.readTCertificateOwner(cert.SerialNumber)
+	if err != nil {
+		return "", nil, errors.New("certificate was not issued by this TCA")
+	}
+
+	return owner, cert.SerialNumber, nil
+}
+
+// revokeCertificateSet revokes every TCert of the set issued to enrollmentID at ts, or of the latest set if ts is not set.
+func (tca *TCA) revokeCertificateSet(enrollmentID string, ts *timestamp.Timestamp) error {
+	var seconds int64
+	if ts != nil {
+		seconds = ts.Seconds
+	}
+
+	serials, err := tca.readCertificateSetSerials(enrollmentID, seconds)
+	if err != nil {
+		return err
+	}
+	if len(serials) == 0 {
+		return errors.New("no certificate set for the given identity was found")
+	}
+
+	return tca.revokeCertificates(enrollmentID, serials...)
+}
+
+// readCertificateSetSerials returns the serial numbers of the TCerts of the set issued to
+// enrollmentID at timestamp, or of the latest set issued to enrollmentID if timestamp is 0.
+func (tca *TCA) readCertificateSetSerials(enrollmentID string, timestamp int64) ([]*big.Int, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	var err error
+	if timestamp == 0 {
+		err = tca.db.QueryRow("SELECT timestamp FROM TCertificateSets WHERE enrollmentID=? ORDER BY row DESC LIMIT 1", enrollmentID).Scan(&timestamp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := tca.db.Query("SELECT serial FROM TCertificates WHERE enrollmentID=? AND timestamp=?", enrollmentID, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var serials []*big.Int
+	for rows.Next() {
+		var serial string
+		if err = rows.Scan(&serial); err != nil {
+			return nil, err
+		}
+		serialNumber, ok := new(big.Int).SetString(serial, 10)
+		if !ok {
+			return nil, errors.New("Malformed serial number " + serial + ".")
+		}
+		serials = append(serials, serialNumber)
+	}
+
+	return serials, rows.Err()
 }
 
 func (tca *TCA) retrieveCertificateSets(enrollmentID string) (*sql.Rows, error) {
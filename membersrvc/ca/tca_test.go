@@ -29,6 +29,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/core/chaincode/shim/crypto/attr"
 	"github.com/hyperledger/fabric/core/crypto"
 	"github.com/hyperledger/fabric/core/crypto/primitives"
 	"github.com/hyperledger/fabric/membersrvc/protos"
@@ -119,6 +120,119 @@ func TestCreateCertificateSet(t *testing.T) {
 	}
 }
 
+func TestCreateCertificateSetWithAttributes(t *testing.T) {
+	tca, err := initTCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enrollmentID := "test_user0"
+	enrollmentPassword := "MS9qrN8hFjlE"
+
+	ecertRaw, priv, err := loadECertAndEnrollmentPrivateKey(enrollmentID, enrollmentPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attributes := []*protos.TCertAttribute{{AttributeName: "company"}}
+	certificateSetRequest, err := buildCertificateSetRequestWithAttributes(enrollmentID, priv, 1, attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcap := &TCAP{tca}
+	response, err := tcap.createCertificateSet(context.Background(), ecertRaw, certificateSetRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tcerts := response.GetCerts().Certs
+	if len(tcerts) != 1 {
+		t.Fatalf("Invalid tcert size. Expected: 1, Actual: %v", len(tcerts))
+	}
+
+	// The attributes are read from the TCert as the attribute mutation policies of the transactions sets do
+	value, err := attr.GetValueFrom("company", tcerts[0].Cert)
+	if err != nil {
+		t.Fatalf("Error reading the attribute from the TCert: %v", err)
+	}
+	if string(value) != "ACompany" {
+		t.Fatalf("Wrong attribute value in the TCert. Expected: %s, Actual: %s", "ACompany", value)
+	}
+	if _, err = attr.GetValueFrom("company", ecertRaw); err == nil {
+		t.Fatal("An enrollment certificate should not hold any attribute")
+	}
+}
+
+func TestRevokeTCertificates(t *testing.T) {
+	tca, err := initTCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enrollmentID := "test_user0"
+	enrollmentPassword := "MS9qrN8hFjlE"
+
+	ecertRaw, priv, err := loadECertAndEnrollmentPrivateKey(enrollmentID, enrollmentPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certificateSetRequest, err := buildCertificateSetRequest(enrollmentID, priv, 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcap := &TCAP{tca}
+	response, err := tcap.createCertificateSet(context.Background(), ecertRaw, certificateSetRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tcerts := response.GetCerts().Certs
+
+	owner, serial, err := tca.readTCertOwner(tcerts[0].Cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner != enrollmentID {
+		t.Fatalf("Expected TCert to be owned by %s, got %s", enrollmentID, owner)
+	}
+
+	if err = tca.revokeCertificates(owner, serial); err != nil {
+		t.Fatal(err)
+	}
+
+	// Revoke the rest of the latest set
+	if err = tca.revokeCertificateSet(enrollmentID, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := tca.readCRL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl, err := x509.ParseCRL(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tca.cert.CheckCRLSignature(crl); err != nil {
+		t.Fatal(err)
+	}
+
+	revoked := make(map[string]bool)
+	for _, entry := range crl.TBSCertList.RevokedCertificates {
+		revoked[entry.SerialNumber.String()] = true
+	}
+	for pos, eachTCert := range tcerts {
+		tcert, err := x509.ParseCertificate(eachTCert.Cert)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !revoked[tcert.SerialNumber.String()] {
+			t.Fatalf("TCert[%d] with serial number %v is missing from the CRL", pos, tcert.SerialNumber)
+		}
+	}
+}
+
 func loadECertAndEnrollmentPrivateKey(enrollmentID string, password string) ([]byte, *ecdsa.PrivateKey, error) {
 	cooked, err := ioutil.ReadFile("./test_resources/key_" + enrollmentID + ".dump")
 	if err != nil {
@@ -174,13 +288,16 @@ func initTCA() (*TCA, error) {
 }
 
 func buildCertificateSetRequest(enrollID string, enrollmentPrivKey *ecdsa.PrivateKey, num, numattrs int) (*protos.TCertCreateSetReq, error) {
-	now := time.Now()
-	timestamp := timestamp.Timestamp{Seconds: int64(now.Second()), Nanos: int32(now.Nanosecond())}
-
 	var attributes []*protos.TCertAttribute
 	if numattrs >= 0 { // else negative means use nil from above
 		attributes = make([]*protos.TCertAttribute, numattrs)
 	}
+	return buildCertificateSetRequestWithAttributes(enrollID, enrollmentPrivKey, num, attributes)
+}
+
+func buildCertificateSetRequestWithAttributes(enrollID string, enrollmentPrivKey *ecdsa.PrivateKey, num int, attributes []*protos.TCertAttribute) (*protos.TCertCreateSetReq, error) {
+	now := time.Now()
+	timestamp := timestamp.Timestamp{Seconds: int64(now.Second()), Nanos: int32(now.Nanosecond())}
 
 	req := &protos.TCertCreateSetReq{
 		Ts:         &timestamp,
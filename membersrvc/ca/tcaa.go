@@ -31,23 +31,89 @@ type TCAA struct {
 	tca *TCA
 }
 
-// RevokeCertificate revokes a certificate from the TCA.  Not yet implemented.
-func (tcaa *TCAA) RevokeCertificate(context.Context, *pb.TCertRevokeReq) (*pb.CAStatus, error) {
+// RevokeCertificate revokes a certificate from the TCA. The requester must be a registrar for the
+// role of the certificate owner.
+func (tcaa *TCAA) RevokeCertificate(ctx context.Context, in *pb.TCertRevokeReq) (*pb.CAStatus, error) {
 	tcaaLogger.Debug("grpc TCAA:RevokeCertificate")
 
-	return nil, errors.New("not yet implemented")
+	if in.Id == nil || in.Cert == nil {
+		return nil, errors.New("identity and certificate to revoke are required")
+	}
+
+	admin := in.Id.Id
+	sig := in.Sig
+	in.Sig = nil
+	if err := tcaa.tca.eca.verifySignature(admin, in, sig); err != nil {
+		return nil, err
+	}
+
+	owner, serial, err := tcaa.tca.readTCertOwner(in.Cert.Cert)
+	if err != nil {
+		return nil, err
+	}
+	if owner != admin {
+		if err = tcaa.tca.eca.canRevoke(admin, tcaa.tca.eca.readRole(owner)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tcaa.tca.revokeCertificates(owner, serial); err != nil {
+		return nil, err
+	}
+
+	return &pb.CAStatus{Status: pb.CAStatus_OK}, nil
 }
 
-// RevokeCertificateSet revokes a certificate set from the TCA.  Not yet implemented.
-func (tcaa *TCAA) RevokeCertificateSet(context.Context, *pb.TCertRevokeSetReq) (*pb.CAStatus, error) {
+// RevokeCertificateSet revokes a certificate set of the member named in the request's owner field
+// from the TCA. The requester must be a registrar for the role of the owner.
+func (tcaa *TCAA) RevokeCertificateSet(ctx context.Context, in *pb.TCertRevokeSetReq) (*pb.CAStatus, error) {
 	tcaaLogger.Debug("grpc TCAA:RevokeCertificateSet")
 
-	return nil, errors.New("not yet implemented")
+	if in.Id == nil || in.Owner == nil {
+		return nil, errors.New("identity and owner of the set are required")
+	}
+
+	admin := in.Id.Id
+	owner := in.Owner.Id
+	sig := in.Sig
+	in.Sig = nil
+	if err := tcaa.tca.eca.verifySignature(admin, in, sig); err != nil {
+		return nil, err
+	}
+	if owner != admin {
+		if err := tcaa.tca.eca.canRevoke(admin, tcaa.tca.eca.readRole(owner)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tcaa.tca.revokeCertificateSet(owner, in.Ts); err != nil {
+		return nil, err
+	}
+
+	return &pb.CAStatus{Status: pb.CAStatus_OK}, nil
 }
 
-// PublishCRL requests the creation of a certificate revocation list from the TCA.  Not yet implemented.
-func (tcaa *TCAA) PublishCRL(context.Context, *pb.TCertCRLReq) (*pb.CAStatus, error) {
+// PublishCRL requests the creation of a certificate revocation list from the TCA. The requester must be a registrar.
+func (tcaa *TCAA) PublishCRL(ctx context.Context, in *pb.TCertCRLReq) (*pb.CAStatus, error) {
 	tcaaLogger.Debug("grpc TCAA:CreateCRL")
 
-	return nil, errors.New("not yet implemented")
+	if in.Id == nil {
+		return nil, errors.New("identity is required")
+	}
+
+	admin := in.Id.Id
+	sig := in.Sig
+	in.Sig = nil
+	if err := tcaa.tca.eca.verifySignature(admin, in, sig); err != nil {
+		return nil, err
+	}
+	if err := tcaa.tca.eca.canRevoke(admin, 0); err != nil {
+		return nil, err
+	}
+
+	if _, err := tcaa.tca.publishCRL(); err != nil {
+		return nil, err
+	}
+
+	return &pb.CAStatus{Status: pb.CAStatus_OK}, nil
 }
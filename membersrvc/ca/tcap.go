@@ -180,6 +180,14 @@ func (tcap *TCAP) createCertificateSet(ctx context.Context, raw []byte, in *pb.T
 		return nil, err
 	}
 
+	revoked, err := tcap.tca.eca.isRevoked(cert.SerialNumber)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errors.New("enrollment certificate revoked")
+	}
+
 	pub := cert.PublicKey.(*ecdsa.PublicKey)
 
 	r, s := big.NewInt(0), big.NewInt(0)
@@ -213,6 +221,7 @@ func (tcap *TCAP) createCertificateSet(ctx context.Context, raw []byte, in *pb.T
 
 	// the batch of TCerts
 	var set []*pb.TCert
+	var serials []*big.Int
 
 	for i := 0; i < num; i++ {
 		tcertid := util.GenerateIntUUID()
@@ -259,9 +268,10 @@ func (tcap *TCAP) createCertificateSet(ctx context.Context, raw []byte, in *pb.T
 		}
 
 		set = append(set, &pb.TCert{Cert: raw, Prek0: preK0})
+		serials = append(serials, tcertid)
 	}
 
-	tcap.tca.persistCertificateSet(id, timestamp, nonce, kdfKey)
+	tcap.tca.persistCertificateSet(id, timestamp, nonce, kdfKey, serials)
 
 	return &pb.TCertCreateSetResp{Certs: &pb.CertSet{Ts: in.Ts, Id: in.Id, Key: kdfKey, Certs: set}}, nil
 }
@@ -344,18 +354,72 @@ func (tcap *TCAP) generateExtensions(tcertid *big.Int, tidx []byte, enrollmentCe
 	return extensions, preK0, nil
 }
 
-// RevokeCertificate revokes a certificate from the TCA.  Not yet implemented.
-func (tcap *TCAP) RevokeCertificate(context.Context, *pb.TCertRevokeReq) (*pb.CAStatus, error) {
+// RevokeCertificate revokes a certificate from the TCA. Users can only revoke their own TCerts.
+func (tcap *TCAP) RevokeCertificate(ctx context.Context, in *pb.TCertRevokeReq) (*pb.CAStatus, error) {
 	tcapLogger.Debugf("grpc TCAP:RevokeCertificate")
 
-	return nil, errors.New("not yet implemented")
+	if in.Id == nil || in.Cert == nil {
+		return nil, errors.New("identity and certificate to revoke are required")
+	}
+
+	id := in.Id.Id
+	sig := in.Sig
+	in.Sig = nil
+	if err := tcap.tca.eca.verifySignature(id, in, sig); err != nil {
+		return nil, err
+	}
+
+	owner, serial, err := tcap.tca.readTCertOwner(in.Cert.Cert)
+	if err != nil {
+		return nil, err
+	}
+	if owner != id {
+		tcapLogger.Debugf("TCAP:RevokeCertificate: %s is not the owner of the certificate", id)
+		return nil, errors.New("access denied")
+	}
+
+	if err = tcap.tca.revokeCertificates(owner, serial); err != nil {
+		return nil, err
+	}
+
+	return &pb.CAStatus{Status: pb.CAStatus_OK}, nil
 }
 
-// RevokeCertificateSet revokes a certificate set from the TCA.  Not yet implemented.
-func (tcap *TCAP) RevokeCertificateSet(context.Context, *pb.TCertRevokeSetReq) (*pb.CAStatus, error) {
+// RevokeCertificateSet revokes a certificate set from the TCA. Users can only revoke their own TCerts.
+func (tcap *TCAP) RevokeCertificateSet(ctx context.Context, in *pb.TCertRevokeSetReq) (*pb.CAStatus, error) {
 	tcapLogger.Debugf("grpc TCAP:RevokeCertificateSet")
 
-	return nil, errors.New("not yet implemented")
+	if in.Id == nil {
+		return nil, errors.New("identity is required")
+	}
+	if in.Owner != nil && in.Owner.Id != in.Id.Id {
+		return nil, errors.New("access denied")
+	}
+
+	id := in.Id.Id
+	sig := in.Sig
+	in.Sig = nil
+	if err := tcap.tca.eca.verifySignature(id, in, sig); err != nil {
+		return nil, err
+	}
+
+	if err := tcap.tca.revokeCertificateSet(id, in.Ts); err != nil {
+		return nil, err
+	}
+
+	return &pb.CAStatus{Status: pb.CAStatus_OK}, nil
+}
+
+// ReadCRL reads the last certificate revocation list published by the TCA.
+func (tcap *TCAP) ReadCRL(ctx context.Context, in *pb.Empty) (*pb.CRL, error) {
+	tcapLogger.Debugf("grpc TCAP:ReadCRL")
+
+	raw, err := tcap.tca.readCRL()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CRL{Crl: raw}, nil
 }
 
 func isEnabledAttributesEncryption() bool {
@@ -158,18 +158,99 @@ func (tlscap *TLSCAP) ReadCertificate(ctx context.Context, in *pb.TLSCertReadReq
 	return &pb.Cert{Cert: raw}, nil
 }
 
-// RevokeCertificate revokes a certificate from the TLSCA.  Not yet implemented.
+// RevokeCertificate revokes a certificate from the TLSCA. As TLS certificates are not bound to an
+// enrollment certificate, the request must be signed with the key of the certificate to revoke.
 //
-func (tlscap *TLSCAP) RevokeCertificate(context.Context, *pb.TLSCertRevokeReq) (*pb.CAStatus, error) {
+func (tlscap *TLSCAP) RevokeCertificate(ctx context.Context, in *pb.TLSCertRevokeReq) (*pb.CAStatus, error) {
 	tlscaLogger.Debug("grpc TLSCAP:RevokeCertificate")
 
-	return nil, errors.New("not yet implemented")
+	if in.Id == nil || in.Cert == nil || in.Sig == nil {
+		return nil, errors.New("identity, certificate to revoke and signature are required")
+	}
+
+	cert, err := x509.ParseCertificate(in.Cert.Cert)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("unsupported key type")
+	}
+
+	sig := in.Sig
+	in.Sig = nil
+
+	r, s := big.NewInt(0), big.NewInt(0)
+	r.UnmarshalText(sig.R)
+	s.UnmarshalText(sig.S)
+
+	hash := primitives.NewHash()
+	raw, _ := proto.Marshal(in)
+	hash.Write(raw)
+	if ecdsa.Verify(pub, hash.Sum(nil), r, s) == false {
+		return nil, errors.New("signature does not verify")
+	}
+
+	owner, _, err := tlscap.tlsca.readCertificateOwner(in.Cert.Cert)
+	if err != nil {
+		return nil, errors.New("certificate was not issued by this TLSCA")
+	}
+	if owner != in.Id.Id {
+		return nil, errors.New("access denied")
+	}
+
+	if err = tlscap.tlsca.revokeCertificates(owner, cert.SerialNumber); err != nil {
+		return nil, err
+	}
+
+	return &pb.CAStatus{Status: pb.CAStatus_OK}, nil
 }
 
-// RevokeCertificate revokes a certificate from the TLSCA.  Not yet implemented.
+// ReadCRL reads the last certificate revocation list published by the TLSCA.
 //
-func (tlscaa *TLSCAA) RevokeCertificate(context.Context, *pb.TLSCertRevokeReq) (*pb.CAStatus, error) {
+func (tlscap *TLSCAP) ReadCRL(ctx context.Context, in *pb.Empty) (*pb.CRL, error) {
+	tlscaLogger.Debug("grpc TLSCAP:ReadCRL")
+
+	raw, err := tlscap.tlsca.readCRL()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CRL{Crl: raw}, nil
+}
+
+// RevokeCertificate revokes a certificate from the TLSCA. The requester must be a registrar for
+// the role of the certificate owner.
+//
+func (tlscaa *TLSCAA) RevokeCertificate(ctx context.Context, in *pb.TLSCertRevokeReq) (*pb.CAStatus, error) {
 	tlscaLogger.Debug("grpc TLSCAA:RevokeCertificate")
 
-	return nil, errors.New("not yet implemented")
+	if in.Id == nil || in.Cert == nil {
+		return nil, errors.New("identity and certificate to revoke are required")
+	}
+
+	admin := in.Id.Id
+	sig := in.Sig
+	in.Sig = nil
+	if err := tlscaa.tlsca.eca.verifySignature(admin, in, sig); err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(in.Cert.Cert)
+	if err != nil {
+		return nil, err
+	}
+	owner, _, err := tlscaa.tlsca.readCertificateOwner(in.Cert.Cert)
+	if err != nil {
+		return nil, errors.New("certificate was not issued by this TLSCA")
+	}
+	if err = tlscaa.tlsca.eca.canRevoke(admin, tlscaa.tlsca.eca.readRole(owner)); err != nil {
+		return nil, err
+	}
+
+	if err = tlscaa.tlsca.revokeCertificates(owner, cert.SerialNumber); err != nil {
+		return nil, err
+	}
+
+	return &pb.CAStatus{Status: pb.CAStatus_OK}, nil
 }
@@ -44,6 +44,7 @@ It has these top-level messages:
 	TCert
 	CertSet
 	CertSets
+	CRL
 	CertPair
 	ACAAttrReq
 	ACAAttrResp
@@ -209,7 +210,7 @@ var ACAAttrResp_StatusCode_value = map[string]int32{
 func (x ACAAttrResp_StatusCode) String() string {
 	return proto.EnumName(ACAAttrResp_StatusCode_name, int32(x))
 }
-func (ACAAttrResp_StatusCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{37, 0} }
+func (ACAAttrResp_StatusCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{38, 0} }
 
 type ACAFetchAttrResp_StatusCode int32
 
@@ -233,7 +234,7 @@ func (x ACAFetchAttrResp_StatusCode) String() string {
 	return proto.EnumName(ACAFetchAttrResp_StatusCode_name, int32(x))
 }
 func (ACAFetchAttrResp_StatusCode) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{39, 0}
+	return fileDescriptor0, []int{40, 0}
 }
 
 type FetchAttrsResult_StatusCode int32
@@ -258,7 +259,7 @@ func (x FetchAttrsResult_StatusCode) String() string {
 	return proto.EnumName(FetchAttrsResult_StatusCode_name, int32(x))
 }
 func (FetchAttrsResult_StatusCode) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{40, 0}
+	return fileDescriptor0, []int{41, 0}
 }
 
 // Status codes shared by both CAs.
@@ -819,9 +820,10 @@ func (m *TCertRevokeReq) GetSig() *Signature {
 }
 
 type TCertRevokeSetReq struct {
-	Id  *Identity                  `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
-	Ts  *google_protobuf.Timestamp `protobuf:"bytes,2,opt,name=ts" json:"ts,omitempty"`
-	Sig *Signature                 `protobuf:"bytes,3,opt,name=sig" json:"sig,omitempty"`
+	Id    *Identity                  `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Ts    *google_protobuf.Timestamp `protobuf:"bytes,2,opt,name=ts" json:"ts,omitempty"`
+	Sig   *Signature                 `protobuf:"bytes,3,opt,name=sig" json:"sig,omitempty"`
+	Owner *Identity                  `protobuf:"bytes,4,opt,name=owner" json:"owner,omitempty"`
 }
 
 func (m *TCertRevokeSetReq) Reset()                    { *m = TCertRevokeSetReq{} }
@@ -850,6 +852,13 @@ func (m *TCertRevokeSetReq) GetSig() *Signature {
 	return nil
 }
 
+func (m *TCertRevokeSetReq) GetOwner() *Identity {
+	if m != nil {
+		return m.Owner
+	}
+	return nil
+}
+
 type TCertCRLReq struct {
 	Id  *Identity  `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	Sig *Signature `protobuf:"bytes,2,opt,name=sig" json:"sig,omitempty"`
@@ -1058,6 +1067,17 @@ func (m *CertSets) GetSets() []*CertSet {
 	return nil
 }
 
+// Certificate revocation list published by either CA.
+//
+type CRL struct {
+	Crl []byte `protobuf:"bytes,1,opt,name=crl,proto3" json:"crl,omitempty"`
+}
+
+func (m *CRL) Reset()                    { *m = CRL{} }
+func (m *CRL) String() string            { return proto.CompactTextString(m) }
+func (*CRL) ProtoMessage()               {}
+func (*CRL) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
 type CertPair struct {
 	Sign []byte `protobuf:"bytes,1,opt,name=sign,proto3" json:"sign,omitempty"`
 	Enc  []byte `protobuf:"bytes,2,opt,name=enc,proto3" json:"enc,omitempty"`
@@ -1066,7 +1086,7 @@ type CertPair struct {
 func (m *CertPair) Reset()                    { *m = CertPair{} }
 func (m *CertPair) String() string            { return proto.CompactTextString(m) }
 func (*CertPair) ProtoMessage()               {}
-func (*CertPair) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+func (*CertPair) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
 
 // ACAAttrReq is sent to request an ACert (attributes certificate) to the Attribute Certificate Authority (ACA).
 type ACAAttrReq struct {
@@ -1085,7 +1105,7 @@ type ACAAttrReq struct {
 func (m *ACAAttrReq) Reset()                    { *m = ACAAttrReq{} }
 func (m *ACAAttrReq) String() string            { return proto.CompactTextString(m) }
 func (*ACAAttrReq) ProtoMessage()               {}
-func (*ACAAttrReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+func (*ACAAttrReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
 
 func (m *ACAAttrReq) GetTs() *google_protobuf.Timestamp {
 	if m != nil {
@@ -1135,7 +1155,7 @@ type ACAAttrResp struct {
 func (m *ACAAttrResp) Reset()                    { *m = ACAAttrResp{} }
 func (m *ACAAttrResp) String() string            { return proto.CompactTextString(m) }
 func (*ACAAttrResp) ProtoMessage()               {}
-func (*ACAAttrResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+func (*ACAAttrResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
 
 func (m *ACAAttrResp) GetCert() *Cert {
 	if m != nil {
@@ -1164,7 +1184,7 @@ type ACAFetchAttrReq struct {
 func (m *ACAFetchAttrReq) Reset()                    { *m = ACAFetchAttrReq{} }
 func (m *ACAFetchAttrReq) String() string            { return proto.CompactTextString(m) }
 func (*ACAFetchAttrReq) ProtoMessage()               {}
-func (*ACAFetchAttrReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+func (*ACAFetchAttrReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
 
 func (m *ACAFetchAttrReq) GetTs() *google_protobuf.Timestamp {
 	if m != nil {
@@ -1198,7 +1218,7 @@ type ACAFetchAttrResp struct {
 func (m *ACAFetchAttrResp) Reset()                    { *m = ACAFetchAttrResp{} }
 func (m *ACAFetchAttrResp) String() string            { return proto.CompactTextString(m) }
 func (*ACAFetchAttrResp) ProtoMessage()               {}
-func (*ACAFetchAttrResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+func (*ACAFetchAttrResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
 
 // FetchAttrsResult is returned within the ECertCreateResp indicating the results of the fetch attributes invoked during enroll.
 type FetchAttrsResult struct {
@@ -1211,7 +1231,7 @@ type FetchAttrsResult struct {
 func (m *FetchAttrsResult) Reset()                    { *m = FetchAttrsResult{} }
 func (m *FetchAttrsResult) String() string            { return proto.CompactTextString(m) }
 func (*FetchAttrsResult) ProtoMessage()               {}
-func (*FetchAttrsResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+func (*FetchAttrsResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
 
 // ACAAttribute is an instance of an attribute with the time constraints. Is used to marshal attributes to be stored within the certificate extensions.
 type ACAAttribute struct {
@@ -1228,7 +1248,7 @@ type ACAAttribute struct {
 func (m *ACAAttribute) Reset()                    { *m = ACAAttribute{} }
 func (m *ACAAttribute) String() string            { return proto.CompactTextString(m) }
 func (*ACAAttribute) ProtoMessage()               {}
-func (*ACAAttribute) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+func (*ACAAttribute) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
 
 func (m *ACAAttribute) GetValidFrom() *google_protobuf.Timestamp {
 	if m != nil {
@@ -1280,6 +1300,7 @@ func init() {
 	proto.RegisterType((*TCert)(nil), "protos.TCert")
 	proto.RegisterType((*CertSet)(nil), "protos.CertSet")
 	proto.RegisterType((*CertSets)(nil), "protos.CertSets")
+	proto.RegisterType((*CRL)(nil), "protos.CRL")
 	proto.RegisterType((*CertPair)(nil), "protos.CertPair")
 	proto.RegisterType((*ACAAttrReq)(nil), "protos.ACAAttrReq")
 	proto.RegisterType((*ACAAttrResp)(nil), "protos.ACAAttrResp")
@@ -1311,6 +1332,7 @@ type ECAPClient interface {
 	ReadCertificatePair(ctx context.Context, in *ECertReadReq, opts ...grpc.CallOption) (*CertPair, error)
 	ReadCertificateByHash(ctx context.Context, in *Hash, opts ...grpc.CallOption) (*Cert, error)
 	RevokeCertificatePair(ctx context.Context, in *ECertRevokeReq, opts ...grpc.CallOption) (*CAStatus, error)
+	ReadCRL(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CRL, error)
 }
 
 type eCAPClient struct {
@@ -1366,6 +1388,15 @@ func (c *eCAPClient) RevokeCertificatePair(ctx context.Context, in *ECertRevokeR
 	return out, nil
 }
 
+func (c *eCAPClient) ReadCRL(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CRL, error) {
+	out := new(CRL)
+	err := grpc.Invoke(ctx, "/protos.ECAP/ReadCRL", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for ECAP service
 
 type ECAPServer interface {
@@ -1374,6 +1405,7 @@ type ECAPServer interface {
 	ReadCertificatePair(context.Context, *ECertReadReq) (*CertPair, error)
 	ReadCertificateByHash(context.Context, *Hash) (*Cert, error)
 	RevokeCertificatePair(context.Context, *ECertRevokeReq) (*CAStatus, error)
+	ReadCRL(context.Context, *Empty) (*CRL, error)
 }
 
 func RegisterECAPServer(s *grpc.Server, srv ECAPServer) {
@@ -1470,6 +1502,24 @@ func _ECAP_RevokeCertificatePair_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ECAP_ReadCRL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ECAPServer).ReadCRL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.ECAP/ReadCRL",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ECAPServer).ReadCRL(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _ECAP_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.ECAP",
 	HandlerType: (*ECAPServer)(nil),
@@ -1494,6 +1544,10 @@ var _ECAP_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RevokeCertificatePair",
 			Handler:    _ECAP_RevokeCertificatePair_Handler,
 		},
+		{
+			MethodName: "ReadCRL",
+			Handler:    _ECAP_ReadCRL_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor0,
@@ -1669,6 +1723,7 @@ type TCAPClient interface {
 	CreateCertificateSet(ctx context.Context, in *TCertCreateSetReq, opts ...grpc.CallOption) (*TCertCreateSetResp, error)
 	RevokeCertificate(ctx context.Context, in *TCertRevokeReq, opts ...grpc.CallOption) (*CAStatus, error)
 	RevokeCertificateSet(ctx context.Context, in *TCertRevokeSetReq, opts ...grpc.CallOption) (*CAStatus, error)
+	ReadCRL(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CRL, error)
 }
 
 type tCAPClient struct {
@@ -1715,6 +1770,15 @@ func (c *tCAPClient) RevokeCertificateSet(ctx context.Context, in *TCertRevokeSe
 	return out, nil
 }
 
+func (c *tCAPClient) ReadCRL(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CRL, error) {
+	out := new(CRL)
+	err := grpc.Invoke(ctx, "/protos.TCAP/ReadCRL", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for TCAP service
 
 type TCAPServer interface {
@@ -1722,6 +1786,7 @@ type TCAPServer interface {
 	CreateCertificateSet(context.Context, *TCertCreateSetReq) (*TCertCreateSetResp, error)
 	RevokeCertificate(context.Context, *TCertRevokeReq) (*CAStatus, error)
 	RevokeCertificateSet(context.Context, *TCertRevokeSetReq) (*CAStatus, error)
+	ReadCRL(context.Context, *Empty) (*CRL, error)
 }
 
 func RegisterTCAPServer(s *grpc.Server, srv TCAPServer) {
@@ -1800,6 +1865,24 @@ func _TCAP_RevokeCertificateSet_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TCAP_ReadCRL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TCAPServer).ReadCRL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.TCAP/ReadCRL",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TCAPServer).ReadCRL(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _TCAP_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.TCAP",
 	HandlerType: (*TCAPServer)(nil),
@@ -1820,6 +1903,10 @@ var _TCAP_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RevokeCertificateSet",
 			Handler:    _TCAP_RevokeCertificateSet_Handler,
 		},
+		{
+			MethodName: "ReadCRL",
+			Handler:    _TCAP_ReadCRL_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor0,
@@ -1962,6 +2049,7 @@ type TLSCAPClient interface {
 	CreateCertificate(ctx context.Context, in *TLSCertCreateReq, opts ...grpc.CallOption) (*TLSCertCreateResp, error)
 	ReadCertificate(ctx context.Context, in *TLSCertReadReq, opts ...grpc.CallOption) (*Cert, error)
 	RevokeCertificate(ctx context.Context, in *TLSCertRevokeReq, opts ...grpc.CallOption) (*CAStatus, error)
+	ReadCRL(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CRL, error)
 }
 
 type tLSCAPClient struct {
@@ -2008,6 +2096,15 @@ func (c *tLSCAPClient) RevokeCertificate(ctx context.Context, in *TLSCertRevokeR
 	return out, nil
 }
 
+func (c *tLSCAPClient) ReadCRL(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CRL, error) {
+	out := new(CRL)
+	err := grpc.Invoke(ctx, "/protos.TLSCAP/ReadCRL", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for TLSCAP service
 
 type TLSCAPServer interface {
@@ -2015,6 +2112,7 @@ type TLSCAPServer interface {
 	CreateCertificate(context.Context, *TLSCertCreateReq) (*TLSCertCreateResp, error)
 	ReadCertificate(context.Context, *TLSCertReadReq) (*Cert, error)
 	RevokeCertificate(context.Context, *TLSCertRevokeReq) (*CAStatus, error)
+	ReadCRL(context.Context, *Empty) (*CRL, error)
 }
 
 func RegisterTLSCAPServer(s *grpc.Server, srv TLSCAPServer) {
@@ -2093,6 +2191,24 @@ func _TLSCAP_RevokeCertificate_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TLSCAP_ReadCRL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TLSCAPServer).ReadCRL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.TLSCAP/ReadCRL",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TLSCAPServer).ReadCRL(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _TLSCAP_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.TLSCAP",
 	HandlerType: (*TLSCAPServer)(nil),
@@ -2113,6 +2229,10 @@ var _TLSCAP_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RevokeCertificate",
 			Handler:    _TLSCAP_RevokeCertificate_Handler,
 		},
+		{
+			MethodName: "ReadCRL",
+			Handler:    _TLSCAP_ReadCRL_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor0,
@@ -2315,119 +2435,122 @@ var _ACAP_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("ca.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1818 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xd4, 0x59, 0x4f, 0x6f, 0xdb, 0xc6,
-	0x12, 0x7f, 0x14, 0x25, 0x5b, 0x1a, 0xc9, 0x32, 0xbd, 0x76, 0x62, 0x45, 0x0f, 0x78, 0x09, 0x98,
-	0x97, 0xbc, 0xbc, 0xa0, 0xb5, 0x53, 0xbb, 0x48, 0x8b, 0xa6, 0x41, 0xc1, 0xc8, 0x74, 0xa3, 0x46,
-	0x96, 0x5c, 0x8a, 0x4a, 0x7b, 0x13, 0x64, 0x9b, 0xb6, 0x09, 0xcb, 0x92, 0x4c, 0x52, 0x01, 0x84,
-	0xdc, 0x0b, 0xb4, 0x05, 0x7a, 0xe8, 0x77, 0x28, 0x50, 0xf4, 0x1b, 0x14, 0x28, 0xd0, 0x6b, 0xff,
-	0x24, 0x40, 0x2f, 0x3d, 0xf6, 0x5c, 0xa0, 0xa7, 0x7e, 0x82, 0xa6, 0xb3, 0xcb, 0x25, 0x45, 0x52,
-	0x7f, 0xcc, 0x38, 0x2e, 0xd2, 0x1e, 0x0c, 0x2d, 0x77, 0x66, 0x77, 0x67, 0x7e, 0x33, 0x3b, 0x7f,
-	0xd6, 0x90, 0xde, 0x6d, 0xad, 0xf4, 0xac, 0xae, 0xd3, 0x25, 0x33, 0xec, 0xc7, 0x2e, 0x5e, 0x3e,
-	0xe8, 0x76, 0x0f, 0xda, 0xc6, 0x2a, 0xfb, 0xdc, 0xe9, 0xef, 0xaf, 0x3a, 0xe6, 0xb1, 0x61, 0x3b,
-	0xad, 0xe3, 0x9e, 0xcb, 0x28, 0x1f, 0x42, 0xba, 0xa4, 0xd4, 0x9d, 0x96, 0xd3, 0xb7, 0xc9, 0x3a,
-	0xcc, 0xd8, 0x6c, 0x54, 0x10, 0xae, 0x08, 0x37, 0xf2, 0x6b, 0xff, 0x76, 0x79, 0xec, 0x15, 0x8f,
-	0x63, 0xc5, 0xfd, 0x29, 0x75, 0xf7, 0x0c, 0x8d, 0xb3, 0xca, 0xff, 0x03, 0x18, 0xce, 0x92, 0x19,
-	0x48, 0xd4, 0x1e, 0x48, 0xff, 0x22, 0x0b, 0x30, 0xd7, 0xa8, 0x3e, 0xa8, 0xd6, 0x3e, 0xa8, 0x36,
-	0x55, 0x4d, 0xab, 0x69, 0x92, 0x20, 0xcf, 0x42, 0x4a, 0x3d, 0xee, 0x39, 0x03, 0xb9, 0x08, 0xe9,
-	0xf2, 0x9e, 0xd1, 0x71, 0x4c, 0x67, 0x40, 0xf2, 0x90, 0x30, 0xf7, 0xd8, 0x71, 0x19, 0x0d, 0x47,
-	0xf2, 0x25, 0x48, 0xe9, 0xdd, 0x23, 0xa3, 0x43, 0x24, 0x10, 0x9d, 0xee, 0x11, 0xa3, 0xe4, 0x34,
-	0x3a, 0xc4, 0x65, 0xc9, 0xfb, 0x2d, 0xfb, 0x90, 0x10, 0x48, 0x1e, 0xe2, 0x2f, 0x27, 0xb1, 0xb1,
-	0xac, 0x42, 0x66, 0xbb, 0xbf, 0xd3, 0x36, 0x77, 0x1f, 0x18, 0x03, 0x72, 0x1d, 0x92, 0xce, 0xa0,
-	0x67, 0x70, 0x25, 0x88, 0xaf, 0x84, 0x35, 0xe8, 0x39, 0x5d, 0x1d, 0x29, 0x1a, 0xa3, 0xd3, 0x23,
-	0x8e, 0x8c, 0x41, 0x21, 0xe1, 0x1e, 0x81, 0x43, 0x79, 0x13, 0x60, 0xdb, 0x32, 0x1f, 0xb5, 0x1c,
-	0xe3, 0xc5, 0xf6, 0xa9, 0x41, 0xa6, 0x6e, 0x1e, 0x74, 0x10, 0x15, 0xcb, 0x88, 0xbd, 0x4d, 0x0e,
-	0x04, 0x8b, 0x6f, 0x22, 0x58, 0xf4, 0xcb, 0x2e, 0x88, 0xee, 0x97, 0x2d, 0x9b, 0x90, 0xd1, 0x8c,
-	0x03, 0xd3, 0x76, 0xac, 0x96, 0x45, 0xae, 0xf8, 0x98, 0x65, 0xd7, 0x24, 0x6f, 0x3b, 0x0f, 0x51,
-	0x8a, 0x22, 0x59, 0x82, 0x94, 0xd5, 0x6d, 0x1b, 0x36, 0x6e, 0x27, 0x22, 0xb0, 0xee, 0x07, 0xf9,
-	0x2f, 0xcc, 0xed, 0x19, 0x6d, 0xe3, 0x00, 0xd5, 0xd3, 0x18, 0x55, 0x64, 0xd4, 0xf0, 0xa4, 0xfc,
-	0x54, 0x80, 0x79, 0xf7, 0x2c, 0xc3, 0x6a, 0xd8, 0x86, 0xa5, 0x19, 0x27, 0x31, 0x4e, 0xbc, 0x02,
-	0x49, 0x7a, 0x08, 0x93, 0x3f, 0xbf, 0x96, 0xf3, 0x78, 0xe8, 0x96, 0x1a, 0xa3, 0x20, 0x47, 0xb6,
-	0xb5, 0xbf, 0x6f, 0xb6, 0xcd, 0x96, 0x63, 0x76, 0x3b, 0x85, 0x24, 0x33, 0x79, 0x70, 0x8a, 0xac,
-	0x42, 0xc6, 0xf2, 0x94, 0x2c, 0xa4, 0xd8, 0x61, 0x0b, 0xfe, 0x46, 0x1e, 0x41, 0x1b, 0xf2, 0x90,
-	0xab, 0x20, 0xda, 0xe6, 0x41, 0x61, 0x26, 0xcc, 0xea, 0x23, 0xaf, 0x51, 0xaa, 0xfc, 0x18, 0xf2,
-	0x9a, 0xd1, 0xda, 0xa3, 0xaa, 0xd4, 0x0d, 0x87, 0x6a, 0x23, 0x83, 0x68, 0x19, 0x27, 0x13, 0xd5,
-	0xa1, 0xc4, 0x18, 0xfa, 0xf0, 0xc3, 0xc5, 0xa9, 0x87, 0xbf, 0x07, 0x49, 0x7a, 0xf0, 0x79, 0x00,
-	0x28, 0xbf, 0x0a, 0xb3, 0x5c, 0x09, 0xd4, 0x20, 0xd5, 0xc7, 0x21, 0xbd, 0xa7, 0x22, 0xee, 0xe8,
-	0x73, 0x33, 0x7b, 0xb9, 0x24, 0xf9, 0x77, 0x01, 0xf2, 0x6a, 0xc9, 0xb0, 0x9c, 0x92, 0x65, 0x50,
-	0xe3, 0xa2, 0x52, 0x37, 0x21, 0xe1, 0xd8, 0x5c, 0x8a, 0xe2, 0x8a, 0x1b, 0x19, 0x56, 0xbc, 0xc8,
-	0xb0, 0xa2, 0x7b, 0x91, 0x41, 0x43, 0x2e, 0x2e, 0x71, 0x62, 0x8a, 0xc4, 0x97, 0xdd, 0x1b, 0xea,
-	0x02, 0x30, 0xe7, 0xb1, 0xb0, 0xdb, 0xcb, 0x2e, 0x2c, 0xb9, 0x06, 0x49, 0xc4, 0xc0, 0x35, 0x75,
-	0x00, 0x22, 0xff, 0xa2, 0x6a, 0x8c, 0x4c, 0x81, 0x34, 0x3a, 0xbb, 0x51, 0x83, 0x0f, 0xb9, 0x28,
-	0x35, 0x9e, 0xa9, 0x7f, 0x46, 0xd7, 0x0d, 0xa9, 0x6c, 0xf7, 0xf0, 0xf6, 0xa5, 0x76, 0x71, 0xc6,
-	0x8e, 0x82, 0x4f, 0xd9, 0xb6, 0x5b, 0x26, 0xc2, 0xc5, 0xc8, 0x78, 0x40, 0x6a, 0xf7, 0xb0, 0x65,
-	0x76, 0xb8, 0xca, 0x11, 0x7d, 0x5c, 0x1a, 0x29, 0xc0, 0x6c, 0xef, 0xc8, 0x65, 0x4b, 0xb1, 0xab,
-	0xe9, 0x7d, 0x9e, 0x0e, 0xc6, 0x5b, 0x90, 0xdd, 0x37, 0x9c, 0xdd, 0x43, 0x14, 0xaa, 0xdf, 0x76,
-	0x38, 0x26, 0x05, 0x8f, 0x71, 0x93, 0x92, 0x14, 0xc7, 0xb1, 0x6c, 0x97, 0xae, 0x05, 0x99, 0xe5,
-	0x5b, 0x90, 0x63, 0x6a, 0x51, 0x3f, 0x8e, 0x75, 0x1d, 0xe5, 0x01, 0xb7, 0xbd, 0x66, 0x3c, 0x42,
-	0x11, 0x62, 0x5f, 0x61, 0x0a, 0x05, 0x07, 0x20, 0x17, 0x04, 0x4a, 0x63, 0x94, 0x78, 0x2e, 0xaf,
-	0x43, 0xd6, 0xb5, 0x81, 0x56, 0x89, 0x77, 0x2e, 0xdf, 0x35, 0x31, 0x75, 0xd7, 0x2f, 0xd1, 0x9b,
-	0xf5, 0xbf, 0xd2, 0x9b, 0x51, 0x8a, 0x5e, 0x7f, 0x27, 0xaa, 0x5b, 0xc0, 0x0b, 0x91, 0xea, 0x89,
-	0x9a, 0x9c, 0x2a, 0xea, 0x3a, 0xcc, 0xeb, 0x11, 0x27, 0xf4, 0xa0, 0x15, 0x26, 0x41, 0x2b, 0xff,
-	0x24, 0xc0, 0x42, 0x60, 0x15, 0x8f, 0x54, 0xe7, 0xab, 0x22, 0xe6, 0xa9, 0x4e, 0xff, 0x98, 0xa9,
-	0x38, 0xa7, 0xd1, 0x21, 0xb9, 0x0d, 0xd0, 0x42, 0xa7, 0x33, 0x77, 0xfa, 0x0e, 0xa6, 0x83, 0x24,
-	0x0b, 0x26, 0x17, 0x7d, 0xe7, 0xa5, 0xe2, 0x28, 0x1e, 0x59, 0x0b, 0x70, 0x7a, 0x38, 0xa4, 0xa6,
-	0xe2, 0x70, 0x9b, 0x5b, 0xcc, 0xdf, 0x82, 0x26, 0x20, 0x7f, 0x93, 0x6a, 0xeb, 0xd8, 0xe0, 0x79,
-	0x3f, 0x3c, 0x29, 0xdf, 0x01, 0x12, 0x45, 0x02, 0x21, 0xbc, 0x16, 0xbe, 0xc7, 0xf3, 0x41, 0x0c,
-	0x29, 0x8f, 0x4b, 0x95, 0x7f, 0x11, 0x40, 0xd2, 0xbd, 0xbb, 0x82, 0xf3, 0x36, 0x85, 0xf1, 0x16,
-	0xa4, 0x76, 0x30, 0x69, 0x74, 0x62, 0x20, 0xe9, 0x32, 0x92, 0x57, 0x68, 0x4c, 0xf2, 0xd0, 0x9c,
-	0xc6, 0x4f, 0xd9, 0xbc, 0x84, 0x22, 0xc6, 0x49, 0x28, 0xc9, 0xd3, 0x12, 0xca, 0x74, 0x50, 0x07,
-	0x1c, 0xd4, 0x97, 0x70, 0xb1, 0x3f, 0xf2, 0x5c, 0xd4, 0x3d, 0x9b, 0xbb, 0xe8, 0xe9, 0xc7, 0xbb,
-	0x4e, 0x9c, 0x88, 0xe5, 0xc4, 0x71, 0x23, 0x8c, 0x7e, 0xfe, 0x11, 0xe6, 0x2b, 0xea, 0x39, 0x95,
-	0xfa, 0x3f, 0x23, 0xc6, 0x34, 0xd1, 0x14, 0x61, 0x59, 0xe3, 0x44, 0x19, 0x72, 0x03, 0xd2, 0x56,
-	0xb7, 0xeb, 0x94, 0x26, 0x79, 0x83, 0x4f, 0x95, 0xd7, 0xd0, 0xcf, 0xdc, 0x03, 0xe2, 0x27, 0x9d,
-	0xc7, 0x3e, 0x80, 0x2f, 0xc1, 0x3b, 0xb1, 0x3b, 0xa0, 0x4b, 0x68, 0x77, 0xe0, 0x83, 0x90, 0xe3,
-	0xc1, 0xf5, 0x35, 0x6c, 0x2a, 0x26, 0x11, 0x69, 0xad, 0xdc, 0xb3, 0x8c, 0xa3, 0x5b, 0xbc, 0xf4,
-	0x76, 0x3f, 0xe4, 0xcf, 0x04, 0x98, 0xe5, 0xa1, 0xe5, 0xfc, 0xa3, 0x30, 0xed, 0x16, 0x44, 0xbf,
-	0x5b, 0x60, 0xa5, 0x07, 0x0b, 0x6d, 0x6e, 0x00, 0x9e, 0x0b, 0x05, 0x60, 0x2f, 0xb0, 0xad, 0x62,
-	0x9f, 0xe6, 0xca, 0x43, 0x6f, 0x49, 0xd2, 0x36, 0x1c, 0xaf, 0xfa, 0x1b, 0x09, 0x85, 0x8c, 0x88,
-	0x45, 0x43, 0xda, 0xab, 0x71, 0xa8, 0xde, 0xac, 0x12, 0xe3, 0x7a, 0xb3, 0xb2, 0x4b, 0x72, 0xcb,
-	0x2e, 0xde, 0xb5, 0xe0, 0x50, 0xfe, 0x55, 0x00, 0x50, 0x4a, 0x0a, 0x8d, 0xd7, 0xe7, 0xef, 0xfb,
-	0x58, 0xb2, 0x1a, 0xcc, 0xef, 0xc4, 0x31, 0x76, 0x76, 0x49, 0x67, 0x4e, 0x47, 0xd8, 0x38, 0xd8,
-	0x9e, 0x37, 0x4c, 0x8e, 0x9f, 0x43, 0x1e, 0xf9, 0x0b, 0x11, 0xb2, 0xbe, 0xa6, 0x78, 0x73, 0x6e,
-	0x47, 0x1a, 0xdf, 0xff, 0x78, 0xab, 0x03, 0x4c, 0x63, 0x7a, 0xdf, 0x18, 0xbe, 0x1b, 0x12, 0x4d,
-	0x8c, 0x21, 0xda, 0x27, 0x89, 0x50, 0x3f, 0xbd, 0x08, 0xf3, 0x9b, 0x8d, 0x4a, 0xa5, 0x59, 0x6f,
-	0x94, 0x4a, 0x6a, 0xbd, 0x8e, 0x63, 0x6c, 0xae, 0x2f, 0x02, 0xd9, 0x56, 0x34, 0xbd, 0xac, 0x84,
-	0xe6, 0x05, 0xb2, 0x0c, 0x8b, 0xd5, 0x5a, 0x53, 0xd1, 0x75, 0xad, 0x7c, 0xaf, 0xa1, 0xab, 0xf5,
-	0xe6, 0x66, 0xad, 0x51, 0xdd, 0x90, 0xd2, 0x68, 0xff, 0xfc, 0xa6, 0x52, 0xae, 0x34, 0x34, 0xb5,
-	0xb9, 0x55, 0xae, 0x3e, 0x54, 0x2a, 0xd2, 0x1e, 0xc9, 0xc2, 0x2c, 0x9f, 0x93, 0xa8, 0x53, 0x66,
-	0xef, 0x29, 0x1b, 0x4d, 0x4d, 0x7d, 0xbf, 0xa1, 0xd6, 0x75, 0xe9, 0x3b, 0x81, 0xce, 0x50, 0x72,
-	0xb3, 0x8a, 0x7f, 0x7a, 0x5d, 0xfa, 0x3e, 0x3c, 0x53, 0xde, 0x90, 0x7e, 0x10, 0x50, 0xb8, 0xbc,
-	0x3f, 0xa3, 0x96, 0x54, 0x4d, 0x97, 0x7e, 0xa4, 0x42, 0x10, 0x7f, 0xb2, 0x5e, 0x7e, 0xb7, 0xaa,
-	0xe8, 0xf4, 0x88, 0x27, 0x02, 0x16, 0xcf, 0x8b, 0x3e, 0x61, 0x28, 0xa3, 0xf4, 0xd4, 0xdf, 0x87,
-	0x89, 0xa7, 0x7c, 0x48, 0xc5, 0x7b, 0x2a, 0x14, 0x13, 0x92, 0x20, 0x7f, 0x8e, 0x05, 0x3d, 0x9a,
-	0xc0, 0xaf, 0x8e, 0x9f, 0xd7, 0x2d, 0x7d, 0xa7, 0x4b, 0x4c, 0x76, 0xba, 0xe7, 0xb6, 0xd0, 0xc7,
-	0x98, 0x28, 0xc2, 0x42, 0xa1, 0x07, 0xdd, 0x89, 0x78, 0xd0, 0xd5, 0x80, 0x07, 0x85, 0x38, 0xc7,
-	0xb9, 0x11, 0x5e, 0xc5, 0x2d, 0xdb, 0xcd, 0x4f, 0x19, 0x4d, 0x3c, 0xb6, 0x0f, 0xe4, 0xeb, 0x21,
-	0x27, 0x40, 0x53, 0x71, 0x3b, 0xa3, 0xf1, 0x83, 0x76, 0x63, 0xb2, 0x44, 0x7b, 0x87, 0xc9, 0xb2,
-	0x44, 0x39, 0xcf, 0x57, 0x96, 0x27, 0x02, 0xe4, 0xf8, 0x7d, 0x79, 0x8e, 0x72, 0x0f, 0x1b, 0xb4,
-	0xbc, 0x3f, 0xf1, 0xb0, 0xd5, 0xee, 0x1b, 0x3c, 0x24, 0x45, 0x66, 0xc9, 0x9b, 0x90, 0x79, 0xd4,
-	0x6a, 0x9b, 0x7b, 0x9b, 0x56, 0xf7, 0x98, 0xdb, 0x69, 0x9a, 0xf9, 0x87, 0xcc, 0xe4, 0x75, 0x98,
-	0x65, 0x1f, 0x7a, 0x97, 0x67, 0xd5, 0x69, 0xeb, 0x3c, 0xd6, 0x9b, 0xff, 0x07, 0x18, 0x3e, 0xd1,
-	0x90, 0x0c, 0xa4, 0xd4, 0xd2, 0x46, 0x5d, 0x41, 0xa5, 0x67, 0x41, 0xd4, 0x70, 0x20, 0xd0, 0x01,
-	0x9d, 0x49, 0xdc, 0xdc, 0x82, 0x24, 0xad, 0xe3, 0x48, 0x1a, 0x92, 0xd5, 0x5a, 0x55, 0x45, 0x1e,
-	0x80, 0x99, 0x52, 0xa5, 0xac, 0x56, 0x75, 0x64, 0xc3, 0xd9, 0x6d, 0x55, 0xd5, 0xa4, 0x04, 0x99,
-	0x83, 0x0c, 0x3a, 0x77, 0x79, 0x43, 0xd1, 0x6b, 0x9a, 0x94, 0xa4, 0xe8, 0x29, 0x8d, 0x8d, 0x32,
-	0xfd, 0x48, 0xe3, 0x01, 0xa2, 0x52, 0xa9, 0x48, 0xcf, 0x9e, 0x89, 0x6b, 0x5f, 0x27, 0x20, 0xa9,
-	0x96, 0x94, 0x6d, 0xac, 0x5b, 0x17, 0x68, 0xf6, 0x2d, 0x29, 0xd4, 0x51, 0xcd, 0x7d, 0x73, 0x17,
-	0x33, 0x3d, 0xf1, 0xd3, 0x03, 0x7b, 0x4c, 0x2b, 0x86, 0x7c, 0x9a, 0xdc, 0x87, 0x0b, 0x6e, 0x41,
-	0x10, 0x58, 0xc1, 0x32, 0x80, 0x1f, 0x46, 0xc3, 0x4f, 0x02, 0xc5, 0xe5, 0xb1, 0xf3, 0xe8, 0xd0,
-	0x77, 0x61, 0x91, 0x9d, 0x1d, 0xd9, 0x67, 0x29, 0xc4, 0xcf, 0x6b, 0x83, 0xe2, 0x48, 0x57, 0x4d,
-	0xd6, 0xe1, 0x42, 0x64, 0xf9, 0xbd, 0x01, 0x7b, 0xbd, 0xf3, 0xe5, 0xa5, 0x5f, 0x11, 0xe9, 0x15,
-	0xba, 0x88, 0x56, 0x0e, 0xd3, 0xa5, 0xf7, 0xab, 0x8b, 0xc0, 0xb9, 0xfc, 0x81, 0x72, 0xed, 0x37,
-	0x81, 0x61, 0xa7, 0x60, 0x48, 0xcf, 0x05, 0x5f, 0xb1, 0xc8, 0x72, 0xf8, 0x25, 0xc9, 0x7f, 0xdb,
-	0x2a, 0x86, 0x9b, 0x75, 0x5c, 0x97, 0x0d, 0x3c, 0x17, 0x0d, 0x4f, 0x0e, 0xbf, 0x21, 0x15, 0xe7,
-	0x83, 0x4f, 0x2e, 0x94, 0xf1, 0x2e, 0xb5, 0x55, 0x44, 0xf6, 0xf8, 0x72, 0x23, 0x5e, 0xc0, 0xea,
-	0x40, 0xfb, 0x10, 0xab, 0x5a, 0xb2, 0x18, 0xb6, 0x0a, 0xab, 0x73, 0xc7, 0x28, 0xfb, 0x29, 0x3a,
-	0x8a, 0x7e, 0x36, 0x47, 0xd9, 0x82, 0xa5, 0x11, 0x47, 0xa1, 0x6a, 0x5c, 0x0a, 0xa5, 0xdb, 0x60,
-	0x33, 0x5a, 0x2c, 0x4e, 0x22, 0x31, 0x6f, 0x99, 0xa6, 0xbd, 0x7e, 0x9a, 0xf6, 0x25, 0x58, 0x1a,
-	0x59, 0x3e, 0x2a, 0x4d, 0xb0, 0xef, 0x18, 0x83, 0xc6, 0xb7, 0x02, 0x43, 0x43, 0xf9, 0x3b, 0x08,
-	0x33, 0xc9, 0x9e, 0xfa, 0x54, 0x7b, 0xfe, 0x21, 0xc0, 0x0c, 0xad, 0xa0, 0xcf, 0x78, 0xf5, 0x17,
-	0x46, 0x2c, 0x4a, 0xfc, 0x07, 0xa6, 0x68, 0x67, 0x53, 0xbc, 0x34, 0x81, 0x82, 0xc6, 0x7c, 0x83,
-	0x3e, 0x00, 0x87, 0xee, 0x6e, 0x00, 0xbd, 0x50, 0x53, 0x10, 0x11, 0xe1, 0x9d, 0x71, 0xc0, 0x17,
-	0x46, 0x96, 0x4e, 0xbe, 0xbd, 0x65, 0xae, 0xbf, 0xf2, 0xe2, 0x5b, 0x7d, 0x83, 0xde, 0xa0, 0x9c,
-	0x0d, 0xc9, 0xb7, 0xe9, 0x8a, 0x93, 0x3e, 0x26, 0x04, 0x65, 0x58, 0x63, 0x92, 0x91, 0x92, 0xf0,
-	0xa4, 0xb8, 0x38, 0xa6, 0x4c, 0x24, 0x1b, 0x58, 0xb1, 0x79, 0x79, 0x96, 0xaf, 0x5d, 0x1e, 0x5f,
-	0x0c, 0x9c, 0x14, 0x0b, 0x93, 0xaa, 0x84, 0x1d, 0xf7, 0xff, 0x37, 0xeb, 0x7f, 0x06, 0x00, 0x00,
-	0xff, 0xff, 0x9f, 0x47, 0x1c, 0x48, 0xd2, 0x19, 0x00, 0x00,
+	// 1862 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x59, 0xcd, 0x6f, 0x23, 0x4b,
+	0x11, 0xcf, 0x7c, 0x38, 0xb6, 0xcb, 0x89, 0x3d, 0xe9, 0xec, 0x6e, 0x66, 0x8d, 0xc4, 0x8b, 0x66,
+	0xd9, 0x65, 0x59, 0x41, 0x12, 0x12, 0x14, 0x10, 0x8f, 0x27, 0x34, 0xeb, 0x4c, 0x78, 0x66, 0x1d,
+	0x27, 0xb4, 0xc7, 0x0b, 0x37, 0x6b, 0xe2, 0x74, 0x9c, 0x51, 0x1c, 0xcf, 0x64, 0x7a, 0xbc, 0xc8,
+	0x7a, 0x77, 0x24, 0x2e, 0x1c, 0xf8, 0x0f, 0x38, 0x20, 0x21, 0x0e, 0x1c, 0x38, 0x23, 0x71, 0xe5,
+	0xe3, 0xad, 0xc4, 0x85, 0x23, 0x12, 0x37, 0x24, 0x4e, 0xfc, 0x07, 0x0f, 0x75, 0xcf, 0x87, 0x67,
+	0x26, 0xb6, 0x33, 0x9b, 0x0d, 0x7a, 0xbc, 0x93, 0xa7, 0xab, 0xaa, 0xbb, 0xaa, 0x7e, 0x55, 0x5d,
+	0x5d, 0xdd, 0x86, 0x52, 0xdf, 0xda, 0x72, 0x3d, 0xc7, 0x77, 0xd0, 0x32, 0xff, 0xa1, 0xf5, 0x0f,
+	0x06, 0x8e, 0x33, 0x18, 0x92, 0x6d, 0x3e, 0x3c, 0x1d, 0x9f, 0x6f, 0xfb, 0xf6, 0x15, 0xa1, 0xbe,
+	0x75, 0xe5, 0x06, 0x82, 0xda, 0x05, 0x94, 0x1a, 0x7a, 0xc7, 0xb7, 0xfc, 0x31, 0x45, 0x7b, 0xb0,
+	0x4c, 0xf9, 0x97, 0x2a, 0x6c, 0x0a, 0xcf, 0xab, 0xbb, 0x5f, 0x0a, 0x64, 0xe8, 0x56, 0x24, 0xb1,
+	0x15, 0xfc, 0x34, 0x9c, 0x33, 0x82, 0x43, 0x51, 0xed, 0xab, 0x00, 0x53, 0x2a, 0x5a, 0x06, 0xf1,
+	0xf8, 0x95, 0xb2, 0x84, 0xd6, 0x60, 0xb5, 0xdb, 0x7e, 0xd5, 0x3e, 0xfe, 0x71, 0xbb, 0x67, 0x60,
+	0x7c, 0x8c, 0x15, 0x41, 0x2b, 0x42, 0xc1, 0xb8, 0x72, 0xfd, 0x89, 0x56, 0x87, 0x52, 0xf3, 0x8c,
+	0x8c, 0x7c, 0xdb, 0x9f, 0xa0, 0x2a, 0x88, 0xf6, 0x19, 0x57, 0x57, 0xc6, 0xa2, 0x7d, 0xa6, 0x3d,
+	0x86, 0x82, 0xe9, 0x5c, 0x92, 0x11, 0x52, 0x40, 0xf2, 0x9d, 0x4b, 0xce, 0x59, 0xc1, 0xec, 0x53,
+	0xab, 0x83, 0xfc, 0xb1, 0x45, 0x2f, 0x10, 0x02, 0xf9, 0xc2, 0xa2, 0x17, 0x21, 0x8b, 0x7f, 0x6b,
+	0x06, 0x94, 0x4f, 0xc6, 0xa7, 0x43, 0xbb, 0xff, 0x8a, 0x4c, 0xd0, 0x33, 0x90, 0xfd, 0x89, 0x4b,
+	0x42, 0x27, 0x50, 0xec, 0x84, 0x37, 0x71, 0x7d, 0xc7, 0x9c, 0xb8, 0x04, 0x73, 0x3e, 0x53, 0x71,
+	0x49, 0x26, 0xaa, 0x18, 0xa8, 0xb8, 0x24, 0x13, 0xed, 0x10, 0xe0, 0xc4, 0xb3, 0xdf, 0x58, 0x3e,
+	0x79, 0xbf, 0x75, 0x8e, 0xa1, 0xdc, 0xb1, 0x07, 0x23, 0xcb, 0x1f, 0x7b, 0x24, 0xf7, 0x32, 0x2b,
+	0x20, 0x78, 0xe1, 0x22, 0x82, 0xc7, 0x46, 0x54, 0x95, 0x82, 0x11, 0xd5, 0x6c, 0x28, 0x63, 0x32,
+	0xb0, 0xa9, 0xef, 0x59, 0x1e, 0xda, 0x8c, 0x31, 0xab, 0xec, 0x2a, 0xd1, 0x72, 0x11, 0xa2, 0x0c,
+	0x45, 0xf4, 0x00, 0x0a, 0x9e, 0x33, 0x24, 0x54, 0x15, 0x37, 0xa5, 0xe7, 0x65, 0x1c, 0x0c, 0xd0,
+	0x57, 0x60, 0xf5, 0x8c, 0x0c, 0xc9, 0xc0, 0xf2, 0x09, 0xe6, 0x5c, 0x89, 0x73, 0xd3, 0x44, 0xed,
+	0xad, 0x00, 0xb5, 0x40, 0x17, 0xf1, 0xba, 0x94, 0x78, 0x98, 0x5c, 0xe7, 0xd0, 0xb8, 0x09, 0x32,
+	0x53, 0xc2, 0xed, 0xaf, 0xee, 0xae, 0x44, 0x32, 0x6c, 0x49, 0xcc, 0x39, 0x68, 0x13, 0x2a, 0xd6,
+	0xf9, 0xb9, 0x3d, 0xb4, 0x2d, 0xdf, 0x76, 0x46, 0xaa, 0xcc, 0x43, 0x9e, 0x24, 0xa1, 0x6d, 0x28,
+	0x7b, 0x91, 0x93, 0x6a, 0x81, 0x2b, 0x5b, 0x8b, 0x17, 0x8a, 0x18, 0x78, 0x2a, 0x83, 0x9e, 0x80,
+	0x44, 0xed, 0x81, 0xba, 0x9c, 0x16, 0x8d, 0x91, 0xc7, 0x8c, 0xab, 0x7d, 0x02, 0x55, 0x4c, 0xac,
+	0x33, 0xe6, 0x4a, 0x87, 0xf8, 0xcc, 0x1b, 0x0d, 0x24, 0x8f, 0x5c, 0xcf, 0x75, 0x87, 0x31, 0x73,
+	0xf8, 0x13, 0x2a, 0x97, 0x16, 0x2a, 0xff, 0x21, 0xc8, 0x4c, 0xf1, 0x7d, 0x00, 0xa8, 0x7d, 0x03,
+	0x8a, 0xa1, 0x13, 0x48, 0x83, 0xc2, 0x98, 0x12, 0x8f, 0xed, 0x53, 0xe9, 0x79, 0x65, 0x2a, 0xcd,
+	0xe3, 0x15, 0xb0, 0xb4, 0xff, 0x08, 0x50, 0x35, 0x1a, 0xc4, 0xf3, 0x1b, 0x1e, 0x61, 0xc1, 0x25,
+	0xd7, 0xe8, 0x05, 0x88, 0x3e, 0x0d, 0xad, 0xa8, 0x6f, 0x05, 0x95, 0x61, 0x2b, 0xaa, 0x0c, 0x5b,
+	0x66, 0x54, 0x19, 0xb0, 0xe8, 0xd3, 0xd0, 0x62, 0x71, 0x81, 0xc5, 0x1f, 0x04, 0x3b, 0x34, 0x00,
+	0x60, 0x35, 0x12, 0xe1, 0xbb, 0x97, 0x6f, 0x58, 0xf4, 0x14, 0x64, 0x6a, 0x0f, 0x82, 0x50, 0x27,
+	0x20, 0x8a, 0x37, 0x2a, 0xe6, 0x6c, 0x06, 0x24, 0x19, 0xf5, 0xd5, 0xc2, 0x3c, 0x29, 0xc6, 0xcd,
+	0x17, 0xea, 0xbf, 0x0b, 0x50, 0x4b, 0xb9, 0x4c, 0x5d, 0xf4, 0x0c, 0x0a, 0x7d, 0xe2, 0xc5, 0x6e,
+	0xc7, 0xae, 0x30, 0xb1, 0x13, 0xcb, 0xf6, 0x70, 0xc0, 0x46, 0x4f, 0xa0, 0xd0, 0xbf, 0xb0, 0xec,
+	0x91, 0x2a, 0xce, 0xf2, 0x27, 0xe0, 0x21, 0x15, 0x8a, 0xee, 0x65, 0x20, 0x56, 0xe0, 0x5b, 0x33,
+	0x1a, 0xde, 0x0e, 0xc6, 0x77, 0xa1, 0x72, 0x4e, 0xfc, 0xfe, 0x05, 0x26, 0x74, 0x3c, 0xf4, 0x43,
+	0x4c, 0xd4, 0x48, 0xf0, 0x90, 0xb1, 0x74, 0xdf, 0xf7, 0x68, 0xc0, 0xc7, 0x49, 0x61, 0x6d, 0x07,
+	0x56, 0xb8, 0x5b, 0x2c, 0x8f, 0x73, 0x6d, 0x47, 0x6d, 0x12, 0xc6, 0x1e, 0x93, 0x37, 0xce, 0x25,
+	0xc9, 0xbd, 0x85, 0x19, 0x14, 0x21, 0x00, 0x2b, 0x49, 0xa0, 0x30, 0xe7, 0xe4, 0x4b, 0x79, 0x13,
+	0x2a, 0x41, 0x0c, 0x70, 0x2b, 0x9f, 0xde, 0x70, 0x55, 0x71, 0xe1, 0xaa, 0xbf, 0x11, 0xa0, 0x6a,
+	0xfe, 0x2f, 0xb3, 0xf9, 0x09, 0x48, 0xee, 0xf8, 0x54, 0x95, 0xe6, 0x66, 0xa1, 0x3b, 0x3e, 0x8d,
+	0x4c, 0x95, 0x17, 0x9a, 0xba, 0x07, 0x35, 0x33, 0x93, 0x84, 0x11, 0xb4, 0xc2, 0x3c, 0x68, 0xb5,
+	0xbf, 0x09, 0xb0, 0x96, 0x98, 0x15, 0x56, 0xaa, 0xfb, 0x75, 0x51, 0x01, 0x69, 0x34, 0xbe, 0xe2,
+	0x2e, 0xae, 0x62, 0xf6, 0x89, 0xf6, 0x01, 0x2c, 0xdf, 0xf7, 0xec, 0xd3, 0xb1, 0x4f, 0xa8, 0x2a,
+	0xf3, 0x62, 0xf2, 0x28, 0x4e, 0x5e, 0x66, 0x8e, 0x1e, 0xb1, 0x71, 0x42, 0x32, 0xc2, 0xa1, 0xb0,
+	0x10, 0x87, 0x7d, 0xa8, 0xa6, 0x97, 0x60, 0x07, 0x50, 0xbc, 0x48, 0xdb, 0xba, 0x22, 0xe1, 0xb9,
+	0x9f, 0x26, 0x6a, 0x1f, 0x02, 0xca, 0x22, 0x41, 0x5d, 0xf4, 0x34, 0xbd, 0x8f, 0x6b, 0x49, 0x0c,
+	0x99, 0x4c, 0xc0, 0xd5, 0xfe, 0x21, 0x80, 0x62, 0x46, 0x7b, 0xa5, 0x43, 0x7c, 0xca, 0x60, 0xdc,
+	0x81, 0xc2, 0x29, 0x19, 0xd8, 0xa3, 0x1c, 0x48, 0x06, 0x82, 0xe8, 0xeb, 0xac, 0x26, 0x45, 0x68,
+	0x2e, 0x92, 0x67, 0x62, 0xd1, 0x81, 0x22, 0xe5, 0x39, 0x50, 0xe4, 0xdb, 0x0e, 0x94, 0xc5, 0xa0,
+	0x4e, 0x42, 0x50, 0x3f, 0x87, 0x8d, 0xfd, 0xbb, 0x28, 0x45, 0x03, 0xdd, 0x61, 0x8a, 0xde, 0xae,
+	0x3e, 0x48, 0x62, 0x31, 0x57, 0x12, 0xe7, 0x31, 0x84, 0x95, 0x74, 0xe7, 0xa7, 0x23, 0xe2, 0xa9,
+	0xf2, 0x1c, 0xad, 0x01, 0x9b, 0x55, 0x22, 0xf3, 0xfe, 0x2b, 0xd1, 0x6f, 0x59, 0x86, 0xb5, 0x3a,
+	0x5f, 0x8c, 0x5a, 0xd4, 0x83, 0xb5, 0x8c, 0xad, 0x79, 0xaa, 0x11, 0x7a, 0x0e, 0x25, 0xcf, 0x71,
+	0xfc, 0xc6, 0xbc, 0xac, 0x89, 0xb9, 0xda, 0x2e, 0x54, 0x43, 0x05, 0xf9, 0x0f, 0xa7, 0x4f, 0x62,
+	0x00, 0x3f, 0x87, 0x2c, 0xae, 0x83, 0xcc, 0xa6, 0xb0, 0x5b, 0x44, 0x0c, 0xc2, 0x4a, 0x58, 0x84,
+	0xbf, 0x09, 0x05, 0x73, 0x1e, 0x93, 0xf5, 0xd4, 0xae, 0x47, 0x2e, 0x77, 0xc2, 0x16, 0x3d, 0x18,
+	0x68, 0xbf, 0x10, 0xa0, 0x18, 0x96, 0xa0, 0xfb, 0xaf, 0xd6, 0xec, 0x56, 0x21, 0xc5, 0xb7, 0x0a,
+	0xde, 0xa2, 0xf0, 0x12, 0x18, 0x14, 0xea, 0xd5, 0x54, 0xa1, 0x8e, 0x0a, 0xe0, 0x36, 0x94, 0x42,
+	0x7b, 0xd8, 0x6e, 0x92, 0x29, 0xf1, 0xa3, 0x2e, 0xf1, 0x46, 0xc9, 0xe4, 0x4c, 0x6d, 0x03, 0xa4,
+	0x06, 0x6e, 0x31, 0x75, 0x7d, 0x6f, 0x18, 0xdd, 0xb7, 0xfa, 0xde, 0x50, 0xdb, 0x81, 0x52, 0xd4,
+	0x24, 0x31, 0x40, 0x78, 0x2b, 0x17, 0x02, 0xc2, 0xbe, 0x91, 0x12, 0xf4, 0x6d, 0xe1, 0xb5, 0x87,
+	0x8c, 0xfa, 0xda, 0xbf, 0x04, 0x00, 0xbd, 0xa1, 0xb3, 0x82, 0x7f, 0xff, 0x9b, 0x42, 0x83, 0x02,
+	0xe1, 0x09, 0x29, 0xcd, 0x48, 0x80, 0x80, 0x75, 0xe7, 0xf3, 0x6c, 0x1b, 0xca, 0x34, 0x4a, 0x93,
+	0xf9, 0x05, 0x78, 0x2a, 0xa3, 0xfd, 0x4a, 0x82, 0x4a, 0xec, 0x29, 0x75, 0xd1, 0x7e, 0xe6, 0xe6,
+	0xfc, 0xe5, 0x68, 0x76, 0x42, 0x68, 0xc6, 0xe5, 0x39, 0x47, 0x52, 0xa7, 0x4c, 0x93, 0x72, 0x98,
+	0xf6, 0x33, 0x31, 0x75, 0x21, 0x5f, 0x87, 0xda, 0x61, 0xb7, 0xd5, 0xea, 0x75, 0xba, 0x8d, 0x86,
+	0xd1, 0xe9, 0x1c, 0x76, 0x5b, 0xca, 0x12, 0x7a, 0x04, 0xe8, 0x44, 0xc7, 0x66, 0x53, 0x4f, 0xd1,
+	0x05, 0xb4, 0x01, 0xeb, 0xed, 0xe3, 0x9e, 0x6e, 0x9a, 0xb8, 0xf9, 0xb2, 0x6b, 0x1a, 0x9d, 0xde,
+	0xe1, 0x71, 0xb7, 0x7d, 0xa0, 0x94, 0x10, 0x82, 0xea, 0xa1, 0xde, 0x6c, 0x75, 0xb1, 0xd1, 0x3b,
+	0x6a, 0xb6, 0x5f, 0xeb, 0x2d, 0xe5, 0x0c, 0x55, 0xa0, 0x18, 0xd2, 0x14, 0x96, 0xad, 0x95, 0x97,
+	0xfa, 0x41, 0x0f, 0x1b, 0x3f, 0xea, 0x1a, 0x1d, 0x53, 0xf9, 0x93, 0xc0, 0x28, 0x8c, 0xdd, 0x6b,
+	0x37, 0x5b, 0x3d, 0xb3, 0xa3, 0xfc, 0x39, 0x4d, 0x69, 0x1e, 0x28, 0x7f, 0x11, 0xd0, 0x3a, 0x54,
+	0x63, 0x8a, 0xd1, 0x30, 0xb0, 0xa9, 0xfc, 0x95, 0x19, 0x81, 0x62, 0x62, 0xa7, 0xf9, 0x83, 0xb6,
+	0x6e, 0x32, 0x15, 0x9f, 0x0a, 0x48, 0x85, 0xf5, 0x98, 0x31, 0xb5, 0x51, 0x79, 0x1b, 0xaf, 0xc3,
+	0xcd, 0xd3, 0x7f, 0xc2, 0xcc, 0x7b, 0x2b, 0x68, 0xbf, 0x14, 0xa0, 0xa6, 0x37, 0xf4, 0xb8, 0xb5,
+	0x7e, 0xd7, 0x94, 0x8c, 0x13, 0x4e, 0x9c, 0x9f, 0x70, 0xef, 0x1c, 0x9d, 0x9f, 0x0b, 0xa0, 0xa4,
+	0x8d, 0xa2, 0x2e, 0xfa, 0x30, 0x93, 0x3d, 0x4f, 0x12, 0xd9, 0x93, 0x92, 0x9c, 0x95, 0x42, 0x0a,
+	0x48, 0x47, 0x34, 0x38, 0xb4, 0xca, 0x58, 0xba, 0xa2, 0x03, 0xed, 0x59, 0x2a, 0x01, 0x2a, 0x50,
+	0x0c, 0x63, 0xac, 0x2c, 0xa5, 0x62, 0xc6, 0x6d, 0xc9, 0x5e, 0x3c, 0xe6, 0xdb, 0x92, 0x95, 0xbc,
+	0x5f, 0x5b, 0x3e, 0x15, 0x60, 0x25, 0xdc, 0x2b, 0xef, 0xd0, 0x2b, 0xa2, 0x67, 0x50, 0x8d, 0x09,
+	0xaf, 0xad, 0xe1, 0x98, 0x84, 0xe5, 0x28, 0x43, 0x45, 0xdf, 0x81, 0xf2, 0x1b, 0x6b, 0x68, 0x9f,
+	0x1d, 0x7a, 0xce, 0x95, 0x2a, 0xdd, 0x1a, 0xfe, 0xa9, 0x30, 0xfa, 0x16, 0x14, 0xf9, 0xc0, 0x74,
+	0x54, 0xf9, 0xd6, 0x79, 0x91, 0xe8, 0x8b, 0xaf, 0x01, 0x4c, 0xdf, 0x77, 0x50, 0x19, 0x0a, 0x46,
+	0xe3, 0xa0, 0xa3, 0x2b, 0x4b, 0xa8, 0x08, 0x12, 0xee, 0xe8, 0x8a, 0xc0, 0x3e, 0x18, 0x45, 0x7c,
+	0x71, 0x04, 0x32, 0x6b, 0x02, 0x51, 0x09, 0xe4, 0xf6, 0x71, 0xdb, 0x50, 0x96, 0x10, 0xc0, 0x72,
+	0xa3, 0xd5, 0x34, 0xda, 0xa6, 0x22, 0x30, 0xea, 0x89, 0x61, 0x60, 0x45, 0x44, 0xab, 0x50, 0x7e,
+	0xad, 0xb7, 0x9a, 0x07, 0xba, 0x79, 0x8c, 0x15, 0x99, 0xa1, 0xa7, 0x77, 0x0f, 0x9a, 0x6c, 0x50,
+	0x42, 0x65, 0x90, 0xf4, 0x56, 0x4b, 0xf9, 0xec, 0x33, 0x69, 0xf7, 0x9f, 0x22, 0xc8, 0x46, 0x43,
+	0x3f, 0x41, 0x3b, 0xb0, 0xc6, 0x8e, 0xe4, 0x86, 0xce, 0x12, 0xd5, 0x3e, 0xb7, 0xfb, 0x96, 0x4f,
+	0x50, 0x7c, 0x66, 0xf0, 0x97, 0xb8, 0x7a, 0x2a, 0xa7, 0xd1, 0xc7, 0xf0, 0x30, 0xe8, 0x12, 0x12,
+	0x33, 0x78, 0xf5, 0x8f, 0x4b, 0x68, 0xfa, 0x3d, 0xa1, 0xbe, 0x31, 0x93, 0x4e, 0x5d, 0xf4, 0x11,
+	0xac, 0x73, 0xdd, 0x99, 0x75, 0x1e, 0xa4, 0xe4, 0xc3, 0x86, 0xa1, 0x7e, 0xe3, 0x4a, 0x8e, 0xf6,
+	0xe0, 0x61, 0x66, 0xfa, 0xcb, 0x09, 0x7f, 0xfa, 0x8b, 0xed, 0x65, 0xa3, 0x8c, 0xf5, 0x3a, 0x3c,
+	0x0c, 0xda, 0x89, 0xc5, 0xd6, 0xc7, 0x2d, 0x47, 0x5d, 0xc9, 0xbe, 0x6e, 0xa2, 0xa7, 0x50, 0xe4,
+	0x7a, 0x71, 0x2b, 0x0b, 0x54, 0x25, 0x96, 0xc5, 0xad, 0xdd, 0x7f, 0x0b, 0x1c, 0x62, 0x1d, 0xed,
+	0xc3, 0x4a, 0xf2, 0xa5, 0x0c, 0x6d, 0xa4, 0x5f, 0xab, 0xe2, 0xf7, 0xb3, 0x7a, 0xfa, 0x41, 0x00,
+	0xed, 0x43, 0x25, 0xf1, 0x24, 0x35, 0x35, 0x30, 0xfd, 0x4e, 0x55, 0xaf, 0x25, 0x9f, 0x75, 0x98,
+	0xe0, 0x47, 0xb0, 0x16, 0x98, 0x9f, 0x0c, 0x69, 0x7e, 0xf7, 0xf6, 0x00, 0x78, 0x0f, 0x49, 0x2f,
+	0x98, 0x87, 0xeb, 0xe9, 0xe0, 0xe1, 0xd6, 0xcc, 0x49, 0xbb, 0xbf, 0x17, 0x41, 0x36, 0xef, 0x96,
+	0x4f, 0x47, 0xf0, 0xe0, 0x46, 0x3e, 0x31, 0x37, 0x1e, 0xa7, 0x4e, 0xe4, 0xe4, 0x85, 0xb7, 0x5e,
+	0x9f, 0xc7, 0xa2, 0xee, 0x2d, 0xde, 0x9b, 0xb7, 0x79, 0xdf, 0x80, 0x07, 0x37, 0xa6, 0xdf, 0xb4,
+	0x26, 0x79, 0xb7, 0xb9, 0x7b, 0x86, 0xfc, 0x51, 0xe0, 0xa0, 0xe9, 0xff, 0x17, 0x36, 0xcf, 0x09,
+	0xbb, 0xb9, 0x30, 0xec, 0xbf, 0x16, 0x61, 0x99, 0x35, 0xe9, 0x77, 0x2c, 0x24, 0x6b, 0x37, 0x02,
+	0x8f, 0xe2, 0xb7, 0xae, 0xec, 0xe5, 0xa9, 0xfe, 0x78, 0x0e, 0x87, 0xba, 0xe8, 0xdb, 0x50, 0xcb,
+	0x54, 0x02, 0xf4, 0x28, 0x23, 0x1d, 0x95, 0x91, 0xb4, 0x09, 0xdf, 0x9f, 0x05, 0xbc, 0x7a, 0x63,
+	0xea, 0x7b, 0xd7, 0x82, 0x66, 0x08, 0x93, 0xfe, 0xde, 0x1a, 0x77, 0xff, 0x20, 0x80, 0xac, 0xdf,
+	0x0d, 0xf0, 0xef, 0xb1, 0x19, 0xd7, 0x63, 0x42, 0xa7, 0xfd, 0x2d, 0x45, 0xe8, 0x46, 0x0f, 0x7a,
+	0x5d, 0x5f, 0x9f, 0xd1, 0x97, 0xa2, 0x03, 0xa8, 0xc5, 0x87, 0x7b, 0x38, 0x77, 0x63, 0x76, 0x07,
+	0x72, 0x5d, 0x57, 0xe7, 0xb5, 0x26, 0xa7, 0xc1, 0x3f, 0x4e, 0x7b, 0xff, 0x1d, 0x00, 0x11, 0xdc,
+	0x0f, 0x0c, 0x84, 0x1a, 0x00, 0x00,
 }
@@ -0,0 +1,77 @@
+package muchain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/hyperledger/fabric/core"
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+func cancelCmd() *cobra.Command {
+	muchainCancelMutationCmd.Flags().StringVarP(&signaturesPath, "signatures", "g", "",
+		"The path to a json file with the additional signatures required by the mutation policy of the set.")
+	muchainCancelMutationCmd.Flags().StringSliceVar(&attributes, "attributes", nil,
+		"Sign with a transaction certificate holding the given attributes, as required by an attribute mutation policy.")
+
+	return muchainCancelMutationCmd
+}
+
+var muchainCancelMutationCmd = &cobra.Command{
+	Use:       "cancel 'tx-set-id'",
+	Short:     fmt.Sprintf("Cancel the mutation scheduled on a %s transactions set.", muchainFuncName),
+	Long:      fmt.Sprintf(`Cancel the mutation scheduled on a %s transactions set. The cancellation is subject to the mutation policy of the set.`, muchainFuncName),
+	ValidArgs: []string{"1"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return muchainCancelMutation(cmd, args)
+	},
+}
+
+func muchainCancelMutation(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("Exactly one argument must be provided. The tx set id of the scheduled mutation to cancel.")
+	}
+
+	mutantSpec := &pb.MutantSpec{
+		TxSetID:         args[0],
+		CancelScheduled: true,
+	}
+
+	if core.SecurityEnabled() {
+		mutantSpec.SecureContext = fabricUsr
+		mutantSpec.Attributes = attributes
+	}
+
+	if cmd.Flag("signatures").Changed {
+		signatures, err := readMutationSignatures(signaturesPath)
+		if err != nil {
+			return err
+		}
+		mutantSpec.Signatures = signatures
+	}
+
+	devopsClient, err := common.GetDevopsClient(cmd)
+	if err != nil {
+		return fmt.Errorf("Error building the devops client: %s", err)
+	}
+
+	resp, err := devopsClient.Mutate(context.Background(), mutantSpec)
+	if err != nil {
+		return fmt.Errorf("Error cancelling the scheduled mutation: %s\n", err)
+	}
+
+	if resp.Msg != nil {
+		logger.Info("Tx id of the mutant transaction:", string(resp.Msg))
+	}
+
+	if resp.Status != pb.Response_SUCCESS {
+		return fmt.Errorf("Unable to cancel the scheduled mutation. Status: %#v", resp.Status)
+	}
+	logger.Infof("Successfully cancelled the scheduled mutation.")
+
+	return nil
+}
@@ -7,10 +7,8 @@ import (
 	"io/ioutil"
 	pb "github.com/hyperledger/fabric/protos"
 	"github.com/hyperledger/fabric/peer/common"
-	"github.com/hyperledger/fabric/core/crypto/primitives"
 	"github.com/hyperledger/fabric/peer/util"
-	"github.com/hyperledger/fabric/core/crypto/primitives/ecies"
-	"crypto/ecdsa"
+	"github.com/hyperledger/fabric/core/crypto/txset"
 )
 
 func parseFile(path string) (*pb.TxSetInput, error) {
@@ -32,26 +30,11 @@ func createTxSpecArray(simpSpecArr []*pb.TxSetInput_SimplifiedSpec, defIndex uin
 	var defSpec *pb.TxSpec
 	var txSetSpecArr = make([][]byte, len(simpSpecArr))
 	for i, simpSpec := range simpSpecArr {
-		var txSpec = &pb.TxSpec{}
-		txSpec.Action = simpSpec.Action
-		spec := &pb.ChaincodeSpec{
-			Type:        simpSpec.Lang,
-			ChaincodeID: simpSpec.ChaincodeID,
-			CtorMsg:     simpSpec.InputArgs,
-		}
-		spec, err := common.SetSecurityParams(fabricUsr, spec)
+		spec, err := common.SetSecurityParams(fabricUsr, simpSpec.ChaincodeSpec())
 		if err != nil {
 			return txSetSpecArr, defSpec, fmt.Errorf("Unable to set security for one of the transactions of the set: %s", err)
 		}
-		if simpSpec.Action == pb.ChaincodeAction_CHAINCODE_DEPLOY {
-			txSpec.Spec = &pb.TxSpec_CodeSpec{CodeSpec: spec}
-		} else {
-			invocationSpec := &pb.ChaincodeInvocationSpec{ChaincodeSpec: spec}
-			if simpSpec.CustomIDGen != "" {
-				invocationSpec.IdGenerationAlg = simpSpec.CustomIDGen
-			}
-			txSpec.Spec = &pb.TxSpec_InvocationSpec{InvocationSpec: invocationSpec}
-		}
+		txSpec := simpSpec.TxSpec(spec)
 		if uint64(i) == defIndex {
 			defSpec = txSpec
 		}
@@ -71,26 +54,10 @@ func encryptNonce(nonce []byte) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Error when reading the chain public key: %s\n", err)
 	}
-	eciesSPI := ecies.NewSPI()
-	pubTemp, err := primitives.PEMtoPublicKey(pem, nil)
-	if err != nil {
-		return nil, fmt.Errorf("Error when reading the chain public key: %s\n", err)
-	}
-	pubKey, err := eciesSPI.NewPublicKey(nil, pubTemp.(*ecdsa.PublicKey))
-	if err != nil {
-		return nil, fmt.Errorf("Error when converting the chain public key: %s\n", err)
-	}
-	cipher, err := eciesSPI.NewAsymmetricCipherFromPublicKey(pubKey)
-	if err != nil {
-		logger.Errorf("Failed creating new encryption scheme: [%s]", err)
-		return nil, err
-	}
-
-	nonce, err = cipher.Process(nonce)
+	nonce, err = txset.EncryptNonce(pem, nonce)
 	if err != nil {
 		logger.Errorf("Failed encrypting the nonce: [%s]", err)
 		return nil, err
 	}
 	return nonce, nil
 }
-
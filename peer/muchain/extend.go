@@ -93,7 +93,7 @@ func muchainExtendTxSet(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	_, encryptedSpecs, err := txset.EncryptTxSetSpecificationStartingFrom(txSpecs, seed, txSetState.TxNumber)
+	_, encryptedSpecs, err := txset.EncryptTxSetSpecificationStartingFrom(txSetState.ConfidentialityProtocolVersion, txSpecs, seed, txSetState.TxNumber)
 	if err != nil {
 		return err
 	}
@@ -110,6 +110,7 @@ func muchainExtendTxSet(cmd *cobra.Command, args []string) error {
 		TxSpecs: encryptedSpecs,
 		ExtSetID: setToExtend,
 		ConfidentialityLevel: pb.ConfidentialityLevel_CONFIDENTIAL,
+		ConfidentialityProtocolVersion: txSetState.ConfidentialityProtocolVersion,
 		Metadata: seed, //TODO: In the shared scenario put only a share of the key and send a different one to every peer
 	}
 
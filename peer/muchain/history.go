@@ -0,0 +1,72 @@
+package muchain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/cobra"
+
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos"
+	"golang.org/x/net/context"
+)
+
+func queryHistory() *cobra.Command {
+	muchainQueryTxSetHistoryCmd.Flags().Uint64VarP(&historyBlock, "block", "b", 0,
+		"The block at which the state of the set is rebuilt. The latest block is used if not given.")
+
+	return muchainQueryTxSetHistoryCmd
+}
+
+var historyBlock uint64
+
+var muchainQueryTxSetHistoryCmd = &cobra.Command{
+	Use:       "query-history 'tx-set-id'",
+	Short:     "Queries the state of the transactions set given as argument at a given block.",
+	Long:      `Queries the state of the transactions set given as argument at a given block, and the index changes caused by mutations up to that block.`,
+	ValidArgs: []string{"1"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return muchainQueryTxSetHistory(cmd, args)
+	},
+}
+
+func muchainQueryTxSetHistory(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("Exactly one argument must be provided. The tx set id of the tx set state to query.")
+	}
+
+	historySpec := &pb.TxSetHistorySpec{
+		TxSetID: args[0],
+		BlockNr: historyBlock,
+	}
+
+	devopsClient, err := common.GetDevopsClient(cmd)
+	if err != nil {
+		return fmt.Errorf("Error building the txSet: %s", err)
+	}
+
+	resp, err := devopsClient.QueryTxSetStateHistory(context.Background(), historySpec)
+	if err != nil {
+		return fmt.Errorf("Error querying the tx set state history: %s\n", err)
+	}
+
+	if resp.Status != pb.Response_SUCCESS {
+		return fmt.Errorf("Unable to query the tx set state history: %s", string(resp.Msg))
+	}
+
+	history := &pb.TxSetStateHistory{}
+	if err = proto.Unmarshal(resp.Msg, history); err != nil {
+		return errors.New("Query successfull, but unable to unmarshal the response.")
+	}
+
+	logger.Infof("Successfully queried state at block %d. Result:", history.BlockNr)
+	fmt.Println(history.State.ToString())
+	fmt.Println("Index changes caused by mutations:")
+	fmt.Println("Block\t\t\tOld Index\t\tNew Index\t\tMutant Tx ID")
+	for _, transition := range history.Transitions {
+		fmt.Print(transition.BlockNr, "\t\t\t", transition.OldIndex, "\t\t\t", transition.NewIndex, "\t\t\t", transition.MutantTxid, "\n")
+	}
+
+	return nil
+}
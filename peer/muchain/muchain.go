@@ -23,7 +23,10 @@ func Cmd() *cobra.Command {
 
 	muchainCmd.AddCommand(newSetCmd())
 	muchainCmd.AddCommand(mutateCmd())
+	muchainCmd.AddCommand(cancelCmd())
+	muchainCmd.AddCommand(simulateCmd())
 	muchainCmd.AddCommand(queryState())
+	muchainCmd.AddCommand(queryHistory())
 	muchainCmd.AddCommand(extendSetCmd())
 
 	return muchainCmd
@@ -1,11 +1,18 @@
 package muchain
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/spf13/cobra"
 	pb "github.com/hyperledger/fabric/protos"
 	"github.com/hyperledger/fabric/peer/common"
+	"github.com/hyperledger/fabric/core"
 	"golang.org/x/net/context"
 )
 
@@ -14,6 +21,16 @@ func mutateCmd() *cobra.Command {
 		"The ID of the transactions set that should be mutated.")
 	muchainIssueMutantTxCmd.Flags().Uint64VarP(&index, "index", "i", 0,
 		"The index (as a positive number) of the new active transaction.")
+	muchainIssueMutantTxCmd.Flags().StringVarP(&signaturesPath, "signatures", "g", "",
+		"The path to a json file with the additional signatures required by the mutation policy of the set.")
+	muchainIssueMutantTxCmd.Flags().StringSliceVarP(&additionalMutations, "also", "a", nil,
+		"Further sets mutated atomically with the first one, as comma separated 'tx-set-id:index' pairs.")
+	muchainIssueMutantTxCmd.Flags().Uint64Var(&atBlock, "at-block", 0,
+		"Schedule the mutation at the start of the given block instead of applying it right away.")
+	muchainIssueMutantTxCmd.Flags().StringVar(&notBefore, "not-before", "",
+		"Schedule the mutation at the start of the first block timestamped at or after the given RFC 3339 time.")
+	muchainIssueMutantTxCmd.Flags().StringSliceVar(&attributes, "attributes", nil,
+		"Sign with a transaction certificate holding the given attributes, as required by an attribute mutation policy.")
 
 	return muchainIssueMutantTxCmd
 }
@@ -21,12 +38,18 @@ func mutateCmd() *cobra.Command {
 var (
 	txSetID string
 	index uint64
+	signaturesPath string
+	additionalMutations []string
+	atBlock uint64
+	notBefore string
+	attributes []string
 )
 
 var muchainIssueMutantTxCmd = &cobra.Command{
 	Use:       "mutate",
 	Short:     fmt.Sprintf("Create a new %s mutant transaction.", muchainFuncName),
-	Long:      fmt.Sprintf(`Create a new %s mutant transaction.`, muchainFuncName),
+	Long:      fmt.Sprintf(`Create a new %s mutant transaction. With --at-block or --not-before the mutation is `+
+		`scheduled and applied by the validators at the start of the matching block, unless cancelled.`, muchainFuncName),
 	ValidArgs: []string{"1"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return muchainIssueMutantTx(cmd, args)
@@ -34,16 +57,17 @@ var muchainIssueMutantTxCmd = &cobra.Command{
 }
 
 func muchainIssueMutantTx(cmd *cobra.Command, args []string) error {
-	if !cmd.Flag("name").Changed {
-		return fmt.Errorf("A valid transactions set id must be provided")
-	}
-	if !cmd.Flag("index").Changed {
-		return  fmt.Errorf("A valid index must be provided")
+	mutantSpec, err := mutantSpecFromFlags(cmd)
+	if err != nil {
+		return err
 	}
-
-	mutantSpec := &pb.MutantSpec{
-		TxSetID: txSetID,
-		Index: index,
+	mutantSpec.AtBlock = atBlock
+	if cmd.Flag("not-before").Changed {
+		t, err := time.Parse(time.RFC3339, notBefore)
+		if err != nil {
+			return fmt.Errorf("Invalid time %s, expected an RFC 3339 time: %s", notBefore, err)
+		}
+		mutantSpec.NotBefore = &timestamp.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
 	}
 
 	devopsClient, err := common.GetDevopsClient(cmd)
@@ -68,3 +92,59 @@ func muchainIssueMutantTx(cmd *cobra.Command, args []string) error {
 
  	return nil
 }
+
+// mutantSpecFromFlags builds the MutantSpec given by the flags of a mutate or simulate command
+func mutantSpecFromFlags(cmd *cobra.Command) (*pb.MutantSpec, error) {
+	if !cmd.Flag("name").Changed {
+		return nil, fmt.Errorf("A valid transactions set id must be provided")
+	}
+	if !cmd.Flag("index").Changed {
+		return nil, fmt.Errorf("A valid index must be provided")
+	}
+
+	mutantSpec := &pb.MutantSpec{
+		TxSetID: txSetID,
+		Index: index,
+	}
+
+	for _, pair := range additionalMutations {
+		sep := strings.LastIndex(pair, ":")
+		if sep <= 0 {
+			return nil, fmt.Errorf("Invalid mutation %s, expected 'tx-set-id:index'", pair)
+		}
+		setIndex, err := strconv.ParseUint(pair[sep+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid index in mutation %s: %s", pair, err)
+		}
+		mutantSpec.AdditionalMutations = append(mutantSpec.AdditionalMutations, &pb.TxSetMutation{TxSetID: pair[:sep], TxSetIndex: setIndex})
+	}
+
+	if core.SecurityEnabled() {
+		mutantSpec.SecureContext = fabricUsr
+		mutantSpec.Attributes = attributes
+	}
+
+	if cmd.Flag("signatures").Changed {
+		signatures, err := readMutationSignatures(signaturesPath)
+		if err != nil {
+			return nil, err
+		}
+		mutantSpec.Signatures = signatures
+	}
+
+	return mutantSpec, nil
+}
+
+// readMutationSignatures reads the additional signatures of a mutation from a json file
+func readMutationSignatures(path string) ([]*pb.MutationSignature, error) {
+	fileBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read %s. Error: %s", path, err)
+	}
+	var signatures []*pb.MutationSignature
+	err = json.Unmarshal(fileBytes, &signatures)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse the mutation signatures: %s", err)
+	}
+	return signatures, nil
+}
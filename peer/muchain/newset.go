@@ -93,9 +93,15 @@ func muchainIssueTxSet(cmd *cobra.Command, args []string) error {
 		TxSpecs: encryptedSpecs,
 		DefaultInx: txSetInputSpec.DefaultIndex,
 		ConfidentialityLevel: pb.ConfidentialityLevel_CONFIDENTIAL,
+		ConfidentialityProtocolVersion: txset.ConfidentialityProtocolVersion,
+		MutationPolicy: txSetInputSpec.MutationPolicy,
 		Metadata: nonce, //TODO: In the shared scenario put only a share of the key and send a different one to every peer
 	}
 
+	if core.SecurityEnabled() {
+		txSetSpec.SecureContext = fabricUsr
+	}
+
 	devopsClient, err := common.GetDevopsClient(cmd)
 	if err != nil {
 		return fmt.Errorf("Error building the txSet: %s", err)
@@ -7,14 +7,19 @@ import (
 
 	pb "github.com/hyperledger/fabric/protos"
 	"github.com/hyperledger/fabric/peer/common"
+	"github.com/hyperledger/fabric/core"
 	"golang.org/x/net/context"
 )
 
 func queryState() *cobra.Command {
+	muchainQueryTxSetStateCmd.Flags().BoolVarP(&orderedQuery, "ordered", "o", false,
+		"Order the query with the other transactions through the consensus, instead of reading the committed state of the peer.")
 
 	return muchainQueryTxSetStateCmd
 }
 
+var orderedQuery bool
+
 var muchainQueryTxSetStateCmd = &cobra.Command{
 	Use:       "query-state 'tx-set-id'",
 	Short:     "Queries the state of the transactions set given as argument.",
@@ -32,6 +37,11 @@ func muchainQueryTxSetState(cmd *cobra.Command, args []string) error {
 
 	querySpec := &pb.MutantSpec{
 		TxSetID: args[0],
+		Ordered: orderedQuery,
+	}
+
+	if core.SecurityEnabled() {
+		querySpec.SecureContext = fabricUsr
 	}
 
 	devopsClient, err := common.GetDevopsClient(cmd)
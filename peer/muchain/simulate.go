@@ -0,0 +1,85 @@
+package muchain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/cobra"
+
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos"
+	"golang.org/x/net/context"
+)
+
+func simulateCmd() *cobra.Command {
+	muchainSimulateMutationCmd.Flags().StringVarP(&txSetID, "name", "n", "",
+		"The ID of the transactions set whose mutation is simulated.")
+	muchainSimulateMutationCmd.Flags().Uint64VarP(&index, "index", "i", 0,
+		"The index (as a positive number) of the new active transaction.")
+	muchainSimulateMutationCmd.Flags().StringVarP(&signaturesPath, "signatures", "g", "",
+		"The path to a json file with the additional signatures required by the mutation policy of the set.")
+	muchainSimulateMutationCmd.Flags().StringSliceVarP(&additionalMutations, "also", "a", nil,
+		"Further sets mutated atomically with the first one, as comma separated 'tx-set-id:index' pairs.")
+	muchainSimulateMutationCmd.Flags().StringSliceVar(&attributes, "attributes", nil,
+		"Sign with a transaction certificate holding the given attributes, as required by an attribute mutation policy.")
+
+	return muchainSimulateMutationCmd
+}
+
+var muchainSimulateMutationCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: fmt.Sprintf("Simulate a %s mutant transaction without issuing it.", muchainFuncName),
+	Long: fmt.Sprintf(`Simulate a %s mutant transaction without issuing it. The blocks the mutation would change are replayed `+
+		`by the peer and then discarded, and the chaincode keys and the transactions whose outcome would change are printed.`, muchainFuncName),
+	ValidArgs: []string{"1"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return muchainSimulateMutation(cmd, args)
+	},
+}
+
+func muchainSimulateMutation(cmd *cobra.Command, args []string) error {
+	mutantSpec, err := mutantSpecFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	devopsClient, err := common.GetDevopsClient(cmd)
+	if err != nil {
+		return fmt.Errorf("Error building the devops client: %s", err)
+	}
+
+	resp, err := devopsClient.SimulateMutation(context.Background(), mutantSpec)
+	if err != nil {
+		return fmt.Errorf("Error simulating the mutation: %s\n", err)
+	}
+
+	if resp.Status != pb.Response_SUCCESS {
+		return fmt.Errorf("Unable to simulate the mutation: %s", string(resp.Msg))
+	}
+
+	simulation := &pb.MutationSimulation{}
+	if err = proto.Unmarshal(resp.Msg, simulation); err != nil {
+		return errors.New("Simulation successfull, but unable to unmarshal the response.")
+	}
+
+	if simulation.FromBlock == 0 {
+		logger.Info("Successfully simulated the mutation, no block would be replayed.")
+		return nil
+	}
+	logger.Infof("Successfully simulated the mutation, blocks would be replayed from block %d.", simulation.FromBlock)
+	fmt.Println("Chaincode keys whose value would change:")
+	fmt.Println("Chaincode\t\tKey\t\t\tOld Value\t\tNew Value")
+	for _, change := range simulation.KeyChanges {
+		fmt.Print(change.ChaincodeID, "\t\t", change.Key, "\t\t\t", string(change.PreviousValue), "\t\t\t", string(change.Value), "\n")
+	}
+	fmt.Println("Transactions whose outcome would change:")
+	fmt.Println("Block\t\t\tTx ID\t\t\tOld Outcome\t\tNew Outcome\t\tError")
+	for _, report := range simulation.ReplayReports {
+		for _, result := range report.Transactions {
+			fmt.Print(report.BlockNumber, "\t\t\t", result.Txid, "\t\t\t", result.PreviousOutcome, "\t\t", result.Outcome, "\t\t", result.Error, "\n")
+		}
+	}
+
+	return nil
+}
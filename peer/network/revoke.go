@@ -0,0 +1,120 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/hyperledger/fabric/core"
+	"github.com/hyperledger/fabric/peer/common"
+	"github.com/hyperledger/fabric/peer/util"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+func revokeCmd() *cobra.Command {
+	// Set the flags on the revoke command.
+	networkRevokeCmd.Flags().StringVarP(&revokeCertPath, "cert", "c",
+		common.UndefinedParamValue,
+		"The path to the PEM or DER encoded certificate to revoke. The "+
+			"enrollment certificate pair of the user is revoked if this flag is not specified.")
+	networkRevokeCmd.Flags().BoolVarP(&revokeTCert, "tcert", "t", false,
+		"The certificate to revoke is a transaction certificate.")
+
+	return networkRevokeCmd
+}
+
+var networkRevokeCmd = &cobra.Command{
+	Use:   "revoke <username>",
+	Short: "Revokes a certificate.",
+	Long: "Asks the member services to revoke a certificate on behalf of a " +
+		"logged in user. Users can revoke their own certificates, registrars " +
+		"the ones of the members they may register.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return networkRevoke(args)
+	},
+}
+
+// revoke related variables.
+var (
+	revokeCertPath string
+	revokeTCert    bool
+)
+
+// networkRevoke revokes a certificate through the Devops server on behalf of
+// a logged in user.
+func networkRevoke(args []string) error {
+	if !core.SecurityEnabled() {
+		return errors.New("Certificates can only be revoked when security is enabled")
+	}
+
+	// Check for username argument
+	if len(args) != 1 {
+		return errors.New("Must supply username as the 1st and only parameter")
+	}
+
+	// The login token is the security context used by the Devops server
+	localStore := util.GetCliFilePath()
+	token, err := ioutil.ReadFile(localStore + "loginToken_" + args[0])
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("User '%s' not logged in. Use the 'peer network login' command to obtain a security token.", args[0])
+		}
+		return fmt.Errorf("Error reading login token of user '%s': %s", args[0], err)
+	}
+
+	revocationSpec := &pb.RevocationSpec{SecureContext: string(token), Type: pb.RevocationSpec_ENROLLMENT}
+	if revokeTCert {
+		revocationSpec.Type = pb.RevocationSpec_TRANSACTION
+	}
+
+	if revokeCertPath != common.UndefinedParamValue {
+		raw, err := ioutil.ReadFile(revokeCertPath)
+		if err != nil {
+			return fmt.Errorf("Unable to read %s. Error: %s", revokeCertPath, err)
+		}
+		if block, _ := pem.Decode(raw); block != nil {
+			raw = block.Bytes
+		}
+		revocationSpec.Cert = raw
+	} else if revokeTCert {
+		return errors.New("Must supply the transaction certificate to revoke")
+	}
+
+	devopsClient, err := common.GetDevopsClient(nil)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Revoking %s certificate on behalf of user '%s'...\n", revocationSpec.Type, args[0])
+	resp, err := devopsClient.Revoke(context.Background(), revocationSpec)
+	if err != nil {
+		return fmt.Errorf("Error revoking certificate: %s", err)
+	}
+	if resp.Status != pb.Response_SUCCESS {
+		return fmt.Errorf("Error revoking certificate: %s", string(resp.Msg))
+	}
+
+	logger.Info("Certificate revoked.")
+
+	return nil
+}
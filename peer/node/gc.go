@@ -0,0 +1,73 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/core/peer"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+func gcCmd() *cobra.Command {
+	return nodeGCCmd
+}
+
+var nodeGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Reports what the tx set garbage collection reclaimed.",
+	Long:  `Reports the data of the immutable tx sets reclaimed by the garbage collection of the running node.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return gcReport()
+	},
+}
+
+func gcReport() error {
+	clientConn, err := peer.NewPeerClientConnection()
+	if err != nil {
+		logger.Infof("Error trying to connect to local peer: %s", err)
+		return fmt.Errorf("Error trying to connect to local peer: %s", err)
+	}
+
+	serverClient := pb.NewAdminClient(clientConn)
+
+	report, err := serverClient.GetTxSetGCReport(context.Background(), &empty.Empty{})
+	if err != nil {
+		logger.Infof("Error trying to get the tx set garbage collection report from local peer: %s", err)
+		return fmt.Errorf("Error trying to get the tx set garbage collection report: %s", err)
+	}
+	if report.Runs == 0 {
+		fmt.Println("No tx set garbage collection has run yet.")
+		return nil
+	}
+	fmt.Printf("Runs: %d\n", report.Runs)
+	if report.LastRun != nil {
+		fmt.Printf("Last run: %s\n", time.Unix(report.LastRun.Seconds, int64(report.LastRun.Nanos)).UTC())
+	}
+	fmt.Printf("Horizon block: %d\n", report.Horizon)
+	fmt.Printf("Nonces: %d\n", report.Nonces)
+	fmt.Printf("Block states: %d\n", report.BlockStates)
+	fmt.Printf("Read/write sets: %d\n", report.ReadWriteSets)
+	fmt.Printf("State deltas: %d\n", report.StateDeltas)
+	fmt.Printf("Tx set state deltas: %d\n", report.TxSetStateDeltas)
+	fmt.Printf("Reclaimed bytes: %d\n", report.ReclaimedBytes)
+	return nil
+}
@@ -35,6 +35,7 @@ func Cmd() *cobra.Command {
 	nodeCmd.AddCommand(startCmd())
 	nodeCmd.AddCommand(statusCmd())
 	nodeCmd.AddCommand(stopCmd())
+	nodeCmd.AddCommand(gcCmd())
 
 	return nodeCmd
 }
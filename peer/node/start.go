@@ -35,6 +35,7 @@ import (
 	"github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/core/crypto"
 	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/genesis"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/core/rest"
@@ -170,6 +171,15 @@ func serve(args []string) error {
 		return err
 	}
 
+	// Collect in the background the data of the tx sets that can no longer be mutated
+	ledgerPtr, err := ledger.GetLedger()
+	if err != nil {
+		return fmt.Errorf("Failed to get handle to ledger (%s)", err)
+	}
+	ledgerPtr.StartTxSetGC()
+	// Compact in the background the states stored for the older blocks
+	ledgerPtr.StartBlockStateCompaction()
+
 	// Register the Peer server
 	pb.RegisterPeerServer(grpcServer, peerServer)
 
@@ -45,8 +45,13 @@ func Test_Block_CreateNew(t *testing.T) {
 	}
 	transaction := &Transaction{Type: 2, ChaincodeID: cidBytes, Payload: data, Txid: "001"}
 	t.Logf("Transaction: %v", transaction)
+	txBytes, err := proto.Marshal(transaction)
+	if err != nil {
+		t.Fatalf("Could not marshal transaction: %s", err)
+	}
+	inBlockTx := &InBlockTransaction{Transaction: &InBlockTransaction_TransactionSet{TransactionSet: &TransactionSet{Transactions: [][]byte{txBytes}}}, Txid: transaction.Txid}
 
-	block := NewBlock([]*Transaction{transaction}, nil)
+	block := NewBlock([]*InBlockTransaction{inBlockTx}, nil)
 	t.Logf("Block: %v", block)
 
 	data, err = proto.Marshal(block)
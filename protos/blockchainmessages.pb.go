@@ -105,6 +105,38 @@ func (x ChaincodeSpec_Type) String() string {
 }
 func (ChaincodeSpec_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor1, []int{3, 0} }
 
+type TxSetMutationPolicy_Type int32
+
+const (
+	TxSetMutationPolicy_UNRESTRICTED   TxSetMutationPolicy_Type = 0
+	TxSetMutationPolicy_CREATOR        TxSetMutationPolicy_Type = 1
+	TxSetMutationPolicy_ENROLLMENT_IDS TxSetMutationPolicy_Type = 2
+	TxSetMutationPolicy_ATTRIBUTE      TxSetMutationPolicy_Type = 3
+	TxSetMutationPolicy_THRESHOLD      TxSetMutationPolicy_Type = 4
+	TxSetMutationPolicy_CHAINCODE      TxSetMutationPolicy_Type = 5
+)
+
+var TxSetMutationPolicy_Type_name = map[int32]string{
+	0: "UNRESTRICTED",
+	1: "CREATOR",
+	2: "ENROLLMENT_IDS",
+	3: "ATTRIBUTE",
+	4: "THRESHOLD",
+	5: "CHAINCODE",
+}
+var TxSetMutationPolicy_Type_value = map[string]int32{
+	"UNRESTRICTED":   0,
+	"CREATOR":        1,
+	"ENROLLMENT_IDS": 2,
+	"ATTRIBUTE":      3,
+	"THRESHOLD":      4,
+	"CHAINCODE":      5,
+}
+
+func (x TxSetMutationPolicy_Type) String() string {
+	return proto.EnumName(TxSetMutationPolicy_Type_name, int32(x))
+}
+
 type TxSetSpec_Type int32
 
 const (
@@ -173,6 +205,7 @@ const (
 	ChaincodeMessage_RANGE_QUERY_STATE_NEXT  ChaincodeMessage_Type = 18
 	ChaincodeMessage_RANGE_QUERY_STATE_CLOSE ChaincodeMessage_Type = 19
 	ChaincodeMessage_KEEPALIVE               ChaincodeMessage_Type = 20
+	ChaincodeMessage_MUTATE_TX_SET           ChaincodeMessage_Type = 21
 )
 
 var ChaincodeMessage_Type_name = map[int32]string{
@@ -197,6 +230,7 @@ var ChaincodeMessage_Type_name = map[int32]string{
 	18: "RANGE_QUERY_STATE_NEXT",
 	19: "RANGE_QUERY_STATE_CLOSE",
 	20: "KEEPALIVE",
+	21: "MUTATE_TX_SET",
 }
 var ChaincodeMessage_Type_value = map[string]int32{
 	"UNDEFINED":               0,
@@ -220,6 +254,7 @@ var ChaincodeMessage_Type_value = map[string]int32{
 	"RANGE_QUERY_STATE_NEXT":  18,
 	"RANGE_QUERY_STATE_CLOSE": 19,
 	"KEEPALIVE":               20,
+	"MUTATE_TX_SET":           21,
 }
 
 func (x ChaincodeMessage_Type) String() string {
@@ -260,10 +295,11 @@ func (*ChaincodeInput) ProtoMessage()               {}
 func (*ChaincodeInput) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
 
 type TxSetInput struct {
-	Type         TxSetSpec_Type               `protobuf:"varint,1,opt,name=type,enum=protos.TxSetSpec_Type" json:"type,omitempty"`
-	DefaultIndex uint64                       `protobuf:"varint,2,opt,name=defaultIndex" json:"defaultIndex,omitempty"`
-	SetID        string                       `protobuf:"bytes,3,opt,name=setID" json:"setID,omitempty"`
-	TxSpecs      []*TxSetInput_SimplifiedSpec `protobuf:"bytes,4,rep,name=txSpecs" json:"txSpecs,omitempty"`
+	Type           TxSetSpec_Type               `protobuf:"varint,1,opt,name=type,enum=protos.TxSetSpec_Type" json:"type,omitempty"`
+	DefaultIndex   uint64                       `protobuf:"varint,2,opt,name=defaultIndex" json:"defaultIndex,omitempty"`
+	SetID          string                       `protobuf:"bytes,3,opt,name=setID" json:"setID,omitempty"`
+	TxSpecs        []*TxSetInput_SimplifiedSpec `protobuf:"bytes,4,rep,name=txSpecs" json:"txSpecs,omitempty"`
+	MutationPolicy *TxSetMutationPolicy         `protobuf:"bytes,5,opt,name=mutationPolicy" json:"mutationPolicy,omitempty"`
 }
 
 func (m *TxSetInput) Reset()                    { *m = TxSetInput{} }
@@ -271,6 +307,13 @@ func (m *TxSetInput) String() string            { return proto.CompactTextString
 func (*TxSetInput) ProtoMessage()               {}
 func (*TxSetInput) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
 
+func (m *TxSetInput) GetMutationPolicy() *TxSetMutationPolicy {
+	if m != nil {
+		return m.MutationPolicy
+	}
+	return nil
+}
+
 func (m *TxSetInput) GetTxSpecs() []*TxSetInput_SimplifiedSpec {
 	if m != nil {
 		return m.TxSpecs
@@ -470,6 +513,10 @@ type TxSetSpec struct {
 	SecureContext        string               `protobuf:"bytes,5,opt,name=secureContext" json:"secureContext,omitempty"`
 	ConfidentialityLevel ConfidentialityLevel `protobuf:"varint,6,opt,name=confidentialityLevel,enum=protos.ConfidentialityLevel" json:"confidentialityLevel,omitempty"`
 	Metadata             []byte               `protobuf:"bytes,7,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// Version of the protocol used to encrypt the transactions of the set
+	ConfidentialityProtocolVersion string `protobuf:"bytes,8,opt,name=confidentialityProtocolVersion" json:"confidentialityProtocolVersion,omitempty"`
+	// Who is allowed to mutate the set. Only allowed at creation.
+	MutationPolicy *TxSetMutationPolicy `protobuf:"bytes,9,opt,name=mutationPolicy" json:"mutationPolicy,omitempty"`
 }
 
 func (m *TxSetSpec) Reset()                    { *m = TxSetSpec{} }
@@ -477,10 +524,74 @@ func (m *TxSetSpec) String() string            { return proto.CompactTextString(
 func (*TxSetSpec) ProtoMessage()               {}
 func (*TxSetSpec) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
 
+func (m *TxSetSpec) GetMutationPolicy() *TxSetMutationPolicy {
+	if m != nil {
+		return m.MutationPolicy
+	}
+	return nil
+}
+
+// A signature over the mutation of a transactions set together with the
+// certificate that has produced it
+type MutationSignature struct {
+	Cert      []byte `protobuf:"bytes,1,opt,name=cert,proto3" json:"cert,omitempty"`
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *MutationSignature) Reset()         { *m = MutationSignature{} }
+func (m *MutationSignature) String() string { return proto.CompactTextString(m) }
+func (*MutationSignature) ProtoMessage()    {}
+
+// The change of the active transaction of a transactions set
+type TxSetMutation struct {
+	TxSetID    string `protobuf:"bytes,1,opt,name=txSetID" json:"txSetID,omitempty"`
+	TxSetIndex uint64 `protobuf:"varint,2,opt,name=txSetIndex" json:"txSetIndex,omitempty"`
+}
+
+func (m *TxSetMutation) Reset()         { *m = TxSetMutation{} }
+func (m *TxSetMutation) String() string { return proto.CompactTextString(m) }
+func (*TxSetMutation) ProtoMessage()    {}
+
+// The policy deciding who is allowed to mutate a transactions set.
+// It is fixed when the transactions set is issued.
+type TxSetMutationPolicy struct {
+	Type TxSetMutationPolicy_Type `protobuf:"varint,1,opt,name=type,enum=protos.TxSetMutationPolicy_Type" json:"type,omitempty"`
+	// The enrollment certificate of the creator of the set, filled in by the validators
+	CreatorCert    []byte   `protobuf:"bytes,2,opt,name=creatorCert,proto3" json:"creatorCert,omitempty"`
+	EnrollmentIDs  []string `protobuf:"bytes,3,rep,name=enrollmentIDs" json:"enrollmentIDs,omitempty"`
+	AttributeName  string   `protobuf:"bytes,4,opt,name=attributeName" json:"attributeName,omitempty"`
+	AttributeValue []byte   `protobuf:"bytes,5,opt,name=attributeValue,proto3" json:"attributeValue,omitempty"`
+	SignerCerts    [][]byte `protobuf:"bytes,6,rep,name=signerCerts,proto3" json:"signerCerts,omitempty"`
+	Threshold      uint32   `protobuf:"varint,7,opt,name=threshold" json:"threshold,omitempty"`
+	ChaincodeIDs   []string `protobuf:"bytes,8,rep,name=chaincodeIDs" json:"chaincodeIDs,omitempty"`
+}
+
+func (m *TxSetMutationPolicy) Reset()         { *m = TxSetMutationPolicy{} }
+func (m *TxSetMutationPolicy) String() string { return proto.CompactTextString(m) }
+func (*TxSetMutationPolicy) ProtoMessage()    {}
+
 // Carries the specification for a Mutant transaction.
 type MutantSpec struct {
 	TxSetID string `protobuf:"bytes,1,opt,name=txSetID" json:"txSetID,omitempty"`
 	Index   uint64 `protobuf:"varint,2,opt,name=index" json:"index,omitempty"`
+	// The enrollment ID used to sign the mutation when security is enabled
+	SecureContext string `protobuf:"bytes,3,opt,name=secureContext" json:"secureContext,omitempty"`
+	// Signatures of other parties required by the mutation policy of the set
+	Signatures []*MutationSignature `protobuf:"bytes,4,rep,name=signatures" json:"signatures,omitempty"`
+	// Only used when querying the state of the set: if true the query is ordered by the consensus
+	// and returns the state of the set once the block containing the query is committed
+	Ordered bool `protobuf:"varint,5,opt,name=ordered" json:"ordered,omitempty"`
+	// Further sets mutated atomically with txSetID
+	AdditionalMutations []*TxSetMutation `protobuf:"bytes,6,rep,name=additionalMutations" json:"additionalMutations,omitempty"`
+	// Schedule the mutation of txSetID at the start of block atBlock, or of the first block
+	// timestamped at or after notBefore, instead of applying it right away
+	AtBlock   uint64                     `protobuf:"varint,7,opt,name=atBlock" json:"atBlock,omitempty"`
+	NotBefore *google_protobuf.Timestamp `protobuf:"bytes,8,opt,name=notBefore" json:"notBefore,omitempty"`
+	// Cancel the mutation scheduled on txSetID, index is ignored
+	CancelScheduled bool `protobuf:"varint,9,opt,name=cancelScheduled" json:"cancelScheduled,omitempty"`
+	// Attributes the signer must prove, as required by an attribute mutation policy. If given the
+	// mutation is signed with a transaction certificate holding them instead of the enrollment certificate
+	Attributes []string `protobuf:"bytes,10,rep,name=attributes" json:"attributes,omitempty"`
 }
 
 func (m *MutantSpec) Reset()                    { *m = MutantSpec{} }
@@ -488,6 +599,38 @@ func (m *MutantSpec) String() string            { return proto.CompactTextString
 func (*MutantSpec) ProtoMessage()               {}
 func (*MutantSpec) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{6} }
 
+func (m *MutantSpec) GetSignatures() []*MutationSignature {
+	if m != nil {
+		return m.Signatures
+	}
+	return nil
+}
+
+func (m *MutantSpec) GetAdditionalMutations() []*TxSetMutation {
+	if m != nil {
+		return m.AdditionalMutations
+	}
+	return nil
+}
+
+func (m *MutantSpec) GetNotBefore() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.NotBefore
+	}
+	return nil
+}
+
+// Query for the state of a transactions set at a given block height
+type TxSetHistorySpec struct {
+	TxSetID string `protobuf:"bytes,1,opt,name=txSetID" json:"txSetID,omitempty"`
+	// The block at which the state is rebuilt, 0 stands for the latest block
+	BlockNr uint64 `protobuf:"varint,2,opt,name=blockNr" json:"blockNr,omitempty"`
+}
+
+func (m *TxSetHistorySpec) Reset()         { *m = TxSetHistorySpec{} }
+func (m *TxSetHistorySpec) String() string { return proto.CompactTextString(m) }
+func (*TxSetHistorySpec) ProtoMessage()    {}
+
 // Specify the deployment of a chaincode.
 // TODO: Define `codePackage`.
 type ChaincodeDeploymentSpec struct {
@@ -578,6 +721,9 @@ type ChaincodeMessage struct {
 	// This event is then stored (currently)
 	// with Block.NonHashData.TransactionResult
 	ChaincodeEvent *ChaincodeEvent `protobuf:"bytes,6,opt,name=chaincodeEvent" json:"chaincodeEvent,omitempty"`
+	// transactions set the executed transaction belongs to. Used only with
+	// Init, Invoke and Query.
+	TxSetContext *ChaincodeTxSetContext `protobuf:"bytes,7,opt,name=txSetContext" json:"txSetContext,omitempty"`
 }
 
 func (m *ChaincodeMessage) Reset()                    { *m = ChaincodeMessage{} }
@@ -606,6 +752,26 @@ func (m *ChaincodeMessage) GetChaincodeEvent() *ChaincodeEvent {
 	return nil
 }
 
+func (m *ChaincodeMessage) GetTxSetContext() *ChaincodeTxSetContext {
+	if m != nil {
+		return m.TxSetContext
+	}
+	return nil
+}
+
+// ChaincodeTxSetContext carries to the chaincode the transactions set whose
+// default transaction is being executed, the index of that transaction in the
+// set and whether the execution is a re-execution caused by a mutation.
+type ChaincodeTxSetContext struct {
+	TxSetID    string `protobuf:"bytes,1,opt,name=txSetID" json:"txSetID,omitempty"`
+	TxSetIndex uint64 `protobuf:"varint,2,opt,name=txSetIndex" json:"txSetIndex,omitempty"`
+	Replay     bool   `protobuf:"varint,3,opt,name=replay" json:"replay,omitempty"`
+}
+
+func (m *ChaincodeTxSetContext) Reset()         { *m = ChaincodeTxSetContext{} }
+func (m *ChaincodeTxSetContext) String() string { return proto.CompactTextString(m) }
+func (*ChaincodeTxSetContext) ProtoMessage()    {}
+
 type PutStateInfo struct {
 	Key   string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
 	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
@@ -680,11 +846,16 @@ func init() {
 	proto.RegisterType((*ChaincodeSpec)(nil), "protos.ChaincodeSpec")
 	proto.RegisterType((*TxSpec)(nil), "protos.TxSpec")
 	proto.RegisterType((*TxSetSpec)(nil), "protos.TxSetSpec")
+	proto.RegisterType((*MutationSignature)(nil), "protos.MutationSignature")
+	proto.RegisterType((*TxSetMutation)(nil), "protos.TxSetMutation")
+	proto.RegisterType((*TxSetMutationPolicy)(nil), "protos.TxSetMutationPolicy")
 	proto.RegisterType((*MutantSpec)(nil), "protos.MutantSpec")
+	proto.RegisterType((*TxSetHistorySpec)(nil), "protos.TxSetHistorySpec")
 	proto.RegisterType((*ChaincodeDeploymentSpec)(nil), "protos.ChaincodeDeploymentSpec")
 	proto.RegisterType((*ChaincodeInvocationSpec)(nil), "protos.ChaincodeInvocationSpec")
 	proto.RegisterType((*ChaincodeSecurityContext)(nil), "protos.ChaincodeSecurityContext")
 	proto.RegisterType((*ChaincodeMessage)(nil), "protos.ChaincodeMessage")
+	proto.RegisterType((*ChaincodeTxSetContext)(nil), "protos.ChaincodeTxSetContext")
 	proto.RegisterType((*PutStateInfo)(nil), "protos.PutStateInfo")
 	proto.RegisterType((*RangeQueryState)(nil), "protos.RangeQueryState")
 	proto.RegisterType((*RangeQueryStateNext)(nil), "protos.RangeQueryStateNext")
@@ -694,6 +865,7 @@ func init() {
 	proto.RegisterEnum("protos.ConfidentialityLevel", ConfidentialityLevel_name, ConfidentialityLevel_value)
 	proto.RegisterEnum("protos.ChaincodeAction", ChaincodeAction_name, ChaincodeAction_value)
 	proto.RegisterEnum("protos.ChaincodeSpec_Type", ChaincodeSpec_Type_name, ChaincodeSpec_Type_value)
+	proto.RegisterEnum("protos.TxSetMutationPolicy_Type", TxSetMutationPolicy_Type_name, TxSetMutationPolicy_Type_value)
 	proto.RegisterEnum("protos.TxSetSpec_Type", TxSetSpec_Type_name, TxSetSpec_Type_value)
 	proto.RegisterEnum("protos.ChaincodeDeploymentSpec_ExecutionEnvironment", ChaincodeDeploymentSpec_ExecutionEnvironment_name, ChaincodeDeploymentSpec_ExecutionEnvironment_value)
 	proto.RegisterEnum("protos.ChaincodeMessage_Type", ChaincodeMessage_Type_name, ChaincodeMessage_Type_value)
@@ -42,6 +42,26 @@ func (x BuildResult_StatusCode) String() string {
 }
 func (BuildResult_StatusCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor3, []int{4, 0} }
 
+type RevocationSpec_CertificateType int32
+
+const (
+	RevocationSpec_ENROLLMENT  RevocationSpec_CertificateType = 0
+	RevocationSpec_TRANSACTION RevocationSpec_CertificateType = 1
+)
+
+var RevocationSpec_CertificateType_name = map[int32]string{
+	0: "ENROLLMENT",
+	1: "TRANSACTION",
+}
+var RevocationSpec_CertificateType_value = map[string]int32{
+	"ENROLLMENT":  0,
+	"TRANSACTION": 1,
+}
+
+func (x RevocationSpec_CertificateType) String() string {
+	return proto.EnumName(RevocationSpec_CertificateType_name, int32(x))
+}
+
 // Secret is a temporary object to establish security with the Devops.
 // A better solution using certificate will be introduced later
 type Secret struct {
@@ -54,6 +74,20 @@ func (m *Secret) String() string            { return proto.CompactTextString(m)
 func (*Secret) ProtoMessage()               {}
 func (*Secret) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{0} }
 
+// RevocationSpec identifies the certificate a logged in user wants revoked.
+// Users can revoke their own certificates, registrars the ones of the members
+// they may register.
+type RevocationSpec struct {
+	SecureContext string                         `protobuf:"bytes,1,opt,name=secureContext" json:"secureContext,omitempty"`
+	Type          RevocationSpec_CertificateType `protobuf:"varint,2,opt,name=type,enum=protos.RevocationSpec_CertificateType" json:"type,omitempty"`
+	// DER encoded certificate, the enrollment certificate of the user if not set
+	Cert []byte `protobuf:"bytes,3,opt,name=cert,proto3" json:"cert,omitempty"`
+}
+
+func (m *RevocationSpec) Reset()         { *m = RevocationSpec{} }
+func (m *RevocationSpec) String() string { return proto.CompactTextString(m) }
+func (*RevocationSpec) ProtoMessage()    {}
+
 type SigmaInput struct {
 	Secret   *Secret `protobuf:"bytes,1,opt,name=secret" json:"secret,omitempty"`
 	AppTCert []byte  `protobuf:"bytes,2,opt,name=appTCert,proto3" json:"appTCert,omitempty"`
@@ -129,11 +163,13 @@ func (*TransactionRequest) Descriptor() ([]byte, []int) { return fileDescriptor3
 
 func init() {
 	proto.RegisterType((*Secret)(nil), "protos.Secret")
+	proto.RegisterType((*RevocationSpec)(nil), "protos.RevocationSpec")
 	proto.RegisterType((*SigmaInput)(nil), "protos.SigmaInput")
 	proto.RegisterType((*ExecuteWithBinding)(nil), "protos.ExecuteWithBinding")
 	proto.RegisterType((*SigmaOutput)(nil), "protos.SigmaOutput")
 	proto.RegisterType((*BuildResult)(nil), "protos.BuildResult")
 	proto.RegisterType((*TransactionRequest)(nil), "protos.TransactionRequest")
+	proto.RegisterEnum("protos.RevocationSpec_CertificateType", RevocationSpec_CertificateType_name, RevocationSpec_CertificateType_value)
 	proto.RegisterEnum("protos.BuildResult_StatusCode", BuildResult_StatusCode_name, BuildResult_StatusCode_value)
 }
 
@@ -176,6 +212,14 @@ type DevopsClient interface {
 	Mutate(ctx context.Context, in *MutantSpec, opts ...grpc.CallOption) (*Response, error)
 	// Queries the state of a given Tx Set
 	QueryTxSetState(ctx context.Context, in *MutantSpec, opts ...grpc.CallOption) (*Response, error)
+	// Rebuilds the state of a given Tx Set at a block height, together with the index transitions
+	// caused by mutations up to that block. The response contains a TxSetStateHistory.
+	QueryTxSetStateHistory(ctx context.Context, in *TxSetHistorySpec, opts ...grpc.CallOption) (*Response, error)
+	// Simulates a mutation without submitting it: the blocks following the mutated set are replayed on the
+	// state of the peer, which is then restored. The response contains a MutationSimulation.
+	SimulateMutation(ctx context.Context, in *MutantSpec, opts ...grpc.CallOption) (*Response, error)
+	// Revoke asks the member services to revoke a certificate on behalf of the logged in user.
+	Revoke(ctx context.Context, in *RevocationSpec, opts ...grpc.CallOption) (*Response, error)
 	// Retrieve a TCert.
 	EXP_GetApplicationTCert(ctx context.Context, in *Secret, opts ...grpc.CallOption) (*Response, error)
 	// Prepare for performing a TX, which will return a binding that can later be used to sign and then execute a transaction.
@@ -275,6 +319,33 @@ func (c *devopsClient) QueryTxSetState(ctx context.Context, in *MutantSpec, opts
 	return out, nil
 }
 
+func (c *devopsClient) QueryTxSetStateHistory(ctx context.Context, in *TxSetHistorySpec, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := grpc.Invoke(ctx, "/protos.Devops/QueryTxSetStateHistory", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devopsClient) SimulateMutation(ctx context.Context, in *MutantSpec, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := grpc.Invoke(ctx, "/protos.Devops/SimulateMutation", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devopsClient) Revoke(ctx context.Context, in *RevocationSpec, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := grpc.Invoke(ctx, "/protos.Devops/Revoke", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *devopsClient) EXP_GetApplicationTCert(ctx context.Context, in *Secret, opts ...grpc.CallOption) (*Response, error) {
 	out := new(Response)
 	err := grpc.Invoke(ctx, "/protos.Devops/EXP_GetApplicationTCert", in, out, c.cc, opts...)
@@ -342,6 +413,14 @@ type DevopsServer interface {
 	Mutate(context.Context, *MutantSpec) (*Response, error)
 	// Queries the state of a given Tx Set
 	QueryTxSetState(context.Context, *MutantSpec) (*Response, error)
+	// Rebuilds the state of a given Tx Set at a block height, together with the index transitions
+	// caused by mutations up to that block. The response contains a TxSetStateHistory.
+	QueryTxSetStateHistory(context.Context, *TxSetHistorySpec) (*Response, error)
+	// Simulates a mutation without submitting it: the blocks following the mutated set are replayed on the
+	// state of the peer, which is then restored. The response contains a MutationSimulation.
+	SimulateMutation(context.Context, *MutantSpec) (*Response, error)
+	// Revoke asks the member services to revoke a certificate on behalf of the logged in user.
+	Revoke(context.Context, *RevocationSpec) (*Response, error)
 	// Retrieve a TCert.
 	EXP_GetApplicationTCert(context.Context, *Secret) (*Response, error)
 	// Prepare for performing a TX, which will return a binding that can later be used to sign and then execute a transaction.
@@ -518,6 +597,60 @@ func _Devops_QueryTxSetState_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Devops_QueryTxSetStateHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxSetHistorySpec)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevopsServer).QueryTxSetStateHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Devops/QueryTxSetStateHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevopsServer).QueryTxSetStateHistory(ctx, req.(*TxSetHistorySpec))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Devops_SimulateMutation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MutantSpec)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevopsServer).SimulateMutation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Devops/SimulateMutation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevopsServer).SimulateMutation(ctx, req.(*MutantSpec))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Devops_Revoke_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevocationSpec)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevopsServer).Revoke(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Devops/Revoke",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevopsServer).Revoke(ctx, req.(*RevocationSpec))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Devops_EXP_GetApplicationTCert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Secret)
 	if err := dec(in); err != nil {
@@ -630,6 +763,18 @@ var _Devops_serviceDesc = grpc.ServiceDesc{
 			MethodName: "QueryTxSetState",
 			Handler:    _Devops_QueryTxSetState_Handler,
 		},
+		{
+			MethodName: "QueryTxSetStateHistory",
+			Handler:    _Devops_QueryTxSetStateHistory_Handler,
+		},
+		{
+			MethodName: "SimulateMutation",
+			Handler:    _Devops_SimulateMutation_Handler,
+		},
+		{
+			MethodName: "Revoke",
+			Handler:    _Devops_Revoke_Handler,
+		},
 		{
 			MethodName: "EXP_GetApplicationTCert",
 			Handler:    _Devops_EXP_GetApplicationTCert_Handler,
@@ -25,6 +25,8 @@ const (
 	EventType_BLOCK     EventType = 1
 	EventType_CHAINCODE EventType = 2
 	EventType_REJECTION EventType = 3
+	EventType_REPLAY    EventType = 4
+	EventType_TXSET     EventType = 5
 )
 
 var EventType_name = map[int32]string{
@@ -32,12 +34,16 @@ var EventType_name = map[int32]string{
 	1: "BLOCK",
 	2: "CHAINCODE",
 	3: "REJECTION",
+	4: "REPLAY",
+	5: "TXSET",
 }
 var EventType_value = map[string]int32{
 	"REGISTER":  0,
 	"BLOCK":     1,
 	"CHAINCODE": 2,
 	"REJECTION": 3,
+	"REPLAY":    4,
+	"TXSET":     5,
 }
 
 func (x EventType) String() string {
@@ -57,6 +63,16 @@ func (m *ChaincodeReg) String() string            { return proto.CompactTextStri
 func (*ChaincodeReg) ProtoMessage()               {}
 func (*ChaincodeReg) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{0} }
 
+// TxSetReg is used for registering transactions set Interests
+// when EventType is TXSET. An empty txSetID registers for all the sets
+type TxSetReg struct {
+	TxSetID string `protobuf:"bytes,1,opt,name=txSetID" json:"txSetID,omitempty"`
+}
+
+func (m *TxSetReg) Reset()         { *m = TxSetReg{} }
+func (m *TxSetReg) String() string { return proto.CompactTextString(m) }
+func (*TxSetReg) ProtoMessage()    {}
+
 type Interest struct {
 	EventType EventType `protobuf:"varint,1,opt,name=eventType,enum=protos.EventType" json:"eventType,omitempty"`
 	// Ideally we should just have the following oneof for different
@@ -66,6 +82,7 @@ type Interest struct {
 	//
 	// Types that are valid to be assigned to RegInfo:
 	//	*Interest_ChaincodeRegInfo
+	//	*Interest_TxSetRegInfo
 	RegInfo isInterest_RegInfo `protobuf_oneof:"RegInfo"`
 }
 
@@ -82,7 +99,12 @@ type Interest_ChaincodeRegInfo struct {
 	ChaincodeRegInfo *ChaincodeReg `protobuf:"bytes,2,opt,name=chaincodeRegInfo,oneof"`
 }
 
+type Interest_TxSetRegInfo struct {
+	TxSetRegInfo *TxSetReg `protobuf:"bytes,3,opt,name=txSetRegInfo,oneof"`
+}
+
 func (*Interest_ChaincodeRegInfo) isInterest_RegInfo() {}
+func (*Interest_TxSetRegInfo) isInterest_RegInfo()     {}
 
 func (m *Interest) GetRegInfo() isInterest_RegInfo {
 	if m != nil {
@@ -98,10 +120,18 @@ func (m *Interest) GetChaincodeRegInfo() *ChaincodeReg {
 	return nil
 }
 
+func (m *Interest) GetTxSetRegInfo() *TxSetReg {
+	if x, ok := m.GetRegInfo().(*Interest_TxSetRegInfo); ok {
+		return x.TxSetRegInfo
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Interest) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Interest_OneofMarshaler, _Interest_OneofUnmarshaler, _Interest_OneofSizer, []interface{}{
 		(*Interest_ChaincodeRegInfo)(nil),
+		(*Interest_TxSetRegInfo)(nil),
 	}
 }
 
@@ -114,6 +144,11 @@ func _Interest_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.ChaincodeRegInfo); err != nil {
 			return err
 		}
+	case *Interest_TxSetRegInfo:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.TxSetRegInfo); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Interest.RegInfo has unexpected type %T", x)
@@ -132,6 +167,14 @@ func _Interest_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffe
 		err := b.DecodeMessage(msg)
 		m.RegInfo = &Interest_ChaincodeRegInfo{msg}
 		return true, err
+	case 3: // RegInfo.txSetRegInfo
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(TxSetReg)
+		err := b.DecodeMessage(msg)
+		m.RegInfo = &Interest_TxSetRegInfo{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -146,6 +189,11 @@ func _Interest_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(2<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Interest_TxSetRegInfo:
+		s := proto.Size(x.TxSetRegInfo)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -192,6 +240,59 @@ func (m *Rejection) GetTx() *InBlockTransaction {
 }
 
 // ---------- producer events ---------
+type TxSetEvent_Kind int32
+
+const (
+	TxSetEvent_CREATED       TxSetEvent_Kind = 0
+	TxSetEvent_EXTENDED      TxSetEvent_Kind = 1
+	TxSetEvent_INDEX_CHANGED TxSetEvent_Kind = 2
+)
+
+var TxSetEvent_Kind_name = map[int32]string{
+	0: "CREATED",
+	1: "EXTENDED",
+	2: "INDEX_CHANGED",
+}
+var TxSetEvent_Kind_value = map[string]int32{
+	"CREATED":       0,
+	"EXTENDED":      1,
+	"INDEX_CHANGED": 2,
+}
+
+func (x TxSetEvent_Kind) String() string {
+	return proto.EnumName(TxSetEvent_Kind_name, int32(x))
+}
+
+// TxSetEvent is sent when a block changes the state of a transactions set.
+// txid is the ID of the mutant transaction for INDEX_CHANGED events, the ID
+// of the set otherwise. oldState is not set for CREATED events
+type TxSetEvent struct {
+	Kind        TxSetEvent_Kind  `protobuf:"varint,1,opt,name=kind,enum=protos.TxSetEvent_Kind" json:"kind,omitempty"`
+	TxSetID     string           `protobuf:"bytes,2,opt,name=txSetID" json:"txSetID,omitempty"`
+	OldState    *TxSetStateValue `protobuf:"bytes,3,opt,name=oldState" json:"oldState,omitempty"`
+	NewState    *TxSetStateValue `protobuf:"bytes,4,opt,name=newState" json:"newState,omitempty"`
+	BlockNumber uint64           `protobuf:"varint,5,opt,name=blockNumber" json:"blockNumber,omitempty"`
+	Txid        string           `protobuf:"bytes,6,opt,name=txid" json:"txid,omitempty"`
+}
+
+func (m *TxSetEvent) Reset()         { *m = TxSetEvent{} }
+func (m *TxSetEvent) String() string { return proto.CompactTextString(m) }
+func (*TxSetEvent) ProtoMessage()    {}
+
+func (m *TxSetEvent) GetOldState() *TxSetStateValue {
+	if m != nil {
+		return m.OldState
+	}
+	return nil
+}
+
+func (m *TxSetEvent) GetNewState() *TxSetStateValue {
+	if m != nil {
+		return m.NewState
+	}
+	return nil
+}
+
 type Unregister struct {
 	Events []*Interest `protobuf:"bytes,1,rep,name=events" json:"events,omitempty"`
 }
@@ -218,6 +319,8 @@ type Event struct {
 	//	*Event_ChaincodeEvent
 	//	*Event_Rejection
 	//	*Event_Unregister
+	//	*Event_ReplayReport
+	//	*Event_TxSetEvent
 	Event isEvent_Event `protobuf_oneof:"Event"`
 }
 
@@ -245,12 +348,20 @@ type Event_Rejection struct {
 type Event_Unregister struct {
 	Unregister *Unregister `protobuf:"bytes,5,opt,name=unregister,oneof"`
 }
+type Event_ReplayReport struct {
+	ReplayReport *BlockReplayReport `protobuf:"bytes,6,opt,name=replayReport,oneof"`
+}
+type Event_TxSetEvent struct {
+	TxSetEvent *TxSetEvent `protobuf:"bytes,7,opt,name=txSetEvent,oneof"`
+}
 
 func (*Event_Register) isEvent_Event()       {}
 func (*Event_Block) isEvent_Event()          {}
 func (*Event_ChaincodeEvent) isEvent_Event() {}
 func (*Event_Rejection) isEvent_Event()      {}
 func (*Event_Unregister) isEvent_Event()     {}
+func (*Event_ReplayReport) isEvent_Event()   {}
+func (*Event_TxSetEvent) isEvent_Event()     {}
 
 func (m *Event) GetEvent() isEvent_Event {
 	if m != nil {
@@ -294,6 +405,20 @@ func (m *Event) GetUnregister() *Unregister {
 	return nil
 }
 
+func (m *Event) GetReplayReport() *BlockReplayReport {
+	if x, ok := m.GetEvent().(*Event_ReplayReport); ok {
+		return x.ReplayReport
+	}
+	return nil
+}
+
+func (m *Event) GetTxSetEvent() *TxSetEvent {
+	if x, ok := m.GetEvent().(*Event_TxSetEvent); ok {
+		return x.TxSetEvent
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Event) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Event_OneofMarshaler, _Event_OneofUnmarshaler, _Event_OneofSizer, []interface{}{
@@ -302,6 +427,8 @@ func (*Event) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error,
 		(*Event_ChaincodeEvent)(nil),
 		(*Event_Rejection)(nil),
 		(*Event_Unregister)(nil),
+		(*Event_ReplayReport)(nil),
+		(*Event_TxSetEvent)(nil),
 	}
 }
 
@@ -334,6 +461,16 @@ func _Event_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Unregister); err != nil {
 			return err
 		}
+	case *Event_ReplayReport:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.ReplayReport); err != nil {
+			return err
+		}
+	case *Event_TxSetEvent:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.TxSetEvent); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Event.Event has unexpected type %T", x)
@@ -384,6 +521,22 @@ func _Event_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer)
 		err := b.DecodeMessage(msg)
 		m.Event = &Event_Unregister{msg}
 		return true, err
+	case 6: // Event.replayReport
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BlockReplayReport)
+		err := b.DecodeMessage(msg)
+		m.Event = &Event_ReplayReport{msg}
+		return true, err
+	case 7: // Event.txSetEvent
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(TxSetEvent)
+		err := b.DecodeMessage(msg)
+		m.Event = &Event_TxSetEvent{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -418,6 +571,16 @@ func _Event_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(5<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Event_ReplayReport:
+		s := proto.Size(x.ReplayReport)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_TxSetEvent:
+		s := proto.Size(x.TxSetEvent)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -427,12 +590,15 @@ func _Event_OneofSizer(msg proto.Message) (n int) {
 
 func init() {
 	proto.RegisterType((*ChaincodeReg)(nil), "protos.ChaincodeReg")
+	proto.RegisterType((*TxSetReg)(nil), "protos.TxSetReg")
 	proto.RegisterType((*Interest)(nil), "protos.Interest")
 	proto.RegisterType((*Register)(nil), "protos.Register")
 	proto.RegisterType((*Rejection)(nil), "protos.Rejection")
+	proto.RegisterType((*TxSetEvent)(nil), "protos.TxSetEvent")
 	proto.RegisterType((*Unregister)(nil), "protos.Unregister")
 	proto.RegisterType((*Event)(nil), "protos.Event")
 	proto.RegisterEnum("protos.EventType", EventType_name, EventType_value)
+	proto.RegisterEnum("protos.TxSetEvent_Kind", TxSetEvent_Kind_name, TxSetEvent_Kind_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
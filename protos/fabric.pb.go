@@ -131,6 +131,30 @@ func (x Response_StatusCode) String() string {
 }
 func (Response_StatusCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor5, []int{17, 0} }
 
+type TransactionReplayResult_Outcome int32
+
+const (
+	// The outcome is not known, e.g. because it was not recorded
+	TransactionReplayResult_UNKNOWN   TransactionReplayResult_Outcome = 0
+	TransactionReplayResult_SUCCEEDED TransactionReplayResult_Outcome = 1
+	TransactionReplayResult_FAILED    TransactionReplayResult_Outcome = 2
+)
+
+var TransactionReplayResult_Outcome_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "SUCCEEDED",
+	2: "FAILED",
+}
+var TransactionReplayResult_Outcome_value = map[string]int32{
+	"UNKNOWN":   0,
+	"SUCCEEDED": 1,
+	"FAILED":    2,
+}
+
+func (x TransactionReplayResult_Outcome) String() string {
+	return proto.EnumName(TransactionReplayResult_Outcome_name, int32(x))
+}
+
 // Transaction defines a function call to a contract.
 // `args` is an array of type string so that the chaincode writer can choose
 // whatever format they wish for the arguments for their chaincode.
@@ -169,6 +193,20 @@ type MutantTransaction struct {
 	TxSetID string `protobuf:"bytes,1,opt,name=txSetID" json:"txSetID,omitempty"`
 	// The index of the new active transaction for this tx set
 	TxSetIndex uint64 `protobuf:"varint,2,opt,name=txSetIndex" json:"txSetIndex,omitempty"`
+	// Additional signatures required by the mutation policies of the tx sets
+	Signatures []*MutationSignature `protobuf:"bytes,3,rep,name=signatures" json:"signatures,omitempty"`
+	// Further tx sets mutated atomically with txSetID: either all the mutations are applied or none
+	AdditionalMutations []*TxSetMutation `protobuf:"bytes,4,rep,name=additionalMutations" json:"additionalMutations,omitempty"`
+	// The name of the chaincode which requested the mutation through its shim, set only by the validators
+	ChaincodeID string `protobuf:"bytes,5,opt,name=chaincodeID" json:"chaincodeID,omitempty"`
+	// If atBlock or notBefore is set, the mutation of txSetID is scheduled instead of applied: it takes effect
+	// at the start of block atBlock, or of the first block containing a transaction timestamped at or after notBefore
+	AtBlock   uint64                     `protobuf:"varint,6,opt,name=atBlock" json:"atBlock,omitempty"`
+	NotBefore *google_protobuf.Timestamp `protobuf:"bytes,7,opt,name=notBefore" json:"notBefore,omitempty"`
+	// If true, the mutation scheduled on txSetID is cancelled
+	CancelScheduled bool `protobuf:"varint,8,opt,name=cancelScheduled" json:"cancelScheduled,omitempty"`
+	// The ID of the mutant transaction which scheduled the mutation applied by this one, set only by the validators
+	ScheduledBy string `protobuf:"bytes,9,opt,name=scheduledBy" json:"scheduledBy,omitempty"`
 }
 
 func (m *MutantTransaction) Reset()                    { *m = MutantTransaction{} }
@@ -176,6 +214,27 @@ func (m *MutantTransaction) String() string            { return proto.CompactTex
 func (*MutantTransaction) ProtoMessage()               {}
 func (*MutantTransaction) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{1} }
 
+func (m *MutantTransaction) GetSignatures() []*MutationSignature {
+	if m != nil {
+		return m.Signatures
+	}
+	return nil
+}
+
+func (m *MutantTransaction) GetAdditionalMutations() []*TxSetMutation {
+	if m != nil {
+		return m.AdditionalMutations
+	}
+	return nil
+}
+
+func (m *MutantTransaction) GetNotBefore() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.NotBefore
+	}
+	return nil
+}
+
 type TransactionSet struct {
 	// transactions: the transactions in this set
 	// the bytes represent information to reconstruct to a Transaction type
@@ -184,6 +243,8 @@ type TransactionSet struct {
 	DefaultInx uint64 `protobuf:"varint,2,opt,name=defaultInx" json:"defaultInx,omitempty"`
 	// if extend is true the current transaction set is an extension of an existing one
 	Extend bool `protobuf:"varint,3,opt,name=extend" json:"extend,omitempty"`
+	// mutationPolicy: who is allowed to mutate this set, only taken into account at the creation of the set
+	MutationPolicy *TxSetMutationPolicy `protobuf:"bytes,4,opt,name=mutationPolicy" json:"mutationPolicy,omitempty"`
 }
 
 func (m *TransactionSet) Reset()                    { *m = TransactionSet{} }
@@ -191,9 +252,18 @@ func (m *TransactionSet) String() string            { return proto.CompactTextSt
 func (*TransactionSet) ProtoMessage()               {}
 func (*TransactionSet) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{2} }
 
+func (m *TransactionSet) GetMutationPolicy() *TxSetMutationPolicy {
+	if m != nil {
+		return m.MutationPolicy
+	}
+	return nil
+}
+
 type TxSetStateQuery struct {
 	TxSetID   string                     `protobuf:"bytes,1,opt,name=TxSetID,json=txSetID" json:"TxSetID,omitempty"`
 	Timestamp *google_protobuf.Timestamp `protobuf:"bytes,2,opt,name=timestamp" json:"timestamp,omitempty"`
+	// If true the query is ordered by the consensus together with the other transactions
+	Ordered bool `protobuf:"varint,3,opt,name=ordered" json:"ordered,omitempty"`
 }
 
 func (m *TxSetStateQuery) Reset()                    { *m = TxSetStateQuery{} }
@@ -491,6 +561,7 @@ func (*BlockchainInfo) Descriptor() ([]byte, []int) { return fileDescriptor5, []
 type NonHashData struct {
 	LocalLedgerCommitTimestamp *google_protobuf.Timestamp `protobuf:"bytes,1,opt,name=localLedgerCommitTimestamp" json:"localLedgerCommitTimestamp,omitempty"`
 	ChaincodeEvents            []*ChaincodeEvent          `protobuf:"bytes,2,rep,name=chaincodeEvents" json:"chaincodeEvents,omitempty"`
+	ReplayReports              []*BlockReplayReport       `protobuf:"bytes,3,rep,name=replayReports" json:"replayReports,omitempty"`
 }
 
 func (m *NonHashData) Reset()                    { *m = NonHashData{} }
@@ -512,6 +583,98 @@ func (m *NonHashData) GetChaincodeEvents() []*ChaincodeEvent {
 	return nil
 }
 
+func (m *NonHashData) GetReplayReports() []*BlockReplayReport {
+	if m != nil {
+		return m.ReplayReports
+	}
+	return nil
+}
+
+// TransactionReplayResult reports how the outcome of a transaction changed
+// when it was executed again after a mutation
+type TransactionReplayResult struct {
+	Txid            string                          `protobuf:"bytes,1,opt,name=txid" json:"txid,omitempty"`
+	PreviousOutcome TransactionReplayResult_Outcome `protobuf:"varint,2,opt,name=previousOutcome,enum=protos.TransactionReplayResult_Outcome" json:"previousOutcome,omitempty"`
+	Outcome         TransactionReplayResult_Outcome `protobuf:"varint,3,opt,name=outcome,enum=protos.TransactionReplayResult_Outcome" json:"outcome,omitempty"`
+	// The error of the re-execution, if it failed
+	Error                 string `protobuf:"bytes,4,opt,name=error" json:"error,omitempty"`
+	ChaincodeEventChanged bool   `protobuf:"varint,5,opt,name=chaincodeEventChanged" json:"chaincodeEventChanged,omitempty"`
+	// The chaincode event emitted by the re-execution, if chaincodeEventChanged
+	ChaincodeEvent *ChaincodeEvent `protobuf:"bytes,6,opt,name=chaincodeEvent" json:"chaincodeEvent,omitempty"`
+}
+
+func (m *TransactionReplayResult) Reset()         { *m = TransactionReplayResult{} }
+func (m *TransactionReplayResult) String() string { return proto.CompactTextString(m) }
+func (*TransactionReplayResult) ProtoMessage()    {}
+
+func (m *TransactionReplayResult) GetChaincodeEvent() *ChaincodeEvent {
+	if m != nil {
+		return m.ChaincodeEvent
+	}
+	return nil
+}
+
+// BlockReplayReport lists the transactions of a replayed block whose outcome
+// or chaincode event changed
+type BlockReplayReport struct {
+	BlockNumber  uint64                     `protobuf:"varint,1,opt,name=blockNumber" json:"blockNumber,omitempty"`
+	Transactions []*TransactionReplayResult `protobuf:"bytes,2,rep,name=transactions" json:"transactions,omitempty"`
+}
+
+func (m *BlockReplayReport) Reset()         { *m = BlockReplayReport{} }
+func (m *BlockReplayReport) String() string { return proto.CompactTextString(m) }
+func (*BlockReplayReport) ProtoMessage()    {}
+
+func (m *BlockReplayReport) GetTransactions() []*TransactionReplayResult {
+	if m != nil {
+		return m.Transactions
+	}
+	return nil
+}
+
+// ChaincodeKeyChange is the change of the value of a chaincode key
+type ChaincodeKeyChange struct {
+	ChaincodeID string `protobuf:"bytes,1,opt,name=chaincodeID" json:"chaincodeID,omitempty"`
+	Key         string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	// Empty if the key did not exist
+	PreviousValue []byte `protobuf:"bytes,3,opt,name=previousValue,proto3" json:"previousValue,omitempty"`
+	// Empty if the key was deleted
+	Value []byte `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *ChaincodeKeyChange) Reset()         { *m = ChaincodeKeyChange{} }
+func (m *ChaincodeKeyChange) String() string { return proto.CompactTextString(m) }
+func (*ChaincodeKeyChange) ProtoMessage()    {}
+
+// MutationSimulation is what a mutation would change if it was submitted: the
+// chaincode keys whose value at the end of the chain would change, and the
+// transactions of the replayed blocks whose outcome or chaincode event would
+// change
+type MutationSimulation struct {
+	// The first block replayed
+	FromBlock     uint64                `protobuf:"varint,1,opt,name=fromBlock" json:"fromBlock,omitempty"`
+	KeyChanges    []*ChaincodeKeyChange `protobuf:"bytes,2,rep,name=keyChanges" json:"keyChanges,omitempty"`
+	ReplayReports []*BlockReplayReport  `protobuf:"bytes,3,rep,name=replayReports" json:"replayReports,omitempty"`
+}
+
+func (m *MutationSimulation) Reset()         { *m = MutationSimulation{} }
+func (m *MutationSimulation) String() string { return proto.CompactTextString(m) }
+func (*MutationSimulation) ProtoMessage()    {}
+
+func (m *MutationSimulation) GetKeyChanges() []*ChaincodeKeyChange {
+	if m != nil {
+		return m.KeyChanges
+	}
+	return nil
+}
+
+func (m *MutationSimulation) GetReplayReports() []*BlockReplayReport {
+	if m != nil {
+		return m.ReplayReports
+	}
+	return nil
+}
+
 type PeerAddress struct {
 	Host string `protobuf:"bytes,1,opt,name=host" json:"host,omitempty"`
 	Port int32  `protobuf:"varint,2,opt,name=port" json:"port,omitempty"`
@@ -786,6 +949,10 @@ func init() {
 	proto.RegisterType((*Block)(nil), "protos.Block")
 	proto.RegisterType((*BlockchainInfo)(nil), "protos.BlockchainInfo")
 	proto.RegisterType((*NonHashData)(nil), "protos.NonHashData")
+	proto.RegisterType((*TransactionReplayResult)(nil), "protos.TransactionReplayResult")
+	proto.RegisterType((*BlockReplayReport)(nil), "protos.BlockReplayReport")
+	proto.RegisterType((*ChaincodeKeyChange)(nil), "protos.ChaincodeKeyChange")
+	proto.RegisterType((*MutationSimulation)(nil), "protos.MutationSimulation")
 	proto.RegisterType((*PeerAddress)(nil), "protos.PeerAddress")
 	proto.RegisterType((*PeerID)(nil), "protos.PeerID")
 	proto.RegisterType((*PeerEndpoint)(nil), "protos.PeerEndpoint")
@@ -804,6 +971,7 @@ func init() {
 	proto.RegisterEnum("protos.PeerEndpoint_Type", PeerEndpoint_Type_name, PeerEndpoint_Type_value)
 	proto.RegisterEnum("protos.Message_Type", Message_Type_name, Message_Type_value)
 	proto.RegisterEnum("protos.Response_StatusCode", Response_StatusCode_name, Response_StatusCode_value)
+	proto.RegisterEnum("protos.TransactionReplayResult_Outcome", TransactionReplayResult_Outcome_name, TransactionReplayResult_Outcome_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
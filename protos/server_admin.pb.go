@@ -8,6 +8,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import google_protobuf1 "github.com/golang/protobuf/ptypes/empty"
+import google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
 
 import (
 	context "golang.org/x/net/context"
@@ -61,8 +62,35 @@ func (m *ServerStatus) String() string            { return proto.CompactTextStri
 func (*ServerStatus) ProtoMessage()               {}
 func (*ServerStatus) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{0} }
 
+// TxSetGCReport - the data reclaimed by the garbage collection of the transactions sets
+// since the creation of the database. Sets introduced before the block horizon can no
+// longer be mutated, the data only needed to replay the blocks before it is discarded.
+type TxSetGCReport struct {
+	Horizon          uint64                     `protobuf:"varint,1,opt,name=horizon" json:"horizon,omitempty"`
+	Runs             uint64                     `protobuf:"varint,2,opt,name=runs" json:"runs,omitempty"`
+	LastRun          *google_protobuf.Timestamp `protobuf:"bytes,3,opt,name=lastRun" json:"lastRun,omitempty"`
+	Nonces           uint64                     `protobuf:"varint,4,opt,name=nonces" json:"nonces,omitempty"`
+	BlockStates      uint64                     `protobuf:"varint,5,opt,name=blockStates" json:"blockStates,omitempty"`
+	ReadWriteSets    uint64                     `protobuf:"varint,6,opt,name=readWriteSets" json:"readWriteSets,omitempty"`
+	StateDeltas      uint64                     `protobuf:"varint,7,opt,name=stateDeltas" json:"stateDeltas,omitempty"`
+	TxSetStateDeltas uint64                     `protobuf:"varint,8,opt,name=txSetStateDeltas" json:"txSetStateDeltas,omitempty"`
+	ReclaimedBytes   uint64                     `protobuf:"varint,9,opt,name=reclaimedBytes" json:"reclaimedBytes,omitempty"`
+}
+
+func (m *TxSetGCReport) Reset()         { *m = TxSetGCReport{} }
+func (m *TxSetGCReport) String() string { return proto.CompactTextString(m) }
+func (*TxSetGCReport) ProtoMessage()    {}
+
+func (m *TxSetGCReport) GetLastRun() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.LastRun
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*ServerStatus)(nil), "protos.ServerStatus")
+	proto.RegisterType((*TxSetGCReport)(nil), "protos.TxSetGCReport")
 	proto.RegisterEnum("protos.ServerStatus_StatusCode", ServerStatus_StatusCode_name, ServerStatus_StatusCode_value)
 }
 
@@ -81,6 +109,8 @@ type AdminClient interface {
 	GetStatus(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ServerStatus, error)
 	StartServer(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ServerStatus, error)
 	StopServer(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ServerStatus, error)
+	// Return what the garbage collection of the transactions sets data reclaimed.
+	GetTxSetGCReport(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*TxSetGCReport, error)
 }
 
 type adminClient struct {
@@ -118,6 +148,15 @@ func (c *adminClient) StopServer(ctx context.Context, in *google_protobuf1.Empty
 	return out, nil
 }
 
+func (c *adminClient) GetTxSetGCReport(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*TxSetGCReport, error) {
+	out := new(TxSetGCReport)
+	err := grpc.Invoke(ctx, "/protos.Admin/GetTxSetGCReport", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Admin service
 
 type AdminServer interface {
@@ -125,6 +164,8 @@ type AdminServer interface {
 	GetStatus(context.Context, *google_protobuf1.Empty) (*ServerStatus, error)
 	StartServer(context.Context, *google_protobuf1.Empty) (*ServerStatus, error)
 	StopServer(context.Context, *google_protobuf1.Empty) (*ServerStatus, error)
+	// Return what the garbage collection of the transactions sets data reclaimed.
+	GetTxSetGCReport(context.Context, *google_protobuf1.Empty) (*TxSetGCReport, error)
 }
 
 func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
@@ -185,6 +226,24 @@ func _Admin_StopServer_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Admin_GetTxSetGCReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf1.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetTxSetGCReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/GetTxSetGCReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetTxSetGCReport(ctx, req.(*google_protobuf1.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Admin_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.Admin",
 	HandlerType: (*AdminServer)(nil),
@@ -201,6 +260,10 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 			MethodName: "StopServer",
 			Handler:    _Admin_StopServer_Handler,
 		},
+		{
+			MethodName: "GetTxSetGCReport",
+			Handler:    _Admin_GetTxSetGCReport_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor6,
@@ -7,6 +7,7 @@ package protos
 import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
+import google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -31,6 +32,12 @@ type TxSetStateValue struct {
 	// e.g. if txsInBlock[i].inBlockIndex == 7 and txsInBlock[i].blockNr == 2 mean that the 8-th transaction
 	// of this transactions set is stored at block 2
 	IndexAtBlock []*TxSetIndex `protobuf:"bytes,6,rep,name=indexAtBlock" json:"indexAtBlock,omitempty"`
+	// Version of the protocol used to encrypt the transactions of this set
+	ConfidentialityProtocolVersion string `protobuf:"bytes,7,opt,name=confidentialityProtocolVersion" json:"confidentialityProtocolVersion,omitempty"`
+	// The policy deciding who is allowed to mutate this set
+	MutationPolicy *TxSetMutationPolicy `protobuf:"bytes,8,opt,name=mutationPolicy" json:"mutationPolicy,omitempty"`
+	// The mutation scheduled on this set and not applied yet, if any
+	ScheduledMutation *ScheduledMutation `protobuf:"bytes,9,opt,name=scheduledMutation" json:"scheduledMutation,omitempty"`
 }
 
 func (m *TxSetStateValue) Reset()                    { *m = TxSetStateValue{} }
@@ -45,6 +52,44 @@ func (m *TxSetStateValue) GetIndexAtBlock() []*TxSetIndex {
 	return nil
 }
 
+func (m *TxSetStateValue) GetMutationPolicy() *TxSetMutationPolicy {
+	if m != nil {
+		return m.MutationPolicy
+	}
+	return nil
+}
+
+func (m *TxSetStateValue) GetScheduledMutation() *ScheduledMutation {
+	if m != nil {
+		return m.ScheduledMutation
+	}
+	return nil
+}
+
+// A mutation of a transactions set that takes effect at the start of a later block
+type ScheduledMutation struct {
+	// The mutant transaction which scheduled the mutation
+	MutantTxid string `protobuf:"bytes,1,opt,name=mutantTxid" json:"mutantTxid,omitempty"`
+	// The index the set is switched to
+	TxSetIndex uint64 `protobuf:"varint,2,opt,name=txSetIndex" json:"txSetIndex,omitempty"`
+	// The mutation is applied at the start of block atBlock if not zero,
+	// or of the first block containing a transaction timestamped at or after notBefore,
+	// whichever comes first
+	AtBlock   uint64                     `protobuf:"varint,3,opt,name=atBlock" json:"atBlock,omitempty"`
+	NotBefore *google_protobuf.Timestamp `protobuf:"bytes,4,opt,name=notBefore" json:"notBefore,omitempty"`
+}
+
+func (m *ScheduledMutation) Reset()         { *m = ScheduledMutation{} }
+func (m *ScheduledMutation) String() string { return proto.CompactTextString(m) }
+func (*ScheduledMutation) ProtoMessage()    {}
+
+func (m *ScheduledMutation) GetNotBefore() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.NotBefore
+	}
+	return nil
+}
+
 // The TxSetIndex identifies a transaction among the ones
 // of a transactions set by providing the block number where that
 // transaction was defined and the index among the transactions
@@ -62,6 +107,48 @@ func (m *TxSetIndex) String() string            { return proto.CompactTextString
 func (*TxSetIndex) ProtoMessage()               {}
 func (*TxSetIndex) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{1} }
 
+// A change of the active transaction of a transactions set caused by a mutant transaction
+type TxSetIndexTransition struct {
+	// The block containing the mutant transaction
+	BlockNr    uint64 `protobuf:"varint,1,opt,name=blockNr" json:"blockNr,omitempty"`
+	MutantTxid string `protobuf:"bytes,2,opt,name=mutantTxid" json:"mutantTxid,omitempty"`
+	// The index of the active transaction before and after the mutation
+	OldIndex uint64 `protobuf:"varint,3,opt,name=oldIndex" json:"oldIndex,omitempty"`
+	NewIndex uint64 `protobuf:"varint,4,opt,name=newIndex" json:"newIndex,omitempty"`
+}
+
+func (m *TxSetIndexTransition) Reset()         { *m = TxSetIndexTransition{} }
+func (m *TxSetIndexTransition) String() string { return proto.CompactTextString(m) }
+func (*TxSetIndexTransition) ProtoMessage()    {}
+
+// The state of a transactions set rebuilt at a given block height
+type TxSetStateHistory struct {
+	TxSetID string `protobuf:"bytes,1,opt,name=txSetID" json:"txSetID,omitempty"`
+	BlockNr uint64 `protobuf:"varint,2,opt,name=blockNr" json:"blockNr,omitempty"`
+	// The state of the set after the block blockNr was committed
+	State *TxSetStateValue `protobuf:"bytes,3,opt,name=state" json:"state,omitempty"`
+	// The index transitions that happened up to blockNr, oldest first
+	Transitions []*TxSetIndexTransition `protobuf:"bytes,4,rep,name=transitions" json:"transitions,omitempty"`
+}
+
+func (m *TxSetStateHistory) Reset()         { *m = TxSetStateHistory{} }
+func (m *TxSetStateHistory) String() string { return proto.CompactTextString(m) }
+func (*TxSetStateHistory) ProtoMessage()    {}
+
+func (m *TxSetStateHistory) GetState() *TxSetStateValue {
+	if m != nil {
+		return m.State
+	}
+	return nil
+}
+
+func (m *TxSetStateHistory) GetTransitions() []*TxSetIndexTransition {
+	if m != nil {
+		return m.Transitions
+	}
+	return nil
+}
+
 // Used to index a transactions set in the db
 type TxSetToBlock struct {
 	// The index from the transactions of a given block at which this txSet was registered
@@ -82,7 +169,10 @@ func (m *TxSetToBlock) GetIndexInBlock() map[uint64]uint64 {
 
 func init() {
 	proto.RegisterType((*TxSetStateValue)(nil), "protos.TxSetStateValue")
+	proto.RegisterType((*ScheduledMutation)(nil), "protos.ScheduledMutation")
 	proto.RegisterType((*TxSetIndex)(nil), "protos.TxSetIndex")
+	proto.RegisterType((*TxSetIndexTransition)(nil), "protos.TxSetIndexTransition")
+	proto.RegisterType((*TxSetStateHistory)(nil), "protos.TxSetStateHistory")
 	proto.RegisterType((*TxSetToBlock)(nil), "protos.TxSetToBlock")
 }
 
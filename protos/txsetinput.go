@@ -0,0 +1,26 @@
+package protos
+
+// ChaincodeSpec returns the chaincode specification described by this simplified specification.
+// The security parameters are left to the caller.
+func (simpSpec *TxSetInput_SimplifiedSpec) ChaincodeSpec() *ChaincodeSpec {
+	return &ChaincodeSpec{
+		Type:        simpSpec.Lang,
+		ChaincodeID: simpSpec.ChaincodeID,
+		CtorMsg:     simpSpec.InputArgs,
+	}
+}
+
+// TxSpec wraps spec into a TxSpec matching the action of this simplified specification.
+func (simpSpec *TxSetInput_SimplifiedSpec) TxSpec(spec *ChaincodeSpec) *TxSpec {
+	txSpec := &TxSpec{Action: simpSpec.Action}
+	if simpSpec.Action == ChaincodeAction_CHAINCODE_DEPLOY {
+		txSpec.Spec = &TxSpec_CodeSpec{CodeSpec: spec}
+	} else {
+		invocationSpec := &ChaincodeInvocationSpec{ChaincodeSpec: spec}
+		if simpSpec.CustomIDGen != "" {
+			invocationSpec.IdGenerationAlg = simpSpec.CustomIDGen
+		}
+		txSpec.Spec = &TxSpec_InvocationSpec{InvocationSpec: invocationSpec}
+	}
+	return txSpec
+}
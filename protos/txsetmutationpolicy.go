@@ -0,0 +1,159 @@
+package protos
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// IsUnrestricted returns true if anyone is allowed to mutate a set protected by this policy
+func (policy *TxSetMutationPolicy) IsUnrestricted() bool {
+	return policy == nil || policy.Type == TxSetMutationPolicy_UNRESTRICTED
+}
+
+// Validate checks that the policy provided at the creation of a set is well formed
+func (policy *TxSetMutationPolicy) Validate() error {
+	if policy.IsUnrestricted() {
+		return nil
+	}
+	switch policy.Type {
+	case TxSetMutationPolicy_CREATOR:
+		if len(policy.CreatorCert) != 0 {
+			return errors.New("The creator certificate of a mutation policy is set by the validators and cannot be provided.")
+		}
+	case TxSetMutationPolicy_ENROLLMENT_IDS:
+		if len(policy.EnrollmentIDs) == 0 {
+			return errors.New("At least an enrollment ID should be provided for the mutation policy.")
+		}
+	case TxSetMutationPolicy_ATTRIBUTE:
+		if policy.AttributeName == "" {
+			return errors.New("The attribute name of the mutation policy should be provided.")
+		}
+	case TxSetMutationPolicy_THRESHOLD:
+		if policy.Threshold == 0 || int(policy.Threshold) > len(policy.SignerCerts) {
+			return fmt.Errorf("Invalid threshold for the mutation policy. Threshold: [%d], number of signers: [%d]", policy.Threshold, len(policy.SignerCerts))
+		}
+	case TxSetMutationPolicy_CHAINCODE:
+		if len(policy.ChaincodeIDs) == 0 {
+			return errors.New("At least a chaincode ID should be provided for the mutation policy.")
+		}
+	default:
+		return fmt.Errorf("Unknown mutation policy type [%d]", policy.Type)
+	}
+	return nil
+}
+
+// MutationSigningBytes returns the bytes to be signed to authorize the mutation of the transactions set txSetID
+// to the given index. The nonce is the one of the current state of the set, so that a signature cannot be
+// replayed once the state of the set has changed.
+func MutationSigningBytes(txSetID string, index uint64, nonce uint64) []byte {
+	buf := make([]byte, 16, 16+len(txSetID))
+	binary.BigEndian.PutUint64(buf[:8], index)
+	binary.BigEndian.PutUint64(buf[8:], nonce)
+	return append(buf, []byte(txSetID)...)
+}
+
+// MutationsSigningBytes returns the bytes to be signed to authorize all the mutations of a mutant transaction
+// at once. nonces holds the nonce of the current state of each mutated set, in the order of mutations. A single
+// mutation is signed as by MutationSigningBytes.
+func MutationsSigningBytes(mutations []*TxSetMutation, nonces []uint64) []byte {
+	if len(mutations) == 1 {
+		return MutationSigningBytes(mutations[0].TxSetID, mutations[0].TxSetIndex, nonces[0])
+	}
+	var buf []byte
+	for i, mutation := range mutations {
+		msg := MutationSigningBytes(mutation.TxSetID, mutation.TxSetIndex, nonces[i])
+		length := make([]byte, 8)
+		binary.BigEndian.PutUint64(length, uint64(len(msg)))
+		buf = append(append(buf, length...), msg...)
+	}
+	return buf
+}
+
+// Operations of a mutant transaction distinguished by MutantSigningBytes
+const (
+	mutantSigningSchedule byte = 1
+	mutantSigningCancel   byte = 2
+)
+
+// MutantSigningBytes returns the bytes to be signed to authorize the mutant transaction. An immediate mutation is
+// signed as by MutationsSigningBytes. Scheduling a mutation also signs the block and time at which it takes effect,
+// and cancelling one is signed apart as well. Their bytes start with an index no set can have, so that they never
+// match the ones of an immediate mutation.
+func MutantSigningBytes(mutant *MutantTransaction, nonces []uint64) []byte {
+	msg := MutationsSigningBytes(mutant.GetTxSetMutations(), nonces)
+	var buf []byte
+	switch {
+	case mutant.CancelScheduled:
+		buf = make([]byte, 9)
+		buf[8] = mutantSigningCancel
+	case mutant.IsScheduling():
+		buf = make([]byte, 29)
+		buf[8] = mutantSigningSchedule
+		binary.BigEndian.PutUint64(buf[9:17], mutant.AtBlock)
+		if notBefore := mutant.NotBefore; notBefore != nil {
+			binary.BigEndian.PutUint64(buf[17:25], uint64(notBefore.Seconds))
+			binary.BigEndian.PutUint32(buf[25:], uint32(notBefore.Nanos))
+		}
+	default:
+		return msg
+	}
+	binary.BigEndian.PutUint64(buf[:8], math.MaxUint64)
+	return append(buf, msg...)
+}
+
+// RequiresSecurity returns true if the policy can be enforced only by verifying the certificates of the signers
+// of the mutations
+func (policy *TxSetMutationPolicy) RequiresSecurity() bool {
+	return !policy.IsUnrestricted() && policy.Type != TxSetMutationPolicy_CHAINCODE
+}
+
+// AllowsChaincode returns true if the chaincode chaincodeID is allowed to mutate a set protected by this policy
+func (policy *TxSetMutationPolicy) AllowsChaincode(chaincodeID string) bool {
+	if policy.IsUnrestricted() {
+		return true
+	}
+	if policy.Type != TxSetMutationPolicy_CHAINCODE {
+		return false
+	}
+	for _, id := range policy.ChaincodeIDs {
+		if id == chaincodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTxSetMutations returns the mutations of all the sets changed by the mutant transaction, txSetID first
+func (m *MutantTransaction) GetTxSetMutations() []*TxSetMutation {
+	mutations := []*TxSetMutation{{TxSetID: m.TxSetID, TxSetIndex: m.TxSetIndex}}
+	return append(mutations, m.AdditionalMutations...)
+}
+
+// IsScheduling returns true if the mutant transaction schedules the mutation of its set instead of applying it
+func (m *MutantTransaction) IsScheduling() bool {
+	return m.AtBlock != 0 || m.NotBefore != nil
+}
+
+// IsIssuedByValidators returns true if the mutant transaction claims to be created by the validators, either on
+// behalf of a chaincode or to apply a scheduled mutation. Such transactions are never accepted from the clients
+func (m *MutantTransaction) IsIssuedByValidators() bool {
+	return m.ChaincodeID != "" || m.ScheduledBy != ""
+}
+
+// NewScheduledMutation returns the mutation scheduled by the mutant transaction with the given ID
+func (m *MutantTransaction) NewScheduledMutation(txid string) *ScheduledMutation {
+	return &ScheduledMutation{MutantTxid: txid, TxSetIndex: m.TxSetIndex, AtBlock: m.AtBlock, NotBefore: m.NotBefore}
+}
+
+// TransactionSetSigningBytes returns the bytes the creator of a transactions set signs when issuing it
+func TransactionSetSigningBytes(txSet *TransactionSet) ([]byte, error) {
+	data, err := proto.Marshal(txSet)
+	if err != nil {
+		return nil, fmt.Errorf("Could not marshal the transactions set: %s", err)
+	}
+	return data, nil
+}
@@ -8,6 +8,9 @@ import (
 	"errors"
 	"sort"
 	"reflect"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
 )
 
 // IsValidBlockExtension checks whether the other txSetStateValue is a valid extension of this txSetStateValue blockwise
@@ -33,6 +36,12 @@ func (txSetStateValue *TxSetStateValue) IsValidBlockExtension(other *TxSetStateV
 	if txSetStateValue.IntroBlock != 0 && other.Index != txSetStateValue.Index {
 		return errors.New("It is not possible to modify the index in a set extension.")
 	}
+	if txSetStateValue.IntroBlock != 0 && other.ConfidentialityProtocolVersion != txSetStateValue.ConfidentialityProtocolVersion {
+		return errors.New("It is not possible to modify the confidentiality protocol version in a set extension.")
+	}
+	if txSetStateValue.IntroBlock != 0 && !reflect.DeepEqual(other.MutationPolicy, txSetStateValue.MutationPolicy) {
+		return errors.New("It is not possible to modify the mutation policy in a set extension.")
+	}
 	return nil
 }
 
@@ -52,9 +61,52 @@ func (txSetStateValue *TxSetStateValue) IsValidMutation(other *TxSetStateValue)
 	if !reflect.DeepEqual(txSetStateValue.IndexAtBlock, other.IndexAtBlock) {
 		return errors.New("A mutant transaction cannot extend a set.")
 	}
+	if txSetStateValue.ConfidentialityProtocolVersion != other.ConfidentialityProtocolVersion {
+		return errors.New("A mutant transaction cannot modify the confidentiality protocol version.")
+	}
+	if !reflect.DeepEqual(txSetStateValue.MutationPolicy, other.MutationPolicy) {
+		return errors.New("A mutant transaction cannot modify the mutation policy.")
+	}
+	return nil
+}
+
+// IsValidScheduleChange checks that the other txSetStateValue only schedules a mutation of this set or cancels the
+// one already scheduled, leaving the rest of the state untouched
+func (txSetStateValue *TxSetStateValue) IsValidScheduleChange(other *TxSetStateValue) error {
+	if txSetStateValue.LastModifiedAtBlock >= other.LastModifiedAtBlock {
+		return fmt.Errorf("It is not allow to modify a transaction before the last time it was modified. Block last time modified: [%d], Current modifying block: [%d]", txSetStateValue.LastModifiedAtBlock, other.LastModifiedAtBlock)
+	}
+	if txSetStateValue.Index != other.Index || txSetStateValue.TxNumber != other.TxNumber || !reflect.DeepEqual(txSetStateValue.IndexAtBlock, other.IndexAtBlock) {
+		return errors.New("Scheduling or cancelling a mutation cannot change the transactions of the set.")
+	}
+	if txSetStateValue.ConfidentialityProtocolVersion != other.ConfidentialityProtocolVersion {
+		return errors.New("Scheduling or cancelling a mutation cannot modify the confidentiality protocol version.")
+	}
+	if !reflect.DeepEqual(txSetStateValue.MutationPolicy, other.MutationPolicy) {
+		return errors.New("Scheduling or cancelling a mutation cannot modify the mutation policy.")
+	}
+	if other.ScheduledMutation != nil && other.ScheduledMutation.TxSetIndex >= other.TxNumber {
+		return fmt.Errorf("Provided an out of bound index for the scheduled mutation. Num transactions: [%d], provided index: [%d]", other.TxNumber, other.ScheduledMutation.TxSetIndex)
+	}
 	return nil
 }
 
+// IsDue returns true if the scheduled mutation should be applied at the start of block blockNumber. blockTime is the
+// timestamp of the previous block, nil if it is not timestamped
+func (scheduled *ScheduledMutation) IsDue(blockNumber uint64, blockTime *timestamp.Timestamp) bool {
+	if scheduled == nil {
+		return false
+	}
+	if scheduled.AtBlock != 0 && blockNumber >= scheduled.AtBlock {
+		return true
+	}
+	notBefore := scheduled.NotBefore
+	if notBefore == nil || blockTime == nil {
+		return false
+	}
+	return blockTime.Seconds > notBefore.Seconds || (blockTime.Seconds == notBefore.Seconds && blockTime.Nanos >= notBefore.Nanos)
+}
+
 func (txSetStateValue *TxSetStateValue) PositionForIndex(inx uint64) (int, error) {
 	i := sort.Search(len(txSetStateValue.IndexAtBlock), func(i int) bool { return inx <= txSetStateValue.IndexAtBlock[i].InBlockIndex})
 	if i < len(txSetStateValue.IndexAtBlock) {
@@ -80,6 +132,22 @@ func (txSetStVal *TxSetStateValue) ToString() string {
 	buffer.WriteString(fmt.Sprintln("Last modified at block number:", txSetStVal.LastModifiedAtBlock))
 	buffer.WriteString(fmt.Sprintln("Active transaction index:", txSetStVal.Index))
 	buffer.WriteString(fmt.Sprintln("Number of transactions in the set:", txSetStVal.TxNumber))
+	buffer.WriteString(fmt.Sprintln("Confidentiality protocol version:", txSetStVal.ConfidentialityProtocolVersion))
+	policyType := TxSetMutationPolicy_UNRESTRICTED
+	if txSetStVal.MutationPolicy != nil {
+		policyType = txSetStVal.MutationPolicy.Type
+	}
+	buffer.WriteString(fmt.Sprintln("Mutation policy:", policyType))
+	if scheduled := txSetStVal.ScheduledMutation; scheduled != nil {
+		buffer.WriteString(fmt.Sprintln("Scheduled mutation:", scheduled.MutantTxid))
+		buffer.WriteString(fmt.Sprintln("\tNew active transaction index:", scheduled.TxSetIndex))
+		if scheduled.AtBlock != 0 {
+			buffer.WriteString(fmt.Sprintln("\tAt block number:", scheduled.AtBlock))
+		}
+		if scheduled.NotBefore != nil {
+			buffer.WriteString(fmt.Sprintln("\tNot before:", time.Unix(scheduled.NotBefore.Seconds, int64(scheduled.NotBefore.Nanos)).UTC().Format(time.RFC3339Nano)))
+		}
+	}
 	buffer.WriteString(fmt.Sprintln("Number of transactions belonging to this set at a given block:"))
 	buffer.WriteString(fmt.Sprintln("Block\t\t\tLast Index"))
 	for _, inx := range txSetStVal.IndexAtBlock {
@@ -0,0 +1,94 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protos
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func TestScheduledMutationIsDue(t *testing.T) {
+	atBlock := &ScheduledMutation{AtBlock: 10}
+	notBefore := &ScheduledMutation{NotBefore: &timestamp.Timestamp{Seconds: 100, Nanos: 5}}
+	both := &ScheduledMutation{AtBlock: 10, NotBefore: &timestamp.Timestamp{Seconds: 100}}
+	testCases := []struct {
+		scheduled   *ScheduledMutation
+		blockNumber uint64
+		blockTime   *timestamp.Timestamp
+		due         bool
+	}{
+		{nil, 10, nil, false},
+		{atBlock, 9, &timestamp.Timestamp{Seconds: 1000}, false},
+		{atBlock, 10, nil, true},
+		{atBlock, 11, nil, true},
+		{notBefore, 1000, nil, false},
+		{notBefore, 1, &timestamp.Timestamp{Seconds: 100, Nanos: 4}, false},
+		{notBefore, 1, &timestamp.Timestamp{Seconds: 100, Nanos: 5}, true},
+		{notBefore, 1, &timestamp.Timestamp{Seconds: 101}, true},
+		{both, 9, &timestamp.Timestamp{Seconds: 99}, false},
+		{both, 9, &timestamp.Timestamp{Seconds: 100}, true},
+		{both, 10, &timestamp.Timestamp{Seconds: 99}, true},
+	}
+	for i, testCase := range testCases {
+		if due := testCase.scheduled.IsDue(testCase.blockNumber, testCase.blockTime); due != testCase.due {
+			t.Errorf("Test case %d: expected due to be %t, got %t", i, testCase.due, due)
+		}
+	}
+}
+
+func TestIsValidScheduleChange(t *testing.T) {
+	previous := &TxSetStateValue{Nonce: 2, IntroBlock: 1, LastModifiedAtBlock: 1, Index: 0, TxNumber: 3,
+		IndexAtBlock: []*TxSetIndex{{BlockNr: 1, InBlockIndex: 2}}}
+	scheduled := *previous
+	scheduled.Nonce = 3
+	scheduled.LastModifiedAtBlock = 4
+	scheduled.ScheduledMutation = &ScheduledMutation{MutantTxid: "mutant", TxSetIndex: 2, AtBlock: 8}
+	if err := previous.IsValidScheduleChange(&scheduled); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	outOfBound := scheduled
+	outOfBound.ScheduledMutation = &ScheduledMutation{MutantTxid: "mutant", TxSetIndex: 3, AtBlock: 8}
+	if err := previous.IsValidScheduleChange(&outOfBound); err == nil {
+		t.Fatal("A mutation to an out of bound index should not be scheduled.")
+	}
+
+	mutated := scheduled
+	mutated.Index = 1
+	if err := previous.IsValidScheduleChange(&mutated); err == nil {
+		t.Fatal("Scheduling a mutation should not change the active transaction.")
+	}
+}
+
+func TestMutantSigningBytes(t *testing.T) {
+	immediate := &MutantTransaction{TxSetID: "set", TxSetIndex: 1}
+	msg := MutantSigningBytes(immediate, []uint64{4})
+	if string(msg) != string(MutationSigningBytes("set", 1, 4)) {
+		t.Fatal("An immediate mutation should be signed as by MutationSigningBytes.")
+	}
+	scheduled := &MutantTransaction{TxSetID: "set", TxSetIndex: 1, AtBlock: 7}
+	cancel := &MutantTransaction{TxSetID: "set", TxSetIndex: 1, CancelScheduled: true}
+	signed := map[string]bool{string(msg): true}
+	for _, mutant := range []*MutantTransaction{scheduled, cancel} {
+		bytes := string(MutantSigningBytes(mutant, []uint64{4}))
+		if signed[bytes] {
+			t.Fatalf("The signing bytes of %v match the ones of another operation.", mutant)
+		}
+		signed[bytes] = true
+	}
+}
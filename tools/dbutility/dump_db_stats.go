@@ -40,15 +40,26 @@ func main() {
 	flagSetName := os.Args[0]
 	flagSet := flag.NewFlagSet(flagSetName, flag.ExitOnError)
 	dbDirPtr := flagSet.String("dbDir", "", "path to db dump")
+	configPtr := flagSet.String("config", "", "path to the core.yaml of the peer that wrote the db (optional)")
+	flagSet.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s: [flags] [stats | txsets | deltas [fromBlock [toBlock]] | default <txSetID> | check]\n", flagSetName)
+		flagSet.PrintDefaults()
+	}
 	flagSet.Parse(os.Args[1:])
 
 	dbDir := *dbDirPtr
 
 	if dbDir == "" {
-		fmt.Fprintf(os.Stderr, "Usage of %s:\n", flagSetName)
-		flagSet.PrintDefaults()
+		flagSet.Usage()
 		os.Exit(3)
 	}
+	if *configPtr != "" {
+		viper.SetConfigFile(*configPtr)
+		if err := viper.ReadInConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading config file: %s\n", err)
+			os.Exit(6)
+		}
+	}
 	viper.Set("peer.fileSystemPath", dbDir)
 	fmt.Printf("dbDir = [%s]\n", dbDir)
 
@@ -64,13 +75,35 @@ func main() {
 	}
 
 	db.Start()
-	openchainDB := db.GetDBHandle()
 	defer db.Stop()
 	fmt.Println()
+
+	command := flagSet.Arg(0)
+	if command == "" || command == "stats" {
+		printStats(db.GetDBHandle())
+		return
+	}
+
+	if err := runTxSetCommand(command, flagSet.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		db.Stop()
+		os.Exit(1)
+	}
+}
+
+func printStats(openchainDB *db.OpenchainDB) {
 	scan(openchainDB, "blockchainCF", openchainDB.BlockchainCF, blockDetailPrinter)
 	fmt.Println()
 	scan(openchainDB, "persistCF", openchainDB.PersistCF, nil)
 	fmt.Println()
+	scan(openchainDB, "blockStateCF", openchainDB.BlockStateCF, nil)
+	fmt.Println()
+	scan(openchainDB, "txSetStateCF", openchainDB.TxSetStateCF, nil)
+	fmt.Println()
+	scan(openchainDB, "txSetStateDeltaCF", openchainDB.TxSetStateDeltaCF, nil)
+	fmt.Println()
+	scan(openchainDB, "noncesCF", openchainDB.NoncesCF, nil)
+	fmt.Println()
 	printLiveFilesMetaData(openchainDB)
 	fmt.Println()
 	printProperties(openchainDB)
@@ -147,11 +180,18 @@ func blockDetailPrinter(blockBytes []byte) {
 	txs := block.GetTransactions()
 	fmt.Printf("Number of transactions = [%d]\n", len(txs))
 	for _, tx := range txs {
-		if len(tx.Payload) >= MaxValueSize {
-			cIDBytes := tx.ChaincodeID
-			cID := &protos.ChaincodeID{}
-			proto.Unmarshal(cIDBytes, cID)
-			fmt.Printf("TxDetails: payloadSize=[%d], tx.Type=[%s], cID.Name=[%s], cID.Path=[%s]\n", len(tx.Payload), tx.Type, cID.Name, cID.Path)
+		txSize := proto.Size(tx)
+		if txSize >= MaxValueSize {
+			kind := "unknown"
+			switch tx.Transaction.(type) {
+			case *protos.InBlockTransaction_TransactionSet:
+				kind = "transactionSet"
+			case *protos.InBlockTransaction_MutantTransaction:
+				kind = "mutantTransaction"
+			case *protos.InBlockTransaction_SetStQueryTransaction:
+				kind = "setStQueryTransaction"
+			}
+			fmt.Printf("TxDetails: size=[%d], txid=[%s], kind=[%s]\n", txSize, tx.Txid, kind)
 		}
 	}
 }
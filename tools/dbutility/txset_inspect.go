@@ -0,0 +1,183 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/protos"
+)
+
+// runTxSetCommand runs one of the commands inspecting the transactions set column families
+func runTxSetCommand(command string, args []string) error {
+	l, err := ledger.GetLedger()
+	if err != nil {
+		return err
+	}
+	switch command {
+	case "txsets":
+		_, err = printTxSetStates(l)
+	case "deltas":
+		size := l.GetBlockchainSize()
+		if size == 0 {
+			return fmt.Errorf("the blockchain has no blocks")
+		}
+		from, to := uint64(0), size-1
+		if len(args) > 0 {
+			if from, err = strconv.ParseUint(args[0], 10, 64); err != nil {
+				return fmt.Errorf("invalid block number [%s]: %s", args[0], err)
+			}
+			to = from
+		}
+		if len(args) > 1 {
+			if to, err = strconv.ParseUint(args[1], 10, 64); err != nil {
+				return fmt.Errorf("invalid block number [%s]: %s", args[1], err)
+			}
+		}
+		if to >= size {
+			to = size - 1
+		}
+		_, err = printTxSetStateDeltas(l, from, to)
+	case "default":
+		if len(args) != 1 {
+			return fmt.Errorf("the default command expects the transactions set ID as its only argument")
+		}
+		err = printCurrentDefault(l, args[0])
+	case "check":
+		err = checkTxSetStateHash(l)
+	default:
+		err = fmt.Errorf("unknown command [%s]", command)
+	}
+	return err
+}
+
+// printTxSetStates prints the state of every transactions set and returns the number of sets
+func printTxSetStates(l *ledger.Ledger) (int, error) {
+	fmt.Println("------- Printing the state of the transactions sets --------")
+	chainSnapshot, txSetSnapshot, err := l.GetStateSnapshot()
+	if err != nil {
+		return 0, err
+	}
+	chainSnapshot.Release()
+	defer txSetSnapshot.Release()
+
+	numSets := 0
+	for txSetSnapshot.Next() {
+		k, v := txSetSnapshot.GetRawKeyValue()
+		numSets++
+		stateValue, err := protos.UnmarshalTxSetStateValue(v)
+		if err != nil {
+			fmt.Printf("txSetID=[%s], undecodable value=[%x]: %s\n", k, v, err)
+			continue
+		}
+		fmt.Printf("txSetID=[%s]\n%s\n", k, stateValue.ToString())
+	}
+	fmt.Printf("numSets=[%d], blockNumber=[%d]\n", numSets, txSetSnapshot.GetBlockNumber())
+	return numSets, nil
+}
+
+// printTxSetStateDeltas prints the changes each block in [from, to] made to the state of
+// the transactions sets and returns the number of blocks whose changes are still retained
+func printTxSetStateDeltas(l *ledger.Ledger, from uint64, to uint64) (int, error) {
+	fmt.Printf("------- Printing the transactions set state deltas of blocks [%d] to [%d] --------\n", from, to)
+	numDeltas := 0
+	for blockNumber := from; blockNumber <= to; blockNumber++ {
+		_, delta, err := l.GetStateDelta(blockNumber)
+		if err != nil {
+			return numDeltas, err
+		}
+		if delta == nil {
+			fmt.Printf("block=[%d]: state delta not retained\n", blockNumber)
+			continue
+		}
+		numDeltas++
+		txSetIDs := delta.GetUpdatedTxSetIDs(true)
+		fmt.Printf("block=[%d]: %d set(s) changed\n", blockNumber, len(txSetIDs))
+		for _, txSetID := range txSetIDs {
+			updated := delta.GetUpdates(txSetID)
+			switch {
+			case updated.IsDeleted():
+				fmt.Printf("  txSetID=[%s] deleted\n", txSetID)
+			case updated.GetPreviousValue() == nil:
+				fmt.Printf("  txSetID=[%s] created: index=[%d], txNumber=[%d]\n", txSetID,
+					updated.GetValue().Index, updated.GetValue().TxNumber)
+			default:
+				fmt.Printf("  txSetID=[%s] updated (mutant=[%t]): index=[%d]->[%d], txNumber=[%d]->[%d], nonce=[%d]->[%d]\n",
+					txSetID, updated.IsMutantChange(),
+					updated.GetPreviousValue().Index, updated.GetValue().Index,
+					updated.GetPreviousValue().TxNumber, updated.GetValue().TxNumber,
+					updated.GetPreviousValue().Nonce, updated.GetValue().Nonce)
+			}
+		}
+	}
+	return numDeltas, nil
+}
+
+// printCurrentDefault prints the state of a transactions set and resolves its current default transaction
+func printCurrentDefault(l *ledger.Ledger, txSetID string) error {
+	fmt.Printf("------- Printing the current default transaction of transactions set [%s] --------\n", txSetID)
+	stateValue, err := l.GetTxSetState(txSetID, true)
+	if err != nil {
+		return err
+	}
+	if stateValue == nil {
+		return fmt.Errorf("no state found for transactions set [%s]", txSetID)
+	}
+	fmt.Print(stateValue.ToString())
+
+	tx, err := l.GetCurrentDefaultByID(txSetID)
+	if err != nil {
+		return err
+	}
+	cID := &protos.ChaincodeID{}
+	if err = proto.Unmarshal(tx.ChaincodeID, cID); err != nil {
+		fmt.Printf("Default transaction: index=[%d], txid=[%s], type=[%s], chaincodeID=[%x] (undecodable)\n",
+			stateValue.Index, tx.Txid, tx.Type, tx.ChaincodeID)
+		return nil
+	}
+	fmt.Printf("Default transaction: index=[%d], txid=[%s], type=[%s], cID.Name=[%s], cID.Path=[%s], payloadSize=[%d]\n",
+		stateValue.Index, tx.Txid, tx.Type, cID.Name, cID.Path, len(tx.Payload))
+	return nil
+}
+
+// checkTxSetStateHash recomputes the hash of the state of the transactions sets and compares it
+// with the one stored in the latest block
+func checkTxSetStateHash(l *ledger.Ledger) error {
+	fmt.Println("------- Checking the transactions set state hash --------")
+	size := l.GetBlockchainSize()
+	if size == 0 {
+		return fmt.Errorf("the blockchain has no blocks")
+	}
+	block, err := l.GetBlockByNumber(size - 1)
+	if err != nil {
+		return err
+	}
+	computed, err := l.GetTempTxSetStateHash()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("block=[%d], storedHash=[%x], computedHash=[%x]\n", size-1, block.GetTxSetStateHash(), computed)
+	if !bytes.Equal(block.GetTxSetStateHash(), computed) {
+		return fmt.Errorf("the transactions set state hash of block [%d] does not match the state", size-1)
+	}
+	fmt.Println("The transactions set state is consistent with the latest block")
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/protos"
+	"github.com/tecbot/gorocksdb"
+)
+
+func TestTxSetInspection(t *testing.T) {
+	dbTestWrapper := db.NewTestDBWrapper()
+	dbTestWrapper.CleanDB(t)
+	defer dbTestWrapper.CloseDB(t)
+	defer deleteTestDBDir()
+
+	l, err := ledger.GetNewLedger()
+	if err != nil {
+		t.Fatalf("Error while constructing ledger: %s", err)
+	}
+	for i, txSetID := range []string{"txSet1", "txSet2"} {
+		l.BeginTxBatch(i)
+		l.SetTxBegin(txSetID)
+		l.SetTxSetState(txSetID, &protos.TxSetStateValue{IntroBlock: uint64(i), TxNumber: 2})
+		l.SetTxFinished(txSetID, true)
+		if err = l.CommitTxBatch(i, nil, nil, nil); err != nil {
+			t.Fatalf("Error while committing block [%d]: %s", i, err)
+		}
+	}
+
+	numSets, err := printTxSetStates(l)
+	if err != nil {
+		t.Fatalf("Error while printing the transactions sets: %s", err)
+	}
+	if numSets != 2 {
+		t.Fatalf("numSets is not correct. Expected [%d], found [%d]", 2, numSets)
+	}
+
+	numDeltas, err := printTxSetStateDeltas(l, 0, 1)
+	if err != nil {
+		t.Fatalf("Error while printing the state deltas: %s", err)
+	}
+	if numDeltas != 2 {
+		t.Fatalf("numDeltas is not correct. Expected [%d], found [%d]", 2, numDeltas)
+	}
+
+	if err = checkTxSetStateHash(l); err != nil {
+		t.Fatalf("The check failed on a consistent db: %s", err)
+	}
+
+	// Tamper with the state of a transactions set
+	openchainDB := db.GetDBHandle()
+	writeBatch := gorocksdb.NewWriteBatch()
+	tampered, _ := (&protos.TxSetStateValue{IntroBlock: 1, TxNumber: 3}).Bytes()
+	writeBatch.PutCF(openchainDB.TxSetStateCF, []byte("txSet2"), tampered)
+	dbTestWrapper.WriteToDB(t, writeBatch)
+
+	l, err = ledger.GetNewLedger()
+	if err != nil {
+		t.Fatalf("Error while constructing ledger: %s", err)
+	}
+	if err = checkTxSetStateHash(l); err == nil {
+		t.Fatal("The check should have failed on a tampered db")
+	}
+}